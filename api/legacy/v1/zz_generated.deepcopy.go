@@ -5,6 +5,7 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -71,6 +72,11 @@ func (in *LogicalVolumeList) DeepCopyObject() runtime.Object {
 func (in *LogicalVolumeSpec) DeepCopyInto(out *LogicalVolumeSpec) {
 	*out = *in
 	out.Size = in.Size.DeepCopy()
+	if in.LvcreateOptions != nil {
+		in, out := &in.LvcreateOptions, &out.LvcreateOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalVolumeSpec.
@@ -91,6 +97,23 @@ func (in *LogicalVolumeStatus) DeepCopyInto(out *LogicalVolumeStatus) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Operation != nil {
+		in, out := &in.Operation, &out.Operation
+		*out = new(OperationStatus)
+		**out = **in
+	}
+	if in.AllocatedSize != nil {
+		in, out := &in.AllocatedSize, &out.AllocatedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalVolumeStatus.
@@ -102,3 +125,18 @@ func (in *LogicalVolumeStatus) DeepCopy() *LogicalVolumeStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
+func (in *OperationStatus) DeepCopy() *OperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
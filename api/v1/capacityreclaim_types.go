@@ -0,0 +1,133 @@
+package v1
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ReclaimPolicyUnused selects the volumes whose PVC is not used by any pod, the largest first.
+	ReclaimPolicyUnused = "Unused"
+	// ReclaimPolicySmallest selects the smallest volumes first.
+	ReclaimPolicySmallest = "Smallest"
+	// ReclaimPolicyPreemptible selects the volumes whose PVC is annotated preemptible, the largest first.
+	ReclaimPolicyPreemptible = "Preemptible"
+
+	// ReclaimActionRequest asks the owners of the selected volumes to migrate or delete them.
+	ReclaimActionRequest = "Request"
+	// ReclaimActionDelete deletes the PVCs of the selected volumes.
+	ReclaimActionDelete = "Delete"
+
+	// ReclaimPhasePending is the phase of a CapacityReclaim whose candidates do not add up to the target yet.
+	ReclaimPhasePending = "Pending"
+	// ReclaimPhaseInProgress is the phase of a CapacityReclaim waiting for its candidates to be reclaimed.
+	ReclaimPhaseInProgress = "InProgress"
+	// ReclaimPhaseCompleted is the phase of a CapacityReclaim which has reclaimed the target.
+	ReclaimPhaseCompleted = "Completed"
+
+	// ConditionCandidatesSelected is the condition of a CapacityReclaim telling whether enough volumes
+	// have been selected to reclaim the target.
+	ConditionCandidatesSelected = "CandidatesSelected"
+	// ConditionTargetReclaimed is the condition of a CapacityReclaim telling whether the target has been reclaimed.
+	ConditionTargetReclaimed = "TargetReclaimed"
+)
+
+// CapacityReclaimSpec defines the space to free on a node and how the volumes to free it are selected.
+type CapacityReclaimSpec struct {
+	// 'nodeName' is the name of the node to free the space on.
+	NodeName string `json:"nodeName"`
+
+	// 'deviceClass' is the name of the device-class to free the space of. Empty means the default device-class.
+	//+kubebuilder:validation:Optional
+	DeviceClass string `json:"deviceClass,omitempty"`
+
+	// 'target' is the amount of space to free.
+	Target resource.Quantity `json:"target"`
+
+	// 'policy' selects the volumes to reclaim, either "Unused", "Smallest" or "Preemptible".
+	//+kubebuilder:validation:Enum=Unused;Smallest;Preemptible
+	Policy string `json:"policy"`
+
+	// 'action' is how the selected volumes are reclaimed.
+	// "Request" asks their owners by events on the PVCs to migrate or delete them, and "Delete" deletes the PVCs.
+	// Defaults to "Request".
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum=Request;Delete
+	Action string `json:"action,omitempty"`
+}
+
+// CapacityReclaimStatus defines the observed state of CapacityReclaim
+type CapacityReclaimStatus struct {
+	// 'phase' is "Pending" until enough volumes are selected, "InProgress" while they are being reclaimed
+	// and "Completed" once the target has been reclaimed.
+	//+kubebuilder:validation:Optional
+	Phase string `json:"phase,omitempty"`
+
+	// 'candidates' are the volumes selected to reclaim the target.
+	//+kubebuilder:validation:Optional
+	Candidates []ReclaimCandidate `json:"candidates,omitempty"`
+
+	// 'selectedSize' is the total size of the candidates.
+	//+kubebuilder:validation:Optional
+	SelectedSize *resource.Quantity `json:"selectedSize,omitempty"`
+
+	// 'reclaimedSize' is the total size of the candidates reclaimed so far.
+	//+kubebuilder:validation:Optional
+	ReclaimedSize *resource.Quantity `json:"reclaimedSize,omitempty"`
+
+	// 'conditions' explains the progress, e.g. "CandidatesSelected" is false while the volumes do not add up to the target.
+	//+kubebuilder:validation:Optional
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ReclaimCandidate is a volume selected to be reclaimed.
+type ReclaimCandidate struct {
+	// 'logicalVolume' is the name of the LogicalVolume.
+	LogicalVolume string `json:"logicalVolume"`
+
+	// 'namespace' is the namespace of the PVC.
+	Namespace string `json:"namespace"`
+
+	// 'persistentVolumeClaim' is the name of the PVC.
+	PersistentVolumeClaim string `json:"persistentVolumeClaim"`
+
+	// 'size' is the size of the volume.
+	Size resource.Quantity `json:"size"`
+
+	// 'reclaimed' is true once the LogicalVolume has been deleted.
+	//+kubebuilder:validation:Optional
+	Reclaimed bool `json:"reclaimed,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target`
+//+kubebuilder:printcolumn:name="Reclaimed",type=string,JSONPath=`.status.reclaimedSize`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// CapacityReclaim is the Schema for the capacityreclaims API
+type CapacityReclaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CapacityReclaimSpec   `json:"spec,omitempty"`
+	Status CapacityReclaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// CapacityReclaimList contains a list of CapacityReclaim
+type CapacityReclaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CapacityReclaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CapacityReclaim{}, &CapacityReclaimList{})
+}
@@ -6,6 +6,54 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// OperationMerge merges a snapshot LogicalVolume back into its source.
+	OperationMerge = "merge"
+	// OperationMove moves a thin LogicalVolume to the thin pool of another device class.
+	// It is requested by an annotation instead of spec.operation.
+	OperationMove = "move"
+
+	// OperationPhaseInProgress is the phase of a running operation.
+	OperationPhaseInProgress = "InProgress"
+	// OperationPhaseCompleted is the phase of a completed operation.
+	OperationPhaseCompleted = "Completed"
+
+	// ConditionNodeDeleted is the condition of a LogicalVolume whose node does not exist.
+	// Its reason tells how the deleted-node policy of topolvm-controller handles the LogicalVolume.
+	ConditionNodeDeleted = "NodeDeleted"
+
+	// ConditionVolumeHealthy is the condition of a LogicalVolume telling whether lvmd reports its LV healthy
+	// from lv_attr and the state of its thin pool. It is set once the LV has been unhealthy.
+	ConditionVolumeHealthy = "VolumeHealthy"
+	// HealthReasonHealthy is the reason of the VolumeHealthy condition of a healthy LV.
+	HealthReasonHealthy = "Healthy"
+	// HealthReasonVolumeUnhealthy is the reason of the VolumeHealthy condition of an LV unhealthy by its lv_attr.
+	HealthReasonVolumeUnhealthy = "VolumeUnhealthy"
+	// HealthReasonThinPoolUnhealthy is the reason of the VolumeHealthy condition of a thin LV whose thin pool is unhealthy.
+	HealthReasonThinPoolUnhealthy = "ThinPoolUnhealthy"
+
+	// ConditionReconciliationHalted is the condition of a LogicalVolume telling whether topolvm-node stops modifying it
+	// until its LV is repaired on the node. It is set once the LV has needed a repair.
+	ConditionReconciliationHalted = "ReconciliationHalted"
+	// HaltReasonRepairNeeded is the reason of the ReconciliationHalted condition while the LV needs a repair.
+	HaltReasonRepairNeeded = "RepairNeeded"
+	// HaltReasonRepaired is the reason of the ReconciliationHalted condition once the LV has been repaired.
+	HaltReasonRepaired = "Repaired"
+
+	// ConditionWarmedUp is the condition of a LogicalVolume provisioned with the warm-up parameter telling whether
+	// its warm-up Job has succeeded. The volume is published only to the pods of the Job until it is True.
+	ConditionWarmedUp = "WarmedUp"
+	// WarmUpReasonRunning is the reason of the WarmedUp condition while the warm-up Job is running.
+	WarmUpReasonRunning = "Running"
+	// WarmUpReasonSucceeded is the reason of the WarmedUp condition once the warm-up Job has succeeded.
+	WarmUpReasonSucceeded = "Succeeded"
+	// WarmUpReasonFailed is the reason of the WarmedUp condition once the warm-up Job has failed.
+	WarmUpReasonFailed = "Failed"
+	// WarmUpReasonInvalidTemplate is the reason of the WarmedUp condition when the warm-up Job cannot be created
+	// from its template.
+	WarmUpReasonInvalidTemplate = "InvalidTemplate"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -20,6 +68,11 @@ type LogicalVolumeSpec struct {
 	DeviceClass         string            `json:"deviceClass,omitempty"`
 	LvcreateOptionClass string            `json:"lvcreateOptionClass,omitempty"`
 
+	// 'lvcreateOptions' are passed to lvcreate after the options of the lvcreate-option-class.
+	// They must be allowed by the inline-options of the class in the lvmd configuration.
+	//+kubebuilder:validation:Optional
+	LvcreateOptions []string `json:"lvcreateOptions,omitempty"`
+
 	// 'source' specifies the logicalvolume name of the source; if present.
 	// This field is populated only when LogicalVolume has a source.
 	//+kubebuilder:validation:Optional
@@ -30,6 +83,31 @@ type LogicalVolumeSpec struct {
 	// This field is populated only when LogicalVolume has a source.
 	//+kubebuilder:validation:Optional
 	AccessType string `json:"accessType,omitempty"`
+
+	// 'provisioningType' overrides the type of the device class, either "thick" or "thin".
+	// A thin volume requires a thin pool in the volume group of the device class.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum=thick;thin
+	ProvisioningType string `json:"provisioningType,omitempty"`
+
+	// 'operation' requests an operation on the existing logical volume.
+	// Set to "merge" on a snapshot to merge it back into its source, which rolls the source back to the snapshot.
+	// The snapshot is removed from the volume group once the merge has completed.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum=merge
+	Operation string `json:"operation,omitempty"`
+
+	// 'wipeOnDelete' overrides the wipe-on-delete of the device class, either "none", "discard" or "zero".
+	// The volume is discarded or zeroed before it is removed, so that its data does not leak into volumes allocated later.
+	//+kubebuilder:validation:Optional
+	//+kubebuilder:validation:Enum=none;discard;zero
+	WipeOnDelete string `json:"wipeOnDelete,omitempty"`
+
+	// 'snapshotBeforeExpand' is the retention of the thin snapshot taken before each expansion, e.g. "24h".
+	// The snapshot is a rollback point in case growing the filesystem goes wrong, and is removed after the retention.
+	// No snapshot is taken if empty.
+	//+kubebuilder:validation:Optional
+	SnapshotBeforeExpand string `json:"snapshotBeforeExpand,omitempty"`
 }
 
 // LogicalVolumeStatus defines the observed state of LogicalVolume
@@ -40,11 +118,48 @@ type LogicalVolumeStatus struct {
 	Code        codes.Code         `json:"code,omitempty"`
 	Message     string             `json:"message,omitempty"`
 	CurrentSize *resource.Quantity `json:"currentSize,omitempty"`
+
+	// 'operation' reports the progress of the operation requested by spec.operation.
+	//+kubebuilder:validation:Optional
+	Operation *OperationStatus `json:"operation,omitempty"`
+
+	// 'allocatedSize' is the space actually allocated for the snapshot logical volume,
+	// i.e. the space reclaimed by deleting it.
+	//+kubebuilder:validation:Optional
+	AllocatedSize *resource.Quantity `json:"allocatedSize,omitempty"`
+
+	// 'origin' is the volume ID of the origin of the snapshot logical volume in LVM.
+	// It is empty once the origin has been removed, while spec.source still names the source LogicalVolume.
+	//+kubebuilder:validation:Optional
+	Origin string `json:"origin,omitempty"`
+
+	// 'conditions' explains the states of the LogicalVolume that need attention,
+	// e.g. "NodeDeleted" while spec.nodeName refers to a deleted node.
+	//+kubebuilder:validation:Optional
+	//+listType=map
+	//+listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// OperationStatus reports the progress of an operation on a LogicalVolume.
+type OperationStatus struct {
+	// 'name' is the name of the operation.
+	Name string `json:"name"`
+
+	// 'phase' is "InProgress" while the operation is running and "Completed" once it has completed.
+	Phase string `json:"phase"`
+
+	// 'progressPercent' is the progress of the operation in percent.
+	ProgressPercent int32 `json:"progressPercent"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.nodeName`
+//+kubebuilder:printcolumn:name="Size",type=string,JSONPath=`.status.currentSize`
+//+kubebuilder:printcolumn:name="Allocated",type=string,JSONPath=`.status.allocatedSize`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // LogicalVolume is the Schema for the logicalvolumes API
 type LogicalVolume struct {
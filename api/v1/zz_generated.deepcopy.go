@@ -5,9 +5,124 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReclaim) DeepCopyInto(out *CapacityReclaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReclaim.
+func (in *CapacityReclaim) DeepCopy() *CapacityReclaim {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReclaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityReclaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReclaimList) DeepCopyInto(out *CapacityReclaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CapacityReclaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReclaimList.
+func (in *CapacityReclaimList) DeepCopy() *CapacityReclaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReclaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CapacityReclaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReclaimSpec) DeepCopyInto(out *CapacityReclaimSpec) {
+	*out = *in
+	out.Target = in.Target.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReclaimSpec.
+func (in *CapacityReclaimSpec) DeepCopy() *CapacityReclaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReclaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityReclaimStatus) DeepCopyInto(out *CapacityReclaimStatus) {
+	*out = *in
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]ReclaimCandidate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SelectedSize != nil {
+		in, out := &in.SelectedSize, &out.SelectedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.ReclaimedSize != nil {
+		in, out := &in.ReclaimedSize, &out.ReclaimedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityReclaimStatus.
+func (in *CapacityReclaimStatus) DeepCopy() *CapacityReclaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityReclaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LogicalVolume) DeepCopyInto(out *LogicalVolume) {
 	*out = *in
@@ -71,6 +186,11 @@ func (in *LogicalVolumeList) DeepCopyObject() runtime.Object {
 func (in *LogicalVolumeSpec) DeepCopyInto(out *LogicalVolumeSpec) {
 	*out = *in
 	out.Size = in.Size.DeepCopy()
+	if in.LvcreateOptions != nil {
+		in, out := &in.LvcreateOptions, &out.LvcreateOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalVolumeSpec.
@@ -91,6 +211,23 @@ func (in *LogicalVolumeStatus) DeepCopyInto(out *LogicalVolumeStatus) {
 		x := (*in).DeepCopy()
 		*out = &x
 	}
+	if in.Operation != nil {
+		in, out := &in.Operation, &out.Operation
+		*out = new(OperationStatus)
+		**out = **in
+	}
+	if in.AllocatedSize != nil {
+		in, out := &in.AllocatedSize, &out.AllocatedSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalVolumeStatus.
@@ -102,3 +239,34 @@ func (in *LogicalVolumeStatus) DeepCopy() *LogicalVolumeStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
+func (in *OperationStatus) DeepCopy() *OperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReclaimCandidate) DeepCopyInto(out *ReclaimCandidate) {
+	*out = *in
+	out.Size = in.Size.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReclaimCandidate.
+func (in *ReclaimCandidate) DeepCopy() *ReclaimCandidate {
+	if in == nil {
+		return nil
+	}
+	out := new(ReclaimCandidate)
+	in.DeepCopyInto(out)
+	return out
+}
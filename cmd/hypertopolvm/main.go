@@ -5,9 +5,12 @@ import (
 	"os"
 	"path/filepath"
 
+	kubectltopolvm "github.com/topolvm/topolvm/cmd/kubectl-topolvm/app"
 	lvmd "github.com/topolvm/topolvm/cmd/lvmd/app"
 	controller "github.com/topolvm/topolvm/cmd/topolvm-controller/app"
 	node "github.com/topolvm/topolvm/cmd/topolvm-node/app"
+	plan "github.com/topolvm/topolvm/cmd/topolvm-plan/app"
+	preflight "github.com/topolvm/topolvm/cmd/topolvm-preflight/app"
 	scheduler "github.com/topolvm/topolvm/cmd/topolvm-scheduler/app"
 )
 
@@ -18,7 +21,10 @@ COMMAND:
     topolvm-controller:  TopoLVM CSI controller service.
     topolvm-node:        TopoLVM CSI node service.
     topolvm-scheduler:   Scheduler extender.
+    topolvm-plan:        Provisioning simulator for capacity planning.
+    topolvm-preflight:   Checks whether a node can run TopoLVM.
     lvmd:                gRPC service to manage LVM volumes.
+    kubectl-topolvm:     kubectl plugin to inspect the volumes.
 `)
 }
 
@@ -42,6 +48,12 @@ func main() {
 		node.Execute()
 	case "topolvm-controller":
 		controller.Execute()
+	case "topolvm-plan":
+		plan.Execute()
+	case "topolvm-preflight":
+		preflight.Execute()
+	case "kubectl-topolvm":
+		kubectltopolvm.Execute()
 	default:
 		usage()
 		os.Exit(1)
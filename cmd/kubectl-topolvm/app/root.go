@@ -0,0 +1,39 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/topolvm/topolvm"
+)
+
+var config struct {
+	kubeconfig string
+	namespace  string
+}
+
+var rootCmd = &cobra.Command{
+	Use:     "kubectl-topolvm",
+	Version: topolvm.Version,
+	Short:   "a kubectl plugin to inspect the volumes of TopoLVM",
+	Long: `A kubectl plugin to inspect the volumes of TopoLVM.
+
+Install it as "kubectl-topolvm" in PATH to run it as "kubectl topolvm".
+`,
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	fs := rootCmd.PersistentFlags()
+	fs.StringVar(&config.kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	fs.StringVarP(&config.namespace, "namespace", "n", "", "namespace of the object, the namespace of the current context by default")
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"github.com/spf13/cobra"
+	topolvmlegacyv1 "github.com/topolvm/topolvm/api/legacy/v1"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	clientwrapper "github.com/topolvm/topolvm/internal/client"
+	"github.com/topolvm/topolvm/internal/lineage"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	outputTree = "tree"
+	outputJSON = "json"
+)
+
+var treeOutput string
+
+var treeCmd = &cobra.Command{
+	Use:   "tree [TYPE/]NAME",
+	Short: "show the snapshots and clones derived from a volume",
+	Long: `Show the lineage of a volume: the volume it has been created from up to
+the root, and the snapshots and clones derived from them, across namespaces.
+
+NAME is a PersistentVolumeClaim. A VolumeSnapshot or a LogicalVolume can be
+given as volumesnapshot/NAME or logicalvolume/NAME.
+
+The volumes depending on the given volume are listed at the end.
+They are affected by deleting the volume or restoring it from a snapshot.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return runTree(cmd.Context(), cmd.OutOrStdout(), args[0])
+	},
+}
+
+func init() {
+	treeCmd.Flags().StringVarP(&treeOutput, "output", "o", outputTree, "output format, one of tree or json")
+	rootCmd.AddCommand(treeCmd)
+}
+
+func runTree(ctx context.Context, w io.Writer, arg string) error {
+	if treeOutput != outputTree && treeOutput != outputJSON {
+		return fmt.Errorf("unknown output format: %s", treeOutput)
+	}
+	kind, name, found := strings.Cut(arg, "/")
+	if !found {
+		kind, name = "persistentvolumeclaim", arg
+	}
+
+	c, namespace, err := newClient()
+	if err != nil {
+		return err
+	}
+	g, err := lineage.Load(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var v *lineage.Volume
+	switch strings.ToLower(kind) {
+	case "persistentvolumeclaim", "pvc":
+		v = g.ForClaim(namespace, name)
+	case "volumesnapshot", "vs":
+		v = g.ForVolumeSnapshot(namespace, name)
+	case "logicalvolume", "lv":
+		v = g.Volume(name)
+	default:
+		return fmt.Errorf("unknown type: %s", kind)
+	}
+	if v == nil {
+		return fmt.Errorf("no volume of TopoLVM found for %s", arg)
+	}
+
+	tree := g.Tree(v)
+	if treeOutput == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(tree)
+	}
+	return lineage.Print(w, tree)
+}
+
+// newClient returns the client for the cluster of the kubeconfig and the namespace of the objects.
+func newClient() (client.Client, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = config.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+	namespace := config.namespace
+	if namespace == "" {
+		namespace, _, err = clientConfig.Namespace()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(topolvmv1.AddToScheme(scheme))
+	utilruntime.Must(topolvmlegacyv1.AddToScheme(scheme))
+	utilruntime.Must(snapshotv1.AddToScheme(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", err
+	}
+	return clientwrapper.NewWrappedClient(c), namespace, nil
+}
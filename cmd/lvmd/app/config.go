@@ -2,10 +2,16 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/lvmd"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/yaml"
 )
@@ -14,10 +20,201 @@ import (
 type Config struct {
 	// SocketName is Unix domain socket name
 	SocketName string `json:"socket-name"`
+	// SocketPermissions sets the mode and the owner of the Unix domain socket and restricts the processes
+	// allowed to connect to it.
+	SocketPermissions *SocketPermissionsConfig `json:"socket-permissions,omitempty"`
 	// DeviceClasses is
 	DeviceClasses []*lvmdTypes.DeviceClass `json:"device-classes"`
 	// LvcreateOptionClasses are classes that define options for the lvcreate command
 	LvcreateOptionClasses []*lvmdTypes.LvcreateOptionClass `json:"lvcreate-option-classes"`
+	// LVMPath is the path of the lvm binary. It is detected if empty.
+	LVMPath string `json:"lvm-path,omitempty"`
+	// DmsetupPath is the path of the dmsetup binary. It is detected if empty.
+	DmsetupPath string `json:"dmsetup-path,omitempty"`
+	// NsenterPath is the path of the nsenter binary used when lvmd runs in a container. It is detected if empty.
+	NsenterPath string `json:"nsenter-path,omitempty"`
+	// CommandTimeouts are the timeouts of lvm commands by the sub-command, e.g. "lvcreate", or "dmsetup".
+	// The timeout of "default" applies to the other commands. Commands without a timeout are never killed.
+	CommandTimeouts map[string]metav1.Duration `json:"command-timeouts,omitempty"`
+	// RPCTimeouts override the deadlines lvmd sets on the RPCs without a deadline by the method name, e.g. "CreateLV".
+	// A zero timeout leaves the RPC unbounded.
+	RPCTimeouts map[string]metav1.Duration `json:"rpc-timeouts,omitempty"`
+	// Concurrency limits the requests changing the volumes of a volume group running at the same time.
+	Concurrency *ConcurrencyConfig `json:"concurrency,omitempty"`
+	// LockRetry configures retrying lvm commands failing on lock contention or busy devices.
+	LockRetry *LockRetryConfig `json:"lock-retry,omitempty"`
+	// UdevSettleTimeout is the maximum time to wait for udev after creating, removing or renaming
+	// logical volumes, so that their device nodes exist when lvmd returns. Zero disables waiting.
+	UdevSettleTimeout metav1.Duration `json:"udev-settle-timeout,omitempty"`
+	// FreeBytesCacheMaxAge is the maximum age of the free bytes returned by GetFreeBytes from the cache
+	// filled by Watch instead of running lvm. Zero disables the cache. The default is 10 seconds.
+	FreeBytesCacheMaxAge *metav1.Duration `json:"free-bytes-cache-max-age,omitempty"`
+	// ShutdownGracePeriod is the maximum time to wait for the requests in flight on SIGTERM before canceling them.
+	// New requests are rejected meanwhile. The default is 25 seconds.
+	ShutdownGracePeriod *metav1.Duration `json:"shutdown-grace-period,omitempty"`
+	// FailureLogInterval is the interval in which identical failures of lvm commands are logged only once.
+	// Zero logs every failure. The default is 1 minute.
+	FailureLogInterval *metav1.Duration `json:"failure-log-interval,omitempty"`
+	// MetricsBindAddress is the address to serve the Prometheus metrics of lvmd on, e.g. ":8080".
+	// Empty disables the metrics endpoint. topolvm-node serves the metrics of the embedded lvmd on its own endpoint.
+	MetricsBindAddress string `json:"metrics-bind-address,omitempty"`
+	// AuditLog is the path of the file lvmd appends a line of JSON to for every request changing the volumes
+	// or the volume groups. Empty disables the audit log.
+	AuditLog string `json:"audit-log,omitempty"`
+	// AutoActivation restricts the autoactivation by lvm on the host to keep it from activating the volumes
+	// of device-classes with activation-skip.
+	AutoActivation *AutoActivationConfig `json:"auto-activation,omitempty"`
+	// TCP configures a TCP listener with mutual TLS in addition to the Unix domain socket,
+	// e.g. for topolvm-node running in a virtual machine on the host of lvmd.
+	TCP *TCPConfig `json:"tcp,omitempty"`
+	// Vsock configures an AF_VSOCK listener in addition to the Unix domain socket,
+	// e.g. for topolvm-node running in a Kata Containers or Firecracker virtual machine on the host of lvmd.
+	Vsock *VsockConfig `json:"vsock,omitempty"`
+}
+
+// VsockConfig configures the AF_VSOCK listener of lvmd.
+type VsockConfig struct {
+	// Port is the vsock port to listen on.
+	Port uint32 `json:"port"`
+	// AllowedCIDs restricts the virtual machines allowed to connect by their context identifiers.
+	// All the virtual machines can connect if empty.
+	AllowedCIDs []uint32 `json:"allowed-cids,omitempty"`
+}
+
+// TCPConfig configures the TCP listener of lvmd. All the files are in PEM format.
+type TCPConfig struct {
+	// Address is the address to listen on, e.g. "0.0.0.0:9090".
+	Address string `json:"address"`
+	// CertFile is the path of the server certificate.
+	CertFile string `json:"cert-file"`
+	// KeyFile is the path of the private key of the server certificate.
+	KeyFile string `json:"key-file"`
+	// CAFile is the path of the CA certificates which must have signed the client certificates.
+	CAFile string `json:"ca-file"`
+}
+
+// SocketPermissionsConfig configures the Unix domain socket of lvmd, e.g. for CSI containers not running as root.
+type SocketPermissionsConfig struct {
+	// Mode is the file mode of the socket in octal, e.g. "0660". The mode is left as created if empty.
+	Mode string `json:"mode,omitempty"`
+	// UID is the owner of the socket. The owner is left as created if nil.
+	UID *int `json:"uid,omitempty"`
+	// GID is the group of the socket. The group is left as created if nil.
+	GID *int `json:"gid,omitempty"`
+	// AllowedUIDs and AllowedGIDs restrict the processes allowed to connect by their user or primary group,
+	// which are checked with SO_PEERCRED. All processes with access to the socket can connect if both are empty.
+	AllowedUIDs []uint32 `json:"allowed-uids,omitempty"`
+	AllowedGIDs []uint32 `json:"allowed-gids,omitempty"`
+}
+
+// FileMode parses Mode.
+func (c *SocketPermissionsConfig) FileMode() (os.FileMode, error) {
+	mode, err := strconv.ParseUint(c.Mode, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid socket mode %q: should be octal permission bits, e.g. 0660", c.Mode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// AutoActivationConfig configures the lvm configuration file written by lvmd on startup, which sets
+// auto_activation_volume_list to the volume groups not used by device-classes with activation-skip.
+type AutoActivationConfig struct {
+	// ConfigFile is the path of the file on the host, e.g. /etc/lvm/lvmlocal.conf.
+	// lvmd does not overwrite an existing file that it has not written.
+	ConfigFile string `json:"config-file"`
+	// ExtraVolumes are added to auto_activation_volume_list, e.g. volume groups created after lvmd starts.
+	ExtraVolumes []string `json:"extra-volumes,omitempty"`
+}
+
+// LockRetryConfig configures the capped exponential backoff of retrying lvm commands.
+// Fields not set keep the defaults.
+type LockRetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retries.
+	MaxRetries *int `json:"max-retries,omitempty"`
+	// InitialBackoff is the wait before the first retry, which doubles for each retry.
+	InitialBackoff *metav1.Duration `json:"initial-backoff,omitempty"`
+	// MaxBackoff caps the wait between retries.
+	MaxBackoff *metav1.Duration `json:"max-backoff,omitempty"`
+}
+
+// ConcurrencyConfig configures limiting the requests per volume group.
+type ConcurrencyConfig struct {
+	// MaxConcurrent is the number of requests running at the same time per volume group. 0 disables the limit.
+	// The default is the number of CPUs available to lvmd up to 4.
+	MaxConcurrent *int `json:"max-concurrent,omitempty"`
+	// MaxQueued is the number of requests waiting for a running one per volume group.
+	// The requests over it fail with RESOURCE_EXHAUSTED.
+	MaxQueued *int `json:"max-queued,omitempty"`
+}
+
+// CommandTimeoutDurations returns CommandTimeouts as durations.
+func (c *Config) CommandTimeoutDurations() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(c.CommandTimeouts))
+	for name, timeout := range c.CommandTimeouts {
+		timeouts[name] = timeout.Duration
+	}
+	return timeouts
+}
+
+// RPCTimeoutDurations returns the default deadlines of the RPCs overridden by RPCTimeouts.
+func (c *Config) RPCTimeoutDurations() map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(lvmd.DefaultRPCTimeouts)+len(c.RPCTimeouts))
+	for method, timeout := range lvmd.DefaultRPCTimeouts {
+		timeouts[method] = timeout
+	}
+	for method, timeout := range c.RPCTimeouts {
+		timeouts[method] = timeout.Duration
+	}
+	return timeouts
+}
+
+// ConcurrencyLimits returns the default limits of the requests per volume group overridden by Concurrency.
+func (c *Config) ConcurrencyLimits() lvmd.ConcurrencyLimits {
+	limits := lvmd.DefaultConcurrencyLimits()
+	if c.Concurrency == nil {
+		return limits
+	}
+	if c.Concurrency.MaxConcurrent != nil {
+		limits.MaxConcurrent = *c.Concurrency.MaxConcurrent
+	}
+	if c.Concurrency.MaxQueued != nil {
+		limits.MaxQueued = *c.Concurrency.MaxQueued
+	}
+	return limits
+}
+
+// ShutdownGracePeriodDuration returns ShutdownGracePeriod or the default one.
+func (c *Config) ShutdownGracePeriodDuration() time.Duration {
+	if c.ShutdownGracePeriod == nil {
+		return lvmd.DefaultShutdownGracePeriod
+	}
+	return c.ShutdownGracePeriod.Duration
+}
+
+// FailureLogIntervalDuration returns FailureLogInterval or the default one.
+func (c *Config) FailureLogIntervalDuration() time.Duration {
+	if c.FailureLogInterval == nil {
+		return command.FailureLogInterval
+	}
+	return c.FailureLogInterval.Duration
+}
+
+// LockRetryPolicy returns the policy of retrying lvm commands, which is the default one overridden by LockRetry.
+func (c *Config) LockRetryPolicy() command.RetryPolicy {
+	policy := command.LockRetry
+	if c.LockRetry == nil {
+		return policy
+	}
+	if c.LockRetry.MaxRetries != nil {
+		policy.MaxRetries = *c.LockRetry.MaxRetries
+	}
+	if c.LockRetry.InitialBackoff != nil {
+		policy.InitialBackoff = c.LockRetry.InitialBackoff.Duration
+	}
+	if c.LockRetry.MaxBackoff != nil {
+		policy.MaxBackoff = c.LockRetry.MaxBackoff.Duration
+	}
+	return policy
 }
 
 var config = &Config{
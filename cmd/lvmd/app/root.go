@@ -2,31 +2,41 @@ package app
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/cobra"
 	"github.com/topolvm/topolvm"
 	"github.com/topolvm/topolvm/internal/lvmd"
 	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/internal/tuning"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"k8s.io/klog/v2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var cfgFilePath string
 var zapOpts zap.Options
+var lvmPathFlag, dmsetupPathFlag, nsenterPathFlag string
+var socketModeFlag string
+var socketUIDFlag, socketGIDFlag int
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -51,6 +61,7 @@ volume group.
 func subMain(ctx context.Context) error {
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
 	logger := log.FromContext(ctx)
+	tuning.SetMaxProcs(logger)
 
 	if err := loadConfFile(ctx, cfgFilePath); err != nil {
 		return err
@@ -59,6 +70,25 @@ func subMain(ctx context.Context) error {
 	if err := lvmd.ValidateDeviceClasses(config.DeviceClasses); err != nil {
 		return err
 	}
+	if err := lvmd.ValidateLvcreateOptionClasses(config.LvcreateOptionClasses); err != nil {
+		return err
+	}
+
+	command.LVMPath = firstNonEmpty(lvmPathFlag, config.LVMPath)
+	command.DmsetupPath = firstNonEmpty(dmsetupPathFlag, config.DmsetupPath)
+	command.NsenterPath = firstNonEmpty(nsenterPathFlag, config.NsenterPath)
+	lvmPath, dmsetupPath, nsenterPath := command.BinaryPaths()
+	logger.Info("using binaries", "lvm", lvmPath, "dmsetup", dmsetupPath, "nsenter", nsenterPath)
+	command.CommandTimeouts = config.CommandTimeoutDurations()
+	command.LockRetry = config.LockRetryPolicy()
+	command.UdevSettleTimeout = config.UdevSettleTimeout.Duration
+	command.FailureLogInterval = config.FailureLogIntervalDuration()
+	if config.FreeBytesCacheMaxAge != nil {
+		lvmd.FreeBytesCacheMaxAge = config.FreeBytesCacheMaxAge.Duration
+	}
+	if _, err := command.DetectVersion(ctx); err != nil {
+		logger.Error(err, "failed to detect lvm version, using the features of all supported versions")
+	}
 
 	vgs, err := command.ListVolumeGroups(ctx)
 	if err != nil {
@@ -67,13 +97,19 @@ func subMain(ctx context.Context) error {
 	}
 
 	for _, dc := range config.DeviceClasses {
-		vg, err := command.SearchVolumeGroupList(vgs, dc.VolumeGroup)
-		if err != nil {
-			logger.Error(err, "volume group not found", "volume_group", dc.VolumeGroup)
-			return err
+		if dc.Type == lvmdTypes.TypeRaw {
+			// the devices of raw device-classes are discovered on use.
+			continue
+		}
+		for _, name := range lvmd.VolumeGroupNames(dc) {
+			if _, err := command.SearchVolumeGroupList(vgs, name); err != nil {
+				logger.Error(err, "volume group not found", "volume_group", name)
+				return err
+			}
 		}
 
 		if dc.Type == lvmdTypes.TypeThin {
+			vg, _ := command.SearchVolumeGroupList(vgs, dc.VolumeGroup)
 			_, err = vg.FindPool(ctx, dc.ThinPoolConfig.Name)
 			if err != nil {
 				logger.Error(err, "Thin pool not found:", "thinpool", dc.ThinPoolConfig.Name)
@@ -82,6 +118,21 @@ func subMain(ctx context.Context) error {
 		}
 	}
 
+	if config.AutoActivation != nil {
+		err := lvmd.ConfigureAutoActivation(ctx, config.AutoActivation.ConfigFile, config.DeviceClasses, config.AutoActivation.ExtraVolumes)
+		if err != nil {
+			logger.Error(err, "failed to restrict autoactivation", "file", config.AutoActivation.ConfigFile)
+			return err
+		}
+	}
+	if _, err := lvmd.CheckAutoActivation(ctx, config.DeviceClasses); err != nil {
+		logger.Error(err, "failed to check autoactivation")
+	}
+	if err := lvmd.RecoverSnapshots(ctx, config.DeviceClasses); err != nil {
+		logger.Error(err, "failed to recover interrupted snapshots")
+		return err
+	}
+
 	// UNIX domain socket file should be removed before listening.
 	err = os.Remove(config.SocketName)
 	if err != nil && !os.IsNotExist(err) {
@@ -92,24 +143,104 @@ func subMain(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	grpcServer := grpc.NewServer()
+	perm := socketPermissions()
+	if err := setSocketPermissions(config.SocketName, perm); err != nil {
+		logger.Error(err, "failed to set the permissions of the socket", "socket", config.SocketName)
+		return err
+	}
+	lis = lvmd.RestrictPeers(lis, perm.AllowedUIDs, perm.AllowedGIDs, logger.WithName("socket"))
 	dcm := lvmd.NewDeviceClassManager(config.DeviceClasses)
 	ocm := lvmd.NewLvcreateOptionClassManager(config.LvcreateOptionClasses)
-	vgService, notifier := lvmd.NewVGService(dcm)
-	proto.RegisterVGServiceServer(grpcServer, vgService)
-	proto.RegisterLVServiceServer(grpcServer, lvmd.NewLVService(dcm, ocm, notifier))
-	grpc_health_v1.RegisterHealthServer(grpcServer, lvmd.NewHealthService())
+	vgService, notifier := lvmd.NewVGService(dcm, ocm)
+	lvService := lvmd.NewLVService(dcm, ocm, notifier)
+	healthService := lvmd.NewHealthService(dcm)
+	deadlines := lvmd.DeadlineInterceptor(config.RPCTimeoutDurations())
+	// the requests wait for the limit within their deadlines.
+	limiter := lvmd.ConcurrencyInterceptor(dcm, config.ConcurrencyLimits())
+	// the requests in flight are waited for on shutdown, while new ones are rejected before queuing for the limit.
+	shutdown := lvmd.NewShutdownTracker()
+	unaryInterceptors := []grpc.UnaryServerInterceptor{shutdown.UnaryInterceptor(), deadlines, limiter}
+	streamInterceptors := []grpc.StreamServerInterceptor{shutdown.StreamInterceptor()}
+	if config.AuditLog != "" {
+		f, err := os.OpenFile(config.AuditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			logger.Error(err, "failed to open the audit log", "path", config.AuditLog)
+			return err
+		}
+		defer f.Close()
+		audit := lvmd.NewAuditLogger(f, logger.WithName("audit"))
+		// the audit log records the requests rejected by the other interceptors too.
+		unaryInterceptors = append([]grpc.UnaryServerInterceptor{audit.UnaryInterceptor()}, unaryInterceptors...)
+		streamInterceptors = append([]grpc.StreamServerInterceptor{audit.StreamInterceptor()}, streamInterceptors...)
+	}
+	newServer := func(opts ...grpc.ServerOption) *grpc.Server {
+		opts = append(opts, grpc.ChainUnaryInterceptor(unaryInterceptors...), grpc.ChainStreamInterceptor(streamInterceptors...))
+		grpcServer := grpc.NewServer(opts...)
+		proto.RegisterVGServiceServer(grpcServer, vgService)
+		proto.RegisterLVServiceServer(grpcServer, lvService)
+		grpc_health_v1.RegisterHealthServer(grpcServer, healthService)
+		return grpcServer
+	}
+	// the credentials of the peer processes are recorded in the audit log.
+	servers := []*grpc.Server{newServer(grpc.Creds(lvmd.UnixPeerCredentials()))}
+	listeners := []net.Listener{lis}
+
+	if tcp := config.TCP; tcp != nil {
+		tlsConfig, err := lvmd.ServerTLSConfig(tcp.CertFile, tcp.KeyFile, tcp.CAFile)
+		if err != nil {
+			logger.Error(err, "failed to configure TLS of the TCP listener")
+			return err
+		}
+		tcpLis, err := net.Listen("tcp", tcp.Address)
+		if err != nil {
+			return err
+		}
+		logger.Info("listening on TCP with mutual TLS", "address", tcpLis.Addr().String())
+		servers = append(servers, newServer(grpc.Creds(credentials.NewTLS(tlsConfig))))
+		listeners = append(listeners, tcpLis)
+	}
+
+	if vsock := config.Vsock; vsock != nil {
+		vsockLis, err := lvmd.ListenVsock(vsock.Port, vsock.AllowedCIDs)
+		if err != nil {
+			logger.Error(err, "failed to listen on vsock", "port", vsock.Port)
+			return err
+		}
+		logger.Info("listening on vsock", "port", vsock.Port, "allowedCIDs", vsock.AllowedCIDs)
+		servers = append(servers, newServer())
+		listeners = append(listeners, vsockLis)
+	}
+
+	if config.MetricsBindAddress != "" {
+		metricsLis, err := net.Listen("tcp", config.MetricsBindAddress)
+		if err != nil {
+			logger.Error(err, "failed to listen for metrics", "address", config.MetricsBindAddress)
+			return err
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}))
+		metricsServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		logger.Info("serving metrics", "address", metricsLis.Addr().String())
+		go func() {
+			if err := metricsServer.Serve(metricsLis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error(err, "failed to serve metrics")
+			}
+		}()
+		defer metricsServer.Close()
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	stopped := make(chan struct{})
 	go func() {
+		defer close(stopped)
 		ticker := time.NewTicker(10 * time.Minute)
 		for {
 			select {
 			case <-ctx.Done():
 				ticker.Stop()
-				grpcServer.GracefulStop()
+				stopServers(logger, servers, shutdown, config.ShutdownGracePeriodDuration())
 				return
 			case <-ticker.C:
 				notifier()
@@ -117,7 +248,84 @@ func subMain(ctx context.Context) error {
 		}
 	}()
 
-	return grpcServer.Serve(lis)
+	// the first server to stop, e.g. on a signal, stops the others.
+	errCh := make(chan error, len(servers))
+	for i := range servers {
+		grpcServer, lis := servers[i], listeners[i]
+		go func() {
+			errCh <- grpcServer.Serve(lis)
+		}()
+	}
+	err = <-errCh
+	stop()
+	// Serve returns as soon as the servers stop accepting connections, so wait for the requests in flight.
+	<-stopped
+	return err
+}
+
+// stopServers stops the servers from accepting new connections and RPCs, and waits for the RPCs in flight
+// for up to gracePeriod, so that a restart does not leave logical volumes created or removed halfway.
+// The RPCs still running after it, and the streams such as Watch, are canceled.
+func stopServers(logger logr.Logger, servers []*grpc.Server, shutdown *lvmd.ShutdownTracker, gracePeriod time.Duration) {
+	for _, grpcServer := range servers {
+		go grpcServer.GracefulStop()
+	}
+	logger.Info("shutting down, waiting for the requests in flight", "grace_period", gracePeriod.String())
+	if remaining := shutdown.Shutdown(gracePeriod); remaining > 0 {
+		logger.Info("canceling the requests still running after the grace period", "requests", remaining)
+	}
+	for _, grpcServer := range servers {
+		grpcServer.Stop()
+	}
+}
+
+// socketPermissions returns the socket-permissions of the config file overridden by the command-line flags.
+func socketPermissions() SocketPermissionsConfig {
+	var perm SocketPermissionsConfig
+	if config.SocketPermissions != nil {
+		perm = *config.SocketPermissions
+	}
+	perm.Mode = firstNonEmpty(socketModeFlag, perm.Mode)
+	if socketUIDFlag >= 0 {
+		perm.UID = &socketUIDFlag
+	}
+	if socketGIDFlag >= 0 {
+		perm.GID = &socketGIDFlag
+	}
+	return perm
+}
+
+// setSocketPermissions sets the mode and the owner of the socket.
+func setSocketPermissions(socket string, perm SocketPermissionsConfig) error {
+	if perm.Mode != "" {
+		mode, err := perm.FileMode()
+		if err != nil {
+			return err
+		}
+		if err := os.Chmod(socket, mode); err != nil {
+			return err
+		}
+	}
+	if perm.UID == nil && perm.GID == nil {
+		return nil
+	}
+	uid, gid := -1, -1
+	if perm.UID != nil {
+		uid = *perm.UID
+	}
+	if perm.GID != nil {
+		gid = *perm.GID
+	}
+	return os.Chown(socket, uid, gid)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -133,6 +341,12 @@ func Execute() {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFilePath, "config", filepath.Join("/etc", "topolvm", "lvmd.yaml"), "config file")
 	rootCmd.PersistentFlags().BoolVar(&command.Containerized, "container", false, "Run within a container")
+	rootCmd.PersistentFlags().StringVar(&lvmPathFlag, "lvm-path", "", "Path of the lvm binary, detected if empty. Overrides lvm-path of the config file")
+	rootCmd.PersistentFlags().StringVar(&dmsetupPathFlag, "dmsetup-path", "", "Path of the dmsetup binary, detected if empty. Overrides dmsetup-path of the config file")
+	rootCmd.PersistentFlags().StringVar(&nsenterPathFlag, "nsenter-path", "", "Path of the nsenter binary, detected if empty. Overrides nsenter-path of the config file")
+	rootCmd.Flags().StringVar(&socketModeFlag, "socket-mode", "", "File mode of the socket in octal, e.g. 0660. Overrides socket-permissions.mode of the config file")
+	rootCmd.Flags().IntVar(&socketUIDFlag, "socket-uid", -1, "Owner of the socket. Overrides socket-permissions.uid of the config file")
+	rootCmd.Flags().IntVar(&socketGIDFlag, "socket-gid", -1, "Group of the socket. Overrides socket-permissions.gid of the config file")
 
 	goflags := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(goflags)
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations accepted by time.ParseDuration, e.g. "1m30s".
+const durationPattern = `^-?(0|([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))+$`
+
+// schemaEnums are the values of the string types of the config which only take certain values.
+var schemaEnums = map[reflect.Type][]string{
+	reflect.TypeOf(lvmdTypes.DeviceType("")): {
+		string(lvmdTypes.TypeThick), string(lvmdTypes.TypeThin), string(lvmdTypes.TypeRaw),
+	},
+	reflect.TypeOf(lvmdTypes.RAIDType("")): {
+		string(lvmdTypes.TypeRAID1), string(lvmdTypes.TypeRAID5), string(lvmdTypes.TypeRAID10),
+	},
+	reflect.TypeOf(lvmdTypes.CacheMode("")): {
+		string(lvmdTypes.CacheModeWritethrough), string(lvmdTypes.CacheModeWriteback),
+	},
+	reflect.TypeOf(lvmdTypes.WipePolicy("")): {
+		string(lvmdTypes.WipeNone), string(lvmdTypes.WipeDiscard), string(lvmdTypes.WipeZero),
+	},
+	reflect.TypeOf(lvmdTypes.VolumeGroupPolicy("")): {
+		string(lvmdTypes.VolumeGroupPolicyMostFree), string(lvmdTypes.VolumeGroupPolicyRoundRobin),
+	},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the lvmd configuration file",
+	Long: `Print the JSON Schema of the lvmd configuration file.
+
+The schema is generated from the types of the configuration, so that the
+configuration files can be validated before they are deployed to the nodes,
+e.g. in infrastructure-as-code pipelines. Unknown fields, which lvmd ignores,
+are rejected by the schema to catch misspelled fields.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		schema, err := configSchema()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(schema))
+		return err
+	},
+}
+
+// configSchema returns the JSON Schema of Config.
+func configSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: make(map[string]any)}
+	root := g.schema(reflect.TypeOf(Config{}))
+	root["$schema"] = jsonSchemaDraft
+	root["title"] = "lvmd configuration"
+	root["$defs"] = g.defs
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaGenerator generates JSON Schemas of Go types as encoding/json and sigs.k8s.io/yaml marshal them.
+// Named structs are put in defs and referenced.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(metav1.Duration{}) {
+		return map[string]any{"type": "string", "pattern": durationPattern}
+	}
+	if values, ok := schemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" || t == reflect.TypeOf(Config{}) {
+			return g.object(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// reserve the name first for recursive types.
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.object(t)
+		}
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	}
+	// other kinds such as interfaces accept any value.
+	return map[string]any{}
+}
+
+func (g *schemaGenerator) object(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.addProperties(properties, t)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func (g *schemaGenerator) addProperties(properties map[string]any, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addProperties(properties, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
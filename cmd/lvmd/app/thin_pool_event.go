@@ -0,0 +1,103 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var thinPoolEventCmd = &cobra.Command{
+	Use:   "thin-pool-event VG/POOL",
+	Short: "Report a thin pool event of dmeventd to lvmd",
+	Long: `Report a thin pool event of dmeventd to lvmd.
+
+Set this command to "thin_command" in the "dmeventd" section of lvm.conf.
+dmeventd runs it with the name of the thin pool when the data or metadata
+usage of the pool crosses 50% and every 5% above. The pool is extended by
+"lvextend --use-policies" as the default command of dmeventd does, then lvmd
+is notified of the usage.
+`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return thinPoolEventSubMain(cmd.Context(), args[0])
+	},
+}
+
+func thinPoolEventSubMain(ctx context.Context, name string) error {
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&zapOpts)))
+	ctx = log.IntoContext(ctx, ctrl.Log.WithName("thin-pool-event"))
+
+	vgName, poolName, ok := strings.Cut(name, "/")
+	if !ok {
+		return fmt.Errorf("thin pool should be given as VG/POOL: %s", name)
+	}
+	if err := loadConfFile(ctx, cfgFilePath); err != nil {
+		return err
+	}
+
+	vg, err := command.FindVolumeGroup(ctx, vgName)
+	if err != nil {
+		return err
+	}
+	pool, err := vg.FindPool(ctx, poolName)
+	if err != nil {
+		return err
+	}
+	extendErr := pool.ExtendByPolicy(ctx)
+
+	req := &proto.ReportThinPoolEventRequest{VolumeGroup: vgName, ThinPool: poolName}
+	if req.DataPercent, err = percentFromEnv("DMEVENTD_THIN_POOL_DATA"); err != nil {
+		return errors.Join(extendErr, err)
+	}
+	if req.MetadataPercent, err = percentFromEnv("DMEVENTD_THIN_POOL_METADATA"); err != nil {
+		return errors.Join(extendErr, err)
+	}
+
+	dialer := &net.Dialer{}
+	dialFunc := func(ctx context.Context, a string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", a)
+	}
+	conn, err := grpc.Dial(
+		config.SocketName,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialFunc),
+	)
+	if err != nil {
+		return errors.Join(extendErr, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_, err = proto.NewVGServiceClient(conn).ReportThinPoolEvent(ctx, req)
+	return errors.Join(extendErr, err)
+}
+
+// percentFromEnv parses the usage in percent set by dmeventd, which may be suffixed with "%".
+func percentFromEnv(key string) (float64, error) {
+	value := strings.TrimSuffix(strings.TrimSpace(os.Getenv(key)), "%")
+	if value == "" {
+		return 0, fmt.Errorf("%s is not set, the command should be run by dmeventd", key)
+	}
+	percent, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return percent, nil
+}
+
+func init() {
+	rootCmd.AddCommand(thinPoolEventCmd)
+}
@@ -8,10 +8,12 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/explain"
 	"github.com/topolvm/topolvm/pkg/driver"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
 )
 
 const (
@@ -29,9 +31,22 @@ const (
 	// Btrfs changes its minimum allocation size based on various underlying device block settings and the host OS,
 	// but 200Mi seemed to be safe after some experimentation.
 	DefaultMinimumAllocationSizeBtrfs = "200Mi"
+
+	// maxAutoReconciles caps the number of objects reconciled at the same time by each controller
+	// unless --max-concurrent-reconciles is given.
+	maxAutoReconciles = 8
 )
 
+// Config represents the configuration file of topolvm-controller given by --config.
+type Config struct {
+	// MaximumAllocation is the maximum sizes of the volumes.
+	MaximumAllocation driver.MaximumAllocationSettings `json:"maximum-allocation"`
+	// AllocationUnit is the units the sizes of the volumes are rounded up to.
+	AllocationUnit driver.AllocationUnitSettings `json:"allocation-unit"`
+}
+
 var config struct {
+	configFile                  string
 	csiSocket                   string
 	metricsAddr                 string
 	secureMetricsServer         bool
@@ -46,6 +61,22 @@ var config struct {
 	leaderElectionRenewDeadline time.Duration
 	leaderElectionRetryPeriod   time.Duration
 	skipNodeFinalize            bool
+	scaleDownProtection         bool
+	deletedNodePolicy           string
+	deletedNodeTTL              time.Duration
+	capacityReclaim             bool
+	lvmdConfigRollouts          []string
+	lvmdConfigRolloutTopology   string
+	idempotencyAudit            int
+	maxConcurrentReconciles     int
+	lvmdMaxConcurrent           int
+	capacityAPIAddr             string
+	capacityAlertRule           string
+	capacityAlertRuleLabels     map[string]string
+	capacityAlertWarning        float64
+	capacityAlertCritical       float64
+	capacityAlertThresholds     []string
+	explainConfig               bool
 	zapOpts                     zap.Options
 	controllerServerSettings    driver.ControllerServerSettings
 }
@@ -59,7 +90,17 @@ It also works as a custom Kubernetes controller.`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
-		return subMain()
+		files, err := loadConfigFile(config.configFile)
+		if err != nil {
+			return err
+		}
+		if !cmd.Flags().Changed("max-volume-deletions-per-node") {
+			config.controllerServerSettings.DeletionPacingSettings.MaxPerNode = driver.MaxDeletionsPerNode(config.lvmdMaxConcurrent)
+		}
+		if config.explainConfig {
+			return explain.Write(os.Stdout, cmd.Flags(), files...)
+		}
+		return subMain(cmd.Flags(), files)
 	},
 }
 
@@ -72,9 +113,40 @@ func Execute() {
 	}
 }
 
+// loadConfigFile loads the configuration file at path into the settings of the controller server,
+// and returns the settings of the file to be explained. An empty path loads nothing.
+func loadConfigFile(path string) ([]map[string]explain.Setting, error) {
+	if path == "" {
+		return nil, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Config
+	if err := yaml.UnmarshalStrict(b, &file); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := file.MaximumAllocation.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if err := file.AllocationUnit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	config.controllerServerSettings.MaximumAllocationSettings = file.MaximumAllocation
+	config.controllerServerSettings.AllocationUnitSettings = file.AllocationUnit
+
+	settings, err := explain.File(path, file)
+	if err != nil {
+		return nil, err
+	}
+	return []map[string]explain.Setting{settings}, nil
+}
+
 //nolint:lll
 func init() {
 	fs := rootCmd.Flags()
+	fs.StringVar(&config.configFile, "config", "", "Path of the configuration file, e.g. of the maximum allocation sizes and the allocation units")
 	fs.StringVar(&config.csiSocket, "csi-socket", topolvm.DefaultCSISocket, "UNIX domain socket filename for CSI")
 	fs.StringVar(&config.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	fs.BoolVar(&config.secureMetricsServer, "secure-metrics-server", false, "Secures the metrics server")
@@ -89,6 +161,26 @@ func init() {
 	fs.DurationVar(&config.leaderElectionRenewDeadline, "leader-election-renew-deadline", 10*time.Second, "Duration that the acting controlplane will retry refreshing leadership before giving up. This is measured against time of last observed ack.")
 	fs.DurationVar(&config.leaderElectionRetryPeriod, "leader-election-retry-period", 2*time.Second, "Duration the LeaderElector clients should wait between tries of actions.")
 	fs.BoolVar(&config.skipNodeFinalize, "skip-node-finalize", false, "skips automatic cleanup of PhysicalVolumeClaims when a Node is deleted")
+	fs.BoolVar(&config.scaleDownProtection, "autoscaler-scale-down-protection", false, "Annotates nodes hosting LogicalVolumes so that cluster-autoscaler does not scale them down")
+	fs.StringVar(&config.deletedNodePolicy, "deleted-node-policy", "orphan", "How to handle LogicalVolumes whose node has been deleted: orphan, force-delete or migrate")
+	fs.DurationVar(&config.deletedNodeTTL, "deleted-node-ttl", time.Hour, "How long the node of a LogicalVolume must have been deleted before the LogicalVolume is deleted or migrated by the deleted-node-policy")
+	fs.BoolVar(&config.capacityReclaim, "capacity-reclaim", false, "Enable the controller freeing the space requested by CapacityReclaims by selecting volumes and asking their owners to migrate or delete them")
+	fs.BoolVar(&config.controllerServerSettings.VolumeWarmUp, "volume-warm-up", false, "Enable the controller running the warm-up Jobs of the volumes provisioned with the "+topolvm.GetWarmUpKey()+" parameter before they are published to other pods")
+	fs.StringArrayVar(&config.lvmdConfigRollouts, "lvmd-config-rollout", nil, "Roll out changes of an lvmd ConfigMap by restarting the pods of a DaemonSet with OnDelete update strategy, in the form of NAMESPACE/CONFIGMAP=DAEMONSET. Can be specified multiple times.")
+	fs.StringVar(&config.lvmdConfigRolloutTopology, "lvmd-config-rollout-topology-key", "kubernetes.io/hostname", "Node label to group nodes into failure domains restarted one at a time when rolling out lvmd configuration.")
+	fs.IntVar(&config.maxConcurrentReconciles, "max-concurrent-reconciles", 0, fmt.Sprintf("Number of objects each controller reconciles at the same time. 0 uses the number of CPUs available up to %d", maxAutoReconciles))
+	fs.IntVar(&config.idempotencyAudit, "csi-idempotency-audit", 0, "Number of recent CSI calls to record for detecting non-idempotent replays, served at "+driver.IdempotencyAuditPath+" of the metrics endpoint. Meant for testing. 0 disables the audit")
+	fs.StringVar(&config.capacityAPIAddr, "capacity-api-bind-address", "", "The address the read-only capacity API for external schedulers and cluster autoscalers, and the lineage API of the volumes bind to. Empty disables the APIs")
+	fs.StringVar(&config.capacityAlertRule, "capacity-alert-rule", "", "Generate a PrometheusRule of the Prometheus operator alerting on the usage of the device-classes published by the nodes, in the form of NAMESPACE/NAME. Empty disables the alerts")
+	fs.StringToStringVar(&config.capacityAlertRuleLabels, "capacity-alert-rule-labels", nil, "Labels of the PrometheusRule, e.g. to be discovered by Prometheus")
+	fs.Float64Var(&config.capacityAlertWarning, "capacity-alert-warning-percent", 80, "Usage of a device-class in percent to fire the warning alert at. 0 disables the alert")
+	fs.Float64Var(&config.capacityAlertCritical, "capacity-alert-critical-percent", 90, "Usage of a device-class in percent to fire the critical alert at. 0 disables the alert")
+	fs.StringArrayVar(&config.capacityAlertThresholds, "capacity-alert-threshold", nil, "Thresholds of the alerts of a device-class in the form of DEVICE_CLASS=WARNING,CRITICAL in percent. Can be specified multiple times.")
+	fs.IntVar(&config.controllerServerSettings.DeletionPacingSettings.MaxPerNode, "max-volume-deletions-per-node", driver.DefaultMaxDeletionsPerNode, "Number of volumes of a node deleted at the same time. Defaults to half of --lvmd-max-concurrent so that the deletions leave room for creating volumes. 0 disables the limit")
+	fs.IntVar(&config.lvmdMaxConcurrent, "lvmd-max-concurrent", driver.DefaultLVMDMaxConcurrent, "The max-concurrent concurrency limit of lvmd on the nodes, which the default of --max-volume-deletions-per-node is derived from. 0 means lvmd has no limit")
+	fs.IntVar(&config.controllerServerSettings.DeletionPacingSettings.MaxTotal, "max-volume-deletions", 32, "Number of volumes deleted at the same time across the nodes. 0 disables the limit")
+	fs.DurationVar(&config.controllerServerSettings.DeletionPacingSettings.Interval, "volume-deletion-interval", 100*time.Millisecond, "Minimum time between starting two deletions of volumes on a node")
+	fs.BoolVar(&config.explainConfig, "explain-config", false, "Print the effective configuration with the source of each setting in YAML and exit. It is also served at "+explain.Path+" of the metrics endpoint with --secure-metrics-server")
 
 	driver.QuantityVar(fs, &config.controllerServerSettings.MinimumAllocationSettings.Block,
 		"minimum-allocation-block",
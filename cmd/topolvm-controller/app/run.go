@@ -4,15 +4,23 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"github.com/spf13/pflag"
 	"github.com/topolvm/topolvm"
 	topolvmlegacyv1 "github.com/topolvm/topolvm/api/legacy/v1"
 	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	clientwrapper "github.com/topolvm/topolvm/internal/client"
+	"github.com/topolvm/topolvm/internal/explain"
 	"github.com/topolvm/topolvm/internal/hook"
+	"github.com/topolvm/topolvm/internal/lineage"
 	"github.com/topolvm/topolvm/internal/runners"
+	"github.com/topolvm/topolvm/internal/tuning"
 	"github.com/topolvm/topolvm/pkg/controller"
 	"github.com/topolvm/topolvm/pkg/driver"
 	"google.golang.org/grpc"
@@ -22,6 +30,7 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -38,6 +47,7 @@ var (
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(snapshotv1.AddToScheme(scheme))
 
 	utilruntime.Must(topolvmv1.AddToScheme(scheme))
 	utilruntime.Must(topolvmlegacyv1.AddToScheme(scheme))
@@ -47,8 +57,13 @@ func init() {
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers,verbs=get;list;watch
 
 // Run builds and starts the manager with leader election.
-func subMain() error {
+func subMain(fs *pflag.FlagSet, files []map[string]explain.Setting) error {
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&config.zapOpts)))
+	tuning.SetMaxProcs(setupLog)
+
+	if err := config.controllerServerSettings.DeletionPacingSettings.Validate(); err != nil {
+		return err
+	}
 
 	cfg, err := ctrl.GetConfig()
 	if err != nil {
@@ -66,9 +81,18 @@ func subMain() error {
 	metricsServerOptions := metricsserver.Options{
 		BindAddress: config.metricsAddr,
 	}
+	metricsServerOptions.ExtraHandlers = make(map[string]http.Handler)
 	if config.secureMetricsServer {
 		metricsServerOptions.SecureServing = true
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
+		// the configuration is served only to the clients authorized by the filter.
+		metricsServerOptions.ExtraHandlers[explain.Path] = explain.Handler(fs, files...)
+	}
+	var interceptors []grpc.UnaryServerInterceptor
+	if config.idempotencyAudit > 0 {
+		auditor := driver.NewIdempotencyAuditor(config.idempotencyAudit)
+		interceptors = append(interceptors, auditor.Intercept)
+		metricsServerOptions.ExtraHandlers[driver.IdempotencyAuditPath] = auditor
 	}
 
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
@@ -81,6 +105,9 @@ func subMain() error {
 		RenewDeadline:           &config.leaderElectionRenewDeadline,
 		RetryPeriod:             &config.leaderElectionRetryPeriod,
 		LeaseDuration:           &config.leaderElectionLeaseDuration,
+		Controller: ctrlconfig.Controller{
+			MaxConcurrentReconciles: tuning.Workers(config.maxConcurrentReconciles, maxAutoReconciles),
+		},
 		WebhookServer: webhook.NewServer(webhook.Options{
 			Host:    hookHost,
 			Port:    hookPort,
@@ -100,22 +127,81 @@ func subMain() error {
 		wh := mgr.GetWebhookServer()
 		wh.Register("/pod/mutate", hook.PodMutator(client, apiReader, dec))
 		wh.Register("/pvc/mutate", hook.PVCMutator(client, apiReader, dec))
+		wh.Register("/storageclass/validate", hook.StorageClassValidator(client, dec))
 		if err := mgr.AddReadyzCheck("webhook", wh.StartedChecker()); err != nil {
 			return err
 		}
 	}
 
 	// register controllers
-	if err := controller.SetupNodeReconciler(mgr, client, config.skipNodeFinalize); err != nil {
+	if err := controller.SetupNodeReconciler(mgr, client, config.skipNodeFinalize, config.scaleDownProtection); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Node")
 		return err
 	}
 
+	switch policy := controller.DeletedNodePolicy(config.deletedNodePolicy); policy {
+	case controller.DeletedNodeOrphan, controller.DeletedNodeForceDelete, controller.DeletedNodeMigrate:
+		if err := controller.SetupDeletedNodeReconciler(mgr, client, policy, config.deletedNodeTTL); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "DeletedNode")
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid deleted-node-policy %q, must be orphan, force-delete or migrate", config.deletedNodePolicy)
+	}
+
 	if err := controller.SetupPersistentVolumeClaimReconciler(mgr, client, apiReader); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "PersistentVolumeClaim")
 		return err
 	}
 
+	if config.capacityReclaim {
+		if topolvm.UseLegacy() {
+			return fmt.Errorf("capacity-reclaim is not supported with the legacy API group %s", topolvmlegacyv1.GroupVersion.Group)
+		}
+		if err := controller.SetupCapacityReclaimReconciler(mgr, client); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CapacityReclaim")
+			return err
+		}
+	}
+
+	if config.controllerServerSettings.VolumeWarmUp {
+		if topolvm.UseLegacy() {
+			return fmt.Errorf("volume-warm-up is not supported with the legacy API group %s", topolvmlegacyv1.GroupVersion.Group)
+		}
+		if err := controller.SetupWarmUpReconciler(mgr, client, apiReader); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "WarmUp")
+			return err
+		}
+	}
+
+	if len(config.lvmdConfigRollouts) != 0 {
+		daemonSets, err := parseLVMdConfigRollouts(config.lvmdConfigRollouts)
+		if err != nil {
+			return err
+		}
+		if err := controller.SetupLVMdConfigRolloutReconciler(mgr, client, daemonSets, config.lvmdConfigRolloutTopology); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "LVMdConfigRollout")
+			return err
+		}
+	}
+
+	if config.capacityAlertRule != "" {
+		namespace, name, ok := strings.Cut(config.capacityAlertRule, "/")
+		if !ok || namespace == "" || name == "" {
+			return fmt.Errorf("invalid capacity-alert-rule %q, must be NAMESPACE/NAME", config.capacityAlertRule)
+		}
+		thresholds, err := parseCapacityAlertThresholds(config.capacityAlertThresholds)
+		if err != nil {
+			return err
+		}
+		defaults := controller.CapacityAlertThresholds{Warning: config.capacityAlertWarning, Critical: config.capacityAlertCritical}
+		rule := types.NamespacedName{Namespace: namespace, Name: name}
+		if err := controller.SetupCapacityAlertReconciler(mgr, client, rule, config.capacityAlertRuleLabels, defaults, thresholds); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "CapacityAlert")
+			return err
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 
 	// Add health checker to manager
@@ -133,7 +219,7 @@ func subMain() error {
 	}
 
 	// Add gRPC server to manager.
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(driver.ServerOptions(driver.ServerKindController, interceptors...)...)
 	csi.RegisterIdentityServer(grpcServer, driver.NewIdentityServer(checker.Ready))
 	controllerSever, err := driver.NewControllerServer(mgr, config.controllerServerSettings)
 	if err != nil {
@@ -141,13 +227,41 @@ func subMain() error {
 	}
 	csi.RegisterControllerServer(grpcServer, controllerSever)
 
+	// Prime the cache of LogicalVolumes before accepting CSI calls, so that the first calls
+	// after a failover do not race with the cache sync and list LogicalVolumes via the API server.
+	var warmedUp <-chan struct{}
+	if warmUpper, ok := controllerSever.(driver.WarmUpper); ok {
+		warmUp := runners.NewWarmUp(warmUpper.WarmUp, 5*time.Second)
+		if err := mgr.Add(warmUp); err != nil {
+			return err
+		}
+		if err := mgr.AddReadyzCheck("cache-warm-up", warmUp.Check); err != nil {
+			return err
+		}
+		warmedUp = warmUp.Done()
+	} else {
+		setupLog.Info("controller server does not warm up, serving CSI calls right away")
+	}
+
 	// gRPC service itself should run even when the manager is *not* a leader
 	// because CSI sidecar containers choose a leader.
-	err = mgr.Add(runners.NewGRPCRunner(grpcServer, config.csiSocket, false))
+	err = mgr.Add(runners.NewGRPCRunnerAfter(grpcServer, config.csiSocket, false, warmedUp))
 	if err != nil {
 		return err
 	}
 
+	// The capacity API answers from the annotations of the nodes in the cache, so it runs on every replica.
+	// The lineage API is served along with it and reads the volumes from the API server, since it is called rarely.
+	if config.capacityAPIAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(driver.CapacityAPIPath, driver.NewCapacityAPI(mgr.GetClient()))
+		mux.Handle(lineage.APIPath, lineage.NewAPI(apiReader))
+		srv := &http.Server{Addr: config.capacityAPIAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+		if err := mgr.Add(runners.NewHTTPRunner(srv, false)); err != nil {
+			return err
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		return err
 	}
@@ -162,3 +276,39 @@ func subMain() error {
 	}
 	return nil
 }
+
+// parseLVMdConfigRollouts parses the values of --lvmd-config-rollout in the form of NAMESPACE/CONFIGMAP=DAEMONSET.
+func parseLVMdConfigRollouts(values []string) (map[types.NamespacedName]string, error) {
+	daemonSets := make(map[types.NamespacedName]string, len(values))
+	for _, v := range values {
+		cm, ds, ok := strings.Cut(v, "=")
+		namespace, name, ok2 := strings.Cut(cm, "/")
+		if !ok || !ok2 || namespace == "" || name == "" || ds == "" {
+			return nil, fmt.Errorf("invalid lvmd-config-rollout %q, must be NAMESPACE/CONFIGMAP=DAEMONSET", v)
+		}
+		daemonSets[types.NamespacedName{Namespace: namespace, Name: name}] = ds
+	}
+	return daemonSets, nil
+}
+
+// parseCapacityAlertThresholds parses the values of --capacity-alert-threshold in the form of DEVICE_CLASS=WARNING,CRITICAL.
+func parseCapacityAlertThresholds(values []string) (map[string]controller.CapacityAlertThresholds, error) {
+	thresholds := make(map[string]controller.CapacityAlertThresholds, len(values))
+	for _, v := range values {
+		dc, percents, ok := strings.Cut(v, "=")
+		warning, critical, ok2 := strings.Cut(percents, ",")
+		if !ok || !ok2 || dc == "" {
+			return nil, fmt.Errorf("invalid capacity-alert-threshold %q, must be DEVICE_CLASS=WARNING,CRITICAL", v)
+		}
+		var t controller.CapacityAlertThresholds
+		var err error
+		if t.Warning, err = strconv.ParseFloat(warning, 64); err != nil {
+			return nil, fmt.Errorf("invalid warning threshold of capacity-alert-threshold %q: %w", v, err)
+		}
+		if t.Critical, err = strconv.ParseFloat(critical, 64); err != nil {
+			return nil, fmt.Errorf("invalid critical threshold of capacity-alert-threshold %q: %w", v, err)
+		}
+		thresholds[dc] = t
+	}
+	return thresholds, nil
+}
@@ -10,18 +10,42 @@ import (
 	"github.com/spf13/viper"
 	"github.com/topolvm/topolvm"
 	lvmd "github.com/topolvm/topolvm/cmd/lvmd/app"
+	"github.com/topolvm/topolvm/pkg/driver"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+// maxAutoReconciles caps the number of LogicalVolumes reconciled at the same time unless --max-concurrent-reconciles
+// is given. The lvm commands changing a volume group serialize on its lock anyway.
+const maxAutoReconciles = 4
+
 var config struct {
-	csiSocket           string
-	lvmdSocket          string
-	metricsAddr         string
-	secureMetricsServer bool
-	zapOpts             zap.Options
-	embedLvmd           bool
-	lvmd                lvmd.Config
+	csiSocket               string
+	legacyInterop           bool
+	legacyCSISocket         string
+	lvmdSocket              string
+	lvmdAddress             string
+	lvmdTLS                 lvmdTLSConfig
+	lvmdVsock               string
+	metricsAddr             string
+	secureMetricsServer     bool
+	zapOpts                 zap.Options
+	embedLvmd               bool
+	thinPoolCritical        float64
+	idempotencyAudit        int
+	projectQuotaAPI         bool
+	maxConcurrentReconciles int
+	deregisterOnShutdown    bool
+	lvmd                    lvmd.Config
+	nodeServerSettings      driver.NodeServerSettings
+}
+
+// lvmdTLSConfig holds the files to connect to lvmd over TCP with mutual TLS.
+type lvmdTLSConfig struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	serverName string
 }
 
 var rootCmd = &cobra.Command{
@@ -53,12 +77,30 @@ func Execute() {
 func init() {
 	fs := rootCmd.Flags()
 	fs.StringVar(&config.csiSocket, "csi-socket", topolvm.DefaultCSISocket, "UNIX domain socket filename for CSI")
+	fs.BoolVar(&config.legacyInterop, "legacy-plugin-interop", false, "Also serve the volumes under the legacy plugin name topolvm.cybozu.com")
+	fs.StringVar(&config.legacyCSISocket, "legacy-csi-socket", topolvm.DefaultLegacyCSISocket, "UNIX domain socket filename for CSI of the legacy plugin name")
 	fs.StringVar(&config.lvmdSocket, "lvmd-socket", topolvm.DefaultLVMdSocket, "UNIX domain socket of lvmd service")
+	fs.StringVar(&config.lvmdAddress, "lvmd-address", "", "TCP address of lvmd service, e.g. 192.168.122.1:9090. Connects with mutual TLS instead of lvmd-socket if set")
+	fs.StringVar(&config.lvmdTLS.certFile, "lvmd-tls-cert-file", "", "Client certificate to connect to lvmd-address")
+	fs.StringVar(&config.lvmdTLS.keyFile, "lvmd-tls-key-file", "", "Private key of the client certificate to connect to lvmd-address")
+	fs.StringVar(&config.lvmdTLS.caFile, "lvmd-tls-ca-file", "", "CA certificates to verify the certificate of lvmd-address")
+	fs.StringVar(&config.lvmdTLS.serverName, "lvmd-tls-server-name", "", "Name to verify the certificate of lvmd-address against. Defaults to the host of lvmd-address")
+	fs.StringVar(&config.lvmdVsock, "lvmd-vsock", "", "vsock address of lvmd service in the form CID:port, e.g. 2:1024 for the host. Connects over vsock instead of lvmd-socket if set")
 	fs.StringVar(&config.metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	fs.BoolVar(&config.secureMetricsServer, "secure-metrics-server", false, "Secures the metrics server")
 	fs.String("nodename", "", "The resource name of the running node")
+	fs.Float64Var(&config.thinPoolCritical, "thin-pool-critical-threshold", 0, "Data or metadata usage of thin pools in percent above which no new volume is scheduled to the device-class. 0 disables the check")
+	fs.IntVar(&config.maxConcurrentReconciles, "max-concurrent-reconciles", 0, fmt.Sprintf("Number of LogicalVolumes reconciled at the same time. 0 uses the number of CPUs available up to %d", maxAutoReconciles))
+	fs.BoolVar(&config.deregisterOnShutdown, "deregister-on-shutdown", false, "Set the capacity annotations of the node to zero on graceful shutdown, so that no new volume is scheduled to the node")
+	fs.IntVar(&config.idempotencyAudit, "csi-idempotency-audit", 0, "Number of recent CSI calls to record for detecting non-idempotent replays, served at "+driver.IdempotencyAuditPath+" of the metrics endpoint. Meant for testing. 0 disables the audit")
+	fs.BoolVar(&config.projectQuotaAPI, "project-quota-api", false, "Serve the API creating directories limited by XFS project quotas inside the volumes at "+driver.ProjectQuotaAPIPath+" of the metrics endpoint")
 	fs.BoolVar(&config.embedLvmd, "embed-lvmd", false, "Runs LVMD locally by embedding it instead of calling it externally via gRPC")
 	fs.StringVar(&cfgFilePath, "config", filepath.Join("/etc", "topolvm", "lvmd.yaml"), "config file")
+	config.nodeServerSettings.MountStrategy = driver.MountStrategyDirect
+	fs.Var(&config.nodeServerSettings.MountStrategy, "mount-strategy", "How mount is executed: direct, nsenter-host or systemd-run")
+	fs.BoolVar(&config.nodeServerSettings.VerifyWrites, "verify-writes", false, "Write, sync and read back a block of data on each filesystem mounted for a pod to detect failing devices")
+	fs.BoolVar(&config.nodeServerSettings.VolumeMountGroup, "volume-mount-group", false, "Let kubelet delegate applying the fsGroup of pods to NodePublishVolume, which follows the fsGroupPolicy of the CSIDriver and the "+topolvm.GetFSGroupChangePolicyKey()+" parameter")
+	fs.StringSliceVar(&config.nodeServerSettings.DefaultMountFlags, "default-mount-flags", nil, "Mount flags among nodev, nosuid and noexec added to every filesystem volume unless its StorageClass opts out with "+topolvm.GetDefaultMountFlagsKey())
 
 	_ = viper.BindEnv("nodename", "NODE_NAME")
 	_ = viper.BindPFlag("nodename", fs.Lookup("nodename"))
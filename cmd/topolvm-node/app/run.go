@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -17,11 +18,13 @@ import (
 	topolvmv1 "github.com/topolvm/topolvm/api/v1"
 	clientwrapper "github.com/topolvm/topolvm/internal/client"
 	"github.com/topolvm/topolvm/internal/runners"
+	"github.com/topolvm/topolvm/internal/tuning"
 	"github.com/topolvm/topolvm/pkg/controller"
 	"github.com/topolvm/topolvm/pkg/driver"
 	"github.com/topolvm/topolvm/pkg/lvmd"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	storagev1 "k8s.io/api/storage/v1"
@@ -31,6 +34,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlconfig "sigs.k8s.io/controller-runtime/pkg/config"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -60,8 +64,12 @@ func subMain(ctx context.Context) error {
 	if len(nodename) == 0 {
 		return errors.New("node name is not given")
 	}
+	if config.legacyInterop && topolvm.UseLegacy() {
+		return errors.New("legacy plugin interop cannot be used together with USE_LEGACY")
+	}
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&config.zapOpts)))
+	tuning.SetMaxProcs(setupLog)
 
 	metricsServerOptions := metricsserver.Options{
 		BindAddress: config.metricsAddr,
@@ -70,11 +78,29 @@ func subMain(ctx context.Context) error {
 		metricsServerOptions.SecureServing = true
 		metricsServerOptions.FilterProvider = filters.WithAuthenticationAndAuthorization
 	}
+	metricsServerOptions.ExtraHandlers = make(map[string]http.Handler)
+	var interceptors []grpc.UnaryServerInterceptor
+	if config.idempotencyAudit > 0 {
+		auditor := driver.NewIdempotencyAuditor(config.idempotencyAudit)
+		interceptors = append(interceptors, auditor.Intercept)
+		metricsServerOptions.ExtraHandlers[driver.IdempotencyAuditPath] = auditor
+	}
+	if config.projectQuotaAPI {
+		quotaAPI, err := driver.NewProjectQuotaAPI(config.nodeServerSettings.MountStrategy)
+		if err != nil {
+			return err
+		}
+		metricsServerOptions.ExtraHandlers[driver.ProjectQuotaAPIPath] = quotaAPI
+	}
+	interceptors = append(interceptors, ErrorLoggingInterceptor)
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:         scheme,
 		Metrics:        metricsServerOptions,
 		LeaderElection: false,
+		Controller: ctrlconfig.Controller{
+			MaxConcurrentReconciles: tuning.Workers(config.maxConcurrentReconciles, maxAutoReconciles),
+		},
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
@@ -92,22 +118,39 @@ func subMain(ctx context.Context) error {
 		if err := loadConfFile(ctx, cfgFilePath); err != nil {
 			return err
 		}
+		lvmd.SetBinaryPaths(config.lvmd.LVMPath, config.lvmd.DmsetupPath, config.lvmd.NsenterPath)
+		lvmd.SetCommandTimeouts(config.lvmd.CommandTimeoutDurations())
+		lvmd.SetLockRetry(config.lvmd.LockRetryPolicy())
+		lvmd.SetUdevSettleTimeout(config.lvmd.UdevSettleTimeout.Duration)
+		lvmd.SetFailureLogInterval(config.lvmd.FailureLogIntervalDuration())
+		if maxAge := config.lvmd.FreeBytesCacheMaxAge; maxAge != nil {
+			lvmd.SetFreeBytesCacheMaxAge(maxAge.Duration)
+		}
+		if err := lvmd.DetectLVMVersion(ctx); err != nil {
+			setupLog.Error(err, "failed to detect lvm version, using the features of all supported versions")
+		}
+		if autoActivation := config.lvmd.AutoActivation; autoActivation != nil {
+			err := lvmd.ConfigureAutoActivation(ctx, autoActivation.ConfigFile, config.lvmd.DeviceClasses, autoActivation.ExtraVolumes)
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := lvmd.CheckAutoActivation(ctx, config.lvmd.DeviceClasses); err != nil {
+			setupLog.Error(err, "failed to check autoactivation")
+		}
+		if err := lvmd.RecoverSnapshots(ctx, config.lvmd.DeviceClasses); err != nil {
+			setupLog.Error(err, "failed to recover interrupted snapshots")
+			return err
+		}
 
 		lvService, vgService = lvmd.NewEmbeddedServiceClients(
 			ctx,
 			config.lvmd.DeviceClasses,
 			config.lvmd.LvcreateOptionClasses,
 		)
+		health = lvmd.NewEmbeddedHealthClient(config.lvmd.DeviceClasses)
 	} else {
-		dialer := &net.Dialer{}
-		dialFunc := func(ctx context.Context, a string) (net.Conn, error) {
-			return dialer.DialContext(ctx, "unix", a)
-		}
-		conn, err := grpc.Dial(
-			config.lvmdSocket,
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			grpc.WithContextDialer(dialFunc),
-		)
+		conn, err := dialLVMd()
 		if err != nil {
 			return err
 		}
@@ -132,7 +175,7 @@ func subMain(ctx context.Context) error {
 	// Add metrics exporter to manager.
 	// Note that grpc.ClientConn can be shared with multiple stubs/services.
 	// https://github.com/grpc/grpc-go/tree/master/examples/features/multiplex
-	if err := mgr.Add(runners.NewMetricsExporter(vgService, client, nodename)); err != nil { // adjusted signature
+	if err := mgr.Add(runners.NewMetricsExporter(vgService, client, nodename, config.thinPoolCritical)); err != nil { // adjusted signature
 		return err
 	}
 
@@ -140,9 +183,12 @@ func subMain(ctx context.Context) error {
 	if err := os.MkdirAll(topolvm.DeviceDirectory, 0755); err != nil {
 		return err
 	}
-	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(ErrorLoggingInterceptor))
+	grpcServer := grpc.NewServer(driver.ServerOptions(driver.ServerKindNode, interceptors...)...)
 	csi.RegisterIdentityServer(grpcServer, driver.NewIdentityServer(checker.Ready))
-	nodeServer, err := driver.NewNodeServer(nodename, vgService, lvService, mgr) // adjusted signature
+	for _, hint := range driver.MountStrategyHints(config.nodeServerSettings.MountStrategy) {
+		setupLog.Info("mount strategy hint", "strategy", config.nodeServerSettings.MountStrategy, "hint", hint)
+	}
+	nodeServer, err := driver.NewNodeServer(nodename, vgService, lvService, mgr, config.nodeServerSettings) // adjusted signature
 	if err != nil {
 		return err
 	}
@@ -152,6 +198,18 @@ func subMain(ctx context.Context) error {
 		return err
 	}
 
+	if config.legacyInterop {
+		// serve the same volumes for the PersistentVolumes still referring to the legacy plugin name.
+		legacyServer := grpc.NewServer(driver.ServerOptions(driver.ServerKindNode, interceptors...)...)
+		csi.RegisterIdentityServer(legacyServer, driver.NewLegacyIdentityServer(checker.Ready))
+		csi.RegisterNodeServer(legacyServer, driver.NewLegacyNodeServer(nodeServer))
+		err = mgr.Add(runners.NewGRPCRunner(legacyServer, config.legacyCSISocket, false))
+		if err != nil {
+			return err
+		}
+		setupLog.Info("serving volumes under the legacy plugin name", "name", topolvm.GetLegacyPluginName(), "socket", config.legacyCSISocket)
+	}
+
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, []os.Signal{os.Interrupt, syscall.SIGTERM}...)
 	go func() {
@@ -167,11 +225,67 @@ func subMain(ctx context.Context) error {
 		return err
 	}
 
+	if config.deregisterOnShutdown {
+		if err := deregister(mgr, nodename); err != nil {
+			setupLog.Error(err, "failed to deregister the node")
+			return err
+		}
+	}
 	return nil
 }
 
+// deregister withdraws the node from scheduling after the manager has stopped, so that the metrics exporter
+// no longer updates the capacity annotations. The cache of the manager is stopped, so a direct client is used.
+func deregister(mgr ctrl.Manager, nodename string) error {
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme()})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return runners.Deregister(ctx, c, nodename)
+}
+
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers,verbs=get;list;watch
 
+// dialLVMd connects to lvmd over TCP with mutual TLS if lvmd-address is set, over vsock if lvmd-vsock is set,
+// or to its Unix domain socket.
+func dialLVMd() (*grpc.ClientConn, error) {
+	if config.lvmdAddress != "" && config.lvmdVsock != "" {
+		return nil, errors.New("lvmd-address and lvmd-vsock cannot be used together")
+	}
+	if config.lvmdAddress != "" {
+		tlsConfig, err := lvmd.ClientTLSConfig(config.lvmdTLS.certFile, config.lvmdTLS.keyFile,
+			config.lvmdTLS.caFile, config.lvmdTLS.serverName)
+		if err != nil {
+			return nil, err
+		}
+		return grpc.Dial(config.lvmdAddress, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	}
+
+	if config.lvmdVsock != "" {
+		dialFunc, err := lvmd.VsockDialer(config.lvmdVsock)
+		if err != nil {
+			return nil, err
+		}
+		return grpc.Dial(
+			"passthrough:///vsock:"+config.lvmdVsock,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithContextDialer(dialFunc),
+		)
+	}
+
+	dialer := &net.Dialer{}
+	dialFunc := func(ctx context.Context, a string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "unix", a)
+	}
+	return grpc.Dial(
+		config.lvmdSocket,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(dialFunc),
+	)
+}
+
 func checkFunc(health grpc_health_v1.HealthClient, r client.Reader) func() error {
 	return func() error {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
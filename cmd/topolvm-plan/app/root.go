@@ -0,0 +1,59 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/topolvm/topolvm"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var config struct {
+	planFile            string
+	clusterFile         string
+	schedulerConfigFile string
+	output              string
+}
+
+var rootCmd = &cobra.Command{
+	Use:     "topolvm-plan",
+	Version: topolvm.Version,
+	Short:   "a provisioning simulator for capacity planning with TopoLVM",
+	Long: `A provisioning simulator for capacity planning with TopoLVM.
+
+It reads hypothetical claims from the plan file and places them one
+after another on the nodes with the filter and score of topolvm-scheduler,
+then reports where they would land and what capacity remains.
+
+The capacity of the nodes and the StorageClasses are read from the
+cluster, or from a file given by --cluster such as the output of
+"kubectl get nodes,storageclasses -o yaml".
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return subMain(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+func init() {
+	fs := rootCmd.Flags()
+	fs.StringVarP(&config.planFile, "plan", "f", "", "plan file containing the claims to be placed")
+	fs.StringVar(&config.clusterFile, "cluster", "", "file containing Nodes and StorageClasses instead of reading them from the cluster")
+	fs.StringVar(&config.schedulerConfigFile, "scheduler-config", "", "config file of topolvm-scheduler to read the divisors from")
+	fs.StringVarP(&config.output, "output", "o", outputTable, "output format, one of table or json")
+	_ = rootCmd.MarkFlagRequired("plan")
+}
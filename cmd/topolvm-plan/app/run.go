@@ -0,0 +1,244 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/topolvm/topolvm"
+	schedulerapp "github.com/topolvm/topolvm/cmd/topolvm-scheduler/app"
+	"github.com/topolvm/topolvm/internal/scheduler"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Plan represents the plan file.
+type Plan struct {
+	// Claims are the hypothetical PersistentVolumeClaims to be placed in this order.
+	Claims []Claim `json:"claims"`
+}
+
+// Claim represents a hypothetical PersistentVolumeClaim.
+type Claim struct {
+	// Name is the name of the claim. A suffix is added if Count is greater than 1.
+	Name string `json:"name"`
+	// StorageClassName is the name of the StorageClass of TopoLVM.
+	StorageClassName string `json:"storageClassName"`
+	// Size is the requested storage. topolvm.DefaultSize is used if it is not given.
+	Size resource.Quantity `json:"size"`
+	// Count is the number of the claims, e.g. the replicas of a StatefulSet. The default is 1.
+	Count int `json:"count"`
+	// NodeSelector restricts the nodes the claims may be placed on.
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+func subMain(ctx context.Context, w io.Writer) error {
+	if config.output != outputTable && config.output != outputJSON {
+		return fmt.Errorf("unknown output format: %s", config.output)
+	}
+
+	var plan Plan
+	if err := readYAML(config.planFile, &plan); err != nil {
+		return err
+	}
+
+	schedulerConfig := schedulerapp.Config{DefaultDivisor: 1}
+	if len(config.schedulerConfigFile) != 0 {
+		if err := readYAML(config.schedulerConfigFile, &schedulerConfig); err != nil {
+			return err
+		}
+	}
+
+	var nodes []corev1.Node
+	var storageClasses []storagev1.StorageClass
+	var err error
+	if len(config.clusterFile) != 0 {
+		nodes, storageClasses, err = readClusterFile(config.clusterFile)
+	} else {
+		nodes, storageClasses, err = readCluster(ctx)
+	}
+	if err != nil {
+		return err
+	}
+
+	claims, err := plannedClaims(plan, storageClasses)
+	if err != nil {
+		return err
+	}
+
+	result := scheduler.Plan(nodes, claims, schedulerConfig.DefaultDivisor, schedulerConfig.Divisors)
+	if config.output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+	} else {
+		printTable(w, result)
+	}
+
+	var unplaced int
+	for _, p := range result.Placements {
+		if p.Node == "" {
+			unplaced++
+		}
+	}
+	if unplaced != 0 {
+		return fmt.Errorf("%d of %d claims cannot be placed", unplaced, len(result.Placements))
+	}
+	return nil
+}
+
+func readYAML(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// readClusterFile reads Nodes and StorageClasses from a List as output by kubectl.
+func readClusterFile(path string) ([]corev1.Node, []storagev1.StorageClass, error) {
+	var list struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := readYAML(path, &list); err != nil {
+		return nil, nil, err
+	}
+
+	var nodes []corev1.Node
+	var storageClasses []storagev1.StorageClass
+	for _, item := range list.Items {
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(item, &typeMeta); err != nil {
+			return nil, nil, err
+		}
+		switch typeMeta.Kind {
+		case "Node":
+			var node corev1.Node
+			if err := json.Unmarshal(item, &node); err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, node)
+		case "StorageClass":
+			var sc storagev1.StorageClass
+			if err := json.Unmarshal(item, &sc); err != nil {
+				return nil, nil, err
+			}
+			storageClasses = append(storageClasses, sc)
+		}
+	}
+	return nodes, storageClasses, nil
+}
+
+func readCluster(ctx context.Context) ([]corev1.Node, []storagev1.StorageClass, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return nil, nil, err
+	}
+	var storageClasses storagev1.StorageClassList
+	if err := c.List(ctx, &storageClasses); err != nil {
+		return nil, nil, err
+	}
+	return nodes.Items, storageClasses.Items, nil
+}
+
+// plannedClaims resolves the device-classes of the claims in the same way as the pod mutating webhook.
+func plannedClaims(plan Plan, storageClasses []storagev1.StorageClass) ([]scheduler.PlannedClaim, error) {
+	scs := make(map[string]*storagev1.StorageClass)
+	for i := range storageClasses {
+		scs[storageClasses[i].Name] = &storageClasses[i]
+	}
+
+	var claims []scheduler.PlannedClaim
+	for _, c := range plan.Claims {
+		sc, ok := scs[c.StorageClassName]
+		if !ok {
+			return nil, fmt.Errorf("storage class %q of claim %q is not found", c.StorageClassName, c.Name)
+		}
+		if sc.Provisioner != topolvm.GetPluginName() {
+			return nil, fmt.Errorf("storage class %q of claim %q is not provisioned by TopoLVM", c.StorageClassName, c.Name)
+		}
+		dc, ok := sc.Parameters[topolvm.GetDeviceClassKey()]
+		if !ok {
+			dc = topolvm.DefaultDeviceClassAnnotationName
+		}
+		size := c.Size.Value()
+		if size == 0 {
+			size = topolvm.DefaultSize
+		}
+
+		count := c.Count
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			name := c.Name
+			if count > 1 {
+				name = fmt.Sprintf("%s-%d", c.Name, i)
+			}
+			claims = append(claims, scheduler.PlannedClaim{
+				Name:         name,
+				DeviceClass:  dc,
+				Size:         size,
+				NodeSelector: c.NodeSelector,
+			})
+		}
+	}
+	return claims, nil
+}
+
+func printTable(w io.Writer, result scheduler.PlanResult) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLAIM\tDEVICE-CLASS\tSIZE\tNODE\tSCORE")
+	for _, p := range result.Placements {
+		node := p.Node
+		if node == "" {
+			node = "<none>"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\n", p.Claim, p.DeviceClass, formatBytes(uint64(p.Size)), node, p.Score)
+	}
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "NODE\tDEVICE-CLASS\tREMAINING")
+	nodeNames := make([]string, 0, len(result.Remaining))
+	for name := range result.Remaining {
+		nodeNames = append(nodeNames, name)
+	}
+	sort.Strings(nodeNames)
+	for _, name := range nodeNames {
+		dcs := make([]string, 0, len(result.Remaining[name]))
+		for dc := range result.Remaining[name] {
+			dcs = append(dcs, dc)
+		}
+		sort.Strings(dcs)
+		for _, dc := range dcs {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", name, dc, formatBytes(result.Remaining[name][dc]))
+		}
+	}
+	_ = tw.Flush()
+}
+
+func formatBytes(b uint64) string {
+	return resource.NewQuantity(int64(b), resource.BinarySI).String()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/topolvm/topolvm/cmd/topolvm-plan/app"
+
+func main() {
+	app.Execute()
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/internal/preflight"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+var config struct {
+	nodeName            string
+	modules             []string
+	minLVMVersion       string
+	minThinToolsVersion string
+	lvmPath             string
+	nsenterPath         string
+	reportFile          string
+	output              string
+	failOnWarning       bool
+}
+
+var rootCmd = &cobra.Command{
+	Use:     "topolvm-preflight",
+	Version: topolvm.Version,
+	Short:   "checks whether a node can run TopoLVM",
+	Long: `Checks whether a node can run TopoLVM.
+
+It checks the kernel modules of the device-mapper targets, the versions
+of lvm2 and thin-provisioning-tools, the devices file of lvm and nsenter,
+then prints a machine-readable report.
+
+It exits with a non-zero status if any check fails, so that it can gate
+the rollout of the DaemonSet of lvmd or topolvm-node as an init container.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return subMain(cmd.Context(), cmd.OutOrStdout())
+	},
+}
+
+// Execute adds all child commands to the root command and sets flags appropriately.
+// This is called by main.main(). It only needs to happen once to the rootCmd.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+//nolint:lll
+func init() {
+	fs := rootCmd.Flags()
+	fs.StringVar(&config.nodeName, "nodename", os.Getenv("NODE_NAME"), "The resource name of the running node, recorded in the report. Defaults to NODE_NAME environment variable")
+	fs.StringSliceVar(&config.modules, "modules", preflight.DefaultModules, "Kernel modules that must be loaded or loadable")
+	fs.StringVar(&config.minLVMVersion, "min-lvm-version", "2.02.163", "Minimum version of lvm2")
+	fs.StringVar(&config.minThinToolsVersion, "min-thin-tools-version", "0.7.0", "Minimum version of thin-provisioning-tools. Empty skips the check for nodes without thin pools")
+	fs.BoolVar(&command.Containerized, "container", false, "Run lvm on the host with nsenter, like lvmd with the same flag")
+	fs.StringVar(&config.lvmPath, "lvm-path", "", "Path of the lvm binary, detected if empty")
+	fs.StringVar(&config.nsenterPath, "nsenter-path", "", "Path of the nsenter binary, detected if empty")
+	fs.StringVar(&config.reportFile, "report-file", "", "File to write the report in JSON to, in addition to the output")
+	fs.StringVarP(&config.output, "output", "o", outputJSON, "output format, one of json or table")
+	fs.BoolVar(&config.failOnWarning, "fail-on-warning", false, "Exit with a non-zero status also if a check warns")
+}
@@ -0,0 +1,93 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/internal/preflight"
+)
+
+// hostRoot is the root filesystem of the host seen from a container sharing the PID namespace of the host.
+const hostRoot = "/proc/1/root"
+
+func subMain(ctx context.Context, w io.Writer) error {
+	if config.output != outputTable && config.output != outputJSON {
+		return fmt.Errorf("unknown output format: %s", config.output)
+	}
+
+	command.LVMPath = config.lvmPath
+	command.NsenterPath = config.nsenterPath
+	lvmPath, _, nsenterPath := command.BinaryPaths()
+	cfg := preflight.Config{
+		Modules:             config.modules,
+		MinLVMVersion:       config.minLVMVersion,
+		MinThinToolsVersion: config.minThinToolsVersion,
+		Containerized:       command.Containerized,
+		LVMPath:             lvmPath,
+		NsenterPath:         nsenterPath,
+	}
+	env := preflight.Env{
+		Root:     "/",
+		HostRoot: "/",
+		Run:      run,
+	}
+	if command.Containerized {
+		env.HostRoot = hostRoot
+	}
+
+	report := preflight.Run(ctx, cfg, env)
+	report.Node = config.nodeName
+
+	if config.reportFile != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(config.reportFile, data, 0o644); err != nil {
+			return err
+		}
+	}
+	if config.output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printTable(w, report)
+	}
+
+	if !report.Ready {
+		return errors.New("the node is not ready for TopoLVM")
+	}
+	if config.failOnWarning {
+		for _, r := range report.Checks {
+			if r.Status == preflight.StatusWarn {
+				return errors.New("the node is ready for TopoLVM with warnings")
+			}
+		}
+	}
+	return nil
+}
+
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C")
+	return cmd.CombinedOutput()
+}
+
+func printTable(w io.Writer, report *preflight.Report) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tMESSAGE")
+	for _, r := range report.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name, r.Status, r.Message)
+	}
+	_ = tw.Flush()
+}
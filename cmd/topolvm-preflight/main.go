@@ -0,0 +1,7 @@
+package main
+
+import "github.com/topolvm/topolvm/cmd/topolvm-preflight/app"
+
+func main() {
+	app.Execute()
+}
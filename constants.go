@@ -25,11 +25,35 @@ func GetPluginName() string {
 	}
 }
 
+// GetLegacyPluginName returns the legacy name of the CSI plugin.
+// It is used to keep serving the volumes of the legacy plugin during the migration to the new name.
+func GetLegacyPluginName() string {
+	return legacyPluginName
+}
+
 // GetCapacityKeyPrefix returns the key prefix of Node annotation that represents VG free space.
 func GetCapacityKeyPrefix() string {
 	return fmt.Sprintf("capacity.%s/", GetPluginName())
 }
 
+// GetCapacityEmergencyKeyPrefix returns the key prefix of Node annotation that marks a device-class
+// whose thin pool exceeds the critical usage threshold. No new volume is scheduled to the device-class while it is set.
+func GetCapacityEmergencyKeyPrefix() string {
+	return fmt.Sprintf("capacity-emergency.%s/", GetPluginName())
+}
+
+// GetThickCapacityKeyPrefix returns the key prefix of Node annotation that represents the VG free space of
+// a thin device-class, against which the thick volumes provisioned on it by overriding the type are charged.
+func GetThickCapacityKeyPrefix() string {
+	return fmt.Sprintf("thick-capacity.%s/", GetPluginName())
+}
+
+// GetLvcreateInlineOptionsKeyPrefix returns the key prefix of Node annotation that represents the prefixes
+// of the inline lvcreate options allowed by a lvcreate-option-class in JSON.
+func GetLvcreateInlineOptionsKeyPrefix() string {
+	return fmt.Sprintf("lvcreate-inline-options.%s/", GetPluginName())
+}
+
 // GetCapacityResource returns the resource name of topolvm capacity.
 func GetCapacityResource() corev1.ResourceName {
 	return corev1.ResourceName(fmt.Sprintf("%s/capacity", GetPluginName()))
@@ -40,6 +64,11 @@ func GetTopologyNodeKey() string {
 	return fmt.Sprintf("topology.%s/node", GetPluginName())
 }
 
+// GetLegacyTopologyNodeKey returns the key of topology that represents node name for the legacy plugin.
+func GetLegacyTopologyNodeKey() string {
+	return fmt.Sprintf("topology.%s/node", legacyPluginName)
+}
+
 // GetDeviceClassKey returns the key used in CSI volume create requests to specify a device-class.
 func GetDeviceClassKey() string {
 	return fmt.Sprintf("%s/device-class", GetPluginName())
@@ -50,21 +79,151 @@ func GetLvcreateOptionClassKey() string {
 	return fmt.Sprintf("%s/lvcreate-option-class", GetPluginName())
 }
 
+// GetLvcreateOptionsKey returns the key used in CSI volume create requests to pass inline options to lvcreate
+// in addition to the options of the lvcreate-option-class. The options are separated by whitespaces.
+func GetLvcreateOptionsKey() string {
+	return fmt.Sprintf("%s/lvcreate-options", GetPluginName())
+}
+
+// GetProvisioningTypeKey returns the key used in CSI volume create requests to override thin or thick provisioning of the device-class.
+func GetProvisioningTypeKey() string {
+	return fmt.Sprintf("%s/provisioning-type", GetPluginName())
+}
+
+// GetEncryptionKey returns the key used in CSI volume create requests to encrypt the volume on the node.
+func GetEncryptionKey() string {
+	return fmt.Sprintf("%s/encryption", GetPluginName())
+}
+
+// GetFilesystemLabelKey returns the key used in CSI volume create requests to set the label of the filesystem.
+// The PVC name and namespace can be embedded as ${pvc.name} and ${pvc.namespace}.
+func GetFilesystemLabelKey() string {
+	return fmt.Sprintf("%s/fs-label", GetPluginName())
+}
+
+// GetFilesystemUUIDKey returns the key used in CSI volume create requests to derive the UUID of the filesystem from the volume ID.
+func GetFilesystemUUIDKey() string {
+	return fmt.Sprintf("%s/fs-uuid", GetPluginName())
+}
+
+// GetMkfsOptionsKey returns the key used in CSI volume create requests to pass options to mkfs
+// when the filesystem of the volume is created, e.g. "-m reflink=1". The options are separated by whitespaces.
+func GetMkfsOptionsKey() string {
+	return fmt.Sprintf("%s/mkfs-options", GetPluginName())
+}
+
+// GetFilesystemMkfsOptionsKey returns the key used in CSI volume create requests to pass options to mkfs
+// of the filesystem type fsType, e.g. "topolvm.io/mkfs-options-xfs". It takes precedence over GetMkfsOptionsKey.
+func GetFilesystemMkfsOptionsKey(fsType string) string {
+	return fmt.Sprintf("%s-%s", GetMkfsOptionsKey(), fsType)
+}
+
+// GetDefaultMountFlagsKey returns the key used in CSI volume create requests to override the default mount flags
+// of topolvm-node, e.g. "nodev,nosuid". The value "none" disables them.
+func GetDefaultMountFlagsKey() string {
+	return fmt.Sprintf("%s/default-mount-flags", GetPluginName())
+}
+
+// GetProjectQuotaKey returns the key used in CSI volume create requests to mount XFS filesystems with project quota,
+// so that directories inside the volume can be limited by the project quota API of topolvm-node. The value is "true".
+func GetProjectQuotaKey() string {
+	return fmt.Sprintf("%s/project-quota", GetPluginName())
+}
+
+// GetFSGroupChangePolicyKey returns the key used in CSI volume create requests to specify how topolvm-node applies
+// the fsGroup of pods to the volume when kubelet delegates it to the driver, i.e. "Always" or "OnRootMismatch".
+func GetFSGroupChangePolicyKey() string {
+	return fmt.Sprintf("%s/fsgroup-change-policy", GetPluginName())
+}
+
+// GetWarmUpKey returns the key used in CSI volume create requests to run a Job on the new volume before it is
+// published to other pods, e.g. to seed a dataset. The value is "NAMESPACE/NAME" of the ConfigMap holding the Job template.
+func GetWarmUpKey() string {
+	return fmt.Sprintf("%s/warm-up", GetPluginName())
+}
+
+// GetColocationKey returns the key used in CSI volume create requests to provision the volume on the node of
+// the volumes of its sibling PVCs. The value is "preferred" or "required".
+func GetColocationKey() string {
+	return fmt.Sprintf("%s/colocation", GetPluginName())
+}
+
+// GetWipeOnDeleteKey returns the key used in CSI volume create requests and PVC annotations to override
+// the wipe-on-delete of the device-class. The value is "none", "discard" or "zero".
+func GetWipeOnDeleteKey() string {
+	return fmt.Sprintf("%s/wipe-on-delete", GetPluginName())
+}
+
+// GetSnapshotBeforeExpandKey returns the key used in CSI volume create requests to take a thin snapshot
+// before each expansion of the volume. The value is the retention of the snapshot, e.g. "24h".
+func GetSnapshotBeforeExpandKey() string {
+	return fmt.Sprintf("%s/snapshot-before-expand", GetPluginName())
+}
+
+// GetExpandSnapshotExpiresTag returns the prefix of the LVM tag of the snapshots taken before expansions,
+// which is followed by the UNIX time at which the snapshot is removed.
+func GetExpandSnapshotExpiresTag() string {
+	return fmt.Sprintf("%s/expand-snapshot-expires=", GetPluginName())
+}
+
+// GetColocationGroupKey returns the key of PVC label grouping the PVCs whose volumes are provisioned on the same node.
+func GetColocationGroupKey() string {
+	return fmt.Sprintf("%s/colocation-group", GetPluginName())
+}
+
 // GetResizeRequestedAtKey returns the key of LogicalVolume that represents the timestamp of the resize request.
 func GetResizeRequestedAtKey() string {
 	return fmt.Sprintf("%s/resize-requested-at", GetPluginName())
 }
 
+// GetShrinkConfirmationKey returns the key of LogicalVolume annotation that confirms shrinking the volume.
+// The value must be the requested size in .spec.size.
+func GetShrinkConfirmationKey() string {
+	return fmt.Sprintf("%s/confirm-shrink", GetPluginName())
+}
+
+// GetMoveToDeviceClassKey returns the key of LogicalVolume annotation that requests moving the thin volume
+// to the thin pool of the device class in the value, which must be in the same volume group.
+func GetMoveToDeviceClassKey() string {
+	return fmt.Sprintf("%s/move-to-device-class", GetPluginName())
+}
+
+// GetPausedKey returns the key of LogicalVolume annotation that pauses the reconciliation of the volume by topolvm-node
+// while its value is "true", e.g. for manual maintenance of the LVM logical volume.
+func GetPausedKey() string {
+	return fmt.Sprintf("%s/paused", GetPluginName())
+}
+
+// GetPreemptibleKey returns the key of PVC annotation that allows the CapacityReclaims with the Preemptible policy
+// to select the volume of the PVC while its value is "true".
+func GetPreemptibleKey() string {
+	return fmt.Sprintf("%s/preemptible", GetPluginName())
+}
+
 // GetPendingDeletionKey returns the name of the pending-deletion annotation
 func GetLVPendingDeletionKey() string {
 	return fmt.Sprintf("%s/pendingdeletion", GetPluginName())
 }
 
+// GetLVMdConfigHashKey returns the key of the annotation that records the hash of the lvmd configuration being rolled out.
+func GetLVMdConfigHashKey() string {
+	return fmt.Sprintf("%s/lvmd-config-hash", GetPluginName())
+}
+
 // GetLogicalVolumeFinalizer returns the name of LogicalVolume finalizer
 func GetLogicalVolumeFinalizer() string {
 	return fmt.Sprintf("%s/logicalvolume", GetPluginName())
 }
 
+// ClusterAutoscalerScaleDownDisabledKey is the annotation of nodes which cluster-autoscaler does not scale down.
+const ClusterAutoscalerScaleDownDisabledKey = "cluster-autoscaler.kubernetes.io/scale-down-disabled"
+
+// GetScaleDownDisabledKey returns the annotation key marking that topolvm-controller has set
+// ClusterAutoscalerScaleDownDisabledKey on the node because it hosts logical volumes.
+func GetScaleDownDisabledKey() string {
+	return fmt.Sprintf("%s/scale-down-disabled", GetPluginName())
+}
+
 // GetNodeFinalizer returns the name of Node finalizer of TopoLVM
 func GetNodeFinalizer() string {
 	return fmt.Sprintf("%s/node", GetPluginName())
@@ -76,9 +235,15 @@ const PVCFinalizer = pluginName + "/pvc"
 // LegacyPVCFinalizer is a legacy finalizer of PVC.
 const LegacyPVCFinalizer = legacyPluginName + "/pvc"
 
+// WarmUpJobNamePrefix is the prefix of the name of the warm-up Job of a volume followed by the name of its LogicalVolume.
+const WarmUpJobNamePrefix = "topolvm-warm-up-"
+
 // DefaultCSISocket is the default path of the CSI socket file.
 const DefaultCSISocket = "/run/topolvm/csi-topolvm.sock"
 
+// DefaultLegacyCSISocket is the default path of the CSI socket file for the legacy plugin name.
+const DefaultLegacyCSISocket = "/run/topolvm/csi-topolvm-legacy.sock"
+
 // DefaultLVMdSocket is the default path of the lvmd socket file.
 const DefaultLVMdSocket = "/run/topolvm/lvmd.sock"
 
@@ -19,6 +19,7 @@ require (
 	github.com/spf13/cobra v1.7.0
 	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.17.0
+	go.uber.org/automaxprocs v1.6.0
 	go.uber.org/zap v1.25.0
 	golang.org/x/sys v0.19.0
 	google.golang.org/grpc v1.58.3
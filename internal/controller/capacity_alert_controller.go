@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// capacityAlertFor is how long the usage has to exceed a threshold before the alert fires.
+	capacityAlertFor = "5m"
+	// capacityAlertRetryInterval is the interval to retry when the PrometheusRule CRD is not installed.
+	capacityAlertRetryInterval = 5 * time.Minute
+)
+
+// prometheusRuleGVK is the PrometheusRule of the Prometheus operator, which is handled as unstructured
+// so that TopoLVM does not depend on the operator.
+var prometheusRuleGVK = schema.GroupVersionKind{Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule"}
+
+// CapacityAlertThresholds are the usages of a device-class in percent at which the capacity alerts fire.
+type CapacityAlertThresholds struct {
+	Warning  float64
+	Critical float64
+}
+
+// CapacityAlertReconciler keeps a PrometheusRule alerting on the usage of the device-classes in sync with
+// the device-classes the nodes publish their capacity of, so that no alert rule is maintained by hand.
+//
+// The usage of a thin device-class is the data usage of its thin pool, otherwise that of its volume group.
+type CapacityAlertReconciler struct {
+	client     client.Client
+	rule       types.NamespacedName
+	labels     map[string]string
+	defaults   CapacityAlertThresholds
+	thresholds map[string]CapacityAlertThresholds
+}
+
+// NewCapacityAlertReconciler returns CapacityAlertReconciler.
+// rule is the PrometheusRule to generate with the labels, e.g. to be discovered by Prometheus.
+// thresholds override the default thresholds by the name of the device-class.
+func NewCapacityAlertReconciler(client client.Client, rule types.NamespacedName, labels map[string]string,
+	defaults CapacityAlertThresholds, thresholds map[string]CapacityAlertThresholds) *CapacityAlertReconciler {
+	return &CapacityAlertReconciler{
+		client:     client,
+		rule:       rule,
+		labels:     labels,
+		defaults:   defaults,
+		thresholds: thresholds,
+	}
+}
+
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;create;update;delete
+
+// Reconcile generates the PrometheusRule for the device-classes of the nodes.
+func (r *CapacityAlertReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	var nodes corev1.NodeList
+	if err := r.client.List(ctx, &nodes); err != nil {
+		return ctrl.Result{}, err
+	}
+	deviceClasses := nodeDeviceClasses(nodes.Items)
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(prometheusRuleGVK)
+	err := r.client.Get(ctx, r.rule, current)
+	switch {
+	case meta.IsNoMatchError(err):
+		log.Error(err, "PrometheusRule is not available, install the CRDs of the Prometheus operator")
+		return ctrl.Result{RequeueAfter: capacityAlertRetryInterval}, nil
+	case apierrors.IsNotFound(err):
+		current = nil
+	case err != nil:
+		return ctrl.Result{}, err
+	}
+
+	if len(deviceClasses) == 0 {
+		if current != nil {
+			if err := r.client.Delete(ctx, current); err != nil && !apierrors.IsNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			log.Info("deleted capacity alerts, no device-class is published")
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// the spec consists of strings only, so that it compares equal to the one read from the API server.
+	spec := r.ruleSpec(deviceClasses)
+	if current == nil {
+		rule := &unstructured.Unstructured{}
+		rule.SetGroupVersionKind(prometheusRuleGVK)
+		rule.SetNamespace(r.rule.Namespace)
+		rule.SetName(r.rule.Name)
+		rule.SetLabels(r.labels)
+		rule.Object["spec"] = spec
+		if err := r.client.Create(ctx, rule); err != nil {
+			return ctrl.Result{}, err
+		}
+		log.Info("created capacity alerts", "device_classes", deviceClasses)
+		return ctrl.Result{}, nil
+	}
+
+	labels := current.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	changed := !reflect.DeepEqual(current.Object["spec"], spec)
+	for k, v := range r.labels {
+		if labels[k] != v {
+			labels[k] = v
+			changed = true
+		}
+	}
+	if !changed {
+		return ctrl.Result{}, nil
+	}
+	current.SetLabels(labels)
+	current.Object["spec"] = spec
+	if err := r.client.Update(ctx, current); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("updated capacity alerts", "device_classes", deviceClasses)
+	return ctrl.Result{}, nil
+}
+
+// nodeDeviceClasses returns the sorted names of the device-classes whose capacity any of the nodes publishes.
+func nodeDeviceClasses(nodes []corev1.Node) []string {
+	found := make(map[string]bool)
+	for _, node := range nodes {
+		for key := range node.Annotations {
+			if dc, ok := strings.CutPrefix(key, topolvm.GetCapacityKeyPrefix()); ok {
+				found[dc] = true
+			}
+		}
+	}
+	deviceClasses := make([]string, 0, len(found))
+	for dc := range found {
+		deviceClasses = append(deviceClasses, dc)
+	}
+	sort.Strings(deviceClasses)
+	return deviceClasses
+}
+
+// ruleSpec returns the spec of the PrometheusRule with a warning and a critical alert for each device-class.
+func (r *CapacityAlertReconciler) ruleSpec(deviceClasses []string) map[string]any {
+	rules := make([]any, 0, 2*len(deviceClasses))
+	for _, dc := range deviceClasses {
+		thresholds, ok := r.thresholds[dc]
+		if !ok {
+			thresholds = r.defaults
+		}
+		for _, alert := range []struct {
+			name      string
+			severity  string
+			threshold float64
+		}{
+			{"TopoLVMDeviceClassCapacityWarning", "warning", thresholds.Warning},
+			{"TopoLVMDeviceClassCapacityCritical", "critical", thresholds.Critical},
+		} {
+			if alert.threshold <= 0 {
+				continue
+			}
+			rules = append(rules, map[string]any{
+				"alert": alert.name,
+				"expr":  fmt.Sprintf("%s > %g", deviceClassUsageExpr(dc), alert.threshold),
+				"for":   capacityAlertFor,
+				"labels": map[string]any{
+					"severity":     alert.severity,
+					"device_class": dc,
+				},
+				"annotations": map[string]any{
+					"summary": fmt.Sprintf("Device-class %s is more than %g%% used.", dc, alert.threshold),
+					"description": fmt.Sprintf("Device-class %s on node {{ $labels.node }} is {{ $value | humanize }}%% used.",
+						dc),
+				},
+			})
+		}
+	}
+	return map[string]any{
+		"groups": []any{
+			map[string]any{
+				"name":  "topolvm-device-class-capacity",
+				"rules": rules,
+			},
+		},
+	}
+}
+
+// deviceClassUsageExpr returns the PromQL expression of the usage of the device-class in percent,
+// which is the data usage of the thin pool of a thin device-class and that of the volume group otherwise.
+func deviceClassUsageExpr(dc string) string {
+	sel := fmt.Sprintf(`{device_class=%q}`, dc)
+	return fmt.Sprintf("(topolvm_thinpool_data_percent%s or (100 - 100 * topolvm_volumegroup_available_bytes%s / topolvm_volumegroup_size_bytes%s))",
+		sel, sel, sel)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CapacityAlertReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// all the nodes are reconciled into the single PrometheusRule.
+	toRule := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []ctrl.Request {
+		return []ctrl.Request{{NamespacedName: r.rule}}
+	})
+	pred := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(nodeDeviceClasses([]corev1.Node{*e.ObjectOld.(*corev1.Node)}),
+				nodeDeviceClasses([]corev1.Node{*e.ObjectNew.(*corev1.Node)}))
+		},
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("capacity-alert-controller").
+		Watches(&corev1.Node{}, toRule, builder.WithPredicates(pred)).
+		Complete(r)
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+var _ = Describe("CapacityAlert controller", func() {
+	ctx := context.Background()
+
+	It("should generate alerts for the device-classes of the nodes", func() {
+		nodes := []corev1.Node{
+			{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				topolvm.GetCapacityKeyPrefix() + "ssd": "10",
+				topolvm.GetCapacityKeyPrefix() + "hdd": "10",
+				"other":                                "",
+			}}},
+			{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+				topolvm.GetCapacityKeyPrefix() + "ssd": "10",
+			}}},
+			{},
+		}
+		deviceClasses := nodeDeviceClasses(nodes)
+		Expect(deviceClasses).To(Equal([]string{"hdd", "ssd"}))
+
+		reconciler := NewCapacityAlertReconciler(nil, types.NamespacedName{}, nil,
+			CapacityAlertThresholds{Warning: 80, Critical: 90},
+			map[string]CapacityAlertThresholds{"ssd": {Warning: 0, Critical: 95.5}})
+		spec := reconciler.ruleSpec(deviceClasses)
+
+		groups := spec["groups"].([]any)
+		Expect(groups).To(HaveLen(1))
+		rules := groups[0].(map[string]any)["rules"].([]any)
+		Expect(rules).To(HaveLen(3))
+		exprs := make(map[string]string)
+		for _, rule := range rules {
+			rule := rule.(map[string]any)
+			labels := rule["labels"].(map[string]any)
+			exprs[labels["device_class"].(string)+"/"+labels["severity"].(string)] = rule["expr"].(string)
+		}
+		Expect(exprs).To(HaveKeyWithValue("hdd/warning", deviceClassUsageExpr("hdd")+" > 80"))
+		Expect(exprs).To(HaveKeyWithValue("hdd/critical", deviceClassUsageExpr("hdd")+" > 90"))
+		Expect(exprs).To(HaveKeyWithValue("ssd/critical", deviceClassUsageExpr("ssd")+" > 95.5"))
+		Expect(exprs["hdd/warning"]).To(ContainSubstring(`topolvm_thinpool_data_percent{device_class="hdd"}`))
+	})
+
+	It("should retry later if PrometheusRule is not installed", func() {
+		reconciler := NewCapacityAlertReconciler(k8sClient, types.NamespacedName{Namespace: "default", Name: "topolvm"}, nil,
+			CapacityAlertThresholds{Warning: 80, Critical: 90}, nil)
+		result, err := reconciler.Reconcile(ctx, ctrl.Request{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RequeueAfter).To(Equal(capacityAlertRetryInterval))
+	})
+})
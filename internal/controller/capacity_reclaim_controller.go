@@ -0,0 +1,389 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// reclaimSelectInterval is the interval to select the candidates again while they do not add up to the target.
+const reclaimSelectInterval = time.Minute
+
+// The reasons of the conditions of CapacityReclaim.
+const (
+	reasonCandidatesSelected     = "Selected"
+	reasonInsufficientCandidates = "InsufficientCandidates"
+	reasonWaitingForReclaim      = "WaitingForReclaim"
+	reasonTargetReached          = "TargetReached"
+)
+
+// CapacityReclaimReconciler frees the space requested by CapacityReclaims. It selects the volumes of the node and
+// the device-class by the policy until their sizes add up to the target, and then asks the owners of the PVCs to
+// migrate or delete them, or deletes the PVCs by itself, depending on the action.
+//
+// The candidates are selected once, so that the owners are not asked about other volumes meanwhile, and a candidate
+// is reclaimed when its LogicalVolume has been deleted.
+type CapacityReclaimReconciler struct {
+	client   client.Client
+	recorder record.EventRecorder
+}
+
+// NewCapacityReclaimReconciler returns CapacityReclaimReconciler.
+func NewCapacityReclaimReconciler(client client.Client) *CapacityReclaimReconciler {
+	return &CapacityReclaimReconciler{
+		client: client,
+	}
+}
+
+//+kubebuilder:rbac:groups=topolvm.io,resources=capacityreclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topolvm.io,resources=capacityreclaims/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile selects the candidates of the CapacityReclaim and tracks the progress of reclaiming them.
+func (r *CapacityReclaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	cr := &topolvmv1.CapacityReclaim{}
+	err := r.client.Get(ctx, req.NamespacedName, cr)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	if cr.DeletionTimestamp != nil || cr.Status.Phase == topolvmv1.ReclaimPhaseCompleted {
+		return ctrl.Result{}, nil
+	}
+
+	if len(cr.Status.Candidates) == 0 {
+		return r.selectCandidates(ctx, cr)
+	}
+	return ctrl.Result{}, r.trackCandidates(ctx, cr)
+}
+
+// selectCandidates selects the candidates of the CapacityReclaim. If they do not add up to the target, nothing is
+// requested and they are selected again later.
+func (r *CapacityReclaimReconciler) selectCandidates(ctx context.Context, cr *topolvmv1.CapacityReclaim) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	volumes, err := r.listVolumes(ctx, cr)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	candidates, selected := selectReclaimCandidates(volumes, cr.Spec.Policy, cr.Spec.Target.Value())
+
+	cr2 := cr.DeepCopy()
+	if selected < cr.Spec.Target.Value() {
+		cr2.Status.Phase = topolvmv1.ReclaimPhasePending
+		meta.SetStatusCondition(&cr2.Status.Conditions, metav1.Condition{
+			Type:   topolvmv1.ConditionCandidatesSelected,
+			Status: metav1.ConditionFalse,
+			Reason: reasonInsufficientCandidates,
+			Message: fmt.Sprintf("the volumes selected by policy %s add up to %s of the target %s",
+				cr.Spec.Policy, resource.NewQuantity(selected, resource.BinarySI), &cr.Spec.Target),
+		})
+		return ctrl.Result{RequeueAfter: reclaimSelectInterval}, r.updateStatus(ctx, cr, cr2)
+	}
+
+	cr2.Status.Phase = topolvmv1.ReclaimPhaseInProgress
+	cr2.Status.Candidates = candidates
+	cr2.Status.SelectedSize = resource.NewQuantity(selected, resource.BinarySI)
+	cr2.Status.ReclaimedSize = resource.NewQuantity(0, resource.BinarySI)
+	meta.SetStatusCondition(&cr2.Status.Conditions, metav1.Condition{
+		Type:    topolvmv1.ConditionCandidatesSelected,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonCandidatesSelected,
+		Message: fmt.Sprintf("selected %d volumes by policy %s", len(candidates), cr.Spec.Policy),
+	})
+	meta.SetStatusCondition(&cr2.Status.Conditions, metav1.Condition{
+		Type:    topolvmv1.ConditionTargetReclaimed,
+		Status:  metav1.ConditionFalse,
+		Reason:  reasonWaitingForReclaim,
+		Message: fmt.Sprintf("waiting for %d volumes to be reclaimed", len(candidates)),
+	})
+	if err := r.updateStatus(ctx, cr, cr2); err != nil {
+		return ctrl.Result{}, err
+	}
+	log.Info("selected volumes to reclaim", "name", cr.Name, "policy", cr.Spec.Policy, "candidates", len(candidates), "selected_bytes", selected)
+	r.recorder.Eventf(cr2, corev1.EventTypeNormal, "CandidatesSelected",
+		"selected %d volumes of %s by policy %s", len(candidates), cr2.Status.SelectedSize, cr.Spec.Policy)
+
+	if reclaimAction(cr) == topolvmv1.ReclaimActionRequest {
+		for _, c := range candidates {
+			pvc := &corev1.PersistentVolumeClaim{}
+			err := r.client.Get(ctx, types.NamespacedName{Namespace: c.Namespace, Name: c.PersistentVolumeClaim}, pvc)
+			switch {
+			case apierrors.IsNotFound(err):
+				continue
+			case err != nil:
+				return ctrl.Result{}, err
+			}
+			r.recorder.Eventf(pvc, corev1.EventTypeWarning, "CapacityReclaimRequested",
+				"CapacityReclaim %s requests to migrate or delete the volume to free %s of device-class %q on node %s",
+				cr.Name, &cr.Spec.Target, cr.Spec.DeviceClass, cr.Spec.NodeName)
+		}
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, r.trackCandidates(ctx, cr2)
+}
+
+// trackCandidates marks the candidates whose LogicalVolume has been deleted reclaimed, and completes the
+// CapacityReclaim once they add up to the target. With the Delete action, it deletes the PVCs of the others.
+func (r *CapacityReclaimReconciler) trackCandidates(ctx context.Context, cr *topolvmv1.CapacityReclaim) error {
+	log := crlog.FromContext(ctx)
+
+	cr2 := cr.DeepCopy()
+	var reclaimed int64
+	waiting := 0
+	for i := range cr2.Status.Candidates {
+		c := &cr2.Status.Candidates[i]
+		if !c.Reclaimed {
+			lv := &topolvmv1.LogicalVolume{}
+			err := r.client.Get(ctx, types.NamespacedName{Name: c.LogicalVolume}, lv)
+			switch {
+			case apierrors.IsNotFound(err):
+				c.Reclaimed = true
+			case err != nil:
+				return err
+			case reclaimAction(cr) == topolvmv1.ReclaimActionDelete && lv.DeletionTimestamp == nil:
+				if err := r.deleteClaim(ctx, cr, lv); err != nil {
+					log.Error(err, "unable to delete PVC", "name", c.PersistentVolumeClaim, "namespace", c.Namespace)
+					return err
+				}
+			}
+		}
+		if c.Reclaimed {
+			reclaimed += c.Size.Value()
+		} else {
+			waiting++
+		}
+	}
+
+	cr2.Status.ReclaimedSize = resource.NewQuantity(reclaimed, resource.BinarySI)
+	if reclaimed < cr.Spec.Target.Value() {
+		meta.SetStatusCondition(&cr2.Status.Conditions, metav1.Condition{
+			Type:    topolvmv1.ConditionTargetReclaimed,
+			Status:  metav1.ConditionFalse,
+			Reason:  reasonWaitingForReclaim,
+			Message: fmt.Sprintf("waiting for %d volumes to be reclaimed", waiting),
+		})
+		return r.updateStatus(ctx, cr, cr2)
+	}
+
+	cr2.Status.Phase = topolvmv1.ReclaimPhaseCompleted
+	meta.SetStatusCondition(&cr2.Status.Conditions, metav1.Condition{
+		Type:    topolvmv1.ConditionTargetReclaimed,
+		Status:  metav1.ConditionTrue,
+		Reason:  reasonTargetReached,
+		Message: fmt.Sprintf("reclaimed %s of the target %s", cr2.Status.ReclaimedSize, &cr.Spec.Target),
+	})
+	if err := r.updateStatus(ctx, cr, cr2); err != nil {
+		return err
+	}
+	log.Info("reclaimed the target", "name", cr.Name, "reclaimed_bytes", reclaimed)
+	r.recorder.Eventf(cr2, corev1.EventTypeNormal, "TargetReclaimed", "reclaimed %s of the target %s",
+		cr2.Status.ReclaimedSize, &cr.Spec.Target)
+	return nil
+}
+
+// deleteClaim deletes the PVC bound to the PersistentVolume of the LogicalVolume, if any.
+func (r *CapacityReclaimReconciler) deleteClaim(ctx context.Context, cr *topolvmv1.CapacityReclaim, lv *topolvmv1.LogicalVolume) error {
+	pvc, err := boundClaim(ctx, r.client, lv)
+	if err != nil {
+		return err
+	}
+	if pvc == nil || pvc.DeletionTimestamp != nil {
+		return nil
+	}
+	if err := r.client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	crlog.FromContext(ctx).Info("deleted PVC to reclaim its volume", "name", pvc.Name, "namespace", pvc.Namespace, "capacity_reclaim", cr.Name)
+	r.recorder.Eventf(pvc, corev1.EventTypeWarning, "CapacityReclaimDeleted",
+		"deleted by CapacityReclaim %s to free %s of device-class %q on node %s",
+		cr.Name, &cr.Spec.Target, cr.Spec.DeviceClass, cr.Spec.NodeName)
+	return nil
+}
+
+func (r *CapacityReclaimReconciler) updateStatus(ctx context.Context, cr, cr2 *topolvmv1.CapacityReclaim) error {
+	if equality.Semantic.DeepEqual(cr.Status, cr2.Status) {
+		return nil
+	}
+	return r.client.Status().Update(ctx, cr2)
+}
+
+// reclaimVolume is a volume which may be selected as a candidate.
+type reclaimVolume struct {
+	candidate   topolvmv1.ReclaimCandidate
+	unused      bool
+	preemptible bool
+}
+
+// listVolumes returns the volumes of the node and the device-class of the CapacityReclaim which are bound to a PVC.
+func (r *CapacityReclaimReconciler) listVolumes(ctx context.Context, cr *topolvmv1.CapacityReclaim) ([]reclaimVolume, error) {
+	lvList := &topolvmv1.LogicalVolumeList{}
+	if err := r.client.List(ctx, lvList); err != nil {
+		return nil, err
+	}
+
+	// the pods are listed by namespace on demand, since only the Unused policy needs them.
+	users := make(map[string]map[string]bool)
+	var volumes []reclaimVolume
+	for i := range lvList.Items {
+		lv := &lvList.Items[i]
+		if lv.Spec.NodeName != cr.Spec.NodeName || lv.Spec.DeviceClass != cr.Spec.DeviceClass || lv.DeletionTimestamp != nil {
+			continue
+		}
+		pvc, err := boundClaim(ctx, r.client, lv)
+		if err != nil {
+			return nil, err
+		}
+		if pvc == nil {
+			continue
+		}
+
+		size := lv.Spec.Size
+		if lv.Status.CurrentSize != nil {
+			size = *lv.Status.CurrentSize
+		}
+		v := reclaimVolume{
+			candidate: topolvmv1.ReclaimCandidate{
+				LogicalVolume:         lv.Name,
+				Namespace:             pvc.Namespace,
+				PersistentVolumeClaim: pvc.Name,
+				Size:                  size,
+			},
+			preemptible: pvc.Annotations[topolvm.GetPreemptibleKey()] == "true",
+		}
+		if cr.Spec.Policy == topolvmv1.ReclaimPolicyUnused {
+			if _, ok := users[pvc.Namespace]; !ok {
+				if users[pvc.Namespace], err = r.claimUsers(ctx, pvc.Namespace); err != nil {
+					return nil, err
+				}
+			}
+			v.unused = !users[pvc.Namespace][pvc.Name]
+		}
+		volumes = append(volumes, v)
+	}
+	return volumes, nil
+}
+
+// claimUsers returns the names of the PVCs in the namespace used by pods which have not terminated.
+func (r *CapacityReclaimReconciler) claimUsers(ctx context.Context, namespace string) (map[string]bool, error) {
+	var pods corev1.PodList
+	if err := r.client.List(ctx, &pods, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	users := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				users[volume.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+	}
+	return users, nil
+}
+
+// selectReclaimCandidates selects the volumes by policy until their sizes add up to target, and returns them
+// with the total size. The Smallest policy selects the smallest volumes first, and the others the largest volumes
+// they allow first, so that as few volumes as possible are disrupted.
+func selectReclaimCandidates(volumes []reclaimVolume, policy string, target int64) ([]topolvmv1.ReclaimCandidate, int64) {
+	var eligible []topolvmv1.ReclaimCandidate
+	for _, v := range volumes {
+		switch {
+		case policy == topolvmv1.ReclaimPolicySmallest,
+			policy == topolvmv1.ReclaimPolicyUnused && v.unused,
+			policy == topolvmv1.ReclaimPolicyPreemptible && v.preemptible:
+			eligible = append(eligible, v.candidate)
+		}
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		if c := eligible[i].Size.Cmp(eligible[j].Size); c != 0 {
+			return (c < 0) == (policy == topolvmv1.ReclaimPolicySmallest)
+		}
+		return eligible[i].LogicalVolume < eligible[j].LogicalVolume
+	})
+
+	var candidates []topolvmv1.ReclaimCandidate
+	var selected int64
+	for _, c := range eligible {
+		if selected >= target {
+			break
+		}
+		candidates = append(candidates, c)
+		selected += c.Size.Value()
+	}
+	return candidates, selected
+}
+
+// reclaimAction returns the action of the CapacityReclaim, which defaults to Request.
+func reclaimAction(cr *topolvmv1.CapacityReclaim) string {
+	if cr.Spec.Action == "" {
+		return topolvmv1.ReclaimActionRequest
+	}
+	return cr.Spec.Action
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *CapacityReclaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("topolvm-controller")
+
+	// the CapacityReclaims in progress are reconciled when a LogicalVolume of their node is deleted.
+	lvPred := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("capacity-reclaim-controller").
+		For(&topolvmv1.CapacityReclaim{}).
+		Watches(&topolvmv1.LogicalVolume{}, handler.EnqueueRequestsFromMapFunc(r.nodeCapacityReclaims), builder.WithPredicates(lvPred)).
+		Complete(r)
+}
+
+func (r *CapacityReclaimReconciler) nodeCapacityReclaims(ctx context.Context, o client.Object) []reconcile.Request {
+	lv, ok := o.(*topolvmv1.LogicalVolume)
+	if !ok {
+		return nil
+	}
+	crList := &topolvmv1.CapacityReclaimList{}
+	if err := r.client.List(ctx, crList); err != nil {
+		crlog.FromContext(ctx).Error(err, "failed to list CapacityReclaims", "node", lv.Spec.NodeName)
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, cr := range crList.Items {
+		if cr.Spec.NodeName == lv.Spec.NodeName && cr.Status.Phase == topolvmv1.ReclaimPhaseInProgress {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: cr.Name}})
+		}
+	}
+	return requests
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("CapacityReclaim controller", func() {
+	ctx := context.Background()
+
+	// createVolume creates a LogicalVolume of size GiB bound to a PVC through a PersistentVolume.
+	createVolume := func(name, nodeName string, size int64, annotations map[string]string) (*topolvmv1.LogicalVolume, *corev1.PersistentVolumeClaim) {
+		quantity := *resource.NewQuantity(size<<30, resource.BinarySI)
+		lv := &topolvmv1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: topolvmv1.LogicalVolumeSpec{
+				Name:     name,
+				NodeName: nodeName,
+				Size:     quantity,
+			},
+		}
+		Expect(k8sClient.Create(ctx, lv)).To(Succeed())
+
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test", Annotations: annotations},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:    corev1.ResourceList{corev1.ResourceStorage: quantity},
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: topolvm.GetPluginName(), VolumeHandle: name},
+				},
+				ClaimRef: &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name, UID: pvc.UID},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+		return lv, pvc
+	}
+
+	createReclaim := func(name, nodeName, policy, action string, target int64) *topolvmv1.CapacityReclaim {
+		cr := &topolvmv1.CapacityReclaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: topolvmv1.CapacityReclaimSpec{
+				NodeName: nodeName,
+				Target:   *resource.NewQuantity(target<<30, resource.BinarySI),
+				Policy:   policy,
+				Action:   action,
+			},
+		}
+		Expect(k8sClient.Create(ctx, cr)).To(Succeed())
+		return cr
+	}
+
+	reconcile := func(cr *topolvmv1.CapacityReclaim) (ctrl.Result, *topolvmv1.CapacityReclaim) {
+		r := NewCapacityReclaimReconciler(k8sClient)
+		r.recorder = record.NewFakeRecorder(100)
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cr)})
+		Expect(err).NotTo(HaveOccurred())
+		current := &topolvmv1.CapacityReclaim{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cr), current)).To(Succeed())
+		return result, current
+	}
+
+	candidateNames := func(cr *topolvmv1.CapacityReclaim) []string {
+		var names []string
+		for _, c := range cr.Status.Candidates {
+			names = append(names, c.LogicalVolume)
+		}
+		return names
+	}
+
+	It("should select the candidates by policy", func() {
+		volume := func(name string, size int64, unused, preemptible bool) reclaimVolume {
+			return reclaimVolume{
+				candidate:   topolvmv1.ReclaimCandidate{LogicalVolume: name, Size: *resource.NewQuantity(size<<30, resource.BinarySI)},
+				unused:      unused,
+				preemptible: preemptible,
+			}
+		}
+		volumes := []reclaimVolume{
+			volume("a", 1, true, false),
+			volume("b", 4, false, true),
+			volume("c", 2, true, true),
+			volume("d", 2, false, false),
+		}
+		names := func(candidates []topolvmv1.ReclaimCandidate) []string {
+			var names []string
+			for _, c := range candidates {
+				names = append(names, c.LogicalVolume)
+			}
+			return names
+		}
+
+		candidates, selected := selectReclaimCandidates(volumes, topolvmv1.ReclaimPolicySmallest, 4<<30)
+		Expect(names(candidates)).To(Equal([]string{"a", "c", "d"}))
+		Expect(selected).To(Equal(int64(5 << 30)))
+
+		candidates, selected = selectReclaimCandidates(volumes, topolvmv1.ReclaimPolicyUnused, 2<<30)
+		Expect(names(candidates)).To(Equal([]string{"c"}))
+		Expect(selected).To(Equal(int64(2 << 30)))
+
+		candidates, selected = selectReclaimCandidates(volumes, topolvmv1.ReclaimPolicyPreemptible, 5<<30)
+		Expect(names(candidates)).To(Equal([]string{"b", "c"}))
+		Expect(selected).To(Equal(int64(6 << 30)))
+
+		candidates, selected = selectReclaimCandidates(volumes, topolvmv1.ReclaimPolicyUnused, 4<<30)
+		Expect(names(candidates)).To(Equal([]string{"c", "a"}))
+		Expect(selected).To(Equal(int64(3 << 30)))
+	})
+
+	It("should request to reclaim unused volumes and complete once they are deleted", func() {
+		lvA, _ := createVolume("reclaim-unused-a", "node-reclaim-unused", 1, nil)
+		lvB, _ := createVolume("reclaim-unused-b", "node-reclaim-unused", 2, nil)
+		_, pvcC := createVolume("reclaim-unused-c", "node-reclaim-unused", 3, nil)
+		createVolume("reclaim-unused-other-node", "node-reclaim-other", 3, nil)
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "reclaim-unused-user", Namespace: "test"},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "ubuntu", Image: "ubuntu"}},
+				Volumes: []corev1.Volume{{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvcC.Name},
+					},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+
+		cr := createReclaim("reclaim-unused", "node-reclaim-unused", topolvmv1.ReclaimPolicyUnused, "", 3)
+		_, current := reconcile(cr)
+		Expect(current.Status.Phase).To(Equal(topolvmv1.ReclaimPhaseInProgress))
+		Expect(candidateNames(current)).To(Equal([]string{"reclaim-unused-b", "reclaim-unused-a"}))
+		Expect(current.Status.SelectedSize.Value()).To(Equal(int64(3 << 30)))
+		Expect(meta.IsStatusConditionTrue(current.Status.Conditions, topolvmv1.ConditionCandidatesSelected)).To(BeTrue())
+
+		Expect(k8sClient.Delete(ctx, lvB)).To(Succeed())
+		_, current = reconcile(cr)
+		Expect(current.Status.Phase).To(Equal(topolvmv1.ReclaimPhaseInProgress))
+		Expect(current.Status.ReclaimedSize.Value()).To(Equal(int64(2 << 30)))
+
+		Expect(k8sClient.Delete(ctx, lvA)).To(Succeed())
+		_, current = reconcile(cr)
+		Expect(current.Status.Phase).To(Equal(topolvmv1.ReclaimPhaseCompleted))
+		Expect(current.Status.ReclaimedSize.Value()).To(Equal(int64(3 << 30)))
+		Expect(meta.IsStatusConditionTrue(current.Status.Conditions, topolvmv1.ConditionTargetReclaimed)).To(BeTrue())
+	})
+
+	It("should wait until the candidates add up to the target", func() {
+		createVolume("reclaim-insufficient", "node-reclaim-insufficient", 1, nil)
+
+		cr := createReclaim("reclaim-insufficient", "node-reclaim-insufficient", topolvmv1.ReclaimPolicySmallest, "", 2)
+		result, current := reconcile(cr)
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		Expect(current.Status.Phase).To(Equal(topolvmv1.ReclaimPhasePending))
+		Expect(current.Status.Candidates).To(BeEmpty())
+		cond := meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionCandidatesSelected)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(reasonInsufficientCandidates))
+	})
+
+	It("should delete the PVCs of preemptible volumes", func() {
+		preemptible := map[string]string{topolvm.GetPreemptibleKey(): "true"}
+		_, pvcA := createVolume("reclaim-preemptible-a", "node-reclaim-preemptible", 1, preemptible)
+		_, pvcB := createVolume("reclaim-preemptible-b", "node-reclaim-preemptible", 2, nil)
+
+		cr := createReclaim("reclaim-preemptible", "node-reclaim-preemptible",
+			topolvmv1.ReclaimPolicyPreemptible, topolvmv1.ReclaimActionDelete, 1)
+		_, current := reconcile(cr)
+		Expect(current.Status.Phase).To(Equal(topolvmv1.ReclaimPhaseInProgress))
+		Expect(candidateNames(current)).To(Equal([]string{"reclaim-preemptible-a"}))
+
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcA), pvcA)
+		Expect(apierrors.IsNotFound(err) || pvcA.DeletionTimestamp != nil).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvcB), pvcB)).To(Succeed())
+		Expect(pvcB.DeletionTimestamp).To(BeNil())
+	})
+})
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+)
+
+// settingChange is a change of a setting in a configuration.
+// Old is nil for an added setting, and New is nil for a removed one.
+type settingChange struct {
+	Setting string `json:"setting"`
+	Old     any    `json:"old,omitempty"`
+	New     any    `json:"new,omitempty"`
+}
+
+// diffConfig returns the changes of the settings from the ConfigMap data of old to that of new sorted by setting.
+// A setting is identified by the key of the data and its path in the YAML document, e.g.
+// "lvmd.yaml:device-classes[ssd].spare-gb", where the elements of a list are named by their "name" if any.
+// Data that is not YAML is compared as a whole.
+func diffConfig(old, new map[string]string) []settingChange {
+	oldSettings := flattenConfig(old)
+	newSettings := flattenConfig(new)
+
+	var changes []settingChange
+	for setting, o := range oldSettings {
+		n, ok := newSettings[setting]
+		switch {
+		case !ok:
+			changes = append(changes, settingChange{Setting: setting, Old: o})
+		case fmt.Sprint(o) != fmt.Sprint(n):
+			changes = append(changes, settingChange{Setting: setting, Old: o, New: n})
+		}
+	}
+	for setting, n := range newSettings {
+		if _, ok := oldSettings[setting]; !ok {
+			changes = append(changes, settingChange{Setting: setting, New: n})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Setting < changes[j].Setting
+	})
+	return changes
+}
+
+func flattenConfig(data map[string]string) map[string]any {
+	settings := make(map[string]any)
+	for key, value := range data {
+		var doc any
+		if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+			settings[key] = value
+			continue
+		}
+		flattenSetting(key+":", doc, settings)
+	}
+	return settings
+}
+
+func flattenSetting(path string, value any, settings map[string]any) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			settings[path] = v
+		}
+		for k, e := range v {
+			if path[len(path)-1] == ':' {
+				flattenSetting(path+k, e, settings)
+			} else {
+				flattenSetting(path+"."+k, e, settings)
+			}
+		}
+	case []any:
+		if len(v) == 0 {
+			settings[path] = v
+		}
+		for i, e := range v {
+			index := strconv.Itoa(i)
+			if m, ok := e.(map[string]any); ok {
+				if name, ok := m["name"].(string); ok {
+					index = name
+				}
+			}
+			flattenSetting(path+"["+index+"]", e, settings)
+		}
+	default:
+		settings[path] = v
+	}
+}
@@ -0,0 +1,302 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm"
+	topolvmlegacyv1 "github.com/topolvm/topolvm/api/legacy/v1"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DeletedNodePolicy is how LogicalVolumes referring to a deleted node are handled.
+type DeletedNodePolicy string
+
+const (
+	// DeletedNodeOrphan keeps the LogicalVolumes until they are deleted manually.
+	DeletedNodeOrphan = DeletedNodePolicy("orphan")
+	// DeletedNodeForceDelete deletes the LogicalVolumes and their PVCs once the node has been gone for the TTL.
+	DeletedNodeForceDelete = DeletedNodePolicy("force-delete")
+	// DeletedNodeMigrate deletes the LogicalVolumes not bound to a PersistentVolume yet once the node has been gone
+	// for the TTL, and lets their PVCs be scheduled to another node. The other LogicalVolumes are kept.
+	DeletedNodeMigrate = DeletedNodePolicy("migrate")
+)
+
+// The reasons of the NodeDeleted condition.
+const (
+	reasonOrphaned            = "Orphaned"
+	reasonDeletionScheduled   = "DeletionScheduled"
+	reasonMigrationScheduled  = "MigrationScheduled"
+	reasonMigrationImpossible = "MigrationImpossible"
+)
+
+// DeletedNodeReconciler handles LogicalVolumes whose spec.nodeName refers to a node that does not exist,
+// which no topolvm-node reconciles anymore. It sets the NodeDeleted condition explaining the state of
+// the LogicalVolume, and deletes or migrates it according to the policy.
+//
+// The node finalizer of NodeReconciler cleans up the LogicalVolumes of deleted nodes as well, unless it is
+// skipped or the node is removed without it; this reconciler catches the LogicalVolumes left behind.
+type DeletedNodeReconciler struct {
+	client client.Client
+	policy DeletedNodePolicy
+	ttl    time.Duration
+}
+
+// NewDeletedNodeReconciler returns DeletedNodeReconciler.
+// ttl is how long the node must have been gone before the LogicalVolume is deleted or migrated,
+// so that nodes re-registering themselves shortly after are not affected.
+func NewDeletedNodeReconciler(client client.Client, policy DeletedNodePolicy, ttl time.Duration) *DeletedNodeReconciler {
+	return &DeletedNodeReconciler{
+		client: client,
+		policy: policy,
+		ttl:    ttl,
+	}
+}
+
+//+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes,verbs=get;list;watch;update;patch;delete
+//+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;update;delete
+
+// Reconcile handles the LogicalVolume if its node does not exist.
+func (r *DeletedNodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	lv := &topolvmv1.LogicalVolume{}
+	err := r.client.Get(ctx, req.NamespacedName, lv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	if lv.Spec.NodeName == "" {
+		return ctrl.Result{}, nil
+	}
+
+	var node metav1.PartialObjectMetadata
+	node.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+	err = r.client.Get(ctx, types.NamespacedName{Name: lv.Spec.NodeName}, &node)
+	switch {
+	case err == nil:
+		return ctrl.Result{}, r.clearCondition(ctx, lv)
+	case apierrors.IsNotFound(err):
+	default:
+		return ctrl.Result{}, err
+	}
+
+	switch r.policy {
+	case DeletedNodeForceDelete:
+		return r.forceDelete(ctx, log, lv)
+	case DeletedNodeMigrate:
+		return r.migrate(ctx, log, lv)
+	}
+	return ctrl.Result{}, r.setCondition(ctx, lv, reasonOrphaned,
+		fmt.Sprintf("node %s does not exist; the LogicalVolume is kept until it is deleted manually", lv.Spec.NodeName))
+}
+
+// forceDelete deletes the LogicalVolume and the PVC bound to it once the node has been gone for the TTL.
+func (r *DeletedNodeReconciler) forceDelete(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	deadline := r.deadline(lv)
+	if wait := time.Until(deadline); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, r.setCondition(ctx, lv, reasonDeletionScheduled,
+			fmt.Sprintf("node %s does not exist; the LogicalVolume and its PVC will be deleted at %s",
+				lv.Spec.NodeName, deadline.UTC().Format(time.RFC3339)))
+	}
+
+	pvc, err := boundClaim(ctx, r.client, lv)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if pvc != nil {
+		if err := r.client.Delete(ctx, pvc); err != nil && !apierrors.IsNotFound(err) {
+			log.Error(err, "unable to delete PVC", "name", pvc.Name, "namespace", pvc.Namespace)
+			return ctrl.Result{}, err
+		}
+		log.Info("deleted PVC of deleted node", "name", pvc.Name, "namespace", pvc.Namespace, "node", lv.Spec.NodeName)
+	}
+	return ctrl.Result{}, cleanupLogicalVolume(ctx, r.client, log, lv)
+}
+
+// migrate deletes the LogicalVolume once the node has been gone for the TTL if it is not bound to
+// a PersistentVolume yet, and removes the selected node from its PVC so that it is scheduled to another node.
+// A LogicalVolume bound to a PersistentVolume may hold data, so it is kept.
+func (r *DeletedNodeReconciler) migrate(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	var pv corev1.PersistentVolume
+	err := r.client.Get(ctx, types.NamespacedName{Name: lv.Spec.Name}, &pv)
+	switch {
+	case err == nil:
+		return ctrl.Result{}, r.setCondition(ctx, lv, reasonMigrationImpossible,
+			fmt.Sprintf("node %s does not exist and the LogicalVolume is bound to PersistentVolume %s; it is kept until it is deleted manually",
+				lv.Spec.NodeName, pv.Name))
+	case apierrors.IsNotFound(err):
+	default:
+		return ctrl.Result{}, err
+	}
+
+	pvc, err := r.provisioningClaim(ctx, lv)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if pvc == nil {
+		return ctrl.Result{}, r.setCondition(ctx, lv, reasonMigrationImpossible,
+			fmt.Sprintf("node %s does not exist and no PVC waiting for the LogicalVolume is found; it is kept until it is deleted manually",
+				lv.Spec.NodeName))
+	}
+
+	deadline := r.deadline(lv)
+	if wait := time.Until(deadline); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, r.setCondition(ctx, lv, reasonMigrationScheduled,
+			fmt.Sprintf("node %s does not exist; PVC %s/%s will be scheduled to another node at %s",
+				lv.Spec.NodeName, pvc.Namespace, pvc.Name, deadline.UTC().Format(time.RFC3339)))
+	}
+
+	// the selected node is removed first, so that the provisioner does not create the volume on the node again.
+	pvc2 := pvc.DeepCopy()
+	delete(pvc2.Annotations, AnnSelectedNode)
+	if err := r.client.Update(ctx, pvc2); err != nil {
+		log.Error(err, "unable to unset the selected node of PVC", "name", pvc.Name, "namespace", pvc.Namespace)
+		return ctrl.Result{}, err
+	}
+	log.Info("unset the deleted node of PVC", "name", pvc.Name, "namespace", pvc.Namespace, "node", lv.Spec.NodeName)
+	return ctrl.Result{}, cleanupLogicalVolume(ctx, r.client, log, lv)
+}
+
+// deadline returns the time to delete or migrate the LogicalVolume, which is the TTL after the deleted node
+// has been noticed.
+func (r *DeletedNodeReconciler) deadline(lv *topolvmv1.LogicalVolume) time.Time {
+	if cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionNodeDeleted); cond != nil {
+		return cond.LastTransitionTime.Add(r.ttl)
+	}
+	return time.Now().Add(r.ttl)
+}
+
+// boundClaim returns the PVC bound to the PersistentVolume of the LogicalVolume, or nil if there is none.
+func boundClaim(ctx context.Context, c client.Client, lv *topolvmv1.LogicalVolume) (*corev1.PersistentVolumeClaim, error) {
+	var pv corev1.PersistentVolume
+	err := c.Get(ctx, types.NamespacedName{Name: lv.Spec.Name}, &pv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil, nil
+	default:
+		return nil, err
+	}
+	ref := pv.Spec.ClaimRef
+	if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != topolvm.GetPluginName() || ref == nil {
+		return nil, nil
+	}
+
+	var pvc corev1.PersistentVolumeClaim
+	err = c.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, &pvc)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil, nil
+	default:
+		return nil, err
+	}
+	if pvc.UID != ref.UID {
+		return nil, nil
+	}
+	return &pvc, nil
+}
+
+// provisioningClaim returns the PVC the LogicalVolume is being provisioned for, or nil if there is none.
+// The provisioner names the volume of a PVC after its UID.
+func (r *DeletedNodeReconciler) provisioningClaim(ctx context.Context, lv *topolvmv1.LogicalVolume) (*corev1.PersistentVolumeClaim, error) {
+	uid, ok := strings.CutPrefix(lv.Spec.Name, "pvc-")
+	if !ok {
+		return nil, nil
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if err := r.client.List(ctx, &pvcs); err != nil {
+		return nil, err
+	}
+	for _, pvc := range pvcs.Items {
+		if string(pvc.UID) == uid && pvc.Spec.VolumeName == "" && pvc.Annotations[AnnSelectedNode] == lv.Spec.NodeName {
+			return &pvc, nil
+		}
+	}
+	return nil, nil
+}
+
+// setCondition sets the NodeDeleted condition of the LogicalVolume.
+func (r *DeletedNodeReconciler) setCondition(ctx context.Context, lv *topolvmv1.LogicalVolume, reason, message string) error {
+	cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionNodeDeleted)
+	if cond != nil && cond.Status == metav1.ConditionTrue && cond.Reason == reason && cond.Message == message {
+		return nil
+	}
+	lv2 := lv.DeepCopy()
+	meta.SetStatusCondition(&lv2.Status.Conditions, metav1.Condition{
+		Type:    topolvmv1.ConditionNodeDeleted,
+		Status:  metav1.ConditionTrue,
+		Reason:  reason,
+		Message: message,
+	})
+	return r.client.Status().Update(ctx, lv2)
+}
+
+// clearCondition removes the NodeDeleted condition of the LogicalVolume once the node exists again.
+func (r *DeletedNodeReconciler) clearCondition(ctx context.Context, lv *topolvmv1.LogicalVolume) error {
+	if meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionNodeDeleted) == nil {
+		return nil
+	}
+	lv2 := lv.DeepCopy()
+	meta.RemoveStatusCondition(&lv2.Status.Conditions, topolvmv1.ConditionNodeDeleted)
+	return r.client.Status().Update(ctx, lv2)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DeletedNodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	var lv client.Object = &topolvmv1.LogicalVolume{}
+	if topolvm.UseLegacy() {
+		lv = &topolvmlegacyv1.LogicalVolume{}
+	}
+
+	// the LogicalVolumes are reconciled when their node is deleted or created again.
+	nodePred := predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return true },
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		UpdateFunc:  func(event.UpdateEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("deleted-node-controller").
+		For(lv).
+		WatchesMetadata(&corev1.Node{}, handler.EnqueueRequestsFromMapFunc(r.nodeLogicalVolumes), builder.WithPredicates(nodePred)).
+		Complete(r)
+}
+
+func (r *DeletedNodeReconciler) nodeLogicalVolumes(ctx context.Context, o client.Object) []reconcile.Request {
+	lvList := &topolvmv1.LogicalVolumeList{}
+	if err := r.client.List(ctx, lvList); err != nil {
+		crlog.FromContext(ctx).Error(err, "failed to list LogicalVolumes", "node", o.GetName())
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, lv := range lvList.Items {
+		if lv.Spec.NodeName == o.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: lv.Name}})
+		}
+	}
+	return requests
+}
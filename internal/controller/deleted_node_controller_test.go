@@ -0,0 +1,150 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("DeletedNode controller", func() {
+	ctx := context.Background()
+
+	createLV := func(name, nodeName string) *topolvmv1.LogicalVolume {
+		lv := &topolvmv1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Finalizers: []string{topolvm.GetLogicalVolumeFinalizer()},
+			},
+			Spec: topolvmv1.LogicalVolumeSpec{
+				Name:     name,
+				NodeName: nodeName,
+				Size:     *resource.NewQuantity(1<<30, resource.BinarySI),
+			},
+		}
+		Expect(k8sClient.Create(ctx, lv)).To(Succeed())
+		return lv
+	}
+
+	createPVC := func(name, nodeName string) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Namespace:   createNamespace(),
+				Annotations: map[string]string{AnnSelectedNode: nodeName},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: *resource.NewQuantity(1<<30, resource.BinarySI)},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+		return pvc
+	}
+
+	createPV := func(name string, pvc *corev1.PersistentVolumeClaim) {
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:    corev1.ResourceList{corev1.ResourceStorage: *resource.NewQuantity(1<<30, resource.BinarySI)},
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: topolvm.GetPluginName(), VolumeHandle: name},
+				},
+				ClaimRef: &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name, UID: pvc.UID},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+	}
+
+	reconcile := func(r *DeletedNodeReconciler, lv *topolvmv1.LogicalVolume) ctrl.Result {
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lv)})
+		Expect(err).NotTo(HaveOccurred())
+		return result
+	}
+
+	nodeDeletedReason := func(lv *topolvmv1.LogicalVolume) string {
+		current := &topolvmv1.LogicalVolume{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(lv), current)).To(Succeed())
+		cond := meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionNodeDeleted)
+		if cond == nil {
+			return ""
+		}
+		return cond.Reason
+	}
+
+	isDeleted := func(obj client.Object) bool {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+		if apierrors.IsNotFound(err) {
+			return true
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return obj.GetDeletionTimestamp() != nil
+	}
+
+	It("should keep orphaned LogicalVolumes and clear the condition when the node comes back", func() {
+		r := NewDeletedNodeReconciler(k8sClient, DeletedNodeOrphan, 0)
+		lv := createLV("lv-deleted-node-orphan", "node-deleted-orphan")
+
+		reconcile(r, lv)
+		Expect(nodeDeletedReason(lv)).To(Equal(reasonOrphaned))
+		Expect(isDeleted(lv)).To(BeFalse())
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-deleted-orphan"}}
+		Expect(k8sClient.Create(ctx, node)).To(Succeed())
+		reconcile(r, lv)
+		Expect(nodeDeletedReason(lv)).To(BeEmpty())
+	})
+
+	It("should delete LogicalVolumes and their PVCs after the TTL", func() {
+		r := NewDeletedNodeReconciler(k8sClient, DeletedNodeForceDelete, time.Hour)
+		lv := createLV("lv-deleted-node-force", "node-deleted-force")
+		pvc := createPVC("pvc-deleted-node-force", "node-deleted-force")
+		createPV(lv.Spec.Name, pvc)
+
+		result := reconcile(r, lv)
+		Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+		Expect(nodeDeletedReason(lv)).To(Equal(reasonDeletionScheduled))
+		Expect(isDeleted(lv)).To(BeFalse())
+
+		r = NewDeletedNodeReconciler(k8sClient, DeletedNodeForceDelete, 0)
+		reconcile(r, lv)
+		Expect(isDeleted(lv)).To(BeTrue())
+		Expect(isDeleted(pvc)).To(BeTrue())
+	})
+
+	It("should migrate PVCs waiting for LogicalVolumes of deleted nodes", func() {
+		r := NewDeletedNodeReconciler(k8sClient, DeletedNodeMigrate, 0)
+		pvc := createPVC("pvc-migrate", "node-deleted-migrate")
+		lv := createLV("pvc-"+string(pvc.UID), "node-deleted-migrate")
+
+		reconcile(r, lv)
+		Expect(isDeleted(lv)).To(BeTrue())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(pvc), pvc)).To(Succeed())
+		Expect(pvc.Annotations).NotTo(HaveKey(AnnSelectedNode))
+	})
+
+	It("should keep LogicalVolumes bound to PersistentVolumes instead of migrating them", func() {
+		r := NewDeletedNodeReconciler(k8sClient, DeletedNodeMigrate, 0)
+		pvc := createPVC("pvc-migrate-bound", "node-deleted-migrate-bound")
+		lv := createLV("lv-deleted-node-migrate-bound", "node-deleted-migrate-bound")
+		createPV(lv.Spec.Name, pvc)
+
+		reconcile(r, lv)
+		Expect(nodeDeletedReason(lv)).To(Equal(reasonMigrationImpossible))
+		Expect(isDeleted(lv)).To(BeFalse())
+		Expect(isDeleted(pvc)).To(BeFalse())
+	})
+})
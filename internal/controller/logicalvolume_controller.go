@@ -3,17 +3,24 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/topolvm/topolvm"
 	topolvmlegacyv1 "github.com/topolvm/topolvm/api/legacy/v1"
 	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/convert"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
 	apierrs "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -21,23 +28,47 @@ import (
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	// mergeRequeueInterval is the interval to check the progress of a snapshot merge.
+	mergeRequeueInterval = 10 * time.Second
+	// moveRequeueInterval is the interval to check the progress of moving a volume to another thin pool.
+	moveRequeueInterval = 10 * time.Second
+	// snapshotUsageInterval is the interval to update the allocated size of snapshots.
+	snapshotUsageInterval = time.Minute
+	// pausedRequeueInterval is the interval to remind of a LogicalVolume whose reconciliation is paused.
+	pausedRequeueInterval = time.Hour
+	// healthRetryInterval is the interval to check again a LogicalVolume whose LV is in a transient unhealthy state.
+	healthRetryInterval = 10 * time.Second
+	// healthHaltedInterval is the interval to check again a LogicalVolume whose LV needs to be repaired on the node.
+	healthHaltedInterval = 5 * time.Minute
+
+	// healthHaltedMessage is the prefix of status.message of a LogicalVolume not modified because of the state of its LV.
+	// Whether the LogicalVolume is halted is told by the ReconciliationHalted condition.
+	healthHaltedMessage = "the volume is not modified until its state is repaired on the node"
+)
+
 // LogicalVolumeReconciler reconciles a LogicalVolume object
 type LogicalVolumeReconciler struct {
 	client    client.Client
 	nodeName  string
 	vgService proto.VGServiceClient
 	lvService proto.LVServiceClient
+	lvLists   *lvListCache
+	recorder  record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 func NewLogicalVolumeReconcilerWithServices(client client.Client, nodeName string, vgService proto.VGServiceClient, lvService proto.LVServiceClient) *LogicalVolumeReconciler {
+	lvLists := newLVListCache(vgService)
 	return &LogicalVolumeReconciler{
 		client:    client,
 		nodeName:  nodeName,
 		vgService: vgService,
-		lvService: lvService,
+		lvService: invalidatingLVService{LVServiceClient: lvService, cache: lvLists},
+		lvLists:   lvLists,
 	}
 }
 
@@ -70,6 +101,15 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	if lv.Annotations[topolvm.GetPausedKey()] == "true" {
+		// nothing is done to the LV, not even removing it, until the annotation is removed.
+		log.Info("reconciliation of logical volume is paused", "name", lv.Name)
+		pausedLogicalVolumes.WithLabelValues(r.nodeName, lv.Spec.DeviceClass, lv.Name).Set(1)
+		r.recordPaused(lv)
+		return ctrl.Result{RequeueAfter: pausedRequeueInterval}, nil
+	}
+	pausedLogicalVolumes.DeleteLabelValues(r.nodeName, lv.Spec.DeviceClass, lv.Name)
+
 	if lv.ObjectMeta.DeletionTimestamp == nil {
 		if !controllerutil.ContainsFinalizer(lv, topolvm.GetLogicalVolumeFinalizer()) {
 			lv2 := lv.DeepCopy()
@@ -104,11 +144,52 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			return ctrl.Result{}, err
 		}
 
-		err := r.expandLV(ctx, log, lv)
+		// the LVs listed for other LogicalVolumes are reused unless this one asks for a change, e.g. on resyncs.
+		current, err := r.getLV(ctx, log, lv, !lvChangePending(lv))
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if result, proceed, err := r.checkHealth(ctx, log, lv, current); !proceed {
+			return result, err
+		}
+
+		if lv.Spec.Operation == topolvmv1.OperationMerge {
+			return r.mergeLV(ctx, log, lv)
+		}
+
+		if target := lv.Annotations[topolvm.GetMoveToDeviceClassKey()]; (target != "" && target != lv.Spec.DeviceClass) || isMoving(lv) {
+			return r.moveLV(ctx, log, lv)
+		}
+
+		if lv.Status.CurrentSize != nil && lv.Spec.Size.Cmp(*lv.Status.CurrentSize) < 0 {
+			err := r.shrinkLV(ctx, log, lv)
+			if err != nil {
+				log.Error(err, "failed to shrink LV", "name", lv.Name)
+			}
+			return ctrl.Result{}, err
+		}
+
+		err = r.expandLV(ctx, log, lv, current)
 		if err != nil {
 			log.Error(err, "failed to expand LV", "name", lv.Name)
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, err
+
+		var result ctrl.Result
+		if lv.Spec.SnapshotBeforeExpand != "" {
+			result, err = r.pruneExpandSnapshots(ctx, log, lv, false)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		if lv.Spec.Source == "" {
+			return result, nil
+		}
+		usage, err := r.updateSnapshotUsage(ctx, log, lv)
+		if err != nil || (result.RequeueAfter != 0 && result.RequeueAfter < usage.RequeueAfter) {
+			return result, err
+		}
+		return usage, nil
 	}
 
 	// finalization
@@ -118,10 +199,16 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	log.Info("start finalizing LogicalVolume", "name", lv.Name)
+	if lv.Spec.SnapshotBeforeExpand != "" && lv.Status.VolumeID != "" {
+		if _, err := r.pruneExpandSnapshots(ctx, log, lv, true); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 	err := r.removeLVIfExists(ctx, log, lv)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
+	snapshotAllocatedBytes.DeleteLabelValues(r.nodeName, lv.Spec.DeviceClass, lv.Name)
 
 	lv2 := lv.DeepCopy()
 	controllerutil.RemoveFinalizer(lv2, topolvm.GetLogicalVolumeFinalizer())
@@ -135,6 +222,7 @@ func (r *LogicalVolumeReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *LogicalVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("topolvm-node")
 	builder := ctrl.NewControllerManagedBy(mgr)
 	if topolvm.UseLegacy() {
 		builder = builder.For(&topolvmlegacyv1.LogicalVolume{})
@@ -147,7 +235,7 @@ func (r *LogicalVolumeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 func (r *LogicalVolumeReconciler) removeLVIfExists(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
 	// Finalizer's process ( RemoveLV then removeString ) is not atomic,
 	// so checking existence of LV to ensure its idempotence
-	_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: string(lv.UID), DeviceClass: lv.Spec.DeviceClass})
+	_, err := r.lvService.RemoveLV(ctx, convert.RemoveLVRequest(lv))
 	if status.Code(err) == codes.NotFound {
 		log.Info("LV already removed", "name", lv.Name, "uid", lv.UID)
 		return nil
@@ -161,19 +249,23 @@ func (r *LogicalVolumeReconciler) removeLVIfExists(ctx context.Context, log logr
 }
 
 func (r *LogicalVolumeReconciler) volumeExists(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (bool, error) {
-	respList, err := r.vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: lv.Spec.DeviceClass})
+	v, err := r.getLV(ctx, log, lv, false)
 	if err != nil {
-		log.Error(err, "failed to get list of LV")
 		return false, err
 	}
+	return v != nil, nil
+}
 
-	for _, v := range respList.Volumes {
-		if v.Name != string(lv.UID) {
-			continue
-		}
-		return true, nil
+// getLV returns the LVM LV for the LogicalVolume, or nil if it does not exist.
+// If reuse is true, the LVs recently listed for other LogicalVolumes of the device class may be used.
+func (r *LogicalVolumeReconciler) getLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume, reuse bool) (*proto.LogicalVolume, error) {
+	volumes, err := r.lvLists.list(ctx, lv.Spec.DeviceClass, reuse)
+	if err != nil {
+		log.Error(err, "failed to get list of LV")
+		return nil, err
 	}
-	return false, nil
+
+	return convert.FindVolume(volumes, lv), nil
 }
 
 func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
@@ -183,8 +275,6 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 		return nil
 	}
 
-	reqBytes := lv.Spec.Size.Value()
-
 	err := func() error {
 		// In case the controller crashed just after LVM LV creation, LV may already exist.
 		found, err := r.volumeExists(ctx, log, lv)
@@ -196,7 +286,7 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 		if found {
 			log.Info("set volumeID to existing LogicalVolume", "name", lv.Name, "uid", lv.UID, "status.volumeID", lv.Status.VolumeID)
 			// Don't set CurrentSize here because the Spec.Size field may be updated after the LVM LV is created.
-			lv.Status.VolumeID = string(lv.UID)
+			lv.Status.VolumeID = convert.VolumeName(lv)
 			lv.Status.Code = codes.OK
 			lv.Status.Message = ""
 			return nil
@@ -215,52 +305,29 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 				log.Error(err, "unable to fetch source LogicalVolume", "name", lv.Name)
 				return err
 			}
-			sourceVolID := sourcelv.Status.VolumeID
 
 			// Create a snapshot lv
-			resp, err := r.lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
-				Name:         string(lv.UID),
-				DeviceClass:  lv.Spec.DeviceClass,
-				SourceVolume: sourceVolID,
-				// convert to uint64 because lvmd internals and lvm use uint64 but CSI uses int64.
-				// still set sizeGB for legacy purposes, can (but not has to) be removed in next minor release.
-				SizeGb:     uint64(reqBytes >> 30),
-				SizeBytes:  reqBytes,
-				AccessType: lv.Spec.AccessType,
-			})
+			resp, err := r.lvService.CreateLVSnapshot(ctx, convert.CreateLVSnapshotRequest(lv, sourcelv))
 			if err != nil {
-				code, message := extractFromError(err)
-				log.Error(err, message)
-				lv.Status.Code = code
-				lv.Status.Message = message
+				convert.SetError(lv, err)
+				log.Error(err, lv.Status.Message)
 				return err
 			}
+			r.recordWarnings(lv, resp.Warnings)
 			volume = resp.Snapshot
 		} else {
 			// Create a regular lv
-			resp, err := r.lvService.CreateLV(ctx, &proto.CreateLVRequest{
-				Name:                string(lv.UID),
-				DeviceClass:         lv.Spec.DeviceClass,
-				LvcreateOptionClass: lv.Spec.LvcreateOptionClass,
-				// convert to uint64 because lvmd internals and lvm use uint64 but CSI uses int64.
-				// still set sizeGB for legacy purposes, can (but not has to) be removed in next minor release.
-				SizeGb:    uint64(reqBytes >> 30),
-				SizeBytes: reqBytes,
-			})
+			resp, err := r.lvService.CreateLV(ctx, convert.CreateLVRequest(lv))
 			if err != nil {
-				code, message := extractFromError(err)
-				log.Error(err, message)
-				lv.Status.Code = code
-				lv.Status.Message = message
+				convert.SetError(lv, err)
+				log.Error(err, lv.Status.Message)
 				return err
 			}
+			r.recordWarnings(lv, resp.Warnings)
 			volume = resp.Volume
 		}
 
-		lv.Status.VolumeID = volume.Name
-		lv.Status.CurrentSize = resource.NewQuantity(reqBytes, resource.BinarySI)
-		lv.Status.Code = codes.OK
-		lv.Status.Message = ""
+		convert.SetCreated(lv, volume)
 		return nil
 	}()
 
@@ -281,42 +348,62 @@ func (r *LogicalVolumeReconciler) createLV(ctx context.Context, log logr.Logger,
 	return nil
 }
 
-func (r *LogicalVolumeReconciler) expandLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+// expandLV expands the LV to the size in the spec. current is the LV listed by lvmd, or nil if it is not found.
+func (r *LogicalVolumeReconciler) expandLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume, current *proto.LogicalVolume) error {
 	// We denote unknown size as -1.
 	var origBytes int64 = -1
-	switch {
-	case lv.Status.CurrentSize == nil:
-		// topolvm-node may be crashed before setting Status.CurrentSize.
-		// Since the actual volume size is unknown,
-		// we need to do resizing to set Status.CurrentSize to the same value as Spec.Size.
-	case lv.Spec.Size.Cmp(*lv.Status.CurrentSize) <= 0:
-		return nil
-	default:
+	if lv.Status.CurrentSize != nil {
 		origBytes = (*lv.Status.CurrentSize).Value()
 	}
+	// topolvm-node may be crashed before setting Status.CurrentSize,
+	// and the status may be updated without the LV being resized, so the
+	// actual LV is checked to find out if the expansion has to be completed.
+	statusExpanded := lv.Status.CurrentSize != nil && lv.Spec.Size.Cmp(*lv.Status.CurrentSize) <= 0
 
 	reqBytes := lv.Spec.Size.Value()
 
+	// If the LV is not found, ResizeLV below reports the error to the status.
+	lvExpanded := current != nil && current.SizeBytes >= reqBytes
+
+	switch {
+	case statusExpanded && (current == nil || lvExpanded):
+		return nil
+	case lvExpanded:
+		// lvresize succeeded, but the status update did not.
+		convert.SetResized(lv)
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return err
+		}
+		expansionRepairsTotal.WithLabelValues(r.nodeName, lv.Spec.DeviceClass, repairReasonStatus).Inc()
+		log.Info("repaired status of expanded LV", "name", lv.Name, "uid", lv.UID, "status.volumeID", lv.Status.VolumeID,
+			"original status.currentSize", origBytes, "status.currentSize", reqBytes, "lvSize", current.SizeBytes)
+		return nil
+	case statusExpanded:
+		// The status claims the expansion completed, but the LV is still smaller.
+		expansionRepairsTotal.WithLabelValues(r.nodeName, lv.Spec.DeviceClass, repairReasonResize).Inc()
+		log.Info("LV is smaller than status.currentSize, resizing it again", "name", lv.Name, "uid", lv.UID,
+			"status.currentSize", origBytes, "lvSize", current.SizeBytes)
+	}
+
 	err := func() error {
-		_, err := r.lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
-			Name: string(lv.UID),
-			// convert to uint64 because lvmd internals and lvm use uint64 but CSI uses int64.
-			// still set sizeGB for legacy purposes, can (but not has to) be removed in next minor release.
-			SizeGb:      uint64(reqBytes >> 30),
-			SizeBytes:   reqBytes,
-			DeviceClass: lv.Spec.DeviceClass,
-		})
+		if lv.Spec.SnapshotBeforeExpand != "" && current != nil {
+			if err := r.snapshotBeforeExpand(ctx, log, lv, current); err != nil {
+				convert.SetError(lv, err)
+				log.Error(err, lv.Status.Message)
+				return err
+			}
+		}
+
+		resp, err := r.lvService.ResizeLV(ctx, convert.ResizeLVRequest(lv, false))
 		if err != nil {
-			code, message := extractFromError(err)
-			log.Error(err, message)
-			lv.Status.Code = code
-			lv.Status.Message = message
+			convert.SetError(lv, err)
+			log.Error(err, lv.Status.Message)
 			return err
 		}
+		r.recordWarnings(lv, resp.Warnings)
 
-		lv.Status.CurrentSize = resource.NewQuantity(reqBytes, resource.BinarySI)
-		lv.Status.Code = codes.OK
-		lv.Status.Message = ""
+		convert.SetResized(lv)
 		return nil
 	}()
 
@@ -338,6 +425,382 @@ func (r *LogicalVolumeReconciler) expandLV(ctx context.Context, log logr.Logger,
 	return nil
 }
 
+// checkHealth reports the health of the LV listed by lvmd, and decides by its state whether the LogicalVolume is
+// reconciled any further. The LV is listed right before any change, so its health is reported before the change.
+// The reconciliation of a LV in a transient state, e.g. suspended, is retried later, and a LV whose state needs to be
+// repaired on the node, e.g. an invalid snapshot or a thin pool needing a check, is not modified until it is repaired
+// and is checked again every healthHaltedInterval.
+// The other unhealthy states are only reported in the VolumeHealthy condition.
+func (r *LogicalVolumeReconciler) checkHealth(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume, current *proto.LogicalVolume) (ctrl.Result, bool, error) {
+	if current == nil {
+		return ctrl.Result{}, true, nil
+	}
+	r.recordHealth(lv, current)
+	changed := convert.SetHealth(lv, current)
+
+	action, healthErr := healthAction(current)
+	halted := meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.ConditionReconciliationHalted)
+	switch {
+	case action == command.HealthActionHalt:
+		message := fmt.Sprintf("%s: %v", healthHaltedMessage, healthErr)
+		if !halted || lv.Status.Message != message {
+			lv.Status.Code = codes.FailedPrecondition
+			lv.Status.Message = message
+			meta.SetStatusCondition(&lv.Status.Conditions, metav1.Condition{
+				Type:    topolvmv1.ConditionReconciliationHalted,
+				Status:  metav1.ConditionTrue,
+				Reason:  topolvmv1.HaltReasonRepairNeeded,
+				Message: healthErr.Error(),
+			})
+			changed = true
+			r.recordEvent(lv, corev1.EventTypeWarning, "ReconciliationHalted", "%s", message)
+		}
+	case halted:
+		lv.Status.Code = codes.OK
+		lv.Status.Message = ""
+		meta.SetStatusCondition(&lv.Status.Conditions, metav1.Condition{
+			Type:    topolvmv1.ConditionReconciliationHalted,
+			Status:  metav1.ConditionFalse,
+			Reason:  topolvmv1.HaltReasonRepaired,
+			Message: "the volume has been repaired",
+		})
+		changed = true
+	}
+	if changed {
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update health condition", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, false, err
+		}
+	}
+
+	switch action {
+	case command.HealthActionRetry:
+		log.Info("LV is in a transient unhealthy state, retrying later", "name", lv.Name, "uid", lv.UID, "health", healthErr)
+		return ctrl.Result{RequeueAfter: healthRetryInterval}, false, nil
+	case command.HealthActionHalt:
+		log.Info("LV is not modified until it is repaired", "name", lv.Name, "uid", lv.UID, "health", healthErr)
+		return ctrl.Result{RequeueAfter: healthHaltedInterval}, false, nil
+	}
+	return ctrl.Result{}, true, nil
+}
+
+// healthAction returns the most severe action for the health errors of the LV and its thin pool reported by lvmd.
+func healthAction(current *proto.LogicalVolume) (command.HealthAction, error) {
+	healthErr := command.ParseHealthError(current.GetHealthError())
+	action := command.HealthActionFor(healthErr)
+	poolErr := command.ParseHealthError(current.GetPoolHealthError())
+	if poolAction := command.HealthActionFor(poolErr); poolAction > action {
+		return poolAction, poolErr
+	}
+	return action, healthErr
+}
+
+// snapshotBeforeExpand takes a read-only thin snapshot of the LV as a rollback point before expanding it,
+// in case growing its filesystem goes wrong. The snapshot expires after the retention in spec.snapshotBeforeExpand.
+// Thick volumes are expanded without a snapshot, since their snapshots would need space for all the writes.
+func (r *LogicalVolumeReconciler) snapshotBeforeExpand(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume, current *proto.LogicalVolume) error {
+	retention, err := time.ParseDuration(lv.Spec.SnapshotBeforeExpand)
+	if err != nil || retention <= 0 {
+		r.recordEvent(lv, corev1.EventTypeWarning, "ExpandSnapshotSkipped", "invalid snapshotBeforeExpand: %s", lv.Spec.SnapshotBeforeExpand)
+		return nil
+	}
+	volume, err := convert.VolumeFromProto(current)
+	if err != nil {
+		return err
+	}
+	if !volume.Thin {
+		r.recordEvent(lv, corev1.EventTypeWarning, "ExpandSnapshotSkipped", "the volume is expanded without a snapshot since it is not thin")
+		return nil
+	}
+
+	name := convert.ExpandSnapshotName(lv)
+	resp, err := r.vgService.ListSnapshots(ctx, &proto.ListSnapshotsRequest{DeviceClass: lv.Spec.DeviceClass, Origin: lv.Status.VolumeID})
+	if err != nil {
+		log.Error(err, "failed to list snapshots", "name", lv.Name, "uid", lv.UID)
+		return err
+	}
+	for _, snapshot := range resp.GetSnapshots() {
+		if snapshot.GetName() == name {
+			// taken by a previous attempt of the expansion.
+			return nil
+		}
+	}
+
+	expires := time.Now().Add(retention)
+	snapResp, err := r.lvService.CreateLVSnapshot(ctx, convert.ExpandSnapshotRequest(lv, expires))
+	if err != nil {
+		log.Error(err, "failed to take snapshot before expansion", "name", lv.Name, "uid", lv.UID)
+		return err
+	}
+	r.recordWarnings(lv, snapResp.GetWarnings())
+	r.recordEvent(lv, corev1.EventTypeNormal, "ExpandSnapshotTaken", "took snapshot %s before expanding the volume to %s, kept until %s",
+		name, lv.Spec.Size.String(), expires.UTC().Format(time.RFC3339))
+	log.Info("took snapshot before expansion", "name", lv.Name, "uid", lv.UID, "snapshot", name, "expires", expires)
+	return nil
+}
+
+// pruneExpandSnapshots removes the expired snapshots taken before the expansions of the LV, or all of them if all is true.
+// The result requeues the LogicalVolume when the next snapshot expires.
+func (r *LogicalVolumeReconciler) pruneExpandSnapshots(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume, all bool) (ctrl.Result, error) {
+	resp, err := r.vgService.ListSnapshots(ctx, &proto.ListSnapshotsRequest{DeviceClass: lv.Spec.DeviceClass, Origin: lv.Status.VolumeID})
+	if status.Code(err) == codes.NotFound {
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		log.Error(err, "failed to list snapshots", "name", lv.Name, "uid", lv.UID)
+		return ctrl.Result{}, err
+	}
+
+	var result ctrl.Result
+	now := time.Now()
+	for _, snapshot := range resp.GetSnapshots() {
+		expires, ok := convert.ExpandSnapshotExpiry(snapshot)
+		if !ok {
+			continue
+		}
+		if !all && expires.After(now) {
+			if requeueAfter := expires.Sub(now); result.RequeueAfter == 0 || requeueAfter < result.RequeueAfter {
+				result.RequeueAfter = requeueAfter
+			}
+			continue
+		}
+		// the snapshot holds the data of the volume, so it is wiped as the volume would be.
+		_, err := r.lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: snapshot.GetName(), DeviceClass: lv.Spec.DeviceClass, WipeOnDelete: lv.Spec.WipeOnDelete})
+		if err != nil && status.Code(err) != codes.NotFound {
+			log.Error(err, "failed to remove snapshot taken before expansion", "name", lv.Name, "uid", lv.UID, "snapshot", snapshot.GetName())
+			return ctrl.Result{}, err
+		}
+		log.Info("removed snapshot taken before expansion", "name", lv.Name, "uid", lv.UID, "snapshot", snapshot.GetName())
+	}
+	return result, nil
+}
+
+// updateSnapshotUsage reports the space allocated for the snapshot LV in the status and the metrics,
+// and its origin in LVM in the status. The usage of snapshots grows as their sources or themselves are written,
+// so it is updated periodically.
+func (r *LogicalVolumeReconciler) updateSnapshotUsage(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	current, err := r.getLV(ctx, log, lv, false)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if current == nil {
+		return ctrl.Result{}, nil
+	}
+
+	snapshotAllocatedBytes.WithLabelValues(r.nodeName, lv.Spec.DeviceClass, lv.Name).Set(float64(current.AllocatedBytes))
+	changed := convert.SetAllocatedSize(lv, current)
+	if convert.SetOrigin(lv, current) {
+		changed = true
+	}
+	if changed {
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: snapshotUsageInterval}, nil
+}
+
+// shrinkLV shrinks the LV and its filesystem to the size in the spec.
+// Shrinking may lose data, so it is done only if the user confirmed the size with an annotation,
+// and lvmd refuses it unless the device-class allows shrinking and the volume is not in use.
+func (r *LogicalVolumeReconciler) shrinkLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+	reqBytes := lv.Spec.Size.Value()
+	origBytes := lv.Status.CurrentSize.Value()
+
+	if lv.Annotations[topolvm.GetShrinkConfirmationKey()] != lv.Spec.Size.String() {
+		message := fmt.Sprintf("shrinking the volume to %s requires annotation %s=%s",
+			lv.Spec.Size.String(), topolvm.GetShrinkConfirmationKey(), lv.Spec.Size.String())
+		if lv.Status.Code == codes.FailedPrecondition && lv.Status.Message == message {
+			return nil
+		}
+		log.Info("shrinking LV is not confirmed", "name", lv.Name, "uid", lv.UID,
+			"status.currentSize", origBytes, "spec.size", reqBytes)
+		lv.Status.Code = codes.FailedPrecondition
+		lv.Status.Message = message
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return err
+		}
+		return nil
+	}
+
+	resp, err := r.lvService.ResizeLV(ctx, convert.ResizeLVRequest(lv, true))
+	if err != nil {
+		convert.SetError(lv, err)
+		log.Error(err, lv.Status.Message)
+		if err2 := r.client.Status().Update(ctx, lv); err2 != nil {
+			// err2 is logged but not returned because err is more important
+			log.Error(err2, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		}
+		return err
+	}
+	r.recordWarnings(lv, resp.Warnings)
+
+	convert.SetResized(lv)
+	if err := r.client.Status().Update(ctx, lv); err != nil {
+		log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		return err
+	}
+
+	// the confirmation is valid only once.
+	lv2 := lv.DeepCopy()
+	delete(lv2.Annotations, topolvm.GetShrinkConfirmationKey())
+	if err := r.client.Patch(ctx, lv2, client.MergeFrom(lv)); err != nil {
+		log.Error(err, "failed to remove annotation", "name", lv.Name)
+		return err
+	}
+
+	log.Info("shrunk LV", "name", lv.Name, "uid", lv.UID, "status.volumeID", lv.Status.VolumeID,
+		"original status.currentSize", origBytes, "status.currentSize", reqBytes)
+	return nil
+}
+
+// mergeLV merges the snapshot LV back into its source and reports the progress in the status.
+// lvmd merges thick snapshots in the background, so the progress is polled until the merge has completed.
+func (r *LogicalVolumeReconciler) mergeLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	op := lv.Status.Operation
+	if op != nil && op.Name == topolvmv1.OperationMerge && op.Phase == topolvmv1.OperationPhaseCompleted {
+		return ctrl.Result{}, nil
+	}
+	if op == nil || op.Name != topolvmv1.OperationMerge {
+		// record the start of the merge first, since the snapshot cannot be found once it is merged.
+		lv.Status.Operation = &topolvmv1.OperationStatus{
+			Name:  topolvmv1.OperationMerge,
+			Phase: topolvmv1.OperationPhaseInProgress,
+		}
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	resp, err := r.lvService.MergeLVSnapshot(ctx, convert.MergeLVSnapshotRequest(lv))
+	switch {
+	case status.Code(err) == codes.NotFound:
+		// lvmd removes the snapshot once the merge has completed.
+		resp = &proto.MergeLVSnapshotResponse{Completed: true, ProgressPercent: 100}
+	case err != nil:
+		convert.SetError(lv, err)
+		log.Error(err, lv.Status.Message)
+		if err2 := r.client.Status().Update(ctx, lv); err2 != nil {
+			// err2 is logged but not returned because err is more important
+			log.Error(err2, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		}
+		return ctrl.Result{}, err
+	}
+	r.recordWarnings(lv, resp.Warnings)
+
+	phase := topolvmv1.OperationPhaseInProgress
+	if resp.Completed {
+		phase = topolvmv1.OperationPhaseCompleted
+	}
+	lv.Status.Operation = &topolvmv1.OperationStatus{
+		Name:            topolvmv1.OperationMerge,
+		Phase:           phase,
+		ProgressPercent: int32(resp.ProgressPercent),
+	}
+	lv.Status.Code = codes.OK
+	lv.Status.Message = ""
+	if err := r.client.Status().Update(ctx, lv); err != nil {
+		log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		return ctrl.Result{}, err
+	}
+
+	if !resp.Completed {
+		log.Info("merging snapshot LV", "name", lv.Name, "uid", lv.UID, "source", lv.Spec.Source, "progress", resp.ProgressPercent)
+		return ctrl.Result{RequeueAfter: mergeRequeueInterval}, nil
+	}
+	log.Info("merged snapshot LV", "name", lv.Name, "uid", lv.UID, "source", lv.Spec.Source)
+	return ctrl.Result{}, nil
+}
+
+// isMoving returns true if the LV is being moved to another thin pool.
+func isMoving(lv *topolvmv1.LogicalVolume) bool {
+	op := lv.Status.Operation
+	return op != nil && op.Name == topolvmv1.OperationMove && op.Phase == topolvmv1.OperationPhaseInProgress
+}
+
+// moveLV moves the thin LV to the thin pool of the device class requested by the annotation and reports the
+// progress in the status. lvmd copies the volume in the background, so the progress is polled until the move
+// has completed, then the device class of the LV is changed and the annotation is removed.
+// If the annotation is removed while the volume is moved, it is moved back to the pool of its device class.
+func (r *LogicalVolumeReconciler) moveLV(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) (ctrl.Result, error) {
+	if !isMoving(lv) {
+		// record the start of the move first, which keeps NodeStageVolume from using the volume meanwhile.
+		lv.Status.Operation = &topolvmv1.OperationStatus{
+			Name:  topolvmv1.OperationMove,
+			Phase: topolvmv1.OperationPhaseInProgress,
+		}
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	target := lv.Annotations[topolvm.GetMoveToDeviceClassKey()]
+	if target == "" {
+		target = lv.Spec.DeviceClass
+	}
+	resp, err := r.lvService.MoveLV(ctx, convert.MoveLVRequest(lv, target))
+	if err != nil {
+		convert.SetError(lv, err)
+		log.Error(err, lv.Status.Message)
+		if code := lv.Status.Code; code == codes.InvalidArgument || code == codes.NotFound {
+			// the move never succeeds, so it is given up instead of keeping the volume from being staged.
+			lv.Status.Operation = nil
+		}
+		if err2 := r.client.Status().Update(ctx, lv); err2 != nil {
+			// err2 is logged but not returned because err is more important
+			log.Error(err2, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+		if lv.Status.Operation == nil {
+			lv2 := lv.DeepCopy()
+			delete(lv2.Annotations, topolvm.GetMoveToDeviceClassKey())
+			if err := r.client.Patch(ctx, lv2, client.MergeFrom(lv)); err != nil {
+				log.Error(err, "failed to remove annotation", "name", lv.Name)
+			}
+		}
+		return ctrl.Result{}, err
+	}
+
+	if !resp.Completed {
+		lv.Status.Operation.ProgressPercent = int32(resp.ProgressPercent)
+		lv.Status.Code = codes.OK
+		lv.Status.Message = ""
+		if err := r.client.Status().Update(ctx, lv); err != nil {
+			log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+			return ctrl.Result{}, err
+		}
+		log.Info("moving LV", "name", lv.Name, "uid", lv.UID, "target", target, "progress", resp.ProgressPercent)
+		return ctrl.Result{RequeueAfter: moveRequeueInterval}, nil
+	}
+
+	lv2 := lv.DeepCopy()
+	lv2.Spec.DeviceClass = target
+	delete(lv2.Annotations, topolvm.GetMoveToDeviceClassKey())
+	if err := r.client.Patch(ctx, lv2, client.MergeFrom(lv)); err != nil {
+		log.Error(err, "failed to change device class", "name", lv.Name, "device_class", target)
+		return ctrl.Result{}, err
+	}
+	lv2.Status.Operation = &topolvmv1.OperationStatus{
+		Name:            topolvmv1.OperationMove,
+		Phase:           topolvmv1.OperationPhaseCompleted,
+		ProgressPercent: 100,
+	}
+	lv2.Status.Code = codes.OK
+	lv2.Status.Message = ""
+	if err := r.client.Status().Update(ctx, lv2); err != nil {
+		log.Error(err, "failed to update status", "name", lv.Name, "uid", lv.UID)
+		return ctrl.Result{}, err
+	}
+	log.Info("moved LV", "name", lv.Name, "uid", lv.UID, "device_class", target)
+	return ctrl.Result{}, nil
+}
+
 type logicalVolumeFilter struct {
 	nodeName string
 }
@@ -379,14 +842,6 @@ func (f logicalVolumeFilter) Generic(e event.GenericEvent) bool {
 	return f.filter(e.Object)
 }
 
-func extractFromError(err error) (codes.Code, string) {
-	s, ok := status.FromError(err)
-	if !ok {
-		return codes.Internal, err.Error()
-	}
-	return s.Code(), s.Message()
-}
-
 func containsKeyAndValue(labels map[string]string, key, value string) bool {
 	for k, v := range labels {
 		if k == key && v == value {
@@ -395,3 +850,54 @@ func containsKeyAndValue(labels map[string]string, key, value string) bool {
 	}
 	return false
 }
+
+// recordHealth emits an event of lv if lvmd reports its LV as unhealthy.
+func (r *LogicalVolumeReconciler) recordHealth(lv *topolvmv1.LogicalVolume, current *proto.LogicalVolume) {
+	if r.recorder == nil || current.GetHealthError() == "" {
+		return
+	}
+	var obj runtime.Object = lv
+	if topolvm.UseLegacy() {
+		obj = &topolvmlegacyv1.LogicalVolume{ObjectMeta: lv.ObjectMeta}
+	}
+	r.recorder.Event(obj, corev1.EventTypeWarning, "VolumeUnhealthy", current.GetHealthError())
+}
+
+// recordPaused emits an event of lv reminding that its reconciliation is paused.
+func (r *LogicalVolumeReconciler) recordPaused(lv *topolvmv1.LogicalVolume) {
+	if r.recorder == nil {
+		return
+	}
+	var obj runtime.Object = lv
+	if topolvm.UseLegacy() {
+		obj = &topolvmlegacyv1.LogicalVolume{ObjectMeta: lv.ObjectMeta}
+	}
+	r.recorder.Eventf(obj, corev1.EventTypeWarning, "ReconciliationPaused",
+		"the volume is neither resized nor removed until the annotation %s is removed", topolvm.GetPausedKey())
+}
+
+// recordEvent emits an event of lv.
+func (r *LogicalVolumeReconciler) recordEvent(lv *topolvmv1.LogicalVolume, eventType, reason, messageFmt string, args ...any) {
+	if r.recorder == nil {
+		return
+	}
+	var obj runtime.Object = lv
+	if topolvm.UseLegacy() {
+		obj = &topolvmlegacyv1.LogicalVolume{ObjectMeta: lv.ObjectMeta}
+	}
+	r.recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
+
+// recordWarnings emits the warnings printed by lvm while processing lv as events of lv.
+func (r *LogicalVolumeReconciler) recordWarnings(lv *topolvmv1.LogicalVolume, warnings []*proto.Warning) {
+	if r.recorder == nil {
+		return
+	}
+	var obj runtime.Object = lv
+	if topolvm.UseLegacy() {
+		obj = &topolvmlegacyv1.LogicalVolume{ObjectMeta: lv.ObjectMeta}
+	}
+	for _, w := range warnings {
+		r.recorder.Event(obj, corev1.EventTypeWarning, w.Reason, w.Message)
+	}
+}
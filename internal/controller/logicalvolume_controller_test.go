@@ -8,12 +8,19 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/topolvm/topolvm"
 	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/internal/lvmd"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	corev1 "k8s.io/api/core/v1"
 	storegev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -36,11 +43,87 @@ func (c MockVGServiceClient) GetLVList(ctx context.Context, in *proto.GetLVListR
 	}, nil
 }
 
+// ListSnapshots implements proto.VGServiceClient.
+func (MockVGServiceClient) ListSnapshots(ctx context.Context, in *proto.ListSnapshotsRequest, opts ...grpc.CallOption) (*proto.ListSnapshotsResponse, error) {
+	var snapshots []*proto.LogicalVolume
+	for _, v := range *volumes {
+		if v.Origin != "" && v.Origin == in.Origin {
+			snapshots = append(snapshots, v)
+		}
+	}
+	return &proto.ListSnapshotsResponse{Snapshots: snapshots}, nil
+}
+
 // Watch implements proto.VGServiceClient.
 func (MockVGServiceClient) Watch(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (proto.VGService_WatchClient, error) {
 	panic("unimplemented")
 }
 
+// CreateVG implements proto.VGServiceClient.
+func (MockVGServiceClient) CreateVG(ctx context.Context, in *proto.CreateVGRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// RemoveVG implements proto.VGServiceClient.
+func (MockVGServiceClient) RemoveVG(ctx context.Context, in *proto.RemoveVGRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// ExtendVG implements proto.VGServiceClient.
+func (MockVGServiceClient) ExtendVG(ctx context.Context, in *proto.ExtendVGRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// ReduceVG implements proto.VGServiceClient.
+func (MockVGServiceClient) ReduceVG(ctx context.Context, in *proto.ReduceVGRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// EvacuatePV implements proto.VGServiceClient.
+func (MockVGServiceClient) EvacuatePV(ctx context.Context, in *proto.EvacuatePVRequest, opts ...grpc.CallOption) (proto.VGService_EvacuatePVClient, error) {
+	panic("unimplemented")
+}
+
+// ReportThinPoolEvent implements proto.VGServiceClient.
+func (MockVGServiceClient) ReportThinPoolEvent(ctx context.Context, in *proto.ReportThinPoolEventRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// GetThinPoolUsage implements proto.VGServiceClient.
+func (MockVGServiceClient) GetThinPoolUsage(ctx context.Context, in *proto.GetThinPoolUsageRequest, opts ...grpc.CallOption) (*proto.GetThinPoolUsageResponse, error) {
+	panic("unimplemented")
+}
+
+// GetLVMVersion implements proto.VGServiceClient.
+func (MockVGServiceClient) GetLVMVersion(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.GetLVMVersionResponse, error) {
+	panic("unimplemented")
+}
+
+// GetCapabilities implements proto.VGServiceClient.
+func (MockVGServiceClient) GetCapabilities(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.GetCapabilitiesResponse, error) {
+	panic("unimplemented")
+}
+
+// GetLVMDevices implements proto.VGServiceClient.
+func (MockVGServiceClient) GetLVMDevices(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.GetLVMDevicesResponse, error) {
+	panic("unimplemented")
+}
+
+// WatchLVs implements proto.VGServiceClient.
+func (MockVGServiceClient) WatchLVs(ctx context.Context, in *proto.WatchLVsRequest, opts ...grpc.CallOption) (proto.VGService_WatchLVsClient, error) {
+	panic("unimplemented")
+}
+
+// SetDeviceClassDraining implements proto.VGServiceClient.
+func (MockVGServiceClient) SetDeviceClassDraining(ctx context.Context, in *proto.SetDeviceClassDrainingRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
+// UpdateLVMDevices implements proto.VGServiceClient.
+func (MockVGServiceClient) UpdateLVMDevices(ctx context.Context, in *proto.UpdateLVMDevicesRequest, opts ...grpc.CallOption) (*proto.GetLVMDevicesResponse, error) {
+	panic("unimplemented")
+}
+
 type MockLVServiceClient struct {
 }
 
@@ -59,19 +142,82 @@ func (c MockLVServiceClient) CreateLV(ctx context.Context, in *proto.CreateLVReq
 	return &createResponse, nil
 }
 
+// ChangeLVTags implements proto.LVServiceClient.
+func (MockLVServiceClient) ChangeLVTags(ctx context.Context, in *proto.ChangeLVTagsRequest, opts ...grpc.CallOption) (*proto.ChangeLVTagsResponse, error) {
+	panic("unimplemented")
+}
+
+// ActivateLV implements proto.LVServiceClient.
+func (MockLVServiceClient) ActivateLV(ctx context.Context, in *proto.ActivateLVRequest, opts ...grpc.CallOption) (*proto.ActivateLVResponse, error) {
+	panic("unimplemented")
+}
+
+// DeactivateLV implements proto.LVServiceClient.
+func (MockLVServiceClient) DeactivateLV(ctx context.Context, in *proto.DeactivateLVRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	panic("unimplemented")
+}
+
 // CreateLVSnapshot implements proto.LVServiceClient.
 func (MockLVServiceClient) CreateLVSnapshot(ctx context.Context, in *proto.CreateLVSnapshotRequest, opts ...grpc.CallOption) (*proto.CreateLVSnapshotResponse, error) {
+	for _, v := range *volumes {
+		if v.Name == in.SourceVolume {
+			snapshot := &proto.LogicalVolume{Name: in.Name, SizeBytes: v.SizeBytes, Tags: in.Tags, Origin: v.Name}
+			*volumes = append(*volumes, snapshot)
+			return &proto.CreateLVSnapshotResponse{Snapshot: snapshot}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
+}
+
+// MergeLVSnapshot implements proto.LVServiceClient.
+func (MockLVServiceClient) MergeLVSnapshot(ctx context.Context, in *proto.MergeLVSnapshotRequest, opts ...grpc.CallOption) (*proto.MergeLVSnapshotResponse, error) {
+	for i, v := range *volumes {
+		if v.Name == in.Name {
+			*volumes = append((*volumes)[:i], (*volumes)[i+1:]...)
+			return &proto.MergeLVSnapshotResponse{Completed: true, ProgressPercent: 100}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
+}
+
+// CreateDeviceClassSnapshot implements proto.LVServiceClient.
+func (MockLVServiceClient) CreateDeviceClassSnapshot(ctx context.Context, in *proto.CreateDeviceClassSnapshotRequest, opts ...grpc.CallOption) (*proto.CreateDeviceClassSnapshotResponse, error) {
 	panic("unimplemented")
 }
 
+// MoveLV implements proto.LVServiceClient.
+func (MockLVServiceClient) MoveLV(ctx context.Context, in *proto.MoveLVRequest, opts ...grpc.CallOption) (*proto.MoveLVResponse, error) {
+	for _, v := range *volumes {
+		if v.Name == in.Name {
+			return &proto.MoveLVResponse{Completed: true, ProgressPercent: 100}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
+}
+
 // RemoveLV implements proto.LVServiceClient.
 func (MockLVServiceClient) RemoveLV(ctx context.Context, in *proto.RemoveLVRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
-	panic("unimplemented")
+	for i, v := range *volumes {
+		if v.Name == in.Name {
+			*volumes = append((*volumes)[:i], (*volumes)[i+1:]...)
+			return &proto.Empty{}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
 }
 
 // ResizeLV implements proto.LVServiceClient.
-func (MockLVServiceClient) ResizeLV(ctx context.Context, in *proto.ResizeLVRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
-	panic("unimplemented")
+func (MockLVServiceClient) ResizeLV(ctx context.Context, in *proto.ResizeLVRequest, opts ...grpc.CallOption) (*proto.ResizeLVResponse, error) {
+	for _, v := range *volumes {
+		if v.Name == in.Name {
+			if in.SizeBytes < v.SizeBytes && !in.AllowShrink {
+				return nil, status.Error(codes.OutOfRange, "shrinking volume size is not allowed")
+			}
+			v.SizeBytes = in.SizeBytes
+			return &proto.ResizeLVResponse{}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "not found")
 }
 
 var _ = Describe("LogicalVolume controller", func() {
@@ -222,4 +368,391 @@ var _ = Describe("LogicalVolume controller", func() {
 			return !controllerutil.ContainsFinalizer(&lv, topolvm.GetLogicalVolumeFinalizer())
 		}, "2s").Should(BeTrue())
 	})
+
+	It("should repair status.currentSize when the LV has already been expanded", func() {
+		startReconciler("-repair-status")
+
+		ctx := context.Background()
+
+		// Setup
+		lv := setupResources(ctx, "-repair-status")
+
+		// ensure LV is created
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.VolumeID != ""
+		}).Should(BeTrue())
+
+		// Simulate that lvresize succeeded but the status update did not.
+		newSize := resource.MustParse("2Gi")
+		for _, v := range *volumes {
+			if v.Name == string(lv.UID) {
+				v.SizeBytes = newSize.Value()
+			}
+		}
+		lv2 := lv.DeepCopy()
+		lv2.Spec.Size = newSize
+		err := k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Verify
+		// ensure status.currentSize is repaired without calling ResizeLV
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.CurrentSize != nil && lv.Status.CurrentSize.Cmp(newSize) == 0
+		}).Should(BeTrue())
+	})
+
+	It("should report the progress of merging a snapshot LV", func() {
+		startReconciler("-merge")
+
+		ctx := context.Background()
+
+		// Setup
+		lv := setupResources(ctx, "-merge")
+
+		// ensure LV is created
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.VolumeID != ""
+		}).Should(BeTrue())
+
+		lv2 := lv.DeepCopy()
+		lv2.Spec.Operation = topolvmv1.OperationMerge
+		err := k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Verify
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.Operation).NotTo(BeNil())
+			g.Expect(lv.Status.Operation.Name).To(Equal(topolvmv1.OperationMerge))
+			g.Expect(lv.Status.Operation.Phase).To(Equal(topolvmv1.OperationPhaseCompleted))
+			g.Expect(lv.Status.Operation.ProgressPercent).To(BeEquivalentTo(100))
+		}).Should(Succeed())
+	})
+
+	It("should shrink LV only when it is confirmed", func() {
+		startReconciler("-shrink")
+
+		ctx := context.Background()
+
+		// Setup
+		lv := setupResources(ctx, "-shrink")
+
+		// ensure LV is created
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.VolumeID != ""
+		}).Should(BeTrue())
+
+		lv2 := lv.DeepCopy()
+		lv2.Spec.Size = resource.MustParse("2Gi")
+		err := k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.CurrentSize).NotTo(BeNil())
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal("2Gi"))
+		}).Should(Succeed())
+
+		// shrinking without the confirmation is reported in the status
+		lv2 = lv.DeepCopy()
+		lv2.Spec.Size = resource.MustParse("1Gi")
+		err = k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.Code).To(Equal(codes.FailedPrecondition))
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal("2Gi"))
+		}).Should(Succeed())
+
+		lv2 = lv.DeepCopy()
+		lv2.Annotations = map[string]string{topolvm.GetShrinkConfirmationKey(): "1Gi"}
+		err = k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Verify
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.Code).To(Equal(codes.OK))
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal("1Gi"))
+			g.Expect(lv.Annotations).NotTo(HaveKey(topolvm.GetShrinkConfirmationKey()))
+		}).Should(Succeed())
+	})
+
+	It("should not resize LV while the reconciliation is paused", func() {
+		startReconciler("-paused")
+
+		ctx := context.Background()
+
+		// Setup
+		lv := setupResources(ctx, "-paused")
+
+		// ensure LV is created
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.VolumeID != ""
+		}).Should(BeTrue())
+		currentSize := lv.Status.CurrentSize.String()
+
+		lv2 := lv.DeepCopy()
+		lv2.Annotations = map[string]string{topolvm.GetPausedKey(): "true"}
+		lv2.Spec.Size = resource.MustParse("2Gi")
+		err := k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// ensure LV is not resized
+		Consistently(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal(currentSize))
+		}, "2s").Should(Succeed())
+
+		lv2 = lv.DeepCopy()
+		delete(lv2.Annotations, topolvm.GetPausedKey())
+		err = k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Verify
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal("2Gi"))
+		}).Should(Succeed())
+	})
+
+	It("should take a snapshot before expanding a thin LV and remove it after the retention", func() {
+		startReconciler("-expand-snapshot")
+
+		ctx := context.Background()
+
+		// Setup
+		lv := setupResources(ctx, "-expand-snapshot")
+
+		// ensure LV is created
+		Eventually(func(g Gomega) bool {
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)
+			if err != nil {
+				return false
+			}
+			return lv.Status.VolumeID != ""
+		}).Should(BeTrue())
+		for _, v := range *volumes {
+			if v.Name == lv.Status.VolumeID {
+				v.Attr = "Vwi-a-tz--"
+			}
+		}
+
+		lv2 := lv.DeepCopy()
+		lv2.Spec.SnapshotBeforeExpand = "3s"
+		lv2.Spec.Size = resource.MustParse("2Gi")
+		err := k8sClient.Patch(ctx, lv2, client.MergeFrom(&lv))
+		Expect(err).NotTo(HaveOccurred())
+
+		// Verify
+		snapshotName := lv.Status.VolumeID + "-expand-2147483648"
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&lv), &lv)).To(Succeed())
+			g.Expect(lv.Status.CurrentSize.String()).To(Equal("2Gi"))
+			resp, err := vgService.ListSnapshots(ctx, &proto.ListSnapshotsRequest{Origin: lv.Status.VolumeID})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(resp.GetSnapshots()).To(HaveLen(1))
+			g.Expect(resp.GetSnapshots()[0].GetName()).To(Equal(snapshotName))
+		}).Should(Succeed())
+
+		// ensure the snapshot is removed after the retention
+		Eventually(func(g Gomega) {
+			resp, err := vgService.ListSnapshots(ctx, &proto.ListSnapshotsRequest{Origin: lv.Status.VolumeID})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(resp.GetSnapshots()).To(BeEmpty())
+		}).Should(Succeed())
+	})
+})
+
+var _ = Describe("LogicalVolume health", func() {
+	ctx := context.Background()
+	var fake *command.FakeLVM
+	var vgService proto.VGServiceClient
+	var lvService proto.LVServiceClient
+
+	BeforeEach(func() {
+		fake = command.NewFakeLVM()
+		fake.AddVolumeGroup("health-vg", 20<<30)
+		DeferCleanup(command.SetExecutor(fake))
+
+		vg, err := command.FindVolumeGroup(ctx, "health-vg")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = vg.CreatePool(ctx, "pool", 10<<30)
+		Expect(err).NotTo(HaveOccurred())
+
+		dcManager := lvmd.NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{Name: "thick", VolumeGroup: "health-vg", Default: true},
+			{Name: "thin", VolumeGroup: "health-vg", Type: lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool", OverprovisionRatio: 10}},
+		})
+		ctx, cancel := context.WithCancel(ctx)
+		DeferCleanup(cancel)
+		lvService, vgService = lvmd.NewEmbeddedServiceClients(ctx, dcManager, lvmd.NewLvcreateOptionClassManager(nil))
+	})
+
+	reconcile := func(lv *topolvmv1.LogicalVolume) ctrl.Result {
+		r := NewLogicalVolumeReconcilerWithServices(k8sClient, "node-health", vgService, lvService)
+		r.recorder = record.NewFakeRecorder(100)
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lv)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(lv), lv)).To(Succeed())
+		return result
+	}
+
+	createLV := func(name, deviceClass, source string) *topolvmv1.LogicalVolume {
+		lv := &topolvmv1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: topolvmv1.LogicalVolumeSpec{
+				Name:        name,
+				NodeName:    "node-health",
+				DeviceClass: deviceClass,
+				Size:        resource.MustParse("1Gi"),
+				Source:      source,
+			},
+		}
+		if source != "" {
+			lv.Spec.AccessType = "rw"
+		}
+		Expect(k8sClient.Create(ctx, lv)).To(Succeed())
+		// the finalizer and the label are added before the LV is created.
+		for i := 0; i < 3 && lv.Status.VolumeID == ""; i++ {
+			reconcile(lv)
+		}
+		Expect(lv.Status.VolumeID).NotTo(BeEmpty())
+		return lv
+	}
+
+	// setState returns a function putting the LV, or its thin pool, into state, and back into the activation state.
+	setState := func(state command.State, pool bool) func(string, bool) error {
+		return func(name string, repaired bool) error {
+			if pool {
+				name = "pool"
+			}
+			if repaired {
+				return fake.SetState("health-vg", name, 0)
+			}
+			return fake.SetState("health-vg", name, state)
+		}
+	}
+
+	type healthCase struct {
+		name        string
+		deviceClass string
+		snapshot    bool
+		// unhealthy makes the LV of the name unhealthy, or healthy again once it is repaired.
+		unhealthy func(name string, repaired bool) error
+		// size is requested after the LV has become unhealthy. Thick snapshots cannot be resized.
+		size         string
+		requeueAfter time.Duration
+		code         codes.Code
+		reason       string
+		currentSize  string
+	}
+
+	DescribeTable("should act on the state of an unhealthy LV",
+		func(c healthCase) {
+			lv := createLV(c.name, c.deviceClass, "")
+			if c.snapshot {
+				lv = createLV(c.name+"-snapshot", c.deviceClass, lv.Name)
+			}
+			Expect(c.unhealthy(lv.Status.VolumeID, false)).To(Succeed())
+
+			lv2 := lv.DeepCopy()
+			lv2.Spec.Size = resource.MustParse(c.size)
+			Expect(k8sClient.Patch(ctx, lv2, client.MergeFrom(lv))).To(Succeed())
+			lv = lv2
+
+			result := reconcile(lv)
+			Expect(result.RequeueAfter).To(Equal(c.requeueAfter))
+			Expect(lv.Status.Code).To(Equal(c.code))
+			halted := c.code == codes.FailedPrecondition
+			if halted {
+				Expect(lv.Status.Message).To(HavePrefix(healthHaltedMessage))
+			}
+			Expect(meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.ConditionReconciliationHalted)).To(Equal(halted))
+			Expect(lv.Status.CurrentSize.String()).To(Equal(c.currentSize))
+			cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(c.reason))
+
+			By("repairing the LV")
+			Expect(c.unhealthy(lv.Status.VolumeID, true)).To(Succeed())
+			reconcile(lv)
+			Expect(lv.Status.Code).To(Equal(codes.OK))
+			Expect(lv.Status.Message).To(BeEmpty())
+			Expect(lv.Status.CurrentSize.String()).To(Equal(c.size))
+			Expect(meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy)).To(BeTrue())
+			if halted {
+				cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionReconciliationHalted)
+				Expect(cond).NotTo(BeNil())
+				Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+				Expect(cond.Reason).To(Equal(topolvmv1.HaltReasonRepaired))
+			}
+		},
+		Entry("retries a suspended LV", healthCase{
+			name:         "health-suspended",
+			deviceClass:  "thick",
+			unhealthy:    setState(command.StateSuspended, false),
+			size:         "2Gi",
+			requeueAfter: healthRetryInterval,
+			code:         codes.OK,
+			reason:       topolvmv1.HealthReasonVolumeUnhealthy,
+			currentSize:  "1Gi",
+		}),
+		Entry("halts an invalid snapshot", healthCase{
+			name:         "health-invalid-snapshot",
+			deviceClass:  "thick",
+			snapshot:     true,
+			unhealthy:    setState(command.StateInvalidSnapshot, false),
+			size:         "1Gi",
+			requeueAfter: healthHaltedInterval,
+			code:         codes.FailedPrecondition,
+			reason:       topolvmv1.HealthReasonVolumeUnhealthy,
+			currentSize:  "1Gi",
+		}),
+		Entry("halts a thin LV whose pool needs a check", healthCase{
+			name:         "health-pool-check-needed",
+			deviceClass:  "thin",
+			unhealthy:    setState(command.StateThinPoolCheckNeeded, true),
+			size:         "2Gi",
+			requeueAfter: healthHaltedInterval,
+			code:         codes.FailedPrecondition,
+			reason:       topolvmv1.HealthReasonThinPoolUnhealthy,
+			currentSize:  "1Gi",
+		}),
+		Entry("surfaces a thin LV whose pool is out of data space", healthCase{
+			name:        "health-pool-full",
+			deviceClass: "thin",
+			unhealthy: func(_ string, repaired bool) error {
+				if repaired {
+					return fake.SetDataPercent("health-vg", "pool", 50)
+				}
+				return fake.SetDataPercent("health-vg", "pool", 100)
+			},
+			size:        "2Gi",
+			code:        codes.OK,
+			reason:      topolvmv1.HealthReasonThinPoolUnhealthy,
+			currentSize: "2Gi",
+		}),
+	)
 })
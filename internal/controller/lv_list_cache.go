@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc"
+)
+
+// lvListReuseInterval is how long the LVs listed by lvmd are reused for the LogicalVolumes not asking for a change.
+const lvListReuseInterval = 5 * time.Second
+
+// lvListCache shares the LVs of a device class listed by lvmd among the reconciliations of its LogicalVolumes,
+// so that a resync of all the LogicalVolumes of a node lists the LVs of each device class about once rather than
+// once per volume. The lists are dropped on any change to the LVs made through invalidatingLVService.
+type lvListCache struct {
+	vgService proto.VGServiceClient
+
+	mu sync.Mutex
+	// generation is incremented on every change to the LVs, so that a list taken across a change is not kept.
+	generation uint64
+	lists      map[string]lvList
+}
+
+type lvList struct {
+	volumes    []*proto.LogicalVolume
+	generation uint64
+	listedAt   time.Time
+}
+
+func newLVListCache(vgService proto.VGServiceClient) *lvListCache {
+	return &lvListCache{
+		vgService: vgService,
+		lists:     make(map[string]lvList),
+	}
+}
+
+// list returns the LVs of the device class. If reuse is true, the LVs listed within lvListReuseInterval are returned
+// without calling lvmd.
+func (c *lvListCache) list(ctx context.Context, deviceClass string, reuse bool) ([]*proto.LogicalVolume, error) {
+	c.mu.Lock()
+	generation := c.generation
+	l, ok := c.lists[deviceClass]
+	c.mu.Unlock()
+	if reuse && ok && l.generation == generation && time.Since(l.listedAt) < lvListReuseInterval {
+		return l.volumes, nil
+	}
+
+	listedAt := time.Now()
+	resp, err := c.vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: deviceClass})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.lists[deviceClass] = lvList{volumes: resp.Volumes, generation: generation, listedAt: listedAt}
+	}
+	return resp.Volumes, nil
+}
+
+func (c *lvListCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.lists = make(map[string]lvList)
+}
+
+// lvChangePending reports whether the LogicalVolume asks for a change of its LV,
+// which is then decided from the LVs listed by lvmd right away.
+func lvChangePending(lv *topolvmv1.LogicalVolume) bool {
+	return lv.Status.CurrentSize == nil || lv.Spec.Size.Cmp(*lv.Status.CurrentSize) != 0 ||
+		lv.Spec.Operation != "" || lv.Annotations[topolvm.GetMoveToDeviceClassKey()] != "" || isMoving(lv)
+}
+
+// invalidatingLVService drops the lists of lvListCache once a call which may change the LVs returns.
+type invalidatingLVService struct {
+	proto.LVServiceClient
+	cache *lvListCache
+}
+
+func (s invalidatingLVService) CreateLV(ctx context.Context, in *proto.CreateLVRequest, opts ...grpc.CallOption) (*proto.CreateLVResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.CreateLV(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) RemoveLV(ctx context.Context, in *proto.RemoveLVRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.RemoveLV(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) ResizeLV(ctx context.Context, in *proto.ResizeLVRequest, opts ...grpc.CallOption) (*proto.ResizeLVResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.ResizeLV(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) ChangeLVTags(ctx context.Context, in *proto.ChangeLVTagsRequest, opts ...grpc.CallOption) (*proto.ChangeLVTagsResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.ChangeLVTags(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) ActivateLV(ctx context.Context, in *proto.ActivateLVRequest, opts ...grpc.CallOption) (*proto.ActivateLVResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.ActivateLV(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) DeactivateLV(ctx context.Context, in *proto.DeactivateLVRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.DeactivateLV(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) CreateLVSnapshot(ctx context.Context, in *proto.CreateLVSnapshotRequest, opts ...grpc.CallOption) (*proto.CreateLVSnapshotResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.CreateLVSnapshot(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) MergeLVSnapshot(ctx context.Context, in *proto.MergeLVSnapshotRequest, opts ...grpc.CallOption) (*proto.MergeLVSnapshotResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.MergeLVSnapshot(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) CreateDeviceClassSnapshot(ctx context.Context, in *proto.CreateDeviceClassSnapshotRequest, opts ...grpc.CallOption) (*proto.CreateDeviceClassSnapshotResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.CreateDeviceClassSnapshot(ctx, in, opts...)
+}
+
+func (s invalidatingLVService) MoveLV(ctx context.Context, in *proto.MoveLVRequest, opts ...grpc.CallOption) (*proto.MoveLVResponse, error) {
+	defer s.cache.invalidate()
+	return s.LVServiceClient.MoveLV(ctx, in, opts...)
+}
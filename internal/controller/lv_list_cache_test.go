@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type countingVGServiceClient struct {
+	MockVGServiceClient
+	lists int
+}
+
+func (c *countingVGServiceClient) GetLVList(ctx context.Context, in *proto.GetLVListRequest, opts ...grpc.CallOption) (*proto.GetLVListResponse, error) {
+	c.lists++
+	return c.MockVGServiceClient.GetLVList(ctx, in, opts...)
+}
+
+var _ = Describe("lvListCache", func() {
+	ctx := context.Background()
+
+	It("should reuse the LVs until they are changed", func() {
+		vgService := &countingVGServiceClient{}
+		cache := newLVListCache(vgService)
+		lvService := invalidatingLVService{LVServiceClient: MockLVServiceClient{}, cache: cache}
+
+		_, err := cache.list(ctx, "ssd", true)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = cache.list(ctx, "ssd", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vgService.lists).To(Equal(1))
+
+		By("listing another device class")
+		_, err = cache.list(ctx, "hdd", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vgService.lists).To(Equal(2))
+
+		By("listing the LVs right away")
+		_, err = cache.list(ctx, "ssd", false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vgService.lists).To(Equal(3))
+
+		By("changing an LV")
+		_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "missing", DeviceClass: "ssd"})
+		Expect(err).To(HaveOccurred())
+		_, err = cache.list(ctx, "ssd", true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(vgService.lists).To(Equal(4))
+	})
+
+	DescribeTable("should list the LVs right away for the LogicalVolumes asking for a change",
+		func(modify func(lv *topolvmv1.LogicalVolume), pending bool) {
+			size := resource.MustParse("1Gi")
+			lv := &topolvmv1.LogicalVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "lv"},
+				Spec:       topolvmv1.LogicalVolumeSpec{Size: size},
+				Status:     topolvmv1.LogicalVolumeStatus{CurrentSize: &size},
+			}
+			modify(lv)
+			Expect(lvChangePending(lv)).To(Equal(pending))
+		},
+		Entry("up to date", func(lv *topolvmv1.LogicalVolume) {}, false),
+		Entry("no current size", func(lv *topolvmv1.LogicalVolume) { lv.Status.CurrentSize = nil }, true),
+		Entry("resized", func(lv *topolvmv1.LogicalVolume) { lv.Spec.Size = resource.MustParse("2Gi") }, true),
+		Entry("merging", func(lv *topolvmv1.LogicalVolume) { lv.Spec.Operation = topolvmv1.OperationMerge }, true),
+		Entry("moving", func(lv *topolvmv1.LogicalVolume) {
+			lv.Annotations = map[string]string{topolvm.GetMoveToDeviceClassKey(): "ssd"}
+		}, true),
+	)
+})
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// rolloutRequeueInterval is the interval to check the progress of a rollout.
+	rolloutRequeueInterval = 10 * time.Second
+)
+
+// LVMdConfigRolloutReconciler restarts the pods running lvmd one failure domain at a time
+// when the ConfigMap holding the lvmd configuration is changed.
+//
+// The DaemonSet of the pods must use the OnDelete update strategy. The reconciler stamps the hash
+// of the configuration on the pod template, deletes the outdated pods of a failure domain, and waits until
+// the new pods are ready and the capacity of their nodes is published again before moving to the next domain.
+type LVMdConfigRolloutReconciler struct {
+	client      client.Client
+	daemonSets  map[types.NamespacedName]string
+	topologyKey string
+
+	mu sync.Mutex
+	// applied is the data of the ConfigMaps last rolled out to compare the changes with.
+	applied map[types.NamespacedName]map[string]string
+}
+
+// NewLVMdConfigRolloutReconciler returns LVMdConfigRolloutReconciler.
+// daemonSets maps the ConfigMaps holding lvmd configurations to the names of the DaemonSets mounting them
+// in the same namespace. Nodes are grouped into failure domains by the value of the topologyKey label.
+func NewLVMdConfigRolloutReconciler(client client.Client, daemonSets map[types.NamespacedName]string, topologyKey string) *LVMdConfigRolloutReconciler {
+	if topologyKey == "" {
+		topologyKey = corev1.LabelHostname
+	}
+	return &LVMdConfigRolloutReconciler{
+		client:      client,
+		daemonSets:  daemonSets,
+		topologyKey: topologyKey,
+		applied:     make(map[types.NamespacedName]map[string]string),
+	}
+}
+
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;patch
+//+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
+
+// Reconcile rolls the lvmd configuration out to the pods of the DaemonSet.
+func (r *LVMdConfigRolloutReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	dsName, ok := r.daemonSets[req.NamespacedName]
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+
+	cm := &corev1.ConfigMap{}
+	err := r.client.Get(ctx, req.NamespacedName, cm)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	hash := configHash(cm)
+
+	ds := &appsv1.DaemonSet{}
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: req.Namespace, Name: dsName}, ds)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		log.Info("DaemonSet is not found", "name", dsName)
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	if ds.Spec.UpdateStrategy.Type != appsv1.OnDeleteDaemonSetStrategyType {
+		// the DaemonSet controller would restart all the pods by itself.
+		log.Error(nil, "DaemonSet must use the OnDelete update strategy to roll out lvmd configuration", "name", dsName)
+		return ctrl.Result{}, nil
+	}
+
+	if ds.Spec.Template.Annotations[topolvm.GetLVMdConfigHashKey()] != hash {
+		ds2 := ds.DeepCopy()
+		if ds2.Spec.Template.Annotations == nil {
+			ds2.Spec.Template.Annotations = make(map[string]string)
+		}
+		ds2.Spec.Template.Annotations[topolvm.GetLVMdConfigHashKey()] = hash
+		if err := r.client.Patch(ctx, ds2, client.MergeFrom(ds)); err != nil {
+			log.Error(err, "failed to stamp the configuration hash", "name", dsName)
+			return ctrl.Result{}, err
+		}
+		log.Info("started rolling out lvmd configuration", "daemonset", dsName, "hash", hash)
+		r.logChanges(log, req.NamespacedName, cm)
+		return ctrl.Result{Requeue: true}, nil
+	}
+	r.setApplied(req.NamespacedName, cm)
+
+	done, err := r.rollout(ctx, log, ds, hash)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !done {
+		return ctrl.Result{RequeueAfter: rolloutRequeueInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// logChanges logs the settings changed from the configuration last rolled out, so that the changes are auditable.
+// All the settings are logged if the controller has not seen the last configuration since it started.
+func (r *LVMdConfigRolloutReconciler) logChanges(log logr.Logger, name types.NamespacedName, cm *corev1.ConfigMap) {
+	old, known := r.setApplied(name, cm)
+	log.Info("lvmd configuration changed", "configmap", name.String(),
+		"previousKnown", known, "changes", diffConfig(old, cm.Data))
+}
+
+// setApplied records the data of cm as rolled out and returns the previous one if any.
+func (r *LVMdConfigRolloutReconciler) setApplied(name types.NamespacedName, cm *corev1.ConfigMap) (map[string]string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.applied[name]
+	r.applied[name] = cm.Data
+	return old, ok
+}
+
+// rollout restarts the outdated pods of the next failure domain if the previous one has completed.
+// It returns true when all the pods run with the configuration of hash.
+func (r *LVMdConfigRolloutReconciler) rollout(ctx context.Context, log logr.Logger, ds *appsv1.DaemonSet, hash string) (bool, error) {
+	if ds.Status.ObservedGeneration < ds.Generation ||
+		ds.Status.NumberReady < ds.Status.DesiredNumberScheduled {
+		log.Info("waiting for the pods of DaemonSet to be ready", "name", ds.Name)
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(ds.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+	var pods corev1.PodList
+	if err := r.client.List(ctx, &pods, client.InNamespace(ds.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, err
+	}
+
+	outdated := make(map[string][]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		if pod.DeletionTimestamp != nil || !isPodReady(pod) {
+			log.Info("waiting for the pod to be ready", "pod", pod.Name)
+			return false, nil
+		}
+
+		node := &metav1.PartialObjectMetadata{}
+		node.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+		if err := r.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+
+		if pod.Annotations[topolvm.GetLVMdConfigHashKey()] != hash {
+			domain := node.Labels[r.topologyKey]
+			outdated[domain] = append(outdated[domain], pod)
+			continue
+		}
+
+		// the pod runs with the new configuration, check the capacity is published again.
+		if _, ok := node.Annotations[topolvm.GetLVMdConfigHashKey()]; !ok {
+			continue
+		}
+		if !hasCapacityAnnotation(node) {
+			log.Info("waiting for the capacity to be published", "node", node.Name)
+			return false, nil
+		}
+		node2 := node.DeepCopy()
+		delete(node2.Annotations, topolvm.GetLVMdConfigHashKey())
+		if err := r.client.Patch(ctx, node2, client.MergeFrom(node)); err != nil {
+			return false, err
+		}
+	}
+
+	if len(outdated) == 0 {
+		return true, nil
+	}
+
+	domains := make([]string, 0, len(outdated))
+	for domain := range outdated {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, pod := range outdated[domains[0]] {
+		if err := r.restartPod(ctx, log, pod, hash); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// restartPod withdraws the capacity of the node of the pod and deletes the pod.
+// The capacity annotations are published again by topolvm-node once the new lvmd is running,
+// so that no volume is scheduled to the node while lvmd is being restarted.
+func (r *LVMdConfigRolloutReconciler) restartPod(ctx context.Context, log logr.Logger, pod *corev1.Pod, hash string) error {
+	node := &metav1.PartialObjectMetadata{}
+	node.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+	if err := r.client.Get(ctx, types.NamespacedName{Name: pod.Spec.NodeName}, node); err != nil {
+		return err
+	}
+	node2 := node.DeepCopy()
+	if node2.Annotations == nil {
+		node2.Annotations = make(map[string]string)
+	}
+	for key := range node2.Annotations {
+		if strings.HasPrefix(key, topolvm.GetCapacityKeyPrefix()) || strings.HasPrefix(key, topolvm.GetThickCapacityKeyPrefix()) {
+			delete(node2.Annotations, key)
+		}
+	}
+	node2.Annotations[topolvm.GetLVMdConfigHashKey()] = hash
+	if err := r.client.Patch(ctx, node2, client.MergeFrom(node)); err != nil {
+		log.Error(err, "failed to withdraw the capacity", "node", node.Name)
+		return err
+	}
+
+	if err := r.client.Delete(ctx, pod); err != nil && !apierrors.IsNotFound(err) {
+		log.Error(err, "failed to delete pod", "pod", pod.Name)
+		return err
+	}
+	log.Info("restarted lvmd to roll out configuration", "pod", pod.Name, "node", pod.Spec.NodeName)
+	return nil
+}
+
+func configHash(cm *corev1.ConfigMap) string {
+	keys := make([]string, 0, len(cm.Data)+len(cm.BinaryData))
+	for k := range cm.Data {
+		keys = append(keys, k)
+	}
+	for k := range cm.BinaryData {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00", k)
+		if v, ok := cm.Data[k]; ok {
+			h.Write([]byte(v))
+		} else {
+			h.Write(cm.BinaryData[k])
+		}
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func hasCapacityAnnotation(node client.Object) bool {
+	for key := range node.GetAnnotations() {
+		if strings.HasPrefix(key, topolvm.GetCapacityKeyPrefix()) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LVMdConfigRolloutReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	pred := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		_, ok := r.daemonSets[client.ObjectKeyFromObject(o)]
+		return ok
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("lvmd-config-rollout-controller").
+		For(&corev1.ConfigMap{}, builder.WithPredicates(pred)).
+		Complete(r)
+}
@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("LVMdConfigRollout controller", func() {
+	ctx := context.Background()
+	var stopFunc func()
+	errCh := make(chan error)
+
+	startReconciler := func(daemonSets map[types.NamespacedName]string) {
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme: scheme,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		reconciler := NewLVMdConfigRolloutReconciler(mgr.GetClient(), daemonSets, "")
+		err = reconciler.SetupWithManager(mgr)
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx, cancel := context.WithCancel(ctx)
+		stopFunc = cancel
+		go func() {
+			errCh <- mgr.Start(ctx)
+		}()
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	AfterEach(func() {
+		stopFunc()
+		Expect(<-errCh).NotTo(HaveOccurred())
+	})
+
+	It("should restart lvmd one node at a time", func() {
+		ns := createNamespace()
+		labels := map[string]string{"app.kubernetes.io/component": "lvmd"}
+
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "lvmd", Namespace: ns},
+			Data:       map[string]string{"lvmd.yaml": "device-classes: []"},
+		}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+
+		ds := &appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "lvmd", Namespace: ns},
+			Spec: appsv1.DaemonSetSpec{
+				Selector:       &metav1.LabelSelector{MatchLabels: labels},
+				UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: labels},
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{{Name: "lvmd", Image: "topolvm"}},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, ds)).To(Succeed())
+
+		for _, name := range []string{"rollout-node1", "rollout-node2"} {
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        name,
+					Labels:      map[string]string{corev1.LabelHostname: name},
+					Annotations: map[string]string{topolvm.GetCapacityKeyPrefix() + "ssd": "1073741824"},
+				},
+			}
+			Expect(k8sClient.Create(ctx, node)).To(Succeed())
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "lvmd-" + name,
+					Namespace:   ns,
+					Labels:      labels,
+					Annotations: map[string]string{topolvm.GetLVMdConfigHashKey(): "outdated"},
+				},
+				Spec: corev1.PodSpec{
+					NodeName:   name,
+					Containers: []corev1.Container{{Name: "lvmd", Image: "topolvm"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+			pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+			Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+		}
+
+		startReconciler(map[types.NamespacedName]string{{Namespace: ns, Name: "lvmd"}: "lvmd"})
+
+		By("stamping the configuration hash on the pod template")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(ds), ds)).To(Succeed())
+			g.Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue(topolvm.GetLVMdConfigHashKey(), configHash(cm)))
+		}).Should(Succeed())
+
+		ds.Status.ObservedGeneration = ds.Generation
+		ds.Status.DesiredNumberScheduled = 2
+		ds.Status.NumberReady = 2
+		Expect(k8sClient.Status().Update(ctx, ds)).To(Succeed())
+
+		By("restarting lvmd of the first node")
+		Eventually(func(g Gomega) {
+			pod := &corev1.Pod{}
+			err := k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: "lvmd-rollout-node1"}, pod)
+			if err == nil {
+				g.Expect(pod.DeletionTimestamp).NotTo(BeNil())
+			}
+
+			node := &corev1.Node{}
+			g.Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "rollout-node1"}, node)).To(Succeed())
+			g.Expect(node.Annotations).NotTo(HaveKey(topolvm.GetCapacityKeyPrefix() + "ssd"))
+			g.Expect(node.Annotations).To(HaveKeyWithValue(topolvm.GetLVMdConfigHashKey(), configHash(cm)))
+		}).Should(Succeed())
+
+		By("keeping lvmd of the second node running")
+		pod := &corev1.Pod{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Namespace: ns, Name: "lvmd-rollout-node2"}, pod)).To(Succeed())
+		Expect(pod.DeletionTimestamp).To(BeNil())
+		node := &corev1.Node{}
+		Expect(k8sClient.Get(ctx, types.NamespacedName{Name: "rollout-node2"}, node)).To(Succeed())
+		Expect(node.Annotations).To(HaveKey(topolvm.GetCapacityKeyPrefix() + "ssd"))
+	})
+})
+
+var _ = Describe("diffConfig", func() {
+	It("should list the changed settings", func() {
+		old := map[string]string{
+			"lvmd.yaml": `
+socket-name: /run/topolvm/lvmd.sock
+device-classes:
+  - name: ssd
+    volume-group: myvg1
+    spare-gb: 10
+  - name: hdd
+    volume-group: myvg2
+`,
+		}
+		new := map[string]string{
+			"lvmd.yaml": `
+socket-name: /run/topolvm/lvmd.sock
+device-classes:
+  - name: hdd
+    volume-group: myvg2
+  - name: ssd
+    volume-group: myvg1
+    spare-gb: 20
+    default: true
+`,
+			"README": "not YAML: [",
+		}
+
+		Expect(diffConfig(old, new)).To(Equal([]settingChange{
+			{Setting: "README", New: "not YAML: ["},
+			{Setting: "lvmd.yaml:device-classes[ssd].default", New: true},
+			{Setting: "lvmd.yaml:device-classes[ssd].spare-gb", Old: float64(10), New: float64(20)},
+		}))
+		Expect(diffConfig(new, new)).To(BeEmpty())
+	})
+})
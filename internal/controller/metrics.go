@@ -0,0 +1,42 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "topolvm"
+
+const (
+	// repairReasonStatus is used when the LV had already been resized but the status was not updated.
+	repairReasonStatus = "status"
+	// repairReasonResize is used when the status claims the expansion completed but the LV is smaller than requested.
+	repairReasonResize = "resize"
+)
+
+var expansionRepairsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "logicalvolume",
+	Name:      "expansion_repairs_total",
+	Help:      "The number of LogicalVolume expansions repaired because the status and the LVM LV size disagreed",
+}, []string{"node", "device_class", "reason"})
+
+var snapshotAllocatedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "snapshot",
+	Name:      "allocated_bytes",
+	Help:      "The space allocated for the snapshot LogicalVolume",
+}, []string{"node", "device_class", "logicalvolume"})
+
+var pausedLogicalVolumes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "logicalvolume",
+	Name:      "paused",
+	Help:      "1 if the reconciliation of the LogicalVolume is paused by the annotation",
+}, []string{"node", "device_class", "logicalvolume"})
+
+func init() {
+	metrics.Registry.MustRegister(expansionRepairsTotal)
+	metrics.Registry.MustRegister(snapshotAllocatedBytes)
+	metrics.Registry.MustRegister(pausedLogicalVolumes)
+}
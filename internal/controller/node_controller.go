@@ -2,6 +2,8 @@ package controller
 
 import (
 	"context"
+	"strings"
+	"sync"
 
 	"github.com/go-logr/logr"
 	"github.com/topolvm/topolvm"
@@ -11,32 +13,46 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // NodeReconciler reconciles a Node object
 type NodeReconciler struct {
 	client           client.Client
 	skipNodeFinalize bool
+	// scaleDownProtection enables the annotation keeping cluster-autoscaler from removing nodes with logical volumes.
+	scaleDownProtection bool
+	recorder            record.EventRecorder
+
+	mu sync.Mutex
+	// emergencies holds the device classes in capacity emergency for each node.
+	emergencies map[string]map[string]string
 }
 
 // NewNodeReconciler returns NodeReconciler.
-func NewNodeReconciler(client client.Client, skipNodeFinalize bool) *NodeReconciler {
+func NewNodeReconciler(client client.Client, skipNodeFinalize, scaleDownProtection bool) *NodeReconciler {
 	return &NodeReconciler{
-		client:           client,
-		skipNodeFinalize: skipNodeFinalize,
+		client:              client,
+		skipNodeFinalize:    skipNodeFinalize,
+		scaleDownProtection: scaleDownProtection,
+		emergencies:         make(map[string]map[string]string),
 	}
 }
 
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;update;patch
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;delete
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 // Reconcile finalize Node
 func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -49,12 +65,20 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	switch {
 	case err == nil:
 	case apierrors.IsNotFound(err):
+		r.mu.Lock()
+		delete(r.emergencies, req.Name)
+		r.mu.Unlock()
 		return ctrl.Result{}, nil
 	default:
 		return ctrl.Result{}, err
 	}
 
+	r.reportEmergencies(&node)
+
 	if node.DeletionTimestamp == nil {
+		if r.scaleDownProtection {
+			return ctrl.Result{}, r.protectFromScaleDown(ctx, log, &node)
+		}
 		return ctrl.Result{}, nil
 	}
 
@@ -76,6 +100,78 @@ func (r *NodeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.
 	return ctrl.Result{}, nil
 }
 
+// reportEmergencies emits events when device classes of the node enter or leave capacity emergency.
+// While in emergency, topolvm-controller and topolvm-scheduler treat the device class as having no free space.
+func (r *NodeReconciler) reportEmergencies(node *v1.PartialObjectMetadata) {
+	current := make(map[string]string)
+	for key, reason := range node.Annotations {
+		dc, ok := strings.CutPrefix(key, topolvm.GetCapacityEmergencyKeyPrefix())
+		if !ok || dc == topolvm.DefaultDeviceClassAnnotationName {
+			continue
+		}
+		current[dc] = reason
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.emergencies[node.Name]
+	for dc, reason := range current {
+		if _, ok := previous[dc]; !ok {
+			r.recorder.Eventf(node, corev1.EventTypeWarning, "CapacityEmergency",
+				"stopped scheduling new volumes to device class %s: %s", dc, reason)
+		}
+	}
+	for dc := range previous {
+		if _, ok := current[dc]; !ok {
+			r.recorder.Eventf(node, corev1.EventTypeNormal, "CapacityEmergencyResolved",
+				"resumed scheduling new volumes to device class %s", dc)
+		}
+	}
+
+	if len(current) == 0 {
+		delete(r.emergencies, node.Name)
+	} else {
+		r.emergencies[node.Name] = current
+	}
+}
+
+// protectFromScaleDown sets the scale-down-disabled annotation of cluster-autoscaler on the node while it hosts
+// logical volumes, so that the autoscaler does not remove the node with the local data. The annotation is
+// removed when the last logical volume is gone, unless it was set by someone else.
+func (r *NodeReconciler) protectFromScaleDown(ctx context.Context, log logr.Logger, node *v1.PartialObjectMetadata) error {
+	lvList := &topolvmv1.LogicalVolumeList{}
+	if err := r.client.List(ctx, lvList, client.MatchingFields{keyLogicalVolumeNode: node.GetName()}); err != nil {
+		log.Error(err, "failed to get LogicalVolumes")
+		return err
+	}
+	hasVolumes := len(lvList.Items) > 0
+	_, disabled := node.Annotations[topolvm.ClusterAutoscalerScaleDownDisabledKey]
+	_, owned := node.Annotations[topolvm.GetScaleDownDisabledKey()]
+
+	node2 := node.DeepCopy()
+	switch {
+	case hasVolumes && !disabled:
+		if node2.Annotations == nil {
+			node2.Annotations = make(map[string]string)
+		}
+		node2.Annotations[topolvm.ClusterAutoscalerScaleDownDisabledKey] = "true"
+		node2.Annotations[topolvm.GetScaleDownDisabledKey()] = "true"
+	case !hasVolumes && owned:
+		if disabled {
+			delete(node2.Annotations, topolvm.ClusterAutoscalerScaleDownDisabledKey)
+		}
+		delete(node2.Annotations, topolvm.GetScaleDownDisabledKey())
+	default:
+		return nil
+	}
+	if err := r.client.Patch(ctx, node2, client.MergeFrom(node)); err != nil {
+		log.Error(err, "failed to update scale-down protection", "name", node.Name)
+		return err
+	}
+	log.Info("updated scale-down protection", "name", node.Name, "disabled", hasVolumes, "logical_volumes", len(lvList.Items))
+	return nil
+}
+
 func (r *NodeReconciler) targetStorageClasses(ctx context.Context) (map[string]bool, error) {
 	var scl storagev1.StorageClassList
 	if err := r.client.List(ctx, &scl); err != nil {
@@ -134,7 +230,7 @@ func (r *NodeReconciler) doFinalize(ctx context.Context, log logr.Logger, node c
 		return err
 	}
 	for _, lv := range lvList.Items {
-		err = r.cleanupLogicalVolume(ctx, log, &lv)
+		err = cleanupLogicalVolume(ctx, r.client, log, &lv)
 		if err != nil {
 			return err
 		}
@@ -143,7 +239,9 @@ func (r *NodeReconciler) doFinalize(ctx context.Context, log logr.Logger, node c
 	return nil
 }
 
-func (r *NodeReconciler) cleanupLogicalVolume(ctx context.Context, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
+// cleanupLogicalVolume deletes the LogicalVolume of a deleted node, removing its finalizer
+// as no topolvm-node is left to remove the logical volume.
+func cleanupLogicalVolume(ctx context.Context, c client.Client, log logr.Logger, lv *topolvmv1.LogicalVolume) error {
 	if controllerutil.ContainsFinalizer(lv, topolvm.GetLogicalVolumeFinalizer()) {
 		lv2 := lv.DeepCopy()
 		if lv2.Annotations == nil {
@@ -152,7 +250,7 @@ func (r *NodeReconciler) cleanupLogicalVolume(ctx context.Context, log logr.Logg
 		// Flag the LV as pending deletion, so the LogicalVolumeReconciler doesn't re-add the finalizer before it sees the deletion
 		lv2.Annotations[topolvm.GetLVPendingDeletionKey()] = "true"
 		controllerutil.RemoveFinalizer(lv2, topolvm.GetLogicalVolumeFinalizer())
-		if err := r.client.Patch(ctx, lv2, client.MergeFrom(lv)); err != nil {
+		if err := c.Patch(ctx, lv2, client.MergeFrom(lv)); err != nil {
 			if apierrors.IsNotFound(err) {
 				log.Info("LogicalVolume is already deleted", "name", lv.Name)
 				return nil
@@ -162,7 +260,7 @@ func (r *NodeReconciler) cleanupLogicalVolume(ctx context.Context, log logr.Logg
 		}
 	}
 
-	if err := r.client.Delete(ctx, lv); err != nil {
+	if err := c.Delete(ctx, lv); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("LogicalVolume is already deleted", "name", lv.Name)
 			return nil
@@ -176,6 +274,8 @@ func (r *NodeReconciler) cleanupLogicalVolume(ctx context.Context, log logr.Logg
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("topolvm-controller")
+
 	ctx := context.Background()
 	err := mgr.GetFieldIndexer().IndexField(ctx, &corev1.PersistentVolumeClaim{}, keySelectedNode, func(o client.Object) []string {
 		return []string{o.(*corev1.PersistentVolumeClaim).Annotations[AnnSelectedNode]}
@@ -207,9 +307,28 @@ func (r *NodeReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		GenericFunc: func(event.GenericEvent) bool { return false },
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
-		WithEventFilter(pred).
+	b := ctrl.NewControllerManagedBy(mgr).
 		Named("node-controller").
-		WatchesMetadata(&corev1.Node{}, &handler.EnqueueRequestForObject{}).
-		Complete(r)
+		WatchesMetadata(&corev1.Node{}, &handler.EnqueueRequestForObject{}, builder.WithPredicates(pred))
+	if r.scaleDownProtection {
+		// the node is reconciled when its logical volumes are created or deleted.
+		var lv client.Object = &topolvmv1.LogicalVolume{}
+		if topolvm.UseLegacy() {
+			lv = &topolvmlegacyv1.LogicalVolume{}
+		}
+		b = b.Watches(lv, handler.EnqueueRequestsFromMapFunc(func(_ context.Context, o client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: logicalVolumeNodeName(o)}}}
+		}))
+	}
+	return b.Complete(r)
+}
+
+func logicalVolumeNodeName(o client.Object) string {
+	switch lv := o.(type) {
+	case *topolvmv1.LogicalVolume:
+		return lv.Spec.NodeName
+	case *topolvmlegacyv1.LogicalVolume:
+		return lv.Spec.NodeName
+	}
+	return ""
 }
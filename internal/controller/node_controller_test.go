@@ -24,13 +24,13 @@ var _ = Describe("NodeController controller", func() {
 	var stopFunc func()
 	errCh := make(chan error)
 
-	startReconciler := func(skipNodeFinalize bool) {
+	startReconciler := func(skipNodeFinalize, scaleDownProtection bool) {
 		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 			Scheme: scheme,
 		})
 		Expect(err).ToNot(HaveOccurred())
 
-		reconciler := NewNodeReconciler(mgr.GetClient(), skipNodeFinalize)
+		reconciler := NewNodeReconciler(mgr.GetClient(), skipNodeFinalize, scaleDownProtection)
 		err = reconciler.SetupWithManager(mgr)
 		Expect(err).NotTo(HaveOccurred())
 
@@ -108,7 +108,7 @@ var _ = Describe("NodeController controller", func() {
 	}
 
 	It("should delete PVC and LogicalVolume when the node is deleted if the finalizer is not skipped", func() {
-		startReconciler(false)
+		startReconciler(false, false)
 
 		ctx := context.Background()
 
@@ -146,7 +146,7 @@ var _ = Describe("NodeController controller", func() {
 	})
 
 	It("should not touch PVC and LogicalVolume when the node is deleted if the finalizer is skipped", func() {
-		startReconciler(true)
+		startReconciler(true, false)
 
 		ctx := context.Background()
 
@@ -175,4 +175,98 @@ var _ = Describe("NodeController controller", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(lv.DeletionTimestamp).To(BeNil())
 	})
+
+	It("should emit events when a device class enters and leaves capacity emergency", func() {
+		startReconciler(false, false)
+
+		ctx := context.Background()
+
+		// Setup
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-emergency",
+				Annotations: map[string]string{
+					topolvm.GetCapacityEmergencyKeyPrefix() + "thin": "thin pool data usage 96.00% exceeds the critical threshold 95.00%",
+				},
+			},
+		}
+		err := k8sClient.Create(ctx, &node)
+		Expect(err).NotTo(HaveOccurred())
+
+		hasEvent := func(g Gomega, reason string) {
+			var events corev1.EventList
+			g.Expect(k8sClient.List(ctx, &events)).To(Succeed())
+			found := false
+			for _, e := range events.Items {
+				if e.InvolvedObject.Name == node.Name && e.Reason == reason {
+					found = true
+				}
+			}
+			g.Expect(found).To(BeTrue(), "event %s is not found", reason)
+		}
+
+		// Verify
+		Eventually(func(g Gomega) {
+			hasEvent(g, "CapacityEmergency")
+		}).Should(Succeed())
+
+		// Exercise
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&node), &node)).To(Succeed())
+		delete(node.Annotations, topolvm.GetCapacityEmergencyKeyPrefix()+"thin")
+		Expect(k8sClient.Update(ctx, &node)).To(Succeed())
+
+		// Verify
+		Eventually(func(g Gomega) {
+			hasEvent(g, "CapacityEmergencyResolved")
+		}).Should(Succeed())
+	})
+
+	It("should protect nodes hosting LogicalVolumes from scale down", func() {
+		startReconciler(false, true)
+
+		ctx := context.Background()
+
+		// Setup
+		node, _, lv := setupResources(ctx, "-scale-down")
+
+		// Verify
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&node), &node)).To(Succeed())
+			g.Expect(node.Annotations).To(HaveKeyWithValue(topolvm.ClusterAutoscalerScaleDownDisabledKey, "true"))
+			g.Expect(node.Annotations).To(HaveKey(topolvm.GetScaleDownDisabledKey()))
+		}).Should(Succeed())
+
+		// Exercise
+		Expect(k8sClient.Delete(ctx, &lv)).To(Succeed())
+
+		// Verify
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&node), &node)).To(Succeed())
+			g.Expect(node.Annotations).NotTo(HaveKey(topolvm.ClusterAutoscalerScaleDownDisabledKey))
+			g.Expect(node.Annotations).NotTo(HaveKey(topolvm.GetScaleDownDisabledKey()))
+		}).Should(Succeed())
+	})
+
+	It("should keep the scale-down-disabled annotation set by others", func() {
+		startReconciler(false, true)
+
+		ctx := context.Background()
+
+		// Setup
+		node := corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-scale-down-user",
+				Annotations: map[string]string{
+					topolvm.ClusterAutoscalerScaleDownDisabledKey: "true",
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, &node)).To(Succeed())
+
+		// Verify
+		Consistently(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(&node), &node)).To(Succeed())
+			g.Expect(node.Annotations).To(HaveKeyWithValue(topolvm.ClusterAutoscalerScaleDownDisabledKey, "true"))
+		}).Should(Succeed())
+	})
 })
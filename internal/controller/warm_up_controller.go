@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// WarmUpTemplateKey is the key of the ConfigMap referred by the warm-up parameter holding the Job template in YAML.
+	WarmUpTemplateKey = "job.yaml"
+	// WarmUpVolumeName is the name of the pod volume of the PVC added to the warm-up Job, to be mounted by its containers.
+	WarmUpVolumeName = "data"
+
+	// warmUpVolumeLabel is the label of the warm-up Jobs holding the name of their LogicalVolume.
+	warmUpVolumeLabel = "topolvm.io/warm-up-volume"
+	// warmUpTemplateRetryInterval is the interval to create the warm-up Job again from an invalid template.
+	warmUpTemplateRetryInterval = time.Minute
+)
+
+// WarmUpReconciler runs the warm-up Job of the volumes provisioned with the warm-up parameter once their PVC is bound,
+// and tracks the Job in the WarmedUp condition of the LogicalVolume. topolvm-node publishes the volume only to the pods
+// of the Job until the condition is True.
+//
+// The Job is created in the namespace of the PVC from the template in the ConfigMap referred by the parameter,
+// with the PVC added as the volume named WarmUpVolumeName. It is owned by the LogicalVolume, so that it is deleted
+// along with the volume. A failed Job is created again when it is deleted.
+type WarmUpReconciler struct {
+	client    client.Client
+	apiReader client.Reader
+	recorder  record.EventRecorder
+}
+
+// NewWarmUpReconciler returns WarmUpReconciler. The templates are read by apiReader, so that ConfigMaps are not cached.
+func NewWarmUpReconciler(client client.Client, apiReader client.Reader) *WarmUpReconciler {
+	return &WarmUpReconciler{
+		client:    client,
+		apiReader: apiReader,
+	}
+}
+
+//+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get
+//+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
+
+// Reconcile creates the warm-up Job of the LogicalVolume and reflects its progress in the WarmedUp condition.
+func (r *WarmUpReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := crlog.FromContext(ctx)
+
+	lv := &topolvmv1.LogicalVolume{}
+	err := r.client.Get(ctx, req.NamespacedName, lv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return ctrl.Result{}, nil
+	default:
+		return ctrl.Result{}, err
+	}
+	if lv.DeletionTimestamp != nil || meta.IsStatusConditionTrue(lv.Status.Conditions, topolvmv1.ConditionWarmedUp) {
+		return ctrl.Result{}, nil
+	}
+
+	template, pvc, err := r.warmUpTarget(ctx, lv)
+	if err != nil || pvc == nil {
+		// the LogicalVolume is reconciled again when its PersistentVolume is bound.
+		return ctrl.Result{}, err
+	}
+
+	job := &batchv1.Job{}
+	err = r.client.Get(ctx, types.NamespacedName{Namespace: pvc.Namespace, Name: topolvm.WarmUpJobNamePrefix + lv.Name}, job)
+	switch {
+	case err == nil:
+		return ctrl.Result{}, r.trackJob(ctx, lv, pvc, job)
+	case apierrors.IsNotFound(err):
+	default:
+		return ctrl.Result{}, err
+	}
+
+	job, err = r.buildJob(ctx, template, lv, pvc)
+	if err == nil {
+		err = r.client.Create(ctx, job)
+	}
+	switch {
+	case err == nil:
+	case apierrors.IsAlreadyExists(err):
+		// the cache has not seen the Job yet.
+		return ctrl.Result{}, nil
+	case errors.As(err, new(warmUpTemplateError)), apierrors.IsInvalid(err):
+		log.Error(err, "invalid warm-up template", "name", lv.Name, "template", template)
+		r.recorder.Eventf(pvc, corev1.EventTypeWarning, "WarmUpInvalidTemplate", "unable to create the warm-up Job from %s: %v", template, err)
+		return ctrl.Result{RequeueAfter: warmUpTemplateRetryInterval}, r.setCondition(ctx, lv, metav1.ConditionFalse,
+			topolvmv1.WarmUpReasonInvalidTemplate, fmt.Sprintf("unable to create the warm-up Job from %s: %v", template, err))
+	default:
+		return ctrl.Result{}, err
+	}
+
+	log.Info("created warm-up Job", "name", lv.Name, "job", job.Name, "namespace", job.Namespace)
+	r.recorder.Eventf(pvc, corev1.EventTypeNormal, "WarmUpStarted", "started warm-up Job %s", job.Name)
+	return ctrl.Result{}, r.setCondition(ctx, lv, metav1.ConditionFalse, topolvmv1.WarmUpReasonRunning,
+		fmt.Sprintf("warm-up Job %s/%s is running", job.Namespace, job.Name))
+}
+
+// warmUpTarget returns the warm-up template of the LogicalVolume and its PVC.
+// The PVC is nil if the LogicalVolume has no warm-up template or its PersistentVolume is not bound yet.
+func (r *WarmUpReconciler) warmUpTarget(ctx context.Context, lv *topolvmv1.LogicalVolume) (string, *corev1.PersistentVolumeClaim, error) {
+	pv := &corev1.PersistentVolume{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: lv.Spec.Name}, pv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return "", nil, nil
+	default:
+		return "", nil, err
+	}
+	if pv.Spec.CSI == nil || pv.Spec.CSI.VolumeAttributes[topolvm.GetWarmUpKey()] == "" {
+		return "", nil, nil
+	}
+	pvc, err := boundClaim(ctx, r.client, lv)
+	if err != nil || pvc == nil || pvc.DeletionTimestamp != nil {
+		return "", nil, err
+	}
+	return pv.Spec.CSI.VolumeAttributes[topolvm.GetWarmUpKey()], pvc, nil
+}
+
+// warmUpTemplateError is the error of a warm-up template which is missing or broken.
+type warmUpTemplateError struct {
+	error
+}
+
+// buildJob returns the warm-up Job of the LogicalVolume from the template in the ConfigMap named by template.
+func (r *WarmUpReconciler) buildJob(ctx context.Context, template string, lv *topolvmv1.LogicalVolume, pvc *corev1.PersistentVolumeClaim) (*batchv1.Job, error) {
+	namespace, name, _ := strings.Cut(template, "/")
+	cm := &corev1.ConfigMap{}
+	err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cm)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		return nil, warmUpTemplateError{err}
+	default:
+		return nil, err
+	}
+	data, ok := cm.Data[WarmUpTemplateKey]
+	if !ok {
+		return nil, warmUpTemplateError{fmt.Errorf("ConfigMap %s has no %s", template, WarmUpTemplateKey)}
+	}
+	tmpl := &batchv1.Job{}
+	if err := yaml.UnmarshalStrict([]byte(data), tmpl); err != nil {
+		return nil, warmUpTemplateError{fmt.Errorf("invalid %s: %w", WarmUpTemplateKey, err)}
+	}
+	job, err := warmUpJob(tmpl, lv, pvc, r.client)
+	if err != nil {
+		return nil, warmUpTemplateError{err}
+	}
+	return job, nil
+}
+
+// warmUpJob returns the warm-up Job of the LogicalVolume made from tmpl, mounting the PVC as WarmUpVolumeName.
+func warmUpJob(tmpl *batchv1.Job, lv *topolvmv1.LogicalVolume, pvc *corev1.PersistentVolumeClaim, c client.Client) (*batchv1.Job, error) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        topolvm.WarmUpJobNamePrefix + lv.Name,
+			Namespace:   pvc.Namespace,
+			Labels:      map[string]string{},
+			Annotations: tmpl.Annotations,
+		},
+		Spec: *tmpl.Spec.DeepCopy(),
+	}
+	for k, v := range tmpl.Labels {
+		job.Labels[k] = v
+	}
+	job.Labels[warmUpVolumeLabel] = lv.Name
+
+	for _, volume := range job.Spec.Template.Spec.Volumes {
+		if volume.Name == WarmUpVolumeName {
+			return nil, fmt.Errorf("the volume %s of the template is reserved for the PVC", WarmUpVolumeName)
+		}
+	}
+	job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: WarmUpVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+		},
+	})
+
+	// a cluster-scoped owner is allowed for the namespaced Job.
+	if err := ctrl.SetControllerReference(lv, job, c.Scheme()); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// trackJob sets the WarmedUp condition of the LogicalVolume from the conditions of its warm-up Job.
+func (r *WarmUpReconciler) trackJob(ctx context.Context, lv *topolvmv1.LogicalVolume, pvc *corev1.PersistentVolumeClaim, job *batchv1.Job) error {
+	log := crlog.FromContext(ctx)
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			if err := r.setCondition(ctx, lv, metav1.ConditionTrue, topolvmv1.WarmUpReasonSucceeded,
+				fmt.Sprintf("warm-up Job %s/%s has succeeded", job.Namespace, job.Name)); err != nil {
+				return err
+			}
+			log.Info("warm-up Job succeeded", "name", lv.Name, "job", job.Name, "namespace", job.Namespace)
+			r.recorder.Eventf(pvc, corev1.EventTypeNormal, "WarmUpSucceeded", "warm-up Job %s has succeeded", job.Name)
+			return nil
+		case batchv1.JobFailed:
+			if current := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionWarmedUp); current != nil && current.Reason == topolvmv1.WarmUpReasonFailed {
+				return nil
+			}
+			if err := r.setCondition(ctx, lv, metav1.ConditionFalse, topolvmv1.WarmUpReasonFailed,
+				fmt.Sprintf("warm-up Job %s/%s has failed: %s; delete the Job to retry", job.Namespace, job.Name, cond.Message)); err != nil {
+				return err
+			}
+			log.Info("warm-up Job failed", "name", lv.Name, "job", job.Name, "namespace", job.Namespace, "reason", cond.Reason)
+			r.recorder.Eventf(pvc, corev1.EventTypeWarning, "WarmUpFailed", "warm-up Job %s has failed: %s", job.Name, cond.Message)
+			return nil
+		}
+	}
+	return r.setCondition(ctx, lv, metav1.ConditionFalse, topolvmv1.WarmUpReasonRunning,
+		fmt.Sprintf("warm-up Job %s/%s is running", job.Namespace, job.Name))
+}
+
+func (r *WarmUpReconciler) setCondition(ctx context.Context, lv *topolvmv1.LogicalVolume, status metav1.ConditionStatus, reason, message string) error {
+	lv2 := lv.DeepCopy()
+	meta.SetStatusCondition(&lv2.Status.Conditions, metav1.Condition{
+		Type:    topolvmv1.ConditionWarmedUp,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+	if equality.Semantic.DeepEqual(lv.Status, lv2.Status) {
+		return nil
+	}
+	return r.client.Status().Update(ctx, lv2)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WarmUpReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.recorder = mgr.GetEventRecorderFor("topolvm-controller")
+
+	// the LogicalVolumes are named after their PersistentVolumes, which are reconciled once bound.
+	pvPred := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		pv, ok := o.(*corev1.PersistentVolume)
+		return ok && pv.Spec.CSI != nil && pv.Spec.CSI.Driver == topolvm.GetPluginName() &&
+			pv.Spec.CSI.VolumeAttributes[topolvm.GetWarmUpKey()] != "" && pv.Status.Phase == corev1.VolumeBound
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("warm-up-controller").
+		For(&topolvmv1.LogicalVolume{}).
+		Owns(&batchv1.Job{}).
+		Watches(&corev1.PersistentVolume{}, handler.EnqueueRequestsFromMapFunc(
+			func(_ context.Context, o client.Object) []reconcile.Request {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: o.GetName()}}}
+			}), builder.WithPredicates(pvPred)).
+		Complete(r)
+}
@@ -0,0 +1,192 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("WarmUp controller", func() {
+	ctx := context.Background()
+
+	const jobTemplate = `
+metadata:
+  labels:
+    app: seed
+spec:
+  backoffLimit: 0
+  template:
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: seed
+          image: ubuntu
+          command: ["mkdir", "-p", "/data/cache"]
+          volumeMounts:
+            - name: data
+              mountPath: /data
+`
+
+	createTemplate := func(name string, data map[string]string) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Data:       data,
+		}
+		Expect(k8sClient.Create(ctx, cm)).To(Succeed())
+	}
+
+	// createVolume creates a LogicalVolume bound to a PVC through a PersistentVolume provisioned with the template.
+	createVolume := func(name, template string) (*topolvmv1.LogicalVolume, *corev1.PersistentVolumeClaim) {
+		quantity := resource.MustParse("1Gi")
+		lv := &topolvmv1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: topolvmv1.LogicalVolumeSpec{
+				Name:     name,
+				NodeName: "node-warm-up",
+				Size:     quantity,
+			},
+		}
+		Expect(k8sClient.Create(ctx, lv)).To(Succeed())
+
+		pvc := &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pvc)).To(Succeed())
+
+		var attributes map[string]string
+		if template != "" {
+			attributes = map[string]string{topolvm.GetWarmUpKey(): template}
+		}
+		pv := &corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeSpec{
+				Capacity:    corev1.ResourceList{corev1.ResourceStorage: quantity},
+				AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				PersistentVolumeSource: corev1.PersistentVolumeSource{
+					CSI: &corev1.CSIPersistentVolumeSource{Driver: topolvm.GetPluginName(), VolumeHandle: name, VolumeAttributes: attributes},
+				},
+				ClaimRef: &corev1.ObjectReference{Namespace: pvc.Namespace, Name: pvc.Name, UID: pvc.UID},
+			},
+		}
+		Expect(k8sClient.Create(ctx, pv)).To(Succeed())
+		return lv, pvc
+	}
+
+	reconcile := func(lv *topolvmv1.LogicalVolume) (ctrl.Result, *topolvmv1.LogicalVolume) {
+		r := NewWarmUpReconciler(k8sClient, k8sClient)
+		r.recorder = record.NewFakeRecorder(100)
+		result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(lv)})
+		Expect(err).NotTo(HaveOccurred())
+		current := &topolvmv1.LogicalVolume{}
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(lv), current)).To(Succeed())
+		return result, current
+	}
+
+	getJob := func(lv *topolvmv1.LogicalVolume) (*batchv1.Job, error) {
+		job := &batchv1.Job{}
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: "test", Name: topolvm.WarmUpJobNamePrefix + lv.Name}, job)
+		return job, err
+	}
+
+	setJobCondition := func(job *batchv1.Job, conditionType batchv1.JobConditionType) {
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		if conditionType == batchv1.JobComplete {
+			job.Status.CompletionTime = &now
+			job.Status.Succeeded = 1
+		} else {
+			job.Status.Failed = 1
+		}
+		job.Status.Conditions = append(job.Status.Conditions, batchv1.JobCondition{
+			Type:               conditionType,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: now,
+			Message:            "test",
+		})
+		Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+	}
+
+	It("should run the warm-up Job and complete once it succeeds", func() {
+		createTemplate("warm-up-seed", map[string]string{WarmUpTemplateKey: jobTemplate})
+		lv, pvc := createVolume("warm-up-succeeded", "test/warm-up-seed")
+
+		_, current := reconcile(lv)
+		cond := meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionWarmedUp)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(topolvmv1.WarmUpReasonRunning))
+
+		job, err := getJob(lv)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(job.Labels).To(HaveKeyWithValue("app", "seed"))
+		Expect(job.Labels).To(HaveKeyWithValue(warmUpVolumeLabel, lv.Name))
+		Expect(metav1.IsControlledBy(job, current)).To(BeTrue())
+		Expect(job.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: WarmUpVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: pvc.Name},
+			},
+		}))
+
+		setJobCondition(job, batchv1.JobComplete)
+		_, current = reconcile(lv)
+		Expect(meta.IsStatusConditionTrue(current.Status.Conditions, topolvmv1.ConditionWarmedUp)).To(BeTrue())
+	})
+
+	It("should report the failed warm-up Job", func() {
+		lv, _ := createVolume("warm-up-failed", "test/warm-up-seed")
+
+		reconcile(lv)
+		job, err := getJob(lv)
+		Expect(err).NotTo(HaveOccurred())
+		setJobCondition(job, batchv1.JobFailed)
+
+		_, current := reconcile(lv)
+		cond := meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionWarmedUp)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		Expect(cond.Reason).To(Equal(topolvmv1.WarmUpReasonFailed))
+	})
+
+	It("should report an invalid template and retry later", func() {
+		createTemplate("warm-up-invalid", map[string]string{"job": jobTemplate})
+		lv, _ := createVolume("warm-up-invalid", "test/warm-up-invalid")
+
+		result, current := reconcile(lv)
+		Expect(result.RequeueAfter).To(Equal(time.Minute))
+		cond := meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionWarmedUp)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Reason).To(Equal(topolvmv1.WarmUpReasonInvalidTemplate))
+		_, err := getJob(lv)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not warm up the volumes provisioned without the parameter", func() {
+		lv, _ := createVolume("warm-up-none", "")
+
+		_, current := reconcile(lv)
+		Expect(meta.FindStatusCondition(current.Status.Conditions, topolvmv1.ConditionWarmedUp)).To(BeNil())
+		_, err := getJob(lv)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+})
@@ -1,10 +1,13 @@
 package driver
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/spf13/pflag"
+	"github.com/topolvm/topolvm"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -53,6 +56,19 @@ func (q *Quantity) Type() string {
 	return reflect.TypeOf(rq).String()
 }
 
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return resource.Quantity(q).MarshalJSON()
+}
+
+func (q *Quantity) UnmarshalJSON(value []byte) error {
+	var rq resource.Quantity
+	if err := rq.UnmarshalJSON(value); err != nil {
+		return err
+	}
+	*q = Quantity(rq)
+	return nil
+}
+
 // MinMaxAllocationsFromSettings returns the minimum and maximum allocations based on the settings.
 // It uses the required and limit bytes from the CSI Call and the device class and capabilities from the StorageClass.
 // It then returns the minimum and maximum allocations in bytes that can be used for that context.
@@ -105,3 +121,215 @@ func (settings MinimumAllocationSettings) GetMinimumAllocationSize(
 
 	return quantity
 }
+
+// MaximumAllocationPolicy is how the controller handles the volumes requested larger than the maximum allocation.
+type MaximumAllocationPolicy string
+
+const (
+	// MaximumAllocationReject rejects the volumes requested larger than the maximum with OutOfRange.
+	MaximumAllocationReject MaximumAllocationPolicy = "reject"
+	// MaximumAllocationCap allocates the maximum for the volumes requested larger than it.
+	MaximumAllocationCap MaximumAllocationPolicy = "cap"
+)
+
+// ErrExceedsMaximumAllocation is returned when the requested size exceeds the maximum allocation.
+var ErrExceedsMaximumAllocation = errors.New("requested capacity exceeds the maximum allocation")
+
+// MaximumAllocation contains the maximum sizes of the volumes.
+type MaximumAllocation struct {
+	// Default is the maximum of the volumes without the maximum of their filesystem or block.
+	Default *Quantity `json:"default,omitempty"`
+	// Filesystem is the maximum of the filesystem volumes by the filesystem type.
+	Filesystem map[string]Quantity `json:"filesystem,omitempty"`
+	// Block is the maximum of the block volumes.
+	Block *Quantity `json:"block,omitempty"`
+}
+
+// MaximumAllocationSettings contains the maximum allocation settings for the controller.
+// It mirrors MinimumAllocationSettings, and the settings of a device class take precedence over the defaults.
+type MaximumAllocationSettings struct {
+	// Policy is how the requests exceeding the maximum are handled. The default is reject.
+	Policy            MaximumAllocationPolicy `json:"policy,omitempty"`
+	MaximumAllocation `json:",inline"`
+	// DeviceClasses contains the maximums by the name of the device class.
+	DeviceClasses map[string]MaximumAllocation `json:"device-classes,omitempty"`
+}
+
+// Validate validates the settings.
+func (settings MaximumAllocationSettings) Validate() error {
+	switch settings.Policy {
+	case "", MaximumAllocationReject, MaximumAllocationCap:
+	default:
+		return fmt.Errorf("invalid maximum allocation policy %q, must be %s or %s",
+			settings.Policy, MaximumAllocationReject, MaximumAllocationCap)
+	}
+	if err := settings.MaximumAllocation.validate(); err != nil {
+		return err
+	}
+	if err := validateDefaultDeviceClass(settings.DeviceClasses); err != nil {
+		return err
+	}
+	for name, allocation := range settings.DeviceClasses {
+		if err := allocation.validate(); err != nil {
+			return fmt.Errorf("device class %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// deviceClassSetting returns the setting of the device class. Like lvmd, the empty name refers to the default
+// device class, whose setting is keyed by the empty name or topolvm.DefaultDeviceClassAnnotationName
+// as in the annotations of the nodes.
+func deviceClassSetting[T any](settings map[string]T, deviceClass string) (T, bool) {
+	if deviceClass == topolvm.DefaultDeviceClassName {
+		if setting, ok := settings[topolvm.DefaultDeviceClassAnnotationName]; ok {
+			return setting, true
+		}
+	}
+	setting, ok := settings[deviceClass]
+	return setting, ok
+}
+
+// validateDefaultDeviceClass rejects the settings of the default device class given under both of its keys.
+func validateDefaultDeviceClass[T any](settings map[string]T) error {
+	_, empty := settings[topolvm.DefaultDeviceClassName]
+	_, annotation := settings[topolvm.DefaultDeviceClassAnnotationName]
+	if empty && annotation {
+		return fmt.Errorf("the default device class is given as both %q and %q",
+			topolvm.DefaultDeviceClassName, topolvm.DefaultDeviceClassAnnotationName)
+	}
+	return nil
+}
+
+func (allocation MaximumAllocation) validate() error {
+	check := func(name string, q Quantity) error {
+		if rq := resource.Quantity(q); rq.Sign() <= 0 {
+			return fmt.Errorf("maximum allocation of %s must be positive: %s", name, rq.String())
+		}
+		return nil
+	}
+	if allocation.Default != nil {
+		if err := check("default", *allocation.Default); err != nil {
+			return err
+		}
+	}
+	if allocation.Block != nil {
+		if err := check("block", *allocation.Block); err != nil {
+			return err
+		}
+	}
+	for fsType, q := range allocation.Filesystem {
+		if err := check(fsType, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetMaximumAllocationSize returns the maximum size to be allocated for the volumes of the device class
+// with the capabilities, and false if there is no maximum.
+// The maximum of the block or the filesystem type is used before the default one, first of the device class.
+// The empty name refers to the default device class.
+func (settings MaximumAllocationSettings) GetMaximumAllocationSize(
+	deviceClass string,
+	capabilities []*csi.VolumeCapability,
+) (resource.Quantity, bool) {
+	if allocation, ok := deviceClassSetting(settings.DeviceClasses, deviceClass); ok {
+		if quantity, ok := allocation.get(capabilities); ok {
+			return quantity, true
+		}
+	}
+	return settings.MaximumAllocation.get(capabilities)
+}
+
+func (allocation MaximumAllocation) get(capabilities []*csi.VolumeCapability) (resource.Quantity, bool) {
+	for _, capability := range capabilities {
+		if capability.GetBlock() != nil && allocation.Block != nil {
+			return resource.Quantity(*allocation.Block), true
+		}
+		if capability.GetMount() != nil {
+			if quantity, ok := allocation.Filesystem[capability.GetMount().FsType]; ok {
+				return resource.Quantity(quantity), true
+			}
+		}
+	}
+	if allocation.Default != nil {
+		return resource.Quantity(*allocation.Default), true
+	}
+	return resource.Quantity{}, false
+}
+
+// MaxAllocationsFromSettings applies the maximum allocation of the device class and the capabilities
+// to the required and limit bytes of a CSI call. The limit is lowered to the maximum.
+// A required size above the maximum is lowered to it with MaximumAllocationCap,
+// and returns ErrExceedsMaximumAllocation otherwise.
+func (settings MaximumAllocationSettings) MaxAllocationsFromSettings(
+	deviceClass string,
+	required, limit int64,
+	capabilities []*csi.VolumeCapability,
+) (int64, int64, error) {
+	quantity, ok := settings.GetMaximumAllocationSize(deviceClass, capabilities)
+	if !ok {
+		return required, limit, nil
+	}
+	maximum := quantity.Value()
+
+	if required > maximum {
+		if settings.Policy != MaximumAllocationCap {
+			return 0, 0, fmt.Errorf("%w: required=%d maximum=%s", ErrExceedsMaximumAllocation, required, quantity.String())
+		}
+		ctrlLogger.Info("required size is greater than maximum size, "+
+			"using maximum size as required size", "required", required, "maximum", maximum)
+		required = maximum
+	}
+	if limit == 0 || limit > maximum {
+		limit = maximum
+	}
+	return required, limit, nil
+}
+
+// AllocationUnitSettings contains the units the requested sizes of the volumes are rounded up to,
+// so that odd-sized requests do not fragment the thin pools or resize the volumes by a few bytes.
+type AllocationUnitSettings struct {
+	// Default is the unit of the device classes without their own unit.
+	Default *Quantity `json:"default,omitempty"`
+	// DeviceClasses contains the units by the name of the device class.
+	DeviceClasses map[string]Quantity `json:"device-classes,omitempty"`
+}
+
+// Validate validates the settings.
+func (settings AllocationUnitSettings) Validate() error {
+	check := func(name string, q Quantity) error {
+		rq := resource.Quantity(q)
+		if rq.Sign() <= 0 || rq.Value()%topolvm.MinimumSectorSize != 0 {
+			return fmt.Errorf("allocation unit of %s must be a positive multiple of %d: %s", name, topolvm.MinimumSectorSize, rq.String())
+		}
+		return nil
+	}
+	if settings.Default != nil {
+		if err := check("default", *settings.Default); err != nil {
+			return err
+		}
+	}
+	if err := validateDefaultDeviceClass(settings.DeviceClasses); err != nil {
+		return err
+	}
+	for name, q := range settings.DeviceClasses {
+		if err := check("device class "+name, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAllocationUnit returns the allocation unit of the device class in bytes, or 0 if there is none.
+// The empty name refers to the default device class.
+func (settings AllocationUnitSettings) GetAllocationUnit(deviceClass string) int64 {
+	var unit resource.Quantity
+	if q, ok := deviceClassSetting(settings.DeviceClasses, deviceClass); ok {
+		unit = resource.Quantity(q)
+	} else if settings.Default != nil {
+		unit = resource.Quantity(*settings.Default)
+	}
+	return unit.Value()
+}
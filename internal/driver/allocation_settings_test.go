@@ -1,6 +1,7 @@
 package driver
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
@@ -178,3 +179,165 @@ func TestQuantity_UnmarshalText(t *testing.T) {
 		})
 	}
 }
+
+func Test_MaximumAllocationSettings(t *testing.T) {
+	mockBlock := &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Block{
+		Block: &csi.VolumeCapability_BlockVolume{},
+	}}
+	mockMount := &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Mount{
+		Mount: &csi.VolumeCapability_MountVolume{FsType: "xfs"},
+	}}
+	quantity := func(s string) *Quantity {
+		q := Quantity(resource.MustParse(s))
+		return &q
+	}
+	settings := MaximumAllocationSettings{
+		MaximumAllocation: MaximumAllocation{
+			Default:    quantity("10Gi"),
+			Filesystem: map[string]Quantity{"xfs": *quantity("20Gi")},
+		},
+		DeviceClasses: map[string]MaximumAllocation{
+			"ssd":       {Block: quantity("5Gi")},
+			"00default": {Block: quantity("3Gi")},
+		},
+	}
+
+	type testBytes struct {
+		required, limit int64
+	}
+	testCases := []struct {
+		name         string
+		policy       MaximumAllocationPolicy
+		deviceClass  string
+		capabilities []*csi.VolumeCapability
+		input        testBytes
+		expected     testBytes
+		err          bool
+	}{
+		{
+			name:     "default maximum lowers the limit",
+			input:    testBytes{required: 1 << 30},
+			expected: testBytes{required: 1 << 30, limit: 10 << 30},
+		},
+		{
+			name:         "filesystem maximum takes precedence over the default",
+			capabilities: []*csi.VolumeCapability{mockMount},
+			input:        testBytes{required: 15 << 30, limit: 30 << 30},
+			expected:     testBytes{required: 15 << 30, limit: 20 << 30},
+		},
+		{
+			name:         "device class maximum takes precedence",
+			deviceClass:  "ssd",
+			capabilities: []*csi.VolumeCapability{mockBlock},
+			input:        testBytes{required: 1 << 30, limit: 2 << 30},
+			expected:     testBytes{required: 1 << 30, limit: 2 << 30},
+		},
+		{
+			name:         "default device class is keyed by the annotation name",
+			capabilities: []*csi.VolumeCapability{mockBlock},
+			input:        testBytes{required: 1 << 30},
+			expected:     testBytes{required: 1 << 30, limit: 3 << 30},
+		},
+		{
+			name:         "device class without the maximum of the capability falls back to the defaults",
+			deviceClass:  "ssd",
+			capabilities: []*csi.VolumeCapability{mockMount},
+			input:        testBytes{required: 15 << 30},
+			expected:     testBytes{required: 15 << 30, limit: 20 << 30},
+		},
+		{
+			name:         "requests exceeding the maximum are rejected by default",
+			deviceClass:  "ssd",
+			capabilities: []*csi.VolumeCapability{mockBlock},
+			input:        testBytes{required: 6 << 30},
+			err:          true,
+		},
+		{
+			name:         "requests exceeding the maximum are capped",
+			policy:       MaximumAllocationCap,
+			deviceClass:  "ssd",
+			capabilities: []*csi.VolumeCapability{mockBlock},
+			input:        testBytes{required: 6 << 30, limit: 8 << 30},
+			expected:     testBytes{required: 5 << 30, limit: 5 << 30},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := settings
+			settings.Policy = tc.policy
+			required, limit, err := settings.MaxAllocationsFromSettings(tc.deviceClass, tc.input.required, tc.input.limit, tc.capabilities)
+			if tc.err {
+				if !errors.Is(err, ErrExceedsMaximumAllocation) {
+					t.Errorf("expected ErrExceedsMaximumAllocation, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if required != tc.expected.required {
+				t.Errorf("expected required bytes to be %d, but got %d", tc.expected.required, required)
+			}
+			if limit != tc.expected.limit {
+				t.Errorf("expected limit bytes to be %d, but got %d", tc.expected.limit, limit)
+			}
+		})
+	}
+
+	if _, _, err := (MaximumAllocationSettings{}).MaxAllocationsFromSettings("", 1<<40, 0, nil); err != nil {
+		t.Errorf("expected no maximum without settings: %v", err)
+	}
+	if err := (MaximumAllocationSettings{Policy: "truncate"}).Validate(); err == nil {
+		t.Error("expected an invalid policy to be rejected")
+	}
+	invalid := MaximumAllocationSettings{DeviceClasses: map[string]MaximumAllocation{"ssd": {Block: quantity("0")}}}
+	if err := invalid.Validate(); err == nil {
+		t.Error("expected a zero maximum to be rejected")
+	}
+	if err := settings.Validate(); err != nil {
+		t.Errorf("expected the settings to be valid: %v", err)
+	}
+	conflict := MaximumAllocationSettings{DeviceClasses: map[string]MaximumAllocation{
+		"":          {Block: quantity("1Gi")},
+		"00default": {Block: quantity("2Gi")},
+	}}
+	if err := conflict.Validate(); err == nil {
+		t.Error("expected the default device class given twice to be rejected")
+	}
+}
+
+func Test_AllocationUnitSettings(t *testing.T) {
+	gi := Quantity(resource.MustParse("1Gi"))
+	settings := AllocationUnitSettings{
+		Default:       &gi,
+		DeviceClasses: map[string]Quantity{"ssd": Quantity(resource.MustParse("256Mi"))},
+	}
+	if unit := settings.GetAllocationUnit("hdd"); unit != 1<<30 {
+		t.Errorf("expected the default unit, but got %d", unit)
+	}
+	if unit := settings.GetAllocationUnit("ssd"); unit != 256<<20 {
+		t.Errorf("expected the unit of the device class, but got %d", unit)
+	}
+	if unit := settings.GetAllocationUnit(""); unit != 1<<30 {
+		t.Errorf("expected the default unit for the default device class, but got %d", unit)
+	}
+	for _, key := range []string{"", "00default"} {
+		settings := AllocationUnitSettings{DeviceClasses: map[string]Quantity{key: Quantity(resource.MustParse("512Mi"))}}
+		if unit := settings.GetAllocationUnit(""); unit != 512<<20 {
+			t.Errorf("expected the unit of the default device class keyed by %q, but got %d", key, unit)
+		}
+	}
+	if unit := (AllocationUnitSettings{}).GetAllocationUnit("ssd"); unit != 0 {
+		t.Errorf("expected no unit without settings, but got %d", unit)
+	}
+
+	if err := settings.Validate(); err != nil {
+		t.Errorf("expected the settings to be valid: %v", err)
+	}
+	for _, invalid := range []string{"0", "-1Gi", "1000"} {
+		settings := AllocationUnitSettings{DeviceClasses: map[string]Quantity{"ssd": Quantity(resource.MustParse(invalid))}}
+		if err := settings.Validate(); err == nil {
+			t.Errorf("expected the unit %s to be rejected", invalid)
+		}
+	}
+}
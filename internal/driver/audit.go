@@ -0,0 +1,252 @@
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	protov1 "github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// IdempotencyAuditPath is the path of the HTTP endpoint serving the records of IdempotencyAuditor.
+const IdempotencyAuditPath = "/debug/csi/idempotency"
+
+var auditLogger = ctrl.Log.WithName("driver").WithName("audit")
+
+// auditedMethods are the CSI methods changing the state of volumes, which must be idempotent.
+// The functions return the result to be identical when a call is replayed.
+var auditedMethods = map[string]func(resp any) string{
+	"/csi.v1.Controller/CreateVolume": func(resp any) string {
+		return resp.(*csi.CreateVolumeResponse).GetVolume().GetVolumeId()
+	},
+	"/csi.v1.Controller/DeleteVolume": nil,
+	"/csi.v1.Controller/ControllerExpandVolume": func(resp any) string {
+		return strconv.FormatInt(resp.(*csi.ControllerExpandVolumeResponse).GetCapacityBytes(), 10)
+	},
+	"/csi.v1.Controller/CreateSnapshot": func(resp any) string {
+		return resp.(*csi.CreateSnapshotResponse).GetSnapshot().GetSnapshotId()
+	},
+	"/csi.v1.Controller/DeleteSnapshot": nil,
+	"/csi.v1.Node/NodeStageVolume":      nil,
+	"/csi.v1.Node/NodeUnstageVolume":    nil,
+	"/csi.v1.Node/NodePublishVolume":    nil,
+	"/csi.v1.Node/NodeUnpublishVolume":  nil,
+	"/csi.v1.Node/NodeExpandVolume":     nil,
+}
+
+// AuditRecord is a CSI call recorded by IdempotencyAuditor.
+type AuditRecord struct {
+	Time     time.Time       `json:"time"`
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Code     string          `json:"code"`
+	Message  string          `json:"message,omitempty"`
+	Result   string          `json:"result,omitempty"`
+	Duration string          `json:"duration"`
+	// Replay is set if the same request has been called before.
+	Replay bool `json:"replay,omitempty"`
+	// Violation describes how the outcome of a replay differs from the previous one.
+	Violation string `json:"violation,omitempty"`
+
+	key string
+	seq uint64
+	// deleted is the ID of the volume or snapshot deleted by the call.
+	deleted string
+}
+
+type auditOutcome struct {
+	code   codes.Code
+	result string
+	seq    uint64
+}
+
+// IdempotencyAuditor records the CSI calls and their outcomes in a ring buffer, and flags replays of a call
+// whose outcome is inconsistent with the previous one, e.g. a replayed CreateVolume creating another volume
+// or a replayed DeleteVolume failing. It is meant for testing the driver against retries of kubelet and the sidecars.
+type IdempotencyAuditor struct {
+	mu         sync.Mutex
+	records    []AuditRecord
+	seq        uint64
+	outcomes   map[string]auditOutcome
+	violations uint64
+}
+
+// NewIdempotencyAuditor returns an IdempotencyAuditor keeping the last size calls.
+func NewIdempotencyAuditor(size int) *IdempotencyAuditor {
+	return &IdempotencyAuditor{
+		records:  make([]AuditRecord, size),
+		outcomes: make(map[string]auditOutcome),
+	}
+}
+
+// Intercept is a grpc.UnaryServerInterceptor recording the CSI calls.
+func (a *IdempotencyAuditor) Intercept(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resultOf, ok := auditedMethods[info.FullMethod]
+	msgV1, isMsg := req.(protov1.Message)
+	if !ok || !isMsg {
+		return handler(ctx, req)
+	}
+	// the CSI messages are generated with the API v1 of protobuf.
+	msg := protov1.MessageV2(msgV1)
+
+	start := time.Now()
+	resp, err := handler(ctx, req)
+
+	record := AuditRecord{
+		Time:     start,
+		Method:   info.FullMethod,
+		Code:     status.Code(err).String(),
+		Duration: time.Since(start).String(),
+	}
+	stripped := stripSecrets(msg)
+	if data, marshalErr := protojson.Marshal(stripped); marshalErr == nil {
+		record.Request = data
+	}
+	if data, marshalErr := (proto.MarshalOptions{Deterministic: true}).Marshal(stripped); marshalErr == nil {
+		sum := sha256.Sum256(data)
+		record.key = info.FullMethod + "/" + hex.EncodeToString(sum[:])
+	}
+	if err != nil {
+		record.Message = status.Convert(err).Message()
+	} else if resultOf != nil {
+		record.Result = resultOf(resp)
+	}
+	switch req := req.(type) {
+	case *csi.DeleteVolumeRequest:
+		record.deleted = req.GetVolumeId()
+	case *csi.DeleteSnapshotRequest:
+		record.deleted = req.GetSnapshotId()
+	}
+
+	a.record(&record, status.Code(err))
+	if record.Violation != "" {
+		auditLogger.Error(nil, "non-idempotent replay of CSI call", "method", record.Method,
+			"request", string(record.Request), "violation", record.Violation)
+	}
+	return resp, err
+}
+
+// record compares the outcome of the call with the previous one of the same request and adds it to the ring buffer.
+func (a *IdempotencyAuditor) record(r *AuditRecord, code codes.Code) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.records) == 0 {
+		return
+	}
+	a.seq++
+	r.seq = a.seq
+
+	if prev, ok := a.outcomes[r.key]; ok && r.key != "" {
+		r.Replay = true
+		switch {
+		case prev.code != codes.OK || isTransient(code):
+		case code != codes.OK:
+			r.Violation = fmt.Sprintf("replay failed with %s, but the previous call succeeded", code)
+		case r.Result != prev.result:
+			r.Violation = fmt.Sprintf("replay returned %q, but the previous call returned %q", r.Result, prev.result)
+		}
+	}
+	if r.Violation != "" {
+		a.violations++
+	}
+	if r.key != "" && !isTransient(code) {
+		a.outcomes[r.key] = auditOutcome{code: code, result: r.Result, seq: r.seq}
+	}
+	if code == codes.OK {
+		a.forgetDeleted(r)
+	}
+
+	slot := &a.records[int(r.seq%uint64(len(a.records)))]
+	if old, ok := a.outcomes[slot.key]; ok && old.seq == slot.seq {
+		delete(a.outcomes, slot.key)
+	}
+	*slot = *r
+}
+
+// forgetDeleted drops the outcomes of the calls creating the volume or snapshot deleted by r,
+// so that creating another one with the same name is not taken as a replay.
+func (a *IdempotencyAuditor) forgetDeleted(r *AuditRecord) {
+	if r.deleted == "" {
+		return
+	}
+	creator := strings.Replace(r.Method, "/Delete", "/Create", 1)
+	for i := range a.records {
+		old := &a.records[i]
+		if old.Method == creator && old.Result == r.deleted {
+			delete(a.outcomes, old.key)
+		}
+	}
+}
+
+// isTransient returns true for codes telling the caller to retry later, which are allowed on replays.
+func isTransient(code codes.Code) bool {
+	switch code {
+	case codes.Aborted, codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// stripSecrets returns a copy of the request without the secrets passed to the CSI calls.
+func stripSecrets(msg proto.Message) proto.Message {
+	msg = proto.Clone(msg)
+	m := msg.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("secrets"); fd != nil {
+		m.Clear(fd)
+	}
+	return msg
+}
+
+// Records returns the recorded calls from the oldest one.
+func (a *IdempotencyAuditor) Records() []AuditRecord {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	records := make([]AuditRecord, 0, len(a.records))
+	for i := 1; i <= len(a.records); i++ {
+		r := a.records[int((a.seq+uint64(i))%uint64(len(a.records)))]
+		if r.seq != 0 {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// ServeHTTP serves the recorded calls and the number of violations in JSON.
+// Only the calls violating idempotency are returned with the "violations" query parameter.
+func (a *IdempotencyAuditor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	records := a.Records()
+	if r.URL.Query().Has("violations") {
+		filtered := records[:0]
+		for _, record := range records {
+			if record.Violation != "" {
+				filtered = append(filtered, record)
+			}
+		}
+		records = filtered
+	}
+	a.mu.Lock()
+	violations := a.violations
+	a.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Violations uint64        `json:"violations"`
+		Records    []AuditRecord `json:"records"`
+	}{violations, records})
+}
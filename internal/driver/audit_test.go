@@ -0,0 +1,97 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIdempotencyAuditor(t *testing.T) {
+	a := NewIdempotencyAuditor(16)
+	ctx := context.Background()
+	call := func(method string, req any, resp any, err error) {
+		t.Helper()
+		_, _ = a.Intercept(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return resp, err
+		})
+	}
+	createVolume := "/csi.v1.Controller/CreateVolume"
+	deleteVolume := "/csi.v1.Controller/DeleteVolume"
+	created := func(id string) *csi.CreateVolumeResponse {
+		return &csi.CreateVolumeResponse{Volume: &csi.Volume{VolumeId: id}}
+	}
+
+	createReq := &csi.CreateVolumeRequest{Name: "pvc-1", Secrets: map[string]string{"passphrase": "secret"}}
+	call(createVolume, createReq, created("vol-1"), nil)
+	// a transient error and a consistent replay are fine.
+	call(createVolume, createReq, nil, status.Error(codes.Aborted, "in progress"))
+	call(createVolume, createReq, created("vol-1"), nil)
+	// a replay creating another volume is not.
+	call(createVolume, createReq, created("vol-2"), nil)
+
+	deleteReq := &csi.DeleteVolumeRequest{VolumeId: "vol-1"}
+	call(deleteVolume, deleteReq, &csi.DeleteVolumeResponse{}, nil)
+	call(deleteVolume, deleteReq, nil, status.Error(codes.Internal, "not found"))
+	// creating a volume of the same name after the deletion is not a replay.
+	call(createVolume, createReq, created("vol-3"), nil)
+	// methods not changing volumes are not recorded.
+	call("/csi.v1.Controller/GetCapacity", &csi.GetCapacityRequest{}, &csi.GetCapacityResponse{}, nil)
+
+	records := a.Records()
+	if len(records) != 7 {
+		t.Fatalf("unexpected number of records: %d", len(records))
+	}
+	var violations []int
+	for i, r := range records {
+		if r.Violation != "" {
+			violations = append(violations, i)
+		}
+		if strings.Contains(string(r.Request), "secret") {
+			t.Errorf("secrets should be stripped: %s", r.Request)
+		}
+	}
+	if len(violations) != 2 || violations[0] != 3 || violations[1] != 5 {
+		t.Errorf("unexpected violations: %v, records=%+v", violations, records)
+	}
+	if records[6].Replay {
+		t.Errorf("creating a deleted volume again should not be a replay: %+v", records[6])
+	}
+
+	w := httptest.NewRecorder()
+	a.ServeHTTP(w, httptest.NewRequest("GET", IdempotencyAuditPath+"?violations", nil))
+	var body struct {
+		Violations uint64        `json:"violations"`
+		Records    []AuditRecord `json:"records"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Violations != 2 || len(body.Records) != 2 {
+		t.Errorf("unexpected response: %+v", body)
+	}
+}
+
+func TestIdempotencyAuditorRingBuffer(t *testing.T) {
+	a := NewIdempotencyAuditor(2)
+	for _, name := range []string{"pvc-1", "pvc-2", "pvc-3"} {
+		_, _ = a.Intercept(context.Background(), &csi.CreateVolumeRequest{Name: name},
+			&grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"},
+			func(context.Context, any) (any, error) {
+				return &csi.CreateVolumeResponse{Volume: &csi.Volume{VolumeId: name}}, nil
+			})
+	}
+	records := a.Records()
+	if len(records) != 2 || records[0].Result != "pvc-2" || records[1].Result != "pvc-3" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+	if len(a.outcomes) != 2 {
+		t.Errorf("outcomes of overwritten records should be dropped: %d", len(a.outcomes))
+	}
+}
@@ -0,0 +1,124 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topolvm/internal/driver/internal/k8s"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CapacityAPIPath is the path prefix of the HTTP API served by the handler of NewCapacityAPI.
+const CapacityAPIPath = "/capacity/v1/"
+
+// NodeCapacity is the capacity of a device class on a node returned by the capacity API.
+type NodeCapacity struct {
+	Node          string `json:"node"`
+	DeviceClass   string `json:"deviceClass"`
+	CapacityBytes int64  `json:"capacityBytes"`
+}
+
+// NodeCapacityList is the list of nodes returned by the capacity API.
+type NodeCapacityList struct {
+	DeviceClass   string         `json:"deviceClass"`
+	RequiredBytes int64          `json:"requiredBytes"`
+	Items         []NodeCapacity `json:"items"`
+}
+
+type capacityGetter interface {
+	GetCapacityByName(ctx context.Context, name, deviceClass string) (int64, error)
+	GetCapacities(ctx context.Context, deviceClass string) (map[string]int64, error)
+}
+
+type capacityAPI struct {
+	nodes capacityGetter
+}
+
+// NewCapacityAPI returns a read-only HTTP API for external schedulers and cluster autoscalers
+// answering the capacity of device classes from the annotations of the nodes:
+//
+//   - GET /capacity/v1/nodes/NODE?device-class=DC returns the capacity of a node as NodeCapacity.
+//   - GET /capacity/v1/nodes?device-class=DC&required-bytes=N returns the nodes which can fit N bytes
+//     as NodeCapacityList, the largest capacity first.
+//
+// The default device class is used if device-class is not given.
+func NewCapacityAPI(r client.Reader) http.Handler {
+	return capacityAPI{nodes: k8s.NewNodeService(r)}
+}
+
+func (a capacityAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, CapacityAPIPath)
+	switch {
+	case path == "nodes":
+		a.listNodes(w, r)
+	case strings.HasPrefix(path, "nodes/") && len(path) > len("nodes/"):
+		a.getNode(w, r, strings.TrimPrefix(path, "nodes/"))
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func (a capacityAPI) getNode(w http.ResponseWriter, r *http.Request, node string) {
+	deviceClass := r.URL.Query().Get("device-class")
+	capacity, err := a.nodes.GetCapacityByName(r.Context(), node, deviceClass)
+	switch {
+	case apierrors.IsNotFound(err):
+		http.Error(w, "node not found", http.StatusNotFound)
+		return
+	case errors.Is(err, k8s.ErrDeviceClassNotFound):
+		http.Error(w, "device class not found on the node", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, NodeCapacity{Node: node, DeviceClass: deviceClass, CapacityBytes: capacity})
+}
+
+func (a capacityAPI) listNodes(w http.ResponseWriter, r *http.Request) {
+	deviceClass := r.URL.Query().Get("device-class")
+	var required int64
+	if v := r.URL.Query().Get("required-bytes"); v != "" {
+		var err error
+		required, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || required < 0 {
+			http.Error(w, "invalid required-bytes", http.StatusBadRequest)
+			return
+		}
+	}
+	capacities, err := a.nodes.GetCapacities(r.Context(), deviceClass)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	list := NodeCapacityList{DeviceClass: deviceClass, RequiredBytes: required, Items: []NodeCapacity{}}
+	for node, capacity := range capacities {
+		if capacity < required {
+			continue
+		}
+		list.Items = append(list.Items, NodeCapacity{Node: node, DeviceClass: deviceClass, CapacityBytes: capacity})
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].CapacityBytes != list.Items[j].CapacityBytes {
+			return list.Items[i].CapacityBytes > list.Items[j].CapacityBytes
+		}
+		return list.Items[i].Node < list.Items[j].Node
+	})
+	writeJSON(w, list)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
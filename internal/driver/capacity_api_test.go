@@ -0,0 +1,89 @@
+package driver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm/internal/driver/internal/k8s"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+type stubCapacityGetter map[string]map[string]int64
+
+func (s stubCapacityGetter) GetCapacityByName(_ context.Context, name, deviceClass string) (int64, error) {
+	classes, ok := s[name]
+	if !ok {
+		return 0, apierrors.NewNotFound(corev1.Resource("nodes"), name)
+	}
+	c, ok := classes[deviceClass]
+	if !ok {
+		return 0, k8s.ErrDeviceClassNotFound
+	}
+	return c, nil
+}
+
+func (s stubCapacityGetter) GetCapacities(_ context.Context, deviceClass string) (map[string]int64, error) {
+	capacities := make(map[string]int64)
+	for node, classes := range s {
+		if c, ok := classes[deviceClass]; ok {
+			capacities[node] = c
+		}
+	}
+	return capacities, nil
+}
+
+func TestCapacityAPI(t *testing.T) {
+	api := capacityAPI{nodes: stubCapacityGetter{
+		"node1": {"ssd": 10 << 30, "hdd": 100 << 30},
+		"node2": {"ssd": 20 << 30},
+		"node3": {"ssd": 5 << 30},
+	}}
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	w := get(CapacityAPIPath + "nodes/node1?device-class=hdd")
+	var node NodeCapacity
+	if err := json.NewDecoder(w.Body).Decode(&node); err != nil {
+		t.Fatal(err)
+	}
+	if expected := (NodeCapacity{Node: "node1", DeviceClass: "hdd", CapacityBytes: 100 << 30}); node != expected {
+		t.Errorf("unexpected node capacity: %+v", node)
+	}
+
+	w = get(CapacityAPIPath + "nodes?device-class=ssd&required-bytes=10737418240")
+	var list NodeCapacityList
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	expected := []NodeCapacity{
+		{Node: "node2", DeviceClass: "ssd", CapacityBytes: 20 << 30},
+		{Node: "node1", DeviceClass: "ssd", CapacityBytes: 10 << 30},
+	}
+	if !reflect.DeepEqual(list.Items, expected) {
+		t.Errorf("unexpected nodes: %+v", list.Items)
+	}
+
+	for url, code := range map[string]int{
+		CapacityAPIPath + "nodes/node4":                  http.StatusNotFound,
+		CapacityAPIPath + "nodes/node2?device-class=hdd": http.StatusNotFound,
+		CapacityAPIPath + "nodes?required-bytes=-1":      http.StatusBadRequest,
+		CapacityAPIPath + "volumes":                      http.StatusNotFound,
+	} {
+		if w := get(url); w.Code != code {
+			t.Errorf("%s: expected %d, got %d", url, code, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodPost, CapacityAPIPath+"nodes", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST should not be allowed: %d", w.Code)
+	}
+}
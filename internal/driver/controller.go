@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,14 +13,24 @@ import (
 	"github.com/topolvm/topolvm"
 	v1 "github.com/topolvm/topolvm/api/v1"
 	"github.com/topolvm/topolvm/internal/driver/internal/k8s"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 var ctrlLogger = ctrl.Log.WithName("driver").WithName("controller")
 
+// The values of the colocation parameter.
+const (
+	colocationPreferred = "preferred"
+	colocationRequired  = "required"
+)
+
 var (
 	ErrNoNegativeRequestBytes = errors.New("required capacity must not be negative")
 	ErrNoNegativeLimitBytes   = errors.New("capacity limit must not be negative")
@@ -30,6 +41,11 @@ var (
 // ControllerServerSettings hold all settings that should be passed to the controller server.
 type ControllerServerSettings struct {
 	MinimumAllocationSettings `json:"allocation" ,yaml:"allocation"`
+	MaximumAllocationSettings MaximumAllocationSettings `json:"maximum-allocation"`
+	AllocationUnitSettings    AllocationUnitSettings    `json:"allocation-unit"`
+	DeletionPacingSettings    DeletionPacingSettings
+	// VolumeWarmUp allows the warm-up parameter, whose Jobs are run by the warm-up controller of topolvm-controller.
+	VolumeWarmUp bool
 }
 
 // NewControllerServer returns a new ControllerServer.
@@ -43,13 +59,21 @@ func NewControllerServer(mgr manager.Manager, settings ControllerServerSettings)
 		lockByName:     NewLockWithID(),
 		lockByVolumeID: NewLockWithID(),
 		server: &controllerServerNoLocked{
-			lvService:   lvService,
-			nodeService: k8s.NewNodeService(mgr.GetClient()),
-			settings:    settings,
+			lvService:         lvService,
+			nodeService:       k8s.NewNodeService(mgr.GetClient()),
+			colocationService: k8s.NewColocationService(mgr.GetClient()),
+			pvcService:        k8s.NewPersistentVolumeClaimService(mgr.GetClient()),
+			deletionPacer:     newDeletionPacer(settings.DeletionPacingSettings),
+			settings:          settings,
 		},
 	}, nil
 }
 
+// WarmUpper is implemented by the servers which should prime their caches before accepting requests.
+type WarmUpper interface {
+	WarmUp(ctx context.Context) error
+}
+
 // This is a wrapper for controllerServerNoLocked to protect concurrent method call.
 type controllerServer struct {
 	csi.UnimplementedControllerServer
@@ -61,6 +85,11 @@ type controllerServer struct {
 	server         *controllerServerNoLocked
 }
 
+// WarmUp primes the cache of LogicalVolumes used to look up volumes by their ID.
+func (s *controllerServer) WarmUp(ctx context.Context) error {
+	return s.server.lvService.WarmUp(ctx)
+}
+
 func (s *controllerServer) CreateVolume(ctx context.Context, req *csi.CreateVolumeRequest) (*csi.CreateVolumeResponse, error) {
 	s.lockByName.LockByID(req.GetName())
 	defer s.lockByName.UnlockByID(req.GetName())
@@ -88,6 +117,22 @@ func (s *controllerServer) GetCapacity(ctx context.Context, req *csi.GetCapacity
 	return s.server.GetCapacity(ctx, req)
 }
 
+func (s *controllerServer) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	// This reads the cache of LogicalVolumes only, and the pages are consistent as long as the volumes exist.
+	// Therefore, it is unnecessary to take lock.
+	return s.server.ListVolumes(ctx, req)
+}
+
+func (s *controllerServer) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	// This reads the cache of LogicalVolumes only, as ListVolumes does, it is unnecessary to take lock.
+	return s.server.ListSnapshots(ctx, req)
+}
+
+func (s *controllerServer) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	// This reads the cache of LogicalVolumes only, it is unnecessary to take lock.
+	return s.server.ControllerGetVolume(ctx, req)
+}
+
 func (s *controllerServer) ControllerGetCapabilities(ctx context.Context, req *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
 	// This returns constants only, it is unnecessary to take lock.
 	return s.server.ControllerGetCapabilities(ctx, req)
@@ -120,8 +165,11 @@ func (s *controllerServer) ControllerExpandVolume(ctx context.Context, req *csi.
 type controllerServerNoLocked struct {
 	csi.UnimplementedControllerServer
 
-	lvService   *k8s.LogicalVolumeService
-	nodeService *k8s.NodeService
+	lvService         *k8s.LogicalVolumeService
+	nodeService       *k8s.NodeService
+	colocationService *k8s.ColocationService
+	pvcService        *k8s.PersistentVolumeClaimService
+	deletionPacer     *deletionPacer
 
 	settings ControllerServerSettings
 }
@@ -131,6 +179,10 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 	source := req.GetVolumeContentSource()
 	deviceClass := req.GetParameters()[topolvm.GetDeviceClassKey()]
 	lvcreateOptionClass := req.GetParameters()[topolvm.GetLvcreateOptionClassKey()]
+	lvcreateOptions := strings.Fields(req.GetParameters()[topolvm.GetLvcreateOptionsKey()])
+	provisioningType := req.GetParameters()[topolvm.GetProvisioningTypeKey()]
+	encryption := req.GetParameters()[topolvm.GetEncryptionKey()]
+	colocation := req.GetParameters()[topolvm.GetColocationKey()]
 
 	ctrlLogger.Info("CreateVolume called",
 		"name", req.GetName(),
@@ -153,12 +205,51 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 	if capabilities == nil {
 		return nil, status.Error(codes.InvalidArgument, "no volume capabilities are provided")
 	}
+	fsType := filesystemType(capabilities)
+	if err := ValidateParameters(req.GetParameters(), fsType); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	wipeOnDelete, err := s.wipeOnDelete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	snapshotBeforeExpand := req.GetParameters()[topolvm.GetSnapshotBeforeExpandKey()]
+	fsLabel, err := filesystemLabel(req.GetParameters(), fsType)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fsDeterministicUUID, err := useDeterministicUUID(req.GetParameters(), fsType)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fsProjectQuota, err := useProjectQuota(req.GetParameters(), fsType)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	fsMkfsOptions, err := mkfsOptions(req.GetParameters(), fsType)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	mountFlags, hasMountFlags := req.GetParameters()[topolvm.GetDefaultMountFlagsKey()]
+	fsGroupPolicy, err := fsGroupChangePolicy(req.GetParameters())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	warmUp := req.GetParameters()[topolvm.GetWarmUpKey()]
+	if warmUp != "" && !s.settings.VolumeWarmUp {
+		// the volume would never be published without the controller running the warm-up Job.
+		return nil, status.Errorf(codes.InvalidArgument, "%s requires --volume-warm-up of topolvm-controller", topolvm.GetWarmUpKey())
+	}
 
 	required, limit := s.settings.MinimumAllocationSettings.MinMaxAllocationsFromSettings(
 		req.GetCapacityRange().GetRequiredBytes(),
 		req.GetCapacityRange().GetLimitBytes(),
 		capabilities,
 	)
+	required, limit, err = s.settings.MaximumAllocationSettings.MaxAllocationsFromSettings(deviceClass, required, limit, capabilities)
+	if err != nil {
+		return nil, status.Error(codes.OutOfRange, err.Error())
+	}
 
 	// check required volume capabilities
 	for _, capability := range capabilities {
@@ -185,7 +276,12 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 		}
 	}
 
-	requestCapacityBytes, err := convertRequestCapacityBytes(required, limit)
+	// the volumes with a source keep the requested size, since thick snapshots must have the size of their source.
+	var allocationUnit int64
+	if source == nil {
+		allocationUnit = s.settings.AllocationUnitSettings.GetAllocationUnit(deviceClass)
+	}
+	requestCapacityBytes, err := convertRequestCapacityBytes(required, limit, allocationUnit)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -256,7 +352,7 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 			// - https://github.com/container-storage-interface/spec/blob/release-1.1/spec.md#createvolume
 			// - https://github.com/kubernetes-csi/csi-test/blob/6738ab2206eac88874f0a3ede59b40f680f59f43/pkg/sanity/controller.go#L404-L428
 			ctrlLogger.Info("decide node because accessibility_requirements not found")
-			nodeName, capacity, err := s.nodeService.GetMaxCapacity(ctx, deviceClass)
+			nodeName, capacity, err := s.nodeService.ForProvisioningType(provisioningType).GetMaxCapacity(ctx, deviceClass)
 
 			if err != nil {
 				return nil, status.Errorf(codes.Internal, "failed to get max capacity node %v", err)
@@ -265,14 +361,23 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 				return nil, status.Error(codes.Internal, "can not find any node")
 			}
 			if capacity < requestCapacityBytes {
-				return nil, status.Errorf(codes.ResourceExhausted, "can not find enough volume space %d", capacity)
+				return nil, status.Errorf(codes.ResourceExhausted, "can not find enough volume space in device-class %s: free=%d, requested=%d",
+					deviceClass, capacity, requestCapacityBytes)
 			}
 			node = nodeName
 		} else {
-			for _, topo := range requirements.Preferred {
-				if v, ok := topo.GetSegments()[topolvm.GetTopologyNodeKey()]; ok {
-					node = v
-					break
+			if colocation != "" {
+				node, err = s.colocatedNode(ctx, req, colocation, deviceClass, requestCapacityBytes)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if node == "" {
+				for _, topo := range requirements.Preferred {
+					if v, ok := topo.GetSegments()[topolvm.GetTopologyNodeKey()]; ok {
+						node = v
+						break
+					}
 				}
 			}
 			if node == "" {
@@ -289,6 +394,12 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 		}
 	}
 
+	if len(lvcreateOptions) > 0 {
+		if err := s.validateLvcreateOptions(ctx, node, lvcreateOptionClass, lvcreateOptions); err != nil {
+			return nil, err
+		}
+	}
+
 	name := req.GetName()
 	if name == "" {
 		return nil, status.Error(codes.InvalidArgument, "invalid name")
@@ -296,19 +407,52 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 
 	name = strings.ToLower(name)
 
-	volumeID, err := s.lvService.CreateVolume(ctx, node, deviceClass, lvcreateOptionClass, name, sourceName, requestCapacityBytes)
+	volumeID, err := s.lvService.CreateVolume(ctx, node, deviceClass, lvcreateOptionClass, lvcreateOptions, provisioningType, wipeOnDelete, snapshotBeforeExpand, name, sourceName, requestCapacityBytes)
 	if err != nil {
-		_, ok := status.FromError(err)
-		if !ok {
-			return nil, status.Error(codes.Internal, err.Error())
-		}
-		return nil, err
+		return nil, createVolumeError(err)
+	}
+
+	var volumeContext map[string]string
+	if encryption != "" || fsLabel != "" || fsDeterministicUUID || fsMkfsOptions != "" || fsProjectQuota || hasMountFlags || fsGroupPolicy != "" || warmUp != "" {
+		volumeContext = make(map[string]string)
+	}
+	if encryption != "" {
+		// the node server needs to know the volume is encrypted on NodeStageVolume and NodePublishVolume.
+		volumeContext[topolvm.GetEncryptionKey()] = encryption
+	}
+	// the node server sets the label and UUID, and passes the mkfs options when it creates the filesystem on NodePublishVolume.
+	if fsLabel != "" {
+		volumeContext[topolvm.GetFilesystemLabelKey()] = fsLabel
+	}
+	if fsDeterministicUUID {
+		volumeContext[topolvm.GetFilesystemUUIDKey()] = deterministicUUID(volumeID)
+	}
+	if fsMkfsOptions != "" {
+		volumeContext[topolvm.GetMkfsOptionsKey()] = fsMkfsOptions
+	}
+	// the node server mounts the filesystem with project quota on NodePublishVolume.
+	if fsProjectQuota {
+		volumeContext[topolvm.GetProjectQuotaKey()] = "true"
+	}
+	// the node server uses the default mount flags of the StorageClass instead of its own on NodePublishVolume.
+	if hasMountFlags {
+		volumeContext[topolvm.GetDefaultMountFlagsKey()] = mountFlags
+	}
+	// the node server applies the fsGroup delegated by kubelet by the policy on NodePublishVolume.
+	if fsGroupPolicy != "" {
+		volumeContext[topolvm.GetFSGroupChangePolicyKey()] = string(fsGroupPolicy)
+	}
+	// the node server publishes the volume only to the warm-up Job until it has succeeded,
+	// and the warm-up controller finds the template from the attributes of the PersistentVolume.
+	if warmUp != "" {
+		volumeContext[topolvm.GetWarmUpKey()] = warmUp
 	}
 
 	return &csi.CreateVolumeResponse{
 		Volume: &csi.Volume{
 			CapacityBytes: requestCapacityBytes,
 			VolumeId:      volumeID,
+			VolumeContext: volumeContext,
 			ContentSource: source,
 			AccessibleTopology: []*csi.Topology{
 				{
@@ -319,6 +463,116 @@ func (s controllerServerNoLocked) CreateVolume(ctx context.Context, req *csi.Cre
 	}, nil
 }
 
+// wipeOnDelete returns the wipe-on-delete of the volume overriding that of the device-class, which is the
+// annotation of the PVC if any, or the parameter of the StorageClass.
+func (s controllerServerNoLocked) wipeOnDelete(ctx context.Context, req *csi.CreateVolumeRequest) (string, error) {
+	wipeOnDelete := req.GetParameters()[topolvm.GetWipeOnDeleteKey()]
+	namespace := req.GetParameters()[pvcNamespaceKey]
+	name := req.GetParameters()[pvcNameKey]
+	if namespace != "" && name != "" {
+		annotation, err := s.pvcService.GetAnnotation(ctx, namespace, name, topolvm.GetWipeOnDeleteKey())
+		if err != nil {
+			return "", status.Errorf(codes.Internal, "failed to get PVC %s/%s: %v", namespace, name, err)
+		}
+		if annotation != "" {
+			wipeOnDelete = annotation
+		}
+	}
+	switch lvmdTypes.WipePolicy(wipeOnDelete) {
+	case "", lvmdTypes.WipeNone, lvmdTypes.WipeDiscard, lvmdTypes.WipeZero:
+	default:
+		return "", status.Errorf(codes.InvalidArgument, "invalid wipe-on-delete: %s", wipeOnDelete)
+	}
+	return wipeOnDelete, nil
+}
+
+// colocatedNode returns the node of the volumes of the sibling PVCs, i.e. the PVCs mounted by the same pod,
+// if the volume can be provisioned there. Otherwise, it returns an empty string if colocation is preferred,
+// or an error if it is required. The PVC of the volume is known from the parameters added by the
+// --extra-create-metadata flag of the external-provisioner.
+func (s controllerServerNoLocked) colocatedNode(ctx context.Context, req *csi.CreateVolumeRequest,
+	colocation, deviceClass string, requestBytes int64) (string, error) {
+	namespace := req.GetParameters()[pvcNamespaceKey]
+	name := req.GetParameters()[pvcNameKey]
+	if namespace == "" || name == "" {
+		ctrlLogger.Info("skip colocation because the PVC is unknown", "name", req.GetName())
+		return "", nil
+	}
+
+	siblings, err := s.colocationService.GetSiblingVolumeNames(ctx, namespace, name)
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "failed to get sibling PVCs of %s/%s: %v", namespace, name, err)
+	}
+	var node, sibling string
+	for _, volume := range siblings {
+		lv, err := s.lvService.GetVolumeByName(ctx, volume)
+		if errors.Is(err, k8s.ErrVolumeNotFound) {
+			continue
+		} else if err != nil {
+			return "", status.Errorf(codes.Internal, "failed to get volume %s: %v", volume, err)
+		}
+		node, sibling = lv.Spec.NodeName, volume
+		break
+	}
+	if node == "" {
+		return "", nil
+	}
+
+	reason := ""
+	if !hasTopologyNode(req.GetAccessibilityRequirements(), node) {
+		reason = "it is not accessible"
+	} else if capacity, err := s.nodeService.ForProvisioningType(req.GetParameters()[topolvm.GetProvisioningTypeKey()]).
+		GetCapacityByName(ctx, node, deviceClass); err != nil || capacity < requestBytes {
+		reason = "it does not have enough capacity"
+	}
+	if reason == "" {
+		ctrlLogger.Info("colocate volume with its sibling", "name", req.GetName(), "sibling", sibling, "node", node)
+		return node, nil
+	}
+	if colocation == colocationRequired {
+		return "", status.Errorf(codes.ResourceExhausted, "cannot provision the volume on node %s of sibling volume %s because %s", node, sibling, reason)
+	}
+	ctrlLogger.Info("skip colocation", "name", req.GetName(), "sibling", sibling, "node", node, "reason", reason)
+	return "", nil
+}
+
+func hasTopologyNode(requirements *csi.TopologyRequirement, node string) bool {
+	for _, topo := range append(requirements.GetRequisite(), requirements.GetPreferred()...) {
+		if topo.GetSegments()[topolvm.GetTopologyNodeKey()] == node {
+			return true
+		}
+	}
+	return false
+}
+
+// validateLvcreateOptions checks that lvmd on the node allows the inline lvcreate options for the lvcreate-option-class.
+// The allowed prefixes are advertised by lvmd in the annotations of the node.
+func (s controllerServerNoLocked) validateLvcreateOptions(ctx context.Context, node, lvcreateOptionClass string, options []string) error {
+	allowed, err := s.nodeService.GetLvcreateInlineOptions(ctx, node, lvcreateOptionClass)
+	if err != nil {
+		if errors.Is(err, k8s.ErrNodeNotFound) {
+			return status.Errorf(codes.InvalidArgument, "node %s is not found", node)
+		}
+		return status.Errorf(codes.Internal, "failed to get inline lvcreate options of node %s: %v", node, err)
+	}
+	for _, option := range options {
+		if !hasAnyPrefix(option, allowed) {
+			return status.Errorf(codes.InvalidArgument, "inline lvcreate option %q is not allowed by lvcreate-option-class %s on node %s",
+				option, lvcreateOptionClass, node)
+		}
+	}
+	return nil
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if prefix != "" && strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // validateContentSource checks if the request has a data source and returns source volume information.
 func (s controllerServerNoLocked) validateContentSource(ctx context.Context, req *csi.CreateVolumeRequest) (*v1.LogicalVolume, string, error) {
 	volumeSource := req.VolumeContentSource
@@ -359,7 +613,7 @@ func (s controllerServerNoLocked) validateContentSource(ctx context.Context, req
 
 // CreateSnapshot creates a logical volume snapshot.
 func (s controllerServerNoLocked) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequest) (*csi.CreateSnapshotResponse, error) {
-	// Since the kubernetes snapshots are Read-Only, we set accessType as 'ro' to activate thin-snapshots as read-only volumes
+	// Since the kubernetes snapshots are Read-Only, we set accessType as 'ro' to activate snapshots as read-only volumes
 	accessType := "ro"
 
 	ctrlLogger.Info("CreateSnapshot called",
@@ -424,7 +678,7 @@ func (s controllerServerNoLocked) DeleteSnapshot(ctx context.Context, req *csi.D
 		return nil, status.Error(codes.InvalidArgument, "missing snapshot id")
 	}
 
-	if err := s.lvService.DeleteVolume(ctx, req.GetSnapshotId()); err != nil {
+	if err := s.deleteLogicalVolume(ctx, req.GetSnapshotId()); err != nil {
 		ctrlLogger.Error(err, "DeleteSnapshot failed", "snapshot_id", req.GetSnapshotId())
 		_, ok := status.FromError(err)
 		if !ok {
@@ -437,7 +691,8 @@ func (s controllerServerNoLocked) DeleteSnapshot(ctx context.Context, req *csi.D
 }
 
 // convertRequestCapacityBytes converts requestBytes and limitBytes to a valid capacity.
-func convertRequestCapacityBytes(requestBytes, limitBytes int64) (int64, error) {
+// The capacity is rounded up to a multiple of allocationUnit if it is positive and the result does not exceed the limit.
+func convertRequestCapacityBytes(requestBytes, limitBytes, allocationUnit int64) (int64, error) {
 	if requestBytes < 0 {
 		return 0, ErrNoNegativeRequestBytes
 	}
@@ -457,7 +712,7 @@ func convertRequestCapacityBytes(requestBytes, limitBytes int64) (int64, error)
 	if requestBytes == 0 {
 		// if there is no limit or the limit is bigger or equal to the default, use the default
 		if limitBytes == 0 || limitBytes >= topolvm.DefaultSize {
-			return topolvm.DefaultSize, nil
+			return roundUpToAllocationUnit(topolvm.DefaultSize, limitBytes, allocationUnit), nil
 		}
 
 		roundedLimit := roundDown(limitBytes, topolvm.MinimumSectorSize)
@@ -481,7 +736,20 @@ func convertRequestCapacityBytes(requestBytes, limitBytes int64) (int64, error)
 		}
 	}
 
-	return requestBytes, nil
+	return roundUpToAllocationUnit(requestBytes, limitBytes, allocationUnit), nil
+}
+
+// roundUpToAllocationUnit rounds up size to the nearest multiple of unit, unless it exceeds limitBytes.
+// The volume must not be larger than the limit, so the size is kept as it is then.
+func roundUpToAllocationUnit(size, limitBytes, unit int64) int64 {
+	if unit <= 0 {
+		return size
+	}
+	rounded := roundUp(size, unit)
+	if limitBytes > 0 && rounded > limitBytes {
+		return size
+	}
+	return rounded
 }
 
 // roundUp rounds up the size to the nearest given multiple.
@@ -502,7 +770,7 @@ func (s controllerServerNoLocked) DeleteVolume(ctx context.Context, req *csi.Del
 		return nil, status.Error(codes.InvalidArgument, "volume_id is not provided")
 	}
 
-	err := s.lvService.DeleteVolume(ctx, req.GetVolumeId())
+	err := s.deleteLogicalVolume(ctx, req.GetVolumeId())
 	if err != nil {
 		ctrlLogger.Error(err, "DeleteVolume failed", "volume_id", req.GetVolumeId())
 		_, ok := status.FromError(err)
@@ -515,6 +783,26 @@ func (s controllerServerNoLocked) DeleteVolume(ctx context.Context, req *csi.Del
 	return &csi.DeleteVolumeResponse{}, nil
 }
 
+// deleteLogicalVolume deletes the LogicalVolume of a volume or a snapshot once the deletion pacer lets it,
+// and waits until the node removes the logical volume.
+func (s controllerServerNoLocked) deleteLogicalVolume(ctx context.Context, volumeID string) error {
+	lv, err := s.lvService.GetVolume(ctx, volumeID)
+	if err != nil {
+		if err == k8s.ErrVolumeNotFound {
+			// nothing to pace, DeleteVolume of LogicalVolumeService succeeds.
+			return s.lvService.DeleteVolume(ctx, volumeID)
+		}
+		return err
+	}
+
+	release, err := s.deletionPacer.acquire(ctx, lv.Spec.NodeName)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.lvService.DeleteVolume(ctx, volumeID)
+}
+
 func (s controllerServerNoLocked) ValidateVolumeCapabilities(ctx context.Context, req *csi.ValidateVolumeCapabilitiesRequest) (*csi.ValidateVolumeCapabilitiesResponse, error) {
 	ctrlLogger.Info("ValidateVolumeCapabilities called",
 		"volume_id", req.GetVolumeId(),
@@ -561,12 +849,13 @@ func (s controllerServerNoLocked) GetCapacity(ctx context.Context, req *csi.GetC
 	}
 
 	deviceClass := req.GetParameters()[topolvm.GetDeviceClassKey()]
+	nodeService := s.nodeService.ForProvisioningType(req.GetParameters()[topolvm.GetProvisioningTypeKey()])
 
 	var capacity int64
 	switch topology {
 	case nil:
 		var err error
-		capacity, err = s.nodeService.GetTotalCapacity(ctx, deviceClass)
+		capacity, err = nodeService.GetTotalCapacity(ctx, deviceClass)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
@@ -578,7 +867,7 @@ func (s controllerServerNoLocked) GetCapacity(ctx context.Context, req *csi.GetC
 			return &csi.GetCapacityResponse{AvailableCapacity: 0}, nil
 		}
 		var err error
-		capacity, err = s.nodeService.GetCapacityByTopologyLabel(ctx, v, deviceClass)
+		capacity, err = nodeService.GetCapacityByTopologyLabel(ctx, v, deviceClass)
 		switch err {
 		case k8s.ErrNodeNotFound:
 			ctrlLogger.Info("target is not found", "accessible_topology", req.AccessibleTopology)
@@ -597,6 +886,243 @@ func (s controllerServerNoLocked) GetCapacity(ctx context.Context, req *csi.GetC
 	}, nil
 }
 
+func (s controllerServerNoLocked) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (*csi.ListVolumesResponse, error) {
+	ctrlLogger.V(1).Info("ListVolumes called",
+		"max_entries", req.GetMaxEntries(),
+		"starting_token", req.GetStartingToken())
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_entries must not be negative")
+	}
+
+	lvs, err := s.lvService.ListVolumes(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return listVolumesPage(lvs, int(req.GetMaxEntries()), req.GetStartingToken())
+}
+
+func (s controllerServerNoLocked) ControllerGetVolume(ctx context.Context, req *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
+	volumeID := req.GetVolumeId()
+	ctrlLogger.V(1).Info("ControllerGetVolume called", "volume_id", volumeID)
+	if volumeID == "" {
+		return nil, status.Error(codes.InvalidArgument, "volume id is nil")
+	}
+
+	lv, err := s.lvService.GetVolume(ctx, volumeID)
+	if errors.Is(err, k8s.ErrVolumeNotFound) {
+		return nil, status.Errorf(codes.NotFound, "volume %s is not found", volumeID)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	// snapshots are not volumes, as in ListVolumes.
+	if isSnapshot(lv) {
+		return nil, status.Errorf(codes.NotFound, "%s is a snapshot, not a volume", volumeID)
+	}
+
+	var source *v1.LogicalVolume
+	if lv.Spec.Source != "" {
+		source, err = s.lvService.GetVolumeByName(ctx, lv.Spec.Source)
+		if err != nil && !errors.Is(err, k8s.ErrVolumeNotFound) {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	entry := listVolumesEntry(lv, source)
+	return &csi.ControllerGetVolumeResponse{
+		Volume: entry.Volume,
+		Status: &csi.ControllerGetVolumeResponse_VolumeStatus{
+			PublishedNodeIds: entry.Status.PublishedNodeIds,
+			VolumeCondition:  entry.Status.VolumeCondition,
+		},
+	}, nil
+}
+
+// listVolumesPage returns the page of the volumes of lvs starting at the volume ID startingToken, or at the first
+// volume if it is empty. The volumes are sorted by their IDs, so that the next token, which is the volume ID of the
+// first volume of the next page, stays valid while volumes are created or deleted, unless that volume is deleted.
+// Snapshots and the volumes not yet created or being deleted are not listed.
+func listVolumesPage(lvs []v1.LogicalVolume, maxEntries int, startingToken string) (*csi.ListVolumesResponse, error) {
+	byName := make(map[string]*v1.LogicalVolume, len(lvs))
+	for i := range lvs {
+		byName[lvs[i].Name] = &lvs[i]
+	}
+	volumes := make([]*v1.LogicalVolume, 0, len(lvs))
+	for i := range lvs {
+		lv := &lvs[i]
+		if lv.Status.VolumeID == "" || lv.DeletionTimestamp != nil || isSnapshot(lv) {
+			continue
+		}
+		volumes = append(volumes, lv)
+	}
+	sort.Slice(volumes, func(i, j int) bool { return volumes[i].Status.VolumeID < volumes[j].Status.VolumeID })
+
+	start, end, err := pageBounds(volumes, maxEntries, startingToken)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &csi.ListVolumesResponse{Entries: make([]*csi.ListVolumesResponse_Entry, 0, end-start)}
+	for _, lv := range volumes[start:end] {
+		res.Entries = append(res.Entries, listVolumesEntry(lv, byName[lv.Spec.Source]))
+	}
+	if end < len(volumes) {
+		res.NextToken = volumes[end].Status.VolumeID
+	}
+	return res, nil
+}
+
+func (s controllerServerNoLocked) ListSnapshots(ctx context.Context, req *csi.ListSnapshotsRequest) (*csi.ListSnapshotsResponse, error) {
+	ctrlLogger.V(1).Info("ListSnapshots called",
+		"max_entries", req.GetMaxEntries(),
+		"starting_token", req.GetStartingToken(),
+		"snapshot_id", req.GetSnapshotId(),
+		"source_volume_id", req.GetSourceVolumeId())
+	if req.GetMaxEntries() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_entries must not be negative")
+	}
+
+	lvs, err := s.lvService.ListVolumes(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return listSnapshotsPage(lvs, int(req.GetMaxEntries()), req.GetStartingToken(), req.GetSnapshotId(), req.GetSourceVolumeId())
+}
+
+// listSnapshotsPage returns the page of the snapshots of lvs in the same way as listVolumesPage.
+// The snapshots are filtered by snapshotID and sourceVolumeID if they are not empty.
+func listSnapshotsPage(lvs []v1.LogicalVolume, maxEntries int, startingToken, snapshotID, sourceVolumeID string) (*csi.ListSnapshotsResponse, error) {
+	byName := make(map[string]*v1.LogicalVolume, len(lvs))
+	for i := range lvs {
+		byName[lvs[i].Name] = &lvs[i]
+	}
+	snapshots := make([]*v1.LogicalVolume, 0, len(lvs))
+	for i := range lvs {
+		lv := &lvs[i]
+		if lv.Status.VolumeID == "" || lv.DeletionTimestamp != nil || !isSnapshot(lv) {
+			continue
+		}
+		if snapshotID != "" && lv.Status.VolumeID != snapshotID {
+			continue
+		}
+		if sourceVolumeID != "" && snapshotSourceVolumeID(lv, byName[lv.Spec.Source]) != sourceVolumeID {
+			continue
+		}
+		snapshots = append(snapshots, lv)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Status.VolumeID < snapshots[j].Status.VolumeID })
+
+	start, end, err := pageBounds(snapshots, maxEntries, startingToken)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &csi.ListSnapshotsResponse{Entries: make([]*csi.ListSnapshotsResponse_Entry, 0, end-start)}
+	for _, lv := range snapshots[start:end] {
+		size := lv.Spec.Size.Value()
+		if lv.Status.CurrentSize != nil {
+			size = lv.Status.CurrentSize.Value()
+		}
+		res.Entries = append(res.Entries, &csi.ListSnapshotsResponse_Entry{
+			Snapshot: &csi.Snapshot{
+				SnapshotId:     lv.Status.VolumeID,
+				SourceVolumeId: snapshotSourceVolumeID(lv, byName[lv.Spec.Source]),
+				SizeBytes:      size,
+				CreationTime:   &timestamp.Timestamp{Seconds: lv.CreationTimestamp.Unix()},
+				ReadyToUse:     true,
+			},
+		})
+	}
+	if end < len(snapshots) {
+		res.NextToken = snapshots[end].Status.VolumeID
+	}
+	return res, nil
+}
+
+// snapshotSourceVolumeID returns the volume ID of the source of the snapshot lv, which is nil if it has been deleted.
+// The origin in LVM is used then, which is also empty once the source LV has been removed.
+func snapshotSourceVolumeID(lv, source *v1.LogicalVolume) string {
+	if source != nil {
+		return source.Status.VolumeID
+	}
+	return lv.Status.Origin
+}
+
+// pageBounds returns the range of lvs sorted by their volume IDs on the page starting at the volume ID startingToken
+// with up to maxEntries items, or all the rest if maxEntries is 0.
+// The volume of startingToken may have been deleted since the previous page, so the page starts at the first volume
+// ID not less than it. Only a token that cannot be a volume ID, i.e. the UID of a LogicalVolume, is rejected.
+func pageBounds(lvs []*v1.LogicalVolume, maxEntries int, startingToken string) (int, int, error) {
+	start := 0
+	if startingToken != "" {
+		if errs := validation.IsDNS1123Label(startingToken); len(errs) != 0 {
+			return 0, 0, status.Errorf(codes.Aborted, "starting_token %q is not a volume ID: %s", startingToken, strings.Join(errs, ", "))
+		}
+		start = sort.Search(len(lvs), func(i int) bool { return lvs[i].Status.VolumeID >= startingToken })
+	}
+	end := len(lvs)
+	if maxEntries > 0 && start+maxEntries < end {
+		end = start + maxEntries
+	}
+	return start, end, nil
+}
+
+// isSnapshot returns true if lv is the read-only snapshot of a VolumeSnapshot rather than a volume.
+func isSnapshot(lv *v1.LogicalVolume) bool {
+	return lv.Spec.Source != "" && lv.Spec.AccessType == "ro"
+}
+
+// listVolumesEntry returns the entry of ListVolumes for lv created from source, which is nil if lv has no source
+// or the source has been deleted. A volume can only be published on its node, which is reported as published.
+func listVolumesEntry(lv, source *v1.LogicalVolume) *csi.ListVolumesResponse_Entry {
+	capacity := lv.Spec.Size.Value()
+	if lv.Status.CurrentSize != nil {
+		capacity = lv.Status.CurrentSize.Value()
+	}
+	volume := &csi.Volume{
+		CapacityBytes: capacity,
+		VolumeId:      lv.Status.VolumeID,
+		AccessibleTopology: []*csi.Topology{
+			{
+				Segments: map[string]string{topolvm.GetTopologyNodeKey(): lv.Spec.NodeName},
+			},
+		},
+	}
+	switch {
+	case source == nil:
+	case isSnapshot(source):
+		volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Snapshot{
+				Snapshot: &csi.VolumeContentSource_SnapshotSource{SnapshotId: source.Status.VolumeID},
+			},
+		}
+	default:
+		volume.ContentSource = &csi.VolumeContentSource{
+			Type: &csi.VolumeContentSource_Volume{
+				Volume: &csi.VolumeContentSource_VolumeSource{VolumeId: source.Status.VolumeID},
+			},
+		}
+	}
+	return &csi.ListVolumesResponse_Entry{
+		Volume: volume,
+		Status: &csi.ListVolumesResponse_VolumeStatus{
+			PublishedNodeIds: []string{lv.Spec.NodeName},
+			VolumeCondition:  volumeConditionOf(lv),
+		},
+	}
+}
+
+// volumeConditionOf returns the condition of lv for the external health monitor. The volume is abnormal
+// while topolvm-node reports its LV or thin pool unhealthy, or while its node is deleted.
+func volumeConditionOf(lv *v1.LogicalVolume) *csi.VolumeCondition {
+	if cond := meta.FindStatusCondition(lv.Status.Conditions, v1.ConditionNodeDeleted); cond != nil && cond.Status == metav1.ConditionTrue {
+		return &csi.VolumeCondition{Abnormal: true, Message: "the node of the volume is deleted: " + cond.Message}
+	}
+	if cond := meta.FindStatusCondition(lv.Status.Conditions, v1.ConditionVolumeHealthy); cond != nil && cond.Status == metav1.ConditionFalse {
+		return &csi.VolumeCondition{Abnormal: true, Message: cond.Message}
+	}
+	return &csi.VolumeCondition{Abnormal: false, Message: "volume is healthy"}
+}
+
 func (s controllerServerNoLocked) ControllerGetCapabilities(context.Context, *csi.ControllerGetCapabilitiesRequest) (*csi.ControllerGetCapabilitiesResponse, error) {
 	capabilities := []csi.ControllerServiceCapability_RPC_Type{
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_VOLUME,
@@ -604,6 +1130,11 @@ func (s controllerServerNoLocked) ControllerGetCapabilities(context.Context, *cs
 		csi.ControllerServiceCapability_RPC_GET_CAPACITY,
 		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
+		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
+		csi.ControllerServiceCapability_RPC_LIST_VOLUMES_PUBLISHED_NODES,
+		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 	}
 
 	csiCaps := make([]*csi.ControllerServiceCapability, len(capabilities))
@@ -642,10 +1173,22 @@ func (s controllerServerNoLocked) ControllerExpandVolume(ctx context.Context, re
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	requestCapacityBytes, err := convertRequestCapacityBytes(
+	var capabilities []*csi.VolumeCapability
+	if capability := req.GetVolumeCapability(); capability != nil {
+		capabilities = append(capabilities, capability)
+	}
+	required, limit, err := s.settings.MaximumAllocationSettings.MaxAllocationsFromSettings(
+		lv.Spec.DeviceClass,
 		req.GetCapacityRange().GetRequiredBytes(),
 		req.GetCapacityRange().GetLimitBytes(),
+		capabilities,
 	)
+	if err != nil {
+		return nil, status.Error(codes.OutOfRange, err.Error())
+	}
+	// unlike on creation, the size of a restored or cloned volume no longer has to match its source.
+	allocationUnit := s.settings.AllocationUnitSettings.GetAllocationUnit(lv.Spec.DeviceClass)
+	requestCapacityBytes, err := convertRequestCapacityBytes(required, limit, allocationUnit)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
@@ -664,7 +1207,7 @@ func (s controllerServerNoLocked) ControllerExpandVolume(ctx context.Context, re
 			NodeExpansionRequired: true,
 		}, nil
 	}
-	capacity, err := s.nodeService.GetCapacityByName(ctx, lv.Spec.NodeName, lv.Spec.DeviceClass)
+	capacity, err := s.nodeService.ForProvisioningType(lv.Spec.ProvisioningType).GetCapacityByName(ctx, lv.Spec.NodeName, lv.Spec.DeviceClass)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -686,3 +1229,21 @@ func (s controllerServerNoLocked) ControllerExpandVolume(ctx context.Context, re
 		NodeExpansionRequired: true,
 	}, nil
 }
+
+// createVolumeError translates the error of creating the LogicalVolume for the external-provisioner.
+// The codes reported by lvmd through the status of the LogicalVolume are kept, in particular ResourceExhausted,
+// on which the provisioner lets the scheduler select another node. The timeouts waiting for the LogicalVolume
+// are reported as DeadlineExceeded, so that the provisioner retries with backoff rather than giving up,
+// since the volume may still be created.
+func createVolumeError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
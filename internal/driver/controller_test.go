@@ -1,19 +1,36 @@
 package driver
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/topolvm/topolvm"
+	v1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/internal/driver/internal/k8s"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func Test_convertRequestCapacityBytes(t *testing.T) {
 	testCases := []struct {
-		requestBytes int64
-		limitBytes   int64
-		expected     int64
-		err          error
+		requestBytes   int64
+		limitBytes     int64
+		allocationUnit int64
+		expected       int64
+		err            error
 	}{
 		{
 			requestBytes: -1,
@@ -70,10 +87,31 @@ func Test_convertRequestCapacityBytes(t *testing.T) {
 			limitBytes:   0,
 			expected:     1 << 30,
 		},
+		{
+			requestBytes:   1<<30 + 1,
+			allocationUnit: 1 << 30,
+			expected:       2 << 30,
+		},
+		{
+			requestBytes:   0,
+			allocationUnit: 3 << 29,
+			expected:       3 << 29,
+		},
+		{
+			requestBytes:   2 << 30,
+			allocationUnit: 1 << 30,
+			expected:       2 << 30,
+		},
+		{
+			requestBytes:   1<<30 + 1,
+			limitBytes:     3 << 29,
+			allocationUnit: 1 << 30,
+			expected:       1<<30 + topolvm.MinimumSectorSize,
+		},
 	}
 
 	for _, tc := range testCases {
-		tcName := fmt.Sprintf("request:%d limit:%d", tc.requestBytes, tc.limitBytes)
+		tcName := fmt.Sprintf("request:%d limit:%d unit:%d", tc.requestBytes, tc.limitBytes, tc.allocationUnit)
 		if tc.err != nil {
 			tcName += fmt.Sprintf(" = %s", tc.err)
 		} else {
@@ -81,7 +119,7 @@ func Test_convertRequestCapacityBytes(t *testing.T) {
 		}
 
 		t.Run(tcName, func(t *testing.T) {
-			v, err := convertRequestCapacityBytes(tc.requestBytes, tc.limitBytes)
+			v, err := convertRequestCapacityBytes(tc.requestBytes, tc.limitBytes, tc.allocationUnit)
 			if !errors.Is(err, tc.err) {
 				t.Errorf("expected error %v, but got %v", tc.err, err)
 			}
@@ -114,3 +152,486 @@ func Test_roundUp(t *testing.T) {
 		})
 	}
 }
+
+func Test_createVolumeError(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		expected codes.Code
+	}{
+		{"lvmd status", status.Error(codes.ResourceExhausted, "no enough space left on VG vg of device-class ssd"), codes.ResourceExhausted},
+		{"timeout", fmt.Errorf("waiting for LogicalVolume: %w", context.DeadlineExceeded), codes.DeadlineExceeded},
+		{"canceled", context.Canceled, codes.Canceled},
+		{"other", errors.New("conflict"), codes.Internal},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := createVolumeError(tc.err)
+			if code := status.Code(err); code != tc.expected {
+				t.Errorf("expected %s, but was %s: %v", tc.expected, code, err)
+			}
+		})
+	}
+}
+
+func Test_listVolumesPage(t *testing.T) {
+	lv := func(name, volumeID, source, accessType string) v1.LogicalVolume {
+		return v1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.LogicalVolumeSpec{
+				NodeName:   "node-" + name,
+				Size:       resource.MustParse("1Gi"),
+				Source:     source,
+				AccessType: accessType,
+			},
+			Status: v1.LogicalVolumeStatus{VolumeID: volumeID},
+		}
+	}
+	deleting := lv("deleting", "v-deleting", "", "")
+	deleting.DeletionTimestamp = &metav1.Time{}
+	lvs := []v1.LogicalVolume{
+		lv("c", "v-c", "", ""),
+		lv("a", "v-a", "", ""),
+		lv("snapshot", "v-snapshot", "a", "ro"),
+		lv("restored", "v-restored", "snapshot", "rw"),
+		lv("clone", "v-clone", "a", "rw"),
+		lv("pending", "", "", ""),
+		deleting,
+	}
+	ids := func(res *csi.ListVolumesResponse) []string {
+		var ids []string
+		for _, e := range res.GetEntries() {
+			ids = append(ids, e.GetVolume().GetVolumeId())
+		}
+		return ids
+	}
+
+	res, err := listVolumesPage(lvs, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"v-a", "v-c", "v-clone", "v-restored"}) || res.GetNextToken() != "" {
+		t.Errorf("unexpected volumes: %v, next token %q", got, res.GetNextToken())
+	}
+	for _, e := range res.GetEntries() {
+		v := e.GetVolume()
+		if v.GetCapacityBytes() != 1<<30 {
+			t.Errorf("unexpected capacity of %s: %d", v.GetVolumeId(), v.GetCapacityBytes())
+		}
+		node := v.GetAccessibleTopology()[0].GetSegments()[topolvm.GetTopologyNodeKey()]
+		if published := e.GetStatus().GetPublishedNodeIds(); len(published) != 1 || published[0] != node {
+			t.Errorf("%s should be published on %s, got %v", v.GetVolumeId(), node, published)
+		}
+		switch v.GetVolumeId() {
+		case "v-clone":
+			if v.GetContentSource().GetVolume().GetVolumeId() != "v-a" {
+				t.Errorf("unexpected content source of the clone: %v", v.GetContentSource())
+			}
+		case "v-restored":
+			if v.GetContentSource().GetSnapshot().GetSnapshotId() != "v-snapshot" {
+				t.Errorf("unexpected content source of the restored volume: %v", v.GetContentSource())
+			}
+		default:
+			if v.GetContentSource() != nil {
+				t.Errorf("unexpected content source of %s: %v", v.GetVolumeId(), v.GetContentSource())
+			}
+		}
+	}
+
+	res, err = listVolumesPage(lvs, 3, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"v-a", "v-c", "v-clone"}) || res.GetNextToken() != "v-restored" {
+		t.Errorf("unexpected first page: %v, next token %q", got, res.GetNextToken())
+	}
+	res, err = listVolumesPage(lvs, 3, res.GetNextToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"v-restored"}) || res.GetNextToken() != "" {
+		t.Errorf("unexpected last page: %v, next token %q", got, res.GetNextToken())
+	}
+
+	// the volume of the token has been deleted since the previous page.
+	res, err = listVolumesPage(lvs, 3, "v-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"v-c", "v-clone", "v-restored"}) || res.GetNextToken() != "" {
+		t.Errorf("unexpected page after a deleted volume: %v, next token %q", got, res.GetNextToken())
+	}
+	res, err = listVolumesPage(lvs, 3, "v-z")
+	if err != nil || len(res.GetEntries()) != 0 {
+		t.Errorf("expected no volume after the last one, got %v, %v", ids(res), err)
+	}
+
+	_, err = listVolumesPage(lvs, 0, "Invalid Token")
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("expected Aborted for an invalid token, got %v", err)
+	}
+}
+
+func Test_listSnapshotsPage(t *testing.T) {
+	lv := func(name, volumeID, source, accessType, origin string) v1.LogicalVolume {
+		return v1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Unix(1700000000, 0)},
+			Spec: v1.LogicalVolumeSpec{
+				NodeName:   "node1",
+				Size:       resource.MustParse("1Gi"),
+				Source:     source,
+				AccessType: accessType,
+			},
+			Status: v1.LogicalVolumeStatus{VolumeID: volumeID, Origin: origin},
+		}
+	}
+	deleting := lv("deleting", "s-deleting", "a", "ro", "v-a")
+	deleting.DeletionTimestamp = &metav1.Time{}
+	lvs := []v1.LogicalVolume{
+		lv("a", "v-a", "", "", ""),
+		lv("b", "v-b", "", "", ""),
+		lv("snap-a2", "s-a2", "a", "ro", "v-a"),
+		lv("snap-a1", "s-a1", "a", "ro", "v-a"),
+		lv("snap-b", "s-b", "b", "ro", "v-b"),
+		lv("orphan", "s-orphan", "deleted", "ro", "v-deleted"),
+		lv("clone", "v-clone", "a", "rw", "v-a"),
+		lv("pending", "", "a", "ro", ""),
+		deleting,
+	}
+	ids := func(res *csi.ListSnapshotsResponse) []string {
+		var ids []string
+		for _, e := range res.GetEntries() {
+			ids = append(ids, e.GetSnapshot().GetSnapshotId())
+		}
+		return ids
+	}
+
+	res, err := listSnapshotsPage(lvs, 0, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-a1", "s-a2", "s-b", "s-orphan"}) || res.GetNextToken() != "" {
+		t.Errorf("unexpected snapshots: %v, next token %q", got, res.GetNextToken())
+	}
+	for _, e := range res.GetEntries() {
+		snap := e.GetSnapshot()
+		if snap.GetSizeBytes() != 1<<30 || !snap.GetReadyToUse() || snap.GetCreationTime().GetSeconds() != 1700000000 {
+			t.Errorf("unexpected snapshot: %v", snap)
+		}
+	}
+	if source := res.GetEntries()[3].GetSnapshot().GetSourceVolumeId(); source != "v-deleted" {
+		t.Errorf("expected the origin to be the source of a snapshot of a deleted volume, got %q", source)
+	}
+
+	res, err = listSnapshotsPage(lvs, 0, "", "", "v-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-a1", "s-a2"}) {
+		t.Errorf("unexpected snapshots of v-a: %v", got)
+	}
+	res, err = listSnapshotsPage(lvs, 0, "", "s-b", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-b"}) || res.GetEntries()[0].GetSnapshot().GetSourceVolumeId() != "v-b" {
+		t.Errorf("unexpected snapshot s-b: %v", res.GetEntries())
+	}
+	for _, id := range []string{"v-clone", "unknown"} {
+		res, err = listSnapshotsPage(lvs, 0, "", id, "")
+		if err != nil || len(res.GetEntries()) != 0 {
+			t.Errorf("%s: expected no snapshot, got %v, %v", id, ids(res), err)
+		}
+	}
+
+	res, err = listSnapshotsPage(lvs, 2, "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-a1", "s-a2"}) || res.GetNextToken() != "s-b" {
+		t.Errorf("unexpected first page: %v, next token %q", got, res.GetNextToken())
+	}
+	res, err = listSnapshotsPage(lvs, 2, res.GetNextToken(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-b", "s-orphan"}) || res.GetNextToken() != "" {
+		t.Errorf("unexpected last page: %v, next token %q", got, res.GetNextToken())
+	}
+	res, err = listSnapshotsPage(lvs, 2, "s-a3", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(res); !reflect.DeepEqual(got, []string{"s-b", "s-orphan"}) {
+		t.Errorf("unexpected page after a deleted snapshot: %v", got)
+	}
+	if _, err := listSnapshotsPage(lvs, 0, "Invalid Token", "", ""); status.Code(err) != codes.Aborted {
+		t.Errorf("expected Aborted for an invalid token, got %v", err)
+	}
+}
+
+func Test_volumeConditionOf(t *testing.T) {
+	lv := &v1.LogicalVolume{}
+	if cond := volumeConditionOf(lv); cond.GetAbnormal() {
+		t.Errorf("expected a volume without conditions to be normal: %+v", cond)
+	}
+
+	lv.Status.Conditions = []metav1.Condition{
+		{Type: v1.ConditionVolumeHealthy, Status: metav1.ConditionTrue, Reason: v1.HealthReasonHealthy},
+	}
+	if cond := volumeConditionOf(lv); cond.GetAbnormal() {
+		t.Errorf("expected a recovered volume to be normal: %+v", cond)
+	}
+
+	lv.Status.Conditions[0] = metav1.Condition{
+		Type: v1.ConditionVolumeHealthy, Status: metav1.ConditionFalse, Reason: v1.HealthReasonThinPoolUnhealthy, Message: "out of data space",
+	}
+	if cond := volumeConditionOf(lv); !cond.GetAbnormal() || cond.GetMessage() != "out of data space" {
+		t.Errorf("expected an unhealthy volume to be abnormal: %+v", cond)
+	}
+
+	lv.Status.Conditions = append(lv.Status.Conditions, metav1.Condition{
+		Type: v1.ConditionNodeDeleted, Status: metav1.ConditionTrue, Reason: "Retained", Message: "retained",
+	})
+	if cond := volumeConditionOf(lv); !cond.GetAbnormal() || !strings.Contains(cond.GetMessage(), "node of the volume is deleted") {
+		t.Errorf("expected a volume of a deleted node to be abnormal: %+v", cond)
+	}
+}
+
+// newFakeControllerServer returns controllerServerNoLocked reading and writing the objects with a fake client,
+// which is returned as well.
+func newFakeControllerServer(t *testing.T, objects ...client.Object) (controllerServerNoLocked, client.Client) {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{v1.AddToScheme, corev1.AddToScheme, appsv1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&v1.LogicalVolume{}).
+		WithIndex(&v1.LogicalVolume{}, "status.volumeID", func(o client.Object) []string {
+			return []string{o.(*v1.LogicalVolume).Status.VolumeID}
+		}).Build()
+	return controllerServerNoLocked{
+		lvService:         k8s.NewLogicalVolumeServiceWithClient(c),
+		nodeService:       k8s.NewNodeService(c),
+		colocationService: k8s.NewColocationService(c),
+		pvcService:        k8s.NewPersistentVolumeClaimService(c),
+	}, c
+}
+
+func Test_colocatedNode(t *testing.T) {
+	node := func(name, capacity string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Annotations: map[string]string{topolvm.GetCapacityKeyPrefix() + "ssd": capacity},
+		}}
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "web"},
+		Spec: appsv1.StatefulSetSpec{VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+			{ObjectMeta: metav1.ObjectMeta{Name: "data"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "log"}},
+		}},
+	}
+	objects := []client.Object{
+		node("node1", "10737418240"),
+		node("node2", "1073741824"),
+		sts,
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data-web-0", UID: "data-0"}},
+		// the sibling is not bound yet, so its volume is guessed from the UID of the PVC.
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "log-web-0", UID: "log-0"}},
+		&v1.LogicalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-log-0"}, Spec: v1.LogicalVolumeSpec{NodeName: "node1"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data-web-1", UID: "data-1"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "log-web-1", UID: "log-1"},
+			Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-log-1"}},
+		&v1.LogicalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-log-1"}, Spec: v1.LogicalVolumeSpec{NodeName: "node2"}},
+		// the sibling of data-web-2 has no volume yet.
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "data-web-2", UID: "data-2"}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "log-web-2", UID: "log-2"}},
+	}
+	s, _ := newFakeControllerServer(t, objects...)
+
+	accessible := func(nodes ...string) *csi.TopologyRequirement {
+		requirements := new(csi.TopologyRequirement)
+		for _, node := range nodes {
+			requirements.Preferred = append(requirements.Preferred,
+				&csi.Topology{Segments: map[string]string{topolvm.GetTopologyNodeKey(): node}})
+		}
+		return requirements
+	}
+	testCases := []struct {
+		name         string
+		pvc          string
+		colocation   string
+		requirements *csi.TopologyRequirement
+		requestBytes int64
+		expectedNode string
+		expectedCode codes.Code
+	}{
+		{
+			name:         "sibling not bound",
+			pvc:          "data-web-0",
+			colocation:   colocationRequired,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 1 << 30,
+			expectedNode: "node1",
+		},
+		{
+			name:         "sibling bound",
+			pvc:          "data-web-1",
+			colocation:   colocationRequired,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 1 << 30,
+			expectedNode: "node2",
+		},
+		{
+			name:         "no sibling volume",
+			pvc:          "data-web-2",
+			colocation:   colocationRequired,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 1 << 30,
+			expectedNode: "",
+		},
+		{
+			name:         "unknown PVC",
+			colocation:   colocationRequired,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 1 << 30,
+			expectedNode: "",
+		},
+		{
+			name:         "not accessible, preferred",
+			pvc:          "data-web-0",
+			colocation:   colocationPreferred,
+			requirements: accessible("node2"),
+			requestBytes: 1 << 30,
+			expectedNode: "",
+		},
+		{
+			name:         "not accessible, required",
+			pvc:          "data-web-0",
+			colocation:   colocationRequired,
+			requirements: accessible("node2"),
+			requestBytes: 1 << 30,
+			expectedCode: codes.ResourceExhausted,
+		},
+		{
+			name:         "not enough capacity, preferred",
+			pvc:          "data-web-1",
+			colocation:   colocationPreferred,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 2 << 30,
+			expectedNode: "",
+		},
+		{
+			name:         "not enough capacity, required",
+			pvc:          "data-web-1",
+			colocation:   colocationRequired,
+			requirements: accessible("node1", "node2"),
+			requestBytes: 2 << 30,
+			expectedCode: codes.ResourceExhausted,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parameters := map[string]string{}
+			if tc.pvc != "" {
+				parameters[pvcNamespaceKey] = "ns"
+				parameters[pvcNameKey] = tc.pvc
+			}
+			req := &csi.CreateVolumeRequest{
+				Name:                      "pvc-new",
+				Parameters:                parameters,
+				AccessibilityRequirements: tc.requirements,
+			}
+			node, err := s.colocatedNode(context.Background(), req, tc.colocation, "ssd", tc.requestBytes)
+			if code := status.Code(err); code != tc.expectedCode {
+				t.Fatalf("expected code %s, got %s: %v", tc.expectedCode, code, err)
+			}
+			if node != tc.expectedNode {
+				t.Errorf("expected node %q, got %q", tc.expectedNode, node)
+			}
+		})
+	}
+}
+
+func Test_hasTopologyNode(t *testing.T) {
+	requirements := &csi.TopologyRequirement{
+		Requisite: []*csi.Topology{{Segments: map[string]string{topolvm.GetTopologyNodeKey(): "node1"}}},
+		Preferred: []*csi.Topology{{Segments: map[string]string{topolvm.GetTopologyNodeKey(): "node2"}}},
+	}
+	for node, expected := range map[string]bool{"node1": true, "node2": true, "node3": false} {
+		if actual := hasTopologyNode(requirements, node); actual != expected {
+			t.Errorf("%s: expected %v, got %v", node, expected, actual)
+		}
+	}
+	if hasTopologyNode(nil, "node1") {
+		t.Error("no node should be accessible without requirements")
+	}
+}
+
+func TestControllerExpandVolumeAllocationUnit(t *testing.T) {
+	currentSize := resource.MustParse("1Gi")
+	lv := func(name, source string) *v1.LogicalVolume {
+		return &v1.LogicalVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: v1.LogicalVolumeSpec{
+				NodeName:    "node1",
+				DeviceClass: "ssd",
+				Size:        currentSize,
+				Source:      source,
+				AccessType:  "rw",
+			},
+			Status: v1.LogicalVolumeStatus{VolumeID: "id-" + name, CurrentSize: &currentSize},
+		}
+	}
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:        "node1",
+		Annotations: map[string]string{topolvm.GetCapacityKeyPrefix() + "ssd": "10737418240"},
+	}}
+	s, c := newFakeControllerServer(t, node, lv("plain", ""), lv("restored", "snapshot"))
+	unit := Quantity(resource.MustParse("1Gi"))
+	s.settings.AllocationUnitSettings = AllocationUnitSettings{Default: &unit}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	// topolvm-node completes the expansion by reporting the size of the LogicalVolumes.
+	go func() {
+		for ctx.Err() == nil {
+			var lvs v1.LogicalVolumeList
+			if err := c.List(ctx, &lvs); err == nil {
+				for i := range lvs.Items {
+					lv := &lvs.Items[i]
+					if lv.Status.CurrentSize.Cmp(lv.Spec.Size) != 0 {
+						size := lv.Spec.Size.DeepCopy()
+						lv.Status.CurrentSize = &size
+						_ = c.Status().Update(ctx, lv)
+					}
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+
+	for _, name := range []string{"plain", "restored"} {
+		res, err := s.ControllerExpandVolume(ctx, &csi.ControllerExpandVolumeRequest{
+			VolumeId:      "id-" + name,
+			CapacityRange: &csi.CapacityRange{RequiredBytes: 1395864371}, // 1.3Gi
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if res.GetCapacityBytes() != 2<<30 {
+			t.Errorf("%s: expected the request to be rounded up to 2Gi, got %d", name, res.GetCapacityBytes())
+		}
+		var expanded v1.LogicalVolume
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, &expanded); err != nil {
+			t.Fatal(err)
+		}
+		if expanded.Spec.Size.Value() != 2<<30 {
+			t.Errorf("%s: unexpected size of LogicalVolume: %s", name, expanded.Spec.Size.String())
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/topolvm/topolvm/internal/tuning"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultLVMDMaxConcurrent is the default concurrency limit of lvmd on 4 CPUs or more.
+const DefaultLVMDMaxConcurrent = tuning.LVMDMaxConcurrent
+
+// DefaultMaxDeletionsPerNode is the default number of volumes of a node deleted at the same time.
+// It is MaxDeletionsPerNode of DefaultLVMDMaxConcurrent.
+const DefaultMaxDeletionsPerNode = DefaultLVMDMaxConcurrent / 2
+
+// MaxDeletionsPerNode returns the number of volumes of a node deleted at the same time for the max-concurrent
+// concurrency limit of lvmd. It is half of the limit, so that deleting many volumes of a node, e.g. on tearing down
+// a namespace, leaves lvmd room to create volumes for other workloads. A limit of zero, i.e. none, disables it too.
+func MaxDeletionsPerNode(lvmdMaxConcurrent int) int {
+	if lvmdMaxConcurrent <= 0 {
+		return 0
+	}
+	if lvmdMaxConcurrent < 2 {
+		return 1
+	}
+	return lvmdMaxConcurrent / 2
+}
+
+// DeletionPacingSettings limit the deletions of volumes and snapshots by DeleteVolume and DeleteSnapshot.
+// The deletions over the limits wait in a queue until their deadline, and the CSI sidecar retries them.
+type DeletionPacingSettings struct {
+	// MaxPerNode is the number of volumes of a node deleted at the same time. Zero disables the limit.
+	MaxPerNode int
+	// MaxTotal is the number of volumes deleted at the same time across the nodes. Zero disables the limit.
+	MaxTotal int
+	// Interval is the minimum time between starting two deletions on a node.
+	Interval time.Duration
+}
+
+// Validate checks the settings.
+func (s DeletionPacingSettings) Validate() error {
+	if s.MaxPerNode < 0 {
+		return fmt.Errorf("the maximum deletions per node must not be negative: %d", s.MaxPerNode)
+	}
+	if s.MaxTotal < 0 {
+		return fmt.Errorf("the maximum deletions must not be negative: %d", s.MaxTotal)
+	}
+	if s.Interval < 0 {
+		return fmt.Errorf("the deletion interval must not be negative: %s", s.Interval)
+	}
+	return nil
+}
+
+// deletionPacer paces the deletions of the volumes by their node.
+type deletionPacer struct {
+	settings DeletionPacingSettings
+	// total is nil if the deletions across the nodes are not limited.
+	total chan struct{}
+
+	mu    sync.Mutex
+	nodes map[string]*nodeDeletions
+}
+
+type nodeDeletions struct {
+	// slots is nil if the deletions of the node are not limited.
+	slots chan struct{}
+	// next is the earliest time to start the next deletion of the node.
+	next time.Time
+	// users is the number of the deletions queued or running, to forget the nodes without deletions.
+	users int
+}
+
+func newDeletionPacer(settings DeletionPacingSettings) *deletionPacer {
+	p := &deletionPacer{
+		settings: settings,
+		nodes:    make(map[string]*nodeDeletions),
+	}
+	if settings.MaxTotal > 0 {
+		p.total = make(chan struct{}, settings.MaxTotal)
+	}
+	return p
+}
+
+func (p *deletionPacer) node(nodeName string) *nodeDeletions {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, ok := p.nodes[nodeName]
+	if !ok {
+		n = &nodeDeletions{}
+		if p.settings.MaxPerNode > 0 {
+			n.slots = make(chan struct{}, p.settings.MaxPerNode)
+		}
+		p.nodes[nodeName] = n
+	}
+	n.users++
+	return n
+}
+
+// done ends a deletion of the node, and forgets the nodes without deletions
+// once their next deletion may start at once.
+func (p *deletionPacer) done(n *nodeDeletions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.users--
+	now := time.Now()
+	for name, other := range p.nodes {
+		if other.users == 0 && !other.next.After(now) {
+			delete(p.nodes, name)
+		}
+	}
+}
+
+// reserveStart returns how long to wait before starting a deletion of the node, and reserves the start time.
+func (p *deletionPacer) reserveStart(n *nodeDeletions) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if n.next.Before(now) {
+		n.next = now
+	}
+	wait := n.next.Sub(now)
+	n.next = n.next.Add(p.settings.Interval)
+	return wait
+}
+
+// acquire waits until a volume of the node may be deleted, and returns the function to call once it is deleted.
+// The slot of the node is taken before the one across the nodes, so that the deletions queued for a busy node
+// do not hold up the deletions of the others.
+func (p *deletionPacer) acquire(ctx context.Context, nodeName string) (func(), error) {
+	n := p.node(nodeName)
+	volumeDeletionsQueued.WithLabelValues(nodeName).Inc()
+	defer volumeDeletionsQueued.WithLabelValues(nodeName).Dec()
+
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+		p.done(n)
+	}
+	for _, slots := range []chan struct{}{n.slots, p.total} {
+		if slots == nil {
+			continue
+		}
+		slots := slots
+		select {
+		case slots <- struct{}{}:
+			releases = append(releases, func() { <-slots })
+		case <-ctx.Done():
+			release()
+			return nil, deletionQueueError(ctx.Err(), nodeName)
+		}
+	}
+
+	if wait := p.reserveStart(n); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			release()
+			return nil, deletionQueueError(ctx.Err(), nodeName)
+		}
+	}
+	return release, nil
+}
+
+func deletionQueueError(err error, nodeName string) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "timed out waiting in the deletion queue of node %s", nodeName)
+	}
+	return status.Errorf(codes.Canceled, "canceled waiting in the deletion queue of node %s", nodeName)
+}
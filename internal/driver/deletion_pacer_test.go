@@ -0,0 +1,139 @@
+package driver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeletionPacerLimitsNode(t *testing.T) {
+	p := newDeletionPacer(DeletionPacingSettings{MaxPerNode: 1, MaxTotal: 2})
+	ctx := context.Background()
+
+	release, err := p.acquire(ctx, "node-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		r, err := p.acquire(ctx, "node-a")
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- r
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("a second volume of the node is deleted at the same time")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if queued := testutil.ToFloat64(volumeDeletionsQueued.WithLabelValues("node-a")); queued != 1 {
+		t.Errorf("expected 1 deletion queued, got %v", queued)
+	}
+
+	// the deletions of the other nodes are not held up by the queue of node-a.
+	releaseB, err := p.acquire(ctx, "node-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	releaseB()
+
+	release()
+	select {
+	case r := <-acquired:
+		r()
+	case <-time.After(time.Second):
+		t.Fatal("the queued deletion is not started")
+	}
+	if queued := testutil.ToFloat64(volumeDeletionsQueued.WithLabelValues("node-a")); queued != 0 {
+		t.Errorf("expected no deletion queued, got %v", queued)
+	}
+	if len(p.nodes) != 0 {
+		t.Errorf("expected the nodes without deletions to be forgotten, got %d", len(p.nodes))
+	}
+}
+
+func TestDeletionPacerLimitsTotal(t *testing.T) {
+	p := newDeletionPacer(DeletionPacingSettings{MaxPerNode: 2, MaxTotal: 1})
+
+	release, err := p.acquire(context.Background(), "node-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = p.acquire(ctx, "node-b")
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestDeletionPacerInterval(t *testing.T) {
+	p := newDeletionPacer(DeletionPacingSettings{Interval: 200 * time.Millisecond})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		release, err := p.acquire(ctx, "node-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond {
+		t.Errorf("expected the deletions to start 200ms apart, but they took %s", elapsed)
+	}
+
+	// the interval applies to each node.
+	start = time.Now()
+	release, err := p.acquire(ctx, "node-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if elapsed := time.Since(start); elapsed >= 200*time.Millisecond {
+		t.Errorf("expected the deletion of another node to start at once, but it took %s", elapsed)
+	}
+}
+
+func TestDeletionPacingSettingsValidate(t *testing.T) {
+	for _, s := range []DeletionPacingSettings{
+		{MaxPerNode: -1},
+		{MaxTotal: -1},
+		{Interval: -time.Second},
+	} {
+		if err := s.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", s)
+		}
+	}
+	if err := (DeletionPacingSettings{MaxPerNode: DefaultMaxDeletionsPerNode}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMaxDeletionsPerNode(t *testing.T) {
+	testCases := []struct {
+		lvmdMaxConcurrent int
+		expected          int
+	}{
+		{lvmdMaxConcurrent: 0, expected: 0},
+		{lvmdMaxConcurrent: 1, expected: 1},
+		{lvmdMaxConcurrent: 3, expected: 1},
+		{lvmdMaxConcurrent: 8, expected: 4},
+	}
+	for _, tc := range testCases {
+		if n := MaxDeletionsPerNode(tc.lvmdMaxConcurrent); n != tc.expected {
+			t.Errorf("expected %d deletions per node for max-concurrent %d, but got %d", tc.expected, tc.lvmdMaxConcurrent, n)
+		}
+	}
+	if n := MaxDeletionsPerNode(DefaultLVMDMaxConcurrent); n != DefaultMaxDeletionsPerNode {
+		t.Errorf("expected %d deletions per node for the default of lvmd, but got %d", DefaultMaxDeletionsPerNode, n)
+	}
+}
@@ -0,0 +1,130 @@
+package driver
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/filesystem"
+)
+
+const (
+	// These parameters are added by external-provisioner with --extra-create-metadata.
+	pvcNameKey      = "csi.storage.k8s.io/pvc/name"
+	pvcNamespaceKey = "csi.storage.k8s.io/pvc/namespace"
+
+	fsUUIDDeterministic = "deterministic"
+)
+
+// filesystemType returns the filesystem type of the volume requested by capabilities.
+// It returns an empty string for block volumes.
+func filesystemType(capabilities []*csi.VolumeCapability) string {
+	for _, capability := range capabilities {
+		if mount := capability.GetMount(); mount != nil {
+			if mount.GetFsType() == "" {
+				return "ext4"
+			}
+			return mount.GetFsType()
+		}
+	}
+	return ""
+}
+
+// filesystemLabel returns the label of the filesystem specified in the parameters of CreateVolume.
+// The PVC name and namespace are expanded, and the label is truncated to the maximum length of fsType.
+func filesystemLabel(parameters map[string]string, fsType string) (string, error) {
+	label := parameters[topolvm.GetFilesystemLabelKey()]
+	if label == "" || fsType == "" {
+		return "", nil
+	}
+	for _, v := range []struct{ placeholder, key string }{
+		{"${pvc.name}", pvcNameKey},
+		{"${pvc.namespace}", pvcNamespaceKey},
+	} {
+		if !strings.Contains(label, v.placeholder) {
+			continue
+		}
+		value := parameters[v.key]
+		if value == "" {
+			return "", fmt.Errorf("%s is used in %s, but %s is not given; run external-provisioner with --extra-create-metadata",
+				v.placeholder, topolvm.GetFilesystemLabelKey(), v.key)
+		}
+		label = strings.ReplaceAll(label, v.placeholder, value)
+	}
+
+	max := filesystem.MaxLabelLength(fsType)
+	if max == 0 {
+		return "", fmt.Errorf("setting the label of %s is not supported", fsType)
+	}
+	if len(label) > max {
+		label = label[:max]
+	}
+	return label, nil
+}
+
+// useDeterministicUUID reports whether the UUID of the filesystem is derived from the volume ID
+// as specified in the parameters of CreateVolume. Otherwise mkfs generates a random UUID.
+func useDeterministicUUID(parameters map[string]string, fsType string) (bool, error) {
+	switch v := parameters[topolvm.GetFilesystemUUIDKey()]; v {
+	case "":
+		return false, nil
+	case fsUUIDDeterministic:
+		if fsType == "" {
+			return false, nil
+		}
+		if filesystem.MaxLabelLength(fsType) == 0 {
+			return false, fmt.Errorf("setting the UUID of %s is not supported", fsType)
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %s: %s", topolvm.GetFilesystemUUIDKey(), v)
+	}
+}
+
+// mkfsOptions returns the options of mkfs specified in the parameters of CreateVolume for fsType,
+// separated by a whitespace. The options for fsType take precedence over the ones for any filesystem type.
+func mkfsOptions(parameters map[string]string, fsType string) (string, error) {
+	if fsType == "" {
+		return "", nil
+	}
+	key := topolvm.GetFilesystemMkfsOptionsKey(fsType)
+	value, ok := parameters[key]
+	if !ok {
+		key = topolvm.GetMkfsOptionsKey()
+		value = parameters[key]
+	}
+	options := strings.Fields(value)
+	if err := filesystem.ValidateMkfsOptions(options); err != nil {
+		return "", fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return strings.Join(options, " "), nil
+}
+
+// useProjectQuota reports whether the filesystem is mounted with project quota as specified in the parameters of CreateVolume.
+// Only xfs is supported, which needs no mkfs option since it keeps the project quota accounting once mounted with prjquota.
+func useProjectQuota(parameters map[string]string, fsType string) (bool, error) {
+	switch v := parameters[topolvm.GetProjectQuotaKey()]; v {
+	case "", "false":
+		return false, nil
+	case "true":
+		if fsType == "" {
+			return false, nil
+		}
+		if fsType != "xfs" {
+			return false, fmt.Errorf("project quota of %s is not supported, use xfs", fsType)
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %s: %s", topolvm.GetProjectQuotaKey(), v)
+	}
+}
+
+// deterministicUUID returns a name-based UUID (version 5) derived from the volume ID.
+func deterministicUUID(volumeID string) string {
+	sum := sha1.Sum([]byte(volumeID))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,161 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+)
+
+func TestFilesystemType(t *testing.T) {
+	mount := func(fsType string) *csi.VolumeCapability {
+		return &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Mount{Mount: &csi.VolumeCapability_MountVolume{FsType: fsType}}}
+	}
+	block := &csi.VolumeCapability{AccessType: &csi.VolumeCapability_Block{Block: &csi.VolumeCapability_BlockVolume{}}}
+
+	if fsType := filesystemType([]*csi.VolumeCapability{mount("xfs")}); fsType != "xfs" {
+		t.Errorf("unexpected filesystem type: %s", fsType)
+	}
+	if fsType := filesystemType([]*csi.VolumeCapability{mount("")}); fsType != "ext4" {
+		t.Errorf("ext4 should be the default filesystem type: %s", fsType)
+	}
+	if fsType := filesystemType([]*csi.VolumeCapability{block}); fsType != "" {
+		t.Errorf("block volumes should not have a filesystem type: %s", fsType)
+	}
+}
+
+func TestFilesystemLabel(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters map[string]string
+		fsType     string
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "no label",
+			parameters: map[string]string{},
+			fsType:     "ext4",
+		},
+		{
+			name: "expand PVC name and namespace",
+			parameters: map[string]string{
+				topolvm.GetFilesystemLabelKey(): "${pvc.namespace}-${pvc.name}",
+				pvcNameKey:                      "data",
+				pvcNamespaceKey:                 "db",
+			},
+			fsType:   "ext4",
+			expected: "db-data",
+		},
+		{
+			name: "truncate to the maximum length",
+			parameters: map[string]string{
+				topolvm.GetFilesystemLabelKey(): "${pvc.name}",
+				pvcNameKey:                      "data-postgres-0",
+			},
+			fsType:   "xfs",
+			expected: "data-postgre",
+		},
+		{
+			name:       "no PVC metadata",
+			parameters: map[string]string{topolvm.GetFilesystemLabelKey(): "${pvc.name}"},
+			fsType:     "ext4",
+			wantErr:    true,
+		},
+		{
+			name:       "block volume",
+			parameters: map[string]string{topolvm.GetFilesystemLabelKey(): "data"},
+		},
+		{
+			name:       "unsupported filesystem",
+			parameters: map[string]string{topolvm.GetFilesystemLabelKey(): "data"},
+			fsType:     "vfat",
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			label, err := filesystemLabel(tc.parameters, tc.fsType)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if label != tc.expected {
+				t.Errorf("unexpected label: expected=%s, actual=%s", tc.expected, label)
+			}
+		})
+	}
+}
+
+func TestUseDeterministicUUID(t *testing.T) {
+	if ok, err := useDeterministicUUID(map[string]string{}, "ext4"); err != nil || ok {
+		t.Errorf("unexpected result without parameter: %v, %v", ok, err)
+	}
+	params := map[string]string{topolvm.GetFilesystemUUIDKey(): "deterministic"}
+	if ok, err := useDeterministicUUID(params, "xfs"); err != nil || !ok {
+		t.Errorf("unexpected result: %v, %v", ok, err)
+	}
+	if _, err := useDeterministicUUID(params, "vfat"); err == nil {
+		t.Error("expected error for unsupported filesystem")
+	}
+	if _, err := useDeterministicUUID(map[string]string{topolvm.GetFilesystemUUIDKey(): "random"}, "ext4"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+
+	uuid := deterministicUUID("a7c4b7f2-3d6e-4e0b-9a53-0c1f4e8b2d91")
+	if uuid != deterministicUUID("a7c4b7f2-3d6e-4e0b-9a53-0c1f4e8b2d91") {
+		t.Error("UUID should be deterministic")
+	}
+	if uuid == deterministicUUID("5d2b3f0a-8c41-4a77-b1e6-2f9d0c7a4e13") {
+		t.Error("UUIDs of different volumes should differ")
+	}
+	if len(uuid) != 36 || uuid[14] != '5' {
+		t.Errorf("invalid version 5 UUID: %s", uuid)
+	}
+}
+
+func TestUseProjectQuota(t *testing.T) {
+	params := map[string]string{topolvm.GetProjectQuotaKey(): "true"}
+	if ok, err := useProjectQuota(params, "xfs"); err != nil || !ok {
+		t.Errorf("unexpected result: %v, %v", ok, err)
+	}
+	if ok, err := useProjectQuota(params, ""); err != nil || ok {
+		t.Errorf("block volumes should ignore the parameter: %v, %v", ok, err)
+	}
+	if ok, err := useProjectQuota(map[string]string{}, "xfs"); err != nil || ok {
+		t.Errorf("unexpected result without parameter: %v, %v", ok, err)
+	}
+	if _, err := useProjectQuota(params, "ext4"); err == nil {
+		t.Error("expected error for unsupported filesystem")
+	}
+	if _, err := useProjectQuota(map[string]string{topolvm.GetProjectQuotaKey(): "yes"}, "xfs"); err == nil {
+		t.Error("expected error for invalid value")
+	}
+}
+
+func TestMkfsOptions(t *testing.T) {
+	params := map[string]string{
+		topolvm.GetMkfsOptionsKey():                "-i  size=512",
+		topolvm.GetFilesystemMkfsOptionsKey("xfs"): "-m reflink=1",
+	}
+	if options, err := mkfsOptions(params, "xfs"); err != nil || options != "-m reflink=1" {
+		t.Errorf("the options for the filesystem type should take precedence: %q, %v", options, err)
+	}
+	if options, err := mkfsOptions(params, "ext4"); err != nil || options != "-i size=512" {
+		t.Errorf("unexpected options: %q, %v", options, err)
+	}
+	if options, err := mkfsOptions(params, ""); err != nil || options != "" {
+		t.Errorf("block volumes should ignore the parameters: %q, %v", options, err)
+	}
+	if options, err := mkfsOptions(map[string]string{}, "xfs"); err != nil || options != "" {
+		t.Errorf("unexpected options without parameter: %q, %v", options, err)
+	}
+	if _, err := mkfsOptions(map[string]string{topolvm.GetMkfsOptionsKey(): "-L data"}, "ext4"); err == nil {
+		t.Error("expected error for the label option")
+	}
+}
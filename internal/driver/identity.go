@@ -20,19 +20,20 @@ var idLogger = ctrl.Log.WithName("driver").WithName("identity")
 // If the plugin is not yet ready, it should return (false, nil).
 // Otherwise, return (true, nil).
 func NewIdentityServer(ready func() (bool, error)) csi.IdentityServer {
-	return &identityServer{ready: ready}
+	return &identityServer{name: topolvm.GetPluginName(), ready: ready}
 }
 
 type identityServer struct {
 	csi.UnimplementedIdentityServer
 
+	name  string
 	ready func() (bool, error)
 }
 
 func (s identityServer) GetPluginInfo(ctx context.Context, req *csi.GetPluginInfoRequest) (*csi.GetPluginInfoResponse, error) {
 	idLogger.V(2).Info("GetPluginInfo", "req", req.String())
 	return &csi.GetPluginInfoResponse{
-		Name:          topolvm.GetPluginName(),
+		Name:          s.name,
 		VendorVersion: topolvm.Version,
 	}, nil
 }
@@ -0,0 +1,50 @@
+package driver
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// ServerKind identifies the CSI gRPC server of TopoLVM.
+type ServerKind string
+
+const (
+	// ServerKindController is the CSI gRPC server of topolvm-controller.
+	ServerKindController = ServerKind("controller")
+	// ServerKindNode is the CSI gRPC server of topolvm-node.
+	ServerKindNode = ServerKind("node")
+)
+
+var (
+	interceptorsMu sync.Mutex
+	interceptors   = map[ServerKind][]grpc.UnaryServerInterceptor{}
+)
+
+// RegisterUnaryInterceptor registers a gRPC interceptor for the CSI server of the given kind.
+// It is meant to be called from init() of a package linked into the binary, so that distributions
+// can enforce their own policies, mutate requests or audit calls without patching the driver.
+// The interceptors are called in the order of registration after the built-in interceptors of the server.
+func RegisterUnaryInterceptor(kind ServerKind, interceptor grpc.UnaryServerInterceptor) {
+	interceptorsMu.Lock()
+	defer interceptorsMu.Unlock()
+	interceptors[kind] = append(interceptors[kind], interceptor)
+}
+
+// ServerOptions returns the options to create the CSI gRPC server of the given kind
+// with the built-in interceptors followed by the registered ones.
+func ServerOptions(kind ServerKind, builtin ...grpc.UnaryServerInterceptor) []grpc.ServerOption {
+	chain := unaryInterceptors(kind, builtin...)
+	if len(chain) == 0 {
+		return nil
+	}
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(chain...)}
+}
+
+func unaryInterceptors(kind ServerKind, builtin ...grpc.UnaryServerInterceptor) []grpc.UnaryServerInterceptor {
+	interceptorsMu.Lock()
+	defer interceptorsMu.Unlock()
+	chain := make([]grpc.UnaryServerInterceptor, 0, len(builtin)+len(interceptors[kind]))
+	chain = append(chain, builtin...)
+	return append(chain, interceptors[kind]...)
+}
@@ -0,0 +1,69 @@
+package driver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptors(t *testing.T) {
+	defer func() {
+		interceptors = map[ServerKind][]grpc.UnaryServerInterceptor{}
+	}()
+
+	var called []string
+	recorder := func(name string) grpc.UnaryServerInterceptor {
+		return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			called = append(called, name)
+			return handler(ctx, req)
+		}
+	}
+	RegisterUnaryInterceptor(ServerKindController, recorder("policy"))
+	RegisterUnaryInterceptor(ServerKindController, func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if req == "forbidden" {
+			return nil, status.Error(codes.PermissionDenied, "denied by policy")
+		}
+		return handler(ctx, req.(string)+"-mutated")
+	})
+	RegisterUnaryInterceptor(ServerKindNode, recorder("node"))
+
+	if chain := unaryInterceptors(ServerKindNode); len(chain) != 1 {
+		t.Errorf("unexpected number of node interceptors: %d", len(chain))
+	}
+	if opts := ServerOptions(ServerKind("unknown")); opts != nil {
+		t.Errorf("no options are expected without interceptors: %v", opts)
+	}
+
+	chain := unaryInterceptors(ServerKindController, recorder("builtin"))
+	invoke := func(req any) (any, error) {
+		handler := func(ctx context.Context, req any) (any, error) {
+			return req, nil
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			interceptor, next := chain[i], handler
+			handler = func(ctx context.Context, req any) (any, error) {
+				return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/csi.v1.Controller/CreateVolume"}, next)
+			}
+		}
+		return handler(context.Background(), req)
+	}
+
+	resp, err := invoke("request")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp != "request-mutated" {
+		t.Errorf("request should be mutated: %v", resp)
+	}
+	if !reflect.DeepEqual(called, []string{"builtin", "policy"}) {
+		t.Errorf("unexpected order: %v", called)
+	}
+
+	if _, err := invoke("forbidden"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("request should be denied: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package k8s
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// annSelectedNode is the annotation of PVCs whose binding waits for the first consumer.
+const annSelectedNode = "volume.kubernetes.io/selected-node"
+
+// ColocationService finds the sibling PVCs of a PVC, i.e. the PVCs mounted by the same pod,
+// so that their volumes can be provisioned on the same node.
+type ColocationService struct {
+	reader client.Reader
+}
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+//+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch
+
+// NewColocationService returns ColocationService.
+func NewColocationService(r client.Reader) *ColocationService {
+	return &ColocationService{reader: r}
+}
+
+// GetSiblingVolumeNames returns the names of the volumes of the sibling PVCs of the PVC, sorted by the PVC names.
+// The names of the volumes not provisioned yet are the ones the external-provisioner will request.
+//
+// The siblings are the PVCs having the same colocation group label, and the PVCs of the other
+// volumeClaimTemplates of the same StatefulSet pod. Nothing is returned for PVCs with a selected node,
+// because their volumes are provisioned on the node of their pod anyway.
+func (s ColocationService) GetSiblingVolumeNames(ctx context.Context, namespace, name string) ([]string, error) {
+	pvc := new(corev1.PersistentVolumeClaim)
+	if err := s.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if _, ok := pvc.Annotations[annSelectedNode]; ok {
+		return nil, nil
+	}
+
+	siblings := make(map[string]bool)
+	if group, ok := pvc.Labels[topolvm.GetColocationGroupKey()]; ok {
+		var pvcs corev1.PersistentVolumeClaimList
+		err := s.reader.List(ctx, &pvcs, client.InNamespace(namespace), client.MatchingLabels{topolvm.GetColocationGroupKey(): group})
+		if err != nil {
+			return nil, err
+		}
+		for _, sibling := range pvcs.Items {
+			siblings[sibling.Name] = true
+		}
+	}
+
+	var stss appsv1.StatefulSetList
+	if err := s.reader.List(ctx, &stss, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	for _, sts := range stss.Items {
+		for _, sibling := range statefulSetSiblings(&sts, name) {
+			siblings[sibling] = true
+		}
+	}
+	delete(siblings, name)
+
+	names := make([]string, 0, len(siblings))
+	for sibling := range siblings {
+		names = append(names, sibling)
+	}
+	sort.Strings(names)
+
+	var volumes []string
+	for _, sibling := range names {
+		pvc := new(corev1.PersistentVolumeClaim)
+		err := s.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: sibling}, pvc)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if pvc.Spec.VolumeName != "" {
+			volumes = append(volumes, pvc.Spec.VolumeName)
+		} else {
+			// the external-provisioner names the volume after the UID of the PVC.
+			volumes = append(volumes, "pvc-"+string(pvc.UID))
+		}
+	}
+	return volumes, nil
+}
+
+// statefulSetSiblings returns the names of the PVCs of the other volumeClaimTemplates of the pod using the PVC.
+// The PVCs of a StatefulSet are named <template>-<statefulset>-<ordinal>.
+func statefulSetSiblings(sts *appsv1.StatefulSet, pvcName string) []string {
+	for _, template := range sts.Spec.VolumeClaimTemplates {
+		pod, ok := strings.CutPrefix(pvcName, template.Name+"-")
+		if !ok || !isStatefulSetPod(sts.Name, pod) {
+			continue
+		}
+		var siblings []string
+		for _, other := range sts.Spec.VolumeClaimTemplates {
+			if other.Name != template.Name {
+				siblings = append(siblings, other.Name+"-"+pod)
+			}
+		}
+		return siblings
+	}
+	return nil
+}
+
+func isStatefulSetPod(stsName, pod string) bool {
+	ordinal, ok := strings.CutPrefix(pod, stsName+"-")
+	if !ok || ordinal == "" {
+		return false
+	}
+	for _, c := range ordinal {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
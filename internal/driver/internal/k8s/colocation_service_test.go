@@ -0,0 +1,190 @@
+package k8s
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testStatefulSet(name string, templates ...string) *appsv1.StatefulSet {
+	sts := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}}
+	for _, template := range templates {
+		sts.Spec.VolumeClaimTemplates = append(sts.Spec.VolumeClaimTemplates,
+			corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: template}})
+	}
+	return sts
+}
+
+func TestIsStatefulSetPod(t *testing.T) {
+	testCases := []struct {
+		pod      string
+		expected bool
+	}{
+		{pod: "web-0", expected: true},
+		{pod: "web-12", expected: true},
+		{pod: "web-", expected: false},
+		{pod: "web", expected: false},
+		{pod: "web-a", expected: false},
+		{pod: "web-1a", expected: false},
+		{pod: "web-db-0", expected: false},
+		{pod: "db-0", expected: false},
+	}
+	for _, tc := range testCases {
+		if actual := isStatefulSetPod("web", tc.pod); actual != tc.expected {
+			t.Errorf("%s: expected %v, actual %v", tc.pod, tc.expected, actual)
+		}
+	}
+}
+
+func TestStatefulSetSiblings(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sts      *appsv1.StatefulSet
+		pvcName  string
+		expected []string
+	}{
+		{
+			name:     "other templates",
+			sts:      testStatefulSet("web", "data", "log", "cache"),
+			pvcName:  "data-web-0",
+			expected: []string{"log-web-0", "cache-web-0"},
+		},
+		{
+			name:     "single template",
+			sts:      testStatefulSet("web", "data"),
+			pvcName:  "data-web-0",
+			expected: nil,
+		},
+		{
+			name:     "template name containing a dash",
+			sts:      testStatefulSet("web", "data-ssd", "log"),
+			pvcName:  "data-ssd-web-3",
+			expected: []string{"log-web-3"},
+		},
+		{
+			name:     "other StatefulSet",
+			sts:      testStatefulSet("db", "data", "log"),
+			pvcName:  "data-web-0",
+			expected: nil,
+		},
+		{
+			name:     "StatefulSet name prefixing another",
+			sts:      testStatefulSet("web", "data", "log"),
+			pvcName:  "data-web-db-0",
+			expected: nil,
+		},
+		{
+			name:     "not a PVC of a template",
+			sts:      testStatefulSet("web", "data", "log"),
+			pvcName:  "manual",
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := statefulSetSiblings(tc.sts, tc.pvcName)
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestGetSiblingVolumeNames(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, appsv1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pvc := func(name, volumeName string, labels, annotations map[string]string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "ns",
+				Name:        name,
+				UID:         types.UID("uid-" + name),
+				Labels:      labels,
+				Annotations: annotations,
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		}
+	}
+	group := map[string]string{topolvm.GetColocationGroupKey(): "group1"}
+	otherGroup := map[string]string{topolvm.GetColocationGroupKey(): "group2"}
+	selected := map[string]string{annSelectedNode: "node1"}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		testStatefulSet("web", "data", "log", "cache"),
+		// log-web-0 is bound, cache-web-0 is not provisioned yet and the PVC of a third template is missing.
+		pvc("data-web-0", "", nil, nil),
+		pvc("log-web-0", "pvc-log", nil, nil),
+		pvc("cache-web-0", "", nil, nil),
+		pvc("data-web-1", "", nil, selected),
+		// the PVCs of a colocation group.
+		pvc("a", "", group, nil),
+		pvc("b", "pvc-b", group, nil),
+		pvc("c", "", otherGroup, nil),
+		// the member of a colocation group and of a StatefulSet.
+		pvc("data-db-0", "", group, nil),
+		testStatefulSet("db", "data", "wal"),
+		pvc("wal-db-0", "pvc-wal", nil, nil),
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "log-web-0"}},
+	).Build()
+	s := NewColocationService(c)
+
+	testCases := []struct {
+		name     string
+		pvcName  string
+		expected []string
+	}{
+		{
+			name:     "StatefulSet",
+			pvcName:  "data-web-0",
+			expected: []string{"pvc-uid-cache-web-0", "pvc-log"},
+		},
+		{
+			name:     "selected node",
+			pvcName:  "data-web-1",
+			expected: nil,
+		},
+		{
+			name:     "colocation group",
+			pvcName:  "a",
+			expected: []string{"pvc-b", "pvc-uid-data-db-0"},
+		},
+		{
+			name:     "colocation group and StatefulSet",
+			pvcName:  "data-db-0",
+			expected: []string{"pvc-uid-a", "pvc-b", "pvc-wal"},
+		},
+		{
+			name:     "no siblings",
+			pvcName:  "c",
+			expected: nil,
+		},
+		{
+			name:     "missing PVC",
+			pvcName:  "missing",
+			expected: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := s.GetSiblingVolumeNames(context.Background(), "ns", tc.pvcName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected %v, actual %v", tc.expected, actual)
+			}
+		})
+	}
+}
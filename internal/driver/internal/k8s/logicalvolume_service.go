@@ -17,6 +17,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
@@ -33,6 +34,7 @@ type LogicalVolumeService struct {
 	}
 	getter       getter.Interface
 	volumeGetter *volumeGetter
+	cache        cache.Cache
 }
 
 const (
@@ -119,6 +121,15 @@ func (v *volumeGetter) Get(ctx context.Context, volumeID string) (*topolvmv1.Log
 	return foundLv, nil
 }
 
+// List returns all the LogicalVolumes from the cache.
+func (v *volumeGetter) List(ctx context.Context) ([]topolvmv1.LogicalVolume, error) {
+	lvList := new(topolvmv1.LogicalVolumeList)
+	if err := v.cacheReader.List(ctx, lvList); err != nil {
+		return nil, err
+	}
+	return lvList.Items, nil
+}
+
 //+kubebuilder:rbac:groups=topolvm.io,resources=logicalvolumes,verbs=get;list;watch;create;delete
 //+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 
@@ -147,11 +158,34 @@ func NewLogicalVolumeService(mgr manager.Manager) (*LogicalVolumeService, error)
 		writer:       client,
 		getter:       newRetryMissingGetter(client, apiReader),
 		volumeGetter: &volumeGetter{cacheReader: client, apiReader: apiReader},
+		cache:        mgr.GetCache(),
 	}, nil
 }
 
+// NewLogicalVolumeServiceWithClient returns LogicalVolumeService reading LogicalVolumes directly with c
+// rather than from the cache of a manager, e.g. for tests with a fake client. It does not support WarmUp.
+// GetVolume requires c to index LogicalVolumes by status.volumeID like the cache of NewLogicalVolumeService.
+func NewLogicalVolumeServiceWithClient(c client.Client) *LogicalVolumeService {
+	wrapped := clientwrapper.NewWrappedClient(c)
+	return &LogicalVolumeService{
+		writer:       wrapped,
+		getter:       newRetryMissingGetter(wrapped, wrapped),
+		volumeGetter: &volumeGetter{cacheReader: wrapped, apiReader: wrapped},
+	}
+}
+
+// WarmUp waits for the cache to be synced and lists LogicalVolumes by the volumeID index once,
+// so that GetVolume is served from the cache without falling back to listing via the API server.
+func (s *LogicalVolumeService) WarmUp(ctx context.Context) error {
+	if !s.cache.WaitForCacheSync(ctx) {
+		return errors.New("failed to sync the cache")
+	}
+	lvList := new(topolvmv1.LogicalVolumeList)
+	return s.volumeGetter.cacheReader.List(ctx, lvList, client.MatchingFields{indexFieldVolumeID: ""})
+}
+
 // CreateVolume creates volume
-func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, oc, name, sourceName string, requestBytes int64) (string, error) {
+func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, oc string, lvcreateOptions []string, provisioningType, wipeOnDelete, snapshotBeforeExpand, name, sourceName string, requestBytes int64) (string, error) {
 	logger.Info("k8s.CreateVolume called", "name", name, "node", node, "size", requestBytes, "sourceName", sourceName)
 	var lv *topolvmv1.LogicalVolume
 	// if the create volume request has no source, proceed with regular lv creation.
@@ -161,28 +195,35 @@ func (s *LogicalVolumeService) CreateVolume(ctx context.Context, node, dc, oc, n
 				Name: name,
 			},
 			Spec: topolvmv1.LogicalVolumeSpec{
-				Name:                name,
-				NodeName:            node,
-				DeviceClass:         dc,
-				LvcreateOptionClass: oc,
-				Size:                *resource.NewQuantity(requestBytes, resource.BinarySI),
+				Name:                 name,
+				NodeName:             node,
+				DeviceClass:          dc,
+				LvcreateOptionClass:  oc,
+				LvcreateOptions:      lvcreateOptions,
+				Size:                 *resource.NewQuantity(requestBytes, resource.BinarySI),
+				ProvisioningType:     provisioningType,
+				WipeOnDelete:         wipeOnDelete,
+				SnapshotBeforeExpand: snapshotBeforeExpand,
 			},
 		}
 
 	} else {
-		// On the other hand, if a volume has a datasource, create a thin snapshot of the source volume with READ-WRITE access.
+		// On the other hand, if a volume has a datasource, create a snapshot of the source volume with READ-WRITE access.
 		lv = &topolvmv1.LogicalVolume{
 			ObjectMeta: metav1.ObjectMeta{
 				Name: name,
 			},
 			Spec: topolvmv1.LogicalVolumeSpec{
-				Name:                name,
-				NodeName:            node,
-				DeviceClass:         dc,
-				LvcreateOptionClass: oc,
-				Size:                *resource.NewQuantity(requestBytes, resource.BinarySI),
-				Source:              sourceName,
-				AccessType:          "rw",
+				Name:                 name,
+				NodeName:             node,
+				DeviceClass:          dc,
+				LvcreateOptionClass:  oc,
+				LvcreateOptions:      lvcreateOptions,
+				Size:                 *resource.NewQuantity(requestBytes, resource.BinarySI),
+				Source:               sourceName,
+				AccessType:           "rw",
+				WipeOnDelete:         wipeOnDelete,
+				SnapshotBeforeExpand: snapshotBeforeExpand,
 			},
 		}
 	}
@@ -350,6 +391,24 @@ func (s *LogicalVolumeService) GetVolume(ctx context.Context, volumeID string) (
 	return s.volumeGetter.Get(ctx, volumeID)
 }
 
+// ListVolumes returns all the LogicalVolumes from the cache.
+func (s *LogicalVolumeService) ListVolumes(ctx context.Context) ([]topolvmv1.LogicalVolume, error) {
+	return s.volumeGetter.List(ctx)
+}
+
+// GetVolumeByName returns LogicalVolume by the name of the volume, which is the name of its PersistentVolume.
+func (s *LogicalVolumeService) GetVolumeByName(ctx context.Context, name string) (*topolvmv1.LogicalVolume, error) {
+	lv := new(topolvmv1.LogicalVolume)
+	err := s.getter.Get(ctx, client.ObjectKey{Name: name}, lv)
+	if apierrors.IsNotFound(err) {
+		return nil, ErrVolumeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lv, nil
+}
+
 // updateSpecSize updates .Spec.Size of LogicalVolume.
 func (s *LogicalVolumeService) updateSpecSize(ctx context.Context, volumeID string, size *resource.Quantity) error {
 	for {
@@ -2,6 +2,7 @@ package k8s
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strconv"
 
@@ -19,6 +20,8 @@ var ErrDeviceClassNotFound = errors.New("device class not found")
 type NodeService struct {
 	// it is safe to use cache reader because updating node annotations is periodic.
 	reader client.Reader
+	// thick makes the capacities of thin device-classes read as the VG free space.
+	thick bool
 }
 
 // NewNodeService returns NodeService.
@@ -26,6 +29,14 @@ func NewNodeService(r client.Reader) *NodeService {
 	return &NodeService{reader: r}
 }
 
+// ForProvisioningType returns the NodeService reading the capacities charged for the volumes of provisioningType.
+// The thick volumes provisioned on a thin device-class by overriding the type are charged against the free space
+// of its volume group rather than of its thin pool.
+func (s NodeService) ForProvisioningType(provisioningType string) *NodeService {
+	s.thick = provisioningType == "thick"
+	return &s
+}
+
 func (s NodeService) getNodes(ctx context.Context) (*v1.PartialObjectMetadataList, error) {
 	nl := new(v1.PartialObjectMetadataList)
 	nl.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
@@ -40,6 +51,15 @@ func (s NodeService) extractCapacityFromAnnotation(node *v1.PartialObjectMetadat
 	if deviceClass == topolvm.DefaultDeviceClassName {
 		deviceClass = topolvm.DefaultDeviceClassAnnotationName
 	}
+	if s.thick {
+		if c, ok := node.Annotations[topolvm.GetThickCapacityKeyPrefix()+deviceClass]; ok {
+			return strconv.ParseInt(c, 10, 64)
+		}
+	}
+	// the thin pool of the device class is about to be exhausted.
+	if _, ok := node.Annotations[topolvm.GetCapacityEmergencyKeyPrefix()+deviceClass]; ok {
+		return 0, nil
+	}
 	c, ok := node.Annotations[topolvm.GetCapacityKeyPrefix()+deviceClass]
 	if !ok {
 		return 0, ErrDeviceClassNotFound
@@ -78,6 +98,32 @@ func (s NodeService) GetCapacityByTopologyLabel(ctx context.Context, topology, d
 	return 0, ErrNodeNotFound
 }
 
+// GetLvcreateInlineOptions returns the prefixes of the inline lvcreate options that lvmd on the node
+// specified by TopoLVM's topology label allows for the lvcreate-option-class.
+func (s NodeService) GetLvcreateInlineOptions(ctx context.Context, topology, lvcreateOptionClass string) ([]string, error) {
+	nl, err := s.getNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range nl.Items {
+		if v, ok := node.Labels[topolvm.GetTopologyNodeKey()]; !ok || v != topology {
+			continue
+		}
+		data, ok := node.Annotations[topolvm.GetLvcreateInlineOptionsKeyPrefix()+lvcreateOptionClass]
+		if !ok {
+			return nil, nil
+		}
+		var options []string
+		if err := json.Unmarshal([]byte(data), &options); err != nil {
+			return nil, err
+		}
+		return options, nil
+	}
+
+	return nil, ErrNodeNotFound
+}
+
 // GetTotalCapacity returns total VG capacity of all nodes.
 func (s NodeService) GetTotalCapacity(ctx context.Context, dc string) (int64, error) {
 	nl, err := s.getNodes(ctx)
@@ -93,6 +139,25 @@ func (s NodeService) GetTotalCapacity(ctx context.Context, dc string) (int64, er
 	return capacity, nil
 }
 
+// GetCapacities returns VG capacity of each node by the node name.
+// Nodes without the device class are omitted.
+func (s NodeService) GetCapacities(ctx context.Context, deviceClass string) (map[string]int64, error) {
+	nl, err := s.getNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	capacities := make(map[string]int64)
+	for _, node := range nl.Items {
+		c, err := s.extractCapacityFromAnnotation(&node, deviceClass)
+		if err != nil {
+			continue
+		}
+		capacities[node.Name] = c
+	}
+	return capacities, nil
+}
+
 // GetMaxCapacity returns max VG capacity among nodes.
 func (s NodeService) GetMaxCapacity(ctx context.Context, deviceClass string) (string, int64, error) {
 	nl, err := s.getNodes(ctx)
@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// PersistentVolumeClaimService reads the PVCs of the volumes being provisioned.
+type PersistentVolumeClaimService struct {
+	reader client.Reader
+}
+
+//+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
+
+// NewPersistentVolumeClaimService returns PersistentVolumeClaimService.
+func NewPersistentVolumeClaimService(r client.Reader) *PersistentVolumeClaimService {
+	return &PersistentVolumeClaimService{reader: r}
+}
+
+// GetAnnotation returns the value of the annotation of the PVC, or an empty string if the PVC or the annotation does not exist.
+func (s PersistentVolumeClaimService) GetAnnotation(ctx context.Context, namespace, name, key string) (string, error) {
+	pvc := new(corev1.PersistentVolumeClaim)
+	if err := s.reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pvc); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return pvc.Annotations[key], nil
+}
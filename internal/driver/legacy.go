@@ -0,0 +1,42 @@
+package driver
+
+import (
+	"context"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+)
+
+// NewLegacyIdentityServer returns a new IdentityServer advertising the legacy plugin name.
+//
+// It is used together with NewLegacyNodeServer so that kubelet can keep using the volumes
+// of PersistentVolumes still referring to the legacy plugin name.
+func NewLegacyIdentityServer(ready func() (bool, error)) csi.IdentityServer {
+	return &identityServer{name: topolvm.GetLegacyPluginName(), ready: ready}
+}
+
+// NewLegacyNodeServer returns a NodeServer serving the same volumes as nodeServer under the legacy plugin name.
+//
+// The volumes are identified by their volume IDs, which are the same for both plugin names,
+// so all the requests are passed to nodeServer except that the topology is reported with the legacy key.
+func NewLegacyNodeServer(nodeServer csi.NodeServer) csi.NodeServer {
+	return &legacyNodeServer{NodeServer: nodeServer}
+}
+
+type legacyNodeServer struct {
+	csi.NodeServer
+}
+
+func (s *legacyNodeServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
+	res, err := s.NodeServer.NodeGetInfo(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if segments := res.GetAccessibleTopology().GetSegments(); segments != nil {
+		if v, ok := segments[topolvm.GetTopologyNodeKey()]; ok {
+			delete(segments, topolvm.GetTopologyNodeKey())
+			segments[topolvm.GetLegacyTopologyNodeKey()] = v
+		}
+	}
+	return res, nil
+}
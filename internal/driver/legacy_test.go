@@ -0,0 +1,46 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+)
+
+type nodeInfoServer struct {
+	csi.UnimplementedNodeServer
+}
+
+func (nodeInfoServer) NodeGetInfo(ctx context.Context, req *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
+	return &csi.NodeGetInfoResponse{
+		NodeId: "node1",
+		AccessibleTopology: &csi.Topology{
+			Segments: map[string]string{topolvm.GetTopologyNodeKey(): "node1"},
+		},
+	}, nil
+}
+
+func TestLegacyNodeServer(t *testing.T) {
+	ctx := context.Background()
+
+	res, err := NewLegacyNodeServer(&nodeInfoServer{}).NodeGetInfo(ctx, &csi.NodeGetInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetNodeId() != "node1" {
+		t.Errorf("unexpected node ID: %s", res.GetNodeId())
+	}
+	segments := res.GetAccessibleTopology().GetSegments()
+	if len(segments) != 1 || segments["topology.topolvm.cybozu.com/node"] != "node1" {
+		t.Errorf("topology should be reported with the legacy key: %v", segments)
+	}
+
+	info, err := NewLegacyIdentityServer(func() (bool, error) { return true, nil }).GetPluginInfo(ctx, &csi.GetPluginInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GetName() != "topolvm.cybozu.com" {
+		t.Errorf("unexpected plugin name: %s", info.GetName())
+	}
+}
@@ -0,0 +1,144 @@
+package driver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topolvm/internal/filesystem"
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	cryptsetupCmd = "/sbin/cryptsetup"
+
+	// encryptionLUKS is the value of the encryption parameter to encrypt volumes with dm-crypt/LUKS.
+	encryptionLUKS = "luks"
+
+	// luksPassphraseKey is the key of the node-stage secret that holds the LUKS passphrase.
+	luksPassphraseKey = "passphrase"
+
+	luksFilesystemType = "crypto_LUKS"
+	luksMapperPrefix   = "topolvm-luks-"
+)
+
+// sysBlockDirectory is where the device-mapper devices are looked up.
+// It is a variable to be replaced in tests.
+var sysBlockDirectory = "/sys/block"
+
+// luksMapperName returns the device-mapper name of the opened LUKS device of the volume.
+func luksMapperName(volumeID string) string {
+	return luksMapperPrefix + volumeID
+}
+
+// luks sets up and tears down dm-crypt/LUKS devices with cryptsetup.
+// The passphrase is always passed through stdin so that it never appears in the process list.
+type luks struct {
+	exec utilexec.Interface
+}
+
+// open formats device with LUKS2 if the device is empty and opens it as name.
+// It does nothing if the device is already opened.
+func (l luks) open(device, name string, passphrase []byte) error {
+	_, _, opened, err := findDeviceMapper(name)
+	if err != nil {
+		return err
+	}
+	if opened {
+		return nil
+	}
+
+	fsType, err := filesystem.DetectFilesystem(device)
+	if err != nil {
+		return fmt.Errorf("filesystem check failed: device=%s, error=%w", device, err)
+	}
+	switch fsType {
+	case luksFilesystemType:
+	case "":
+		if err := l.run(passphrase, "luksFormat", "--type", "luks2", "--batch-mode", "--key-file", "-", device); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("device %s is already formatted with %s, refusing to format it with LUKS", device, fsType)
+	}
+
+	return l.run(passphrase, "open", "--type", "luks2", "--key-file", "-", device, name)
+}
+
+// close closes the LUKS device name. It does nothing if the device is not opened.
+func (l luks) close(name string) error {
+	_, _, opened, err := findDeviceMapper(name)
+	if err != nil {
+		return err
+	}
+	if !opened {
+		return nil
+	}
+	return l.run(nil, "close", name)
+}
+
+// resize grows the opened LUKS device name to the size of the underlying device.
+// LUKS2 devices may require the passphrase to be resized, so it is passed if given.
+func (l luks) resize(name string, passphrase []byte) error {
+	args := []string{"resize", name}
+	if len(passphrase) != 0 {
+		args = append(args, "--key-file", "-")
+	}
+	return l.run(passphrase, args...)
+}
+
+func (l luks) run(stdin []byte, args ...string) error {
+	cmd := l.exec.Command(cryptsetupCmd, args...)
+	if stdin != nil {
+		cmd.SetStdin(bytes.NewReader(stdin))
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("cryptsetup %s failed: output=%s, error=%w", args[0], strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// findDeviceMapper looks up the device-mapper device by its name in sysfs and returns its device number.
+// The device files under /dev/mapper are not used because they are not populated in the container.
+func findDeviceMapper(name string) (major, minor uint32, found bool, err error) {
+	dirs, err := filepath.Glob(filepath.Join(sysBlockDirectory, "dm-*"))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	for _, dir := range dirs {
+		dmName, err := os.ReadFile(filepath.Join(dir, "dm", "name"))
+		if errors.Is(err, os.ErrNotExist) {
+			// the device has been removed after the lookup.
+			continue
+		} else if err != nil {
+			return 0, 0, false, err
+		}
+		if strings.TrimSpace(string(dmName)) != name {
+			continue
+		}
+
+		dev, err := os.ReadFile(filepath.Join(dir, "dev"))
+		if err != nil {
+			return 0, 0, false, err
+		}
+		majorStr, minorStr, ok := strings.Cut(strings.TrimSpace(string(dev)), ":")
+		if !ok {
+			return 0, 0, false, fmt.Errorf("invalid device number of %s: %s", name, dev)
+		}
+		maj, err := strconv.ParseUint(majorStr, 10, 32)
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("invalid device number of %s: %w", name, err)
+		}
+		min, err := strconv.ParseUint(minorStr, 10, 32)
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("invalid device number of %s: %w", name, err)
+		}
+		return uint32(maj), uint32(min), true, nil
+	}
+	return 0, 0, false, nil
+}
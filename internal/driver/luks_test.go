@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	utilexec "k8s.io/utils/exec"
+	fakeexec "k8s.io/utils/exec/testing"
+)
+
+func TestFindDeviceMapper(t *testing.T) {
+	prev := sysBlockDirectory
+	sysBlockDirectory = t.TempDir()
+	defer func() { sysBlockDirectory = prev }()
+
+	for dm, v := range map[string][2]string{
+		"dm-0": {"vg-lv\n", "253:0\n"},
+		"dm-3": {luksMapperName("vol") + "\n", "253:3\n"},
+	} {
+		if err := os.MkdirAll(filepath.Join(sysBlockDirectory, dm, "dm"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(sysBlockDirectory, dm, "dm", "name"), []byte(v[0]), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(sysBlockDirectory, dm, "dev"), []byte(v[1]), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	major, minor, found, err := findDeviceMapper(luksMapperName("vol"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !found || major != 253 || minor != 3 {
+		t.Errorf("unexpected device: found=%v, major=%d, minor=%d", found, major, minor)
+	}
+
+	if _, _, found, err := findDeviceMapper(luksMapperName("other")); err != nil || found {
+		t.Errorf("unopened device should not be found: found=%v, err=%v", found, err)
+	}
+
+	// close does nothing if the device is not opened.
+	l := luks{exec: &fakeexec.FakeExec{}}
+	if err := l.close(luksMapperName("other")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLUKSResize(t *testing.T) {
+	var cmd fakeexec.FakeCmd
+	var argv []string
+	cmd.CombinedOutputScript = []fakeexec.FakeAction{
+		func() ([]byte, []byte, error) { return nil, nil, nil },
+	}
+	l := luks{exec: &fakeexec.FakeExec{
+		CommandScript: []fakeexec.FakeCommandAction{
+			func(name string, args ...string) utilexec.Cmd {
+				argv = append([]string{name}, args...)
+				return fakeexec.InitFakeCmd(&cmd, name, args...)
+			},
+		},
+	}}
+
+	if err := l.resize("topolvm-luks-vol", []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{cryptsetupCmd, "resize", "topolvm-luks-vol", "--key-file", "-"}
+	if !reflect.DeepEqual(argv, expected) {
+		t.Errorf("unexpected command: %v", argv)
+	}
+	stdin, err := io.ReadAll(cmd.Stdin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(stdin) != "secret" {
+		t.Errorf("passphrase should be passed via stdin: %q", stdin)
+	}
+}
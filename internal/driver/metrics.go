@@ -0,0 +1,62 @@
+package driver
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "topolvm"
+
+var volumeFilesystemFull = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "volume",
+	Name:      "filesystem_full",
+	Help:      "1 if the filesystem of the volume has no free space or inodes left, which is remedied by expanding the PVC",
+}, []string{"node", "device_class", "volume_id"})
+
+var volumeThinPoolFull = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "volume",
+	Name:      "thinpool_full",
+	Help:      "1 if the thin pool of the volume is out of data space, which is remedied by adding capacity to the thin pool",
+}, []string{"node", "device_class", "volume_id"})
+
+var writeVerificationFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "volume",
+	Name:      "write_verification_failures_total",
+	Help:      "The number of filesystems failing the write verification when they are mounted",
+}, []string{"node", "device_class"})
+
+var volumeDeletionsQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "volume",
+	Name:      "deletions_queued",
+	Help:      "The number of volumes of the node waiting for the deletions of its other volumes to finish",
+}, []string{"node"})
+
+func init() {
+	metrics.Registry.MustRegister(volumeFilesystemFull)
+	metrics.Registry.MustRegister(volumeThinPoolFull)
+	metrics.Registry.MustRegister(writeVerificationFailures)
+	metrics.Registry.MustRegister(volumeDeletionsQueued)
+}
+
+func boolToFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// recordVolumeFullness exports whether the filesystem or the thin pool of the volume is full.
+func recordVolumeFullness(node, deviceClass, volumeID string, filesystemFull, thinPoolFull bool) {
+	volumeFilesystemFull.WithLabelValues(node, deviceClass, volumeID).Set(boolToFloat64(filesystemFull))
+	volumeThinPoolFull.WithLabelValues(node, deviceClass, volumeID).Set(boolToFloat64(thinPoolFull))
+}
+
+// forgetVolumeFullness drops the metrics of a volume which is no longer staged on the node.
+func forgetVolumeFullness(volumeID string) {
+	volumeFilesystemFull.DeletePartialMatch(prometheus.Labels{"volume_id": volumeID})
+	volumeThinPoolFull.DeletePartialMatch(prometheus.Labels{"volume_id": volumeID})
+}
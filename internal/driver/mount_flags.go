@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultMountFlagsNone is the value of the default-mount-flags parameter disabling the default mount flags.
+const defaultMountFlagsNone = "none"
+
+// defaultMountFlagOpposites maps the flags allowed as default mount flags to the flags reverting them.
+var defaultMountFlagOpposites = map[string]string{
+	"nodev":  "dev",
+	"nosuid": "suid",
+	"noexec": "exec",
+}
+
+// ParseDefaultMountFlags parses the comma-separated default mount flags, which are some of nodev, nosuid and noexec.
+// "none" and an empty string result in no flags.
+func ParseDefaultMountFlags(value string) ([]string, error) {
+	if value == "" || value == defaultMountFlagsNone {
+		return nil, nil
+	}
+	var flags []string
+	for _, flag := range strings.Split(value, ",") {
+		flag = strings.TrimSpace(flag)
+		if _, ok := defaultMountFlagOpposites[flag]; !ok {
+			return nil, fmt.Errorf("unsupported default mount flag %q: should be nodev, nosuid or noexec", flag)
+		}
+		if !hasMountFlag(flags, flag) {
+			flags = append(flags, flag)
+		}
+	}
+	return flags, nil
+}
+
+// defaultMountFlags returns the default mount flags of a volume, which are those of the node unless the volume context
+// overrides them with the default-mount-flags parameter of the StorageClass.
+func defaultMountFlags(nodeDefaults []string, volumeContext map[string]string, key string) ([]string, error) {
+	value, ok := volumeContext[key]
+	if !ok {
+		return nodeDefaults, nil
+	}
+	return ParseDefaultMountFlags(value)
+}
+
+// applyDefaultMountFlags appends the default flags to mountFlags, except those mountFlags already sets or reverts,
+// e.g. "noexec" is not added if the StorageClass specifies "exec" in its mountOptions.
+func applyDefaultMountFlags(mountFlags, defaults []string) []string {
+	for _, flag := range defaults {
+		if hasMountFlag(mountFlags, flag) || hasMountFlag(mountFlags, defaultMountFlagOpposites[flag]) {
+			continue
+		}
+		mountFlags = append(mountFlags, flag)
+	}
+	return mountFlags
+}
+
+func hasMountFlag(mountFlags []string, flag string) bool {
+	for _, f := range mountFlags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
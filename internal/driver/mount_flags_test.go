@@ -0,0 +1,66 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+)
+
+func TestParseDefaultMountFlags(t *testing.T) {
+	for value, expected := range map[string][]string{
+		"":                     nil,
+		"none":                 nil,
+		"nodev":                {"nodev"},
+		"nodev, nosuid,noexec": {"nodev", "nosuid", "noexec"},
+		"nosuid,nosuid":        {"nosuid"},
+	} {
+		flags, err := ParseDefaultMountFlags(value)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", value, err)
+		} else if !reflect.DeepEqual(flags, expected) {
+			t.Errorf("%q: expected %v, got %v", value, expected, flags)
+		}
+	}
+	for _, value := range []string{"ro", "nodev,", "none,nodev"} {
+		if _, err := ParseDefaultMountFlags(value); err == nil {
+			t.Errorf("%q: error should happen", value)
+		}
+	}
+}
+
+func TestMakeMountOptionsDefaults(t *testing.T) {
+	key := topolvm.GetDefaultMountFlagsKey()
+	nodeDefaults := []string{"nodev", "nosuid", "noexec"}
+	cases := []struct {
+		name          string
+		volumeContext map[string]string
+		mountFlags    []string
+		expected      []string
+	}{
+		{"node defaults", nil, []string{"noatime"}, []string{"noatime", "nodev", "nosuid", "noexec", "nouuid"}},
+		{"opposite flag", nil, []string{"exec", "nodev"}, []string{"exec", "nodev", "nosuid", "nouuid"}},
+		{"storage class flags", map[string]string{key: "nosuid"}, nil, []string{"nosuid", "nouuid"}},
+		{"storage class opt-out", map[string]string{key: "none"}, nil, []string{"nouuid"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			defaults, err := defaultMountFlags(nodeDefaults, tc.volumeContext, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			options, err := makeMountOptions(false, &csi.VolumeCapability_MountVolume{FsType: "xfs", MountFlags: tc.mountFlags}, defaults)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(options, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, options)
+			}
+		})
+	}
+
+	if _, err := defaultMountFlags(nodeDefaults, map[string]string{key: "nodev,suid"}, key); err == nil {
+		t.Error("error should happen for an invalid volume context")
+	}
+}
@@ -0,0 +1,163 @@
+package driver
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+	mountutil "k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	nsenterCmd    = "/usr/bin/nsenter"
+	systemdRunCmd = "systemd-run"
+	mountCmd      = "mount"
+	umountCmd     = "umount"
+)
+
+// MountStrategy decides how the node server executes mount and umount.
+type MountStrategy string
+
+const (
+	// MountStrategyDirect runs mount within the mount namespace of topolvm-node.
+	MountStrategyDirect MountStrategy = "direct"
+	// MountStrategyNsenterHost runs mount and umount within the mount namespace of the host with nsenter,
+	// the same way as lvm is run by a containerized lvmd.
+	// It requires hostPID and the device directory to be shared with the host.
+	MountStrategyNsenterHost MountStrategy = "nsenter-host"
+	// MountStrategySystemdRun runs mount in a transient systemd scope within the mount namespace of the host,
+	// so that FUSE or other mount helpers survive restarts of topolvm-node.
+	// systemd-run is entered into the host with nsenter, because a scope runs its command in the namespaces
+	// of the caller. It requires hostPID and systemd-run to be installed on the host.
+	MountStrategySystemdRun MountStrategy = "systemd-run"
+)
+
+var _ pflag.Value = new(MountStrategy)
+
+func (s *MountStrategy) String() string {
+	return string(*s)
+}
+
+func (s *MountStrategy) Set(v string) error {
+	switch MountStrategy(v) {
+	case MountStrategyDirect, MountStrategyNsenterHost, MountStrategySystemdRun:
+		*s = MountStrategy(v)
+		return nil
+	}
+	return fmt.Errorf("unknown mount strategy %q, must be one of %s, %s or %s",
+		v, MountStrategyDirect, MountStrategyNsenterHost, MountStrategySystemdRun)
+}
+
+func (s *MountStrategy) Type() string {
+	return "string"
+}
+
+// newMounter returns the mounter executing mount with the strategy.
+func newMounter(strategy MountStrategy) (mountutil.SafeFormatAndMount, error) {
+	executor := utilexec.New()
+	var mounter mountutil.Interface
+	switch strategy {
+	case "", MountStrategyDirect:
+		mounter = mountutil.New("")
+	case MountStrategyNsenterHost, MountStrategySystemdRun:
+		mounter = &wrappedMounter{
+			Interface: mountutil.New(""),
+			strategy:  strategy,
+			exec:      executor,
+		}
+	default:
+		return mountutil.SafeFormatAndMount{}, fmt.Errorf("unknown mount strategy %q", strategy)
+	}
+	return mountutil.SafeFormatAndMount{
+		Interface: mounter,
+		Exec:      executor,
+	}, nil
+}
+
+// wrappedMounter runs mount (and umount) wrapped with nsenter or systemd-run.
+// Other operations such as listing mount points are done within the current mount namespace,
+// which receives the mounts by the bidirectional mount propagation of the kubelet directories.
+type wrappedMounter struct {
+	mountutil.Interface
+	strategy MountStrategy
+	exec     utilexec.Interface
+}
+
+func (m *wrappedMounter) Mount(source, target, fstype string, options []string) error {
+	return m.MountSensitive(source, target, fstype, options, nil)
+}
+
+func (m *wrappedMounter) MountSensitive(source, target, fstype string, options, sensitiveOptions []string) error {
+	args, logArgs := mountutil.MakeMountArgsSensitive(source, target, fstype, options, sensitiveOptions)
+	cmd, args := m.wrap(target, mountCmd, args)
+	nodeLogger.Info("mounting", "strategy", m.strategy, "command", cmd, "target", target, "args", logArgs)
+	out, err := m.exec.Command(cmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("mount failed: %w, strategy: %s, arguments: %s, output: %s", err, m.strategy, logArgs, string(out))
+	}
+	return nil
+}
+
+func (m *wrappedMounter) Unmount(target string) error {
+	if m.strategy != MountStrategyNsenterHost {
+		return m.Interface.Unmount(target)
+	}
+	cmd, args := m.wrap(target, umountCmd, []string{target})
+	out, err := m.exec.Command(cmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unmount failed: %w, strategy: %s, target: %s, output: %s", err, m.strategy, target, string(out))
+	}
+	return nil
+}
+
+// wrap returns the command and arguments to run cmd with the strategy.
+func (m *wrappedMounter) wrap(target, cmd string, args []string) (string, []string) {
+	switch m.strategy {
+	case MountStrategyNsenterHost:
+		return nsenterCmd, append([]string{"-m", "-u", "-i", "-n", "-p", "-t", "1", cmd}, args...)
+	case MountStrategySystemdRun:
+		return nsenterCmd, append([]string{
+			"-m", "-t", "1", systemdRunCmd, "--description=TopoLVM transient mount for " + target, "--scope", "--", cmd,
+		}, args...)
+	}
+	return cmd, args
+}
+
+// MountStrategyHints returns hints about the mount strategy for the environment topolvm-node runs in.
+// They are meant to be logged at startup and do not prevent the node server from starting.
+func MountStrategyHints(strategy MountStrategy) []string {
+	var hints []string
+	// systemd is looked for on the host, which is accessible through the root of PID 1 with hostPID.
+	_, systemdErr := os.Stat("/proc/1/root/run/systemd/system")
+	hostMountNS, hostNSErr := os.Readlink("/proc/1/ns/mnt")
+	selfMountNS, _ := os.Readlink("/proc/self/ns/mnt")
+
+	switch strategy {
+	case "", MountStrategyDirect:
+		if systemdErr == nil {
+			hints = append(hints, "systemd is detected, consider the systemd-run mount strategy "+
+				"if mounts do not survive restarts of topolvm-node")
+		}
+	case MountStrategyNsenterHost:
+		if _, err := os.Stat(nsenterCmd); err != nil {
+			hints = append(hints, nsenterCmd+" is not found, mounts will fail")
+		}
+		if hostNSErr != nil {
+			hints = append(hints, "the mount namespace of the host is not accessible, hostPID is required")
+		} else if hostMountNS == selfMountNS {
+			hints = append(hints, "topolvm-node already runs in the mount namespace of the host, "+
+				"the direct mount strategy is sufficient")
+		}
+	case MountStrategySystemdRun:
+		if _, err := os.Stat(nsenterCmd); err != nil {
+			hints = append(hints, nsenterCmd+" is not found, mounts will fail")
+		}
+		if hostNSErr != nil {
+			hints = append(hints, "the mount namespace of the host is not accessible, hostPID is required")
+		} else if systemdErr != nil {
+			hints = append(hints, "systemd is not detected on the host, mounts will fail")
+		}
+	}
+	return hints
+}
@@ -0,0 +1,54 @@
+package driver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMountStrategySet(t *testing.T) {
+	for _, v := range []string{"direct", "nsenter-host", "systemd-run"} {
+		var s MountStrategy
+		if err := s.Set(v); err != nil {
+			t.Errorf("%s should be valid: %v", v, err)
+		}
+		if s.String() != v {
+			t.Errorf("unexpected value: %s", s.String())
+		}
+	}
+
+	var s MountStrategy
+	if err := s.Set("chroot"); err == nil {
+		t.Error("unknown strategy should be rejected")
+	}
+}
+
+func TestWrappedMounterWrap(t *testing.T) {
+	testCases := []struct {
+		strategy MountStrategy
+		cmd      string
+		args     []string
+	}{
+		{
+			strategy: MountStrategyNsenterHost,
+			cmd:      nsenterCmd,
+			args:     []string{"-m", "-u", "-i", "-n", "-p", "-t", "1", "mount", "-t", "xfs", "/dev/foo", "/mnt"},
+		},
+		{
+			strategy: MountStrategySystemdRun,
+			cmd:      nsenterCmd,
+			args: []string{"-m", "-t", "1", "systemd-run", "--description=TopoLVM transient mount for /mnt", "--scope", "--",
+				"mount", "-t", "xfs", "/dev/foo", "/mnt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		m := &wrappedMounter{strategy: tc.strategy}
+		cmd, args := m.wrap("/mnt", mountCmd, []string{"-t", "xfs", "/dev/foo", "/mnt"})
+		if cmd != tc.cmd {
+			t.Errorf("%s: unexpected command: %s", tc.strategy, cmd)
+		}
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("%s: unexpected args: %v", tc.strategy, args)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	"github.com/topolvm/topolvm"
+	v1 "github.com/topolvm/topolvm/api/v1"
 	"github.com/topolvm/topolvm/internal/driver/internal/k8s"
 	"github.com/topolvm/topolvm/internal/filesystem"
 	"github.com/topolvm/topolvm/internal/lvmd/command"
@@ -20,8 +21,8 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	mountutil "k8s.io/mount-utils"
-	utilexec "k8s.io/utils/exec"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
@@ -33,12 +34,34 @@ const (
 
 var nodeLogger = ctrl.Log.WithName("driver").WithName("node")
 
+// NodeServerSettings hold all settings that should be passed to the node server.
+type NodeServerSettings struct {
+	MountStrategy MountStrategy `json:"mountStrategy" ,yaml:"mountStrategy"`
+	// VerifyWrites enables writing, syncing and reading back a block of data on each filesystem
+	// mounted by NodePublishVolume to detect failing devices before workloads use them.
+	VerifyWrites bool `json:"verifyWrites" ,yaml:"verifyWrites"`
+	// DefaultMountFlags are the flags among nodev, nosuid and noexec added to the mount options of
+	// every filesystem volume unless its StorageClass opts out of them.
+	DefaultMountFlags []string `json:"defaultMountFlags" ,yaml:"defaultMountFlags"`
+	// VolumeMountGroup advertises the VOLUME_MOUNT_GROUP capability, so that kubelet delegates applying the fsGroup
+	// of pods to NodePublishVolume instead of changing the ownership of all the files of the volume by itself.
+	VolumeMountGroup bool `json:"volumeMountGroup" ,yaml:"volumeMountGroup"`
+}
+
 // NewNodeServer returns a new NodeServer.
-func NewNodeServer(nodeName string, vgServiceClient proto.VGServiceClient, lvServiceClient proto.LVServiceClient, mgr manager.Manager) (csi.NodeServer, error) {
+func NewNodeServer(nodeName string, vgServiceClient proto.VGServiceClient, lvServiceClient proto.LVServiceClient, mgr manager.Manager, settings NodeServerSettings) (csi.NodeServer, error) {
 	lvService, err := k8s.NewLogicalVolumeService(mgr)
 	if err != nil {
 		return nil, err
 	}
+	mounter, err := newMounter(settings.MountStrategy)
+	if err != nil {
+		return nil, err
+	}
+	defaultMountFlags, err := ParseDefaultMountFlags(strings.Join(settings.DefaultMountFlags, ","))
+	if err != nil {
+		return nil, err
+	}
 
 	return &nodeServer{
 		server: &nodeServerNoLocked{
@@ -46,10 +69,13 @@ func NewNodeServer(nodeName string, vgServiceClient proto.VGServiceClient, lvSer
 			client:       vgServiceClient,
 			lvService:    lvServiceClient,
 			k8sLVService: lvService,
-			mounter: mountutil.SafeFormatAndMount{
-				Interface: mountutil.New(""),
-				Exec:      utilexec.New(),
-			},
+			k8sReader:    mgr.GetClient(),
+			mounter:      mounter,
+			luks:         luks{exec: mounter.Exec},
+			verifyWrites: settings.VerifyWrites,
+
+			volumeMountGroup:  settings.VolumeMountGroup,
+			defaultMountFlags: defaultMountFlags,
 		},
 	}, nil
 }
@@ -65,6 +91,20 @@ type nodeServer struct {
 	server *nodeServerNoLocked
 }
 
+func (s *nodeServer) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.server.NodeStageVolume(ctx, req)
+}
+
+func (s *nodeServer) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.server.NodeUnstageVolume(ctx, req)
+}
+
 func (s *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -113,7 +153,150 @@ type nodeServerNoLocked struct {
 	client       proto.VGServiceClient
 	lvService    proto.LVServiceClient
 	k8sLVService *k8s.LogicalVolumeService
+	k8sReader    client.Reader
 	mounter      mountutil.SafeFormatAndMount
+	luks         luks
+	verifyWrites bool
+
+	volumeMountGroup  bool
+	defaultMountFlags []string
+}
+
+// NodeStageVolume activates inactive volumes, e.g. with the activation skip flag or not autoactivated by lvm
+// on the host, and opens the dm-crypt/LUKS device
+// of encrypted volumes, formatting it on the first stage.
+// Nothing else is staged for volumes without encryption, they are directly published by NodePublishVolume.
+func (s *nodeServerNoLocked) NodeStageVolume(ctx context.Context, req *csi.NodeStageVolumeRequest) (*csi.NodeStageVolumeResponse, error) {
+	volumeContext := req.GetVolumeContext()
+	volumeID := req.GetVolumeId()
+
+	nodeLogger.Info("NodeStageVolume called",
+		"volume_id", volumeID,
+		"staging_target_path", req.GetStagingTargetPath(),
+		"volume_capability", req.GetVolumeCapability(),
+		"num_secrets", len(req.GetSecrets()),
+		"volume_context", volumeContext)
+
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no volume_id is provided")
+	}
+	if len(req.GetStagingTargetPath()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no staging_target_path is provided")
+	}
+	if req.GetVolumeCapability() == nil {
+		return nil, status.Error(codes.InvalidArgument, "no volume_capability is provided")
+	}
+
+	encryption := volumeContext[topolvm.GetEncryptionKey()]
+	switch encryption {
+	case "", encryptionLUKS:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported encryption: %s", encryption)
+	}
+	passphrase := req.GetSecrets()[luksPassphraseKey]
+	if encryption != "" && len(passphrase) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "no %q is provided in the node-stage secret", luksPassphraseKey)
+	}
+
+	lvr, err := s.k8sLVService.GetVolume(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if op := lvr.Status.Operation; op != nil && op.Name == v1.OperationMove && op.Phase == v1.OperationPhaseInProgress {
+		// writes to the volume being copied would be lost.
+		return nil, status.Errorf(codes.Unavailable, "volume %s is being moved to another thin pool", volumeID)
+	}
+	lv, err := s.getLvFromContext(ctx, lvr.Spec.DeviceClass, volumeID)
+	if err != nil {
+		return nil, err
+	}
+	if lv == nil {
+		return nil, status.Errorf(codes.NotFound, "failed to find LV: %s", volumeID)
+	}
+	attr, err := command.ParsedLvAttr(lv.GetAttr())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse attributes returned from logical volume service: %v", err)
+	}
+	if attr.State != command.StateActive {
+		resp, err := s.lvService.ActivateLV(ctx, &proto.ActivateLVRequest{Name: volumeID, DeviceClass: lvr.Spec.DeviceClass})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to activate LV: volume=%s, error=%v", volumeID, err)
+		}
+		lv = resp.GetVolume()
+		nodeLogger.Info("NodeStageVolume activated LV", "volume_id", volumeID)
+	}
+	if encryption == "" {
+		return &csi.NodeStageVolumeResponse{}, nil
+	}
+
+	device := filepath.Join(topolvm.DeviceDirectory, volumeID)
+	if err := s.createDeviceIfNeeded(device, lv.DevMajor, lv.DevMinor); err != nil {
+		return nil, err
+	}
+	if err := s.luks.open(device, luksMapperName(volumeID), []byte(passphrase)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open LUKS device: volume=%s, error=%v", volumeID, err)
+	}
+
+	nodeLogger.Info("NodeStageVolume(luks) succeeded",
+		"volume_id", volumeID,
+		"staging_target_path", req.GetStagingTargetPath())
+	return &csi.NodeStageVolumeResponse{}, nil
+}
+
+// NodeUnstageVolume closes the dm-crypt/LUKS device of the volume if it is opened.
+func (s *nodeServerNoLocked) NodeUnstageVolume(ctx context.Context, req *csi.NodeUnstageVolumeRequest) (*csi.NodeUnstageVolumeResponse, error) {
+	volumeID := req.GetVolumeId()
+
+	nodeLogger.Info("NodeUnstageVolume called",
+		"volume_id", volumeID,
+		"staging_target_path", req.GetStagingTargetPath())
+
+	if len(volumeID) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no volume_id is provided")
+	}
+	if len(req.GetStagingTargetPath()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no staging_target_path is provided")
+	}
+
+	if err := s.luks.close(luksMapperName(volumeID)); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to close LUKS device: volume=%s, error=%v", volumeID, err)
+	}
+	device := filepath.Join(topolvm.DeviceDirectory, volumeID)
+	if err := os.Remove(device); err != nil && !os.IsNotExist(err) {
+		return nil, status.Errorf(codes.Internal, "remove device failed for %s: error=%v", device, err)
+	}
+	if err := s.deactivateIfSkipped(ctx, volumeID); err != nil {
+		return nil, err
+	}
+	forgetVolumeFullness(volumeID)
+
+	return &csi.NodeUnstageVolumeResponse{}, nil
+}
+
+// deactivateIfSkipped deactivates the volume if it has the activation skip flag, so that it is active only while it is staged.
+func (s *nodeServerNoLocked) deactivateIfSkipped(ctx context.Context, volumeID string) error {
+	lvr, err := s.k8sLVService.GetVolume(ctx, volumeID)
+	if errors.Is(err, k8s.ErrVolumeNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	lv, err := s.getLvFromContext(ctx, lvr.Spec.DeviceClass, volumeID)
+	if err != nil || lv == nil {
+		return err
+	}
+	attr, err := command.ParsedLvAttr(lv.GetAttr())
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to parse attributes returned from logical volume service: %v", err)
+	}
+	if attr.SkipActivation != command.SkipActivationTrue || attr.State != command.StateActive {
+		return nil
+	}
+	if _, err := s.lvService.DeactivateLV(ctx, &proto.DeactivateLVRequest{Name: volumeID, DeviceClass: lvr.Spec.DeviceClass}); err != nil {
+		return status.Errorf(codes.Internal, "failed to deactivate LV: volume=%s, error=%v", volumeID, err)
+	}
+	nodeLogger.Info("NodeUnstageVolume deactivated LV", "volume_id", volumeID)
+	return nil
 }
 
 func (s *nodeServerNoLocked) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
@@ -159,6 +342,9 @@ func (s *nodeServerNoLocked) NodePublishVolume(ctx context.Context, req *csi.Nod
 	if err != nil {
 		return nil, err
 	}
+	if err := checkWarmedUp(lvr, volumeContext); err != nil {
+		return nil, err
+	}
 	lv, err = s.getLvFromContext(ctx, lvr.Spec.DeviceClass, volumeID)
 	if err != nil {
 		return nil, err
@@ -167,10 +353,24 @@ func (s *nodeServerNoLocked) NodePublishVolume(ctx context.Context, req *csi.Nod
 		return nil, status.Errorf(codes.NotFound, "failed to find LV: %s", volumeID)
 	}
 
+	// encrypted volumes are published with the LUKS device opened by NodeStageVolume.
+	deviceName, devMajor, devMinor := volumeID, lv.DevMajor, lv.DevMinor
+	if volumeContext[topolvm.GetEncryptionKey()] != "" {
+		var found bool
+		deviceName = luksMapperName(volumeID)
+		devMajor, devMinor, found, err = findDeviceMapper(deviceName)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to find LUKS device: volume=%s, error=%v", volumeID, err)
+		}
+		if !found {
+			return nil, status.Errorf(codes.FailedPrecondition, "LUKS device is not opened, the volume must be staged first: %s", volumeID)
+		}
+	}
+
 	if isBlockVol {
-		err = s.nodePublishBlockVolume(req, lv)
+		err = s.nodePublishBlockVolume(req, devMajor, devMinor)
 	} else if isFsVol {
-		err = s.nodePublishFilesystemVolume(req, lv)
+		err = s.nodePublishFilesystemVolume(ctx, req, lvr.Spec.DeviceClass, filepath.Join(topolvm.DeviceDirectory, deviceName), devMajor, devMinor)
 	}
 	if err != nil {
 		return nil, err
@@ -178,8 +378,8 @@ func (s *nodeServerNoLocked) NodePublishVolume(ctx context.Context, req *csi.Nod
 	return &csi.NodePublishVolumeResponse{}, nil
 }
 
-func makeMountOptions(readOnly bool, mountOption *csi.VolumeCapability_MountVolume) ([]string, error) {
-	mountOptions := make([]string, 0, len(mountOption.MountFlags)+2)
+func makeMountOptions(readOnly bool, mountOption *csi.VolumeCapability_MountVolume, defaults []string) ([]string, error) {
+	mountOptions := make([]string, 0, len(mountOption.MountFlags)+len(defaults)+2)
 	if readOnly {
 		mountOptions = append(mountOptions, "ro")
 	}
@@ -190,6 +390,7 @@ func makeMountOptions(readOnly bool, mountOption *csi.VolumeCapability_MountVolu
 		}
 		mountOptions = append(mountOptions, f)
 	}
+	mountOptions = applyDefaultMountFlags(mountOptions, defaults)
 
 	// avoid duplicate UUIDs
 	if mountOption.FsType == "xfs" {
@@ -199,24 +400,41 @@ func makeMountOptions(readOnly bool, mountOption *csi.VolumeCapability_MountVolu
 	return mountOptions, nil
 }
 
-func (s *nodeServerNoLocked) nodePublishFilesystemVolume(req *csi.NodePublishVolumeRequest, lv *proto.LogicalVolume) error {
+func (s *nodeServerNoLocked) nodePublishFilesystemVolume(ctx context.Context, req *csi.NodePublishVolumeRequest, deviceClass, device string, devMajor, devMinor uint32) error {
 	// Check request
 	mountOption := req.GetVolumeCapability().GetMount()
+	requestedFsType := mountOption.FsType
 	if mountOption.FsType == "" {
 		mountOption.FsType = "ext4"
 	}
 
-	// Find lv and create a block device with it
-	device := filepath.Join(topolvm.DeviceDirectory, req.GetVolumeId())
-	err := s.createDeviceIfNeeded(device, lv)
+	// Create a block device of the lv
+	err := s.createDeviceIfNeeded(device, devMajor, devMinor)
 	if err != nil {
 		return err
 	}
 
-	mountOptions, err := makeMountOptions(req.GetReadonly(), mountOption)
+	defaults, err := defaultMountFlags(s.defaultMountFlags, req.GetVolumeContext(), topolvm.GetDefaultMountFlagsKey())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid default mount flags: volume=%s, error=%v", req.GetVolumeId(), err)
+	}
+	mountOptions, err := makeMountOptions(req.GetReadonly(), mountOption, defaults)
 	if err != nil {
 		return err
 	}
+	if req.GetVolumeContext()[topolvm.GetProjectQuotaKey()] == "true" && mountOption.FsType == "xfs" {
+		mountOptions = withProjectQuota(mountOptions)
+	}
+	formatOptions, err := filesystem.FormatOptions(mountOption.FsType,
+		req.GetVolumeContext()[topolvm.GetFilesystemLabelKey()], req.GetVolumeContext()[topolvm.GetFilesystemUUIDKey()])
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid filesystem identity: volume=%s, error=%v", req.GetVolumeId(), err)
+	}
+	mkfsOptions := strings.Fields(req.GetVolumeContext()[topolvm.GetMkfsOptionsKey()])
+	if err := filesystem.ValidateMkfsOptions(mkfsOptions); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid mkfs options: volume=%s, error=%v", req.GetVolumeId(), err)
+	}
+	formatOptions = append(formatOptions, mkfsOptions...)
 
 	err = os.MkdirAll(req.GetTargetPath(), 0755)
 	if err != nil {
@@ -238,12 +456,26 @@ func (s *nodeServerNoLocked) nodePublishFilesystemVolume(req *csi.NodePublishVol
 	}
 
 	if !mounted {
-		if err := s.mounter.FormatAndMount(device, req.GetTargetPath(), mountOption.FsType, mountOptions); err != nil {
+		if err := s.mounter.FormatAndMountSensitiveWithFormatOptions(device, req.GetTargetPath(), mountOption.FsType, mountOptions, nil, formatOptions); err != nil {
 			return status.Errorf(codes.Internal, "mount failed: volume=%s, error=%v", req.GetVolumeId(), err)
 		}
 		if err := os.Chmod(req.GetTargetPath(), 0777|os.ModeSetgid); err != nil {
 			return status.Errorf(codes.Internal, "chmod 2777 failed: target=%s, error=%v", req.GetTargetPath(), err)
 		}
+		if s.verifyWrites && !req.GetReadonly() {
+			if err := verifyWrites(req.GetTargetPath()); err != nil {
+				writeVerificationFailures.WithLabelValues(s.nodeName, deviceClass).Inc()
+				// unmount so that the verification is repeated when the call is retried.
+				if err := s.mounter.Unmount(req.GetTargetPath()); err != nil {
+					nodeLogger.Error(err, "failed to unmount after write verification failure", "target_path", req.GetTargetPath())
+				}
+				return status.Errorf(codes.Internal, "write verification failed: volume=%s, error=%v", req.GetVolumeId(), err)
+			}
+		}
+	}
+
+	if err := s.setVolumeOwnership(ctx, req, requestedFsType); err != nil {
+		return err
 	}
 
 	r := mountutil.NewResizeFs(s.mounter.Exec)
@@ -263,13 +495,13 @@ func (s *nodeServerNoLocked) nodePublishFilesystemVolume(req *csi.NodePublishVol
 	return nil
 }
 
-func (s *nodeServerNoLocked) createDeviceIfNeeded(device string, lv *proto.LogicalVolume) error {
+func (s *nodeServerNoLocked) createDeviceIfNeeded(device string, devMajor, devMinor uint32) error {
 	var stat unix.Stat_t
 	err := filesystem.Stat(device, &stat)
 	switch err {
 	case nil:
 		// a block device already exists, check its attributes
-		if stat.Rdev == unix.Mkdev(lv.DevMajor, lv.DevMinor) && stat.Uid == uint32(os.Getuid()) && stat.Mode == deviceMode {
+		if stat.Rdev == unix.Mkdev(devMajor, devMinor) && stat.Uid == uint32(os.Getuid()) && stat.Mode == deviceMode {
 			return nil
 		}
 		err := os.Remove(device)
@@ -283,10 +515,10 @@ func (s *nodeServerNoLocked) createDeviceIfNeeded(device string, lv *proto.Logic
 			return status.Errorf(codes.Internal, "mkdir failed: target=%s, error=%v", path.Dir(device), err)
 		}
 
-		devno := unix.Mkdev(lv.DevMajor, lv.DevMinor)
+		devno := unix.Mkdev(devMajor, devMinor)
 		if err := filesystem.Mknod(device, deviceMode, int(devno)); err != nil {
 			return status.Errorf(codes.Internal, "mknod failed for %s. major=%d, minor=%d, error=%v",
-				device, lv.DevMajor, lv.DevMinor, err)
+				device, devMajor, devMinor, err)
 		}
 	default:
 		return status.Errorf(codes.Internal, "failed to stat %s: error=%v", device, err)
@@ -294,10 +526,10 @@ func (s *nodeServerNoLocked) createDeviceIfNeeded(device string, lv *proto.Logic
 	return nil
 }
 
-func (s *nodeServerNoLocked) nodePublishBlockVolume(req *csi.NodePublishVolumeRequest, lv *proto.LogicalVolume) error {
-	// Find lv and create a block device with it
+func (s *nodeServerNoLocked) nodePublishBlockVolume(req *csi.NodePublishVolumeRequest, devMajor, devMinor uint32) error {
+	// Create a block device of the lv
 	targetPath := req.GetTargetPath()
-	err := s.createDeviceIfNeeded(targetPath, lv)
+	err := s.createDeviceIfNeeded(targetPath, devMajor, devMinor)
 	if err != nil {
 		return err
 	}
@@ -369,8 +601,10 @@ func (s *nodeServerNoLocked) nodeUnpublishFilesystemVolume(req *csi.NodeUnpublis
 		return status.Errorf(codes.Internal, "unmount failed for %s: error=%v", targetPath, err)
 	}
 
-	if err := os.Remove(device); err != nil && !os.IsNotExist(err) {
-		return status.Errorf(codes.Internal, "remove device failed for %s: error=%v", device, err)
+	for _, d := range []string{device, filepath.Join(topolvm.DeviceDirectory, luksMapperName(req.GetVolumeId()))} {
+		if err := os.Remove(d); err != nil && !os.IsNotExist(err) {
+			return status.Errorf(codes.Internal, "remove device failed for %s: error=%v", d, err)
+		}
 	}
 
 	nodeLogger.Info("NodeUnpublishVolume(fs) is succeeded",
@@ -468,20 +702,37 @@ func (s *nodeServerNoLocked) NodeGetVolumeStats(ctx context.Context, req *csi.No
 		return nil, status.Errorf(codes.Internal, "failed to parse attributes returned from logical volume service: %v", err)
 	}
 
-	var volumeCondition csi.VolumeCondition
+	filesystemFull := (sfs.Blocks > 0 && sfs.Bavail == 0) || (sfs.Files > 0 && sfs.Ffree == 0)
+	thinPoolFull := lv.GetPoolHealthError() == command.ErrThinPoolOutOfDataSpace.Error()
+	recordVolumeFullness(s.nodeName, lvr.Spec.DeviceClass, volumeID, filesystemFull, thinPoolFull)
+
+	return &csi.NodeGetVolumeStatsResponse{Usage: usage, VolumeCondition: volumeCondition(attr, lv.GetPoolHealthError(), &sfs)}, nil
+}
+
+// volumeCondition reports the health of the LV, then the health of its thin pool and then whether the filesystem is full.
+// A full thin pool and a full filesystem are told apart, because the former must be fixed by adding capacity
+// to the thin pool on the node while the latter is fixed by expanding the PVC.
+func volumeCondition(attr command.LvAttr, poolHealthError string, sfs *unix.Statfs_t) *csi.VolumeCondition {
 	if err := attr.VerifyHealth(); err != nil {
-		volumeCondition = csi.VolumeCondition{
+		return &csi.VolumeCondition{Abnormal: true, Message: err.Error()}
+	}
+	switch {
+	case poolHealthError == command.ErrThinPoolOutOfDataSpace.Error():
+		return &csi.VolumeCondition{
 			Abnormal: true,
-			Message:  err.Error(),
-		}
-	} else {
-		volumeCondition = csi.VolumeCondition{
-			Abnormal: false,
-			Message:  "volume is healthy and operating normally",
+			Message:  poolHealthError + ", capacity must be added to the thin pool on the node as expanding the volume does not help",
 		}
+	case poolHealthError != "":
+		return &csi.VolumeCondition{Abnormal: true, Message: "thin pool is unhealthy: " + poolHealthError}
+	case sfs.Blocks > 0 && sfs.Bavail == 0:
+		return &csi.VolumeCondition{Abnormal: true, Message: "filesystem is full, expand the volume or delete files"}
+	case sfs.Files > 0 && sfs.Ffree == 0:
+		return &csi.VolumeCondition{Abnormal: true, Message: "filesystem has no free inodes, expand the volume or delete files"}
+	}
+	return &csi.VolumeCondition{
+		Abnormal: false,
+		Message:  "volume is healthy and operating normally",
 	}
-
-	return &csi.NodeGetVolumeStatsResponse{Usage: usage, VolumeCondition: &volumeCondition}, nil
 }
 
 func (s *nodeServerNoLocked) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
@@ -507,11 +758,24 @@ func (s *nodeServerNoLocked) NodeExpandVolume(ctx context.Context, req *csi.Node
 	_, err := convertRequestCapacityBytes(
 		req.GetCapacityRange().GetRequiredBytes(),
 		req.GetCapacityRange().GetLimitBytes(),
+		0,
 	)
 	if err != nil {
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// the LUKS device of encrypted volumes has to be grown before the filesystem.
+	luksName := luksMapperName(volumeID)
+	luksMajor, luksMinor, encrypted, err := findDeviceMapper(luksName)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find LUKS device: volume=%s, error=%v", volumeID, err)
+	}
+	if encrypted {
+		if err := s.luks.resize(luksName, []byte(req.GetSecrets()[luksPassphraseKey])); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to resize LUKS device: volume=%s, error=%v", volumeID, err)
+		}
+	}
+
 	if isBlock := req.GetVolumeCapability().GetBlock() != nil; isBlock {
 		nodeLogger.Info("NodeExpandVolume(block) is skipped",
 			"volume_id", volumeID,
@@ -535,7 +799,12 @@ func (s *nodeServerNoLocked) NodeExpandVolume(ctx context.Context, req *csi.Node
 	if lv == nil {
 		return nil, status.Errorf(codes.NotFound, "failed to find LV: %s", volumeID)
 	}
-	err = s.createDeviceIfNeeded(device, lv)
+	if encrypted {
+		device = filepath.Join(topolvm.DeviceDirectory, luksName)
+		err = s.createDeviceIfNeeded(device, luksMajor, luksMinor)
+	} else {
+		err = s.createDeviceIfNeeded(device, lv.DevMajor, lv.DevMinor)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -570,10 +839,14 @@ func (s *nodeServerNoLocked) NodeExpandVolume(ctx context.Context, req *csi.Node
 
 func (s *nodeServerNoLocked) NodeGetCapabilities(context.Context, *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
 	capabilities := []csi.NodeServiceCapability_RPC_Type{
+		csi.NodeServiceCapability_RPC_STAGE_UNSTAGE_VOLUME,
 		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
 		csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
 		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
 	}
+	if s.volumeMountGroup {
+		capabilities = append(capabilities, csi.NodeServiceCapability_RPC_VOLUME_MOUNT_GROUP)
+	}
 
 	csiCaps := make([]*csi.NodeServiceCapability, len(capabilities))
 	for i, capability := range capabilities {
@@ -1,16 +1,57 @@
 package driver
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"golang.org/x/sys/unix"
 )
 
 func TestMakeMountOptions(t *testing.T) {
 	_, err := makeMountOptions(true, &csi.VolumeCapability_MountVolume{
 		MountFlags: []string{"rw"},
-	})
+	}, nil)
 	if err == nil {
 		t.Fatalf("err should happen")
 	}
 }
+
+func TestVolumeCondition(t *testing.T) {
+	healthy, err := command.ParsedLvAttr("Vwi-aotz--")
+	if err != nil {
+		t.Fatal(err)
+	}
+	suspended, err := command.ParsedLvAttr("Vwi-sotz--")
+	if err != nil {
+		t.Fatal(err)
+	}
+	free := unix.Statfs_t{Blocks: 100, Bavail: 10, Files: 100, Ffree: 10}
+	noSpace := unix.Statfs_t{Blocks: 100, Bavail: 0, Files: 100, Ffree: 10}
+	noInodes := unix.Statfs_t{Blocks: 100, Bavail: 10, Files: 100, Ffree: 0}
+
+	cases := []struct {
+		name            string
+		attr            command.LvAttr
+		poolHealthError string
+		sfs             unix.Statfs_t
+		abnormal        bool
+		message         string
+	}{
+		{"healthy", healthy, "", free, false, "healthy"},
+		{"unhealthy volume", suspended, command.ErrThinPoolOutOfDataSpace.Error(), noSpace, true, "suspended"},
+		{"full thin pool", healthy, command.ErrThinPoolOutOfDataSpace.Error(), noSpace, true, "capacity must be added to the thin pool"},
+		{"failed thin pool", healthy, command.ErrThinPoolFailed.Error(), free, true, "thin pool is unhealthy"},
+		{"full filesystem", healthy, "", noSpace, true, "expand the volume"},
+		{"no inodes", healthy, "", noInodes, true, "no free inodes"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cond := volumeCondition(tc.attr, tc.poolHealthError, &tc.sfs)
+			if cond.GetAbnormal() != tc.abnormal || !strings.Contains(cond.GetMessage(), tc.message) {
+				t.Errorf("unexpected condition: %+v", cond)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/filesystem"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// fsGroupChangePolicy returns the fsGroupChangePolicy specified in the parameters of CreateVolume, or an empty string
+// if it is not specified. kubelet does not pass the policy of the pod to CSI drivers, so it is given by the StorageClass.
+func fsGroupChangePolicy(parameters map[string]string) (corev1.PodFSGroupChangePolicy, error) {
+	switch v := corev1.PodFSGroupChangePolicy(parameters[topolvm.GetFSGroupChangePolicyKey()]); v {
+	case "", corev1.FSGroupChangeAlways, corev1.FSGroupChangeOnRootMismatch:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid %s: %s", topolvm.GetFSGroupChangePolicyKey(), v)
+	}
+}
+
+// appliesFSGroup reports whether the fsGroup is applied to a volume by the fsGroupPolicy of the CSIDriver,
+// in the same way as kubelet does for the drivers without VOLUME_MOUNT_GROUP.
+// fsType is the filesystem type requested for the volume. The access mode is always SINGLE_NODE_WRITER.
+func appliesFSGroup(policy *storagev1.FSGroupPolicy, fsType string, readOnly bool) bool {
+	// the ownership of a read-only filesystem cannot be changed.
+	if readOnly {
+		return false
+	}
+	// ReadWriteOnceWithFSType is the default of the API.
+	p := storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy
+	if policy != nil {
+		p = *policy
+	}
+	switch p {
+	case storagev1.FileFSGroupPolicy:
+		return true
+	case storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy:
+		return fsType != ""
+	default:
+		return false
+	}
+}
+
+// setVolumeOwnership applies the fsGroup passed as the volume mount group by kubelet to the filesystem mounted
+// at the target path of NodePublishVolume. fsType is the filesystem type requested for the volume.
+//
+// With the OnRootMismatch policy, the default, the files are walked only if the root of the volume lacks
+// the ownership, so that publishing a volume with millions of files again does not chown all of them.
+func (s *nodeServerNoLocked) setVolumeOwnership(ctx context.Context, req *csi.NodePublishVolumeRequest, fsType string) error {
+	group := req.GetVolumeCapability().GetMount().GetVolumeMountGroup()
+	if group == "" {
+		return nil
+	}
+	gid, err := strconv.ParseInt(group, 10, 32)
+	if err != nil || gid < 0 {
+		return status.Errorf(codes.InvalidArgument, "invalid volume mount group: volume=%s, group=%s", req.GetVolumeId(), group)
+	}
+
+	var drv storagev1.CSIDriver
+	err = s.k8sReader.Get(ctx, types.NamespacedName{Name: topolvm.GetPluginName()}, &drv)
+	switch {
+	case err == nil:
+	case apierrors.IsNotFound(err):
+		// kubelet works without the CSIDriver, applying the default policy.
+	default:
+		return status.Errorf(codes.Internal, "failed to get CSIDriver %s: %v", topolvm.GetPluginName(), err)
+	}
+	if !appliesFSGroup(drv.Spec.FSGroupPolicy, fsType, req.GetReadonly()) {
+		return nil
+	}
+
+	policy, err := fsGroupChangePolicy(req.GetVolumeContext())
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "volume=%s, error=%v", req.GetVolumeId(), err)
+	}
+	if policy != corev1.FSGroupChangeAlways {
+		needed, err := filesystem.OwnershipNeedsChange(req.GetTargetPath(), gid)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to check volume ownership: target=%s, error=%v", req.GetTargetPath(), err)
+		}
+		if !needed {
+			return nil
+		}
+	}
+
+	start := time.Now()
+	changed, err := filesystem.SetVolumeOwnership(req.GetTargetPath(), gid)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to set volume ownership: volume=%s, gid=%d, error=%v", req.GetVolumeId(), gid, err)
+	}
+	nodeLogger.Info("volume ownership set",
+		"volume_id", req.GetVolumeId(),
+		"gid", gid,
+		"files_changed", changed,
+		"duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,36 @@
+package driver
+
+import (
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+)
+
+func TestAppliesFSGroup(t *testing.T) {
+	file := storagev1.FileFSGroupPolicy
+	none := storagev1.NoneFSGroupPolicy
+	rwo := storagev1.ReadWriteOnceWithFSTypeFSGroupPolicy
+
+	cases := []struct {
+		name     string
+		policy   *storagev1.FSGroupPolicy
+		fsType   string
+		readOnly bool
+		want     bool
+	}{
+		{"default", nil, "xfs", false, true},
+		{"default without fsType", nil, "", false, false},
+		{"ReadWriteOnceWithFSType", &rwo, "ext4", false, true},
+		{"ReadWriteOnceWithFSType without fsType", &rwo, "", false, false},
+		{"File without fsType", &file, "", false, true},
+		{"None", &none, "xfs", false, false},
+		{"read-only", &file, "xfs", true, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := appliesFSGroup(tc.policy, tc.fsType, tc.readOnly); got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
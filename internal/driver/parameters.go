@@ -0,0 +1,76 @@
+package driver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/filesystem"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+// FilesystemTypeKey is the parameter of StorageClasses passing the filesystem type to CreateVolume.
+const FilesystemTypeKey = "csi.storage.k8s.io/fstype"
+
+// ValidateParameters checks the values of the TopoLVM parameters of CreateVolume, i.e. of a StorageClass.
+// fsType is the filesystem type of the volume, or an empty string for block volumes, which skips the filesystem parameters.
+// The parameters referring to the nodes, e.g. the device-class, are checked on provisioning.
+func ValidateParameters(parameters map[string]string, fsType string) error {
+	switch v := parameters[topolvm.GetProvisioningTypeKey()]; v {
+	case "", "thick", "thin":
+	default:
+		return fmt.Errorf("invalid provisioning type: %s", v)
+	}
+	switch v := parameters[topolvm.GetEncryptionKey()]; v {
+	case "", encryptionLUKS:
+	default:
+		return fmt.Errorf("unsupported encryption: %s", v)
+	}
+	switch v := parameters[topolvm.GetColocationKey()]; v {
+	case "", colocationPreferred, colocationRequired:
+	default:
+		return fmt.Errorf("invalid colocation: %s", v)
+	}
+	switch v := parameters[topolvm.GetWipeOnDeleteKey()]; lvmdTypes.WipePolicy(v) {
+	case "", lvmdTypes.WipeNone, lvmdTypes.WipeDiscard, lvmdTypes.WipeZero:
+	default:
+		return fmt.Errorf("invalid wipe-on-delete: %s", v)
+	}
+	if v := parameters[topolvm.GetSnapshotBeforeExpandKey()]; v != "" {
+		if retention, err := time.ParseDuration(v); err != nil || retention <= 0 {
+			return fmt.Errorf("invalid %s: %s", topolvm.GetSnapshotBeforeExpandKey(), v)
+		}
+	}
+	if len(strings.Fields(parameters[topolvm.GetLvcreateOptionsKey()])) > 0 && parameters[topolvm.GetLvcreateOptionClassKey()] == "" {
+		return fmt.Errorf("%s requires %s", topolvm.GetLvcreateOptionsKey(), topolvm.GetLvcreateOptionClassKey())
+	}
+	if v := parameters[topolvm.GetWarmUpKey()]; v != "" {
+		if namespace, name, ok := strings.Cut(v, "/"); !ok || namespace == "" || name == "" {
+			return fmt.Errorf("invalid %s: %s, must be NAMESPACE/NAME of a ConfigMap", topolvm.GetWarmUpKey(), v)
+		}
+	}
+	if _, err := fsGroupChangePolicy(parameters); err != nil {
+		return err
+	}
+	if v, ok := parameters[topolvm.GetDefaultMountFlagsKey()]; ok {
+		if _, err := ParseDefaultMountFlags(v); err != nil {
+			return fmt.Errorf("invalid %s: %v", topolvm.GetDefaultMountFlagsKey(), err)
+		}
+	}
+
+	// the PVC name and namespace in the label are known only on provisioning, so only the support of labels is checked.
+	if parameters[topolvm.GetFilesystemLabelKey()] != "" && fsType != "" && filesystem.MaxLabelLength(fsType) == 0 {
+		return fmt.Errorf("setting the label of %s is not supported", fsType)
+	}
+	if _, err := mkfsOptions(parameters, fsType); err != nil {
+		return err
+	}
+	if _, err := useDeterministicUUID(parameters, fsType); err != nil {
+		return err
+	}
+	if _, err := useProjectQuota(parameters, fsType); err != nil {
+		return err
+	}
+	return nil
+}
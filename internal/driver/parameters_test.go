@@ -0,0 +1,121 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/topolvm/topolvm"
+)
+
+func TestValidateParameters(t *testing.T) {
+	testCases := []struct {
+		name       string
+		parameters map[string]string
+		fsType     string
+		wantErr    bool
+	}{
+		{
+			name:       "no parameters",
+			parameters: map[string]string{},
+			fsType:     "ext4",
+		},
+		{
+			name: "valid parameters",
+			parameters: map[string]string{
+				topolvm.GetDeviceClassKey():          "ssd",
+				topolvm.GetProvisioningTypeKey():     "thin",
+				topolvm.GetEncryptionKey():           "luks",
+				topolvm.GetColocationKey():           "required",
+				topolvm.GetWipeOnDeleteKey():         "discard",
+				topolvm.GetSnapshotBeforeExpandKey(): "24h",
+				topolvm.GetLvcreateOptionClassKey():  "raid1",
+				topolvm.GetLvcreateOptionsKey():      "--stripes 2",
+				topolvm.GetDefaultMountFlagsKey():    "none",
+				topolvm.GetFilesystemLabelKey():      "${pvc.name}",
+				topolvm.GetFilesystemUUIDKey():       "deterministic",
+				topolvm.GetProjectQuotaKey():         "true",
+				topolvm.GetMkfsOptionsKey():          "-m reflink=1",
+				topolvm.GetWarmUpKey():               "topolvm-system/seed",
+				topolvm.GetFSGroupChangePolicyKey():  "Always",
+			},
+			fsType: "xfs",
+		},
+		{
+			name:       "invalid provisioning type",
+			parameters: map[string]string{topolvm.GetProvisioningTypeKey(): "thinner"},
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported encryption",
+			parameters: map[string]string{topolvm.GetEncryptionKey(): "aes"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid colocation",
+			parameters: map[string]string{topolvm.GetColocationKey(): "always"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid wipe-on-delete",
+			parameters: map[string]string{topolvm.GetWipeOnDeleteKey(): "shred"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid snapshot retention",
+			parameters: map[string]string{topolvm.GetSnapshotBeforeExpandKey(): "1 day"},
+			wantErr:    true,
+		},
+		{
+			name:       "lvcreate options without a class",
+			parameters: map[string]string{topolvm.GetLvcreateOptionsKey(): "--stripes 2"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid default mount flags",
+			parameters: map[string]string{topolvm.GetDefaultMountFlagsKey(): "ro"},
+			wantErr:    true,
+		},
+		{
+			name:       "label of an unsupported filesystem",
+			parameters: map[string]string{topolvm.GetFilesystemLabelKey(): "data"},
+			fsType:     "vfat",
+			wantErr:    true,
+		},
+		{
+			name:       "project quota of ext4",
+			parameters: map[string]string{topolvm.GetProjectQuotaKey(): "true"},
+			fsType:     "ext4",
+			wantErr:    true,
+		},
+		{
+			name:       "invalid fsgroup-change-policy",
+			parameters: map[string]string{topolvm.GetFSGroupChangePolicyKey(): "Never"},
+			wantErr:    true,
+		},
+		{
+			name:       "warm-up without namespace",
+			parameters: map[string]string{topolvm.GetWarmUpKey(): "seed"},
+			wantErr:    true,
+		},
+		{
+			name:       "mkfs options naming a device",
+			parameters: map[string]string{topolvm.GetFilesystemMkfsOptionsKey("ext4"): "-O bigalloc /dev/sda"},
+			fsType:     "ext4",
+			wantErr:    true,
+		},
+		{
+			name:       "filesystem parameters of block volumes",
+			parameters: map[string]string{topolvm.GetFilesystemLabelKey(): "data", topolvm.GetProjectQuotaKey(): "true"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateParameters(tc.parameters, tc.fsType)
+			if tc.wantErr && err == nil {
+				t.Error("expected an error")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
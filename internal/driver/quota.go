@@ -0,0 +1,220 @@
+package driver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	mountutil "k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+)
+
+const (
+	xfsQuotaCmd = "/usr/sbin/xfs_quota"
+
+	// ProjectQuotaAPIPath is the path prefix of the HTTP API served by the handler of NewProjectQuotaAPI.
+	ProjectQuotaAPIPath = "/quota/v1/volumes/"
+
+	// maxProjectID is the largest XFS project ID. 4294967295 is reserved as an invalid ID.
+	maxProjectID = 1<<32 - 2
+)
+
+// projectQuotaMountOptions are the mount options which enable the XFS project quota.
+var projectQuotaMountOptions = []string{"prjquota", "pquota", "pqnoenforce"}
+
+// quotaPathPattern restricts the directories of the project quota API to simple relative paths,
+// which are passed to xfs_quota within its command string.
+var quotaPathPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// hasProjectQuota reports whether mountOptions enable the project quota.
+func hasProjectQuota(mountOptions []string) bool {
+	for _, option := range projectQuotaMountOptions {
+		if hasMountFlag(mountOptions, option) {
+			return true
+		}
+	}
+	return false
+}
+
+// withProjectQuota adds prjquota to mountOptions unless they already enable the project quota.
+func withProjectQuota(mountOptions []string) []string {
+	if hasProjectQuota(mountOptions) {
+		return mountOptions
+	}
+	return append(mountOptions, projectQuotaMountOptions[0])
+}
+
+// QuotaDirectory is a directory inside a volume whose usage is limited by an XFS project quota.
+type QuotaDirectory struct {
+	// Path is the path of the directory relative to the root of the volume.
+	Path string `json:"path"`
+	// ProjectID is the XFS project ID assigned to the directory and inherited by its contents.
+	ProjectID uint32 `json:"projectID"`
+	// LimitBytes is the hard limit of the blocks used by the project. 0 means no limit.
+	LimitBytes int64 `json:"limitBytes"`
+}
+
+func (d QuotaDirectory) validate() error {
+	if !quotaPathPattern.MatchString(d.Path) {
+		return fmt.Errorf("invalid path %q: must be a relative path of letters, digits, '_', '.' and '-'", d.Path)
+	}
+	for _, elem := range strings.Split(d.Path, "/") {
+		if elem == "." || elem == ".." {
+			return fmt.Errorf("invalid path %q: must not contain %q", d.Path, elem)
+		}
+	}
+	if d.ProjectID == 0 || d.ProjectID > maxProjectID {
+		return fmt.Errorf("invalid projectID %d: must be between 1 and %d", d.ProjectID, uint32(maxProjectID))
+	}
+	if d.LimitBytes < 0 {
+		return fmt.Errorf("invalid limitBytes %d", d.LimitBytes)
+	}
+	return nil
+}
+
+// projectQuota assigns XFS project quotas to directories with xfs_quota.
+type projectQuota struct {
+	exec utilexec.Interface
+}
+
+// setup assigns projectID to dir and its contents inside the filesystem mounted at mountPath and limits the project.
+// Both steps are idempotent.
+func (q projectQuota) setup(mountPath, dir string, projectID uint32, limitBytes int64) error {
+	for _, command := range []string{
+		fmt.Sprintf("project -s -p %s %d", dir, projectID),
+		fmt.Sprintf("limit -p bhard=%d %d", limitBytes, projectID),
+	} {
+		out, err := q.exec.Command(xfsQuotaCmd, "-x", "-c", command, mountPath).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("xfs_quota failed: command=%q, output=%s, error=%w", command, string(out), err)
+		}
+	}
+	return nil
+}
+
+// mkdirInside creates path relative to root and its parents. Existing elements must be directories,
+// so that symbolic links created by the workload cannot redirect the directory outside the volume.
+func mkdirInside(root, path string) (string, error) {
+	dir := root
+	for _, elem := range strings.Split(path, "/") {
+		dir = filepath.Join(dir, elem)
+		fi, err := os.Lstat(dir)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			if err := os.Mkdir(dir, 0755); err != nil {
+				return "", err
+			}
+			// the same permissions as the root of the volume set by NodePublishVolume.
+			if err := os.Chmod(dir, 0777|os.ModeSetgid); err != nil {
+				return "", err
+			}
+		case err != nil:
+			return "", err
+		case !fi.IsDir():
+			return "", fmt.Errorf("%s exists and is not a directory", dir)
+		}
+	}
+	return dir, nil
+}
+
+type projectQuotaAPI struct {
+	mounter mountutil.Interface
+	quota   projectQuota
+}
+
+// NewProjectQuotaAPI returns an HTTP API creating directories limited by XFS project quotas inside the volumes
+// mounted on the node with the project-quota parameter, so that a single PVC can be sub-allocated,
+// e.g. to the builds of a nested container runtime:
+//
+//   - PUT /quota/v1/volumes/VOLUME_ID/directories with QuotaDirectory creates the directory if needed,
+//     assigns the project ID to it and sets the limit of the project.
+//
+// The volume must be published by NodePublishVolume on the node.
+func NewProjectQuotaAPI(strategy MountStrategy) (http.Handler, error) {
+	mounter, err := newMounter(strategy)
+	if err != nil {
+		return nil, err
+	}
+	return projectQuotaAPI{mounter: mounter.Interface, quota: projectQuota{exec: mounter.Exec}}, nil
+}
+
+func (a projectQuotaAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	volumeID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, ProjectQuotaAPIPath), "/directories")
+	if !ok || volumeID == "" || strings.Contains(volumeID, "/") {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != http.MethodPut {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var dir QuotaDirectory
+	if err := json.NewDecoder(r.Body).Decode(&dir); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := dir.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mountPath, quota, err := a.findMount(volumeID)
+	switch {
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	case mountPath == "":
+		http.Error(w, "volume is not mounted on the node", http.StatusNotFound)
+		return
+	case !quota:
+		http.Error(w, "project quota is not enabled for the volume, set "+topolvm.GetProjectQuotaKey()+" in the StorageClass", http.StatusConflict)
+		return
+	}
+
+	path, err := mkdirInside(mountPath, dir.Path)
+	if err != nil {
+		http.Error(w, "failed to create the directory: "+err.Error(), http.StatusConflict)
+		return
+	}
+	if err := a.quota.setup(mountPath, path, dir.ProjectID, dir.LimitBytes); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	nodeLogger.Info("project quota directory set up",
+		"volume_id", volumeID,
+		"path", dir.Path,
+		"project_id", dir.ProjectID,
+		"limit_bytes", dir.LimitBytes)
+	writeJSON(w, dir)
+}
+
+// findMount returns a path where the filesystem of the volume is mounted, preferring a mount with project quota.
+// It returns an empty path if the volume is not mounted.
+func (a projectQuotaAPI) findMount(volumeID string) (string, bool, error) {
+	mounts, err := a.mounter.List()
+	if err != nil {
+		return "", false, err
+	}
+	devices := []string{
+		filepath.Join(topolvm.DeviceDirectory, volumeID),
+		filepath.Join(topolvm.DeviceDirectory, luksMapperName(volumeID)),
+	}
+	var found string
+	for _, m := range mounts {
+		if m.Type != "xfs" || !hasMountFlag(devices, m.Device) {
+			continue
+		}
+		if hasProjectQuota(m.Opts) {
+			return m.Path, true, nil
+		}
+		found = m.Path
+	}
+	return found, false, nil
+}
@@ -0,0 +1,139 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	mountutil "k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
+	fakeexec "k8s.io/utils/exec/testing"
+)
+
+func TestWithProjectQuota(t *testing.T) {
+	if options := withProjectQuota([]string{"noatime", "nouuid"}); !reflect.DeepEqual(options, []string{"noatime", "nouuid", "prjquota"}) {
+		t.Errorf("unexpected options: %v", options)
+	}
+	if options := withProjectQuota([]string{"pqnoenforce"}); !reflect.DeepEqual(options, []string{"pqnoenforce"}) {
+		t.Errorf("the project quota option of the StorageClass should be kept: %v", options)
+	}
+}
+
+func TestQuotaDirectoryValidate(t *testing.T) {
+	valid := []QuotaDirectory{
+		{Path: "builds", ProjectID: 1},
+		{Path: "builds/job-1.2_a", ProjectID: maxProjectID, LimitBytes: 1 << 30},
+	}
+	for _, d := range valid {
+		if err := d.validate(); err != nil {
+			t.Errorf("%+v: unexpected error: %v", d, err)
+		}
+	}
+	invalid := []QuotaDirectory{
+		{Path: "", ProjectID: 1},
+		{Path: "/builds", ProjectID: 1},
+		{Path: "builds/../..", ProjectID: 1},
+		{Path: "builds/", ProjectID: 1},
+		{Path: "my builds", ProjectID: 1},
+		{Path: "builds", ProjectID: 0},
+		{Path: "builds", ProjectID: 1, LimitBytes: -1},
+	}
+	for _, d := range invalid {
+		if err := d.validate(); err == nil {
+			t.Errorf("%+v: error should happen", d)
+		}
+	}
+}
+
+func TestMkdirInside(t *testing.T) {
+	root := t.TempDir()
+	dir, err := mkdirInside(root, "a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() || fi.Mode()&os.ModeSetgid == 0 {
+		t.Errorf("unexpected directory %s: %v, %v", dir, fi, err)
+	}
+	if _, err := mkdirInside(root, "a/b"); err != nil {
+		t.Errorf("existing directories should be accepted: %v", err)
+	}
+
+	if err := os.Symlink(t.TempDir(), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mkdirInside(root, "link/c"); err == nil {
+		t.Error("symbolic links should not be followed")
+	}
+}
+
+func TestProjectQuotaAPI(t *testing.T) {
+	root := t.TempDir()
+	var commands [][]string
+	var cmd fakeexec.FakeCmd
+	for i := 0; i < 2; i++ {
+		cmd.CombinedOutputScript = append(cmd.CombinedOutputScript, func() ([]byte, []byte, error) { return nil, nil, nil })
+	}
+	fake := &fakeexec.FakeExec{}
+	for i := 0; i < 2; i++ {
+		fake.CommandScript = append(fake.CommandScript, func(name string, args ...string) utilexec.Cmd {
+			commands = append(commands, append([]string{name}, args...))
+			return fakeexec.InitFakeCmd(&cmd, name, args...)
+		})
+	}
+	api := projectQuotaAPI{
+		mounter: mountutil.NewFakeMounter([]mountutil.MountPoint{
+			{Device: filepath.Join(topolvm.DeviceDirectory, "vol1"), Path: root, Type: "xfs", Opts: []string{"rw", "prjquota"}},
+			{Device: filepath.Join(topolvm.DeviceDirectory, "vol2"), Path: t.TempDir(), Type: "xfs", Opts: []string{"rw"}},
+		}),
+		quota: projectQuota{exec: fake},
+	}
+	put := func(url string, dir QuotaDirectory) *httptest.ResponseRecorder {
+		body, err := json.Marshal(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, httptest.NewRequest(http.MethodPut, url, bytes.NewReader(body)))
+		return w
+	}
+
+	dir := QuotaDirectory{Path: "builds/1", ProjectID: 1001, LimitBytes: 1 << 30}
+	if w := put(ProjectQuotaAPIPath+"vol1/directories", dir); w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d: %s", w.Code, w.Body.String())
+	}
+	path := filepath.Join(root, "builds", "1")
+	expected := [][]string{
+		{xfsQuotaCmd, "-x", "-c", "project -s -p " + path + " 1001", root},
+		{xfsQuotaCmd, "-x", "-c", "limit -p bhard=1073741824 1001", root},
+	}
+	if !reflect.DeepEqual(commands, expected) {
+		t.Errorf("unexpected commands: %v", commands)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("the directory should be created: %v", err)
+	}
+
+	for url, code := range map[string]int{
+		ProjectQuotaAPIPath + "vol2/directories":    http.StatusConflict,
+		ProjectQuotaAPIPath + "unknown/directories": http.StatusNotFound,
+		ProjectQuotaAPIPath + "vol1":                http.StatusNotFound,
+	} {
+		if w := put(url, dir); w.Code != code {
+			t.Errorf("%s: expected %d, got %d", url, code, w.Code)
+		}
+	}
+	if w := put(ProjectQuotaAPIPath+"vol1/directories", QuotaDirectory{Path: "../x", ProjectID: 1}); w.Code != http.StatusBadRequest {
+		t.Errorf("expected bad request, got %d", w.Code)
+	}
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, ProjectQuotaAPIPath+"vol1/directories", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,94 @@
+package driver
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+// verifyFileName is the name of the file written by verifyWrites in the root of a filesystem.
+const verifyFileName = ".topolvm-verify"
+
+// verifyBlockSize is the size of the data written by verifyWrites, which is aligned for O_DIRECT.
+const verifyBlockSize = 4096
+
+// verifyWrites writes a block of random data to a file in dir, syncs it to the device and reads it back
+// bypassing the page cache, so that a failing device is detected before a workload writes to it.
+// The file is removed afterwards.
+func verifyWrites(dir string) error {
+	// anonymous mappings are page aligned as required by O_DIRECT.
+	buf, err := unix.Mmap(-1, 0, verifyBlockSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = unix.Munmap(buf) }()
+	data := make([]byte, verifyBlockSize)
+	if _, err := rand.Read(data); err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, verifyFileName)
+	defer func() { _ = os.Remove(path) }()
+
+	copy(buf, data)
+	if err := writeSynced(path, buf); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+	if err := readUncached(path, buf); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if !bytes.Equal(buf, data) {
+		return fmt.Errorf("data read back from %s differs from the data written", path)
+	}
+	return nil
+}
+
+// openDirect opens the file with O_DIRECT, or without it if the filesystem does not support it.
+func openDirect(path string, flag int) (*os.File, bool, error) {
+	f, err := os.OpenFile(path, flag|unix.O_DIRECT, 0600)
+	if errors.Is(err, unix.EINVAL) {
+		f, err = os.OpenFile(path, flag, 0600)
+		return f, false, err
+	}
+	return f, true, err
+}
+
+func writeSynced(path string, buf []byte) error {
+	f, _, err := openDirect(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readUncached(path string, buf []byte) error {
+	f, direct, err := openDirect(path, os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if !direct {
+		// the data has been synced, so the cached pages can be dropped to read it from the device.
+		if err := unix.Fadvise(int(f.Fd()), 0, 0, unix.FADV_DONTNEED); err != nil {
+			return err
+		}
+	}
+	_, err = f.ReadAt(buf, 0)
+	return err
+}
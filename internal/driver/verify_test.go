@@ -0,0 +1,20 @@
+package driver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyWrites(t *testing.T) {
+	dir := t.TempDir()
+	if err := verifyWrites(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, verifyFileName)); !os.IsNotExist(err) {
+		t.Errorf("the verification file should be removed: %v", err)
+	}
+	if err := verifyWrites(filepath.Join(dir, "missing")); err == nil {
+		t.Error("verification in a missing directory should fail")
+	}
+}
@@ -0,0 +1,39 @@
+package driver
+
+import (
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	v1 "github.com/topolvm/topolvm/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podNameKey is added to the volume context of NodePublishVolume by kubelet as podInfoOnMount of the CSIDriver is true.
+const podNameKey = "csi.storage.k8s.io/pod.name"
+
+// checkWarmedUp returns an error unless the volume provisioned with the warm-up parameter may be published to the pod,
+// i.e. the pod belongs to the warm-up Job or the Job has succeeded. kubelet retries NodePublishVolume meanwhile.
+func checkWarmedUp(lv *v1.LogicalVolume, volumeContext map[string]string) error {
+	if volumeContext[topolvm.GetWarmUpKey()] == "" {
+		return nil
+	}
+	// the pods of a Job are named after the Job.
+	if strings.HasPrefix(volumeContext[podNameKey], topolvm.WarmUpJobNamePrefix+lv.Name+"-") {
+		return nil
+	}
+
+	cond := meta.FindStatusCondition(lv.Status.Conditions, v1.ConditionWarmedUp)
+	switch {
+	case cond == nil:
+		return status.Errorf(codes.Unavailable, "volume %s is waiting for its warm-up Job", lv.Status.VolumeID)
+	case cond.Status == metav1.ConditionTrue:
+		return nil
+	case cond.Reason == v1.WarmUpReasonFailed, cond.Reason == v1.WarmUpReasonInvalidTemplate:
+		return status.Errorf(codes.FailedPrecondition, "warm-up of volume %s failed: %s", lv.Status.VolumeID, cond.Message)
+	default:
+		return status.Errorf(codes.Unavailable, "volume %s is being warmed up: %s", lv.Status.VolumeID, cond.Message)
+	}
+}
@@ -0,0 +1,82 @@
+package driver
+
+import (
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	v1 "github.com/topolvm/topolvm/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCheckWarmedUp(t *testing.T) {
+	volume := func(status metav1.ConditionStatus, reason string) *v1.LogicalVolume {
+		lv := &v1.LogicalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+		if reason != "" {
+			lv.Status.Conditions = []metav1.Condition{{Type: v1.ConditionWarmedUp, Status: status, Reason: reason}}
+		}
+		return lv
+	}
+	warmUp := map[string]string{topolvm.GetWarmUpKey(): "topolvm-system/seed", podNameKey: "app-0"}
+
+	testCases := []struct {
+		name          string
+		lv            *v1.LogicalVolume
+		volumeContext map[string]string
+		expected      codes.Code
+	}{
+		{
+			name:          "no warm-up",
+			lv:            volume("", ""),
+			volumeContext: map[string]string{podNameKey: "app-0"},
+			expected:      codes.OK,
+		},
+		{
+			name:          "not started",
+			lv:            volume("", ""),
+			volumeContext: warmUp,
+			expected:      codes.Unavailable,
+		},
+		{
+			name:          "running",
+			lv:            volume(metav1.ConditionFalse, v1.WarmUpReasonRunning),
+			volumeContext: warmUp,
+			expected:      codes.Unavailable,
+		},
+		{
+			name:          "succeeded",
+			lv:            volume(metav1.ConditionTrue, v1.WarmUpReasonSucceeded),
+			volumeContext: warmUp,
+			expected:      codes.OK,
+		},
+		{
+			name:          "failed",
+			lv:            volume(metav1.ConditionFalse, v1.WarmUpReasonFailed),
+			volumeContext: warmUp,
+			expected:      codes.FailedPrecondition,
+		},
+		{
+			name:          "invalid template",
+			lv:            volume(metav1.ConditionFalse, v1.WarmUpReasonInvalidTemplate),
+			volumeContext: warmUp,
+			expected:      codes.FailedPrecondition,
+		},
+		{
+			name: "pod of the warm-up Job",
+			lv:   volume(metav1.ConditionFalse, v1.WarmUpReasonRunning),
+			volumeContext: map[string]string{
+				topolvm.GetWarmUpKey(): "topolvm-system/seed",
+				podNameKey:             topolvm.WarmUpJobNamePrefix + "pvc-1-x7k2p",
+			},
+			expected: codes.OK,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := status.Code(checkWarmedUp(tc.lv, tc.volumeContext)); code != tc.expected {
+				t.Errorf("expected %s, got %s", tc.expected, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+// Package explain reports the effective configuration of the binaries of TopoLVM and where each setting comes from,
+// so that operators can verify the settings actually in effect.
+package explain
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+// Path is the path of the HTTP endpoint serving the effective configuration.
+const Path = "/debug/config"
+
+// Source is where the value of a setting comes from.
+type Source string
+
+const (
+	// SourceDefault is the built-in default of the setting.
+	SourceDefault Source = "default"
+	// SourceFlag is the command-line flag given.
+	SourceFlag Source = "flag"
+	// SourceFile is the configuration file given.
+	SourceFile Source = "file"
+)
+
+// commandFlags are the flags added by cobra to print the help or the version, which are not settings.
+var commandFlags = map[string]bool{"help": true, "version": true}
+
+// Setting is the effective value of a setting and its provenance.
+type Setting struct {
+	Value  any    `json:"value"`
+	Source Source `json:"source"`
+	// Default is the built-in default overridden by the flag, if any.
+	Default *string `json:"default,omitempty"`
+	// File is the path of the configuration file of the setting, if any.
+	File string `json:"file,omitempty"`
+}
+
+// Config returns the effective settings of the flags of fs by the names of the flags,
+// along with the settings of the configuration files, if any. It must be called after fs is parsed.
+func Config(fs *pflag.FlagSet, files ...map[string]Setting) map[string]Setting {
+	settings := make(map[string]Setting)
+	for _, file := range files {
+		for name, setting := range file {
+			settings[name] = setting
+		}
+	}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if commandFlags[f.Name] {
+			return
+		}
+		setting := Setting{Value: flagValue(fs, f), Source: SourceDefault}
+		if f.Changed {
+			defValue := f.DefValue
+			setting.Source, setting.Default = SourceFlag, &defValue
+		}
+		settings[f.Name] = setting
+	})
+	return settings
+}
+
+// File returns the settings of the configuration file at path decoded into config by the top-level keys.
+func File(path string, config any) (map[string]Setting, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]Setting, len(values))
+	for name, value := range values {
+		settings[name] = Setting{Value: value, Source: SourceFile, File: path}
+	}
+	return settings, nil
+}
+
+// flagValue returns the value of f as a YAML scalar, list or map of the type of the flag.
+func flagValue(fs *pflag.FlagSet, f *pflag.Flag) any {
+	if slice, ok := f.Value.(pflag.SliceValue); ok {
+		return slice.GetSlice()
+	}
+	value := f.Value.String()
+	switch f.Value.Type() {
+	case "bool":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "float32", "float64":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "stringToString":
+		if m, err := fs.GetStringToString(f.Name); err == nil {
+			return m
+		}
+	}
+	return value
+}
+
+// Write writes the effective settings of the flags of fs and the configuration files in YAML sorted by the names.
+func Write(w io.Writer, fs *pflag.FlagSet, files ...map[string]Setting) error {
+	data, err := yaml.Marshal(Config(fs, files...))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// Handler returns an HTTP handler serving the effective settings of the flags of fs and the configuration files in YAML.
+func Handler(fs *pflag.FlagSet, files ...map[string]Setting) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/yaml")
+		_ = Write(w, fs, files...)
+	})
+}
@@ -0,0 +1,109 @@
+package explain
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func testFlagSet(t *testing.T, args ...string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("socket", "/run/topolvm.sock", "")
+	fs.Bool("enabled", true, "")
+	fs.Int("workers", 0, "")
+	fs.Float64("percent", 80, "")
+	fs.Duration("ttl", time.Hour, "")
+	fs.StringArray("rollout", nil, "")
+	fs.StringToString("labels", nil, "")
+	fs.Bool("help", false, "")
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestConfig(t *testing.T) {
+	config := Config(testFlagSet(t, "--workers=4", "--rollout=a", "--rollout=b", "--labels=k=v", "--ttl=5m"))
+
+	defValue := func(v string) *string { return &v }
+	expected := map[string]Setting{
+		"socket":  {Value: "/run/topolvm.sock", Source: SourceDefault},
+		"enabled": {Value: true, Source: SourceDefault},
+		"workers": {Value: int64(4), Source: SourceFlag, Default: defValue("0")},
+		"percent": {Value: float64(80), Source: SourceDefault},
+		"ttl":     {Value: "5m0s", Source: SourceFlag, Default: defValue("1h0m0s")},
+		"rollout": {Value: []string{"a", "b"}, Source: SourceFlag, Default: defValue("[]")},
+		"labels":  {Value: map[string]string{"k": "v"}, Source: SourceFlag, Default: defValue("[]")},
+	}
+	if !reflect.DeepEqual(config, expected) {
+		t.Errorf("unexpected config:\n%#v\nexpected:\n%#v", config, expected)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	handler := Handler(testFlagSet(t, "--socket=/tmp/csi.sock"))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, Path, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	expected := `enabled:
+  source: default
+  value: true
+labels:
+  source: default
+  value: {}
+percent:
+  source: default
+  value: 80
+rollout:
+  source: default
+  value: []
+socket:
+  default: /run/topolvm.sock
+  source: flag
+  value: /tmp/csi.sock
+ttl:
+  source: default
+  value: 1h0m0s
+workers:
+  source: default
+  value: 0
+`
+	if w.Body.String() != expected {
+		t.Errorf("unexpected output:\n%s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, Path, nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed, got %d", w.Code)
+	}
+}
+
+func TestFile(t *testing.T) {
+	type fileConfig struct {
+		Allocation map[string]string `json:"allocation"`
+		Policy     string            `json:"policy,omitempty"`
+	}
+	file, err := File("/etc/topolvm/config.yaml", fileConfig{Allocation: map[string]string{"block": "1Gi"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := Config(testFlagSet(t), file)
+	expected := Setting{Value: map[string]any{"block": "1Gi"}, Source: SourceFile, File: "/etc/topolvm/config.yaml"}
+	if !reflect.DeepEqual(config["allocation"], expected) {
+		t.Errorf("unexpected setting of the file: %#v", config["allocation"])
+	}
+	if _, ok := config["policy"]; ok {
+		t.Error("expected the settings omitted in the file not to be reported")
+	}
+	if config["socket"].Source != SourceDefault {
+		t.Errorf("expected the flags to be reported with the file: %#v", config["socket"])
+	}
+}
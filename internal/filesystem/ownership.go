@@ -0,0 +1,82 @@
+package filesystem
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+const (
+	// the permissions added for the group like kubelet does for fsGroup.
+	groupRWMask   = 0660
+	groupExecMask = 0110
+)
+
+// OwnershipNeedsChange reports whether dir, the root of a volume, lacks the group gid or the permissions of the group
+// set by SetVolumeOwnership. It is the check of the OnRootMismatch fsGroupChangePolicy, which assumes the files
+// under the root have the same ownership as the root.
+func OwnershipNeedsChange(dir string, gid int64) (bool, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return false, err
+	}
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return true, nil
+	}
+	required := groupRWMask | groupExecMask | os.ModeSetgid
+	return int64(stat.Gid) != gid || info.Mode()&required != required, nil
+}
+
+// SetVolumeOwnership sets the group of the files under dir to gid and allows the group to read and write them,
+// as kubelet does for the fsGroup of a pod. The directories get the setgid bit so that new files inherit the group.
+// Symbolic links are not followed, and their permissions are left as is.
+// It returns the number of the files changed.
+func SetVolumeOwnership(dir string, gid int64) (int, error) {
+	changed := 0
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		ok, err := setOwnership(path, info, gid)
+		if ok {
+			changed++
+		}
+		return err
+	})
+	return changed, err
+}
+
+func setOwnership(path string, info fs.FileInfo, gid int64) (bool, error) {
+	changed := false
+	if stat, ok := info.Sys().(*syscall.Stat_t); !ok || int64(stat.Gid) != gid {
+		if err := os.Lchown(path, -1, int(gid)); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	// chmod of a symbolic link changes the file it refers to, which may be outside of the volume.
+	if info.Mode()&os.ModeSymlink != 0 {
+		return changed, nil
+	}
+
+	mask := fs.FileMode(groupRWMask)
+	if info.IsDir() {
+		mask |= groupExecMask | os.ModeSetgid
+	} else if info.Mode()&groupExecMask != 0 {
+		mask |= groupExecMask
+	}
+	// chown clears the setuid and setgid bits of the files, so they are restored from the mode read before.
+	if !changed && info.Mode()&mask == mask {
+		return false, nil
+	}
+	if err := os.Chmod(path, info.Mode()|mask); err != nil {
+		return changed, err
+	}
+	return true, nil
+}
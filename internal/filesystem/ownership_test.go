@@ -0,0 +1,98 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestSetVolumeOwnership(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("run as root")
+	}
+	const gid = 2000
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sub := filepath.Join(dir, "sub")
+	file := filepath.Join(sub, "file")
+	script := filepath.Join(dir, "script")
+	link := filepath.Join(dir, "link")
+	if err := os.Mkdir(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(script, nil, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("/etc/hostname", link); err != nil {
+		t.Fatal(err)
+	}
+
+	needed, err := OwnershipNeedsChange(dir, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !needed {
+		t.Error("expected the ownership to need a change")
+	}
+
+	changed, err := SetVolumeOwnership(dir, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 5 {
+		t.Errorf("expected 5 files changed, got %d", changed)
+	}
+	for path, mode := range map[string]os.FileMode{
+		dir:    0775 | os.ModeSetgid,
+		sub:    0770 | os.ModeSetgid,
+		file:   0660,
+		script: 0770,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()|info.Mode()&os.ModeSetgid != mode {
+			t.Errorf("unexpected mode of %s: %s", path, info.Mode())
+		}
+		if g := info.Sys().(*syscall.Stat_t).Gid; g != gid {
+			t.Errorf("unexpected group of %s: %d", path, g)
+		}
+	}
+	info, err := os.Lstat(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := info.Sys().(*syscall.Stat_t).Gid; g != gid {
+		t.Errorf("unexpected group of the link: %d", g)
+	}
+	target, err := os.Stat("/etc/hostname")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := target.Sys().(*syscall.Stat_t).Gid; g == gid {
+		t.Error("the target of the link must not be changed")
+	}
+
+	needed, err = OwnershipNeedsChange(dir, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if needed {
+		t.Error("expected the ownership to need no change")
+	}
+	changed, err = SetVolumeOwnership(dir, gid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 0 {
+		t.Errorf("expected no file changed, got %d", changed)
+	}
+}
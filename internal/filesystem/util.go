@@ -92,3 +92,63 @@ func Statfs(path string, buf *unix.Statfs_t) (err error) {
 		return err
 	}
 }
+
+// maxLabelLength is the maximum length of a filesystem label in bytes.
+var maxLabelLength = map[string]int{
+	"ext2":  16,
+	"ext3":  16,
+	"ext4":  16,
+	"xfs":   12,
+	"btrfs": 255,
+}
+
+// MaxLabelLength returns the maximum length of the label of fsType in bytes.
+// It returns 0 if TopoLVM cannot set the label and UUID of fsType.
+func MaxLabelLength(fsType string) int {
+	return maxLabelLength[fsType]
+}
+
+// FormatOptions returns the options of mkfs to create a filesystem of fsType with the label and UUID.
+// Empty label or uuid are left for mkfs to decide.
+func FormatOptions(fsType, label, uuid string) ([]string, error) {
+	if label == "" && uuid == "" {
+		return nil, nil
+	}
+	max := MaxLabelLength(fsType)
+	if max == 0 {
+		return nil, fmt.Errorf("setting the label or UUID of %s is not supported", fsType)
+	}
+
+	var options []string
+	if label != "" {
+		if len(label) > max {
+			return nil, fmt.Errorf("label of %s must be at most %d bytes: %s", fsType, max, label)
+		}
+		options = append(options, "-L", label)
+	}
+	if uuid != "" {
+		if fsType == "xfs" {
+			options = append(options, "-m", "uuid="+uuid)
+		} else {
+			options = append(options, "-U", uuid)
+		}
+	}
+	return options, nil
+}
+
+// ValidateMkfsOptions checks the options of mkfs given by users in addition to FormatOptions.
+// The label and UUID are set by FormatOptions only, and paths are rejected so that the options
+// cannot make mkfs format another device than the volume.
+func ValidateMkfsOptions(options []string) error {
+	for _, option := range options {
+		switch {
+		case strings.HasPrefix(option, "/"), strings.Contains(option, "=/"):
+			return fmt.Errorf("paths are not allowed in mkfs options: %s", option)
+		case strings.HasPrefix(option, "-L"), strings.HasPrefix(option, "--label"):
+			return fmt.Errorf("mkfs options must not set the label, use the fs-label parameter: %s", option)
+		case strings.HasPrefix(option, "-U"), strings.HasPrefix(option, "--uuid"), strings.Contains(option, "uuid="):
+			return fmt.Errorf("mkfs options must not set the UUID, use the fs-uuid parameter: %s", option)
+		}
+	}
+	return nil
+}
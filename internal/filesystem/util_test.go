@@ -3,6 +3,7 @@ package filesystem
 import (
 	"os"
 	"os/exec"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -67,3 +68,65 @@ func TestDetectFilesystem(t *testing.T) {
 		t.Error("fs is not ext4", fs)
 	}
 }
+
+func TestValidateMkfsOptions(t *testing.T) {
+	testCases := []struct {
+		options []string
+		wantErr bool
+	}{
+		{options: nil},
+		{options: []string{"-m", "reflink=1", "-i", "size=512"}},
+		{options: []string{"-O", "bigalloc", "-C", "65536", "-I", "512"}},
+		{options: []string{"/dev/sda"}, wantErr: true},
+		{options: []string{"-d", "name=/dev/sda"}, wantErr: true},
+		{options: []string{"-L", "data"}, wantErr: true},
+		{options: []string{"--label=data"}, wantErr: true},
+		{options: []string{"-U", "random"}, wantErr: true},
+		{options: []string{"-m", "crc=1,uuid=5c0e8a3c-7e43-5d51-9c4d-4d1e0fcb3f2a"}, wantErr: true},
+	}
+	for _, tc := range testCases {
+		err := ValidateMkfsOptions(tc.options)
+		if tc.wantErr && err == nil {
+			t.Errorf("expected error: options=%v", tc.options)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("unexpected error: options=%v, error=%v", tc.options, err)
+		}
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	testCases := []struct {
+		fsType   string
+		label    string
+		uuid     string
+		expected []string
+		wantErr  bool
+	}{
+		{fsType: "ext4"},
+		{fsType: "ext4", label: "data", uuid: "5c0e8a3c-7e43-5d51-9c4d-4d1e0fcb3f2a",
+			expected: []string{"-L", "data", "-U", "5c0e8a3c-7e43-5d51-9c4d-4d1e0fcb3f2a"}},
+		{fsType: "xfs", label: "data", uuid: "5c0e8a3c-7e43-5d51-9c4d-4d1e0fcb3f2a",
+			expected: []string{"-L", "data", "-m", "uuid=5c0e8a3c-7e43-5d51-9c4d-4d1e0fcb3f2a"}},
+		{fsType: "btrfs", label: "data", expected: []string{"-L", "data"}},
+		{fsType: "xfs", label: "label-too-long", wantErr: true},
+		{fsType: "ext4", label: "label-is-too-long", wantErr: true},
+		{fsType: "vfat", label: "data", wantErr: true},
+	}
+	for _, tc := range testCases {
+		options, err := FormatOptions(tc.fsType, tc.label, tc.uuid)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("expected error: fsType=%s, label=%s", tc.fsType, tc.label)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		if !reflect.DeepEqual(options, tc.expected) {
+			t.Errorf("unexpected options: fsType=%s, expected=%v, actual=%v", tc.fsType, tc.expected, options)
+		}
+	}
+}
@@ -21,6 +21,9 @@ import (
 
 var pmLogger = ctrl.Log.WithName("pod-mutator")
 
+// dryRunWarningPrefix prefixes the warning returning the JSON patch to dry-run requests.
+const dryRunWarningPrefix = "topolvm would apply JSON patch: "
+
 //+kubebuilder:webhook:failurePolicy=fail,matchPolicy=equivalent,groups=core,resources=pods,verbs=create,versions=v1,name=pod-hook.topolvm.io,path=/pod/mutate,mutating=true,sideEffects=none,admissionReviewVersions={v1,v1beta1}
 //+kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch
 //+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
@@ -97,7 +100,17 @@ func (m *podMutator) Handle(ctx context.Context, req admission.Request) admissio
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
 
-	return admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
+	res := admission.PatchResponseFromRaw(req.Object.Raw, marshaledPod)
+	if req.DryRun != nil && *req.DryRun {
+		// preview the patch to those reviewing the changes with dry-run, e.g. `kubectl apply --dry-run=server`.
+		patch, err := json.Marshal(res.Patches)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		pmLogger.Info("dry-run patch", "namespace", pod.Namespace, "name", pod.Name, "patch", res.Patches)
+		res.Warnings = append(res.Warnings, dryRunWarningPrefix+string(patch))
+	}
+	return res
 }
 
 type targetSC struct {
@@ -1,16 +1,21 @@
 package hook
 
 import (
+	"encoding/json"
 	"strconv"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/getter"
+	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 const (
@@ -422,3 +427,38 @@ var _ = Describe("pod mutation webhook", func() {
 		Expect(capacity).Should(Equal(strconv.Itoa(500 * mebibyte)))
 	})
 })
+
+var _ = Describe("pod mutation webhook in dry-run", func() {
+	It("should return the JSON patch as a warning", func() {
+		pod := testPod()
+		pod.APIVersion = "v1"
+		pod.Kind = "Pod"
+		pod.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "vol1",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: pvcSource("pvc1"),
+				},
+			},
+		}
+		raw, err := json.Marshal(pod)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		mutator := &podMutator{
+			getter:  getter.NewRetryMissingGetter(k8sClient, k8sClient),
+			decoder: admission.NewDecoder(k8sClient.Scheme()),
+		}
+		res := mutator.Handle(testCtx, admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Namespace: mutatePodNamespace,
+				Object:    runtime.RawExtension{Raw: raw},
+				DryRun:    pointer.Bool(true),
+			},
+		})
+		Expect(res.Allowed).Should(BeTrue())
+		Expect(res.Patches).ShouldNot(BeEmpty())
+		patch, err := json.Marshal(res.Patches)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(res.Warnings).Should(ContainElement(dryRunWarningPrefix + string(patch)))
+	})
+})
@@ -39,6 +39,7 @@ func run(ctx context.Context, cfg *rest.Config, scheme *runtime.Scheme, opts *en
 	wh := mgr.GetWebhookServer()
 	wh.Register(podMutatingWebhookPath, PodMutator(mgr.GetClient(), mgr.GetAPIReader(), dec))
 	wh.Register(pvcMutatingWebhookPath, PVCMutator(mgr.GetClient(), mgr.GetAPIReader(), dec))
+	wh.Register(storageClassValidatingWebhookPath, StorageClassValidator(mgr.GetClient(), dec))
 
 	if err := mgr.Start(ctx); err != nil {
 		return err
@@ -45,6 +45,8 @@ const (
 
 	podMutatingWebhookPath = "/pod/mutate"
 	pvcMutatingWebhookPath = "/pvc/mutate"
+
+	storageClassValidatingWebhookPath = "/storageclass/validate"
 )
 
 func strPtr(s string) *string { return &s }
@@ -127,6 +129,7 @@ var _ = BeforeSuite(func() {
 
 	By("bootstrapping test environment")
 	failPolicy := admissionv1.Fail
+	ignorePolicy := admissionv1.Ignore
 	sideEffects := admissionv1.SideEffectClassNone
 	webhookInstallOptions := envtest.WebhookInstallOptions{
 		MutatingWebhooks: []*admissionv1.MutatingWebhookConfiguration{
@@ -188,6 +191,42 @@ var _ = BeforeSuite(func() {
 				},
 			},
 		},
+		ValidatingWebhooks: []*admissionv1.ValidatingWebhookConfiguration{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "topolvm-hook",
+				},
+				TypeMeta: metav1.TypeMeta{
+					Kind:       "ValidatingWebhookConfiguration",
+					APIVersion: "admissionregistration.k8s.io/v1",
+				},
+				Webhooks: []admissionv1.ValidatingWebhook{
+					{
+						Name:                    "storageclass-hook.topolvm.io",
+						AdmissionReviewVersions: []string{"v1", "v1beta1"},
+						FailurePolicy:           &ignorePolicy,
+						ClientConfig: admissionv1.WebhookClientConfig{
+							Service: &admissionv1.ServiceReference{
+								Path: strPtr(storageClassValidatingWebhookPath),
+							},
+						},
+						Rules: []admissionv1.RuleWithOperations{
+							{
+								Operations: []admissionv1.OperationType{
+									admissionv1.Create,
+								},
+								Rule: admissionv1.Rule{
+									APIGroups:   []string{"storage.k8s.io"},
+									APIVersions: []string{"v1"},
+									Resources:   []string{"storageclasses"},
+								},
+							},
+						},
+						SideEffects: &sideEffects,
+					},
+				},
+			},
+		},
 	}
 
 	testEnv = &envtest.Environment{
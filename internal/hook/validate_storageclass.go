@@ -0,0 +1,109 @@
+package hook
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/driver"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var scvLogger = ctrl.Log.WithName("storageclass-validator")
+
+//+kubebuilder:webhook:failurePolicy=ignore,matchPolicy=equivalent,groups=storage.k8s.io,resources=storageclasses,verbs=create,versions=v1,name=storageclass-hook.topolvm.io,path=/storageclass/validate,mutating=false,sideEffects=none,admissionReviewVersions={v1,v1beta1}
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
+
+// storageClassValidator validates the parameters of TopoLVM StorageClasses.
+type storageClassValidator struct {
+	reader  client.Reader
+	decoder *admission.Decoder
+}
+
+// StorageClassValidator creates a validating webhook for StorageClasses, which rejects the StorageClasses
+// whose parameters would make every CreateVolume fail, e.g. an unknown device-class.
+func StorageClassValidator(r client.Reader, dec *admission.Decoder) http.Handler {
+	return &webhook.Admission{
+		Handler: &storageClassValidator{
+			reader:  r,
+			decoder: dec,
+		},
+	}
+}
+
+// Handle implements admission.Handler interface.
+func (v *storageClassValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	sc := &storagev1.StorageClass{}
+	if err := v.decoder.Decode(req, sc); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if sc.Provisioner != topolvm.GetPluginName() {
+		return admission.Allowed("no request for TopoLVM")
+	}
+
+	// the filesystem type is empty for block volumes, so the filesystem parameters are checked for the default one.
+	fsType := sc.Parameters[driver.FilesystemTypeKey]
+	if fsType == "" {
+		fsType = "ext4"
+	}
+	if err := driver.ValidateParameters(sc.Parameters, fsType); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	deviceClasses, optionClasses, err := v.nodeClasses(ctx)
+	if err != nil {
+		scvLogger.Error(err, "failed to list nodes")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	// the nodes annotate their device-classes once topolvm-node has started, which may be after the StorageClass is created.
+	if len(deviceClasses) == 0 {
+		return admission.Allowed("").WithWarnings("no node reports its device-classes yet, so they are not validated")
+	}
+
+	deviceClass := sc.Parameters[topolvm.GetDeviceClassKey()]
+	annotationName := deviceClass
+	if deviceClass == topolvm.DefaultDeviceClassName {
+		annotationName = topolvm.DefaultDeviceClassAnnotationName
+	}
+	if !deviceClasses[annotationName] {
+		if deviceClass == topolvm.DefaultDeviceClassName {
+			return admission.Denied("no node has a default device-class")
+		}
+		return admission.Denied(fmt.Sprintf("device-class %s is not found on any node", deviceClass))
+	}
+	if optionClass := sc.Parameters[topolvm.GetLvcreateOptionClassKey()]; optionClass != "" && !optionClasses[optionClass] {
+		return admission.Denied(fmt.Sprintf("lvcreate-option-class %s is not found on any node", optionClass))
+	}
+	return admission.Allowed("")
+}
+
+// nodeClasses returns the device-classes and the lvcreate-option-classes annotated on the nodes by topolvm-node.
+func (v *storageClassValidator) nodeClasses(ctx context.Context) (map[string]bool, map[string]bool, error) {
+	nl := new(metav1.PartialObjectMetadataList)
+	nl.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("NodeList"))
+	if err := v.reader.List(ctx, nl); err != nil {
+		return nil, nil, err
+	}
+
+	deviceClasses := map[string]bool{}
+	optionClasses := map[string]bool{}
+	for _, node := range nl.Items {
+		for key := range node.Annotations {
+			switch {
+			case strings.HasPrefix(key, topolvm.GetCapacityKeyPrefix()):
+				deviceClasses[key[len(topolvm.GetCapacityKeyPrefix()):]] = true
+			case strings.HasPrefix(key, topolvm.GetLvcreateInlineOptionsKeyPrefix()):
+				optionClasses[key[len(topolvm.GetLvcreateInlineOptionsKeyPrefix()):]] = true
+			}
+		}
+	}
+	return deviceClasses, optionClasses, nil
+}
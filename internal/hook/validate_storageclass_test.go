@@ -0,0 +1,85 @@
+package hook
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func createStorageClass(name, provisioner string, parameters map[string]string) error {
+	sc := &storagev1.StorageClass{
+		ObjectMeta:        metav1.ObjectMeta{Name: name},
+		Provisioner:       provisioner,
+		VolumeBindingMode: modePtr(storagev1.VolumeBindingWaitForFirstConsumer),
+		Parameters:        parameters,
+	}
+	return k8sClient.Create(testCtx, sc)
+}
+
+var _ = Describe("storageclass validation webhook", Ordered, func() {
+	It("should allow StorageClasses while no node reports its device-classes", func() {
+		err := createStorageClass("validate-no-nodes", "topolvm.io", map[string]string{
+			topolvm.GetDeviceClassKey(): "unknown",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should deny StorageClasses with invalid parameters", func() {
+		err := createStorageClass("validate-invalid-parameter", "topolvm.io", map[string]string{
+			topolvm.GetProvisioningTypeKey(): "thinner",
+		})
+		Expect(err).Should(MatchError(ContainSubstring("invalid provisioning type: thinner")))
+
+		err = createStorageClass("validate-unsupported-fstype", "topolvm.io", map[string]string{
+			"csi.storage.k8s.io/fstype":  "ext4",
+			topolvm.GetProjectQuotaKey(): "true",
+		})
+		Expect(err).Should(MatchError(ContainSubstring("project quota of ext4 is not supported")))
+	})
+
+	It("should validate the classes annotated on the nodes", func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "validate-storageclass",
+				Annotations: map[string]string{
+					topolvm.GetCapacityKeyPrefix() + topolvm.DefaultDeviceClassAnnotationName: "1073741824",
+					topolvm.GetCapacityKeyPrefix() + "ssd":                                    "1073741824",
+					topolvm.GetLvcreateInlineOptionsKeyPrefix() + "raid1":                     `["--stripes"]`,
+				},
+			},
+		}
+		Expect(k8sClient.Create(testCtx, node)).Should(Succeed())
+
+		Eventually(func() error {
+			return createStorageClass("validate-unknown-device-class", "topolvm.io", map[string]string{
+				topolvm.GetDeviceClassKey(): "hdd",
+			})
+		}).Should(MatchError(ContainSubstring("device-class hdd is not found on any node")))
+
+		err := createStorageClass("validate-unknown-option-class", "topolvm.io", map[string]string{
+			topolvm.GetDeviceClassKey():         "ssd",
+			topolvm.GetLvcreateOptionClassKey(): "raid5",
+		})
+		Expect(err).Should(MatchError(ContainSubstring("lvcreate-option-class raid5 is not found on any node")))
+
+		err = createStorageClass("validate-known-classes", "topolvm.io", map[string]string{
+			topolvm.GetDeviceClassKey():         "ssd",
+			topolvm.GetLvcreateOptionClassKey(): "raid1",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+
+		err = createStorageClass("validate-default-device-class", "topolvm.io", nil)
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+
+	It("should not validate StorageClasses of other provisioners", func() {
+		err := createStorageClass("validate-other-provisioner", "kubernetes.io/no-provisioner", map[string]string{
+			topolvm.GetDeviceClassKey():      "hdd",
+			topolvm.GetProvisioningTypeKey(): "thinner",
+		})
+		Expect(err).ShouldNot(HaveOccurred())
+	})
+})
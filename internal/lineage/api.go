@@ -0,0 +1,64 @@
+package lineage
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// APIPath is the path prefix of the HTTP API served by the handler of NewAPI.
+const APIPath = "/lineage/v1/"
+
+type api struct {
+	reader client.Reader
+}
+
+// NewAPI returns a read-only HTTP API answering the lineage of a volume as Tree:
+//
+//   - GET /lineage/v1/claims/NAMESPACE/NAME for the volume bound to a PersistentVolumeClaim.
+//   - GET /lineage/v1/volumesnapshots/NAMESPACE/NAME for the volume backing a VolumeSnapshot.
+//   - GET /lineage/v1/logicalvolumes/NAME for a LogicalVolume.
+func NewAPI(r client.Reader) http.Handler {
+	return api{reader: r}
+}
+
+func (a api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, APIPath), "/")
+	for _, part := range parts {
+		if part == "" {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+	}
+	var find func(g *Graph) *Volume
+	switch {
+	case len(parts) == 3 && parts[0] == "claims":
+		find = func(g *Graph) *Volume { return g.ForClaim(parts[1], parts[2]) }
+	case len(parts) == 3 && parts[0] == "volumesnapshots":
+		find = func(g *Graph) *Volume { return g.ForVolumeSnapshot(parts[1], parts[2]) }
+	case len(parts) == 2 && parts[0] == "logicalvolumes":
+		find = func(g *Graph) *Volume { return g.Volume(parts[1]) }
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	g, err := Load(r.Context(), a.reader)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	v := find(g)
+	if v == nil {
+		http.Error(w, "volume not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(g.Tree(v))
+}
@@ -0,0 +1,243 @@
+// Package lineage builds the lineage of LogicalVolumes, i.e. which volumes are snapshots or clones of which,
+// so that operators can see what a deletion or a restore affects before acting.
+package lineage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kind is how the volume of a LogicalVolume has been created.
+type Kind string
+
+const (
+	// KindVolume is a volume created without a source.
+	KindVolume Kind = "volume"
+	// KindSnapshot is a read-only snapshot of its source, which backs a VolumeSnapshot.
+	KindSnapshot Kind = "snapshot"
+	// KindClone is a writable snapshot of its source, i.e. a cloned volume or a volume restored from a VolumeSnapshot.
+	KindClone Kind = "clone"
+)
+
+// Volume is a LogicalVolume in the lineage.
+type Volume struct {
+	// Name is the name of the LogicalVolume.
+	Name string `json:"name"`
+	// VolumeID is the name of the logical volume in LVM.
+	VolumeID string `json:"volumeID,omitempty"`
+	Kind     Kind   `json:"kind"`
+	// Node is the node of the logical volume.
+	Node        string `json:"node"`
+	DeviceClass string `json:"deviceClass,omitempty"`
+	Size        string `json:"size"`
+	// Claim is the PersistentVolumeClaim bound to the volume in the form of NAMESPACE/NAME, if any.
+	Claim string `json:"claim,omitempty"`
+	// VolumeSnapshot is the VolumeSnapshot backed by the volume in the form of NAMESPACE/NAME, if any.
+	VolumeSnapshot string `json:"volumeSnapshot,omitempty"`
+	// Source is the name of the LogicalVolume the volume has been created from, if any.
+	Source string `json:"source,omitempty"`
+	// SourceMissing is true if the source of the volume no longer exists.
+	SourceMissing bool `json:"sourceMissing,omitempty"`
+	// Deleting is true if the LogicalVolume is being deleted.
+	Deleting bool `json:"deleting,omitempty"`
+	// Children are the volumes created from the volume, sorted by name.
+	Children []*Volume `json:"children,omitempty"`
+
+	parent *Volume
+}
+
+// Graph is the lineage of all the LogicalVolumes.
+type Graph struct {
+	volumes    map[string]*Volume
+	byVolumeID map[string]*Volume
+	byClaim    map[string]*Volume
+	bySnapshot map[string]*Volume
+}
+
+// Build builds the lineage of lvs. The edges are taken from spec.source, or from status.origin for volumes without it.
+// The PersistentVolumes and the VolumeSnapshotContents of TopoLVM name the volumes after the claims and the
+// VolumeSnapshots they back.
+func Build(lvs []topolvmv1.LogicalVolume, pvs []corev1.PersistentVolume, contents []snapshotv1.VolumeSnapshotContent) *Graph {
+	g := &Graph{
+		volumes:    make(map[string]*Volume, len(lvs)),
+		byVolumeID: make(map[string]*Volume, len(lvs)),
+		byClaim:    make(map[string]*Volume),
+		bySnapshot: make(map[string]*Volume),
+	}
+	origins := make(map[string]string)
+	for i := range lvs {
+		lv := &lvs[i]
+		v := &Volume{
+			Name:        lv.Name,
+			VolumeID:    lv.Status.VolumeID,
+			Kind:        KindVolume,
+			Node:        lv.Spec.NodeName,
+			DeviceClass: lv.Spec.DeviceClass,
+			Size:        lv.Spec.Size.String(),
+			Source:      lv.Spec.Source,
+			Deleting:    lv.DeletionTimestamp != nil,
+		}
+		if v.Source != "" {
+			v.Kind = KindClone
+			if lv.Spec.AccessType == "ro" {
+				v.Kind = KindSnapshot
+			}
+		}
+		g.volumes[v.Name] = v
+		if v.VolumeID != "" {
+			g.byVolumeID[v.VolumeID] = v
+		}
+		if lv.Status.Origin != "" {
+			origins[v.Name] = lv.Status.Origin
+		}
+	}
+
+	for _, v := range g.volumes {
+		if v.Source == "" {
+			origin, ok := origins[v.Name]
+			if !ok {
+				continue
+			}
+			parent, ok := g.byVolumeID[origin]
+			if !ok {
+				continue
+			}
+			v.Source = parent.Name
+			v.Kind = KindClone
+		}
+		parent, ok := g.volumes[v.Source]
+		if !ok {
+			v.SourceMissing = true
+			continue
+		}
+		// A broken status.origin must not turn the lineage into a cycle.
+		if isAncestor(v, parent) {
+			continue
+		}
+		v.parent = parent
+		parent.Children = append(parent.Children, v)
+	}
+	for _, v := range g.volumes {
+		sort.Slice(v.Children, func(i, j int) bool { return v.Children[i].Name < v.Children[j].Name })
+	}
+
+	for i := range pvs {
+		pv := &pvs[i]
+		if pv.Spec.CSI == nil || pv.Spec.CSI.Driver != topolvm.GetPluginName() || pv.Spec.ClaimRef == nil {
+			continue
+		}
+		v, ok := g.byVolumeID[pv.Spec.CSI.VolumeHandle]
+		if !ok {
+			continue
+		}
+		v.Claim = pv.Spec.ClaimRef.Namespace + "/" + pv.Spec.ClaimRef.Name
+		g.byClaim[v.Claim] = v
+	}
+	for i := range contents {
+		content := &contents[i]
+		if content.Spec.Driver != topolvm.GetPluginName() || content.Status == nil || content.Status.SnapshotHandle == nil {
+			continue
+		}
+		v, ok := g.byVolumeID[*content.Status.SnapshotHandle]
+		if !ok {
+			continue
+		}
+		ref := content.Spec.VolumeSnapshotRef
+		v.VolumeSnapshot = ref.Namespace + "/" + ref.Name
+		g.bySnapshot[v.VolumeSnapshot] = v
+	}
+	return g
+}
+
+// isAncestor returns true if v is u or one of the volumes u has been created from.
+func isAncestor(v, u *Volume) bool {
+	for ; u != nil; u = u.parent {
+		if u == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Load builds the lineage of all the LogicalVolumes in the cluster.
+// VolumeSnapshots are not named if their CRDs are not installed.
+func Load(ctx context.Context, r client.Reader) (*Graph, error) {
+	var lvs topolvmv1.LogicalVolumeList
+	if err := r.List(ctx, &lvs); err != nil {
+		return nil, fmt.Errorf("failed to list LogicalVolumes: %w", err)
+	}
+	var pvs corev1.PersistentVolumeList
+	if err := r.List(ctx, &pvs); err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+	var contents snapshotv1.VolumeSnapshotContentList
+	if err := r.List(ctx, &contents); err != nil && !meta.IsNoMatchError(err) {
+		return nil, fmt.Errorf("failed to list VolumeSnapshotContents: %w", err)
+	}
+	return Build(lvs.Items, pvs.Items, contents.Items), nil
+}
+
+// Volume returns the volume of the LogicalVolume name, or nil if it is not found.
+func (g *Graph) Volume(name string) *Volume {
+	return g.volumes[name]
+}
+
+// ForClaim returns the volume bound to the PersistentVolumeClaim, or nil if it is not found.
+func (g *Graph) ForClaim(namespace, name string) *Volume {
+	return g.byClaim[namespace+"/"+name]
+}
+
+// ForVolumeSnapshot returns the volume backing the VolumeSnapshot, or nil if it is not found.
+func (g *Graph) ForVolumeSnapshot(namespace, name string) *Volume {
+	return g.bySnapshot[namespace+"/"+name]
+}
+
+// Root returns the volume at the root of the lineage of v.
+func (g *Graph) Root(v *Volume) *Volume {
+	for v.parent != nil {
+		v = v.parent
+	}
+	return v
+}
+
+// Dependents returns the names of the volumes created from v, directly or through other volumes, sorted by name.
+func (g *Graph) Dependents(v *Volume) []string {
+	names := []string{}
+	queue := []*Volume{v}
+	for len(queue) > 0 {
+		for _, child := range queue[0].Children {
+			names = append(names, child.Name)
+			queue = append(queue, child)
+		}
+		queue = queue[1:]
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Tree is the lineage of a volume.
+type Tree struct {
+	// Target is the name of the LogicalVolume asked for.
+	Target string `json:"target"`
+	// Root is the volume at the root of the lineage of the target with all the volumes created from it.
+	Root *Volume `json:"root"`
+	// Dependents are the names of the volumes created from the target, directly or through other volumes.
+	Dependents []string `json:"dependents"`
+}
+
+// Tree returns the lineage of v.
+func (g *Graph) Tree(v *Volume) *Tree {
+	return &Tree{
+		Target:     v.Name,
+		Root:       g.Root(v),
+		Dependents: g.Dependents(v),
+	}
+}
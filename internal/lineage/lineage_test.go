@@ -0,0 +1,222 @@
+package lineage
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	snapshotv1 "github.com/kubernetes-csi/external-snapshotter/client/v6/apis/volumesnapshot/v1"
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testLV(name, volumeID, source, accessType, origin string) topolvmv1.LogicalVolume {
+	return topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			NodeName:    "node1",
+			DeviceClass: "thin",
+			Size:        resource.MustParse("1Gi"),
+			Source:      source,
+			AccessType:  accessType,
+		},
+		Status: topolvmv1.LogicalVolumeStatus{VolumeID: volumeID, Origin: origin},
+	}
+}
+
+func testPV(volumeID, namespace, name string) corev1.PersistentVolume {
+	return corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-" + volumeID},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: topolvm.GetPluginName(), VolumeHandle: volumeID},
+			},
+			ClaimRef: &corev1.ObjectReference{Namespace: namespace, Name: name},
+		},
+	}
+}
+
+func testContent(volumeID, namespace, name string) snapshotv1.VolumeSnapshotContent {
+	return snapshotv1.VolumeSnapshotContent{
+		ObjectMeta: metav1.ObjectMeta{Name: "content-" + volumeID},
+		Spec: snapshotv1.VolumeSnapshotContentSpec{
+			Driver:            topolvm.GetPluginName(),
+			VolumeSnapshotRef: corev1.ObjectReference{Namespace: namespace, Name: name},
+		},
+		Status: &snapshotv1.VolumeSnapshotContentStatus{SnapshotHandle: &volumeID},
+	}
+}
+
+// testObjects are a volume with a snapshot restored to another volume, a clone of the volume,
+// a restore whose snapshot has been deleted, and a snapshot found only by its origin.
+func testObjects() ([]topolvmv1.LogicalVolume, []corev1.PersistentVolume, []snapshotv1.VolumeSnapshotContent) {
+	lvs := []topolvmv1.LogicalVolume{
+		testLV("data", "v-data", "", "", ""),
+		testLV("snap", "v-snap", "data", "ro", "v-data"),
+		testLV("restored", "v-restored", "snap", "rw", "v-snap"),
+		testLV("clone", "v-clone", "data", "rw", "v-data"),
+		testLV("orphan", "v-orphan", "deleted", "rw", ""),
+		testLV("other", "v-other", "", "", "v-restored"),
+	}
+	pvs := []corev1.PersistentVolume{
+		testPV("v-data", "ns", "data"),
+		testPV("v-restored", "ns", "restored"),
+		testPV("v-clone", "ns", "clone"),
+	}
+	contents := []snapshotv1.VolumeSnapshotContent{testContent("v-snap", "ns", "snap")}
+	return lvs, pvs, contents
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testObjects())
+
+	data := g.ForClaim("ns", "data")
+	if data == nil || data.Name != "data" || data.Kind != KindVolume {
+		t.Fatalf("unexpected volume of the claim: %+v", data)
+	}
+	var children []string
+	for _, child := range data.Children {
+		children = append(children, child.Name)
+	}
+	if !reflect.DeepEqual(children, []string{"clone", "snap"}) {
+		t.Errorf("unexpected children of data: %v", children)
+	}
+
+	snap := g.ForVolumeSnapshot("ns", "snap")
+	if snap == nil || snap.Kind != KindSnapshot || snap.VolumeSnapshot != "ns/snap" {
+		t.Errorf("unexpected volume of the snapshot: %+v", snap)
+	}
+	restored := g.ForClaim("ns", "restored")
+	if restored == nil || restored.Kind != KindClone || g.Root(restored) != data {
+		t.Errorf("unexpected restored volume: %+v", restored)
+	}
+	other := g.Volume("other")
+	if other.Source != "restored" || other.Kind != KindClone || g.Root(other) != data {
+		t.Errorf("expected other to be linked by its origin: %+v", other)
+	}
+	orphan := g.Volume("orphan")
+	if !orphan.SourceMissing || g.Root(orphan) != orphan {
+		t.Errorf("expected the source of orphan to be missing: %+v", orphan)
+	}
+
+	if deps := g.Dependents(data); !reflect.DeepEqual(deps, []string{"clone", "other", "restored", "snap"}) {
+		t.Errorf("unexpected dependents of data: %v", deps)
+	}
+	if deps := g.Dependents(g.Volume("clone")); len(deps) != 0 {
+		t.Errorf("unexpected dependents of clone: %v", deps)
+	}
+	if g.ForClaim("ns", "unknown") != nil {
+		t.Error("expected no volume for an unknown claim")
+	}
+}
+
+func TestBuildCycle(t *testing.T) {
+	g := Build([]topolvmv1.LogicalVolume{
+		testLV("a", "v-a", "", "", "v-b"),
+		testLV("b", "v-b", "a", "rw", "v-a"),
+	}, nil, nil)
+	a, b := g.Volume("a"), g.Volume("b")
+	if (a.parent == nil) == (b.parent == nil) || g.Root(a) != g.Root(b) {
+		t.Errorf("expected exactly one of the edges to be linked: %+v %+v", a, b)
+	}
+}
+
+func TestPrint(t *testing.T) {
+	g := Build(testObjects())
+	var buf bytes.Buffer
+	if err := Print(&buf, g.Tree(g.Volume("snap"))); err != nil {
+		t.Fatal(err)
+	}
+	expected := `data  volume  claim=ns/data  node=node1  size=1Gi
+├── clone  clone  claim=ns/clone  node=node1  size=1Gi
+└── snap  snapshot  volumesnapshot=ns/snap  node=node1  size=1Gi  *
+    └── restored  clone  claim=ns/restored  node=node1  size=1Gi
+        └── other  clone  node=node1  size=1Gi
+
+2 volume(s) depend on snap: other, restored
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := Print(&buf, g.Tree(g.Volume("orphan"))); err != nil {
+		t.Fatal(err)
+	}
+	expected = `orphan  clone  node=node1  size=1Gi  source=deleted(missing)  *
+
+no volume depends on orphan
+`
+	if buf.String() != expected {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestAPI(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{topolvmv1.AddToScheme, corev1.AddToScheme, snapshotv1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lvs, pvs, contents := testObjects()
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range lvs {
+		builder = builder.WithObjects(&lvs[i])
+	}
+	for i := range pvs {
+		builder = builder.WithObjects(&pvs[i])
+	}
+	for i := range contents {
+		builder = builder.WithObjects(&contents[i])
+	}
+	api := NewAPI(builder.Build())
+	get := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		api.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		return w
+	}
+
+	for url, target := range map[string]string{
+		APIPath + "claims/ns/restored":      "restored",
+		APIPath + "volumesnapshots/ns/snap": "snap",
+		APIPath + "logicalvolumes/clone":    "clone",
+	} {
+		w := get(url)
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: unexpected status %d: %s", url, w.Code, w.Body.String())
+			continue
+		}
+		var tree Tree
+		if err := json.NewDecoder(w.Body).Decode(&tree); err != nil {
+			t.Fatal(err)
+		}
+		if tree.Target != target || tree.Root.Name != "data" || len(tree.Root.Children) != 2 {
+			t.Errorf("%s: unexpected tree: %+v", url, tree)
+		}
+	}
+
+	for _, url := range []string{
+		APIPath + "claims/ns/unknown",
+		APIPath + "claims/ns",
+		APIPath + "claims/ns/",
+		APIPath + "nodes/node1",
+	} {
+		if w := get(url); w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected not found, got %d", url, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	api.ServeHTTP(w, httptest.NewRequest(http.MethodPost, APIPath+"logicalvolumes/clone", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed, got %d", w.Code)
+	}
+}
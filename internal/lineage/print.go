@@ -0,0 +1,56 @@
+package lineage
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Print writes the lineage of t to w as a tree, one volume per line, marking the target with "*".
+// It ends with the volumes depending on the target, which are affected by deleting or restoring it.
+func Print(w io.Writer, t *Tree) error {
+	if err := printVolume(w, t.Root, t.Target, "", ""); err != nil {
+		return err
+	}
+	var err error
+	if len(t.Dependents) == 0 {
+		_, err = fmt.Fprintf(w, "\nno volume depends on %s\n", t.Target)
+	} else {
+		_, err = fmt.Fprintf(w, "\n%d volume(s) depend on %s: %s\n", len(t.Dependents), t.Target, strings.Join(t.Dependents, ", "))
+	}
+	return err
+}
+
+func printVolume(w io.Writer, v *Volume, target, prefix, childPrefix string) error {
+	fields := []string{prefix + v.Name, string(v.Kind)}
+	if v.Claim != "" {
+		fields = append(fields, "claim="+v.Claim)
+	}
+	if v.VolumeSnapshot != "" {
+		fields = append(fields, "volumesnapshot="+v.VolumeSnapshot)
+	}
+	fields = append(fields, "node="+v.Node, "size="+v.Size)
+	if v.SourceMissing {
+		fields = append(fields, "source="+v.Source+"(missing)")
+	}
+	if v.Deleting {
+		fields = append(fields, "deleting")
+	}
+	if v.Name == target {
+		fields = append(fields, "*")
+	}
+	if _, err := fmt.Fprintln(w, strings.Join(fields, "  ")); err != nil {
+		return err
+	}
+
+	for i, child := range v.Children {
+		branch, indent := "├── ", "│   "
+		if i == len(v.Children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if err := printVolume(w, child, target, childPrefix+branch, childPrefix+indent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
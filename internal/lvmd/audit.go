@@ -0,0 +1,178 @@
+package lvmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	gproto "google.golang.org/protobuf/proto"
+)
+
+// AuditedRPCs are the RPCs changing the volumes or the volume groups recorded by AuditLogger by the method name.
+var AuditedRPCs = map[string]bool{
+	"CreateLV":                  true,
+	"CreateLVSnapshot":          true,
+	"CreateDeviceClassSnapshot": true,
+	"MergeLVSnapshot":           true,
+	"MoveLV":                    true,
+	"RemoveLV":                  true,
+	"ResizeLV":                  true,
+	"ChangeLVTags":              true,
+	"ActivateLV":                true,
+	"DeactivateLV":              true,
+	"CreateVG":                  true,
+	"RemoveVG":                  true,
+	"ExtendVG":                  true,
+	"ReduceVG":                  true,
+	"EvacuatePV":                true,
+	"UpdateLVMDevices":          true,
+	"SetDeviceClassDraining":    true,
+}
+
+// AuditRecord is a line of the audit log.
+type AuditRecord struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	// Peer is the address of the requester.
+	Peer string `json:"peer,omitempty"`
+	// PID, UID and GID are the credentials of the requester connected to the Unix domain socket.
+	PID *int32  `json:"pid,omitempty"`
+	UID *uint32 `json:"uid,omitempty"`
+	GID *uint32 `json:"gid,omitempty"`
+	// Subject is the subject of the client certificate of the requester connected with mutual TLS.
+	Subject string `json:"subject,omitempty"`
+	// Metadata is the gRPC metadata sent by the requester.
+	Metadata map[string][]string `json:"metadata,omitempty"`
+	Request  json.RawMessage     `json:"request,omitempty"`
+	Response json.RawMessage     `json:"response,omitempty"`
+	Code     string              `json:"code"`
+	Message  string              `json:"message,omitempty"`
+	Duration float64             `json:"durationSeconds"`
+}
+
+// AuditLogger appends an AuditRecord of every AuditedRPCs to a writer as a line of JSON.
+type AuditLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	logger logr.Logger
+}
+
+// NewAuditLogger returns AuditLogger writing to w. The failures to write are logged to logger.
+func NewAuditLogger(w io.Writer, logger logr.Logger) *AuditLogger {
+	return &AuditLogger{w: w, logger: logger}
+}
+
+// UnaryInterceptor returns a unary interceptor recording the requests and their results.
+func (a *AuditLogger) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		method := path.Base(info.FullMethod)
+		if !AuditedRPCs[method] {
+			return handler(ctx, req)
+		}
+		start := time.Now()
+		res, err := handler(ctx, req)
+		a.record(ctx, method, start, req, res, err)
+		return res, err
+	}
+}
+
+// StreamInterceptor returns a stream interceptor recording the first request of the streams and their results.
+func (a *AuditLogger) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		method := path.Base(info.FullMethod)
+		if !AuditedRPCs[method] {
+			return handler(srv, ss)
+		}
+		start := time.Now()
+		stream := &auditedStream{ServerStream: ss}
+		err := handler(srv, stream)
+		a.record(ss.Context(), method, start, stream.req, nil, err)
+		return err
+	}
+}
+
+type auditedStream struct {
+	grpc.ServerStream
+	req any
+}
+
+func (s *auditedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+func (a *AuditLogger) record(ctx context.Context, method string, start time.Time, req, res any, err error) {
+	record := AuditRecord{
+		Time:     start.UTC(),
+		Method:   method,
+		Request:  marshalAuditMessage(req),
+		Code:     status.Code(err).String(),
+		Duration: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		record.Message = status.Convert(err).Message()
+	} else {
+		record.Response = marshalAuditMessage(res)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if p.Addr != nil {
+			record.Peer = p.Addr.String()
+		}
+		switch info := p.AuthInfo.(type) {
+		case PeerCredInfo:
+			record.PID, record.UID, record.GID = &info.PID, &info.UID, &info.GID
+		case credentials.TLSInfo:
+			if certs := info.State.PeerCertificates; len(certs) > 0 {
+				record.Subject = certs[0].Subject.String()
+			}
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		record.Metadata = make(map[string][]string, len(md))
+		for k, v := range md {
+			// keep credentials out of the log.
+			if k == "authorization" || strings.HasPrefix(k, ":") {
+				continue
+			}
+			record.Metadata[k] = v
+		}
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		a.logger.Error(err, "failed to marshal audit record", "method", method)
+		return
+	}
+	line = append(line, '\n')
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.w.Write(line); err != nil {
+		a.logger.Error(err, "failed to write audit record", "method", method)
+	}
+}
+
+func marshalAuditMessage(m any) json.RawMessage {
+	msg, ok := m.(gproto.Message)
+	if !ok || msg == nil {
+		return nil
+	}
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,80 @@
+package lvmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+func TestAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	interceptor := NewAuditLogger(&buf, testr.New(t)).UnaryInterceptor()
+	call := func(method string, req any, handler grpc.UnaryHandler) {
+		ctx := peer.NewContext(context.Background(), &peer.Peer{
+			Addr:     &net.UnixAddr{Name: "@", Net: "unix"},
+			AuthInfo: PeerCredInfo{PID: 42, UID: 1000, GID: 100},
+		})
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "topolvm-node", "authorization", "secret"))
+		_, _ = interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/proto.LVService/" + method}, handler)
+	}
+
+	call("CreateLV", &proto.CreateLVRequest{Name: "vol1", SizeBytes: 1 << 30}, func(ctx context.Context, _ any) (any, error) {
+		return &proto.CreateLVResponse{Volume: &proto.LogicalVolume{Name: "vol1", SizeBytes: 1 << 30}}, nil
+	})
+	call("GetLVList", &proto.GetLVListRequest{}, func(ctx context.Context, _ any) (any, error) {
+		return &proto.GetLVListResponse{}, nil
+	})
+	call("RemoveLV", &proto.RemoveLVRequest{Name: "vol2"}, func(ctx context.Context, _ any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+
+	dec := json.NewDecoder(&buf)
+	var records []AuditRecord
+	for dec.More() {
+		var record AuditRecord
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected records of CreateLV and RemoveLV, got %d", len(records))
+	}
+
+	created := records[0]
+	if created.Method != "CreateLV" || created.Code != codes.OK.String() {
+		t.Errorf("unexpected record: %+v", created)
+	}
+	if created.PID == nil || *created.PID != 42 || created.UID == nil || *created.UID != 1000 || created.GID == nil || *created.GID != 100 {
+		t.Errorf("expected the credentials of the requester: %+v", created)
+	}
+	if ua := created.Metadata["user-agent"]; len(ua) != 1 || ua[0] != "topolvm-node" {
+		t.Errorf("expected the user-agent of the requester: %v", created.Metadata)
+	}
+	if _, ok := created.Metadata["authorization"]; ok {
+		t.Error("authorization should not be recorded")
+	}
+	var req proto.CreateLVRequest
+	if err := protojson.Unmarshal(created.Request, &req); err != nil || req.Name != "vol1" || req.SizeBytes != 1<<30 {
+		t.Errorf("unexpected request: %s", created.Request)
+	}
+	if len(created.Response) == 0 {
+		t.Error("expected the response")
+	}
+
+	removed := records[1]
+	if removed.Method != "RemoveLV" || removed.Code != codes.NotFound.String() || removed.Message != "not found" || removed.Response != nil {
+		t.Errorf("unexpected record: %+v", removed)
+	}
+}
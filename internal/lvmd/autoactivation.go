@@ -0,0 +1,105 @@
+package lvmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ConfigureAutoActivation writes the lvm configuration file on the host that restricts the autoactivation by lvm
+// to the volume groups not used by device-classes with activation-skip, and to the extra entries.
+// This keeps lvm on the host from activating the volumes that TopoLVM activates only while they are staged,
+// including those without the activation skip flag such as volumes restored from snapshots.
+func ConfigureAutoActivation(ctx context.Context, file string, deviceClasses []*lvmdTypes.DeviceClass, extra []string) error {
+	vgs, err := command.ListVolumeGroups(ctx)
+	if err != nil {
+		return err
+	}
+	managed := make(map[string]bool)
+	for _, dc := range deviceClasses {
+		if dc.ActivationSkip {
+			for _, name := range VolumeGroupNames(dc) {
+				managed[name] = true
+			}
+		}
+	}
+
+	volumes := make([]string, 0, len(vgs)+len(extra))
+	for _, vg := range vgs {
+		if !managed[vg.Name()] {
+			volumes = append(volumes, vg.Name())
+		}
+	}
+	sort.Strings(volumes)
+	volumes = append(volumes, extra...)
+
+	if err := command.WriteAutoActivationConfig(file, volumes); err != nil {
+		return fmt.Errorf("failed to write the autoactivation configuration: %w", err)
+	}
+	log.FromContext(ctx).Info("restricted autoactivation of lvm", "file", file, "auto_activation_volume_list", volumes)
+	return nil
+}
+
+// CheckAutoActivation returns warnings about the volumes of device-classes with activation-skip that lvm on
+// the host autoactivates, e.g. on boot or when udev reports a device. TopoLVM neither deactivates them when
+// they are unstaged, because they have no activation skip flag, nor can keep lvm from activating them again.
+// The warnings are also logged.
+func CheckAutoActivation(ctx context.Context, deviceClasses []*lvmdTypes.DeviceClass) ([]string, error) {
+	list, restricted, err := command.GetConfigList(ctx, command.AutoActivationVolumeListKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	for _, dc := range deviceClasses {
+		if !dc.ActivationSkip {
+			continue
+		}
+		vgs, err := deviceClassVolumeGroups(ctx, dc)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, vg := range vgs {
+			lvs, err := vg.ListVolumes(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, lv := range lvs {
+				if !lv.HasActivationSkip() && (!restricted || autoActivationListed(list, lv)) {
+					names = append(names, lv.Name())
+				}
+			}
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Strings(names)
+		warning := fmt.Sprintf("%d volume(s) of device-class %s have no activation skip flag and are autoactivated by lvm on the host",
+			len(names), dc.Name)
+		log.FromContext(ctx).Info("volumes conflict with the autoactivation of lvm on the host",
+			"device_class", dc.Name, "volumes", names, "auto_activation_volume_list", list)
+		warnings = append(warnings, warning)
+	}
+	return warnings, nil
+}
+
+// autoActivationListed returns true if the volume matches an entry of auto_activation_volume_list,
+// which is either a volume group, a volume or a tag.
+func autoActivationListed(list []string, lv *command.LogicalVolume) bool {
+	for _, entry := range list {
+		if entry == lv.VG().Name() || entry == lv.FullName() {
+			return true
+		}
+		for _, tag := range lv.Tags() {
+			if entry == "@"+tag {
+				return true
+			}
+		}
+	}
+	return false
+}
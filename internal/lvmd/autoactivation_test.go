@@ -0,0 +1,92 @@
+package lvmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestAutoActivationWithFakeLVM(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("skip-vg", 4<<30)
+	fake.AddVolumeGroup("other-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "skip-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "skipped", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	skipped, err := vg.FindVolume(ctx, "skipped")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := skipped.SetActivationSkip(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	// e.g. restored from a snapshot, which does not have the flag.
+	if err := vg.CreateVolume(ctx, "restored", 1<<30, []string{"restored"}, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	deviceClasses := []*lvmdTypes.DeviceClass{
+		{Name: "skip", VolumeGroup: "skip-vg", ActivationSkip: true},
+		{Name: "other", VolumeGroup: "other-vg"},
+	}
+	testCases := []struct {
+		name     string
+		list     string
+		warnings int
+	}{
+		{name: "not restricted", warnings: 1},
+		{name: "other volume group", list: `["other-vg"]`, warnings: 0},
+		{name: "volume group", list: `["other-vg","skip-vg"]`, warnings: 1},
+		{name: "volume", list: `["skip-vg/restored"]`, warnings: 1},
+		{name: "tag", list: `["@restored"]`, warnings: 1},
+	}
+	for _, tc := range testCases {
+		if tc.list != "" {
+			fake.SetConfig(command.AutoActivationVolumeListKey, tc.list)
+		}
+		warnings, err := CheckAutoActivation(ctx, deviceClasses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(warnings) != tc.warnings {
+			t.Errorf("%s: unexpected warnings: %v", tc.name, warnings)
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "lvmlocal.conf")
+	if err := ConfigureAutoActivation(ctx, file, deviceClasses, []string{"rootvg"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `auto_activation_volume_list = [ "other-vg", "rootvg" ]`) {
+		t.Errorf("unexpected configuration: %s", data)
+	}
+	if err := ConfigureAutoActivation(ctx, file, deviceClasses, nil); err != nil {
+		t.Errorf("the file written by lvmd should be overwritten: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("local {\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ConfigureAutoActivation(ctx, file, deviceClasses, nil); err == nil {
+		t.Error("a file not written by lvmd should not be overwritten")
+	}
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	defaultNsenterPath    = "/usr/bin/nsenter"
+	defaultLVMPath        = "/sbin/lvm"
+	defaultDmsetupPath    = "/sbin/dmsetup"
+	defaultUdevadmPath    = "/usr/bin/udevadm"
+	defaultBlkdiscardPath = "/usr/sbin/blkdiscard"
+	defaultDdPath         = "/usr/bin/dd"
+	defaultLsblkPath      = "/usr/bin/lsblk"
+
+	// hostRoot is the root filesystem of the host seen from a container sharing the PID namespace of the host.
+	hostRoot = "/proc/1/root"
+)
+
+// Paths of the binaries executed by lvmd. Empty paths are detected on the first call of BinaryPaths.
+var (
+	LVMPath     string
+	DmsetupPath string
+	NsenterPath string
+)
+
+// hostBinaryDirs are searched on the host for lvm and dmsetup if Containerized is true.
+// They cover the layouts of distributions like NixOS, Talos and Flatcar.
+var hostBinaryDirs = []string{
+	"/sbin",
+	"/usr/sbin",
+	"/bin",
+	"/usr/bin",
+	"/usr/local/sbin",
+	"/usr/local/bin",
+	"/opt/bin",
+	"/run/current-system/sw/bin",
+}
+
+var detectBinaries sync.Once
+
+// Paths of udevadm, blkdiscard, dd and lsblk, detected together with the other binaries like lvm.
+var (
+	udevadmPath    string
+	blkdiscardPath string
+	ddPath         string
+	lsblkPath      string
+)
+
+// BinaryPaths returns the paths of lvm, dmsetup and nsenter.
+// The paths not set are detected once: nsenter is looked up in PATH, and so are lvm and dmsetup unless
+// Containerized is true, in which case they are searched in the well-known directories of the host.
+// If a binary is not found, its conventional path is used.
+func BinaryPaths() (lvm, dmsetup, nsenter string) {
+	detectBinaries.Do(func() {
+		if LVMPath == "" {
+			LVMPath = findBinary("lvm", defaultLVMPath, Containerized)
+		}
+		if DmsetupPath == "" {
+			DmsetupPath = findBinary("dmsetup", defaultDmsetupPath, Containerized)
+		}
+		if NsenterPath == "" {
+			NsenterPath = findBinary("nsenter", defaultNsenterPath, false)
+		}
+		udevadmPath = findBinary("udevadm", defaultUdevadmPath, Containerized)
+		blkdiscardPath = findBinary("blkdiscard", defaultBlkdiscardPath, Containerized)
+		ddPath = findBinary("dd", defaultDdPath, Containerized)
+		lsblkPath = findBinary("lsblk", defaultLsblkPath, Containerized)
+	})
+	return LVMPath, DmsetupPath, NsenterPath
+}
+
+// findBinary returns the path of the binary named name, or fallback if it is not found.
+func findBinary(name, fallback string, onHost bool) string {
+	if !onHost {
+		if path, err := exec.LookPath(name); err == nil {
+			if abs, err := filepath.Abs(path); err == nil {
+				return abs
+			}
+		}
+		return fallback
+	}
+	return findInDirs(hostRoot, hostBinaryDirs, name, fallback)
+}
+
+// findInDirs returns the first path of the executable named name in dirs under root.
+// The returned path is relative to root. Symbolic links are not followed because absolute
+// links would be resolved outside of root, e.g. the links into /nix/store on NixOS.
+func findInDirs(root string, dirs []string, name, fallback string) string {
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name)
+		info, err := os.Lstat(filepath.Join(root, path))
+		if err != nil {
+			continue
+		}
+		if info.Mode()&os.ModeSymlink != 0 || (info.Mode().IsRegular() && info.Mode()&0o111 != 0) {
+			return path
+		}
+	}
+	return fallback
+}
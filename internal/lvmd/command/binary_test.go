@@ -0,0 +1,42 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindInDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"sbin", "usr/sbin", "run/current-system/sw/bin"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// not executable
+	if err := os.WriteFile(filepath.Join(root, "sbin/lvm"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "usr/sbin/dmsetup"), nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// an absolute link, which does not resolve under root.
+	if err := os.Symlink("/nix/store/lvm2/bin/lvm", filepath.Join(root, "run/current-system/sw/bin/lvm")); err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := []string{"/sbin", "/usr/sbin", "/run/current-system/sw/bin"}
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "lvm", expected: "/run/current-system/sw/bin/lvm"},
+		{name: "dmsetup", expected: "/usr/sbin/dmsetup"},
+		{name: "nsenter", expected: "/fallback"},
+	}
+	for _, tc := range testCases {
+		if path := findInDirs(root, dirs, tc.name, "/fallback"); path != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, path)
+		}
+	}
+}
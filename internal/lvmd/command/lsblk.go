@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BlockDevice is a disk or partition reported by lsblk.
+type BlockDevice struct {
+	// Path is the path of the device node, e.g. /dev/sdb.
+	Path string
+	// Major and Minor are the device numbers.
+	Major uint32
+	Minor uint32
+	// Size is the size of the device in bytes.
+	Size uint64
+	// Type is the type of the device, e.g. disk or part.
+	Type string
+	// FSType is the signature on the device, e.g. xfs or LVM2_member, or empty if none is found.
+	FSType string
+	// MountPoint is where the device is mounted, or empty if it is not mounted.
+	MountPoint string
+	// ID identifies the device regardless of its path, which may change on reboot.
+	// It is the partition UUID of a partition, then the WWN or the serial number of the device, if any.
+	// Otherwise, it is the path.
+	ID string
+	// HasChildren is true if the device has partitions or holders such as device-mapper devices.
+	HasChildren bool
+}
+
+// lsblkDevice is an entry of the output of lsblk --json.
+type lsblkDevice struct {
+	Name       reportValue   `json:"name"`
+	MajMin     reportValue   `json:"maj:min"`
+	Size       reportValue   `json:"size"`
+	Type       reportValue   `json:"type"`
+	FSType     reportValue   `json:"fstype"`
+	MountPoint reportValue   `json:"mountpoint"`
+	WWN        reportValue   `json:"wwn"`
+	Serial     reportValue   `json:"serial"`
+	PartUUID   reportValue   `json:"partuuid"`
+	Children   []lsblkDevice `json:"children"`
+}
+
+// ListBlockDevices returns the disks and partitions on the node.
+// The holders of the devices, such as the logical volumes on physical volumes, are not returned.
+func ListBlockDevices(ctx context.Context) ([]BlockDevice, error) {
+	var res struct {
+		BlockDevices []lsblkDevice `json:"blockdevices"`
+	}
+	err := callBinaryInto(ctx, &res, BinaryLsblk, "--json", "--bytes", "--paths",
+		"--output", "NAME,MAJ:MIN,SIZE,TYPE,FSTYPE,MOUNTPOINT,WWN,SERIAL,PARTUUID")
+	if err != nil {
+		return nil, err
+	}
+
+	var devices []BlockDevice
+	var walk func(entries []lsblkDevice) error
+	walk = func(entries []lsblkDevice) error {
+		for _, entry := range entries {
+			if entry.Type != "disk" && entry.Type != "part" {
+				continue
+			}
+			device, err := entry.blockDevice()
+			if err != nil {
+				return err
+			}
+			devices = append(devices, device)
+			if err := walk(entry.Children); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(res.BlockDevices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+func (d lsblkDevice) blockDevice() (BlockDevice, error) {
+	device := BlockDevice{
+		Path:        string(d.Name),
+		Type:        string(d.Type),
+		FSType:      string(d.FSType),
+		MountPoint:  string(d.MountPoint),
+		HasChildren: len(d.Children) != 0,
+	}
+	major, minor, ok := strings.Cut(string(d.MajMin), ":")
+	if !ok {
+		return BlockDevice{}, fmt.Errorf("invalid device numbers of %s: %s", d.Name, d.MajMin)
+	}
+	majorNumber, err := strconv.ParseUint(major, 10, 32)
+	if err != nil {
+		return BlockDevice{}, fmt.Errorf("invalid device numbers of %s: %w", d.Name, err)
+	}
+	minorNumber, err := strconv.ParseUint(minor, 10, 32)
+	if err != nil {
+		return BlockDevice{}, fmt.Errorf("invalid device numbers of %s: %w", d.Name, err)
+	}
+	device.Major, device.Minor = uint32(majorNumber), uint32(minorNumber)
+	device.Size, err = strconv.ParseUint(string(d.Size), 10, 64)
+	if err != nil {
+		return BlockDevice{}, fmt.Errorf("invalid size of %s: %w", d.Name, err)
+	}
+
+	// the partitions of a disk share its WWN and serial number.
+	switch {
+	case device.Type == "part" && d.PartUUID != "":
+		device.ID = "partuuid:" + string(d.PartUUID)
+	case device.Type == "disk" && d.WWN != "":
+		device.ID = "wwn:" + string(d.WWN)
+	case device.Type == "disk" && d.Serial != "":
+		device.ID = "serial:" + string(d.Serial)
+	default:
+		device.ID = "path:" + device.Path
+	}
+	return device, nil
+}
+
+// DiscardDevice discards all blocks of the device with blkdiscard, so that its data cannot be read
+// by the next user of the device. The blocks are overwritten with zeroes instead if zero is true or
+// the device does not support discards.
+func DiscardDevice(ctx context.Context, path string, zero bool) error {
+	if !zero {
+		err := callBinary(ctx, BinaryBlkdiscard, path)
+		if !errors.Is(err, ErrOperationNotSupported) {
+			return err
+		}
+		log.FromContext(ctx).Info("device does not support discards, zeroing it", "path", path)
+	}
+	return callBinary(ctx, BinaryBlkdiscard, "-z", path)
+}
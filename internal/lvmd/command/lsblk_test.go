@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestLsblkBlockDevice(t *testing.T) {
+	// older versions of lsblk report the sizes as strings even with --bytes.
+	output := `{"blockdevices": [
+		{"name": "/dev/sda", "maj:min": "8:0", "size": "107374182400", "type": "disk", "fstype": null, "mountpoint": null,
+		 "wwn": "0x5000c500a0b1c2d3", "serial": "S1", "partuuid": null, "children": [
+			{"name": "/dev/sda1", "maj:min": "8:1", "size": "1073741824", "type": "part", "fstype": "xfs", "mountpoint": "/boot",
+			 "wwn": "0x5000c500a0b1c2d3", "serial": null, "partuuid": "0f1e2d3c-01"}
+		]},
+		{"name": "/dev/vdb", "maj:min": "252:16", "size": 10737418240, "type": "disk", "fstype": "LVM2_member", "mountpoint": null,
+		 "wwn": null, "serial": null, "partuuid": null}
+	]}`
+	var res struct {
+		BlockDevices []lsblkDevice `json:"blockdevices"`
+	}
+	if err := json.Unmarshal([]byte(output), &res); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []BlockDevice{
+		{Path: "/dev/sda", Major: 8, Minor: 0, Size: 100 << 30, Type: "disk", ID: "wwn:0x5000c500a0b1c2d3", HasChildren: true},
+		{Path: "/dev/sda1", Major: 8, Minor: 1, Size: 1 << 30, Type: "part", FSType: "xfs", MountPoint: "/boot", ID: "partuuid:0f1e2d3c-01"},
+		{Path: "/dev/vdb", Major: 252, Minor: 16, Size: 10 << 30, Type: "disk", FSType: "LVM2_member", ID: "path:/dev/vdb"},
+	}
+	entries := []lsblkDevice{res.BlockDevices[0], res.BlockDevices[0].Children[0], res.BlockDevices[1]}
+	for i, entry := range entries {
+		device, err := entry.blockDevice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if device != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], device)
+		}
+	}
+}
+
+func TestFakeLVMListBlockDevices(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddDevice("/dev/sdb", 1<<30)
+	fake.AddDevice("/dev/sdc", 2<<30)
+	defer SetExecutor(fake)()
+
+	if _, err := CreateVolumeGroup(ctx, "vg", []string{"/dev/sdc"}); err != nil {
+		t.Fatal(err)
+	}
+	devices, err := ListBlockDevices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("unexpected devices: %+v", devices)
+	}
+	if d := devices[0]; d.Path != "/dev/sdb" || d.Size != 1<<30 || d.FSType != "" || d.ID != "serial:FAKE-sdb" {
+		t.Errorf("unexpected device: %+v", d)
+	}
+	if d := devices[1]; d.Path != "/dev/sdc" || d.FSType != "LVM2_member" || d.Minor == devices[0].Minor {
+		t.Errorf("unexpected device: %+v", d)
+	}
+	if err := DiscardDevice(ctx, "/dev/sdb", false); err != nil {
+		t.Errorf("failed to discard device: %v", err)
+	}
+}
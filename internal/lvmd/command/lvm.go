@@ -5,22 +5,29 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/topolvm/topolvm"
 )
 
-const (
-	nsenter = "/usr/bin/nsenter"
-	lvm     = "/sbin/lvm"
-)
-
 // ErrNotFound is returned when a VG or LV is not found.
 var ErrNotFound = errors.New("not found")
 
+// ErrVolumeGroupNotEmpty is returned when a volume group to be removed still has logical volumes.
+var ErrVolumeGroupNotEmpty = errors.New("volume group has logical volumes")
+
 // ErrNoMultipleOfSectorSize is returned when a volume is requested that is smaller than the minimum sector size.
 var ErrNoMultipleOfSectorSize = fmt.Errorf("cannot create volume as given size "+
 	"is not a multiple of %d and could get rejected", topolvm.MinimumSectorSize)
 
+const (
+	// copyBlockSize is the block size of dd copying volumes, which skips the blocks of zeroes.
+	copyBlockSize = 1 << 20
+	// copyChunkSize is the size copied by each dd command, after which the progress is reported.
+	copyChunkSize = 1 << 30
+)
+
 // VolumeGroup represents a volume group of linux lvm.
 // The state should be considered immutable and will not automatically update.
 // The Update method should be called to refresh the state in case it is known that the state may have changed.
@@ -32,17 +39,26 @@ type VolumeGroup struct {
 }
 
 // getLVs returns the current state of lvm lvs for the given volume group.
-// If lvname is empty, all lvs are returned. Otherwise, only the lv with the given name is returned or an error if not found.
-func getLVs(ctx context.Context, vg *VolumeGroup, lvname string) (map[string]lv, error) {
-	// use fast path if we have the lvs already through the report
-	if len(vg.reportLvs) > 0 {
+// If lvname is empty, all lvs matching sel are returned. Otherwise, only the lv with the given name is returned or an error if not found.
+func getLVs(ctx context.Context, vg *VolumeGroup, lvname string, sel lvSelection) (map[string]lv, error) {
+	// use fast path if we have the lvs already through the report, even if the volume group has no lvs
+	if vg.reportLvs != nil {
 		if lvname != "" {
-			if lvFromMap, ok := vg.reportLvs[lvname]; ok {
+			if lvFromMap, ok := vg.reportLvs[lvname]; ok && sel.matches(lvFromMap) {
 				return map[string]lv{lvname: lvFromMap}, nil
 			}
 			return nil, ErrNotFound
 		}
-		return vg.reportLvs, nil
+		if sel == (lvSelection{}) {
+			return vg.reportLvs, nil
+		}
+		selected := map[string]lv{}
+		for name, l := range vg.reportLvs {
+			if sel.matches(l) {
+				selected[name] = l
+			}
+		}
+		return selected, nil
 	}
 
 	// by default, fetch all lvs for the vg
@@ -52,7 +68,7 @@ func getLVs(ctx context.Context, vg *VolumeGroup, lvname string) (map[string]lv,
 		name += "/" + lvname
 	}
 
-	return getLVReport(ctx, name)
+	return getLVReport(ctx, name, sel)
 }
 
 func (vg *VolumeGroup) Update(ctx context.Context) error {
@@ -125,9 +141,61 @@ func ListVolumeGroups(ctx context.Context) ([]*VolumeGroup, error) {
 	return groups, nil
 }
 
+// CreateVolumeGroup creates a volume group on the given devices.
+// Devices that are not physical volumes yet are initialized by lvm.
+func CreateVolumeGroup(ctx context.Context, name string, devices []string) (*VolumeGroup, error) {
+	if len(devices) == 0 {
+		return nil, errors.New("no device is given")
+	}
+	args := append([]string{"vgcreate", "--yes", name}, devices...)
+	if err := callLVM(ctx, args...); err != nil {
+		return nil, err
+	}
+	return FindVolumeGroup(ctx, name)
+}
+
+// Extend adds the given devices to this volume group.
+// Devices that are not physical volumes yet are initialized by lvm.
+func (vg *VolumeGroup) Extend(ctx context.Context, devices []string) error {
+	if len(devices) == 0 {
+		return errors.New("no device is given")
+	}
+	args := append([]string{"vgextend", "--yes", vg.Name()}, devices...)
+	if err := callLVM(ctx, args...); err != nil {
+		return err
+	}
+	return vg.Update(ctx)
+}
+
+// Reduce removes the given physical volumes from this volume group.
+// The physical volumes must not have extents allocated to logical volumes.
+func (vg *VolumeGroup) Reduce(ctx context.Context, devices []string) error {
+	if len(devices) == 0 {
+		return errors.New("no device is given")
+	}
+	args := append([]string{"vgreduce", vg.Name()}, devices...)
+	if err := callLVM(ctx, args...); err != nil {
+		return err
+	}
+	return vg.Update(ctx)
+}
+
+// Remove removes this volume group. The physical volumes are left initialized.
+// ErrVolumeGroupNotEmpty is returned if the volume group still has logical volumes.
+func (vg *VolumeGroup) Remove(ctx context.Context) error {
+	lvs, err := getLVReport(ctx, vg.Name(), lvSelection{})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	if len(lvs) != 0 {
+		return ErrVolumeGroupNotEmpty
+	}
+	return callLVM(ctx, "vgremove", vg.Name())
+}
+
 // FindVolume finds a named logical volume in this volume group.
 func (vg *VolumeGroup) FindVolume(ctx context.Context, name string) (*LogicalVolume, error) {
-	volumes, err := vg.listVolumes(ctx, name)
+	volumes, err := vg.listVolumes(ctx, name, lvSelection{})
 	if err != nil {
 		return nil, err
 	}
@@ -141,31 +209,40 @@ func (vg *VolumeGroup) FindVolume(ctx context.Context, name string) (*LogicalVol
 
 // ListVolumes lists all logical volumes in this volume group.
 func (vg *VolumeGroup) ListVolumes(ctx context.Context) (map[string]*LogicalVolume, error) {
-	return vg.listVolumes(ctx, "")
+	return vg.listVolumes(ctx, "", lvSelection{})
+}
+
+// ListVolumesWithTag lists the logical volumes in this volume group having the tag.
+func (vg *VolumeGroup) ListVolumesWithTag(ctx context.Context, tag string) (map[string]*LogicalVolume, error) {
+	return vg.listVolumes(ctx, "", lvSelection{tag: tag})
 }
 
 // listVolumes is the internal implementation for retrieving logical volumes and converting them to LogicalVolume instances.
 // It is the backing implementation for both ListVolumes and FindVolume, since the lvs command can be used for both.
-func (vg *VolumeGroup) listVolumes(ctx context.Context, name string) (map[string]*LogicalVolume, error) {
+// The volumes are filtered by lvm according to sel.
+func (vg *VolumeGroup) listVolumes(ctx context.Context, name string, sel lvSelection) (map[string]*LogicalVolume, error) {
 	ret := map[string]*LogicalVolume{}
 
-	var lvs map[string]lv
-	// use fast path if we have the lvs already through the report
-	if vg.reportLvs != nil {
-		lvs = vg.reportLvs
-	} else {
-		var err error
-		// skip ErrNotFound because an empty list is a valid response
-		if lvs, err = getLVs(ctx, vg, name); err != nil && !errors.Is(err, ErrNotFound) {
-			return nil, err
-		}
+	// skip ErrNotFound because an empty list is a valid response
+	lvs, err := getLVs(ctx, vg, name, sel)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
 
 	for _, lv := range lvs {
-		if !lv.isThinPool() {
+		if !lv.isThinPool() && !lv.isVDOPool() {
 			ret[lv.name] = vg.convertLV(lv)
 		}
 	}
+	for _, vol := range ret {
+		if vol.vdoPool == nil {
+			continue
+		}
+		// the VDO pool is only known if the whole volume group is listed.
+		if pool, ok := lvs[*vol.vdoPool]; ok {
+			vol.vdoSavingPercent = pool.vdoSavingPercent
+		}
+	}
 	return ret, nil
 }
 
@@ -182,10 +259,26 @@ func (vg *VolumeGroup) convertLV(lv lv) *LogicalVolume {
 		pool = &lv.poolLV
 	}
 
+	var vdoPool *string
+	if lv.isVDO() {
+		// pool_lv of a VDO volume is its VDO pool, not a thin pool.
+		vdoPool, pool = pool, nil
+	}
+
+	// the whole size is allocated from the volume group unless it is a thin volume.
+	allocated := lv.size
+	var snapPercent float64
 	if origin != nil && pool == nil {
 		// this volume is a snapshot, but not a thin volume.
+		// its lv_size is the size of the copy-on-write space.
 		size = lv.originSize
+		snapPercent = lv.dataPercent
 	}
+	if pool != nil {
+		allocated = uint64(float64(lv.size) * lv.dataPercent / 100)
+	}
+
+	attr, attrErr := ParsedLvAttr(lv.attr)
 
 	return &LogicalVolume{
 		fullName(lv.name, vg),
@@ -198,26 +291,51 @@ func (vg *VolumeGroup) convertLV(lv lv) *LogicalVolume {
 		uint32(lv.major),
 		uint32(lv.minor),
 		lv.tags,
-		lv.attr,
+		attr,
+		attrErr,
+		lv.copyPercent,
+		lv.cache,
+		vdoPool,
+		0,
+		snapPercent,
+		allocated,
 	}
 }
 
+// RAIDOptions holds the RAID layout of a logical volume.
+type RAIDOptions struct {
+	// Type is the RAID segment type passed to lvcreate --type, e.g. raid1.
+	Type string
+	// Mirrors is the number of additional data copies, 0 means it is not passed to lvcreate.
+	Mirrors uint
+}
+
 // CreateVolume creates logical volume in this volume group.
 // name is a name of creating volume. size is volume size in bytes. volTags is a
 // list of tags to add to the volume.
+// raid is the RAID layout of the volume, or nil for a linear or striped volume.
 // lvcreateOptions are additional arguments to pass to lvcreate.
+// devices are the physical volumes to allocate the volume from, or nil for any physical volume of this volume group.
 func (vg *VolumeGroup) CreateVolume(ctx context.Context, name string, size uint64, tags []string, stripe uint, stripeSize string,
-	lvcreateOptions []string) error {
+	raid *RAIDOptions, lvcreateOptions []string, devices []string) error {
 
 	if size%uint64(topolvm.MinimumSectorSize) != 0 {
 		return ErrNoMultipleOfSectorSize
 	}
 
-	lvcreateArgs := []string{"lvcreate", "-n", name, "-L", fmt.Sprintf("%vb", size), "-W", "y", "-y"}
+	defaults, lvcreateOptions := normalizeLvcreateOptions([]string{"-W", "y", "-y"}, lvcreateOptions)
+	lvcreateArgs := []string{"lvcreate", "-n", name, "-L", fmt.Sprintf("%vb", size)}
+	lvcreateArgs = append(lvcreateArgs, defaults...)
 	for _, tag := range tags {
 		lvcreateArgs = append(lvcreateArgs, "--addtag")
 		lvcreateArgs = append(lvcreateArgs, tag)
 	}
+	if raid != nil {
+		lvcreateArgs = append(lvcreateArgs, "--type", raid.Type)
+		if raid.Mirrors != 0 {
+			lvcreateArgs = append(lvcreateArgs, "-m", fmt.Sprintf("%d", raid.Mirrors))
+		}
+	}
 	if stripe != 0 {
 		lvcreateArgs = append(lvcreateArgs, "-i", fmt.Sprintf("%d", stripe))
 
@@ -227,8 +345,9 @@ func (vg *VolumeGroup) CreateVolume(ctx context.Context, name string, size uint6
 	}
 	lvcreateArgs = append(lvcreateArgs, lvcreateOptions...)
 	lvcreateArgs = append(lvcreateArgs, vg.Name())
+	lvcreateArgs = append(lvcreateArgs, devices...)
 
-	return callLVM(ctx, lvcreateArgs...)
+	return callLVMSettled(ctx, devicePath(name, vg), lvcreateArgs...)
 }
 
 // FindPool finds a named thin pool in this volume group.
@@ -249,22 +368,14 @@ func (vg *VolumeGroup) FindPool(ctx context.Context, name string) (*ThinPool, er
 func (vg *VolumeGroup) ListPools(ctx context.Context, poolname string) (map[string]*ThinPool, error) {
 	ret := map[string]*ThinPool{}
 
-	var lvs map[string]lv
-	// use fast path if we have the lvs already through the report
-	if vg.reportLvs != nil {
-		lvs = vg.reportLvs
-	} else {
-		var err error
-		// skip ErrNotFound because an empty list is a valid response
-		if lvs, err = getLVs(ctx, vg, poolname); err != nil && !errors.Is(err, ErrNotFound) {
-			return nil, err
-		}
+	// skip ErrNotFound because an empty list is a valid response
+	lvs, err := getLVs(ctx, vg, poolname, lvSelection{thinPools: true})
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
 	}
 
 	for _, lv := range lvs {
-		if lv.isThinPool() {
-			ret[lv.name] = newThinPool(vg, lv)
-		}
+		ret[lv.name] = newThinPool(vg, lv)
 	}
 	return ret, nil
 }
@@ -296,6 +407,11 @@ func fullName(name string, vg *VolumeGroup) string {
 	return fmt.Sprintf("%v/%v", vg.Name(), name)
 }
 
+// devicePath returns the path of the device node of the named volume, which udev creates for active volumes.
+func devicePath(name string, vg *VolumeGroup) string {
+	return path.Join("/dev", vg.Name(), name)
+}
+
 func newThinPool(vg *VolumeGroup, lvmLv lv) *ThinPool {
 	return &ThinPool{
 		vg,
@@ -347,19 +463,15 @@ func (t *ThinPool) Resize(ctx context.Context, newSize uint64) error {
 	return nil
 }
 
+// ExtendByPolicy extends the thin pool as configured by the autoextend settings of lvm.conf,
+// which dmeventd does by default when the usage of the pool crosses the autoextend threshold.
+func (t *ThinPool) ExtendByPolicy(ctx context.Context) error {
+	return callLVM(ctx, "lvextend", "--use-policies", t.FullName())
+}
+
 // ListVolumes lists all volumes in this thin pool.
 func (t *ThinPool) ListVolumes(ctx context.Context) (map[string]*LogicalVolume, error) {
-	volumes, err := t.vg.ListVolumes(ctx)
-	filteredVolumes := make(map[string]*LogicalVolume, len(volumes))
-	for _, volume := range volumes {
-		if volume.pool != nil && *volume.pool == t.Name() {
-			filteredVolumes[volume.Name()] = volume
-		}
-	}
-	if err != nil {
-		return nil, err
-	}
-	return filteredVolumes, nil
+	return t.vg.listVolumes(ctx, "", lvSelection{pool: t.Name()})
 }
 
 // FindVolume finds a named logical volume in this thin pool
@@ -377,6 +489,7 @@ func (t *ThinPool) FindVolume(ctx context.Context, name string) (*LogicalVolume,
 
 // CreateVolume creates a thin volume from this pool.
 func (t *ThinPool) CreateVolume(ctx context.Context, name string, size uint64, tags []string, stripe uint, stripeSize string, lvcreateOptions []string) error {
+	defaults, lvcreateOptions := normalizeLvcreateOptions([]string{"-W", "y", "-y"}, lvcreateOptions)
 	lvcreateArgs := []string{
 		"lvcreate",
 		"-T",
@@ -385,10 +498,8 @@ func (t *ThinPool) CreateVolume(ctx context.Context, name string, size uint64, t
 		name,
 		"-V",
 		fmt.Sprintf("%vb", size),
-		"-W",
-		"y",
-		"-y",
 	}
+	lvcreateArgs = append(lvcreateArgs, defaults...)
 	for _, tag := range tags {
 		lvcreateArgs = append(lvcreateArgs, "--addtag")
 		lvcreateArgs = append(lvcreateArgs, tag)
@@ -402,7 +513,57 @@ func (t *ThinPool) CreateVolume(ctx context.Context, name string, size uint64, t
 	}
 	lvcreateArgs = append(lvcreateArgs, lvcreateOptions...)
 
-	return callLVM(ctx, lvcreateArgs...)
+	return callLVMSettled(ctx, devicePath(name, t.vg), lvcreateArgs...)
+}
+
+// SnapshotVolumes takes thin snapshots of volumes of this pool at a single point in time.
+// snapshots maps the names of the volumes to the names of their snapshots.
+//
+// All the active volumes are suspended before the first snapshot is taken, which flushes their
+// pending I/O and freezes their filesystems, so that no volume changes until its snapshot is taken.
+// lvcreate resumes each volume after taking its snapshot, and the volumes left suspended on failure
+// are resumed before returning.
+func (t *ThinPool) SnapshotVolumes(ctx context.Context, snapshots map[string]string, tags []string) (err error) {
+	volumes, err := t.ListVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	origins := make([]*LogicalVolume, 0, len(snapshots))
+	for name := range snapshots {
+		volume, ok := volumes[name]
+		if !ok {
+			return fmt.Errorf("%w: volume %s in thin pool %s", ErrNotFound, name, t.FullName())
+		}
+		origins = append(origins, volume)
+	}
+	sort.Slice(origins, func(i, j int) bool { return origins[i].name < origins[j].name })
+
+	suspended := make(map[string]*LogicalVolume, len(origins))
+	defer func() {
+		for _, volume := range suspended {
+			if resumeErr := volume.resume(ctx); resumeErr != nil {
+				err = errors.Join(err, resumeErr)
+			}
+		}
+	}()
+	for _, volume := range origins {
+		if !volume.IsActive() {
+			continue
+		}
+		if err := volume.suspend(ctx); err != nil {
+			return err
+		}
+		suspended[volume.name] = volume
+	}
+	for _, volume := range origins {
+		if err := callLVM(ctx, volume.thinSnapshotArgs(snapshots[volume.name], tags)...); err != nil {
+			return err
+		}
+		delete(suspended, volume.name)
+	}
+	// udev is not awaited until all the volumes are resumed, as it may hang probing a suspended device.
+	settleUdev(ctx, "")
+	return nil
 }
 
 // Free on a thinpool returns used data, metadata percentages,
@@ -423,6 +584,22 @@ func (t *ThinPool) Free(ctx context.Context) (*ThinPoolUsage, error) {
 	return tpu, nil
 }
 
+// Health returns an error if the thin pool is unhealthy according to its attributes, or
+// ErrThinPoolOutOfDataSpace if its data space is used up. Thin volumes do not report the state of their pool.
+func (t *ThinPool) Health() error {
+	attr, err := ParsedLvAttr(t.state.attr)
+	if err != nil {
+		return err
+	}
+	if err := attr.VerifyHealth(); err != nil {
+		return err
+	}
+	if t.state.dataPercent >= 100 {
+		return ErrThinPoolOutOfDataSpace
+	}
+	return nil
+}
+
 // LogicalVolume represents a logical volume.
 type LogicalVolume struct {
 	fullname string
@@ -436,7 +613,21 @@ type LogicalVolume struct {
 	devMajor uint32
 	devMinor uint32
 	tags     []string
-	attr     string
+	attr     LvAttr
+	// attrErr is the error parsing lv_attr reported by lvm.
+	attrErr error
+	// copyPercent is the synchronization progress of RAID volumes.
+	copyPercent float64
+	// cache holds the statistics of cached volumes.
+	cache CacheStats
+	// vdoPool is the VDO pool of VDO volumes.
+	vdoPool *string
+	// vdoSavingPercent is the space saving of VDO volumes, which is reported on the VDO pool.
+	vdoSavingPercent float64
+	// snapPercent is the usage of the copy-on-write space of thick snapshots.
+	snapPercent float64
+	// allocated is the space allocated from the thin pool or the volume group.
+	allocated uint64
 }
 
 // Name returns a volume name.
@@ -469,6 +660,14 @@ func (l *LogicalVolume) IsSnapshot() bool {
 	return l.origin != nil
 }
 
+// OriginName returns the name of the origin if this is a snapshot, or an empty string if not.
+func (l *LogicalVolume) OriginName() string {
+	if l.origin == nil {
+		return ""
+	}
+	return *l.origin
+}
+
 // Origin returns logical volume instance if this is a snapshot, or nil if not.
 func (l *LogicalVolume) Origin(ctx context.Context) (*LogicalVolume, error) {
 	if l.origin == nil {
@@ -477,6 +676,34 @@ func (l *LogicalVolume) Origin(ctx context.Context) (*LogicalVolume, error) {
 	return l.vg.FindVolume(ctx, *l.origin)
 }
 
+// OriginChain returns the origins of this snapshot from its origin to the volume that is not a snapshot,
+// or an empty slice if this is not a snapshot. A thin snapshot may have a thin snapshot as its origin.
+// The chain ends early if an origin has been removed, because lvm forgets the origin of its snapshots then.
+func (l *LogicalVolume) OriginChain(ctx context.Context) ([]*LogicalVolume, error) {
+	chain := []*LogicalVolume{}
+	if l.origin == nil {
+		return chain, nil
+	}
+	volumes, err := l.vg.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	visited := map[string]bool{l.name: true}
+	for current := l; current.origin != nil; {
+		origin, ok := volumes[*current.origin]
+		if !ok {
+			break
+		}
+		if visited[origin.name] {
+			return nil, fmt.Errorf("origin chain of %s has a cycle at %s", l.fullname, origin.fullname)
+		}
+		visited[origin.name] = true
+		chain = append(chain, origin)
+		current = origin
+	}
+	return chain, nil
+}
+
 // IsThin checks if the volume is thin volume or not.
 func (l *LogicalVolume) IsThin() bool {
 	return l.pool != nil
@@ -505,11 +732,30 @@ func (l *LogicalVolume) Tags() []string {
 	return l.tags
 }
 
-// Attr returns the attr flag field of the logical volume.
-func (l *LogicalVolume) Attr() string {
+// Attr returns the parsed lv_attr field of the logical volume.
+func (l *LogicalVolume) Attr() LvAttr {
 	return l.attr
 }
 
+// Health returns an error if the logical volume is unhealthy according to its attributes,
+// or if lvm reported attributes which cannot be parsed.
+func (l *LogicalVolume) Health() error {
+	if l.attrErr != nil {
+		return l.attrErr
+	}
+	return l.attr.VerifyHealth()
+}
+
+// IsRAID checks if the volume is a RAID volume or not.
+func (l *LogicalVolume) IsRAID() bool {
+	return l.attr.VolumeType == VolumeTypeRAID || l.attr.VolumeType == VolumeTypeRAIDNoInitialSync
+}
+
+// CopyPercent returns the synchronization progress of a RAID volume in percent, or 0 if it is not a RAID volume.
+func (l *LogicalVolume) CopyPercent() float64 {
+	return l.copyPercent
+}
+
 // ThinSnapshot takes a thin snapshot of a volume.
 // The volume must be thinly-provisioned.
 // snapshots can be created unconditionally.
@@ -518,14 +764,122 @@ func (l *LogicalVolume) ThinSnapshot(ctx context.Context, name string, tags []st
 		return fmt.Errorf("cannot take snapshot of non-thin volume: %s", l.fullname)
 	}
 
+	return callLVMSettled(ctx, devicePath(name, l.vg), l.thinSnapshotArgs(name, tags)...)
+}
+
+func (l *LogicalVolume) thinSnapshotArgs(name string, tags []string) []string {
 	lvcreateArgs := []string{"lvcreate", "-s", "-k", "n", "-n", name, l.fullname}
 
 	for _, tag := range tags {
 		lvcreateArgs = append(lvcreateArgs, "--addtag")
 		lvcreateArgs = append(lvcreateArgs, tag)
 	}
+	return lvcreateArgs
+}
+
+// Snapshot takes a classic copy-on-write snapshot of a non-thin volume.
+// cowSize is the size of the space in bytes to store the changes of the origin and the snapshot,
+// the snapshot becomes invalid once the changes exceed the space.
+func (l *LogicalVolume) Snapshot(ctx context.Context, name string, cowSize uint64, tags []string) error {
+	if l.IsThin() {
+		return fmt.Errorf("cannot take copy-on-write snapshot of thin volume: %s", l.fullname)
+	}
+	if l.IsSnapshot() {
+		return fmt.Errorf("cannot take snapshot of snapshot volume: %s", l.fullname)
+	}
+	if cowSize%uint64(topolvm.MinimumSectorSize) != 0 {
+		return ErrNoMultipleOfSectorSize
+	}
+
+	lvcreateArgs := []string{"lvcreate", "-s", "-n", name, "-L", fmt.Sprintf("%vb", cowSize)}
+
+	for _, tag := range tags {
+		lvcreateArgs = append(lvcreateArgs, "--addtag")
+		lvcreateArgs = append(lvcreateArgs, tag)
+	}
+	lvcreateArgs = append(lvcreateArgs, l.fullname)
+
+	return callLVMSettled(ctx, devicePath(name, l.vg), lvcreateArgs...)
+}
+
+// SnapPercent returns the usage of the copy-on-write space of a thick snapshot in percent,
+// or 0 if it is not a thick snapshot.
+func (l *LogicalVolume) SnapPercent() float64 {
+	return l.snapPercent
+}
+
+// AllocatedBytes returns the space actually allocated for the volume in bytes.
+// It is the used part of the virtual size for thin volumes, the copy-on-write space for thick snapshots,
+// and the size for the other volumes.
+func (l *LogicalVolume) AllocatedBytes() uint64 {
+	return l.allocated
+}
+
+// IsOpen checks if the volume is opened, e.g. mounted or in use by another device.
+func (l *LogicalVolume) IsOpen() bool {
+	return l.attr.Open == OpenTrue
+}
+
+// IsActive checks if the volume is activated.
+func (l *LogicalVolume) IsActive() bool {
+	return l.attr.State == StateActive
+}
+
+// dmName returns the name of the device-mapper device of the volume.
+// lvm escapes the hyphens in the names of the volume group and the volume by doubling them.
+func (l *LogicalVolume) dmName() string {
+	return strings.ReplaceAll(l.vg.Name(), "-", "--") + "-" + strings.ReplaceAll(l.name, "-", "--")
+}
+
+// suspend suspends the device of the volume, which flushes pending I/O and freezes the filesystem on it.
+func (l *LogicalVolume) suspend(ctx context.Context) error {
+	return callBinary(ctx, BinaryDmsetup, "suspend", l.dmName())
+}
+
+// resume resumes the device of the volume. Resuming a device which is not suspended does nothing.
+func (l *LogicalVolume) resume(ctx context.Context) error {
+	return callBinary(ctx, BinaryDmsetup, "resume", l.dmName())
+}
+
+// IsMerging checks if the volume is a snapshot being merged into its origin.
+func (l *LogicalVolume) IsMerging() bool {
+	return l.attr.VolumeType == VolumeTypeMergingSnapshot
+}
+
+// Merge merges this snapshot back into its origin with lvconvert --merge.
+// lvm defers the merge while the origin or the snapshot is active, so both are deactivated first
+// and the origin is activated again to start the merge. The snapshot is removed once the merge
+// has completed, which happens in the background for thick snapshots.
+func (l *LogicalVolume) Merge(ctx context.Context) error {
+	if !l.IsSnapshot() {
+		return fmt.Errorf("cannot merge non-snapshot volume: %s", l.fullname)
+	}
+	origin := fullName(*l.origin, l.vg)
 
-	return callLVM(ctx, lvcreateArgs...)
+	if err := callLVM(ctx, "lvchange", "-a", "n", l.fullname, origin); err != nil {
+		return err
+	}
+	if err := callLVM(ctx, "lvconvert", "--merge", "--background", l.fullname); err != nil {
+		return err
+	}
+	return callLVM(ctx, "lvchange", "-a", "y", origin)
+}
+
+// ListCOWSnapshots lists the classic copy-on-write snapshots of the volume named origin.
+// lvm removes them together with the origin.
+func (vg *VolumeGroup) ListCOWSnapshots(ctx context.Context, origin string) ([]*LogicalVolume, error) {
+	volumes, err := vg.listVolumes(ctx, "", lvSelection{origin: origin})
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*LogicalVolume
+	for _, v := range volumes {
+		if v.IsSnapshot() && !v.IsThin() && *v.origin == origin {
+			snapshots = append(snapshots, v)
+		}
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].name < snapshots[j].name })
+	return snapshots, nil
 }
 
 // Activate activates the logical volume for desired access.
@@ -543,6 +897,102 @@ func (l *LogicalVolume) Activate(ctx context.Context, access string) error {
 	return callLVM(ctx, lvchangeArgs...)
 }
 
+// Discard discards all blocks of the active volume with blkdiscard, so that its data cannot be read from
+// the volumes allocated later on the same extents. The blocks are overwritten with zeroes instead if zero
+// is true or the device does not support discards.
+func (l *LogicalVolume) Discard(ctx context.Context, zero bool) error {
+	return DiscardDevice(ctx, l.path, zero)
+}
+
+// CopyTo copies the data of this active volume to the active volume target with dd, calling progress with
+// the bytes copied so far after each chunk of copyChunkSize. The target must not be smaller than this volume.
+// Blocks of zeroes are not written, so that a thin target only allocates the blocks holding data.
+func (l *LogicalVolume) CopyTo(ctx context.Context, target *LogicalVolume, progress func(copied uint64)) error {
+	if target.size < l.size {
+		return fmt.Errorf("cannot copy %s to smaller volume %s", l.fullname, target.fullname)
+	}
+	for offset := uint64(0); offset < l.size; offset += copyChunkSize {
+		count := l.size - offset
+		if count > copyChunkSize {
+			count = copyChunkSize
+		}
+		err := callBinary(ctx, BinaryDd, "if="+l.path, "of="+target.path,
+			fmt.Sprintf("bs=%d", copyBlockSize),
+			fmt.Sprintf("skip=%d", offset/copyBlockSize),
+			fmt.Sprintf("seek=%d", offset/copyBlockSize),
+			fmt.Sprintf("count=%d", (count+copyBlockSize-1)/copyBlockSize),
+			"iflag=direct", "oflag=direct", "conv=sparse,notrunc")
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(offset + count)
+		}
+	}
+	return nil
+}
+
+// HasActivationSkip checks if the volume is skipped by the activation of lvm, e.g. by vgchange -a y on boot.
+func (l *LogicalVolume) HasActivationSkip() bool {
+	return l.attr.SkipActivation == SkipActivationTrue
+}
+
+// SetActivationSkip sets or clears the activation skip flag of the volume.
+func (l *LogicalVolume) SetActivationSkip(ctx context.Context, skip bool) error {
+	flag := "n"
+	if skip {
+		flag = "y"
+	}
+	return callLVM(ctx, "lvchange", "-k", flag, l.fullname)
+}
+
+// ActivateIgnoringSkip activates the volume for read-write access while keeping its activation skip flag,
+// so that the volume stays inactive after a reboot until it is activated again.
+func (l *LogicalVolume) ActivateIgnoringSkip(ctx context.Context) error {
+	return callLVM(ctx, "lvchange", "-K", "-a", "y", l.fullname)
+}
+
+// Deactivate deactivates the volume, which removes its device-mapper device.
+// lvm refuses to deactivate a volume in use.
+func (l *LogicalVolume) Deactivate(ctx context.Context) error {
+	return callLVM(ctx, "lvchange", "-a", "n", l.fullname)
+}
+
+// ChangeTags adds and removes tags of this volume with lvchange --addtag and --deltag.
+// Adding a tag the volume has or removing a tag it does not have is not an error.
+// This method also updates Tags().
+func (l *LogicalVolume) ChangeTags(ctx context.Context, add, remove []string) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	lvchangeArgs := []string{"lvchange"}
+	for _, tag := range add {
+		lvchangeArgs = append(lvchangeArgs, "--addtag", tag)
+	}
+	for _, tag := range remove {
+		lvchangeArgs = append(lvchangeArgs, "--deltag", tag)
+	}
+	lvchangeArgs = append(lvchangeArgs, l.fullname)
+	if err := callLVM(ctx, lvchangeArgs...); err != nil {
+		return err
+	}
+
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[tag] = true
+	}
+	tags := make([]string, 0, len(l.tags)+len(add))
+	seen := make(map[string]bool, len(l.tags)+len(add))
+	for _, tag := range append(l.tags, add...) {
+		if !removed[tag] && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	l.tags = tags
+	return nil
+}
+
 // Resize this volume.
 // newSize is a new size of this volume in bytes.
 func (l *LogicalVolume) Resize(ctx context.Context, newSize uint64) error {
@@ -566,9 +1016,32 @@ func (l *LogicalVolume) Resize(ctx context.Context, newSize uint64) error {
 	return nil
 }
 
+// Shrink shrinks this volume and the filesystem on it to newSize.
+// The filesystem is checked and shrunk by lvm before the volume is reduced,
+// so it fails for filesystems that cannot be shrunk such as XFS, or if the data does not fit into newSize.
+func (l *LogicalVolume) Shrink(ctx context.Context, newSize uint64) error {
+	if l.size < newSize {
+		return fmt.Errorf("volume cannot be shrunk to a larger size")
+	}
+	if l.size == newSize {
+		return nil
+	}
+	if err := callLVM(ctx, "lvresize", "--resizefs", "--yes", "-L", fmt.Sprintf("%vb", newSize), l.fullname); err != nil {
+		return err
+	}
+
+	vol, err := l.vg.FindVolume(ctx, l.name)
+	if err != nil {
+		return err
+	}
+	l.size = vol.size
+
+	return nil
+}
+
 // RemoveVolume removes the given volume from the volume group.
 func (vg *VolumeGroup) RemoveVolume(ctx context.Context, name string) error {
-	err := callLVM(ctx, "lvremove", "-f", fullName(name, vg))
+	err := callLVMSettled(ctx, "", "lvremove", "-f", fullName(name, vg))
 
 	if IsLVMNotFound(err) {
 		return errors.Join(ErrNotFound, err)
@@ -580,7 +1053,7 @@ func (vg *VolumeGroup) RemoveVolume(ctx context.Context, name string) error {
 // Rename this volume.
 // This method also updates properties such as Name() or Path().
 func (l *LogicalVolume) Rename(ctx context.Context, name string) error {
-	if err := callLVM(ctx, "lvrename", l.vg.Name(), l.name, name); err != nil {
+	if err := callLVMSettled(ctx, devicePath(name, l.vg), "lvrename", l.vg.Name(), l.name, name); err != nil {
 		return err
 	}
 	l.fullname = fullName(name, l.vg)
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/topolvm/topolvm"
+)
+
+// cacheVolumeSuffix is the suffix of the cache volume attached to a logical volume.
+const cacheVolumeSuffix = "_cache"
+
+// CacheStats holds the dm-cache statistics of a cached logical volume.
+type CacheStats struct {
+	TotalBlocks uint64
+	UsedBlocks  uint64
+	DirtyBlocks uint64
+	ReadHits    uint64
+	ReadMisses  uint64
+	WriteHits   uint64
+	WriteMisses uint64
+}
+
+// Add adds the statistics of another cached volume.
+func (c *CacheStats) Add(other CacheStats) {
+	c.TotalBlocks += other.TotalBlocks
+	c.UsedBlocks += other.UsedBlocks
+	c.DirtyBlocks += other.DirtyBlocks
+	c.ReadHits += other.ReadHits
+	c.ReadMisses += other.ReadMisses
+	c.WriteHits += other.WriteHits
+	c.WriteMisses += other.WriteMisses
+}
+
+// IsCached checks if the volume is cached by dm-cache or not.
+func (l *LogicalVolume) IsCached() bool {
+	return l.attr.VolumeType == VolumeTypeCached
+}
+
+// CacheStats returns the dm-cache statistics of the volume.
+// The statistics are zero if the volume is not cached or not active.
+func (l *LogicalVolume) CacheStats() CacheStats {
+	return l.cache
+}
+
+// AttachCache creates a cache volume of cacheSize bytes on the given physical volumes
+// and attaches it to this volume with lvconvert --type cache.
+// mode is the cache mode such as writethrough or writeback, the default of lvm is used if empty.
+func (l *LogicalVolume) AttachCache(ctx context.Context, cacheSize uint64, mode string, devices []string) error {
+	if cacheSize%uint64(topolvm.MinimumSectorSize) != 0 {
+		return ErrNoMultipleOfSectorSize
+	}
+
+	cacheName := l.name + cacheVolumeSuffix
+	lvcreateArgs := []string{"lvcreate", "-n", cacheName, "-L", fmt.Sprintf("%vb", cacheSize), "-W", "y", "-y", l.vg.Name()}
+	lvcreateArgs = append(lvcreateArgs, devices...)
+	if err := callLVM(ctx, lvcreateArgs...); err != nil {
+		return fmt.Errorf("failed to create cache volume: %w", err)
+	}
+
+	lvconvertArgs := []string{"lvconvert", "-y", "--type", "cache", "--cachevol", cacheName}
+	if mode != "" {
+		lvconvertArgs = append(lvconvertArgs, "--cachemode", mode)
+	}
+	lvconvertArgs = append(lvconvertArgs, l.fullname)
+	if err := callLVM(ctx, lvconvertArgs...); err != nil {
+		err = fmt.Errorf("failed to attach cache volume: %w", err)
+		if rmErr := l.vg.RemoveVolume(ctx, cacheName); rmErr != nil {
+			err = errors.Join(err, rmErr)
+		}
+		return err
+	}
+	return nil
+}
@@ -7,36 +7,233 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var Containerized = false
 
+// DefaultCommandTimeoutKey is the key of CommandTimeouts applying to the commands not listed.
+const DefaultCommandTimeoutKey = "default"
+
+// CommandTimeouts are the timeouts of the commands by the lvm sub-command, e.g. "lvcreate", or "dmsetup".
+// A command not finishing in time is terminated with SIGTERM and killed with SIGKILL after killGracePeriod.
+// Commands without a timeout, including all commands if it is empty, are never killed.
+var CommandTimeouts = map[string]time.Duration{}
+
+// killGracePeriod is the time between SIGTERM and SIGKILL for a command that timed out.
+var killGracePeriod = 10 * time.Second
+
+// RetryPolicy is the capped exponential backoff of retrying lvm commands.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry, which doubles for each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries.
+	MaxBackoff time.Duration
+}
+
+// LockRetry is the policy of retrying the lvm commands failing on lock contention or busy devices,
+// i.e. with errors matching ErrLockContention or ErrResourceBusy.
+var LockRetry = RetryPolicy{
+	MaxRetries:     5,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// UdevSettleTimeout is the maximum time to wait for udev to process the events of the logical volumes
+// created, removed or renamed, so that their device nodes exist, or are gone, when the call returns.
+// Zero disables waiting.
+var UdevSettleTimeout time.Duration
+
+// commandTimeout returns the timeout of the lvm sub-command or dmsetup.
+func commandTimeout(name string) time.Duration {
+	if timeout, ok := CommandTimeouts[name]; ok {
+		return timeout
+	}
+	return CommandTimeouts[DefaultCommandTimeoutKey]
+}
+
+// Binary is a command run by an Executor.
+type Binary string
+
+const (
+	// BinaryLVM is lvm, whose first argument is the sub-command.
+	BinaryLVM Binary = "lvm"
+	// BinaryDmsetup is dmsetup, which suspends and resumes device-mapper devices.
+	BinaryDmsetup Binary = "dmsetup"
+	// BinaryUdevadm is udevadm, which waits for udev to settle.
+	BinaryUdevadm Binary = "udevadm"
+	// BinaryBlkdiscard is blkdiscard, which discards or zeroes block devices.
+	BinaryBlkdiscard Binary = "blkdiscard"
+	// BinaryDd is dd, which copies the data of volumes.
+	BinaryDd Binary = "dd"
+	// BinaryLsblk is lsblk, which lists block devices.
+	BinaryLsblk Binary = "lsblk"
+)
+
+// Executor runs lvm sub-commands and the other tools used along with lvm.
+// By default, the binaries of the host are executed. FakeLVM can be used
+// instead to simulate lvm in memory.
+type Executor interface {
+	// Execute runs binary with the given arguments and returns the stdout as a ReadCloser.
+	// Errors of the command itself are returned when the ReadCloser is closed.
+	Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error)
+}
+
+var (
+	executorMu sync.RWMutex
+	executor   Executor = hostExecutor{}
+)
+
+// SetExecutor replaces the Executor used for all lvm sub-commands until the returned function is called,
+// which restores the previous one. The Executor is replaced process-wide, so it is meant for tests not running in parallel.
+func SetExecutor(e Executor) (restore func()) {
+	executorMu.Lock()
+	defer executorMu.Unlock()
+	prev := executor
+	executor = e
+	return func() {
+		executorMu.Lock()
+		defer executorMu.Unlock()
+		executor = prev
+	}
+}
+
+func currentExecutor() Executor {
+	executorMu.RLock()
+	defer executorMu.RUnlock()
+	return executor
+}
+
+// hostExecutor executes the binaries of the host, wrapped with nsenter if Containerized is true.
+type hostExecutor struct{}
+
+func (hostExecutor) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	lvm, dmsetup, _ := BinaryPaths()
+	var name string
+	switch binary {
+	case BinaryLVM:
+		name = lvm
+	case BinaryDmsetup:
+		name = dmsetup
+	case BinaryUdevadm:
+		name = udevadmPath
+	case BinaryBlkdiscard:
+		name = blkdiscardPath
+	case BinaryDd:
+		name = ddPath
+	case BinaryLsblk:
+		name = lsblkPath
+	default:
+		return nil, fmt.Errorf("unknown binary %q", binary)
+	}
+	// the commands of lvm are told apart by their sub-command, the others by the binary.
+	subcommand := string(binary)
+	if binary == BinaryLVM && len(args) > 0 {
+		subcommand = args[0]
+	}
+	timeout := commandTimeout(subcommand)
+	cmd := wrapExecCommand(name, args...)
+	cmd.Env = os.Environ()
+	cmd.Env = append(cmd.Env, "LC_ALL=C")
+	output, err := runCommand(ctx, cmd, timeout)
+	if err != nil {
+		recordCommand(ctx, subcommand, err)
+		return nil, err
+	}
+	return recordingReadCloser{ReadCloser: output, ctx: ctx, subcommand: subcommand}, nil
+}
+
 // callLVM calls lvm sub-commands and prints the output to the log.
+// The command is retried according to LockRetry if it fails on lock contention or busy devices.
 func callLVM(ctx context.Context, args ...string) error {
-	return callLVMInto(ctx, nil, args...)
+	return callBinary(ctx, BinaryLVM, args...)
+}
+
+// callBinary calls binary like callLVM.
+func callBinary(ctx context.Context, binary Binary, args ...string) error {
+	backoff := LockRetry.InitialBackoff
+	for retry := 1; ; retry++ {
+		err := callBinaryInto(ctx, nil, binary, args...)
+		if err == nil || retry > LockRetry.MaxRetries ||
+			!(errors.Is(err, ErrLockContention) || errors.Is(err, ErrResourceBusy)) {
+			return err
+		}
+
+		log.FromContext(ctx).Info("retrying lvm command", "binary", binary, "args", args, "retry", retry, "backoff", backoff, "error", err.Error())
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > LockRetry.MaxBackoff {
+			backoff = LockRetry.MaxBackoff
+		}
+	}
+}
+
+// callLVMSettled calls lvm sub-commands like callLVM, then waits for udev to settle with settleUdev.
+func callLVMSettled(ctx context.Context, device string, args ...string) error {
+	if err := callLVM(ctx, args...); err != nil {
+		return err
+	}
+	settleUdev(ctx, device)
+	return nil
+}
+
+// settleUdev waits for udev to process the queued events if UdevSettleTimeout is set.
+// device is the device node expected to exist afterwards, waiting stops once it appears. It is empty
+// after removing a device node, in which case all the queued events are awaited.
+// The lvm command has already succeeded when udev does not settle in time, so it is only logged.
+func settleUdev(ctx context.Context, device string) {
+	if UdevSettleTimeout <= 0 {
+		return
+	}
+	args := []string{"settle", fmt.Sprintf("--timeout=%d", int(math.Ceil(UdevSettleTimeout.Seconds())))}
+	if device != "" {
+		args = append(args, "--exit-if-exists="+device)
+	}
+	if err := callBinaryInto(ctx, nil, BinaryUdevadm, args...); err != nil {
+		log.FromContext(ctx).Error(err, "udev did not settle", "path", device)
+	}
 }
 
 // callLVMInto calls lvm sub-commands and decodes the output via JSON into the provided struct pointer.
+// If it implements reportDecoder, the report is decoded entry by entry while it is read.
 // if the struct pointer is nil, the output will be printed to the log instead.
 func callLVMInto(ctx context.Context, into any, args ...string) error {
-	output, err := callLVMStreamed(ctx, args...)
+	return callBinaryInto(ctx, into, BinaryLVM, args...)
+}
+
+// callBinaryInto calls binary and decodes the output like callLVMInto.
+func callBinaryInto(ctx context.Context, into any, binary Binary, args ...string) error {
+	output, err := callBinaryStreamed(ctx, binary, args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute command: %v", err)
 	}
 
-	// if we don't decode the output into a struct, we can still log the command results from stdout.
-	if into == nil {
+	switch rd := into.(type) {
+	case nil:
+		// if we don't decode the output into a struct, we can still log the command results from stdout.
 		scanner := bufio.NewScanner(output)
 		for scanner.Scan() {
 			log.FromContext(ctx).Info(strings.TrimSpace(scanner.Text()))
 		}
 		err = scanner.Err()
-	} else {
+	case reportDecoder:
+		err = decodeReport(output, rd)
+		// drain the rest so that lvm does not block on writing to the pipe.
+		_, _ = io.Copy(io.Discard, output)
+	default:
 		err = json.NewDecoder(output).Decode(&into)
 	}
 	closeErr := output.Close()
@@ -48,18 +245,20 @@ func callLVMInto(ctx context.Context, into any, args ...string) error {
 // The caller is responsible for closing the ReadCloser, which will cause the command to complete.
 // Not calling close on this method will result in a resource leak.
 func callLVMStreamed(ctx context.Context, args ...string) (io.ReadCloser, error) {
-	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithCallDepth(1))
-	cmd := wrapExecCommand(lvm, args...)
-	cmd.Env = os.Environ()
-	cmd.Env = append(cmd.Env, "LC_ALL=C")
-	return runCommand(ctx, cmd)
+	return callBinaryStreamed(ctx, BinaryLVM, args...)
+}
+
+// callBinaryStreamed calls binary and returns the output like callLVMStreamed.
+func callBinaryStreamed(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	ctx = log.IntoContext(ctx, log.FromContext(ctx).WithCallDepth(2))
+	return currentExecutor().Execute(ctx, binary, args...)
 }
 
 // wrapExecCommand calls cmd with args but wrapped to run on the host with nsenter if Containerized is true.
 func wrapExecCommand(cmd string, args ...string) *exec.Cmd {
 	if Containerized {
 		args = append([]string{"-m", "-u", "-i", "-n", "-p", "-t", "1", cmd}, args...)
-		cmd = nsenter
+		_, _, cmd = BinaryPaths()
 	}
 	c := exec.Command(cmd, args...)
 	return c
@@ -68,7 +267,19 @@ func wrapExecCommand(cmd string, args ...string) *exec.Cmd {
 // runCommand runs the command and returns the stdout as a ReadCloser that also Waits for the command to finish.
 // After the Close command is called the cmd is closed and the resources are released.
 // Not calling close on this method will result in a resource leak.
-func runCommand(ctx context.Context, cmd *exec.Cmd) (io.ReadCloser, error) {
+// If timeout is not zero, the command is killed after it and Close returns ErrCommandTimeout.
+// The command is killed on the deadline of ctx as well, if it comes earlier.
+func runCommand(ctx context.Context, cmd *exec.Cmd, timeout time.Duration) (io.ReadCloser, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, fmt.Errorf("%w: deadline exceeded before running %s", ErrCommandTimeout, strings.Join(cmd.Args, " "))
+		}
+		if timeout == 0 || remaining < timeout {
+			timeout = remaining
+		}
+	}
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return nil, err
@@ -79,22 +290,83 @@ func runCommand(ctx context.Context, cmd *exec.Cmd) (io.ReadCloser, error) {
 		return nil, err
 	}
 
+	// the command runs in its own process group, so that the processes started by nsenter are killed with it.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
 	log.FromContext(ctx).Info("invoking command", "args", cmd.Args)
 	if err := cmd.Start(); err != nil {
 		_ = stdout.Close()
 		_ = stderr.Close()
 		return nil, err
 	}
+	var killer *commandKiller
+	if timeout > 0 {
+		killer = startCommandKiller(ctx, cmd.Process.Pid, timeout)
+	}
 	// Return a read closer that will wait for the command to finish when closed to release all resources.
-	return commandReadCloser{cmd: cmd, ReadCloser: stdout, stderr: stderr}, nil
+	return commandReadCloser{ctx: ctx, cmd: cmd, ReadCloser: stdout, stderr: stderr, killer: killer}, nil
+}
+
+// commandKiller terminates the process group of a command which does not finish within its timeout
+// with SIGTERM, and kills it with SIGKILL if it does not exit within killGracePeriod.
+type commandKiller struct {
+	ctx     context.Context
+	pgid    int
+	timeout time.Duration
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	timedOut bool
+	stopped  bool
+}
+
+func startCommandKiller(ctx context.Context, pgid int, timeout time.Duration) *commandKiller {
+	k := &commandKiller{ctx: ctx, pgid: pgid, timeout: timeout}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.timer = time.AfterFunc(timeout, k.terminate)
+	return k
+}
+
+func (k *commandKiller) terminate() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.stopped {
+		return
+	}
+	k.timedOut = true
+	log.FromContext(k.ctx).Info("terminating command which timed out", "timeout", k.timeout.String())
+	_ = syscall.Kill(-k.pgid, syscall.SIGTERM)
+	k.timer = time.AfterFunc(killGracePeriod, k.kill)
+}
+
+func (k *commandKiller) kill() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.stopped {
+		return
+	}
+	log.FromContext(k.ctx).Info("killing command which did not terminate", "grace_period", killGracePeriod.String())
+	_ = syscall.Kill(-k.pgid, syscall.SIGKILL)
+}
+
+// stop stops the timers and returns true if the command has timed out.
+func (k *commandKiller) stop() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.stopped = true
+	k.timer.Stop()
+	return k.timedOut
 }
 
 // commandReadCloser is a ReadCloser that calls the Wait function of the command when Close is called.
 // This is used to wait for the command the pipe before waiting for the command to finish.
 type commandReadCloser struct {
+	ctx context.Context
 	cmd *exec.Cmd
 	io.ReadCloser
 	stderr io.ReadCloser
+	killer *commandKiller
 }
 
 // Close closes stdout and stderr and waits for the command to exit. Close
@@ -106,12 +378,21 @@ func (p commandReadCloser) Close() error {
 		return err
 	}
 
-	if err := p.cmd.Wait(); err != nil {
+	err = p.cmd.Wait()
+	if p.killer != nil && p.killer.stop() {
+		return &lvmErr{
+			err:    fmt.Errorf("%w after %s: %s: %v", ErrCommandTimeout, p.killer.timeout, strings.Join(p.cmd.Args, " "), err),
+			stderr: stderr,
+		}
+	}
+	if err != nil {
 		// wait can result in an exit code error
 		return &lvmErr{
 			err:    err,
 			stderr: stderr,
 		}
 	}
+	// lvm prints warnings to stderr even if the command succeeds.
+	recordWarnings(p.ctx, stderr)
 	return nil
 }
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr/funcr"
 	"github.com/go-logr/logr/testr"
@@ -171,7 +173,7 @@ func Test_lvm_command(t *testing.T) {
 		}
 
 		t.Run("create volume with multiple of Sector Size is fine", func(t *testing.T) {
-			err = vg.CreateVolume(ctx, "test1", uint64(topolvm.MinimumSectorSize), []string{"tag"}, 0, "", nil)
+			err = vg.CreateVolume(ctx, "test1", uint64(topolvm.MinimumSectorSize), []string{"tag"}, 0, "", nil, nil, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -188,10 +190,179 @@ func Test_lvm_command(t *testing.T) {
 		})
 
 		t.Run("create volume with size not multiple of sector Size to get rejected", func(t *testing.T) {
-			err = vg.CreateVolume(ctx, "test1", uint64(topolvm.MinimumSectorSize)+1, []string{"tag"}, 0, "", nil)
+			err = vg.CreateVolume(ctx, "test1", uint64(topolvm.MinimumSectorSize)+1, []string{"tag"}, 0, "", nil, nil, nil)
 			if !errors.Is(err, ErrNoMultipleOfSectorSize) {
 				t.Fatalf("expected error to be %v, got %v", ErrNoMultipleOfSectorSize, err)
 			}
 		})
 	})
 }
+
+func TestRunCommandTimeout(t *testing.T) {
+	prev := killGracePeriod
+	killGracePeriod = 200 * time.Millisecond
+	defer func() { killGracePeriod = prev }()
+	ctx := context.Background()
+
+	// the shell and sleep ignore SIGTERM, so they have to be killed.
+	start := time.Now()
+	out, err := runCommand(ctx, exec.Command("sh", "-c", "trap '' TERM; sleep 10; echo done"), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(out)
+	err = out.Close()
+	if !errors.Is(err, ErrCommandTimeout) {
+		t.Errorf("expected ErrCommandTimeout, got %v", err)
+	}
+	if len(data) != 0 {
+		t.Errorf("unexpected output: %s", data)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the command should have been killed, took %s", elapsed)
+	}
+
+	out, err = runCommand(ctx, exec.Command("echo", "done"), time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(out)
+	if err := out.Close(); err != nil {
+		t.Errorf("a command finishing in time should succeed: %v", err)
+	}
+
+	// the deadline of the context bounds the command without a timeout.
+	deadlineCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	out, err = runCommand(deadlineCtx, exec.Command("sh", "-c", "trap '' TERM; sleep 10; echo done"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.ReadAll(out)
+	err = out.Close()
+	if !errors.Is(err, ErrCommandTimeout) || !strings.Contains(err.Error(), "sleep 10") {
+		t.Errorf("expected ErrCommandTimeout naming the command, got %v", err)
+	}
+	if _, err := runCommand(deadlineCtx, exec.Command("echo", "done"), 0); !errors.Is(err, ErrCommandTimeout) {
+		t.Errorf("expected ErrCommandTimeout for an expired deadline, got %v", err)
+	}
+}
+
+// flakyExecutor fails with stderr for the first failures calls.
+type flakyExecutor struct {
+	failures int
+	stderr   string
+	calls    int
+}
+
+func (e *flakyExecutor) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	e.calls++
+	if e.calls <= e.failures {
+		return newFakeOutput(ctx, nil, "", fakeError(5, e.stderr)), nil
+	}
+	return newFakeOutput(ctx, nil, "", nil), nil
+}
+
+func TestCallLVMRetry(t *testing.T) {
+	prevPolicy := LockRetry
+	LockRetry = RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	defer func() { LockRetry = prevPolicy }()
+	ctx := context.Background()
+
+	lockErr := `  VG myvg1 lock failed: held by other host.`
+	e := &flakyExecutor{failures: 2, stderr: lockErr}
+	defer SetExecutor(e)()
+	if err := callLVM(ctx, "lvcreate", "-n", "lv1", "myvg1"); err != nil {
+		t.Errorf("the command should succeed after the retries: %v", err)
+	}
+	if e.calls != 3 {
+		t.Errorf("unexpected number of calls: %d", e.calls)
+	}
+
+	e = &flakyExecutor{failures: 3, stderr: "  device-mapper: remove ioctl on  (253:3) failed: Device or resource busy"}
+	defer SetExecutor(e)()
+	if err := callLVM(ctx, "lvremove", "-f", "myvg1/lv1"); !errors.Is(err, ErrResourceBusy) {
+		t.Errorf("the command should fail after the retries: %v", err)
+	}
+	if e.calls != 3 {
+		t.Errorf("unexpected number of calls: %d", e.calls)
+	}
+
+	e = &flakyExecutor{failures: 1, stderr: `  Volume group "myvg1" not found`}
+	defer SetExecutor(e)()
+	if err := callLVM(ctx, "lvremove", "-f", "myvg1/lv1"); err == nil {
+		t.Error("the command should not be retried")
+	}
+	if e.calls != 1 {
+		t.Errorf("unexpected number of calls: %d", e.calls)
+	}
+}
+
+// argsExecutor records the arguments passed to an Executor.
+type argsExecutor struct {
+	Executor
+	calls []string
+}
+
+func (e *argsExecutor) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	e.calls = append(e.calls, strings.Join(append([]string{string(binary)}, args...), " "))
+	return e.Executor.Execute(ctx, binary, args...)
+}
+
+func TestUdevSettle(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("settle-vg", 4<<30)
+	e := &argsExecutor{Executor: fake}
+	defer SetExecutor(e)()
+
+	vg, err := FindVolumeGroup(ctx, "settle-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "lv1", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, call := range e.calls {
+		if strings.HasPrefix(call, "udevadm") {
+			t.Errorf("udev should not be awaited by default: %s", call)
+		}
+	}
+
+	prevTimeout := UdevSettleTimeout
+	UdevSettleTimeout = 1500 * time.Millisecond
+	defer func() { UdevSettleTimeout = prevTimeout }()
+
+	e.calls = nil
+	if err := vg.CreateVolume(ctx, "lv2", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	lv, err := vg.FindVolume(ctx, "lv2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lv.Rename(ctx, "lv3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.RemoveVolume(ctx, "lv3"); err != nil {
+		t.Fatal(err)
+	}
+
+	var settles []string
+	for _, call := range e.calls {
+		if strings.HasPrefix(call, "udevadm") {
+			settles = append(settles, call)
+		}
+	}
+	expected := []string{
+		"udevadm settle --timeout=2 --exit-if-exists=/dev/settle-vg/lv2",
+		"udevadm settle --timeout=2 --exit-if-exists=/dev/settle-vg/lv3",
+		"udevadm settle --timeout=2",
+	}
+	if strings.Join(settles, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected udevadm calls: %q", settles)
+	}
+	if lv.Path() != "/dev/settle-vg/lv3" {
+		t.Errorf("unexpected path after rename: %s", lv.Path())
+	}
+}
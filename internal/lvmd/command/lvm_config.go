@@ -0,0 +1,81 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AutoActivationVolumeListKey is the lvm configuration setting restricting the autoactivation of volumes,
+// e.g. by vgchange -aay on boot or by pvscan when udev reports a new device.
+const AutoActivationVolumeListKey = "activation/auto_activation_volume_list"
+
+// autoActivationConfigHeader is the first line of the configuration files written by WriteAutoActivationConfig.
+const autoActivationConfigHeader = "# This file is managed by TopoLVM lvmd and overwritten on its startup."
+
+// ConfigNotFoundPattern matches the error message of lvm config when a setting is not set.
+var ConfigNotFoundPattern = regexp.MustCompile(`Configuration node .* not found`)
+
+var configStringPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+
+// GetConfigList returns the strings of the array setting of lvm such as AutoActivationVolumeListKey,
+// or false if it is not set.
+func GetConfigList(ctx context.Context, key string) ([]string, bool, error) {
+	output, err := callLVMStreamed(ctx, "config", key)
+	if err != nil {
+		return nil, false, err
+	}
+	var value string
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		if _, v, ok := strings.Cut(scanner.Text(), "="); ok {
+			value = v
+		}
+	}
+	if err := errors.Join(output.Close(), scanner.Err()); err != nil {
+		if lvmErr, ok := AsLVMError(err); ok && ConfigNotFoundPattern.MatchString(lvmErr.Error()) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	list := []string{}
+	for _, m := range configStringPattern.FindAllStringSubmatch(value, -1) {
+		list = append(list, m[1])
+	}
+	return list, true, nil
+}
+
+// WriteAutoActivationConfig writes the lvm configuration file setting AutoActivationVolumeListKey to volumes.
+// The file is on the host if Containerized is true. A file not written by this function is not overwritten.
+func WriteAutoActivationConfig(file string, volumes []string) error {
+	if Containerized {
+		file = filepath.Join(hostRoot, file)
+	}
+	if data, err := os.ReadFile(file); err == nil {
+		if !strings.HasPrefix(string(data), autoActivationConfigHeader+"\n") {
+			return fmt.Errorf("refusing to overwrite %s, which is not managed by lvmd", file)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	quoted := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		quoted = append(quoted, fmt.Sprintf("%q", volume))
+	}
+	content := fmt.Sprintf("%s\nactivation {\n\tauto_activation_volume_list = [ %s ]\n}\n",
+		autoActivationConfigHeader, strings.Join(quoted, ", "))
+
+	// the file is replaced by renaming, so that lvm never reads a partially written file.
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
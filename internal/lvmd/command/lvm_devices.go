@@ -0,0 +1,107 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+)
+
+const (
+	// UseDevicesFileKey is the lvm configuration setting enabling the devices file, which lists the only
+	// devices lvm scans and uses.
+	UseDevicesFileKey = "devices/use_devicesfile"
+	// GlobalFilterKey is the lvm configuration setting of the device filter applied by all lvm commands.
+	GlobalFilterKey = "devices/global_filter"
+	// FilterKey is the lvm configuration setting of the device filter applied in addition to GlobalFilterKey.
+	FilterKey = "devices/filter"
+)
+
+// DevicesFileEntry is an entry of the lvm devices file.
+type DevicesFileEntry struct {
+	// Device is the current path of the device, or an empty string if the device is not found.
+	Device string
+	// IDType is the type of the identifier of the device, e.g. sys_wwid or devname.
+	IDType string
+	// IDName is the identifier of the device.
+	IDName string
+	// PVID is the UUID of the physical volume on the device, or an empty string if it is not a physical volume.
+	PVID string
+}
+
+// UseDevicesFile returns true if lvm uses the devices file rather than scanning all devices.
+// It is the effective setting including the default of lvm.
+func UseDevicesFile(ctx context.Context) (bool, error) {
+	output, err := callLVMStreamed(ctx, "config", "--typeconfig", "full", UseDevicesFileKey)
+	if err != nil {
+		return false, err
+	}
+	var value string
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		if _, v, ok := strings.Cut(scanner.Text(), "="); ok {
+			value = strings.TrimSpace(v)
+		}
+	}
+	if err := errors.Join(output.Close(), scanner.Err()); err != nil {
+		if lvmErr, ok := AsLVMError(err); ok && ConfigNotFoundPattern.MatchString(lvmErr.Error()) {
+			return false, nil
+		}
+		return false, err
+	}
+	return value == "1", nil
+}
+
+// ListDevicesFile returns the entries of the lvm devices file.
+func ListDevicesFile(ctx context.Context) ([]DevicesFileEntry, error) {
+	output, err := callLVMStreamed(ctx, "lvmdevices")
+	if err != nil {
+		return nil, err
+	}
+	var entries []DevicesFileEntry
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		// e.g. "  Device /dev/sdb IDTYPE=sys_wwid IDNAME=naa.5000c500a0b1c2d3 DEVNAME=/dev/sdb PVID=Tq... PART=1"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "Device" {
+			continue
+		}
+		var entry DevicesFileEntry
+		for _, field := range fields[2:] {
+			key, value, _ := strings.Cut(field, "=")
+			switch key {
+			case "IDTYPE":
+				entry.IDType = value
+			case "IDNAME":
+				entry.IDName = value
+			case "DEVNAME":
+				entry.Device = devicesFileValue(value)
+			case "PVID":
+				entry.PVID = devicesFileValue(value)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	if err := errors.Join(output.Close(), scanner.Err()); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// devicesFileValue returns the value of a field of the devices file, which is "." or "none" if unset.
+func devicesFileValue(value string) string {
+	if value == "." || value == "none" {
+		return ""
+	}
+	return value
+}
+
+// AddToDevicesFile adds the device to the lvm devices file, so that lvm scans it.
+func AddToDevicesFile(ctx context.Context, device string) error {
+	return callLVM(ctx, "lvmdevices", "--adddev", device)
+}
+
+// RemoveFromDevicesFile removes the device from the lvm devices file, so that lvm ignores it.
+func RemoveFromDevicesFile(ctx context.Context, device string) error {
+	return callLVM(ctx, "lvmdevices", "--deldev", device)
+}
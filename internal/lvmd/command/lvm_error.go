@@ -7,14 +7,55 @@ import (
 	"regexp"
 )
 
+// ErrCommandTimeout is returned when a lvm command does not finish within its timeout and is killed.
+var ErrCommandTimeout = errors.New("command timed out")
+
+// The classes of failed lvm commands. A LVMError matches them with errors.Is by its stderr output.
+var (
+	// ErrInsufficientFreeSpace is matched when the volume group or thin pool has not enough free extents.
+	ErrInsufficientFreeSpace = errors.New("insufficient free space")
+	// ErrLockContention is matched when lvm failed to acquire a lock held by another command or host.
+	ErrLockContention = errors.New("lock contention")
+	// ErrDeviceMissing is matched when a physical volume of the volume group is missing.
+	ErrDeviceMissing = errors.New("device missing")
+	// ErrResourceBusy is matched when device-mapper failed to change a device in use, e.g. by udev.
+	ErrResourceBusy = errors.New("resource busy")
+	// ErrOperationNotSupported is matched when the device does not support an operation, e.g. discards.
+	ErrOperationNotSupported = errors.New("operation not supported")
+)
+
 var (
-	// NotFoundPattern is a regular expression that matches the error message when a volume group or logical volume is not found.
+	// NotFoundPattern is a regular expression that matches the error message when a volume group, logical volume
+	// or physical volume is not found.
 	// The volume group might not be present or the logical volume might not be present in the volume group.
-	NotFoundPattern = regexp.MustCompile(`Volume group "(.*?)" not found|Failed to find logical volume "(.*?)"`)
+	NotFoundPattern = regexp.MustCompile(`Volume group "(.*?)" not found|Failed to find logical volume "(.*?)"|Failed to find physical volume "(.*?)"`)
+
+	// InsufficientFreeSpacePattern matches the error messages of lvm when allocating extents fails.
+	InsufficientFreeSpacePattern = regexp.MustCompile(`(?i)insufficient free space|Insufficient suitable allocatable extents`)
+	// LockContentionPattern matches the error messages of lvm when a lock cannot be acquired.
+	LockContentionPattern = regexp.MustCompile(`(?i)Can't get lock|Failed to lock|lock failed|Giving up waiting for lock|Resource temporarily unavailable`)
+	// ResourceBusyPattern matches the error messages of device-mapper when a device is busy.
+	ResourceBusyPattern = regexp.MustCompile(`(?i)resource busy`)
+	// OperationNotSupportedPattern matches the error messages of ioctls not supported by a device.
+	OperationNotSupportedPattern = regexp.MustCompile(`(?i)operation not supported`)
+	// DeviceMissingPattern matches the error messages of lvm when a physical volume is missing.
+	DeviceMissingPattern = regexp.MustCompile(`Couldn't find device with uuid|while PVs are missing|PV .* is missing|No device found for`)
 )
 
+// errorClasses maps the classes of failed lvm commands to the patterns of their stderr output.
+var errorClasses = []struct {
+	err     error
+	pattern *regexp.Regexp
+}{
+	{ErrInsufficientFreeSpace, InsufficientFreeSpacePattern},
+	{ErrLockContention, LockContentionPattern},
+	{ErrDeviceMissing, DeviceMissingPattern},
+	{ErrResourceBusy, ResourceBusyPattern},
+	{ErrOperationNotSupported, OperationNotSupportedPattern},
+}
+
 // IsLVMNotFound returns true if the error is a LVM recognized error and it determined that either
-// the underlying volume group or logical volume is not found.
+// the underlying volume group, logical volume or physical volume is not found.
 func IsLVMNotFound(err error) bool {
 	lvmErr, ok := AsLVMError(err)
 
@@ -58,6 +99,20 @@ func (e *lvmErr) Unwrap() error {
 	return e.err
 }
 
+// Is reports whether the stderr output of the command classifies the error as target,
+// e.g. ErrInsufficientFreeSpace. It is called by errors.Is.
+func (e *lvmErr) Is(target error) bool {
+	if len(e.stderr) == 0 {
+		return false
+	}
+	for _, class := range errorClasses {
+		if class.err == target {
+			return class.pattern.Match(e.stderr)
+		}
+	}
+	return false
+}
+
 func (e *lvmErr) ExitCode() int {
 	type exitError interface {
 		ExitCode() int
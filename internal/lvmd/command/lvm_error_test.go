@@ -0,0 +1,71 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestLVMErrorClasses(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   error
+	}{
+		{
+			name:   "insufficient free space of lvcreate",
+			stderr: `  Volume group "myvg1" has insufficient free space (255 extents): 512 required.`,
+			want:   ErrInsufficientFreeSpace,
+		},
+		{
+			name:   "insufficient free space of pvmove",
+			stderr: "  Insufficient free space: 128 extents needed, but only 64 available",
+			want:   ErrInsufficientFreeSpace,
+		},
+		{
+			name:   "lock held by another host",
+			stderr: `  VG myvg1 lock failed: held by other host.`,
+			want:   ErrLockContention,
+		},
+		{
+			name:   "lock timeout",
+			stderr: "  Giving up waiting for lock.\n  Can't get lock for myvg1.",
+			want:   ErrLockContention,
+		},
+		{
+			name:   "busy device",
+			stderr: "  device-mapper: remove ioctl on  (253:3) failed: Device or resource busy",
+			want:   ErrResourceBusy,
+		},
+		{
+			name:   "discard not supported",
+			stderr: "blkdiscard: /dev/myvg1/lv1: BLKDISCARD ioctl failed: Operation not supported",
+			want:   ErrOperationNotSupported,
+		},
+		{
+			name:   "missing physical volume",
+			stderr: "  WARNING: Couldn't find device with uuid 2x7Y7v-ZMs1-aZvT-Gd3s-0OD6-y11H-XSrIOu.",
+			want:   ErrDeviceMissing,
+		},
+		{
+			name:   "unclassified",
+			stderr: `  Logical volume "lv1" already exists in volume group "myvg1"`,
+		},
+	}
+	classes := []error{ErrInsufficientFreeSpace, ErrLockContention, ErrDeviceMissing, ErrResourceBusy, ErrOperationNotSupported}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("failed to create volume: %w", &lvmErr{err: fakeExitError(5), stderr: []byte(tt.stderr)})
+			for _, class := range classes {
+				if got := errors.Is(err, class); got != (class == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", class, got)
+				}
+			}
+		})
+	}
+
+	if errors.Is(&lvmErr{err: fakeExitError(5)}, ErrDeviceMissing) {
+		t.Error("an error without stderr should not be classified")
+	}
+}
@@ -0,0 +1,1629 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// fakeExtentSize is the extent size FakeLVM rounds volume sizes up to, which is the default of lvm.
+const fakeExtentSize = 4 << 20
+
+// FakeLVM is an Executor that simulates lvm in memory.
+// It understands the vgs/lvs/pvs/fullreport reports, lvm version, segtypes, config, lvmdevices and the lvcreate, lvremove, lvresize,
+// lvchange, lvrename, lvconvert, pvcreate, pvremove, vgcreate, vgextend, vgreduce and vgremove sub-commands
+// as well as dmsetup suspend and resume, udevadm settle, blkdiscard and dd as they are issued by this package,
+// so that lvmd services and the CSI driver can be tested without root privileges or a real LVM stack.
+//
+// Use SetExecutor to make this package use a FakeLVM.
+type FakeLVM struct {
+	mu      sync.Mutex
+	vgs     map[string]*fakeVG
+	devices map[string]*fakeDevice
+	config  map[string]string
+	// devicesFile lists the devices in the devices file, which is used if UseDevicesFileKey is set to 1.
+	devicesFile []string
+	// version is the version of lvm reported by lvm version.
+	version string
+	// segmentTypes are the segment types reported by lvm segtypes.
+	segmentTypes []string
+	serial       uint64
+	// stderr holds the warnings printed by the current command.
+	stderr strings.Builder
+}
+
+type fakeVG struct {
+	name string
+	uuid string
+	size uint64
+	lvs  map[string]*fakeLV
+}
+
+type fakeLV struct {
+	name        string
+	uuid        string
+	size        uint64
+	origin      string
+	pool        string
+	tags        []string
+	thinPool    bool
+	active      bool
+	readOnly    bool
+	minor       uint64
+	dataPercent float64
+	// metadataPercent is the metadata usage of a thin pool.
+	metadataPercent float64
+	raidType        string
+	mirrors         uint64
+	stripes         uint64
+	// cacheVol is the name of the attached cache volume.
+	cacheVol string
+	// hidden is set for cache volumes attached to another volume.
+	hidden bool
+	// vdoPool is set for VDO pools, whose VDO volume refers to them by pool.
+	vdoPool       bool
+	savingPercent float64
+	// merging is set for snapshots being merged into their origin.
+	merging bool
+	// suspended is set for volumes whose device is suspended by dmsetup.
+	suspended bool
+	// activationSkip is set for volumes not activated without --ignoreactivationskip.
+	activationSkip bool
+	// state overrides the state reported in lv_attr, e.g. to simulate invalid snapshots.
+	state State
+}
+
+// fakeDevice is a block device, which may be initialized as a physical volume.
+type fakeDevice struct {
+	name string
+	size uint64
+	// minor is the minor device number reported by lsblk.
+	minor uint32
+	// uuid is set for physical volumes.
+	uuid string
+	// vg is the name of the volume group the physical volume belongs to.
+	vg string
+	// unallocatable is set for physical volumes excluded from allocation by pvchange.
+	unallocatable bool
+	// evacuated is set for physical volumes whose extents have been moved by pvmove.
+	evacuated bool
+}
+
+// footprint returns the bytes allocated from the volume group for the volume.
+func (l *fakeLV) footprint() uint64 {
+	switch l.raidType {
+	case "raid5":
+		return l.size / l.stripes * (l.stripes + 1)
+	case "raid1", "raid10":
+		return l.size * (l.mirrors + 1)
+	}
+	return l.size
+}
+
+// NewFakeLVM returns a FakeLVM without any volume group.
+func NewFakeLVM() *FakeLVM {
+	return &FakeLVM{
+		vgs:     map[string]*fakeVG{},
+		devices: map[string]*fakeDevice{},
+		config:  map[string]string{},
+		version: "2.03.22",
+		segmentTypes: []string{
+			"linear", "striped", "snapshot", "mirror", "raid1", "raid10", "raid4", "raid5", "raid6",
+			"thin-pool", "thin", "cache-pool", "cache", "writecache", "vdo-pool", "vdo",
+		},
+	}
+}
+
+// SetVersion sets the version of lvm reported by lvm version, e.g. 2.03.11.
+func (f *FakeLVM) SetVersion(version string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.version = version
+}
+
+// SetSegmentTypes sets the segment types reported by lvm segtypes, e.g. to simulate lvm built without vdo.
+func (f *FakeLVM) SetSegmentTypes(types ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.segmentTypes = types
+}
+
+// SetConfig sets the value of the lvm configuration setting reported by lvm config, e.g. `["vg1"]` for
+// activation/auto_activation_volume_list.
+func (f *FakeLVM) SetConfig(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.config[key] = value
+}
+
+// AddDevice adds a block device of the given size in bytes, which can be initialized with pvcreate.
+func (f *FakeLVM) AddDevice(name string, size uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.devices[name] = &fakeDevice{name: name, size: size, minor: uint32(16 * len(f.devices))}
+}
+
+// AddVolumeGroup adds an empty volume group of the given size in bytes.
+func (f *FakeLVM) AddVolumeGroup(name string, size uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vgs[name] = &fakeVG{
+		name: name,
+		uuid: f.newUUID(),
+		size: size - size%fakeExtentSize,
+		lvs:  map[string]*fakeLV{},
+	}
+}
+
+// SetDataPercent sets the data usage in percent reported for a thin pool, thin volume or thick snapshot.
+// A thick snapshot being merged into its origin completes the merge when its usage drops to 0.
+func (f *FakeLVM) SetDataPercent(vgName, lvName string, percent float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	vg, l, err := f.findLV(vgName + "/" + lvName)
+	if err != nil {
+		return err
+	}
+	l.dataPercent = percent
+	if l.merging && l.active {
+		vg.progressMerge(l)
+	}
+	return nil
+}
+
+// SetMetadataPercent sets the metadata usage in percent reported for a thin pool.
+func (f *FakeLVM) SetMetadataPercent(vgName, poolName string, percent float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, l, err := f.findLV(vgName + "/" + poolName)
+	if err != nil {
+		return err
+	}
+	if !l.thinPool {
+		return fmt.Errorf("%s/%s is not a thin pool", vgName, poolName)
+	}
+	l.metadataPercent = percent
+	return nil
+}
+
+// SetState sets the state reported in lv_attr for a volume, e.g. StateInvalidSnapshot or StateThinPoolCheckNeeded,
+// which overrides its activation state. The zero State reports the activation state again.
+func (f *FakeLVM) SetState(vgName, lvName string, state State) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, l, err := f.findLV(vgName + "/" + lvName)
+	if err != nil {
+		return err
+	}
+	l.state = state
+	return nil
+}
+
+// SetVDOSavingPercent sets the space saving in percent reported for a VDO pool.
+func (f *FakeLVM) SetVDOSavingPercent(vgName, poolName string, percent float64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, l, err := f.findLV(vgName + "/" + poolName)
+	if err != nil {
+		return err
+	}
+	if !l.vdoPool {
+		return fakeError(5, "Logical volume %s is not a VDO pool.", poolName)
+	}
+	l.savingPercent = percent
+	return nil
+}
+
+// Execute implements Executor.
+func (f *FakeLVM) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	log.FromContext(ctx).Info("invoking command", "args", append([]string{string(binary)}, args...))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var out any
+	var stdout string
+	var err error
+	f.stderr.Reset()
+	opts := parseFakeArgs(args)
+	switch binary {
+	case BinaryLVM:
+		if len(args) == 0 {
+			return newFakeOutput(ctx, nil, "", fakeError(3, "No command specified.")), nil
+		}
+		opts = parseFakeArgs(args[1:])
+		switch args[0] {
+		case "version":
+			stdout = fmt.Sprintf("  LVM version:     %s(2) (fake)\n", f.version)
+		case "segtypes":
+			for _, t := range f.segmentTypes {
+				stdout += "  " + t + "\n"
+			}
+		case "fullreport":
+			out = f.fullReport()
+		case "vgs":
+			out, err = f.vgsReport(opts)
+		case "lvs":
+			out, err = f.lvsReport(opts)
+		case "pvs":
+			out, err = f.pvsReport(opts)
+		case "lvcreate":
+			stdout, err = f.lvcreate(opts)
+		case "lvremove":
+			stdout, err = f.lvremove(opts)
+		case "lvresize":
+			stdout, err = f.lvresize(opts)
+		case "lvchange":
+			err = f.lvchange(opts)
+		case "lvrename":
+			stdout, err = f.lvrename(opts)
+		case "lvconvert":
+			stdout, err = f.lvconvert(opts)
+		case "pvcreate":
+			stdout, err = f.pvcreate(opts)
+		case "pvremove":
+			stdout, err = f.pvremove(opts)
+		case "pvchange":
+			stdout, err = f.pvchange(opts)
+		case "pvmove":
+			stdout, err = f.pvmove(opts)
+		case "vgcreate":
+			stdout, err = f.vgcreate(opts)
+		case "vgextend":
+			stdout, err = f.vgextend(opts)
+		case "vgreduce":
+			stdout, err = f.vgreduce(opts)
+		case "vgremove":
+			stdout, err = f.vgremove(opts)
+		case "config":
+			stdout, err = f.lvmConfig(opts)
+		case "lvmdevices":
+			stdout, err = f.lvmdevices(opts)
+		default:
+			err = fakeError(3, "No such command '%s'.  Try 'help'.", args[0])
+		}
+	case BinaryDmsetup:
+		err = f.dmsetup(opts)
+	case BinaryBlkdiscard:
+		err = f.blkdiscard(opts)
+	case BinaryDd:
+		err = f.dd(opts)
+	case BinaryLsblk:
+		out = f.lsblk()
+	case BinaryUdevadm:
+		// there is no udev event to wait for.
+	default:
+		err = fakeError(127, "%s: command not found", binary)
+	}
+
+	if out != nil {
+		data, jsonErr := json.Marshal(out)
+		if jsonErr == nil && opts.value("--reportformat") == "json_std" {
+			data, jsonErr = fakeJSONStd(data)
+		}
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		return newFakeOutput(ctx, data, f.stderr.String(), err), nil
+	}
+	return newFakeOutput(ctx, []byte(stdout), f.stderr.String(), err), nil
+}
+
+func (f *FakeLVM) newUUID() string {
+	f.serial++
+	return fmt.Sprintf("fake-%026d", f.serial)
+}
+
+func (f *FakeLVM) findVG(name string) (*fakeVG, error) {
+	vg, ok := f.vgs[name]
+	if !ok {
+		return nil, fakeError(5, "Volume group \"%s\" not found", name)
+	}
+	return vg, nil
+}
+
+// findLV looks up a logical volume given as "vg/lv" or "/dev/vg/lv".
+func (f *FakeLVM) findLV(target string) (*fakeVG, *fakeLV, error) {
+	vgName, lvName, _ := strings.Cut(strings.TrimPrefix(target, "/dev/"), "/")
+	vg, err := f.findVG(vgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	l, ok := vg.lvs[lvName]
+	if !ok {
+		return nil, nil, fakeError(5, "Failed to find logical volume \"%s/%s\"", vgName, lvName)
+	}
+	return vg, l, nil
+}
+
+func (f *FakeLVM) fullReport() any {
+	reports := make([]map[string][]map[string]string, 0, len(f.vgs))
+	for _, vg := range f.sortedVGs() {
+		lvs := []map[string]string{}
+		for _, l := range vg.visibleLVs() {
+			lvs = append(lvs, vg.lvReport(l))
+		}
+		reports = append(reports, map[string][]map[string]string{
+			"vg": {vg.vgReport()},
+			"lv": lvs,
+		})
+	}
+	return map[string]any{"report": reports}
+}
+
+func (f *FakeLVM) vgsReport(opts *fakeArgs) (any, error) {
+	vgs := []map[string]string{}
+	if len(opts.positional) == 0 {
+		for _, vg := range f.sortedVGs() {
+			vgs = append(vgs, vg.vgReport())
+		}
+	}
+	for _, name := range opts.positional {
+		vg, err := f.findVG(name)
+		if err != nil {
+			return nil, err
+		}
+		vgs = append(vgs, vg.vgReport())
+	}
+	return map[string]any{"report": []map[string]any{{"vg": vgs}}}, nil
+}
+
+func (f *FakeLVM) lvsReport(opts *fakeArgs) (any, error) {
+	lvs := []map[string]string{}
+	selected := func(map[string]string) bool { return true }
+	if opts.has("-S") {
+		var err error
+		if selected, err = fakeSelection(opts.value("-S")); err != nil {
+			return nil, err
+		}
+	}
+	targets := opts.positional
+	if len(targets) == 0 {
+		for _, vg := range f.sortedVGs() {
+			targets = append(targets, vg.name)
+		}
+	}
+	for _, target := range targets {
+		if !strings.Contains(strings.TrimPrefix(target, "/dev/"), "/") {
+			vg, err := f.findVG(target)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range vg.visibleLVs() {
+				if report := vg.lvReport(l); selected(report) {
+					lvs = append(lvs, report)
+				}
+			}
+			continue
+		}
+		vg, l, err := f.findLV(target)
+		if err != nil {
+			return nil, err
+		}
+		if l.hidden {
+			// lvm renames attached cache volumes with a _cvol suffix.
+			return nil, fakeError(5, "Failed to find logical volume \"%s\"", target)
+		}
+		if report := vg.lvReport(l); selected(report) {
+			lvs = append(lvs, report)
+		}
+	}
+	return map[string]any{"report": []map[string]any{{"lv": lvs}}}, nil
+}
+
+func (f *FakeLVM) pvsReport(opts *fakeArgs) (any, error) {
+	pvs := []map[string]string{}
+	if len(opts.positional) == 0 {
+		for _, d := range f.sortedDevices() {
+			if d.uuid != "" {
+				pvs = append(pvs, f.pvReport(d))
+			}
+		}
+	}
+	for _, name := range opts.positional {
+		d, ok := f.devices[name]
+		if !ok || d.uuid == "" {
+			return nil, fakeError(5, "Failed to find physical volume \"%s\".", name)
+		}
+		pvs = append(pvs, f.pvReport(d))
+	}
+	return map[string]any{"report": []map[string]any{{"pv": pvs}}}, nil
+}
+
+func (f *FakeLVM) pvcreate(opts *fakeArgs) (string, error) {
+	if len(opts.positional) == 0 {
+		return "", fakeError(3, "Please enter a physical volume path.")
+	}
+	var out strings.Builder
+	for _, name := range opts.positional {
+		d, ok := f.devices[name]
+		if !ok {
+			return out.String(), fakeError(5, "No device found for %s.", name)
+		}
+		if d.vg != "" {
+			return out.String(), fakeError(5, "Can't initialize physical volume \"%s\" of volume group \"%s\" without -ff", name, d.vg)
+		}
+		d.uuid = f.newUUID()
+		f.addToDevicesFile(name)
+		fmt.Fprintf(&out, "  Physical volume \"%s\" successfully created.\n", name)
+	}
+	return out.String(), nil
+}
+
+func (f *FakeLVM) pvremove(opts *fakeArgs) (string, error) {
+	if len(opts.positional) == 0 {
+		return "", fakeError(3, "Please enter a physical volume path.")
+	}
+	var out strings.Builder
+	for _, name := range opts.positional {
+		d, ok := f.devices[name]
+		if !ok || d.uuid == "" {
+			return out.String(), fakeError(5, "No PV found on device %s.", name)
+		}
+		if d.vg != "" {
+			return out.String(), fakeError(5, "PV %s is used by VG %s so please use vgreduce first.", name, d.vg)
+		}
+		d.uuid = ""
+		fmt.Fprintf(&out, "  Labels on physical volume \"%s\" successfully wiped.\n", name)
+	}
+	return out.String(), nil
+}
+
+// blkdiscard checks that the device or the device of an active volume exists, the data of fake devices
+// and volumes is not stored.
+func (f *FakeLVM) blkdiscard(opts *fakeArgs) error {
+	if len(opts.positional) != 1 {
+		return fakeError(1, "no device specified")
+	}
+	if _, ok := f.devices[opts.positional[0]]; ok {
+		return nil
+	}
+	_, l, err := f.findLV(opts.positional[0])
+	if err != nil || !l.active {
+		return fakeError(1, "cannot open %s: No such file or directory", opts.positional[0])
+	}
+	return nil
+}
+
+// lsblk reports the devices as disks identified by their serial numbers. Physical volumes have the LVM2_member signature.
+func (f *FakeLVM) lsblk() any {
+	devices := make([]map[string]any, 0, len(f.devices))
+	for _, d := range f.sortedDevices() {
+		var fstype any
+		if d.uuid != "" {
+			fstype = "LVM2_member"
+		}
+		devices = append(devices, map[string]any{
+			"name":       d.name,
+			"maj:min":    fmt.Sprintf("8:%d", d.minor),
+			"size":       d.size,
+			"type":       "disk",
+			"fstype":     fstype,
+			"mountpoint": nil,
+			"wwn":        nil,
+			"serial":     "FAKE-" + path.Base(d.name),
+			"partuuid":   nil,
+		})
+	}
+	return map[string]any{"blockdevices": devices}
+}
+
+// dd checks that the volumes being copied are active. The data of the volumes is not simulated.
+func (f *FakeLVM) dd(opts *fakeArgs) error {
+	for _, operand := range opts.positional {
+		key, device, _ := strings.Cut(operand, "=")
+		if key != "if" && key != "of" {
+			continue
+		}
+		_, l, err := f.findLV(device)
+		if err != nil || !l.active {
+			return fakeError(1, "failed to open '%s': No such file or directory", device)
+		}
+	}
+	return nil
+}
+
+// lvmConfig prints the settings given by their paths.
+func (f *FakeLVM) lvmConfig(opts *fakeArgs) (string, error) {
+	var out strings.Builder
+	for _, key := range opts.positional {
+		value, ok := f.config[key]
+		if !ok {
+			return "", fakeError(5, "Configuration node %s not found", key)
+		}
+		fmt.Fprintf(&out, "%s=%s\n", path.Base(key), value)
+	}
+	return out.String(), nil
+}
+
+// lvmdevices lists the devices file, or adds or removes a device.
+func (f *FakeLVM) lvmdevices(opts *fakeArgs) (string, error) {
+	if f.config[UseDevicesFileKey] != "1" {
+		return "", fakeError(5, "Devices file not enabled.")
+	}
+	if device := opts.value("--adddev"); device != "" {
+		if _, ok := f.devices[device]; !ok {
+			return "", fakeError(5, "Device %s not found.", device)
+		}
+		f.addToDevicesFile(device)
+		return "", nil
+	}
+	if device := opts.value("--deldev"); device != "" {
+		for i, d := range f.devicesFile {
+			if d == device {
+				f.devicesFile = append(f.devicesFile[:i], f.devicesFile[i+1:]...)
+				return "", nil
+			}
+		}
+		return "", fakeError(5, "Device not found in devices file.")
+	}
+
+	var out strings.Builder
+	for _, name := range f.devicesFile {
+		pvid := "none"
+		if d, ok := f.devices[name]; ok && d.uuid != "" {
+			pvid = d.uuid
+		}
+		fmt.Fprintf(&out, "  Device %s IDTYPE=devname IDNAME=%s DEVNAME=%s PVID=%s\n", name, name, name, pvid)
+	}
+	return out.String(), nil
+}
+
+// addToDevicesFile adds the device to the devices file if it is used, as lvm does on pvcreate.
+func (f *FakeLVM) addToDevicesFile(device string) {
+	if f.config[UseDevicesFileKey] != "1" {
+		return
+	}
+	for _, d := range f.devicesFile {
+		if d == device {
+			return
+		}
+	}
+	f.devicesFile = append(f.devicesFile, device)
+}
+
+// dmsetup suspends or resumes the device of a volume given by its device-mapper name.
+func (f *FakeLVM) dmsetup(opts *fakeArgs) error {
+	if len(opts.positional) != 2 {
+		return fakeError(1, "Command not recognised.")
+	}
+	var target *fakeLV
+	for _, vg := range f.vgs {
+		for _, l := range vg.lvs {
+			if l.active && strings.ReplaceAll(vg.name, "-", "--")+"-"+strings.ReplaceAll(l.name, "-", "--") == opts.positional[1] {
+				target = l
+			}
+		}
+	}
+	if target == nil {
+		return fakeError(1, "Device %s not found", opts.positional[1])
+	}
+	switch opts.positional[0] {
+	case "suspend":
+		target.suspended = true
+	case "resume":
+		target.suspended = false
+	default:
+		return fakeError(1, "Unknown command: %s", opts.positional[0])
+	}
+	return nil
+}
+
+func (f *FakeLVM) pvchange(opts *fakeArgs) (string, error) {
+	var allocatable bool
+	switch v := opts.value("--allocatable"); v {
+	case "y":
+		allocatable = true
+	case "n":
+	default:
+		return "", fakeError(3, "Invalid argument for --allocatable: %s", v)
+	}
+	var out strings.Builder
+	for _, name := range opts.positional {
+		d, ok := f.devices[name]
+		if !ok || d.uuid == "" {
+			return out.String(), fakeError(5, "Failed to find physical volume \"%s\".", name)
+		}
+		if d.vg == "" {
+			return out.String(), fakeError(5, "Allocatability not supported by orphan lvm2 format PV %s", name)
+		}
+		d.unallocatable = !allocatable
+		if allocatable {
+			d.evacuated = false
+		}
+		fmt.Fprintf(&out, "  Physical volume \"%s\" changed\n", name)
+	}
+	return out.String(), nil
+}
+
+// pvmove moves the extents of a physical volume at once, printing the progress as pvmove does.
+// Destinations are not taken into account.
+func (f *FakeLVM) pvmove(opts *fakeArgs) (string, error) {
+	if len(opts.positional) == 0 {
+		return "", fakeError(5, "No data to move for any volume group")
+	}
+	name := opts.positional[0]
+	d, ok := f.devices[name]
+	if !ok || d.uuid == "" {
+		return "", fakeError(5, "Failed to find physical volume \"%s\".", name)
+	}
+	vg, err := f.findVG(d.vg)
+	if err != nil {
+		return "", err
+	}
+	size := d.size - d.size%fakeExtentSize
+	used := size - f.pvFree(d)
+	if used == 0 {
+		return "", fakeError(5, "No data to move for %s.", vg.name)
+	}
+	if vg.free()-f.pvFree(d) < used {
+		return "", fakeError(5, "Insufficient free space: %d extents needed, but only %d available",
+			used/fakeExtentSize, (vg.free()-f.pvFree(d))/fakeExtentSize)
+	}
+	d.evacuated = true
+	return fmt.Sprintf("  %s: Moved: 50.00%%\n  %s: Moved: 100.00%%\n", name, name), nil
+}
+
+func (f *FakeLVM) vgcreate(opts *fakeArgs) (string, error) {
+	if len(opts.positional) < 2 {
+		return "", fakeError(3, "Please provide volume group name and physical volumes")
+	}
+	name, devices := opts.positional[0], opts.positional[1:]
+	if _, ok := f.vgs[name]; ok {
+		return "", fakeError(5, "A volume group called %s already exists.", name)
+	}
+	vg := &fakeVG{name: name, uuid: f.newUUID(), lvs: map[string]*fakeLV{}}
+	if err := f.addPVs(vg, devices); err != nil {
+		return "", err
+	}
+	f.vgs[name] = vg
+	return fmt.Sprintf("  Volume group \"%s\" successfully created\n", name), nil
+}
+
+func (f *FakeLVM) vgextend(opts *fakeArgs) (string, error) {
+	if len(opts.positional) < 2 {
+		return "", fakeError(3, "Please enter volume group name and physical volume(s)")
+	}
+	vg, err := f.findVG(opts.positional[0])
+	if err != nil {
+		return "", err
+	}
+	if err := f.addPVs(vg, opts.positional[1:]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("  Volume group \"%s\" successfully extended\n", vg.name), nil
+}
+
+// addPVs adds the devices to the volume group, initializing them as physical volumes if needed.
+func (f *FakeLVM) addPVs(vg *fakeVG, devices []string) error {
+	for _, name := range devices {
+		d, ok := f.devices[name]
+		if !ok {
+			return fakeError(5, "No device found for %s.", name)
+		}
+		if d.vg != "" {
+			return fakeError(5, "Physical volume '%s' is already in volume group '%s'", name, d.vg)
+		}
+	}
+	for _, name := range devices {
+		d := f.devices[name]
+		if d.uuid == "" {
+			d.uuid = f.newUUID()
+			f.addToDevicesFile(name)
+		}
+		d.vg = vg.name
+		vg.size += d.size - d.size%fakeExtentSize
+	}
+	return nil
+}
+
+func (f *FakeLVM) vgreduce(opts *fakeArgs) (string, error) {
+	if len(opts.positional) < 2 {
+		return "", fakeError(3, "Please give volume group name and physical volume paths")
+	}
+	vg, err := f.findVG(opts.positional[0])
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, name := range opts.positional[1:] {
+		d, ok := f.devices[name]
+		if !ok || d.vg != vg.name {
+			return out.String(), fakeError(5, "Physical Volume \"%s\" not found in Volume Group \"%s\".", name, vg.name)
+		}
+		if len(f.vgDevices(vg)) == 1 {
+			return out.String(), fakeError(5, "Can't remove final physical volume \"%s\" from volume group \"%s\"", name, vg.name)
+		}
+		if f.pvFree(d) != d.size-d.size%fakeExtentSize {
+			return out.String(), fakeError(5, "Physical volume \"%s\" still in use", name)
+		}
+		d.vg = ""
+		vg.size -= d.size - d.size%fakeExtentSize
+		fmt.Fprintf(&out, "  Removed \"%s\" from volume group \"%s\"\n", name, vg.name)
+	}
+	return out.String(), nil
+}
+
+func (f *FakeLVM) vgremove(opts *fakeArgs) (string, error) {
+	var out strings.Builder
+	for _, name := range opts.positional {
+		vg, err := f.findVG(name)
+		if err != nil {
+			return out.String(), err
+		}
+		if len(vg.lvs) != 0 && !opts.has("-f") {
+			return out.String(), fakeError(5, "Volume group \"%s\" not removed", name)
+		}
+		for _, d := range f.vgDevices(vg) {
+			d.vg = ""
+		}
+		delete(f.vgs, name)
+		fmt.Fprintf(&out, "  Volume group \"%s\" successfully removed\n", name)
+	}
+	return out.String(), nil
+}
+
+func (f *FakeLVM) lvcreate(opts *fakeArgs) (string, error) {
+	// positional arguments after the volume group are physical volumes to allocate from.
+	if len(opts.positional) == 0 {
+		return "", fakeError(3, "Please specify a volume group or logical volume.")
+	}
+	name := opts.value("-n")
+	target := opts.positional[0]
+
+	var vg *fakeVG
+	var origin *fakeLV
+	l := &fakeLV{
+		name:           name,
+		tags:           opts.values("--addtag"),
+		activationSkip: opts.value("-k") == "y",
+	}
+	l.active = opts.value("-a") != "n" && (!l.activationSkip || opts.has("-K"))
+
+	switch {
+	case opts.value("--type") == "vdo":
+		return f.lvcreateVDO(l, target, opts)
+	case opts.has("-s"):
+		originVG, originLV, err := f.findLV(target)
+		if err != nil {
+			return "", err
+		}
+		vg, origin = originVG, originLV
+		l.origin = origin.name
+		if opts.has("-L") {
+			size, err := fakeSize(opts.value("-L"))
+			if err != nil {
+				return "", err
+			}
+			l.size = size
+		} else {
+			if origin.pool == "" {
+				return "", fakeError(3, "Please specify either size or extents with snapshots.")
+			}
+			l.size = origin.size
+			l.pool = origin.pool
+		}
+	case opts.has("-T") && opts.has("-V"):
+		poolVG, pool, err := f.findLV(target)
+		if err != nil {
+			return "", err
+		}
+		if !pool.thinPool {
+			return "", fakeError(5, "Logical volume %s is not a thin pool.", pool.name)
+		}
+		size, err := fakeSize(opts.value("-V"))
+		if err != nil {
+			return "", err
+		}
+		vg = poolVG
+		l.size = size
+		l.pool = pool.name
+	case opts.has("-T"):
+		vgName, poolName, _ := strings.Cut(target, "/")
+		var err error
+		if vg, err = f.findVG(vgName); err != nil {
+			return "", err
+		}
+		if l.size, err = fakeSize(opts.value("-L")); err != nil {
+			return "", err
+		}
+		l.name = poolName
+		l.thinPool = true
+	default:
+		var err error
+		if vg, err = f.findVG(target); err != nil {
+			return "", err
+		}
+		if l.size, err = fakeSize(opts.value("-L")); err != nil {
+			return "", err
+		}
+		if err := l.setRAID(opts); err != nil {
+			return "", err
+		}
+	}
+
+	if l.name == "" {
+		return "", fakeError(3, "Please specify a name for the logical volume with -n.")
+	}
+	if _, ok := vg.lvs[l.name]; ok {
+		return "", fakeError(5, "Logical Volume \"%s\" already exists in volume group \"%s\"", l.name, vg.name)
+	}
+	if l.pool == "" && l.footprint() > vg.free() {
+		return "", fakeError(5, "Volume group \"%s\" has insufficient free space (%d extents): %d required.",
+			vg.name, vg.free()/fakeExtentSize, l.footprint()/fakeExtentSize)
+	}
+
+	l.uuid = f.newUUID()
+	l.minor = f.serial
+	vg.lvs[l.name] = l
+	if origin != nil {
+		// lvm suspends the origin to take the snapshot and resumes it afterwards.
+		origin.suspended = false
+	}
+	f.warnOverprovisioned(vg, vg.lvs[l.pool])
+	return fmt.Sprintf("  Logical volume \"%s\" created.\n", l.name), nil
+}
+
+// warnOverprovisioned prints the warning of lvm if the thin volumes of pool exceed its size.
+func (f *FakeLVM) warnOverprovisioned(vg *fakeVG, pool *fakeLV) {
+	if pool == nil || !pool.thinPool {
+		return
+	}
+	var virtual uint64
+	for _, l := range vg.lvs {
+		if l.pool == pool.name {
+			virtual += l.size
+		}
+	}
+	if virtual > pool.size {
+		fmt.Fprintf(&f.stderr, "  WARNING: Sum of all thin volume sizes (%.2f GiB) exceeds the size of thin pool %s/%s (%.2f GiB).\n",
+			float64(virtual)/(1<<30), vg.name, pool.name, float64(pool.size)/(1<<30))
+	}
+}
+
+// lvcreateVDO creates a VDO pool given as "vg/pool" and its VDO volume.
+func (f *FakeLVM) lvcreateVDO(l *fakeLV, target string, opts *fakeArgs) (string, error) {
+	vgName, poolName, _ := strings.Cut(target, "/")
+	vg, err := f.findVG(vgName)
+	if err != nil {
+		return "", err
+	}
+	pool := &fakeLV{name: poolName, vdoPool: true, active: true}
+	if pool.size, err = fakeSize(opts.value("-L")); err != nil {
+		return "", err
+	}
+	if l.size, err = fakeSize(opts.value("-V")); err != nil {
+		return "", err
+	}
+	l.pool = poolName
+	if l.name == "" || poolName == "" {
+		return "", fakeError(3, "Please specify a name for the logical volume with -n.")
+	}
+	for _, name := range []string{l.name, poolName} {
+		if _, ok := vg.lvs[name]; ok {
+			return "", fakeError(5, "Logical Volume \"%s\" already exists in volume group \"%s\"", name, vg.name)
+		}
+	}
+	if pool.size > vg.free() {
+		return "", fakeError(5, "Volume group \"%s\" has insufficient free space (%d extents): %d required.",
+			vg.name, vg.free()/fakeExtentSize, pool.size/fakeExtentSize)
+	}
+	pool.uuid = f.newUUID()
+	pool.minor = f.serial
+	l.uuid = f.newUUID()
+	l.minor = f.serial
+	vg.lvs[pool.name] = pool
+	vg.lvs[l.name] = l
+	return fmt.Sprintf("  Logical volume \"%s\" created.\n", l.name), nil
+}
+
+// setRAID applies the RAID layout given by --type, -m and -i to the volume.
+func (l *fakeLV) setRAID(opts *fakeArgs) error {
+	l.raidType = opts.value("--type")
+	switch l.raidType {
+	case "", "linear", "striped":
+		l.raidType = ""
+		return nil
+	case "raid1", "raid5", "raid10":
+	default:
+		return fakeError(3, "Unsupported segment type %s.", l.raidType)
+	}
+	l.mirrors, l.stripes = 1, 1
+	var err error
+	if opts.has("-m") {
+		if l.mirrors, err = strconv.ParseUint(opts.value("-m"), 10, 64); err != nil {
+			return fakeError(3, "Invalid argument for --mirrors: %s", opts.value("-m"))
+		}
+	}
+	if opts.has("-i") {
+		if l.stripes, err = strconv.ParseUint(opts.value("-i"), 10, 64); err != nil {
+			return fakeError(3, "Invalid argument for --stripes: %s", opts.value("-i"))
+		}
+	}
+	if l.raidType == "raid5" && l.stripes < 2 {
+		return fakeError(3, "Minimum of 2 stripes required for raid5.")
+	}
+	return nil
+}
+
+func (f *FakeLVM) lvremove(opts *fakeArgs) (string, error) {
+	var out strings.Builder
+	for _, target := range opts.positional {
+		vg, l, err := f.findLV(target)
+		if err != nil {
+			return out.String(), err
+		}
+		for _, other := range vg.sortedLVs() {
+			switch {
+			case other.pool == l.name && (l.thinPool || l.vdoPool),
+				other.origin == l.name && other.pool == "",
+				other.name == l.cacheVol,
+				other.name == l.pool && other.vdoPool:
+				// thin volumes of a pool, COW snapshots of an origin, cache volumes,
+				// VDO volumes and their VDO pools are removed together.
+				delete(vg.lvs, other.name)
+				fmt.Fprintf(&out, "  Logical volume \"%s\" successfully removed.\n", other.name)
+			case other.origin == l.name:
+				other.origin = ""
+			}
+		}
+		delete(vg.lvs, l.name)
+		fmt.Fprintf(&out, "  Logical volume \"%s\" successfully removed.\n", l.name)
+	}
+	return out.String(), nil
+}
+
+func (f *FakeLVM) lvresize(opts *fakeArgs) (string, error) {
+	if len(opts.positional) != 1 {
+		return "", fakeError(3, "Please specify exactly one logical volume.")
+	}
+	vg, l, err := f.findLV(opts.positional[0])
+	if err != nil {
+		return "", err
+	}
+	size, err := fakeSize(opts.value("-L"))
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case size == l.size:
+		return "", fakeError(5, "New size (%d extents) matches existing size (%d extents).",
+			size/fakeExtentSize, l.size/fakeExtentSize)
+	case size < l.size && !opts.has("-f") && !opts.has("-r"):
+		return "", fakeError(5, "Logical volume %s/%s cannot be reduced without --force.", vg.name, l.name)
+	}
+	resized := *l
+	resized.size = size
+	if size > l.size && l.pool == "" && !l.vdoPool && resized.footprint()-l.footprint() > vg.free() {
+		return "", fakeError(5, "Insufficient free space: %d extents needed, but only %d available",
+			(resized.footprint()-l.footprint())/fakeExtentSize, vg.free()/fakeExtentSize)
+	}
+	l.size = size
+	f.warnOverprovisioned(vg, vg.lvs[l.pool])
+	return fmt.Sprintf("  Logical volume %s/%s successfully resized.\n", vg.name, l.name), nil
+}
+
+func (f *FakeLVM) lvchange(opts *fakeArgs) error {
+	for _, target := range opts.positional {
+		vg, l, err := f.findLV(target)
+		if err != nil {
+			return err
+		}
+		switch opts.value("-p") {
+		case "r":
+			l.readOnly = true
+		case "rw":
+			l.readOnly = false
+		}
+		switch opts.value("-k") {
+		case "y":
+			l.activationSkip = true
+		case "n":
+			l.activationSkip = false
+		}
+		switch opts.value("-a") {
+		case "y", "ay":
+			// lvm skips the activation of volumes with the activation skip flag without -K.
+			l.active = l.active || !l.activationSkip || opts.has("-K")
+		case "n":
+			l.active = false
+		}
+		for _, tag := range opts.values("--addtag") {
+			if !fakeContains(l.tags, tag) {
+				l.tags = append(l.tags, tag)
+			}
+		}
+		if deleted := opts.values("--deltag"); len(deleted) != 0 {
+			tags := l.tags[:0]
+			for _, tag := range l.tags {
+				if !fakeContains(deleted, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			l.tags = tags
+		}
+		for _, other := range vg.sortedLVs() {
+			if other.origin == l.name && other.merging && l.active {
+				// a deferred merge starts on the activation of the origin.
+				other.active = true
+				vg.progressMerge(other)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FakeLVM) lvrename(opts *fakeArgs) (string, error) {
+	if len(opts.positional) != 3 {
+		return "", fakeError(3, "Old and new logical volume names required")
+	}
+	vg, l, err := f.findLV(opts.positional[0] + "/" + opts.positional[1])
+	if err != nil {
+		return "", err
+	}
+	newName := opts.positional[2]
+	if _, ok := vg.lvs[newName]; ok {
+		return "", fakeError(5, "Logical Volume \"%s\" already exists in volume group \"%s\"", newName, vg.name)
+	}
+	for _, other := range vg.lvs {
+		if other.origin == l.name {
+			other.origin = newName
+		}
+		if l.thinPool && other.pool == l.name {
+			other.pool = newName
+		}
+	}
+	delete(vg.lvs, l.name)
+	l.name = newName
+	vg.lvs[newName] = l
+	return fmt.Sprintf("  Renamed \"%s\" to \"%s\" in volume group \"%s\"\n", opts.positional[1], newName, vg.name), nil
+}
+
+func (f *FakeLVM) lvconvert(opts *fakeArgs) (string, error) {
+	if len(opts.positional) != 1 {
+		return "", fakeError(3, "Please specify exactly one logical volume.")
+	}
+	if opts.has("--merge") {
+		return f.lvconvertMerge(opts.positional[0])
+	}
+	if opts.value("--type") != "cache" || !opts.has("--cachevol") {
+		return "", fakeError(3, "Only attaching a cache volume with --type cache --cachevol is supported.")
+	}
+	vg, l, err := f.findLV(opts.positional[0])
+	if err != nil {
+		return "", err
+	}
+	cache, ok := vg.lvs[opts.value("--cachevol")]
+	if !ok {
+		return "", fakeError(5, "Failed to find logical volume \"%s/%s\"", vg.name, opts.value("--cachevol"))
+	}
+	if l.cacheVol != "" || l.thinPool || l.pool != "" || cache.hidden {
+		return "", fakeError(5, "Logical volume %s/%s cannot be cached.", vg.name, l.name)
+	}
+	l.cacheVol = cache.name
+	cache.hidden = true
+	return fmt.Sprintf("  Logical volume %s/%s is now cached.\n", vg.name, l.name), nil
+}
+
+func (f *FakeLVM) lvconvertMerge(target string) (string, error) {
+	vg, l, err := f.findLV(target)
+	if err != nil {
+		return "", err
+	}
+	origin, ok := vg.lvs[l.origin]
+	if !ok {
+		return "", fakeError(5, "Command on LV %s/%s uses options that require LV types thin or snapshot.", vg.name, l.name)
+	}
+	// the fake does not start merges right away, but on the next activation of the origin like lvm does for in-use volumes.
+	l.merging = true
+	return fmt.Sprintf("  Merging of snapshot %s/%s will occur on next activation of %s/%s.\n",
+		vg.name, l.name, vg.name, origin.name), nil
+}
+
+// progressMerge completes the merge of the snapshot l into its origin,
+// unless it is a thick snapshot whose copy-on-write space has not been merged yet.
+func (vg *fakeVG) progressMerge(l *fakeLV) {
+	origin, ok := vg.lvs[l.origin]
+	if !ok {
+		return
+	}
+	if l.pool == "" && l.dataPercent > 0 {
+		return
+	}
+	if l.pool != "" {
+		// a merged thin snapshot replaces the origin.
+		origin.size = l.size
+	}
+	delete(vg.lvs, l.name)
+}
+
+func (f *FakeLVM) sortedVGs() []*fakeVG {
+	vgs := make([]*fakeVG, 0, len(f.vgs))
+	for _, vg := range f.vgs {
+		vgs = append(vgs, vg)
+	}
+	sort.Slice(vgs, func(i, j int) bool { return vgs[i].name < vgs[j].name })
+	return vgs
+}
+
+func (f *FakeLVM) sortedDevices() []*fakeDevice {
+	devices := make([]*fakeDevice, 0, len(f.devices))
+	for _, d := range f.devices {
+		devices = append(devices, d)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].name < devices[j].name })
+	return devices
+}
+
+// vgDevices returns the physical volumes of the volume group.
+func (f *FakeLVM) vgDevices(vg *fakeVG) []*fakeDevice {
+	var devices []*fakeDevice
+	for _, d := range f.sortedDevices() {
+		if d.vg == vg.name {
+			devices = append(devices, d)
+		}
+	}
+	return devices
+}
+
+// pvFree returns the free space of the physical volume.
+// The space allocated in the volume group is assigned to the volume group added by AddVolumeGroup
+// first and then to its physical volumes in order, where physical volumes evacuated by pvmove come last.
+func (f *FakeLVM) pvFree(d *fakeDevice) uint64 {
+	size := d.size - d.size%fakeExtentSize
+	vg, ok := f.vgs[d.vg]
+	if !ok {
+		return size
+	}
+	used := vg.size - vg.free()
+	base := vg.size
+	devices := f.vgDevices(vg)
+	for _, other := range devices {
+		base -= other.size - other.size%fakeExtentSize
+	}
+	if used <= base {
+		return size
+	}
+	used -= base
+	sort.SliceStable(devices, func(i, j int) bool { return !devices[i].evacuated && devices[j].evacuated })
+	for _, other := range devices {
+		otherSize := other.size - other.size%fakeExtentSize
+		if other == d {
+			if used >= otherSize {
+				return 0
+			}
+			return otherSize - used
+		}
+		if used <= otherSize {
+			return size
+		}
+		used -= otherSize
+	}
+	return size
+}
+
+func (vg *fakeVG) sortedLVs() []*fakeLV {
+	lvs := make([]*fakeLV, 0, len(vg.lvs))
+	for _, l := range vg.lvs {
+		lvs = append(lvs, l)
+	}
+	sort.Slice(lvs, func(i, j int) bool { return lvs[i].name < lvs[j].name })
+	return lvs
+}
+
+// visibleLVs returns the volumes shown by lvs, which does not show hidden volumes without -a.
+func (vg *fakeVG) visibleLVs() []*fakeLV {
+	var lvs []*fakeLV
+	for _, l := range vg.sortedLVs() {
+		if !l.hidden {
+			lvs = append(lvs, l)
+		}
+	}
+	return lvs
+}
+
+// free returns the space not allocated by thick volumes, thin pools and COW snapshots.
+func (vg *fakeVG) free() uint64 {
+	var used uint64
+	for _, l := range vg.lvs {
+		if l.pool == "" {
+			used += l.footprint()
+		}
+	}
+	return vg.size - used
+}
+
+func (vg *fakeVG) vgReport() map[string]string {
+	return map[string]string{
+		"vg_name": vg.name,
+		"vg_uuid": vg.uuid,
+		"vg_size": strconv.FormatUint(vg.size, 10),
+		"vg_free": strconv.FormatUint(vg.free(), 10),
+	}
+}
+
+func (f *FakeLVM) pvReport(d *fakeDevice) map[string]string {
+	attr := PvAttr{PvAllocationNone, PvExportedFalse, PvMissingFalse}
+	if d.vg != "" && !d.unallocatable {
+		attr.PvAllocation = PvAllocationAllocatable
+	}
+	size := d.size - d.size%fakeExtentSize
+	return map[string]string{
+		"pv_name":  d.name,
+		"pv_uuid":  d.uuid,
+		"vg_name":  d.vg,
+		"pv_attr":  attr.String(),
+		"pv_size":  strconv.FormatUint(size, 10),
+		"pv_free":  strconv.FormatUint(f.pvFree(d), 10),
+		"dev_size": strconv.FormatUint(d.size, 10),
+	}
+}
+
+func (vg *fakeVG) lvReport(l *fakeLV) map[string]string {
+	major, minor := "-1", "-1"
+	if l.active {
+		major, minor = "253", strconv.FormatUint(l.minor, 10)
+	}
+	var originSize string
+	if origin, ok := vg.lvs[l.origin]; ok {
+		originSize = strconv.FormatUint(origin.size, 10)
+	}
+	var dataPercent, metadataPercent, copyPercent, savingPercent string
+	if l.vdoPool {
+		savingPercent = strconv.FormatFloat(l.savingPercent, 'f', 2, 64)
+	}
+	if l.thinPool || l.pool != "" || l.origin != "" {
+		dataPercent = strconv.FormatFloat(l.dataPercent, 'f', 2, 64)
+	}
+	if l.thinPool {
+		metadataPercent = strconv.FormatFloat(l.metadataPercent, 'f', 2, 64)
+	}
+	if l.raidType != "" {
+		copyPercent = "100.00"
+	}
+	layout := "linear"
+	switch {
+	case l.thinPool:
+		layout = "thin,pool"
+	case l.vdoPool:
+		layout = "vdo,pool"
+	case l.pool != "" && vg.lvs[l.pool] != nil && vg.lvs[l.pool].vdoPool:
+		layout = "vdo"
+	case l.pool != "":
+		layout = "thin,sparse"
+	}
+	var cacheBlocks, cacheCounter string
+	if cache, ok := vg.lvs[l.cacheVol]; ok && l.active {
+		// dm-cache uses 64KiB blocks by default.
+		cacheBlocks = strconv.FormatUint(cache.size/(64<<10), 10)
+		cacheCounter = "0"
+	}
+	return map[string]string{
+		"lv_uuid":            l.uuid,
+		"lv_name":            l.name,
+		"lv_full_name":       vg.name + "/" + l.name,
+		"lv_path":            "/dev/" + vg.name + "/" + l.name,
+		"lv_size":            strconv.FormatUint(l.size, 10),
+		"lv_kernel_major":    major,
+		"lv_kernel_minor":    minor,
+		"origin":             l.origin,
+		"origin_size":        originSize,
+		"pool_lv":            l.pool,
+		"lv_tags":            strings.Join(l.tags, ","),
+		"lv_attr":            vg.lvAttr(l),
+		"lv_layout":          layout,
+		"vg_name":            vg.name,
+		"data_percent":       dataPercent,
+		"metadata_percent":   metadataPercent,
+		"copy_percent":       copyPercent,
+		"cache_total_blocks": cacheBlocks,
+		"cache_used_blocks":  cacheCounter,
+		"cache_dirty_blocks": cacheCounter,
+		"cache_read_hits":    cacheCounter,
+		"cache_read_misses":  cacheCounter,
+		"cache_write_hits":   cacheCounter,
+		"cache_write_misses": cacheCounter,
+		"vdo_saving_percent": savingPercent,
+	}
+}
+
+func (vg *fakeVG) lvAttr(l *fakeLV) string {
+	attr := []byte("-wi-------")
+	switch {
+	case l.vdoPool:
+		attr[0], attr[6] = byte(VolumeTypeVDOPool), 'v'
+	case l.pool != "" && vg.lvs[l.pool] != nil && vg.lvs[l.pool].vdoPool:
+		attr[0], attr[6] = byte(VolumeTypeVirtual), 'v'
+	case l.thinPool:
+		attr[0], attr[6], attr[7] = byte(VolumeTypeThinPool), 't', 'z'
+	case l.origin != "" && l.merging:
+		attr[0], attr[6] = byte(VolumeTypeMergingSnapshot), 's'
+		if l.pool != "" {
+			attr[6], attr[7] = 't', 'z'
+		}
+	case l.pool != "":
+		attr[0], attr[6], attr[7] = byte(VolumeTypeThinVolume), 't', 'z'
+	case l.origin != "":
+		attr[0], attr[6] = byte(VolumeTypeSnapshot), 's'
+	case l.raidType != "":
+		attr[0], attr[6] = byte(VolumeTypeRAID), OpenTargetRaid
+	case l.cacheVol != "":
+		attr[0], attr[6] = byte(VolumeTypeCached), 'C'
+	}
+	for _, other := range vg.lvs {
+		if other.origin == l.name && other.pool == "" {
+			attr[0], attr[6] = byte(VolumeTypeOrigin), 's'
+		}
+		if other.origin == l.name && other.merging {
+			attr[0] = byte(VolumeTypeOriginWithMergingSnapshot)
+		}
+	}
+	if l.readOnly {
+		attr[1] = byte(PermissionsReadOnly)
+	}
+	switch {
+	case l.state != 0:
+		attr[4] = byte(l.state)
+	case l.suspended:
+		attr[4] = byte(StateSuspended)
+	case l.active:
+		attr[4] = byte(StateActive)
+	}
+	if l.thinPool && l.dataPercent >= 100 {
+		attr[8] = byte(VolumeHealthThinPoolOutOfDataSpace)
+	}
+	if l.activationSkip {
+		attr[9] = byte(SkipActivationTrue)
+	}
+	return string(attr)
+}
+
+// fakeArgs holds the parsed arguments of a lvm sub-command.
+type fakeArgs struct {
+	opts       map[string][]string
+	positional []string
+}
+
+// fakeFlagAliases maps long options to their short form.
+var fakeFlagAliases = map[string]string{
+	"--name":                 "-n",
+	"--size":                 "-L",
+	"--virtualsize":          "-V",
+	"--thin":                 "-T",
+	"--snapshot":             "-s",
+	"--setactivationskip":    "-k",
+	"--ignoreactivationskip": "-K",
+	"--wipesignatures":       "-W",
+	"--yes":                  "-y",
+	"--force":                "-f",
+	"--resizefs":             "-r",
+	"--stripes":              "-i",
+	"--stripesize":           "-I",
+	"--activate":             "-a",
+	"--permission":           "-p",
+	"--mirrors":              "-m",
+	"--options":              "-o",
+	"--select":               "-S",
+}
+
+// fakeValueFlags are the options that take a value.
+var fakeValueFlags = map[string]bool{
+	"-n": true, "-L": true, "-V": true, "-k": true, "-W": true, "-i": true, "-I": true, "-a": true,
+	"-p": true, "-m": true, "-o": true, "-S": true, "--addtag": true, "--deltag": true, "--type": true,
+	"--cachevol": true, "--cachemode": true, "--compression": true, "--deduplication": true,
+	"--units": true, "--reportformat": true, "--configreport": true, "--interval": true, "--allocatable": true,
+	"--typeconfig": true, "--adddev": true, "--deldev": true,
+}
+
+// fakeJSONStd converts a report in the json format to the json_std format, which reports numbers as numbers,
+// undefined numbers as null and string lists as arrays.
+func fakeJSONStd(data []byte) ([]byte, error) {
+	var report any
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	var convert func(key string, value any) any
+	convert = func(key string, value any) any {
+		switch v := value.(type) {
+		case map[string]any:
+			for k, e := range v {
+				v[k] = convert(k, e)
+			}
+		case []any:
+			for i, e := range v {
+				v[i] = convert(key, e)
+			}
+		case string:
+			switch {
+			case key == "lv_tags":
+				tags := []string{}
+				if v != "" {
+					tags = strings.Split(v, ",")
+				}
+				return tags
+			case fakeNumericField(key) && v == "":
+				return nil
+			case fakeNumericField(key):
+				return json.Number(v)
+			}
+		}
+		return value
+	}
+	return json.Marshal(convert("", report))
+}
+
+// fakeNumericField returns true if the field of a report is a number.
+func fakeNumericField(key string) bool {
+	for _, suffix := range []string{"_size", "_free", "_percent", "_blocks", "_hits", "_misses", "_major", "_minor"} {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseFakeArgs(args []string) *fakeArgs {
+	parsed := &fakeArgs{opts: map[string][]string{}}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			parsed.positional = append(parsed.positional, arg)
+			continue
+		}
+		flag, value, hasValue := strings.Cut(arg, "=")
+		if alias, ok := fakeFlagAliases[flag]; ok {
+			flag = alias
+		}
+		if !hasValue && fakeValueFlags[flag] && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		parsed.opts[flag] = append(parsed.opts[flag], value)
+	}
+	return parsed
+}
+
+func (a *fakeArgs) has(flag string) bool {
+	_, ok := a.opts[flag]
+	return ok
+}
+
+func (a *fakeArgs) value(flag string) string {
+	values := a.opts[flag]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+func (a *fakeArgs) values(flag string) []string {
+	return a.opts[flag]
+}
+
+// fakeContains returns true if list contains value.
+func fakeContains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeSelection evaluates the subset of the --select syntax of lvmreport(7) used by the command layer:
+// criteria joined by "&&", each comparing a field with a quoted value or, for lists, with a set of values in braces.
+func fakeSelection(selection string) (func(report map[string]string) bool, error) {
+	type criterion struct {
+		field  string
+		values []string
+		subset bool
+	}
+	var criteria []criterion
+	for _, raw := range strings.Split(selection, "&&") {
+		field, value, ok := strings.Cut(strings.TrimSpace(raw), "=")
+		if !ok {
+			return nil, fakeError(5, "Selection syntax error at '%s'.", raw)
+		}
+		c := criterion{field: field}
+		if strings.HasPrefix(value, "{") && strings.HasSuffix(value, "}") {
+			c.subset = true
+			value = value[1 : len(value)-1]
+		}
+		for _, v := range strings.Split(value, ",") {
+			if unquoted, err := strconv.Unquote(v); err == nil {
+				v = unquoted
+			}
+			c.values = append(c.values, v)
+		}
+		criteria = append(criteria, c)
+	}
+	return func(report map[string]string) bool {
+		for _, c := range criteria {
+			if !c.subset {
+				if report[c.field] != c.values[0] {
+					return false
+				}
+				continue
+			}
+			list := strings.Split(report[c.field], ",")
+			for _, v := range c.values {
+				if !fakeContains(list, v) {
+					return false
+				}
+			}
+		}
+		return true
+	}, nil
+}
+
+// fakeSize parses a lvm size argument and rounds it up to the extent size.
+// A size without unit is in MiB like in lvm.
+func fakeSize(arg string) (uint64, error) {
+	units := map[byte]uint64{
+		'b': 1, 'B': 1, 's': 512, 'S': 512,
+		'k': 1 << 10, 'K': 1 << 10, 'm': 1 << 20, 'M': 1 << 20,
+		'g': 1 << 30, 'G': 1 << 30, 't': 1 << 40, 'T': 1 << 40,
+	}
+	multiplier := uint64(1 << 20)
+	if len(arg) > 0 {
+		if u, ok := units[arg[len(arg)-1]]; ok {
+			multiplier = u
+			arg = arg[:len(arg)-1]
+		}
+	}
+	n, err := strconv.ParseUint(arg, 10, 64)
+	if err != nil || n == 0 {
+		return 0, fakeError(3, "Invalid argument for --size: %s", arg)
+	}
+	size := n * multiplier
+	if rem := size % fakeExtentSize; rem != 0 {
+		size += fakeExtentSize - rem
+	}
+	return size, nil
+}
+
+// fakeExitError mimics the exit error of a command.
+type fakeExitError int
+
+func (e fakeExitError) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+func (e fakeExitError) ExitCode() int {
+	return int(e)
+}
+
+func fakeError(code int, format string, args ...any) error {
+	return &lvmErr{
+		err:    fakeExitError(code),
+		stderr: []byte("  " + fmt.Sprintf(format, args...)),
+	}
+}
+
+// fakeOutput is the stdout of a simulated command, which returns the error of the command on Close.
+type fakeOutput struct {
+	io.Reader
+	ctx    context.Context
+	stderr string
+	err    error
+}
+
+func newFakeOutput(ctx context.Context, data []byte, stderr string, err error) fakeOutput {
+	return fakeOutput{Reader: bytes.NewReader(data), ctx: ctx, stderr: stderr, err: err}
+}
+
+func (o fakeOutput) Close() error {
+	if o.err == nil {
+		recordWarnings(o.ctx, []byte(o.stderr))
+	}
+	return o.err
+}
@@ -0,0 +1,832 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestFakeLVM(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 10<<30)
+	defer SetExecutor(fake)()
+
+	if _, err := FindVolumeGroup(ctx, "non-existing-vg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	vg, err := FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := vg.Size(); size != 10<<30 {
+		t.Errorf("unexpected vg size: %d", size)
+	}
+
+	// thick volume, the size is rounded up to the extent size.
+	if err := vg.CreateVolume(ctx, "thick", 1<<30+4096, []string{"tag1"}, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "thick", 1<<30, nil, 0, "", nil, nil, nil); err == nil {
+		t.Error("creating a duplicated volume should fail")
+	}
+	if err := vg.CreateVolume(ctx, "too-large", 20<<30, nil, 0, "", nil, nil, nil); err == nil {
+		t.Error("creating a volume larger than the free space should fail")
+	}
+	thick, err := vg.FindVolume(ctx, "thick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thick.Size() != 1<<30+fakeExtentSize {
+		t.Errorf("unexpected size: %d", thick.Size())
+	}
+	if len(thick.Tags()) != 1 || thick.Tags()[0] != "tag1" {
+		t.Errorf("unexpected tags: %v", thick.Tags())
+	}
+	if thick.Path() != "/dev/fake-vg/thick" {
+		t.Errorf("unexpected path: %s", thick.Path())
+	}
+	if err := thick.Health(); err != nil {
+		t.Errorf("unhealthy volume %s: %v", thick.Attr(), err)
+	}
+	if err := thick.Resize(ctx, 2<<30); err != nil {
+		t.Fatal(err)
+	}
+	if thick.Size() != 2<<30 {
+		t.Errorf("unexpected size after resize: %d", thick.Size())
+	}
+	if err := thick.Resize(ctx, 1<<30); err == nil {
+		t.Error("shrinking a volume should fail")
+	}
+
+	// copy-on-write snapshot of a thick volume has the size of the origin.
+	if err := thick.Snapshot(ctx, "cow", 512<<20, nil); err != nil {
+		t.Fatal(err)
+	}
+	cow, err := vg.FindVolume(ctx, "cow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cow.IsSnapshot() || cow.IsThin() || cow.Size() != thick.Size() {
+		t.Errorf("unexpected snapshot: attr=%s, size=%d", cow.Attr(), cow.Size())
+	}
+	if cow.AllocatedBytes() != 512<<20 {
+		t.Errorf("copy-on-write space should be allocated: %d", cow.AllocatedBytes())
+	}
+	if err := cow.Snapshot(ctx, "cow-of-cow", 512<<20, nil); err == nil {
+		t.Error("taking a snapshot of a snapshot should fail")
+	}
+	snapshots, err := vg.ListCOWSnapshots(ctx, "thick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name() != "cow" {
+		t.Errorf("unexpected snapshots: %v", snapshots)
+	}
+	if err := fake.SetDataPercent(vg.Name(), "cow", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// the merge of a copy-on-write snapshot proceeds in the background.
+	if err := cow.Merge(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cow, err = vg.FindVolume(ctx, "cow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cow.IsMerging() || cow.SnapPercent() != 10 {
+		t.Errorf("unexpected merging snapshot: attr=%s, snap_percent=%f", cow.Attr(), cow.SnapPercent())
+	}
+	if err := fake.SetDataPercent(vg.Name(), "cow", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.FindVolume(ctx, "cow"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("merged snapshot should be removed: %v", err)
+	}
+
+	// RAID1 volume allocates the mirror in addition to the requested size.
+	if err := vg.CreateVolume(ctx, "mirrored", 1<<30, nil, 0, "", &RAIDOptions{Type: "raid1", Mirrors: 1}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	mirrored, err := vg.FindVolume(ctx, "mirrored")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mirrored.IsRAID() || mirrored.CopyPercent() != 100 {
+		t.Errorf("unexpected RAID volume: attr=%s, copy_percent=%f", mirrored.Attr(), mirrored.CopyPercent())
+	}
+	if err := vg.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := vg.Free(); free != 6<<30 {
+		t.Errorf("unexpected free bytes after creating a RAID volume: %d", free)
+	}
+	if err := vg.RemoveVolume(ctx, "mirrored"); err != nil {
+		t.Fatal(err)
+	}
+
+	// cached volume, the cache volume is hidden and removed together.
+	if err := vg.CreateVolume(ctx, "cached", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := vg.FindVolume(ctx, "cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cached.AttachCache(ctx, 128<<20, "writethrough", []string{"/dev/fast"}); err != nil {
+		t.Fatal(err)
+	}
+	cached, err = vg.FindVolume(ctx, "cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cached.IsCached() || cached.CacheStats().TotalBlocks != 2048 {
+		t.Errorf("unexpected cached volume: attr=%s, stats=%+v", cached.Attr(), cached.CacheStats())
+	}
+	if _, err := vg.FindVolume(ctx, "cached"+cacheVolumeSuffix); !errors.Is(err, ErrNotFound) {
+		t.Errorf("cache volume should be hidden: %v", err)
+	}
+	if err := vg.RemoveVolume(ctx, "cached"); err != nil {
+		t.Fatal(err)
+	}
+
+	// VDO volume, the VDO pool is hidden from the volumes and removed together.
+	if err := vg.CreateVDOVolume(ctx, "dedup", 4<<30, nil, VDOOptions{PhysicalSize: 1 << 30, Compression: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetVDOSavingPercent("fake-vg", "dedup"+vdoPoolSuffix, 30); err != nil {
+		t.Fatal(err)
+	}
+	volumes, err := vg.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dedup, ok := volumes["dedup"]
+	if !ok {
+		t.Fatalf("VDO volume is not listed: %v", volumes)
+	}
+	if !dedup.IsVDO() || dedup.IsThin() || dedup.Size() != 4<<30 || dedup.VDOSavingPercent() != 30 {
+		t.Errorf("unexpected VDO volume: attr=%s, size=%d, saving=%f", dedup.Attr(), dedup.Size(), dedup.VDOSavingPercent())
+	}
+	if _, ok := volumes["dedup"+vdoPoolSuffix]; ok {
+		t.Error("VDO pool should not be listed as a volume")
+	}
+	if err := vg.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := vg.Free(); free != 7<<30 {
+		t.Errorf("unexpected free bytes after creating a VDO volume: %d", free)
+	}
+	if err := vg.RemoveVolume(ctx, "dedup"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := vg.Free(); free != 8<<30 {
+		t.Errorf("VDO pool should be removed together: free=%d", free)
+	}
+
+	// thin pool, thin volume and thin snapshot
+	pool, err := vg.CreatePool(ctx, "pool", 4<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin", 8<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	thin, err := pool.FindVolume(ctx, "thin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !thin.IsThin() {
+		t.Error("thin volume should be thin")
+	}
+	if err := thin.ThinSnapshot(ctx, "snap", nil); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := vg.FindVolume(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snap.IsSnapshot() || !snap.IsThin() || snap.Size() != thin.Size() {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+	if err := snap.Activate(ctx, "ro"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetDataPercent("fake-vg", "pool", 42); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetDataPercent("fake-vg", "snap", 25); err != nil {
+		t.Fatal(err)
+	}
+
+	vgs, err := ListVolumeGroups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vg, err = SearchVolumeGroupList(vgs, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := vg.Free(); free != 4<<30 {
+		t.Errorf("unexpected free bytes: %d", free)
+	}
+	pool, err = vg.FindPool(ctx, "pool")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usage, err := pool.Free(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.DataPercent != 42 || usage.VirtualBytes != 16<<30 {
+		t.Errorf("unexpected thin pool usage: %+v", usage)
+	}
+	snap, err = vg.FindVolume(ctx, "snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Attr().Permissions != PermissionsReadOnly {
+		t.Errorf("snapshot should be read-only: %s", snap.Attr())
+	}
+	if snap.AllocatedBytes() != 2<<30 {
+		t.Errorf("unexpected allocated bytes of thin snapshot: %d", snap.AllocatedBytes())
+	}
+
+	// removal
+	if err := vg.RemoveVolume(ctx, "thin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.RemoveVolume(ctx, "thin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := vg.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	volumes, err = vg.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(volumes) != 2 {
+		t.Errorf("unexpected volumes: %v", volumes)
+	}
+}
+
+func TestFakeLVMPhysicalVolumes(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddDevice("/dev/sdb", 10<<30)
+	fake.AddDevice("/dev/sdc", 5<<30)
+	defer SetExecutor(fake)()
+
+	if _, err := FindPhysicalVolume(ctx, "/dev/sdb"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := CreatePhysicalVolume(ctx, "/dev/sdx"); err == nil {
+		t.Error("initializing a non-existing device should fail")
+	}
+
+	pv, err := CreatePhysicalVolume(ctx, "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pv.Name() != "/dev/sdb" || pv.UUID() == "" || pv.VGName() != "" {
+		t.Errorf("unexpected pv: name=%s, uuid=%s, vg=%s", pv.Name(), pv.UUID(), pv.VGName())
+	}
+	if pv.Size() != 10<<30 || pv.Free() != 10<<30 || pv.DeviceSize() != 10<<30 {
+		t.Errorf("unexpected size: size=%d, free=%d, dev=%d", pv.Size(), pv.Free(), pv.DeviceSize())
+	}
+	attr, err := ParsedPvAttr(pv.Attr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr.IsAllocatable() || attr.IsMissing() {
+		t.Errorf("unexpected attr: %s", attr)
+	}
+	if _, err := CreatePhysicalVolume(ctx, "/dev/sdc"); err != nil {
+		t.Fatal(err)
+	}
+
+	pvs, err := ListPhysicalVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pvs) != 2 || pvs[0].Name() != "/dev/sdb" || pvs[1].Name() != "/dev/sdc" {
+		t.Errorf("unexpected pvs: %v", pvs)
+	}
+
+	if err := pv.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := pv.Remove(ctx); err == nil {
+		t.Error("removing a wiped physical volume should fail")
+	}
+	if _, err := FindPhysicalVolume(ctx, "/dev/sdb"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFakeLVMVolumeGroupLifecycle(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddDevice("/dev/sdb", 1<<30)
+	fake.AddDevice("/dev/sdc", 1<<30)
+	fake.AddDevice("/dev/sdd", 1<<30)
+	defer SetExecutor(fake)()
+
+	if _, err := CreateVolumeGroup(ctx, "new-vg", nil); err == nil {
+		t.Error("creating a volume group without devices should fail")
+	}
+	vg, err := CreateVolumeGroup(ctx, "new-vg", []string{"/dev/sdb"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := vg.Size(); size != 1<<30 {
+		t.Errorf("unexpected vg size: %d", size)
+	}
+	if _, err := CreateVolumeGroup(ctx, "other-vg", []string{"/dev/sdb"}); err == nil {
+		t.Error("creating a volume group on a used physical volume should fail")
+	}
+
+	if err := vg.Extend(ctx, []string{"/dev/sdc", "/dev/sdd"}); err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := vg.Size(); size != 3<<30 {
+		t.Errorf("unexpected vg size after extension: %d", size)
+	}
+	pvs, err := vg.ListPhysicalVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pvs) != 3 {
+		t.Fatalf("unexpected pvs: %v", pvs)
+	}
+	for _, pv := range pvs {
+		attr, err := ParsedPvAttr(pv.Attr())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !attr.IsAllocatable() {
+			t.Errorf("pv %s should be allocatable", pv.Name())
+		}
+	}
+
+	// the volume is allocated from the first physical volume.
+	if err := vg.CreateVolume(ctx, "lv", 512<<20, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	pv, err := FindPhysicalVolume(ctx, "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pv.Free() != 512<<20 {
+		t.Errorf("unexpected pv free: %d", pv.Free())
+	}
+	if err := vg.Reduce(ctx, []string{"/dev/sdb"}); err == nil {
+		t.Error("removing a physical volume in use should fail")
+	}
+	if err := vg.Reduce(ctx, []string{"/dev/sdd"}); err != nil {
+		t.Fatal(err)
+	}
+	if size, _ := vg.Size(); size != 2<<30 {
+		t.Errorf("unexpected vg size after reduction: %d", size)
+	}
+	if pv, err := FindPhysicalVolume(ctx, "/dev/sdd"); err != nil || pv.VGName() != "" {
+		t.Errorf("removed physical volume should be left initialized: pv=%v, err=%v", pv, err)
+	}
+
+	if err := vg.Remove(ctx); !errors.Is(err, ErrVolumeGroupNotEmpty) {
+		t.Fatalf("expected ErrVolumeGroupNotEmpty, got %v", err)
+	}
+	if err := vg.RemoveVolume(ctx, "lv"); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.Remove(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FindVolumeGroup(ctx, "new-vg"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFakeLVMMovePhysicalVolume(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddDevice("/dev/sdb", 1<<30)
+	fake.AddDevice("/dev/sdc", 1<<30)
+	defer SetExecutor(fake)()
+
+	vg, err := CreateVolumeGroup(ctx, "move-vg", []string{"/dev/sdb", "/dev/sdc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "lv", 512<<20, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	pv, err := FindPhysicalVolume(ctx, "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pv.SetAllocatable(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	var progress []float64
+	if err := pv.Move(ctx, nil, func(percent float64) { progress = append(progress, percent) }); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(progress, []float64{50, 100}) {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+
+	pv, err = FindPhysicalVolume(ctx, "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attr, err := ParsedPvAttr(pv.Attr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr.IsAllocatable() || pv.Free() != pv.Size() {
+		t.Errorf("physical volume should be evacuated: attr=%s, free=%d", attr, pv.Free())
+	}
+	if other, err := FindPhysicalVolume(ctx, "/dev/sdc"); err != nil || other.Free() != 512<<20 {
+		t.Errorf("extents should be moved to the other physical volume: pv=%v, err=%v", other, err)
+	}
+
+	// moving an empty physical volume succeeds without running pvmove.
+	progress = nil
+	if err := pv.Move(ctx, nil, func(percent float64) { progress = append(progress, percent) }); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(progress, []float64{100}) {
+		t.Errorf("unexpected progress: %v", progress)
+	}
+	if err := vg.Reduce(ctx, []string{"/dev/sdb"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// recordingExecutor records the sub-commands passed to an Executor.
+type recordingExecutor struct {
+	Executor
+	commands []string
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	if binary != BinaryLVM && len(args) > 0 {
+		e.commands = append(e.commands, string(binary)+" "+args[0])
+	} else if len(args) > 0 {
+		e.commands = append(e.commands, args[0])
+	}
+	return e.Executor.Execute(ctx, binary, args...)
+}
+
+func TestFakeLVMSnapshotVolumes(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("snap-vg", 4<<30)
+	recorder := &recordingExecutor{Executor: fake}
+	defer SetExecutor(recorder)()
+
+	vg, err := FindVolumeGroup(ctx, "snap-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"lv-1", "lv-2"} {
+		if err := pool.CreateVolume(ctx, name, 1<<30, nil, 0, "", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recorder.commands = nil
+	err = pool.SnapshotVolumes(ctx, map[string]string{"lv-1": "lv-1-backup", "lv-2": "lv-2-backup"}, []string{"backup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshotting []string
+	for _, command := range recorder.commands {
+		if command != "lvs" && command != "vgs" {
+			snapshotting = append(snapshotting, command)
+		}
+	}
+	expected := []string{"dmsetup suspend", "dmsetup suspend", "lvcreate", "lvcreate"}
+	if !reflect.DeepEqual(snapshotting, expected) {
+		t.Errorf("all volumes should be suspended before taking the snapshots: %v", snapshotting)
+	}
+
+	volumes, err := pool.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"lv-1", "lv-2"} {
+		snapshot, ok := volumes[name+"-backup"]
+		if !ok || !snapshot.IsSnapshot() || !reflect.DeepEqual(snapshot.Tags(), []string{"backup"}) {
+			t.Errorf("snapshot of %s is not taken: %+v", name, snapshot)
+		}
+		if !volumes[name].IsActive() {
+			t.Errorf("%s should be resumed: attr=%s", name, volumes[name].Attr())
+		}
+	}
+
+	// the volumes are resumed when taking a snapshot fails.
+	err = pool.SnapshotVolumes(ctx, map[string]string{"lv-1": "lv-1-again", "lv-2": "lv-2-backup"}, nil)
+	if err == nil {
+		t.Fatal("taking a snapshot of an existing name should fail")
+	}
+	volumes, err = pool.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !volumes["lv-2"].IsActive() {
+		t.Errorf("lv-2 should be resumed: attr=%s", volumes["lv-2"].Attr())
+	}
+	if err := pool.SnapshotVolumes(ctx, map[string]string{"missing": "missing-backup"}, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unexpected error for a missing volume: %v", err)
+	}
+}
+
+func TestFakeLVMSelectVolumes(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("select-vg", 8<<30)
+	defer SetExecutor(fake)()
+
+	vg, err := FindVolumeGroup(ctx, "select-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "thick", 1<<30, []string{"a"}, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin-1", 1<<30, []string{"a", "b"}, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin-2", 1<<30, []string{"b"}, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	thick, err := vg.FindVolume(ctx, "thick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := thick.Snapshot(ctx, "thick-snap", 1<<30, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the volume group listed by ListVolumeGroups filters the volumes of the lvm state instead of calling lvs.
+	vgs, err := ListVolumeGroups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reported, err := SearchVolumeGroupList(vgs, "select-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, vg := range []*VolumeGroup{vg, reported} {
+		pools, err := vg.ListPools(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pools) != 1 || pools["pool"] == nil {
+			t.Errorf("unexpected pools: %v", pools)
+		}
+		pool := pools["pool"]
+		if pool == nil {
+			continue
+		}
+
+		thinVolumes, err := pool.ListVolumes(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(thinVolumes) != 2 || thinVolumes["thin-1"] == nil || thinVolumes["thin-2"] == nil {
+			t.Errorf("unexpected thin volumes: %v", thinVolumes)
+		}
+
+		tagged, err := vg.ListVolumesWithTag(ctx, "a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tagged) != 2 || tagged["thick"] == nil || tagged["thin-1"] == nil {
+			t.Errorf("unexpected volumes with tag: %v", tagged)
+		}
+		tagged, err = vg.ListVolumesWithTag(ctx, "c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tagged) != 0 {
+			t.Errorf("no volume should have the tag: %v", tagged)
+		}
+
+		snapshots, err := vg.ListCOWSnapshots(ctx, "thick")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(snapshots) != 1 || snapshots[0].Name() != "thick-snap" {
+			t.Errorf("unexpected snapshots: %v", snapshots)
+		}
+
+		if _, err := pool.FindVolume(ctx, "thick"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("thick volume should not be found in the pool: %v", err)
+		}
+	}
+}
+
+func TestLVSelectionString(t *testing.T) {
+	sel := lvSelection{pool: "pool", tag: "topolvm", thinPools: true}
+	expected := `pool_lv="pool" && lv_tags={"topolvm"} && lv_layout={thin,pool}`
+	if sel.String() != expected {
+		t.Errorf("unexpected selection: %s", sel.String())
+	}
+	if (lvSelection{}).String() != "" {
+		t.Errorf("zero selection should be empty: %s", lvSelection{}.String())
+	}
+}
+
+func TestListVolumeGroupsSingleReport(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("report-vg1", 4<<30)
+	fake.AddVolumeGroup("report-vg2", 4<<30)
+	recorder := &recordingExecutor{Executor: fake}
+	defer SetExecutor(recorder)()
+
+	vg, err := FindVolumeGroup(ctx, "report-vg1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// everything Watch of lvmd needs on every tick must be served by a single lvm fullreport.
+	recorder.commands = nil
+	vgs, err := ListVolumeGroups(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vgs) != 2 {
+		t.Fatalf("unexpected volume groups: %v", vgs)
+	}
+	for _, vg := range vgs {
+		if _, err := vg.ListVolumes(ctx); err != nil {
+			t.Fatal(err)
+		}
+		pools, err := vg.ListPools(ctx, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name := range pools {
+			pool, err := vg.FindPool(ctx, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			usage, err := pool.Free(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if usage.VirtualBytes != 1<<30 {
+				t.Errorf("unexpected virtual bytes of the pool: %d", usage.VirtualBytes)
+			}
+		}
+	}
+	if !reflect.DeepEqual(recorder.commands, []string{"fullreport"}) {
+		t.Errorf("lvm should be called only once: %v", recorder.commands)
+	}
+}
+
+// noDiscardExecutor fails blkdiscard without -z like a device not supporting discards.
+type noDiscardExecutor struct {
+	Executor
+	calls []string
+}
+
+func (e *noDiscardExecutor) Execute(ctx context.Context, binary Binary, args ...string) (io.ReadCloser, error) {
+	if binary == BinaryBlkdiscard && len(args) > 0 {
+		e.calls = append(e.calls, "blkdiscard "+strings.Join(args, " "))
+		if args[0] != "-z" {
+			err := fakeError(1, "blkdiscard: %s: BLKDISCARD ioctl failed: Operation not supported", args[0])
+			return newFakeOutput(ctx, nil, "", err), nil
+		}
+	}
+	return e.Executor.Execute(ctx, binary, args...)
+}
+
+func TestFakeLVMDiscard(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("discard-vg", 4<<30)
+	e := &noDiscardExecutor{Executor: fake}
+	defer SetExecutor(e)()
+
+	vg, err := FindVolumeGroup(ctx, "discard-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "lv1", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	lv, err := vg.FindVolume(ctx, "lv1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := lv.Discard(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"blkdiscard /dev/discard-vg/lv1", "blkdiscard -z /dev/discard-vg/lv1"}
+	if !reflect.DeepEqual(e.calls, expected) {
+		t.Errorf("the volume should be zeroed if discards are not supported: %v", e.calls)
+	}
+
+	e.calls = nil
+	if err := lv.Discard(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(e.calls, expected[1:]) {
+		t.Errorf("the volume should be zeroed without trying to discard it: %v", e.calls)
+	}
+
+	if err := lv.Deactivate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lv.Discard(ctx, true); err == nil {
+		t.Error("an inactive volume cannot be discarded")
+	}
+}
+
+func TestFakeLVMDetectVersion(t *testing.T) {
+	ctx := log.IntoContext(context.Background(), testr.New(t))
+
+	fake := NewFakeLVM()
+	fake.AddVolumeGroup("vg", 4<<30)
+	defer SetExecutor(fake)()
+	defer detectedVersion.Store(nil)
+
+	for _, tc := range []struct {
+		version string
+		format  string
+	}{
+		{"2.03.11", "json"},
+		{"2.03.17", "json_std"},
+		{"2.04.01", "json_std"},
+	} {
+		fake.SetVersion(tc.version)
+		version, err := DetectVersion(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version.String() != tc.version || ReportFormat() != tc.format {
+			t.Errorf("unexpected version %s and report format %s for %s", version, ReportFormat(), tc.version)
+		}
+
+		vg, err := FindVolumeGroup(ctx, "vg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := vg.CreateVolume(ctx, "lv", 1<<30, []string{"a", "b"}, 0, "", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		lv, err := vg.FindVolume(ctx, "lv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lv.Size() != 1<<30 || strings.Join(lv.Tags(), ",") != "a,b" {
+			t.Errorf("unexpected volume in %s: size=%d, tags=%v", tc.format, lv.Size(), lv.Tags())
+		}
+		if err := vg.RemoveVolume(ctx, "lv"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -50,6 +50,9 @@ const (
 	VolumeTypeThinPool                   VolumeType = 't'
 	VolumeTypeThinPoolData               VolumeType = 'T'
 	VolumeTypeThinPoolMetadata           VolumeType = 'e'
+	VolumeTypeCached                     VolumeType = 'C'
+	VolumeTypeVDOPool                    VolumeType = 'd'
+	VolumeTypeVDOPoolData                VolumeType = 'D'
 	VolumeTypeDefault                    VolumeType = '-'
 )
 
@@ -194,7 +197,7 @@ func ParsedLvAttr(raw string) (LvAttr, error) {
 
 func (l LvAttr) String() string {
 	return fmt.Sprintf(
-		"%c%c%c%c%c%c%c%c%c",
+		"%c%c%c%c%c%c%c%c%c%c",
 		l.VolumeType,
 		l.Permissions,
 		l.AllocationPolicy,
@@ -204,6 +207,7 @@ func (l LvAttr) String() string {
 		l.OpenTarget,
 		l.Zero,
 		l.VolumeHealth,
+		l.SkipActivation,
 	)
 }
 
@@ -280,3 +284,90 @@ func (l LvAttr) VerifyHealth() error {
 
 	return nil
 }
+
+// HealthAction is what a reconciler does with a logical volume in an unhealthy state.
+// The actions are ordered by severity, so that the most severe one applies to a volume with several errors.
+type HealthAction int
+
+const (
+	// HealthActionNone reconciles the healthy volume as usual.
+	HealthActionNone HealthAction = iota
+	// HealthActionSurface reports the state, but reconciles the volume as usual, since modifying it is still safe.
+	HealthActionSurface
+	// HealthActionRetry postpones modifying the volume, since the state is usually transient, e.g. a suspended device.
+	HealthActionRetry
+	// HealthActionHalt stops modifying the volume until the state is repaired on the host, e.g. by lvconvert --repair.
+	HealthActionHalt
+)
+
+func (a HealthAction) String() string {
+	switch a {
+	case HealthActionNone:
+		return "None"
+	case HealthActionSurface:
+		return "Surface"
+	case HealthActionRetry:
+		return "Retry"
+	case HealthActionHalt:
+		return "Halt"
+	}
+	return fmt.Sprintf("HealthAction(%d)", int(a))
+}
+
+// healthErrors are the errors returned by VerifyHealth, ThinPool.Health and LogicalVolume.Health
+// with the action for the volumes in the state.
+var healthErrors = []struct {
+	err    error
+	action HealthAction
+}{
+	{ErrPartialActivation, HealthActionHalt},
+	{ErrUnknownVolumeHealth, HealthActionSurface},
+	{ErrWriteCacheError, HealthActionSurface},
+	{ErrThinPoolFailed, HealthActionHalt},
+	{ErrThinPoolOutOfDataSpace, HealthActionSurface},
+	{ErrThinPoolMetadataReadOnly, HealthActionHalt},
+	{ErrThinVolumeFailed, HealthActionHalt},
+	{ErrRAIDRefreshNeeded, HealthActionSurface},
+	{ErrRAIDMismatchesExist, HealthActionSurface},
+	{ErrRAIDReshaping, HealthActionRetry},
+	{ErrRAIDReshapeRemoved, HealthActionSurface},
+	{ErrRAIDWriteMostly, HealthActionSurface},
+	{ErrLogicalVolumeSuspended, HealthActionRetry},
+	{ErrInvalidSnapshot, HealthActionHalt},
+	{ErrSnapshotMergeFailed, HealthActionHalt},
+	{ErrMappedDevicePresentWithInactiveTables, HealthActionRetry},
+	{ErrMappedDevicePresentWithoutTables, HealthActionHalt},
+	{ErrThinPoolCheckNeeded, HealthActionHalt},
+	{ErrUnknownVolumeState, HealthActionSurface},
+	{ErrHistoricalVolumeState, HealthActionSurface},
+	{ErrLogicalVolumeUnderlyingDeviceStateUnknown, HealthActionSurface},
+}
+
+// HealthActionFor returns the action for a logical volume whose health is reported by err.
+// Unknown errors are surfaced, and nil is HealthActionNone.
+func HealthActionFor(err error) HealthAction {
+	if err == nil {
+		return HealthActionNone
+	}
+	for _, h := range healthErrors {
+		if errors.Is(err, h.err) {
+			return h.action
+		}
+	}
+	return HealthActionSurface
+}
+
+// ParseHealthError returns the health error whose message is reported by lvmd, e.g. in the health_error of
+// a LogicalVolume, so that it can be passed to HealthActionFor. Unknown messages are returned as new errors,
+// and the empty message is nil.
+func ParseHealthError(message string) error {
+	if message == "" {
+		return nil
+	}
+	for _, h := range healthErrors {
+		if h.err.Error() == message {
+			return h.err
+		}
+	}
+	return errors.New(message)
+}
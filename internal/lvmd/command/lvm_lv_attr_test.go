@@ -43,6 +43,7 @@ func TestParsedLvAttr(t *testing.T) {
 				OpenTarget:       OpenTargetNone,
 				Zero:             ZeroFalse,
 				VolumeHealth:     VolumeHealthOK,
+				SkipActivation:   SkipActivationFalse,
 			},
 			NoError,
 		},
@@ -59,6 +60,7 @@ func TestParsedLvAttr(t *testing.T) {
 				OpenTarget:       OpenTargetRaid,
 				Zero:             ZeroFalse,
 				VolumeHealth:     VolumeHealthOK,
+				SkipActivation:   SkipActivationFalse,
 			},
 			NoError,
 		},
@@ -75,6 +77,7 @@ func TestParsedLvAttr(t *testing.T) {
 				OpenTarget:       OpenTargetThin,
 				Zero:             ZeroTrue,
 				VolumeHealth:     VolumeHealthOK,
+				SkipActivation:   SkipActivationFalse,
 			},
 			NoError,
 		},
@@ -217,3 +220,77 @@ func TestVerifyHealth(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthActionFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawAttr string
+		want    HealthAction
+	}{
+		{
+			name:    "Healthy Volume",
+			rawAttr: "-wi-a-----",
+			want:    HealthActionNone,
+		},
+		{
+			name:    "Logical Volume Suspended",
+			rawAttr: "-wi-s-----",
+			want:    HealthActionRetry,
+		},
+		{
+			name:    "RAID Reshaping",
+			rawAttr: "rwi-a-r-s-",
+			want:    HealthActionRetry,
+		},
+		{
+			name:    "Invalid Snapshot",
+			rawAttr: "swi-I-s---",
+			want:    HealthActionHalt,
+		},
+		{
+			name:    "Snapshot Merge Failed",
+			rawAttr: "Swi-m-s---",
+			want:    HealthActionHalt,
+		},
+		{
+			name:    "Thin Pool Check Needed",
+			rawAttr: "twi-c-tz--",
+			want:    HealthActionHalt,
+		},
+		{
+			name:    "Thin Pool Out of Data Space",
+			rawAttr: "twi-a-tzD-",
+			want:    HealthActionSurface,
+		},
+		{
+			name:    "RAID Refresh Needed",
+			rawAttr: "rwi-a-r-r-",
+			want:    HealthActionSurface,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lvAttr, err := ParsedLvAttr(tt.rawAttr)
+			if err != nil {
+				t.Fatalf("ParsedLvAttr() error = %v", err)
+			}
+			healthErr := lvAttr.VerifyHealth()
+			if got := HealthActionFor(healthErr); got != tt.want {
+				t.Errorf("HealthActionFor(%v) = %s, want %s", healthErr, got, tt.want)
+			}
+			// lvmd reports the error by its message.
+			var message string
+			if healthErr != nil {
+				message = healthErr.Error()
+			}
+			if got := HealthActionFor(ParseHealthError(message)); got != tt.want {
+				t.Errorf("HealthActionFor(ParseHealthError(%q)) = %s, want %s", message, got, tt.want)
+			}
+		})
+	}
+
+	if got := HealthActionFor(ParseHealthError("device /dev/foo is not found")); got != HealthActionSurface {
+		t.Errorf("expected unknown errors to be surfaced, got %s", got)
+	}
+}
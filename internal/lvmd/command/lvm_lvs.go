@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 )
@@ -24,29 +25,50 @@ type lv struct {
 	size            uint64
 	dataPercent     float64
 	metaDataPercent float64
+	copyPercent     float64
+	cache           CacheStats
+	// vdoSavingPercent is reported on VDO pools.
+	vdoSavingPercent float64
 }
 
 func (u *lv) isThinPool() bool {
 	return u.attr[0] == 't'
 }
 
+func (u *lv) isVDOPool() bool {
+	return u.attr[0] == byte(VolumeTypeVDOPool)
+}
+
+func (u *lv) isVDO() bool {
+	return u.attr[0] == byte(VolumeTypeVirtual) && len(u.poolLV) > 0
+}
+
 func (u *lv) UnmarshalJSON(data []byte) error {
 	type lvInternal struct {
-		Name            string `json:"lv_name"`
-		FullName        string `json:"lv_full_name"`
-		UUID            string `json:"lv_uuid"`
-		Path            string `json:"lv_path"`
-		Major           string `json:"lv_kernel_major"`
-		Minor           string `json:"lv_kernel_minor"`
-		Origin          string `json:"origin"`
-		OriginSize      string `json:"origin_size"`
-		PoolLV          string `json:"pool_lv"`
-		Tags            string `json:"lv_tags"`
-		Attr            string `json:"lv_attr"`
-		VgName          string `json:"vg_name"`
-		Size            string `json:"lv_size"`
-		DataPercent     string `json:"data_percent"`
-		MetaDataPercent string `json:"metadata_percent"`
+		Name            reportValue `json:"lv_name"`
+		FullName        reportValue `json:"lv_full_name"`
+		UUID            reportValue `json:"lv_uuid"`
+		Path            reportValue `json:"lv_path"`
+		Major           reportValue `json:"lv_kernel_major"`
+		Minor           reportValue `json:"lv_kernel_minor"`
+		Origin          reportValue `json:"origin"`
+		OriginSize      reportValue `json:"origin_size"`
+		PoolLV          reportValue `json:"pool_lv"`
+		Tags            reportValue `json:"lv_tags"`
+		Attr            reportValue `json:"lv_attr"`
+		VgName          reportValue `json:"vg_name"`
+		Size            reportValue `json:"lv_size"`
+		DataPercent     reportValue `json:"data_percent"`
+		MetaDataPercent reportValue `json:"metadata_percent"`
+		CopyPercent     reportValue `json:"copy_percent"`
+		CacheTotal      reportValue `json:"cache_total_blocks"`
+		CacheUsed       reportValue `json:"cache_used_blocks"`
+		CacheDirty      reportValue `json:"cache_dirty_blocks"`
+		CacheReadHits   reportValue `json:"cache_read_hits"`
+		CacheReadMisses reportValue `json:"cache_read_misses"`
+		CacheWriteHits  reportValue `json:"cache_write_hits"`
+		CacheWriteMiss  reportValue `json:"cache_write_misses"`
+		VDOSaving       reportValue `json:"vdo_saving_percent"`
 	}
 
 	var temp lvInternal
@@ -54,74 +76,167 @@ func (u *lv) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	u.name = temp.Name
-	u.fullName = temp.FullName
-	u.uuid = temp.UUID
-	u.path = temp.Path
+	u.name = string(temp.Name)
+	u.fullName = string(temp.FullName)
+	u.uuid = string(temp.UUID)
+	u.path = string(temp.Path)
 
 	// If LV is not active, major/minor numbers will be -1, ignore conversion
 	// errors which results in 0 for values in this case.
-	u.major, _ = strconv.ParseUint(temp.Major, 10, 32)
-	u.minor, _ = strconv.ParseUint(temp.Minor, 10, 32)
+	u.major, _ = strconv.ParseUint(string(temp.Major), 10, 32)
+	u.minor, _ = strconv.ParseUint(string(temp.Minor), 10, 32)
 
 	var convErr error
-	u.origin = temp.Origin
+	u.origin = string(temp.Origin)
 	if len(temp.OriginSize) > 0 {
-		u.originSize, convErr = strconv.ParseUint(temp.OriginSize, 10, 64)
+		u.originSize, convErr = strconv.ParseUint(string(temp.OriginSize), 10, 64)
 		if convErr != nil {
 			return convErr
 		}
 	}
 
-	u.poolLV = temp.PoolLV
-	u.tags = strings.Split(temp.Tags, ",")
-	u.attr = temp.Attr
-	u.vgName = temp.VgName
+	u.poolLV = string(temp.PoolLV)
+	u.tags = strings.Split(string(temp.Tags), ",")
+	u.attr = string(temp.Attr)
+	u.vgName = string(temp.VgName)
 
 	if len(temp.Size) > 0 {
-		u.size, convErr = strconv.ParseUint(temp.Size, 10, 64)
+		u.size, convErr = strconv.ParseUint(string(temp.Size), 10, 64)
 		if convErr != nil {
 			return convErr
 		}
 	}
 
 	if len(temp.DataPercent) > 0 {
-		u.dataPercent, convErr = strconv.ParseFloat(temp.DataPercent, 64)
+		u.dataPercent, convErr = strconv.ParseFloat(string(temp.DataPercent), 64)
 		if convErr != nil {
 			return convErr
 		}
 	}
 
 	if len(temp.MetaDataPercent) > 0 {
-		u.metaDataPercent, convErr = strconv.ParseFloat(temp.MetaDataPercent, 64)
+		u.metaDataPercent, convErr = strconv.ParseFloat(string(temp.MetaDataPercent), 64)
+		if convErr != nil {
+			return convErr
+		}
+	}
+	if len(temp.CopyPercent) > 0 {
+		u.copyPercent, convErr = strconv.ParseFloat(string(temp.CopyPercent), 64)
 		if convErr != nil {
 			return convErr
 		}
 	}
+
+	if len(temp.VDOSaving) > 0 {
+		u.vdoSavingPercent, convErr = strconv.ParseFloat(string(temp.VDOSaving), 64)
+		if convErr != nil {
+			return convErr
+		}
+	}
+
+	// cache statistics are only reported for active cached volumes.
+	for _, c := range []struct {
+		raw   reportValue
+		field *uint64
+	}{
+		{temp.CacheTotal, &u.cache.TotalBlocks},
+		{temp.CacheUsed, &u.cache.UsedBlocks},
+		{temp.CacheDirty, &u.cache.DirtyBlocks},
+		{temp.CacheReadHits, &u.cache.ReadHits},
+		{temp.CacheReadMisses, &u.cache.ReadMisses},
+		{temp.CacheWriteHits, &u.cache.WriteHits},
+		{temp.CacheWriteMiss, &u.cache.WriteMisses},
+	} {
+		if len(c.raw) > 0 {
+			*c.field, convErr = strconv.ParseUint(string(c.raw), 10, 64)
+			if convErr != nil {
+				return convErr
+			}
+		}
+	}
 	return nil
 }
 
-func getLVReport(ctx context.Context, name string) (map[string]lv, error) {
-	type lvReport struct {
-		Report []struct {
-			LV []lv `json:"lv"`
-		} `json:"report"`
+// lvSelection filters the logical volumes reported by lvs with --select,
+// so that lvm does not report all logical volumes of the volume group to filter them afterwards.
+// The zero value selects all logical volumes.
+type lvSelection struct {
+	// pool selects the thin volumes of the named thin pool.
+	pool string
+	// origin selects the snapshots of the named logical volume.
+	origin string
+	// tag selects the logical volumes having the tag.
+	tag string
+	// thinPools selects the thin pools.
+	thinPools bool
+}
+
+// String returns the selection in the syntax of --select described in lvmreport(7).
+func (s lvSelection) String() string {
+	var criteria []string
+	if s.pool != "" {
+		criteria = append(criteria, fmt.Sprintf("pool_lv=%q", s.pool))
+	}
+	if s.origin != "" {
+		criteria = append(criteria, fmt.Sprintf("origin=%q", s.origin))
+	}
+	if s.tag != "" {
+		// braces match the lists containing the tag rather than the lists consisting of it.
+		criteria = append(criteria, fmt.Sprintf("lv_tags={%q}", s.tag))
+	}
+	if s.thinPools {
+		criteria = append(criteria, "lv_layout={thin,pool}")
+	}
+	return strings.Join(criteria, " && ")
+}
+
+// matches returns true if lvm would select the logical volume.
+// It filters the logical volumes fetched by getLVMState, which are reported without selection.
+func (s lvSelection) matches(l lv) bool {
+	if s.pool != "" && l.poolLV != s.pool {
+		return false
 	}
+	if s.origin != "" && l.origin != s.origin {
+		return false
+	}
+	if s.tag != "" && !containsString(l.tags, s.tag) {
+		return false
+	}
+	if s.thinPools && !l.isThinPool() {
+		return false
+	}
+	return true
+}
 
-	var res = new(lvReport)
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
 
+func getLVReport(ctx context.Context, name string, sel lvSelection) (map[string]lv, error) {
+	res := lvReport{}
 	args := []string{
 		"lvs",
 		name,
 		"-o",
 		"lv_uuid,lv_name,lv_full_name,lv_path,lv_size," +
 			"lv_kernel_major,lv_kernel_minor,origin,origin_size,pool_lv,lv_tags," +
-			"lv_attr,vg_name,data_percent,metadata_percent,pool_lv",
+			"lv_attr,vg_name,data_percent,metadata_percent,copy_percent,pool_lv," +
+			"cache_total_blocks,cache_used_blocks,cache_dirty_blocks," +
+			"cache_read_hits,cache_read_misses,cache_write_hits,cache_write_misses," +
+			"vdo_saving_percent",
 		"--units",
 		"b",
 		"--nosuffix",
 		"--reportformat",
-		"json",
+		ReportFormat(),
+	}
+	if selection := sel.String(); selection != "" {
+		args = append(args, "--select", selection)
 	}
 	err := callLVMInto(ctx, res, args...)
 
@@ -133,20 +248,9 @@ func getLVReport(ctx context.Context, name string) (map[string]lv, error) {
 		return nil, err
 	}
 
-	if len(res.Report) == 0 {
+	if len(res) == 0 {
 		return nil, ErrNotFound
 	}
 
-	lvs := res.Report[0].LV
-
-	if len(lvs) == 0 {
-		return nil, ErrNotFound
-	}
-
-	lvmap := make(map[string]lv, len(lvs))
-	for _, lv := range lvs {
-		lvmap[lv.name] = lv
-	}
-
-	return lvmap, nil
+	return res, nil
 }
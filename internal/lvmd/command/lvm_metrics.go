@@ -0,0 +1,145 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const metricsNamespace = "topolvm"
+
+// The exit_code labels of the failures without an exit code.
+const (
+	// exitCodeTimeout is the label of the commands killed after their timeout.
+	exitCodeTimeout = "timeout"
+	// exitCodeSignal is the label of the commands killed by a signal.
+	exitCodeSignal = "signal"
+	// exitCodeError is the label of the commands which could not be run, e.g. because the binary is missing.
+	exitCodeError = "error"
+)
+
+var commandsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "lvm",
+	Name:      "commands_total",
+	Help:      "The number of lvm commands run by lvmd",
+}, []string{"subcommand"})
+
+var commandFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metricsNamespace,
+	Subsystem: "lvm",
+	Name:      "command_failures_total",
+	Help:      "The number of lvm commands run by lvmd which failed",
+}, []string{"subcommand", "exit_code"})
+
+func init() {
+	metrics.Registry.MustRegister(commandsTotal)
+	metrics.Registry.MustRegister(commandFailuresTotal)
+}
+
+// FailureLogInterval is the interval in which identical failures of lvm commands, i.e. with the same sub-command,
+// exit code and error, are logged only once. The failures in between are counted and reported with the next
+// one logged, so that a systemic failure does not flood the log. Zero logs every failure.
+var FailureLogInterval = time.Minute
+
+// exitCodeLabel returns the exit_code label of err returned by a command.
+func exitCodeLabel(err error) string {
+	if errors.Is(err, ErrCommandTimeout) {
+		return exitCodeTimeout
+	}
+	lvmErr, ok := AsLVMError(err)
+	if !ok {
+		return exitCodeError
+	}
+	if code := lvmErr.ExitCode(); code >= 0 {
+		return strconv.Itoa(code)
+	}
+	return exitCodeSignal
+}
+
+type failureKey struct {
+	subcommand string
+	exitCode   string
+	message    string
+}
+
+type sampledFailure struct {
+	logged     time.Time
+	suppressed int
+}
+
+// failureSampler decides which failures of lvm commands are logged according to FailureLogInterval.
+type failureSampler struct {
+	mu       sync.Mutex
+	failures map[failureKey]*sampledFailure
+}
+
+// maxSuppressedAge is how many intervals the suppressed failures are kept for when no identical failure follows.
+const maxSuppressedAge = 10
+
+var failures = &failureSampler{failures: make(map[failureKey]*sampledFailure)}
+
+// sample returns true if the failure is logged at now, along with the number of the identical failures
+// suppressed since it was logged the last time.
+func (s *failureSampler) sample(key failureKey, now time.Time, interval time.Duration) (bool, int) {
+	if interval <= 0 {
+		return true, 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the failures suppressed are kept to be reported with the next identical one, but not forever.
+	for k, f := range s.failures {
+		if age := now.Sub(f.logged); age >= interval && (f.suppressed == 0 || age >= maxSuppressedAge*interval) {
+			delete(s.failures, k)
+		}
+	}
+
+	f, ok := s.failures[key]
+	if !ok {
+		s.failures[key] = &sampledFailure{logged: now}
+		return true, 0
+	}
+	if now.Sub(f.logged) < interval {
+		f.suppressed++
+		return false, 0
+	}
+	suppressed := f.suppressed
+	f.logged, f.suppressed = now, 0
+	return true, suppressed
+}
+
+// recordCommand counts the command run as subcommand and logs its failure unless it is suppressed by the sampling.
+func recordCommand(ctx context.Context, subcommand string, err error) {
+	commandsTotal.WithLabelValues(subcommand).Inc()
+	if err == nil {
+		return
+	}
+	exitCode := exitCodeLabel(err)
+	commandFailuresTotal.WithLabelValues(subcommand, exitCode).Inc()
+
+	key := failureKey{subcommand: subcommand, exitCode: exitCode, message: err.Error()}
+	if ok, suppressed := failures.sample(key, time.Now(), FailureLogInterval); ok {
+		log.FromContext(ctx).Error(err, "lvm command failed",
+			"subcommand", subcommand, "exit_code", exitCode, "suppressed_identical_failures", suppressed)
+	}
+}
+
+// recordingReadCloser records the command with recordCommand when it has finished on Close.
+type recordingReadCloser struct {
+	io.ReadCloser
+	ctx        context.Context
+	subcommand string
+}
+
+func (r recordingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	recordCommand(r.ctx, r.subcommand, err)
+	return err
+}
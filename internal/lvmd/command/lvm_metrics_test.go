@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestExitCodeLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "exit code", err: fakeError(5, "Volume group \"myvg1\" not found"), want: "5"},
+		{name: "timeout", err: &lvmErr{err: fmt.Errorf("%w after 1s: lvs", ErrCommandTimeout)}, want: exitCodeTimeout},
+		{name: "signal", err: &lvmErr{err: errors.New("signal: killed")}, want: exitCodeSignal},
+		{name: "not started", err: errors.New("exec: \"lvm\": executable file not found in $PATH"), want: exitCodeError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCodeLabel(tt.err); got != tt.want {
+				t.Errorf("exitCodeLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailureSampler(t *testing.T) {
+	s := &failureSampler{failures: make(map[failureKey]*sampledFailure)}
+	key := failureKey{subcommand: "lvcreate", exitCode: "5", message: "insufficient free space"}
+	other := failureKey{subcommand: "lvremove", exitCode: "5", message: "insufficient free space"}
+	now := time.Now()
+	interval := time.Minute
+
+	if ok, suppressed := s.sample(key, now, interval); !ok || suppressed != 0 {
+		t.Errorf("expected the first failure to be logged, got %v, %d", ok, suppressed)
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := s.sample(key, now.Add(time.Duration(i+1)*time.Second), interval); ok {
+			t.Errorf("expected the identical failure %d to be suppressed", i)
+		}
+	}
+	if ok, _ := s.sample(other, now.Add(time.Second), interval); !ok {
+		t.Error("expected a failure of another sub-command to be logged")
+	}
+	if ok, suppressed := s.sample(key, now.Add(interval), interval); !ok || suppressed != 3 {
+		t.Errorf("expected the failure after the interval to be logged with 3 suppressed, got %v, %d", ok, suppressed)
+	}
+
+	// the failures logged without suppressed ones are forgotten after the interval.
+	s.sample(key, now.Add(3*interval), interval)
+	if _, ok := s.failures[other]; ok {
+		t.Error("expected the failure of another sub-command to be pruned")
+	}
+
+	if ok, _ := s.sample(key, now.Add(3*interval), 0); !ok {
+		t.Error("expected every failure to be logged with zero interval")
+	}
+}
+
+func TestRecordCommand(t *testing.T) {
+	ctx := context.Background()
+	total := testutil.ToFloat64(commandsTotal.WithLabelValues("lvresize"))
+	failed := testutil.ToFloat64(commandFailuresTotal.WithLabelValues("lvresize", "5"))
+
+	recordCommand(ctx, "lvresize", nil)
+	recordCommand(ctx, "lvresize", fakeError(5, "Volume group \"myvg1\" not found"))
+
+	if got := testutil.ToFloat64(commandsTotal.WithLabelValues("lvresize")) - total; got != 2 {
+		t.Errorf("expected 2 commands counted, got %v", got)
+	}
+	if got := testutil.ToFloat64(commandFailuresTotal.WithLabelValues("lvresize", "5")) - failed; got != 1 {
+		t.Errorf("expected 1 failure counted, got %v", got)
+	}
+}
@@ -0,0 +1,190 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lvcreateFlagAliases maps the long options of lvcreate, which lvmd looks for in the extra options,
+// to their short form.
+var lvcreateFlagAliases = map[string]string{
+	"--name":           "-n",
+	"--size":           "-L",
+	"--extents":        "-l",
+	"--virtualsize":    "-V",
+	"--snapshot":       "-s",
+	"--thin":           "-T",
+	"--wipesignatures": "-W",
+	"--yes":            "-y",
+	"--stripes":        "-i",
+	"--stripesize":     "-I",
+	"--mirrors":        "-m",
+}
+
+// lvcreateNoValueFlags are the options lvmd looks for which take no value,
+// so that the argument following them is positional.
+var lvcreateNoValueFlags = map[string]bool{
+	"-s": true,
+	"-T": true,
+	"-y": true,
+}
+
+// lvcreateRepeatableFlags are the options lvcreate accepts more than once.
+var lvcreateRepeatableFlags = map[string]bool{
+	"--addtag":  true,
+	"--devices": true,
+}
+
+// lvcreateManagedFlags are the options lvmd sets from the request, which the extra options cannot override.
+// -W and -y are set by lvmd as well, but the extra options take precedence over them.
+var lvcreateManagedFlags = []string{"-n", "-L", "-l", "-V", "-s", "-T", "--thinpool"}
+
+// lvcreateOption is an option in the extra arguments of lvcreate, e.g. "--wipesignatures=n" or "-W" and "n".
+type lvcreateOption struct {
+	// flag is the short form of the option if it has one.
+	flag  string
+	value string
+	// args are the arguments of the option as given.
+	args []string
+}
+
+func canonicalLvcreateFlag(flag string) string {
+	if short, ok := lvcreateFlagAliases[flag]; ok {
+		return short
+	}
+	return flag
+}
+
+// parseLvcreateOptions parses the extra arguments of lvcreate. A value may follow an option as the next argument
+// or be attached to it, e.g. "--stripes=2" or "-i2". lvmd appends the volume group or the thin pool to them,
+// so positional arguments are rejected.
+func parseLvcreateOptions(options []string) ([]lvcreateOption, error) {
+	var parsed []lvcreateOption
+	for i := 0; i < len(options); i++ {
+		arg := options[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			return nil, fmt.Errorf("positional argument %q is not supported in lvcreate options", arg)
+		}
+		opt := lvcreateOption{args: []string{arg}}
+		hasValue := false
+		if strings.HasPrefix(arg, "--") {
+			var flag string
+			flag, opt.value, hasValue = strings.Cut(arg, "=")
+			opt.flag = canonicalLvcreateFlag(flag)
+		} else {
+			opt.flag = arg[:2]
+			if len(arg) > 2 {
+				opt.value, hasValue = arg[2:], true
+			}
+		}
+		if !hasValue && !lvcreateNoValueFlags[opt.flag] && i+1 < len(options) && !strings.HasPrefix(options[i+1], "-") {
+			i++
+			opt.value = options[i]
+			opt.args = append(opt.args, options[i])
+		}
+		parsed = append(parsed, opt)
+	}
+	return parsed, nil
+}
+
+// ValidateLvcreateOptions returns an error if the extra arguments of lvcreate set an option lvmd sets
+// from the request, such as the name or the size of the volume, or have positional arguments.
+func ValidateLvcreateOptions(options []string) error {
+	parsed, err := parseLvcreateOptions(options)
+	if err != nil {
+		return err
+	}
+	for _, opt := range parsed {
+		for _, flag := range lvcreateManagedFlags {
+			if opt.flag == flag {
+				return fmt.Errorf("%s is set by lvmd and cannot be used in lvcreate options", opt.args[0])
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateLvcreateOptionPrefix returns an error if an extra argument of lvcreate starting with prefix
+// may set an option lvmd sets from the request.
+func ValidateLvcreateOptionPrefix(prefix string) error {
+	for long, short := range lvcreateFlagAliases {
+		if !containsString(lvcreateManagedFlags, short) {
+			continue
+		}
+		if err := validateLvcreateOptionPrefix(prefix, long, false); err != nil {
+			return err
+		}
+		if err := validateLvcreateOptionPrefix(prefix, short, true); err != nil {
+			return err
+		}
+	}
+	return validateLvcreateOptionPrefix(prefix, "--thinpool", false)
+}
+
+func validateLvcreateOptionPrefix(prefix, flag string, short bool) error {
+	matches := strings.HasPrefix(flag, prefix)
+	if rest, ok := strings.CutPrefix(prefix, flag); ok {
+		matches = matches || short || rest == "" || strings.HasPrefix(rest, "=")
+	}
+	if matches {
+		return fmt.Errorf("prefix %q allows %s, which is set by lvmd", prefix, flag)
+	}
+	return nil
+}
+
+// HasLvcreateOption returns true if the extra arguments of lvcreate set the option flag, e.g. "--stripes" or "-i".
+func HasLvcreateOption(options []string, flag string) bool {
+	parsed, err := parseLvcreateOptions(options)
+	if err != nil {
+		return false
+	}
+	flag = canonicalLvcreateFlag(flag)
+	for _, opt := range parsed {
+		if opt.flag == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeLvcreateOptions resolves the conflicts of the extra arguments of lvcreate with the defaults lvmd sets,
+// e.g. "-W y", and among themselves. An option given again replaces the earlier one, so that the inline options
+// of a request take precedence over the options of the class and both over the defaults, and the same options
+// applied twice result in the same arguments. The options lvcreate accepts more than once are only deduplicated.
+// The options are returned as given if they cannot be parsed, so that lvcreate reports them.
+func normalizeLvcreateOptions(defaults, options []string) ([]string, []string) {
+	parsed, err := parseLvcreateOptions(options)
+	if err != nil {
+		return defaults, options
+	}
+	last := make(map[string]int)
+	for i, opt := range parsed {
+		key := opt.flag
+		if lvcreateRepeatableFlags[opt.flag] {
+			key += "=" + opt.value
+		}
+		last[key] = i
+	}
+	var normalized []string
+	for i, opt := range parsed {
+		key := opt.flag
+		if lvcreateRepeatableFlags[opt.flag] {
+			key += "=" + opt.value
+		}
+		if last[key] == i {
+			normalized = append(normalized, opt.args...)
+		}
+	}
+
+	parsedDefaults, err := parseLvcreateOptions(defaults)
+	if err != nil {
+		return defaults, normalized
+	}
+	var kept []string
+	for _, opt := range parsedDefaults {
+		if _, ok := last[opt.flag]; !ok {
+			kept = append(kept, opt.args...)
+		}
+	}
+	return kept, normalized
+}
@@ -0,0 +1,114 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeLvcreateOptions(t *testing.T) {
+	defaults := []string{"-W", "y", "-y"}
+	cases := []struct {
+		name             string
+		options          []string
+		expectedDefaults []string
+		expectedOptions  []string
+	}{
+		{
+			name:             "no options",
+			expectedDefaults: []string{"-W", "y", "-y"},
+		},
+		{
+			name:             "unrelated options",
+			options:          []string{"--type=raid1", "-m", "1"},
+			expectedDefaults: []string{"-W", "y", "-y"},
+			expectedOptions:  []string{"--type=raid1", "-m", "1"},
+		},
+		{
+			name:             "long option overriding a default",
+			options:          []string{"--wipesignatures=n"},
+			expectedDefaults: []string{"-y"},
+			expectedOptions:  []string{"--wipesignatures=n"},
+		},
+		{
+			name:             "short options overriding the defaults",
+			options:          []string{"-Wn", "-y"},
+			expectedDefaults: nil,
+			expectedOptions:  []string{"-Wn", "-y"},
+		},
+		{
+			name:             "later option taking precedence",
+			options:          []string{"--type=raid1", "--alloc=cling", "--alloc", "anywhere"},
+			expectedDefaults: []string{"-W", "y", "-y"},
+			expectedOptions:  []string{"--type=raid1", "--alloc", "anywhere"},
+		},
+		{
+			name:             "options applied twice",
+			options:          []string{"--type=raid1", "--addtag=a", "--type=raid1", "--addtag=a", "--addtag=b"},
+			expectedDefaults: []string{"-W", "y", "-y"},
+			expectedOptions:  []string{"--type=raid1", "--addtag=a", "--addtag=b"},
+		},
+		{
+			name:             "positional argument",
+			options:          []string{"-y", "/dev/sdb"},
+			expectedDefaults: []string{"-W", "y", "-y"},
+			expectedOptions:  []string{"-y", "/dev/sdb"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			gotDefaults, gotOptions := normalizeLvcreateOptions(defaults, c.options)
+			if !reflect.DeepEqual(gotDefaults, c.expectedDefaults) {
+				t.Errorf("unexpected defaults: %v", gotDefaults)
+			}
+			if !reflect.DeepEqual(gotOptions, c.expectedOptions) {
+				t.Errorf("unexpected options: %v", gotOptions)
+			}
+		})
+	}
+}
+
+func TestValidateLvcreateOptions(t *testing.T) {
+	cases := []struct {
+		options []string
+		valid   bool
+	}{
+		{[]string{"--type=raid1", "--wipesignatures", "n", "-y"}, true},
+		{[]string{"--config=allocation/cling_tag_list=[\"@ssd\"]"}, true},
+		{[]string{"--size=1G"}, false},
+		{[]string{"-L1G"}, false},
+		{[]string{"-n", "vol"}, false},
+		{[]string{"--thinpool=pool0"}, false},
+		{[]string{"-y", "vg"}, false},
+	}
+	for _, c := range cases {
+		err := ValidateLvcreateOptions(c.options)
+		if c.valid && err != nil {
+			t.Errorf("%v should be valid: %v", c.options, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%v should be invalid", c.options)
+		}
+	}
+}
+
+func TestValidateLvcreateOptionPrefix(t *testing.T) {
+	for prefix, valid := range map[string]bool{
+		"--config=allocation/": true,
+		"--alloc=":             true,
+		"--stripes=":           true,
+		"--size=":              false,
+		"--virtualsize":        false,
+		"--si":                 false,
+		"-L":                   false,
+		"--":                   false,
+		"-":                    false,
+	} {
+		err := ValidateLvcreateOptionPrefix(prefix)
+		if valid && err != nil {
+			t.Errorf("%q should be valid: %v", prefix, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("%q should be invalid", prefix)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+)
+
+type PvAllocation rune
+
+const (
+	PvAllocationDuplicate   PvAllocation = 'd'
+	PvAllocationAllocatable PvAllocation = 'a'
+	PvAllocationUsed        PvAllocation = 'u'
+	PvAllocationNone        PvAllocation = '-'
+)
+
+type PvExported rune
+
+const (
+	PvExportedTrue  PvExported = 'x'
+	PvExportedFalse PvExported = '-'
+)
+
+type PvMissing rune
+
+const (
+	PvMissingTrue  PvMissing = 'm'
+	PvMissingFalse PvMissing = '-'
+)
+
+// PvAttr has mapped pv_attr information, see https://linux.die.net/man/8/pvs
+type PvAttr struct {
+	PvAllocation
+	PvExported
+	PvMissing
+}
+
+const pvAttrLength = 3
+
+func ParsedPvAttr(raw string) (PvAttr, error) {
+	if len(raw) != pvAttrLength {
+		return PvAttr{}, fmt.Errorf("%s is an invalid length pv_attr, expected %v, but got %v",
+			raw, pvAttrLength, len(raw))
+	}
+	return PvAttr{
+		PvAllocation(raw[0]),
+		PvExported(raw[1]),
+		PvMissing(raw[2]),
+	}, nil
+}
+
+func (p PvAttr) String() string {
+	return fmt.Sprintf("%c%c%c", p.PvAllocation, p.PvExported, p.PvMissing)
+}
+
+// IsAllocatable returns true if extents can be allocated from the physical volume.
+func (p PvAttr) IsAllocatable() bool {
+	return p.PvAllocation == PvAllocationAllocatable
+}
+
+// IsMissing returns true if the device of the physical volume is missing.
+func (p PvAttr) IsMissing() bool {
+	return p.PvMissing == PvMissingTrue
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"testing"
+)
+
+func TestParsedPvAttr(t *testing.T) {
+	tests := []struct {
+		raw         string
+		want        PvAttr
+		allocatable bool
+		missing     bool
+	}{
+		{"---", PvAttr{PvAllocationNone, PvExportedFalse, PvMissingFalse}, false, false},
+		{"a--", PvAttr{PvAllocationAllocatable, PvExportedFalse, PvMissingFalse}, true, false},
+		{"u--", PvAttr{PvAllocationUsed, PvExportedFalse, PvMissingFalse}, false, false},
+		{"ax-", PvAttr{PvAllocationAllocatable, PvExportedTrue, PvMissingFalse}, true, false},
+		{"a-m", PvAttr{PvAllocationAllocatable, PvExportedFalse, PvMissingTrue}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParsedPvAttr(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsedPvAttr() got = %v, want %v", got, tt.want)
+			}
+			if got.String() != tt.raw {
+				t.Errorf("String() got = %s, want %s", got.String(), tt.raw)
+			}
+			if got.IsAllocatable() != tt.allocatable {
+				t.Errorf("IsAllocatable() got = %v", got.IsAllocatable())
+			}
+			if got.IsMissing() != tt.missing {
+				t.Errorf("IsMissing() got = %v", got.IsMissing())
+			}
+		})
+	}
+
+	if _, err := ParsedPvAttr("a---"); err == nil {
+		t.Error("invalid length pv_attr should fail")
+	}
+}
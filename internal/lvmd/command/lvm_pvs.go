@@ -0,0 +1,242 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pvmoveInterval is the interval in seconds at which pvmove reports its progress.
+const pvmoveInterval = 5
+
+type pv struct {
+	name    string
+	uuid    string
+	vgName  string
+	attr    string
+	size    uint64
+	free    uint64
+	devSize uint64
+}
+
+func (u *pv) UnmarshalJSON(data []byte) error {
+	type pvInternal struct {
+		Name    reportValue `json:"pv_name"`
+		UUID    reportValue `json:"pv_uuid"`
+		VGName  reportValue `json:"vg_name"`
+		Attr    reportValue `json:"pv_attr"`
+		Size    reportValue `json:"pv_size"`
+		Free    reportValue `json:"pv_free"`
+		DevSize reportValue `json:"dev_size"`
+	}
+
+	var temp pvInternal
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	u.name = string(temp.Name)
+	u.uuid = string(temp.UUID)
+	u.vgName = string(temp.VGName)
+	u.attr = string(temp.Attr)
+
+	for _, c := range []struct {
+		raw   reportValue
+		field *uint64
+	}{
+		{temp.Size, &u.size},
+		{temp.Free, &u.free},
+		{temp.DevSize, &u.devSize},
+	} {
+		if c.raw == "" {
+			continue
+		}
+		var convErr error
+		*c.field, convErr = strconv.ParseUint(string(c.raw), 10, 64)
+		if convErr != nil {
+			return convErr
+		}
+	}
+	return nil
+}
+
+// getPVReport returns the physical volume on the named device, or all physical volumes if name is empty.
+func getPVReport(ctx context.Context, name string) ([]pv, error) {
+	res := new(pvReport)
+	args := []string{"pvs"}
+	if name != "" {
+		args = append(args, name)
+	}
+	args = append(args,
+		"-o", "pv_uuid,pv_name,vg_name,pv_attr,pv_size,pv_free,dev_size",
+		"--units", "b", "--nosuffix", "--reportformat", ReportFormat(),
+	)
+	err := callLVMInto(ctx, res, args...)
+
+	if IsLVMNotFound(err) {
+		return nil, errors.Join(ErrNotFound, err)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return *res, nil
+}
+
+// PhysicalVolume represents a physical volume of linux lvm.
+// The state should be considered immutable and will not automatically update.
+type PhysicalVolume struct {
+	state pv
+}
+
+// Name returns the device path of the physical volume.
+func (p *PhysicalVolume) Name() string {
+	return p.state.name
+}
+
+// UUID returns the UUID of the physical volume.
+func (p *PhysicalVolume) UUID() string {
+	return p.state.uuid
+}
+
+// VGName returns the name of the volume group of the physical volume, or an empty string
+// if the physical volume does not belong to any volume group.
+func (p *PhysicalVolume) VGName() string {
+	return p.state.vgName
+}
+
+// Size returns the capacity of the physical volume in bytes.
+func (p *PhysicalVolume) Size() uint64 {
+	return p.state.size
+}
+
+// Free returns the space of the physical volume not allocated to logical volumes in bytes.
+func (p *PhysicalVolume) Free() uint64 {
+	return p.state.free
+}
+
+// DeviceSize returns the size of the underlying device in bytes.
+func (p *PhysicalVolume) DeviceSize() uint64 {
+	return p.state.devSize
+}
+
+// Attr returns the pv_attr of the physical volume, which can be parsed with ParsedPvAttr.
+func (p *PhysicalVolume) Attr() string {
+	return p.state.attr
+}
+
+// ListPhysicalVolumes lists all physical volumes.
+func ListPhysicalVolumes(ctx context.Context) ([]*PhysicalVolume, error) {
+	pvs, err := getPVReport(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*PhysicalVolume, 0, len(pvs))
+	for _, pv := range pvs {
+		ret = append(ret, &PhysicalVolume{state: pv})
+	}
+	return ret, nil
+}
+
+// FindPhysicalVolume finds the physical volume on the given device.
+// ErrNotFound is returned if the device is not a physical volume.
+func FindPhysicalVolume(ctx context.Context, device string) (*PhysicalVolume, error) {
+	pvs, err := getPVReport(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	for _, pv := range pvs {
+		if pv.name == device {
+			return &PhysicalVolume{state: pv}, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// CreatePhysicalVolume initializes the given device as a physical volume.
+func CreatePhysicalVolume(ctx context.Context, device string) (*PhysicalVolume, error) {
+	if err := callLVM(ctx, "pvcreate", "--yes", device); err != nil {
+		return nil, err
+	}
+	return FindPhysicalVolume(ctx, device)
+}
+
+// Remove wipes the lvm label from the device of the physical volume.
+// The physical volume must not belong to a volume group.
+func (p *PhysicalVolume) Remove(ctx context.Context) error {
+	return callLVM(ctx, "pvremove", "--yes", p.state.name)
+}
+
+// ListPhysicalVolumes lists the physical volumes of this volume group.
+func (vg *VolumeGroup) ListPhysicalVolumes(ctx context.Context) ([]*PhysicalVolume, error) {
+	pvs, err := ListPhysicalVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ret []*PhysicalVolume
+	for _, pv := range pvs {
+		if pv.VGName() == vg.Name() {
+			ret = append(ret, pv)
+		}
+	}
+	return ret, nil
+}
+
+// SetAllocatable sets whether extents of new or extended logical volumes can be allocated on the physical volume.
+func (p *PhysicalVolume) SetAllocatable(ctx context.Context, allocatable bool) error {
+	value := "n"
+	if allocatable {
+		value = "y"
+	}
+	return callLVM(ctx, "pvchange", "--allocatable", value, p.state.name)
+}
+
+// Move moves the allocated extents of the physical volume to destinations, or to any other physical volume
+// of its volume group if destinations is empty. It blocks until all extents are moved and calls progress
+// with the percentage of the moved extents every time pvmove reports it.
+// Logical volumes stay usable while being moved. Calling Move for a physical volume whose move was
+// interrupted resumes the move.
+func (p *PhysicalVolume) Move(ctx context.Context, destinations []string, progress func(percent float64)) error {
+	if p.state.free == p.state.size {
+		// pvmove fails if there is nothing to move.
+		progress(100)
+		return nil
+	}
+
+	args := append([]string{"pvmove", "--interval", strconv.Itoa(pvmoveInterval), p.state.name}, destinations...)
+	output, err := callLVMStreamed(ctx, args...)
+	if err != nil {
+		return fmt.Errorf("failed to execute command: %v", err)
+	}
+	// keep reading until pvmove exits, otherwise it blocks on writing the progress.
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if percent, ok := parsePvmoveProgress(line); ok {
+			progress(percent)
+			continue
+		}
+		log.FromContext(ctx).Info(line)
+	}
+	return errors.Join(output.Close(), scanner.Err())
+}
+
+// parsePvmoveProgress parses a progress line of pvmove such as "/dev/sdb: Moved: 12.50%".
+func parsePvmoveProgress(line string) (float64, bool) {
+	_, value, ok := strings.Cut(line, ": Moved: ")
+	if !ok {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
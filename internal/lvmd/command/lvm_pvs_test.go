@@ -0,0 +1,22 @@
+package command
+
+import "testing"
+
+func TestParsePvmoveProgress(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected float64
+		ok       bool
+	}{
+		{line: "/dev/sdb: Moved: 12.50%", expected: 12.5, ok: true},
+		{line: "/dev/sdb: Moved: 100.00%", expected: 100, ok: true},
+		{line: "Detected pvmove in progress for /dev/sdb"},
+		{line: "/dev/sdb: Moved: unknown"},
+	}
+	for _, tc := range testCases {
+		percent, ok := parsePvmoveProgress(tc.line)
+		if ok != tc.ok || percent != tc.expected {
+			t.Errorf("unexpected result for %q: percent=%f, ok=%v", tc.line, percent, ok)
+		}
+	}
+}
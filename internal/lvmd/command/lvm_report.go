@@ -0,0 +1,191 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// reportDecoder is implemented by the destinations of callLVMInto which decode the report of lvm
+// entry by entry, instead of unmarshaling the whole output at once.
+// The memory needed to decode a report is then bounded by the largest entry rather than the
+// size of the output, which is tens of MB on nodes with thousands of logical volumes.
+type reportDecoder interface {
+	// decodeEntry decodes the next entry of the section, e.g. "lv", from dec.
+	// Entries of sections not of interest have to be skipped with skipEntry.
+	decodeEntry(section string, dec *json.Decoder) error
+}
+
+// decodeReport walks the tokens of a report of lvm in the form of
+// {"report": [{"<section>": [<entry>, ...], ...}, ...]} and passes each entry to rd.
+// Other members of the top-level object such as "log" are skipped.
+func decodeReport(r io.Reader, rd reportDecoder) error {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if key != "report" {
+			if err := skipEntry(dec); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := expectDelim(dec, '['); err != nil {
+			return err
+		}
+		for dec.More() {
+			if err := expectDelim(dec, '{'); err != nil {
+				return err
+			}
+			for dec.More() {
+				section, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if err := expectDelim(dec, '['); err != nil {
+					return err
+				}
+				for dec.More() {
+					if err := rd.decodeEntry(fmt.Sprint(section), dec); err != nil {
+						return err
+					}
+				}
+				if err := expectDelim(dec, ']'); err != nil {
+					return err
+				}
+			}
+			if err := expectDelim(dec, '}'); err != nil {
+				return err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := token.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("unexpected token in lvm report: %v, expected %v", token, delim)
+	}
+	return nil
+}
+
+// skipEntry skips the next value of dec.
+func skipEntry(dec *json.Decoder) error {
+	var skipped json.RawMessage
+	return dec.Decode(&skipped)
+}
+
+// reportValue is a field of an entry of a report of lvm in the notation of the json report format,
+// which reports all fields as strings. The json_std report format reports numbers as numbers,
+// undefined numbers as null and string lists such as lv_tags as arrays, which are converted.
+type reportValue string
+
+func (v *reportValue) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch {
+	case len(data) == 0:
+		return fmt.Errorf("empty value in lvm report")
+	case bytes.Equal(data, []byte("null")):
+		*v = ""
+	case data[0] == '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*v = reportValue(s)
+	case data[0] == '[':
+		var list []string
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		*v = reportValue(strings.Join(list, ","))
+	default:
+		var n json.Number
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		*v = reportValue(n)
+	}
+	return nil
+}
+
+// lvReport collects the logical volumes of a report by name.
+type lvReport map[string]lv
+
+func (r lvReport) decodeEntry(section string, dec *json.Decoder) error {
+	if section != "lv" {
+		return skipEntry(dec)
+	}
+	var l lv
+	if err := dec.Decode(&l); err != nil {
+		return err
+	}
+	r[l.name] = l
+	return nil
+}
+
+// vgReport collects the volume groups of a report.
+type vgReport []vg
+
+func (r *vgReport) decodeEntry(section string, dec *json.Decoder) error {
+	if section != "vg" {
+		return skipEntry(dec)
+	}
+	var v vg
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	*r = append(*r, v)
+	return nil
+}
+
+// pvReport collects the physical volumes of a report.
+type pvReport []pv
+
+func (r *pvReport) decodeEntry(section string, dec *json.Decoder) error {
+	if section != "pv" {
+		return skipEntry(dec)
+	}
+	var p pv
+	if err := dec.Decode(&p); err != nil {
+		return err
+	}
+	*r = append(*r, p)
+	return nil
+}
+
+// fullReport collects the volume groups and logical volumes of lvm fullreport.
+type fullReport struct {
+	vgs vgReport
+	lvs []lv
+}
+
+func (r *fullReport) decodeEntry(section string, dec *json.Decoder) error {
+	switch section {
+	case "vg":
+		return r.vgs.decodeEntry(section, dec)
+	case "lv":
+		var l lv
+		if err := dec.Decode(&l); err != nil {
+			return err
+		}
+		r.lvs = append(r.lvs, l)
+		return nil
+	}
+	return skipEntry(dec)
+}
@@ -0,0 +1,143 @@
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecodeReport(t *testing.T) {
+	output := `{
+	  "report": [
+	    {
+	      "vg": [{"vg_name": "myvg1", "vg_uuid": "uuid1", "vg_size": "1073741824", "vg_free": "536870912"}],
+	      "pv": [{}, {}],
+	      "lv": [
+	        {"lv_name": "lv1", "lv_attr": "-wi-a-----", "lv_size": "4194304", "vg_name": "myvg1"},
+	        {"lv_name": "lv2", "lv_attr": "-wi-------", "lv_size": "8388608", "vg_name": "myvg1"}
+	      ]
+	    },
+	    {
+	      "lv": [{"lv_name": "lv3", "lv_attr": "-wi-a-----", "lv_size": "4194304", "vg_name": "myvg2"}]
+	    }
+	  ],
+	  "log": [{"log_seq_num": "1", "log_type": "status", "log_message": ""}]
+	}`
+
+	lvs := lvReport{}
+	if err := decodeReport(strings.NewReader(output), lvs); err != nil {
+		t.Fatal(err)
+	}
+	if len(lvs) != 3 || lvs["lv2"].size != 8388608 || lvs["lv3"].vgName != "myvg2" {
+		t.Errorf("unexpected logical volumes: %+v", lvs)
+	}
+
+	var vgs vgReport
+	if err := decodeReport(strings.NewReader(output), &vgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(vgs) != 1 || vgs[0].name != "myvg1" || vgs[0].free != 536870912 {
+		t.Errorf("unexpected volume groups: %+v", vgs)
+	}
+
+	for _, bad := range []string{
+		`[]`,
+		`{"report": {}}`,
+		`{"report": [{"lv": {}}]}`,
+		`{"report": [{"lv": [{"lv_name": "lv1", "lv_size": "x"}]}]}`,
+		`{"report": [{"lv": [`,
+	} {
+		if err := decodeReport(strings.NewReader(bad), lvReport{}); err == nil {
+			t.Errorf("decoding %q should fail", bad)
+		}
+	}
+}
+
+func TestDecodeReportJSONStd(t *testing.T) {
+	output := `{
+	  "report": [
+	    {
+	      "vg": [{"vg_name": "myvg1", "vg_uuid": "uuid1", "vg_size": 1073741824, "vg_free": 536870912}],
+	      "lv": [
+	        {"lv_name": "lv1", "lv_attr": "Vwi-a-tz--", "lv_size": 4194304, "vg_name": "myvg1", "pool_lv": "pool",
+	         "lv_tags": ["topolvm", "owner=a"], "data_percent": 12.5, "lv_kernel_major": 253, "lv_kernel_minor": 3},
+	        {"lv_name": "lv2", "lv_attr": "-wi-------", "lv_size": 8388608, "vg_name": "myvg1",
+	         "lv_tags": [], "data_percent": null, "lv_kernel_major": -1, "lv_kernel_minor": -1}
+	      ]
+	    }
+	  ]
+	}`
+
+	lvs := lvReport{}
+	if err := decodeReport(strings.NewReader(output), lvs); err != nil {
+		t.Fatal(err)
+	}
+	lv1, lv2 := lvs["lv1"], lvs["lv2"]
+	if lv1.size != 4194304 || lv1.dataPercent != 12.5 || lv1.minor != 3 || strings.Join(lv1.tags, ",") != "topolvm,owner=a" {
+		t.Errorf("unexpected logical volume: %+v", lv1)
+	}
+	if lv2.size != 8388608 || lv2.dataPercent != 0 || lv2.major != 0 || len(lv2.tags) != 1 || lv2.tags[0] != "" {
+		t.Errorf("the volume should be decoded as in the json format: %+v", lv2)
+	}
+
+	var vgs vgReport
+	if err := decodeReport(strings.NewReader(output), &vgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(vgs) != 1 || vgs[0].size != 1073741824 || vgs[0].free != 536870912 {
+		t.Errorf("unexpected volume groups: %+v", vgs)
+	}
+
+	if err := decodeReport(strings.NewReader(`{"report": [{"lv": [{"lv_name": {}}]}]}`), lvReport{}); err == nil {
+		t.Error("decoding an object as a field should fail")
+	}
+}
+
+// fullReportOutput returns the output of lvm fullreport with n logical volumes.
+func fullReportOutput(n int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"report": [{"vg": [{"vg_name": "myvg1", "vg_uuid": "uuid", "vg_size": "1099511627776", "vg_free": "0"}], "lv": [`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, `{"lv_uuid": "uuid-%[1]d", "lv_name": "lv-%[1]d", "lv_full_name": "myvg1/lv-%[1]d", "lv_path": "/dev/myvg1/lv-%[1]d",`+
+			` "lv_size": "1073741824", "lv_kernel_major": "253", "lv_kernel_minor": "%[1]d", "origin": "", "origin_size": "",`+
+			` "pool_lv": "pool", "lv_tags": "topolvm.io/lv", "lv_attr": "Vwi-a-tz--", "vg_name": "myvg1",`+
+			` "data_percent": "12.50", "metadata_percent": "", "copy_percent": ""}`, i)
+	}
+	buf.WriteString(`]}]}`)
+	return buf.Bytes()
+}
+
+// BenchmarkParseFullReportResult compares decoding the report entry by entry with
+// unmarshaling the whole output at once, which buffers the output in addition to the volumes.
+func BenchmarkParseFullReportResult(b *testing.B) {
+	output := fullReportOutput(10000)
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, _, err := parseFullReportResult(io.NopCloser(bytes.NewReader(output))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var result struct {
+				Report []struct {
+					VG []vg `json:"vg"`
+					LV []lv `json:"lv"`
+				} `json:"report"`
+			}
+			if err := json.NewDecoder(bytes.NewReader(output)).Decode(&result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"strings"
+)
+
+// SegmentTypes returns the segment types supported by lvm reported by lvm segtypes, e.g. thin, raid1 or vdo.
+// The segment types of optional features such as vdo are only reported if lvm is built with them.
+func SegmentTypes(ctx context.Context) ([]string, error) {
+	output, err := callLVMStreamed(ctx, "segtypes")
+	if err != nil {
+		return nil, err
+	}
+	var types []string
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		if t := strings.TrimSpace(scanner.Text()); t != "" {
+			types = append(types, t)
+		}
+	}
+	if err := errors.Join(output.Close(), scanner.Err()); err != nil {
+		return nil, err
+	}
+	return types, nil
+}
@@ -2,7 +2,6 @@ package command
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 
@@ -10,36 +9,25 @@ import (
 )
 
 func parseFullReportResult(data io.ReadCloser) ([]vg, []lv, error) {
-	type fullReportResult struct {
-		Report []struct {
-			VG []vg `json:"vg"`
-			LV []lv `json:"lv"`
-		} `json:"report"`
-	}
-
-	var result fullReportResult
-	if err := json.NewDecoder(data).Decode(&result); err != nil {
+	var result fullReport
+	if err := decodeReport(data, &result); err != nil {
 		return nil, nil, err
 	}
-
-	var vgs []vg
-	var lvs []lv
-	for _, report := range result.Report {
-		vgs = append(vgs, report.VG...)
-		lvs = append(lvs, report.LV...)
-	}
-	return vgs, lvs, nil
+	return result.vgs, result.lvs, nil
 }
 
 // Issue single lvm command that retrieves everything we need in one call and get the output as JSON
 func getLVMState(ctx context.Context) ([]vg, []lv, error) {
 	args := []string{
-		"--reportformat", "json",
+		"--reportformat", ReportFormat(),
 		"--units", "b", "--nosuffix",
 		"--configreport", "vg", "-o", "vg_name,vg_uuid,vg_size,vg_free",
 		"--configreport", "lv", "-o", "lv_uuid,lv_name,lv_full_name,lv_path,lv_size," +
 			"lv_kernel_major,lv_kernel_minor,origin,origin_size,pool_lv,lv_tags," +
-			"lv_attr,vg_name,data_percent,metadata_percent,pool_lv",
+			"lv_attr,vg_name,data_percent,metadata_percent,copy_percent,pool_lv," +
+			"cache_total_blocks,cache_used_blocks,cache_dirty_blocks," +
+			"cache_read_hits,cache_read_misses,cache_write_hits,cache_write_misses," +
+			"vdo_saving_percent",
 		// fullreport doesn't have an option to omit an entire section, so we
 		// omit all fields instead.
 		"--configreport", "pv", "-o,",
@@ -0,0 +1,62 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/topolvm/topolvm"
+)
+
+// vdoPoolSuffix is the suffix of the VDO pool backing a VDO volume.
+const vdoPoolSuffix = "_vpool"
+
+// VDOOptions holds the VDO configuration of a logical volume.
+type VDOOptions struct {
+	// PhysicalSize is the size of the VDO pool in bytes.
+	PhysicalSize  uint64
+	Compression   bool
+	Deduplication bool
+}
+
+// IsVDO checks if the volume is a VDO volume or not.
+func (l *LogicalVolume) IsVDO() bool {
+	return l.vdoPool != nil
+}
+
+// VDOSavingPercent returns the space saving of a VDO volume by compression and deduplication.
+// It is only available for volumes listed with ListVolumes, as it is reported on the VDO pool.
+func (l *LogicalVolume) VDOSavingPercent() float64 {
+	return l.vdoSavingPercent
+}
+
+// CreateVDOVolume creates a VDO volume of the given virtual size in this volume group.
+// The VDO pool of the volume is created with opts.PhysicalSize and named after the volume,
+// lvm removes the VDO pool together with the volume.
+func (vg *VolumeGroup) CreateVDOVolume(ctx context.Context, name string, size uint64, tags []string,
+	opts VDOOptions, lvcreateOptions []string) error {
+
+	if size%uint64(topolvm.MinimumSectorSize) != 0 || opts.PhysicalSize%uint64(topolvm.MinimumSectorSize) != 0 {
+		return ErrNoMultipleOfSectorSize
+	}
+
+	defaults, lvcreateOptions := normalizeLvcreateOptions([]string{"-W", "y", "-y"}, lvcreateOptions)
+	lvcreateArgs := []string{"lvcreate", "--type", "vdo", "-n", name,
+		"-L", fmt.Sprintf("%vb", opts.PhysicalSize), "-V", fmt.Sprintf("%vb", size),
+		"--compression", yesNo(opts.Compression), "--deduplication", yesNo(opts.Deduplication)}
+	lvcreateArgs = append(lvcreateArgs, defaults...)
+	for _, tag := range tags {
+		lvcreateArgs = append(lvcreateArgs, "--addtag")
+		lvcreateArgs = append(lvcreateArgs, tag)
+	}
+	lvcreateArgs = append(lvcreateArgs, lvcreateOptions...)
+	lvcreateArgs = append(lvcreateArgs, fullName(name+vdoPoolSuffix, vg))
+
+	return callLVMSettled(ctx, devicePath(name, vg), lvcreateArgs...)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "y"
+	}
+	return "n"
+}
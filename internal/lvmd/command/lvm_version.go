@@ -0,0 +1,102 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Version is the version of lvm, e.g. 2.03.22.
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// jsonStdVersion is the version of lvm adding the json_std report format, which reports numbers as numbers
+// and string lists as arrays rather than everything as strings.
+var jsonStdVersion = Version{Major: 2, Minor: 3, Patch: 17}
+
+var versionPattern = regexp.MustCompile(`^\s*LVM version:\s*(\d+)\.(\d+)\.(\d+)`)
+
+// detectedVersion is the version of lvm detected by DetectVersion.
+var detectedVersion atomic.Pointer[Version]
+
+// String returns the version in the notation of lvm.
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%02d.%02d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast returns true if v is the same as or newer than other.
+func (v Version) AtLeast(other Version) bool {
+	if v.Major != other.Major {
+		return v.Major > other.Major
+	}
+	if v.Minor != other.Minor {
+		return v.Minor > other.Minor
+	}
+	return v.Patch >= other.Patch
+}
+
+// GetVersion returns the version of lvm reported by lvm version.
+func GetVersion(ctx context.Context) (Version, error) {
+	output, err := callLVMStreamed(ctx, "version")
+	if err != nil {
+		return Version{}, err
+	}
+	var version *Version
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		m := versionPattern.FindStringSubmatch(scanner.Text())
+		if m == nil {
+			continue
+		}
+		v := Version{}
+		v.Major, _ = strconv.Atoi(m[1])
+		v.Minor, _ = strconv.Atoi(m[2])
+		v.Patch, _ = strconv.Atoi(m[3])
+		version = &v
+	}
+	if err := errors.Join(output.Close(), scanner.Err()); err != nil {
+		return Version{}, err
+	}
+	if version == nil {
+		return Version{}, errors.New("failed to find the lvm version in the output of lvm version")
+	}
+	return *version, nil
+}
+
+// DetectVersion detects the version of lvm and enables the features of this package that depend on it,
+// such as the json_std report format. Until it is called, only the features of all supported versions are used.
+func DetectVersion(ctx context.Context) (Version, error) {
+	version, err := GetVersion(ctx)
+	if err != nil {
+		return Version{}, err
+	}
+	detectedVersion.Store(&version)
+	log.FromContext(ctx).Info("detected lvm version", "version", version.String(), "report_format", ReportFormat())
+	return version, nil
+}
+
+// DetectedVersion returns the version of lvm detected by DetectVersion, or false if it has not been detected.
+func DetectedVersion() (Version, bool) {
+	if v := detectedVersion.Load(); v != nil {
+		return *v, true
+	}
+	return Version{}, false
+}
+
+// ReportFormat returns the --reportformat of the reports of lvm this package requests.
+// The reports are parsed in either format.
+func ReportFormat() string {
+	if v, ok := DetectedVersion(); ok && v.AtLeast(jsonStdVersion) {
+		return "json_std"
+	}
+	return "json"
+}
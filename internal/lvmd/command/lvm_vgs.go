@@ -16,10 +16,10 @@ type vg struct {
 
 func (u *vg) UnmarshalJSON(data []byte) error {
 	type vgInternal struct {
-		Name string `json:"vg_name"`
-		UUID string `json:"vg_uuid"`
-		Size string `json:"vg_size"`
-		Free string `json:"vg_free"`
+		Name reportValue `json:"vg_name"`
+		UUID reportValue `json:"vg_uuid"`
+		Size reportValue `json:"vg_size"`
+		Free reportValue `json:"vg_free"`
 	}
 
 	var temp vgInternal
@@ -27,15 +27,15 @@ func (u *vg) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	u.name = temp.Name
-	u.uuid = temp.UUID
+	u.name = string(temp.Name)
+	u.uuid = string(temp.UUID)
 
 	var convErr error
-	u.size, convErr = strconv.ParseUint(temp.Size, 10, 64)
+	u.size, convErr = strconv.ParseUint(string(temp.Size), 10, 64)
 	if convErr != nil {
 		return convErr
 	}
-	u.free, convErr = strconv.ParseUint(temp.Free, 10, 64)
+	u.free, convErr = strconv.ParseUint(string(temp.Free), 10, 64)
 	if convErr != nil {
 		return convErr
 	}
@@ -44,14 +44,9 @@ func (u *vg) UnmarshalJSON(data []byte) error {
 }
 
 func getVGReport(ctx context.Context, name string) (vg, error) {
-	type vgReport struct {
-		Report []struct {
-			VG []vg `json:"vg"`
-		} `json:"report"`
-	}
 	res := new(vgReport)
 	args := []string{
-		"vgs", name, "-o", "vg_uuid,vg_name,vg_size,vg_free", "--units", "b", "--nosuffix", "--reportformat", "json",
+		"vgs", name, "-o", "vg_uuid,vg_name,vg_size,vg_free", "--units", "b", "--nosuffix", "--reportformat", ReportFormat(),
 	}
 	err := callLVMInto(ctx, res, args...)
 
@@ -63,11 +58,9 @@ func getVGReport(ctx context.Context, name string) (vg, error) {
 		return vg{}, err
 	}
 
-	for _, report := range res.Report {
-		for _, vg := range report.VG {
-			if vg.name == name {
-				return vg, nil
-			}
+	for _, vg := range *res {
+		if vg.name == name {
+			return vg, nil
 		}
 	}
 
@@ -0,0 +1,121 @@
+package command
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// WarningReason is a machine-readable category of a warning printed by lvm.
+type WarningReason string
+
+const (
+	// WarningReasonThinPoolOverprovisioned is reported when the thin volumes of a thin pool exceed its size.
+	WarningReasonThinPoolOverprovisioned WarningReason = "ThinPoolOverprovisioned"
+	// WarningReasonThinPoolThresholdExceeded is reported when the data or metadata usage of a thin pool is high.
+	WarningReasonThinPoolThresholdExceeded WarningReason = "ThinPoolThresholdExceeded"
+	// WarningReasonThinPoolAutoextendDisabled is reported when thin pools are not extended automatically.
+	WarningReasonThinPoolAutoextendDisabled WarningReason = "ThinPoolAutoextendDisabled"
+	// WarningReasonMissingDevice is reported when a physical volume of the volume group is missing.
+	WarningReasonMissingDevice WarningReason = "MissingDevice"
+	// WarningReasonNotZeroed is reported when the beginning of a new logical volume could not be zeroed.
+	WarningReasonNotZeroed WarningReason = "NotZeroed"
+	// WarningReasonOther is reported for all other warnings.
+	WarningReasonOther WarningReason = "Other"
+)
+
+const warningPrefix = "WARNING: "
+
+var warningPatterns = []struct {
+	pattern *regexp.Regexp
+	reason  WarningReason
+}{
+	{regexp.MustCompile(`(?i)sum of all thin volume sizes .* exceeds`), WarningReasonThinPoolOverprovisioned},
+	{regexp.MustCompile(`(?i)not turned on protection against thin pools|thin_pool_autoextend_threshold`), WarningReasonThinPoolAutoextendDisabled},
+	{regexp.MustCompile(`(?i)thin pool .*(threshold|full)|space in metadata of thin pool .* is too low`), WarningReasonThinPoolThresholdExceeded},
+	{regexp.MustCompile(`(?i)couldn't find device|missing (pv|physical volume)`), WarningReasonMissingDevice},
+	{regexp.MustCompile(`(?i)not zeroed`), WarningReasonNotZeroed},
+}
+
+// Warning is a warning printed by lvm on stderr although the command succeeded.
+type Warning struct {
+	Reason WarningReason
+	// Message is the warning without the "WARNING: " prefix.
+	Message string
+}
+
+// ParseWarnings extracts the warnings from the stderr output of lvm.
+// Lines not starting with "WARNING: " are ignored.
+func ParseWarnings(stderr []byte) []Warning {
+	var warnings []Warning
+	scanner := bufio.NewScanner(bytes.NewReader(stderr))
+	for scanner.Scan() {
+		message, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), warningPrefix)
+		if !ok {
+			continue
+		}
+		reason := WarningReasonOther
+		for _, p := range warningPatterns {
+			if p.pattern.MatchString(message) {
+				reason = p.reason
+				break
+			}
+		}
+		warnings = append(warnings, Warning{Reason: reason, Message: message})
+	}
+	return warnings
+}
+
+type warningsKey struct{}
+
+type warningCollector struct {
+	mu       sync.Mutex
+	warnings []Warning
+}
+
+// WithWarnings returns a context that collects the warnings of the lvm commands called with it.
+// The collected warnings can be retrieved with WarningsFromContext.
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey{}, &warningCollector{})
+}
+
+// WarningsFromContext returns the warnings collected in a context returned by WithWarnings.
+// A warning printed by several commands is returned once.
+func WarningsFromContext(ctx context.Context) []Warning {
+	c, ok := ctx.Value(warningsKey{}).(*warningCollector)
+	if !ok {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Warning(nil), c.warnings...)
+}
+
+// recordWarnings logs the warnings in stderr of a succeeded command and adds them to the collector of ctx.
+func recordWarnings(ctx context.Context, stderr []byte) {
+	warnings := ParseWarnings(stderr)
+	if len(warnings) == 0 {
+		return
+	}
+	for _, w := range warnings {
+		log.FromContext(ctx).Info("lvm printed a warning", "reason", w.Reason, "message", w.Message)
+	}
+	if c, ok := ctx.Value(warningsKey{}).(*warningCollector); ok {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+	next:
+		for _, w := range warnings {
+			for _, recorded := range c.warnings {
+				if recorded == w {
+					continue next
+				}
+			}
+			c.warnings = append(c.warnings, w)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestParseWarnings(t *testing.T) {
+	stderr := []byte(`  WARNING: Sum of all thin volume sizes (5.00 GiB) exceeds the size of thin pool myvg1/pool0 (1.00 GiB).
+  WARNING: You have not turned on protection against thin pools running out of space.
+  WARNING: Set activation/thin_pool_autoextend_threshold below 100 to trigger automatic extension of thin pools before they get full.
+  Logical volume "thin1" created.
+  WARNING: Couldn't find device with uuid abcdef.
+  WARNING: something new.
+`)
+	expected := []Warning{
+		{WarningReasonThinPoolOverprovisioned, "Sum of all thin volume sizes (5.00 GiB) exceeds the size of thin pool myvg1/pool0 (1.00 GiB)."},
+		{WarningReasonThinPoolAutoextendDisabled, "You have not turned on protection against thin pools running out of space."},
+		{WarningReasonThinPoolAutoextendDisabled, "Set activation/thin_pool_autoextend_threshold below 100 to trigger automatic extension of thin pools before they get full."},
+		{WarningReasonMissingDevice, "Couldn't find device with uuid abcdef."},
+		{WarningReasonOther, "something new."},
+	}
+	if warnings := ParseWarnings(stderr); !reflect.DeepEqual(warnings, expected) {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if warnings := ParseWarnings([]byte("  Logical volume \"thin1\" created.\n")); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestRecordWarnings(t *testing.T) {
+	stderr := []byte("  WARNING: something new.\n")
+
+	// warnings are dropped without a collector.
+	recordWarnings(context.Background(), stderr)
+
+	ctx := WithWarnings(context.Background())
+	recordWarnings(ctx, stderr)
+	recordWarnings(ctx, stderr)
+	warnings := WarningsFromContext(ctx)
+	if len(warnings) != 1 || warnings[0].Message != "something new." {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+}
@@ -0,0 +1,145 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/topolvm/topolvm/internal/tuning"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ConcurrencyLimitedRPCs are the RPCs limited by ConcurrencyInterceptor by the method name.
+// They run lvm commands taking the lock of the volume group. Long running RPCs such as MoveLV are not limited
+// so that they do not hold up the others.
+var ConcurrencyLimitedRPCs = map[string]bool{
+	"CreateLV":         true,
+	"CreateLVSnapshot": true,
+	"RemoveLV":         true,
+	"ResizeLV":         true,
+	"ChangeLVTags":     true,
+	"ActivateLV":       true,
+	"DeactivateLV":     true,
+}
+
+// ConcurrencyLimits are the limits of ConcurrencyInterceptor per volume group.
+type ConcurrencyLimits struct {
+	// MaxConcurrent is the number of requests running at the same time. Zero disables the limits.
+	MaxConcurrent int
+	// MaxQueued is the number of requests waiting for one of the running requests to finish.
+	// The requests exceeding it fail with codes.ResourceExhausted.
+	MaxQueued int
+}
+
+// DefaultConcurrencyLimits returns the default limits of ConcurrencyInterceptor, which run a request per CPU
+// available to lvmd, up to tuning.LVMDMaxConcurrent, per volume group.
+func DefaultConcurrencyLimits() ConcurrencyLimits {
+	return ConcurrencyLimits{
+		MaxConcurrent: tuning.Workers(0, tuning.LVMDMaxConcurrent),
+		MaxQueued:     64,
+	}
+}
+
+type deviceClassRequest interface {
+	GetDeviceClass() string
+}
+
+// ConcurrencyInterceptor returns a unary interceptor limiting the ConcurrencyLimitedRPCs running at the same time
+// for the volume group of the device-class of the request, so that a burst of requests does not start lvm commands
+// competing for the lock of the volume group. The requests over the limit wait in a queue until their deadline.
+func ConcurrencyInterceptor(dcm *DeviceClassManager, limits ConcurrencyLimits) grpc.UnaryServerInterceptor {
+	var mu sync.Mutex
+	limiters := make(map[string]*vgLimiter)
+	limiter := func(vgName string) *vgLimiter {
+		mu.Lock()
+		defer mu.Unlock()
+		l, ok := limiters[vgName]
+		if !ok {
+			l = &vgLimiter{slots: make(chan struct{}, limits.MaxConcurrent), maxQueued: limits.MaxQueued}
+			limiters[vgName] = l
+		}
+		return l
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		method := path.Base(info.FullMethod)
+		if limits.MaxConcurrent <= 0 || !ConcurrencyLimitedRPCs[method] {
+			return handler(ctx, req)
+		}
+		dcReq, ok := req.(deviceClassRequest)
+		if !ok {
+			return handler(ctx, req)
+		}
+		dc, err := dcm.DeviceClass(dcReq.GetDeviceClass())
+		if err != nil {
+			// the handler reports the unknown device-class.
+			return handler(ctx, req)
+		}
+
+		// the volume groups of a device-class backed by multiple ones share the limits.
+		vgName := strings.Join(VolumeGroupNames(dc), ",")
+		l := limiter(vgName)
+		if err := l.acquire(ctx); err != nil {
+			return nil, concurrencyError(err, method, vgName)
+		}
+		defer l.release()
+		return handler(ctx, req)
+	}
+}
+
+var errQueueFull = errors.New("queue is full")
+
+type vgLimiter struct {
+	slots     chan struct{}
+	maxQueued int
+
+	mu     sync.Mutex
+	queued int
+}
+
+func (l *vgLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	l.mu.Lock()
+	if l.queued >= l.maxQueued {
+		l.mu.Unlock()
+		return errQueueFull
+	}
+	l.queued++
+	l.mu.Unlock()
+	defer func() {
+		l.mu.Lock()
+		l.queued--
+		l.mu.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *vgLimiter) release() {
+	<-l.slots
+}
+
+func concurrencyError(err error, method, vgName string) error {
+	switch {
+	case errors.Is(err, errQueueFull):
+		return status.Errorf(codes.ResourceExhausted, "too many requests queued for volume group %s, %s rejected", vgName, method)
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "%s timed out waiting in the queue of volume group %s", method, vgName)
+	default:
+		return status.Errorf(codes.Canceled, "%s canceled waiting in the queue of volume group %s", method, vgName)
+	}
+}
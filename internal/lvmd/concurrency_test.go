@@ -0,0 +1,86 @@
+package lvmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestConcurrencyInterceptor(t *testing.T) {
+	dcm := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "dc1", VolumeGroup: "vg1", Default: true},
+		{Name: "dc2", VolumeGroup: "vg2"},
+	})
+	interceptor := ConcurrencyInterceptor(dcm, ConcurrencyLimits{MaxConcurrent: 1, MaxQueued: 1})
+	call := func(ctx context.Context, method, deviceClass string, handler grpc.UnaryHandler) error {
+		_, err := interceptor(ctx, &proto.CreateLVRequest{DeviceClass: deviceClass},
+			&grpc.UnaryServerInfo{FullMethod: "/proto.LVService/" + method}, handler)
+		return err
+	}
+	nop := func(ctx context.Context, _ any) (any, error) {
+		return nil, nil
+	}
+
+	// occupy the slot of vg1
+	running := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = call(context.Background(), "CreateLV", "dc1", func(ctx context.Context, _ any) (any, error) {
+			close(running)
+			<-done
+			return nil, nil
+		})
+	}()
+	<-running
+
+	// the other volume groups and the RPCs not limited are not blocked
+	if err := call(context.Background(), "CreateLV", "dc2", nop); err != nil {
+		t.Error(err)
+	}
+	if err := call(context.Background(), "MoveLV", "dc1", nop); err != nil {
+		t.Error(err)
+	}
+
+	// the queued request runs after the running one finishes
+	queued := make(chan error)
+	go func() {
+		queued <- call(context.Background(), "RemoveLV", "", nop)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// the queue is full
+	err := call(context.Background(), "ResizeLV", "dc1", nop)
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected ResourceExhausted, got %v", err)
+	}
+
+	close(done)
+	if err := <-queued; err != nil {
+		t.Error(err)
+	}
+
+	// the request waiting for the slot until the deadline
+	done = make(chan struct{})
+	running = make(chan struct{})
+	go func() {
+		_ = call(context.Background(), "CreateLV", "dc1", func(ctx context.Context, _ any) (any, error) {
+			close(running)
+			<-done
+			return nil, nil
+		})
+	}()
+	<-running
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = call(ctx, "CreateLV", "dc1", nop)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	close(done)
+}
@@ -0,0 +1,56 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultRPCTimeouts are the deadlines lvmd sets on the RPCs by the method name, e.g. "CreateLV",
+// if the client sends none. The RPCs not listed, such as MoveLV moving all the extents of a volume,
+// are not bounded. RemoveLV is given time to wipe the volume.
+var DefaultRPCTimeouts = map[string]time.Duration{
+	"CreateLV":         60 * time.Second,
+	"CreateLVSnapshot": 60 * time.Second,
+	"ResizeLV":         60 * time.Second,
+	"RemoveLV":         120 * time.Second,
+	"ChangeLVTags":     30 * time.Second,
+	"ActivateLV":       30 * time.Second,
+	"DeactivateLV":     30 * time.Second,
+	"GetLVList":        10 * time.Second,
+	"ListSnapshots":    10 * time.Second,
+	"GetFreeBytes":     10 * time.Second,
+	"GetThinPoolUsage": 10 * time.Second,
+	"GetLVMVersion":    10 * time.Second,
+	"GetCapabilities":  10 * time.Second,
+}
+
+// DeadlineInterceptor returns a unary interceptor setting the timeout of the RPC by the method name as the deadline
+// of the requests without one. A zero timeout leaves the RPC unbounded.
+// The lvm command running on the deadline is killed, and the RPC fails with codes.DeadlineExceeded.
+func DeadlineInterceptor(timeouts map[string]time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := ctx.Deadline(); ok {
+			return handler(ctx, req)
+		}
+		method := path.Base(info.FullMethod)
+		timeout := timeouts[method]
+		if timeout <= 0 {
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		res, err := handler(ctx, req)
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Errorf(codes.DeadlineExceeded, "%s exceeded the deadline of %s set by lvmd: %s",
+				method, timeout, status.Convert(err).Message())
+		}
+		return res, err
+	}
+}
@@ -0,0 +1,61 @@
+package lvmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeadlineInterceptor(t *testing.T) {
+	interceptor := DeadlineInterceptor(map[string]time.Duration{
+		"CreateLV": time.Minute,
+		"RemoveLV": 100 * time.Millisecond,
+		"MoveLV":   0,
+	})
+	call := func(ctx context.Context, method string, handler grpc.UnaryHandler) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/proto.LVService/" + method}, handler)
+		return err
+	}
+	deadline := func(expected time.Duration) grpc.UnaryHandler {
+		return func(ctx context.Context, _ any) (any, error) {
+			d, ok := ctx.Deadline()
+			switch {
+			case expected == 0 && ok:
+				t.Errorf("unexpected deadline in %s", time.Until(d))
+			case expected != 0 && !ok:
+				t.Errorf("expected a deadline in %s", expected)
+			case expected != 0 && time.Until(d) > expected:
+				t.Errorf("expected a deadline in %s, got %s", expected, time.Until(d))
+			}
+			return nil, nil
+		}
+	}
+
+	if err := call(context.Background(), "CreateLV", deadline(time.Minute)); err != nil {
+		t.Error(err)
+	}
+	if err := call(context.Background(), "MoveLV", deadline(0)); err != nil {
+		t.Error(err)
+	}
+	if err := call(context.Background(), "GetLVList", deadline(0)); err != nil {
+		t.Error(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := call(ctx, "CreateLV", deadline(5*time.Second)); err != nil {
+		t.Error(err)
+	}
+
+	err := call(context.Background(), "RemoveLV", func(ctx context.Context, _ any) (any, error) {
+		<-ctx.Done()
+		return nil, status.Error(codes.Internal, "lvremove was killed")
+	})
+	if status.Code(err) != codes.DeadlineExceeded || !strings.Contains(err.Error(), "lvremove was killed") {
+		t.Errorf("expected DeadlineExceeded with the cause, got %v", err)
+	}
+}
@@ -3,17 +3,31 @@ package lvmd
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 )
 
 // ErrDeviceClassNotFound is returned when a VG or LV is not found.
 var ErrDeviceClassNotFound = errors.New("device-class not found")
 
+// ErrNoThinPool is returned when a thin volume is requested on a volume group without thin device-class.
+var ErrNoThinPool = errors.New("no thin pool found")
+
+// ErrInvalidProvisioningType is returned when the provisioning type is neither thick nor thin.
+var ErrInvalidProvisioningType = errors.New("provisioning type should be either thick or thin")
+
 const (
-	defaultSpareGB = 10
+	defaultSpareGB          = 10
+	defaultCacheSizePercent = 10
+	defaultVDOSizePercent   = 100
+
+	defaultSnapshotCOWSizePercent = 100
 )
 
 // This regexp is based on the following validation:
@@ -40,6 +54,8 @@ func ValidateDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
 	var countDefault = 0
 	dcNames := make(map[string]bool)
 	vgNames := make(map[string]bool)
+	// quotaPools records if the device classes on a thin pool so far have quotas to share the pool.
+	quotaPools := make(map[string]bool)
 	for _, dc := range deviceClasses {
 		if len(dc.Name) == 0 {
 			return errors.New("device-class name should not be empty")
@@ -49,9 +65,6 @@ func ValidateDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
 		if !qualifiedNameRegexp.MatchString(dc.Name) {
 			return fmt.Errorf("device-class name should consist of alphanumeric characters, '-', '_' or '.', and should start and end with an alphanumeric character: %s", dc.Name)
 		}
-		if len(dc.VolumeGroup) == 0 {
-			return fmt.Errorf("volume group name should not be empty: %s", dc.Name)
-		}
 		if dc.Default {
 			countDefault++
 		}
@@ -61,9 +74,25 @@ func ValidateDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
 
 		// validate Type of the device-class
 		switch dc.Type {
-		case "", lvmdTypes.TypeThick, lvmdTypes.TypeThin:
+		case "", lvmdTypes.TypeThick, lvmdTypes.TypeThin, lvmdTypes.TypeRaw:
 		default:
-			return fmt.Errorf("target 'type' of device-class can be one of '%[1]s' or '%[2]s' or empty to default to '%[1]s'", lvmdTypes.TypeThick, lvmdTypes.TypeThin)
+			return fmt.Errorf("target 'type' of device-class can be one of '%[1]s', '%[2]s' or '%[3]s' or empty to default to '%[1]s'", lvmdTypes.TypeThick, lvmdTypes.TypeThin, lvmdTypes.TypeRaw)
+		}
+
+		if dc.Type == lvmdTypes.TypeRaw {
+			if err := validateRaw(dc); err != nil {
+				return err
+			}
+		} else if len(dc.VolumeGroups) != 0 {
+			if err := validateVolumeGroups(dc); err != nil {
+				return err
+			}
+		} else if len(dc.VolumeGroup) == 0 {
+			return fmt.Errorf("volume group name should not be empty: %s", dc.Name)
+		} else if dc.Raw != nil {
+			return fmt.Errorf("raw is only supported for raw device-class: %s", dc.Name)
+		} else if dc.VolumeGroupPolicy != "" {
+			return fmt.Errorf("volume-group-policy is only supported with volume-groups: %s", dc.Name)
 		}
 
 		name := dc.VolumeGroup
@@ -82,17 +111,84 @@ func ValidateDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
 			if dc.ThinPoolConfig.OverprovisionRatio < 1.0 {
 				return fmt.Errorf("overprovision ratio for thin pool %s in device class %s should be greater than 1.0", dc.ThinPoolConfig.Name, dc.Name)
 			}
+			for _, threshold := range dc.ThinPoolConfig.NotifyThresholds {
+				if threshold <= 0 || threshold > 100 {
+					return fmt.Errorf("notify-thresholds for thin pool %s in device class %s should be between 0 and 100", dc.ThinPoolConfig.Name, dc.Name)
+				}
+			}
+			if quota := dc.ThinPoolConfig.VirtualQuotaGB; quota != nil && *quota == 0 {
+				return fmt.Errorf("virtual-quota-gb for thin pool %s in device class %s should be greater than 0", dc.ThinPoolConfig.Name, dc.Name)
+			}
 			// combination of volumegroup and thinpool should be unique across device classes
-			// so the key 'name' shouldn't appear twice to verify it's uniqueness
+			// so the key 'name' shouldn't appear twice to verify it's uniqueness,
+			// unless all the device classes sharing the thin pool have quotas
 			name = name + "/" + dc.ThinPoolConfig.Name
+			if _, ok := quotaPools[name]; !ok {
+				quotaPools[name] = dc.ThinPoolConfig.VirtualQuotaGB != nil
+			} else if quotaPools[name] && dc.ThinPoolConfig.VirtualQuotaGB != nil {
+				name = name + "/" + dc.Name
+			}
+		}
+
+		if dc.RAID != nil {
+			if err := validateRAID(dc); err != nil {
+				return err
+			}
+		}
+
+		if dc.Cache != nil {
+			if err := validateCache(dc); err != nil {
+				return err
+			}
 		}
 
-		if vgNames[name] {
-			return fmt.Errorf("duplicate volumegroup/thinpool name: %s, %s", dc.Name, name)
+		if dc.VDO != nil {
+			if err := validateVDO(dc); err != nil {
+				return err
+			}
+		}
+
+		if dc.SnapshotCOWSizePercent != nil {
+			if dc.Type == lvmdTypes.TypeThin {
+				return fmt.Errorf("snapshot-cow-size-percent is not supported for thin device-class: %s", dc.Name)
+			}
+			if *dc.SnapshotCOWSizePercent == 0 || *dc.SnapshotCOWSizePercent > 100 {
+				return fmt.Errorf("snapshot-cow-size-percent should be between 1 and 100: %s", dc.Name)
+			}
 		}
 
+		if len(dc.LVCreateOptions) != 0 {
+			if err := validateLVCreateOptions(dc); err != nil {
+				return err
+			}
+		}
+
+		if dc.MaxSize != "" {
+			if err := validateMaxSize(dc); err != nil {
+				return err
+			}
+		}
+
+		switch dc.WipeOnDelete {
+		case "", lvmdTypes.WipeNone, lvmdTypes.WipeDiscard, lvmdTypes.WipeZero:
+		default:
+			return fmt.Errorf("wipe-on-delete can be one of '%s', '%s' or '%s': %s",
+				lvmdTypes.WipeNone, lvmdTypes.WipeDiscard, lvmdTypes.WipeZero, dc.Name)
+		}
+
+		if dc.Type != lvmdTypes.TypeRaw {
+			names := []string{name}
+			if len(dc.VolumeGroups) != 0 {
+				names = dc.VolumeGroups
+			}
+			for _, name := range names {
+				if vgNames[name] {
+					return fmt.Errorf("duplicate volumegroup/thinpool name: %s, %s; device classes sharing a thin pool should all set virtual-quota-gb", dc.Name, name)
+				}
+				vgNames[name] = true
+			}
+		}
 		dcNames[dc.Name] = true
-		vgNames[name] = true
 		if dc.StripeSize != "" && !stripeSizeRegexp.MatchString(dc.StripeSize) {
 			return fmt.Errorf("stripe-size format is \"Size[k|UNIT]\": %s", dc.Name)
 		}
@@ -100,15 +196,281 @@ func ValidateDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
 	if countDefault > 1 {
 		return errors.New("should not have multiple default device-class")
 	}
+	return validateThinDeviceClasses(deviceClasses)
+}
+
+// validateThinDeviceClasses validates the thin-device-class of the thick device-classes,
+// which must be a thin device-class on the same volume group.
+func validateThinDeviceClasses(deviceClasses []*lvmdTypes.DeviceClass) error {
+	byName := make(map[string]*lvmdTypes.DeviceClass)
+	for _, dc := range deviceClasses {
+		byName[dc.Name] = dc
+	}
+	for _, dc := range deviceClasses {
+		if dc.ThinDeviceClass == "" {
+			continue
+		}
+		if dc.Type != "" && dc.Type != lvmdTypes.TypeThick || len(dc.VolumeGroups) != 0 {
+			return fmt.Errorf("thin-device-class is only supported for thick device-class with volume-group: %s", dc.Name)
+		}
+		thin, ok := byName[dc.ThinDeviceClass]
+		if !ok || thin.Type != lvmdTypes.TypeThin || thin.VolumeGroup != dc.VolumeGroup {
+			return fmt.Errorf("thin-device-class should be a thin device-class on volume group %s: %s", dc.VolumeGroup, dc.Name)
+		}
+	}
+	return nil
+}
+
+// validateVolumeGroups validates a device-class backed by multiple volume groups, which is only supported
+// for thick device-classes whose volumes need no other volume group, i.e. without cache and VDO.
+func validateVolumeGroups(dc *lvmdTypes.DeviceClass) error {
+	switch {
+	case len(dc.VolumeGroup) != 0:
+		return fmt.Errorf("volume-group and volume-groups cannot be used together: %s", dc.Name)
+	case dc.Type != "" && dc.Type != lvmdTypes.TypeThick:
+		return fmt.Errorf("volume-groups is only supported for thick device-class: %s", dc.Name)
+	case dc.Raw != nil:
+		return fmt.Errorf("raw is only supported for raw device-class: %s", dc.Name)
+	case dc.Cache != nil, dc.VDO != nil:
+		return fmt.Errorf("cache and vdo are not supported with volume-groups: %s", dc.Name)
+	}
+	for _, name := range dc.VolumeGroups {
+		if len(name) == 0 {
+			return fmt.Errorf("volume group name should not be empty: %s", dc.Name)
+		}
+	}
+	switch dc.VolumeGroupPolicy {
+	case "", lvmdTypes.VolumeGroupPolicyMostFree, lvmdTypes.VolumeGroupPolicyRoundRobin:
+	default:
+		return fmt.Errorf("volume-group-policy can be either '%s' or '%s': %s",
+			lvmdTypes.VolumeGroupPolicyMostFree, lvmdTypes.VolumeGroupPolicyRoundRobin, dc.Name)
+	}
+	return nil
+}
+
+// validateLVCreateOptions validates lvcreate-options of the device-class, which cannot set the options lvmd sets
+// from the request or from the other settings of the device-class.
+func validateLVCreateOptions(dc *lvmdTypes.DeviceClass) error {
+	if err := command.ValidateLvcreateOptions(dc.LVCreateOptions); err != nil {
+		return fmt.Errorf("invalid lvcreate-options: %s: %w", dc.Name, err)
+	}
+	switch {
+	case dc.Stripe != nil && command.HasLvcreateOption(dc.LVCreateOptions, "--stripes"):
+		return fmt.Errorf("stripe cannot be used with --stripes in lvcreate-options: %s", dc.Name)
+	case dc.StripeSize != "" && command.HasLvcreateOption(dc.LVCreateOptions, "--stripesize"):
+		return fmt.Errorf("stripe-size cannot be used with --stripesize in lvcreate-options: %s", dc.Name)
+	case dc.RAID != nil && command.HasLvcreateOption(dc.LVCreateOptions, "--mirrors"):
+		return fmt.Errorf("raid cannot be used with --mirrors in lvcreate-options: %s", dc.Name)
+	}
+	return nil
+}
+
+// VolumeGroupNames returns the names of the volume groups of the device-class,
+// which are VolumeGroups for a device-class backed by multiple volume groups.
+func VolumeGroupNames(dc *lvmdTypes.DeviceClass) []string {
+	if len(dc.VolumeGroups) != 0 {
+		return dc.VolumeGroups
+	}
+	if len(dc.VolumeGroup) != 0 {
+		return []string{dc.VolumeGroup}
+	}
+	return nil
+}
+
+// validateRaw validates a raw device-class, which supports none of the options of logical volumes.
+func validateRaw(dc *lvmdTypes.DeviceClass) error {
+	if len(dc.VolumeGroup) != 0 || len(dc.VolumeGroups) != 0 {
+		return fmt.Errorf("volume group is not supported for raw device-class: %s", dc.Name)
+	}
+	if dc.Raw == nil || len(dc.Raw.Devices) == 0 {
+		return fmt.Errorf("device class type is raw but raw devices are empty: %s", dc.Name)
+	}
+	for _, pattern := range dc.Raw.Devices {
+		if !filepath.IsAbs(pattern) {
+			return fmt.Errorf("raw device %s should be an absolute path: %s", pattern, dc.Name)
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern of raw device %s: %s", pattern, dc.Name)
+		}
+	}
+	if dc.Raw.StateFile != "" && !filepath.IsAbs(dc.Raw.StateFile) {
+		return fmt.Errorf("state-file should be an absolute path: %s", dc.Name)
+	}
+	switch {
+	case dc.ThinPoolConfig != nil:
+		return fmt.Errorf("thin-pool is not supported for raw device-class: %s", dc.Name)
+	case dc.RAID != nil, dc.Cache != nil, dc.VDO != nil:
+		return fmt.Errorf("raid, cache and vdo are not supported for raw device-class: %s", dc.Name)
+	case dc.Stripe != nil, dc.StripeSize != "", len(dc.LVCreateOptions) != 0:
+		return fmt.Errorf("stripe, stripe-size and lvcreate-options are not supported for raw device-class: %s", dc.Name)
+	case dc.SnapshotCOWSizePercent != nil, dc.AllowShrink, dc.ActivationSkip:
+		return fmt.Errorf("snapshot-cow-size-percent, allow-shrink and activation-skip are not supported for raw device-class: %s", dc.Name)
+	}
+	return nil
+}
+
+func validateRAID(dc *lvmdTypes.DeviceClass) error {
+	if dc.Type == lvmdTypes.TypeThin {
+		return fmt.Errorf("raid is not supported for thin device-class: %s", dc.Name)
+	}
+	for _, opt := range dc.LVCreateOptions {
+		if strings.HasPrefix(opt, "--type") {
+			return fmt.Errorf("raid cannot be used with --type in lvcreate-options: %s", dc.Name)
+		}
+	}
+	if dc.RAID.Mirrors != nil && *dc.RAID.Mirrors == 0 {
+		return fmt.Errorf("raid mirrors should be greater than 0: %s", dc.Name)
+	}
+	switch dc.RAID.Type {
+	case lvmdTypes.TypeRAID1:
+		if dc.Stripe != nil {
+			return fmt.Errorf("stripe cannot be used with %s: %s", dc.RAID.Type, dc.Name)
+		}
+	case lvmdTypes.TypeRAID5:
+		if dc.RAID.Mirrors != nil {
+			return fmt.Errorf("mirrors cannot be used with %s: %s", dc.RAID.Type, dc.Name)
+		}
+		if dc.Stripe == nil || *dc.Stripe < 2 {
+			return fmt.Errorf("%s requires stripe of at least 2: %s", dc.RAID.Type, dc.Name)
+		}
+	case lvmdTypes.TypeRAID10:
+		if dc.Stripe == nil || *dc.Stripe < 2 {
+			return fmt.Errorf("%s requires stripe of at least 2: %s", dc.RAID.Type, dc.Name)
+		}
+	default:
+		return fmt.Errorf("raid type can be one of '%s', '%s' or '%s': %s",
+			lvmdTypes.TypeRAID1, lvmdTypes.TypeRAID5, lvmdTypes.TypeRAID10, dc.Name)
+	}
+	return nil
+}
+
+// raidMirrors returns the number of additional data copies of the device-class.
+func raidMirrors(dc *lvmdTypes.DeviceClass) uint {
+	if dc.RAID == nil || dc.RAID.Type == lvmdTypes.TypeRAID5 {
+		return 0
+	}
+	if dc.RAID.Mirrors == nil {
+		return 1
+	}
+	return *dc.RAID.Mirrors
+}
+
+// GetUsableBytes returns the bytes usable for logical volume data out of the given raw bytes
+// of a volume group, taking the RAID redundancy of the device-class into account.
+func GetUsableBytes(dc *lvmdTypes.DeviceClass, raw uint64) uint64 {
+	if dc.RAID == nil {
+		return raw
+	}
+	switch dc.RAID.Type {
+	case lvmdTypes.TypeRAID5:
+		stripe := uint64(*dc.Stripe)
+		return raw / (stripe + 1) * stripe
+	default:
+		return raw / uint64(raidMirrors(dc)+1)
+	}
+}
+
+func validateCache(dc *lvmdTypes.DeviceClass) error {
+	if dc.Type == lvmdTypes.TypeThin {
+		return fmt.Errorf("cache is not supported for thin device-class: %s", dc.Name)
+	}
+	if len(dc.Cache.Device) == 0 {
+		return fmt.Errorf("cache device should not be empty: %s", dc.Name)
+	}
+	if dc.Cache.SizePercent != nil && (*dc.Cache.SizePercent == 0 || *dc.Cache.SizePercent > 100) {
+		return fmt.Errorf("cache size-percent should be between 1 and 100: %s", dc.Name)
+	}
+	switch dc.Cache.Mode {
+	case "", lvmdTypes.CacheModeWritethrough, lvmdTypes.CacheModeWriteback:
+	default:
+		return fmt.Errorf("cache mode can be either '%s' or '%s': %s",
+			lvmdTypes.CacheModeWritethrough, lvmdTypes.CacheModeWriteback, dc.Name)
+	}
+	return nil
+}
+
+// GetCacheBytes returns the size of the cache volume attached to a logical volume of the given size.
+func GetCacheBytes(dc *lvmdTypes.DeviceClass, size uint64) uint64 {
+	if dc.Cache == nil {
+		return 0
+	}
+	percent := uint64(defaultCacheSizePercent)
+	if dc.Cache.SizePercent != nil {
+		percent = uint64(*dc.Cache.SizePercent)
+	}
+	cacheSize := size * percent / 100
+	// round up to the sector size as required by lvcreate.
+	if rem := cacheSize % uint64(topolvm.MinimumSectorSize); rem != 0 {
+		cacheSize += uint64(topolvm.MinimumSectorSize) - rem
+	}
+	return cacheSize
+}
+
+func validateVDO(dc *lvmdTypes.DeviceClass) error {
+	if dc.Type == lvmdTypes.TypeThin {
+		return fmt.Errorf("vdo is not supported for thin device-class: %s", dc.Name)
+	}
+	if dc.RAID != nil || dc.Cache != nil || dc.Stripe != nil {
+		return fmt.Errorf("vdo cannot be used with raid, cache or stripe: %s", dc.Name)
+	}
+	for _, opt := range dc.LVCreateOptions {
+		if strings.HasPrefix(opt, "--type") {
+			return fmt.Errorf("vdo cannot be used with --type in lvcreate-options: %s", dc.Name)
+		}
+	}
+	if dc.VDO.PhysicalSizePercent != nil && (*dc.VDO.PhysicalSizePercent == 0 || *dc.VDO.PhysicalSizePercent > 100) {
+		return fmt.Errorf("vdo physical-size-percent should be between 1 and 100: %s", dc.Name)
+	}
 	return nil
 }
 
+// GetVDOOptions returns the VDO configuration of a logical volume of the given size,
+// or nil if the device-class does not use VDO.
+func GetVDOOptions(dc *lvmdTypes.DeviceClass, size uint64) *command.VDOOptions {
+	if dc.VDO == nil {
+		return nil
+	}
+	percent := uint64(defaultVDOSizePercent)
+	if dc.VDO.PhysicalSizePercent != nil {
+		percent = uint64(*dc.VDO.PhysicalSizePercent)
+	}
+	physicalSize := size * percent / 100
+	// round up to the sector size as required by lvcreate.
+	if rem := physicalSize % uint64(topolvm.MinimumSectorSize); rem != 0 {
+		physicalSize += uint64(topolvm.MinimumSectorSize) - rem
+	}
+	return &command.VDOOptions{
+		PhysicalSize:  physicalSize,
+		Compression:   dc.VDO.Compression == nil || *dc.VDO.Compression,
+		Deduplication: dc.VDO.Deduplication == nil || *dc.VDO.Deduplication,
+	}
+}
+
+// GetSnapshotCOWBytes returns the size of the copy-on-write space of a snapshot of a thick logical volume of the given size.
+func GetSnapshotCOWBytes(dc *lvmdTypes.DeviceClass, size uint64) uint64 {
+	percent := uint64(defaultSnapshotCOWSizePercent)
+	if dc.SnapshotCOWSizePercent != nil {
+		percent = uint64(*dc.SnapshotCOWSizePercent)
+	}
+	cowSize := size * percent / 100
+	// round up to the sector size as required by lvcreate.
+	if rem := cowSize % uint64(topolvm.MinimumSectorSize); rem != 0 {
+		cowSize += uint64(topolvm.MinimumSectorSize) - rem
+	}
+	return cowSize
+}
+
 // DeviceClassManager maps between device-classes and volume groups.
 type DeviceClassManager struct {
 	defaultDeviceClass        *lvmdTypes.DeviceClass
 	deviceClassByName         map[string]*lvmdTypes.DeviceClass
 	deviceClassByVGName       map[string]*lvmdTypes.DeviceClass
 	deviceClassByThinPoolName map[string]*lvmdTypes.DeviceClass
+	thinDeviceClassesByVGName map[string][]*lvmdTypes.DeviceClass
+	// thinDeviceClassesByPoolName lists the device-classes sharing each thin pool.
+	thinDeviceClassesByPoolName map[string][]*lvmdTypes.DeviceClass
+	// drain is shared by the copies of the manager.
+	drain *drainState
 }
 
 // NewDeviceClassManager creates a new DeviceClassManager
@@ -117,11 +479,15 @@ func NewDeviceClassManager(deviceClasses []*lvmdTypes.DeviceClass) *DeviceClassM
 	dcm.deviceClassByName = make(map[string]*lvmdTypes.DeviceClass)
 	dcm.deviceClassByVGName = make(map[string]*lvmdTypes.DeviceClass)
 	dcm.deviceClassByThinPoolName = make(map[string]*lvmdTypes.DeviceClass)
+	dcm.thinDeviceClassesByVGName = make(map[string][]*lvmdTypes.DeviceClass)
+	dcm.thinDeviceClassesByPoolName = make(map[string][]*lvmdTypes.DeviceClass)
+	dcm.drain = &drainState{draining: make(map[string]bool)}
 	for _, dc := range deviceClasses {
 		if dc.Default {
 			dcm.defaultDeviceClass = dc
 		}
 		dcm.deviceClassByName[dc.Name] = dc
+		dcm.drain.draining[dc.Name] = dc.Draining
 
 		// device-class has two targets and at a time it can only be in one of
 		// "deviceClassByVGName" or "deviceClassByThinPoolName" maps
@@ -130,11 +496,19 @@ func NewDeviceClassManager(deviceClasses []*lvmdTypes.DeviceClass) *DeviceClassM
 			// device-class target is volumegroup and any logical volume referring to
 			// this device-class will have thick logical volumes
 			dc.Type = lvmdTypes.TypeThick
-			dcm.deviceClassByVGName[dc.VolumeGroup] = dc
+			for _, vgName := range VolumeGroupNames(dc) {
+				dcm.deviceClassByVGName[vgName] = dc
+			}
 		case lvmdTypes.TypeThin:
 			// we can't store pool name alone as there can be of thinpool with same name
 			// but on a different vg, so combination of vg and thinpool should be unique
-			dcm.deviceClassByThinPoolName[dc.VolumeGroup+"/"+dc.ThinPoolConfig.Name] = dc
+			// the first device-class of a shared thin pool represents the pool.
+			pool := dc.VolumeGroup + "/" + dc.ThinPoolConfig.Name
+			if _, ok := dcm.deviceClassByThinPoolName[pool]; !ok {
+				dcm.deviceClassByThinPoolName[pool] = dc
+			}
+			dcm.thinDeviceClassesByPoolName[pool] = append(dcm.thinDeviceClassesByPoolName[pool], dc)
+			dcm.thinDeviceClassesByVGName[dc.VolumeGroup] = append(dcm.thinDeviceClassesByVGName[dc.VolumeGroup], dc)
 		}
 	}
 	return &dcm
@@ -151,6 +525,16 @@ func (m DeviceClassManager) DeviceClass(dcName string) (*lvmdTypes.DeviceClass,
 	return nil, ErrDeviceClassNotFound
 }
 
+// DeviceClasses returns all the device-classes sorted by name.
+func (m DeviceClassManager) DeviceClasses() []*lvmdTypes.DeviceClass {
+	dcs := make([]*lvmdTypes.DeviceClass, 0, len(m.deviceClassByName))
+	for _, dc := range m.deviceClassByName {
+		dcs = append(dcs, dc)
+	}
+	sort.Slice(dcs, func(i, j int) bool { return dcs[i].Name < dcs[j].Name })
+	return dcs
+}
+
 // FindDeviceClassByVGName returns the device-class with the volume group name
 func (m DeviceClassManager) FindDeviceClassByVGName(vgName string) (*lvmdTypes.DeviceClass, error) {
 	if v, ok := m.deviceClassByVGName[vgName]; ok {
@@ -159,7 +543,8 @@ func (m DeviceClassManager) FindDeviceClassByVGName(vgName string) (*lvmdTypes.D
 	return nil, ErrDeviceClassNotFound
 }
 
-// FindDeviceClassByThinPoolName returns the device-class with volume group and pool combination
+// FindDeviceClassByThinPoolName returns the device-class with volume group and pool combination.
+// The first device-class is returned for a thin pool shared by device-classes.
 func (m DeviceClassManager) FindDeviceClassByThinPoolName(vgName string, poolName string) (*lvmdTypes.DeviceClass, error) {
 	name := vgName + "/" + poolName
 	if v, ok := m.deviceClassByThinPoolName[name]; ok {
@@ -167,3 +552,84 @@ func (m DeviceClassManager) FindDeviceClassByThinPoolName(vgName string, poolNam
 	}
 	return nil, ErrDeviceClassNotFound
 }
+
+// ProvisioningDeviceClass returns the device-class to provision a logical volume of provisioningType
+// instead of the type of dc. It returns dc itself if provisioningType is empty or the same as the type of dc.
+//
+// A thick volume is created directly in the volume group of a thin device-class.
+// A thin volume is created in the thin pool of the thin-device-class of a thick device-class, or of the only thin
+// device-class on its volume group if not set, and RAID, cache and VDO of the thick device-class are not applied.
+// Raw device-classes provision neither.
+func (m DeviceClassManager) ProvisioningDeviceClass(dc *lvmdTypes.DeviceClass, provisioningType lvmdTypes.DeviceType) (*lvmdTypes.DeviceClass, error) {
+	if provisioningType == "" || provisioningType == dc.Type {
+		return dc, nil
+	}
+
+	if dc.Type == lvmdTypes.TypeRaw && (provisioningType == lvmdTypes.TypeThick || provisioningType == lvmdTypes.TypeThin) {
+		return nil, fmt.Errorf("raw device-class %s cannot provision %s volumes", dc.Name, provisioningType)
+	}
+
+	derived := *dc
+	switch provisioningType {
+	case lvmdTypes.TypeThick:
+		derived.Type = lvmdTypes.TypeThick
+		derived.ThinPoolConfig = nil
+	case lvmdTypes.TypeThin:
+		thinDC, err := m.thinDeviceClass(dc)
+		if err != nil {
+			return nil, err
+		}
+		derived.Type = lvmdTypes.TypeThin
+		derived.ThinPoolConfig = thinDC.ThinPoolConfig
+		derived.RAID = nil
+		derived.Cache = nil
+		derived.VDO = nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidProvisioningType, provisioningType)
+	}
+	return &derived, nil
+}
+
+// thinDeviceClass returns the thin device-class whose thin pool holds the thin volumes provisioned on
+// the thick device-class dc by overriding the type.
+func (m DeviceClassManager) thinDeviceClass(dc *lvmdTypes.DeviceClass) (*lvmdTypes.DeviceClass, error) {
+	if dc.ThinDeviceClass != "" {
+		thinDC, ok := m.deviceClassByName[dc.ThinDeviceClass]
+		if !ok {
+			return nil, fmt.Errorf("%w: thin-device-class %s of device-class %s", ErrNoThinPool, dc.ThinDeviceClass, dc.Name)
+		}
+		return thinDC, nil
+	}
+	thinDCs := m.thinDeviceClassesByVGName[dc.VolumeGroup]
+	switch len(thinDCs) {
+	case 0:
+		return nil, fmt.Errorf("%w: volume group %s of device-class %s", ErrNoThinPool, dc.VolumeGroup, dc.Name)
+	case 1:
+		return thinDCs[0], nil
+	default:
+		return nil, fmt.Errorf("%w: volume group %s of device-class %s has more than one thin device-class, set thin-device-class",
+			ErrNoThinPool, dc.VolumeGroup, dc.Name)
+	}
+}
+
+// overrideTags returns the tags to create a volume overriding the type of dc with, which record dc so that
+// the volume is listed for dc rather than for the device-class whose volume group or thin pool holds it.
+func overrideTags(dc *lvmdTypes.DeviceClass, tags []string) []string {
+	return append(append([]string{}, tags...), deviceClassTag(dc.Name))
+}
+
+// overridingDeviceClass returns the device-class recorded in the tags of lv whose type lv overrides,
+// or nil if lv is provisioned with the type of the device-class holding it.
+func (m DeviceClassManager) overridingDeviceClass(lv *command.LogicalVolume) *lvmdTypes.DeviceClass {
+	for _, tag := range lv.Tags() {
+		name, ok := strings.CutPrefix(tag, deviceClassTagPrefix)
+		if !ok {
+			continue
+		}
+		dc, ok := m.deviceClassByName[name]
+		if ok && (dc.Type == lvmdTypes.TypeThin) != lv.IsThin() {
+			return dc
+		}
+	}
+	return nil
+}
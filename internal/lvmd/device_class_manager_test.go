@@ -1,9 +1,11 @@
 package lvmd
 
 import (
+	"errors"
 	"strconv"
 	"testing"
 
+	"github.com/topolvm/topolvm/internal/lvmd/command"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 )
 
@@ -11,6 +13,14 @@ func TestValidateDeviceClasses(t *testing.T) {
 	stripe := uint(2)
 	opRatio := float64(10.0)
 	wrongOpRatio := float64(0.5)
+	mirrors := uint(2)
+	zeroMirrors := uint(0)
+	cachePercent := uint(20)
+	wrongCachePercent := uint(101)
+	vdoPercent := uint(50)
+	zeroVDOPercent := uint(0)
+	cowPercent := uint(30)
+	tooLargePercent := uint(101)
 
 	cases := []struct {
 		deviceClasses []*lvmdTypes.DeviceClass
@@ -157,6 +167,22 @@ func TestValidateDeviceClasses(t *testing.T) {
 			},
 			valid: true,
 		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "dev0",
+					VolumeGroup: "vg0",
+					Default:     true,
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool0",
+						OverprovisionRatio: opRatio,
+						NotifyThresholds:   []float64{80, 120},
+					},
+				},
+			},
+			valid: false,
+		},
 		{
 			// ThinPoolConfig should be ignored if Type is TypeThick
 			deviceClasses: []*lvmdTypes.DeviceClass{
@@ -372,6 +398,525 @@ func TestValidateDeviceClasses(t *testing.T) {
 			},
 			valid: false,
 		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "raid1",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1},
+				},
+				{
+					Name:        "raid5",
+					VolumeGroup: "node1-myvg2",
+					Stripe:      &stripe,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID5},
+				},
+				{
+					Name:        "raid10",
+					VolumeGroup: "node1-myvg3",
+					Stripe:      &stripe,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID10, Mirrors: &mirrors},
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "raid5-without-stripe",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID5},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "raid1-with-zero-mirrors",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1, Mirrors: &zeroMirrors},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "unknown-raid-type",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					RAID:        &lvmdTypes.RAIDConfig{Type: "raid6"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "thin-raid",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool0",
+						OverprovisionRatio: opRatio,
+					},
+					RAID: &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "cached",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Cache: &lvmdTypes.CacheConfig{
+						Device:      "/dev/nvme0n1",
+						SizePercent: &cachePercent,
+						Mode:        lvmdTypes.CacheModeWriteback,
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "cache-without-device",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Cache:       &lvmdTypes.CacheConfig{},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "cache-with-unknown-mode",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Cache:       &lvmdTypes.CacheConfig{Device: "/dev/nvme0n1", Mode: "writearound"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "cache-too-large",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Cache:       &lvmdTypes.CacheConfig{Device: "/dev/nvme0n1", SizePercent: &wrongCachePercent},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "vdo",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					VDO:         &lvmdTypes.VDOConfig{PhysicalSizePercent: &vdoPercent},
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "vdo-zero-percent",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					VDO:         &lvmdTypes.VDOConfig{PhysicalSizePercent: &zeroVDOPercent},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "vdo-with-raid",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					RAID:        &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1},
+					VDO:         &lvmdTypes.VDOConfig{},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "vdo-with-type",
+					VolumeGroup:     "node1-myvg1",
+					Default:         true,
+					LVCreateOptions: []string{"--type=raid1"},
+					VDO:             &lvmdTypes.VDOConfig{},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "thin-vdo",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: opRatio,
+					},
+					VDO: &lvmdTypes.VDOConfig{},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:                   "cow",
+					VolumeGroup:            "node1-myvg1",
+					Default:                true,
+					SnapshotCOWSizePercent: &cowPercent,
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:                   "cow-too-large",
+					VolumeGroup:            "node1-myvg1",
+					Default:                true,
+					SnapshotCOWSizePercent: &tooLargePercent,
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "thin-cow",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: opRatio,
+					},
+					SnapshotCOWSizePercent: &cowPercent,
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "wipe",
+					VolumeGroup:  "node1-myvg1",
+					Default:      true,
+					WipeOnDelete: lvmdTypes.WipeDiscard,
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "wipe-unknown",
+					VolumeGroup:  "node1-myvg1",
+					Default:      true,
+					WipeOnDelete: "shred",
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "quota",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					MaxSize:     "100Gi",
+					MaxVolumes:  10,
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "max-size-invalid",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					MaxSize:     "100GB!",
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "max-size-zero",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+					MaxSize:     "0",
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "ssd",
+					VolumeGroup: "node1-myvg1",
+					Default:     true,
+				},
+				{
+					Name:         "raw",
+					Type:         lvmdTypes.TypeRaw,
+					Raw:          &lvmdTypes.RawConfig{Devices: []string{"/dev/nvme[1-3]n1", "/dev/sdz"}},
+					WipeOnDelete: lvmdTypes.WipeDiscard,
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name: "raw-no-devices",
+					Type: lvmdTypes.TypeRaw,
+					Raw:  &lvmdTypes.RawConfig{},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name: "raw-relative",
+					Type: lvmdTypes.TypeRaw,
+					Raw:  &lvmdTypes.RawConfig{Devices: []string{"nvme1n1"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "raw-vg",
+					VolumeGroup: "node1-myvg1",
+					Type:        lvmdTypes.TypeRaw,
+					Raw:         &lvmdTypes.RawConfig{Devices: []string{"/dev/nvme1n1"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:   "raw-stripe",
+					Type:   lvmdTypes.TypeRaw,
+					Raw:    &lvmdTypes.RawConfig{Devices: []string{"/dev/nvme1n1"}},
+					Stripe: &stripe,
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:        "thick-raw",
+					VolumeGroup: "node1-myvg1",
+					Raw:         &lvmdTypes.RawConfig{Devices: []string{"/dev/nvme1n1"}},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:              "disks",
+					VolumeGroups:      []string{"node1-disk1", "node1-disk2"},
+					VolumeGroupPolicy: lvmdTypes.VolumeGroupPolicyRoundRobin,
+					RAID:              &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1},
+					Default:           true,
+				},
+				{
+					Name:        "ssd",
+					VolumeGroup: "node1-myvg1",
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "disks-and-vg",
+					VolumeGroup:  "node1-myvg1",
+					VolumeGroups: []string{"node1-disk1", "node1-disk2"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "disks-thin",
+					VolumeGroups: []string{"node1-disk1", "node1-disk2"},
+					Type:         lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: opRatio,
+					},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "disks-cache",
+					VolumeGroups: []string{"node1-disk1", "node1-disk2"},
+					Cache:        &lvmdTypes.CacheConfig{Device: "/dev/nvme0n1"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "disks-empty",
+					VolumeGroups: []string{"node1-disk1", ""},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:              "disks-policy",
+					VolumeGroups:      []string{"node1-disk1", "node1-disk2"},
+					VolumeGroupPolicy: "least-used",
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:              "policy-without-disks",
+					VolumeGroup:       "node1-myvg1",
+					VolumeGroupPolicy: lvmdTypes.VolumeGroupPolicyMostFree,
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:         "disks",
+					VolumeGroups: []string{"node1-disk1", "node1-disk2"},
+				},
+				{
+					Name:        "disk2",
+					VolumeGroup: "node1-disk2",
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "size-in-options",
+					VolumeGroup:     "node1-myvg1",
+					LVCreateOptions: []string{"--size=1G"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "stripes-twice",
+					VolumeGroup:     "node1-myvg1",
+					Stripe:          &stripe,
+					LVCreateOptions: []string{"-i", "2"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "positional-in-options",
+					VolumeGroup:     "node1-myvg1",
+					LVCreateOptions: []string{"--type=raid1", "/dev/sdb"},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "thick-with-thin",
+					VolumeGroup:     "node1-myvg1",
+					ThinDeviceClass: "thin",
+				},
+				{
+					Name:        "thin",
+					VolumeGroup: "node1-myvg1",
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: opRatio,
+					},
+				},
+			},
+			valid: true,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "thick-with-other-vg",
+					VolumeGroup:     "node1-myvg2",
+					ThinDeviceClass: "thin",
+				},
+				{
+					Name:        "thin",
+					VolumeGroup: "node1-myvg1",
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: opRatio,
+					},
+				},
+			},
+			valid: false,
+		},
+		{
+			deviceClasses: []*lvmdTypes.DeviceClass{
+				{
+					Name:            "thick-with-thick",
+					VolumeGroup:     "node1-myvg1",
+					ThinDeviceClass: "other",
+				},
+				{
+					Name:        "other",
+					VolumeGroup: "node1-myvg1",
+				},
+			},
+			valid: false,
+		},
 	}
 
 	for i, c := range cases {
@@ -384,6 +929,72 @@ func TestValidateDeviceClasses(t *testing.T) {
 	}
 }
 
+func TestGetUsableBytes(t *testing.T) {
+	stripe := uint(2)
+	mirrors := uint(2)
+	cases := []struct {
+		dc       *lvmdTypes.DeviceClass
+		expected uint64
+	}{
+		{dc: &lvmdTypes.DeviceClass{}, expected: 12 << 30},
+		{dc: &lvmdTypes.DeviceClass{RAID: &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1}}, expected: 6 << 30},
+		{dc: &lvmdTypes.DeviceClass{RAID: &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID1, Mirrors: &mirrors}}, expected: 4 << 30},
+		{dc: &lvmdTypes.DeviceClass{Stripe: &stripe, RAID: &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID5}}, expected: 8 << 30},
+		{dc: &lvmdTypes.DeviceClass{Stripe: &stripe, RAID: &lvmdTypes.RAIDConfig{Type: lvmdTypes.TypeRAID10}}, expected: 6 << 30},
+	}
+
+	for i, c := range cases {
+		if actual := GetUsableBytes(c.dc, 12<<30); actual != c.expected {
+			t.Errorf("%d: expected %d, actual %d", i, c.expected, actual)
+		}
+	}
+}
+
+func TestGetCacheBytes(t *testing.T) {
+	percent := uint(20)
+	cases := []struct {
+		dc       *lvmdTypes.DeviceClass
+		size     uint64
+		expected uint64
+	}{
+		{dc: &lvmdTypes.DeviceClass{}, size: 10 << 30, expected: 0},
+		{dc: &lvmdTypes.DeviceClass{Cache: &lvmdTypes.CacheConfig{Device: "/dev/sdb"}}, size: 10 << 30, expected: 1 << 30},
+		{dc: &lvmdTypes.DeviceClass{Cache: &lvmdTypes.CacheConfig{Device: "/dev/sdb", SizePercent: &percent}}, size: 10 << 30, expected: 2 << 30},
+		// rounded up to the sector size
+		{dc: &lvmdTypes.DeviceClass{Cache: &lvmdTypes.CacheConfig{Device: "/dev/sdb"}}, size: 4096, expected: 4096},
+	}
+
+	for i, c := range cases {
+		if actual := GetCacheBytes(c.dc, c.size); actual != c.expected {
+			t.Errorf("%d: expected %d, actual %d", i, c.expected, actual)
+		}
+	}
+}
+
+func TestGetVDOOptions(t *testing.T) {
+	percent := uint(50)
+	disabled := false
+	if opts := GetVDOOptions(&lvmdTypes.DeviceClass{}, 10<<30); opts != nil {
+		t.Errorf("unexpected options without vdo: %+v", opts)
+	}
+	cases := []struct {
+		vdo      *lvmdTypes.VDOConfig
+		size     uint64
+		expected command.VDOOptions
+	}{
+		{vdo: &lvmdTypes.VDOConfig{}, size: 10 << 30, expected: command.VDOOptions{PhysicalSize: 10 << 30, Compression: true, Deduplication: true}},
+		{vdo: &lvmdTypes.VDOConfig{PhysicalSizePercent: &percent, Compression: &disabled}, size: 10 << 30, expected: command.VDOOptions{PhysicalSize: 5 << 30, Deduplication: true}},
+		// rounded up to the sector size
+		{vdo: &lvmdTypes.VDOConfig{PhysicalSizePercent: &percent, Deduplication: &disabled}, size: 4096, expected: command.VDOOptions{PhysicalSize: 4096, Compression: true}},
+	}
+
+	for i, c := range cases {
+		if actual := GetVDOOptions(&lvmdTypes.DeviceClass{VDO: c.vdo}, c.size); *actual != c.expected {
+			t.Errorf("%d: expected %+v, actual %+v", i, c.expected, *actual)
+		}
+	}
+}
+
 func TestDeviceClassManager(t *testing.T) {
 	spare50gb := uint64(50)
 	spare100gb := uint64(100)
@@ -478,4 +1089,118 @@ func TestDeviceClassManager(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+
+	multi := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "disks", VolumeGroups: []string{"disk1", "disk2"}},
+	})
+	for _, vgName := range []string{"disk1", "disk2"} {
+		dc, err := multi.FindDeviceClassByVGName(vgName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dc.Name != "disks" {
+			t.Errorf("wrong device-class found for %s: %s", vgName, dc.Name)
+		}
+	}
+}
+
+func TestProvisioningDeviceClass(t *testing.T) {
+	stripe := uint(2)
+	thick := &lvmdTypes.DeviceClass{
+		Name:        "thick",
+		VolumeGroup: "vg0",
+		Stripe:      &stripe,
+		Cache:       &lvmdTypes.CacheConfig{Device: "/dev/fast"},
+	}
+	thin := &lvmdTypes.DeviceClass{
+		Name:        "thin",
+		VolumeGroup: "vg0",
+		Type:        lvmdTypes.TypeThin,
+		ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+			Name:               "pool0",
+			OverprovisionRatio: 10.0,
+		},
+	}
+	other := &lvmdTypes.DeviceClass{
+		Name:        "other",
+		VolumeGroup: "vg1",
+	}
+	manager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{thick, thin, other})
+
+	for _, provisioningType := range []lvmdTypes.DeviceType{"", lvmdTypes.TypeThick} {
+		dc, err := manager.ProvisioningDeviceClass(thick, provisioningType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dc != thick {
+			t.Errorf("%q: the device-class itself should be returned", provisioningType)
+		}
+	}
+
+	dc, err := manager.ProvisioningDeviceClass(thick, lvmdTypes.TypeThin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.Type != lvmdTypes.TypeThin || dc.ThinPoolConfig != thin.ThinPoolConfig || dc.Cache != nil || *dc.Stripe != stripe {
+		t.Errorf("unexpected device-class: %+v", dc)
+	}
+	if thick.Type != lvmdTypes.TypeThick {
+		t.Error("the original device-class should not be modified")
+	}
+
+	dc, err = manager.ProvisioningDeviceClass(thin, lvmdTypes.TypeThick)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.Type != lvmdTypes.TypeThick || dc.ThinPoolConfig != nil || dc.VolumeGroup != "vg0" {
+		t.Errorf("unexpected device-class: %+v", dc)
+	}
+
+	if _, err := manager.ProvisioningDeviceClass(other, lvmdTypes.TypeThin); !errors.Is(err, ErrNoThinPool) {
+		t.Errorf("expected ErrNoThinPool, got %v", err)
+	}
+	if _, err := manager.ProvisioningDeviceClass(thick, "unknown"); !errors.Is(err, ErrInvalidProvisioningType) {
+		t.Errorf("expected ErrInvalidProvisioningType, got %v", err)
+	}
+
+	// the thin pool is ambiguous with more than one thin device-class on the volume group.
+	thin2 := &lvmdTypes.DeviceClass{
+		Name:           "thin2",
+		VolumeGroup:    "vg0",
+		Type:           lvmdTypes.TypeThin,
+		ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool1", OverprovisionRatio: 10.0},
+	}
+	manager = NewDeviceClassManager([]*lvmdTypes.DeviceClass{thick, thin, thin2})
+	if _, err := manager.ProvisioningDeviceClass(thick, lvmdTypes.TypeThin); !errors.Is(err, ErrNoThinPool) {
+		t.Errorf("expected ErrNoThinPool, got %v", err)
+	}
+	explicit := *thick
+	explicit.ThinDeviceClass = "thin2"
+	dc, err = manager.ProvisioningDeviceClass(&explicit, lvmdTypes.TypeThin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dc.ThinPoolConfig != thin2.ThinPoolConfig {
+		t.Errorf("the thin pool of thin-device-class should be used: %+v", dc.ThinPoolConfig)
+	}
+}
+
+func TestGetSnapshotCOWBytes(t *testing.T) {
+	percent := uint(20)
+	cases := []struct {
+		dc       *lvmdTypes.DeviceClass
+		size     uint64
+		expected uint64
+	}{
+		{dc: &lvmdTypes.DeviceClass{}, size: 10 << 30, expected: 10 << 30},
+		{dc: &lvmdTypes.DeviceClass{SnapshotCOWSizePercent: &percent}, size: 10 << 30, expected: 2 << 30},
+		// rounded up to the sector size
+		{dc: &lvmdTypes.DeviceClass{SnapshotCOWSizePercent: &percent}, size: 4096, expected: 4096},
+	}
+
+	for i, c := range cases {
+		if actual := GetSnapshotCOWBytes(c.dc, c.size); actual != c.expected {
+			t.Errorf("%d: expected %d, actual %d", i, c.expected, actual)
+		}
+	}
 }
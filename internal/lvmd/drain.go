@@ -0,0 +1,72 @@
+package lvmd
+
+import (
+	"context"
+	"sync"
+
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// drainState holds the device-classes being drained, which start with draining in the configuration
+// and are changed by SetDeviceClassDraining until lvmd restarts.
+type drainState struct {
+	mu       sync.Mutex
+	draining map[string]bool
+}
+
+// IsDraining returns true if the device-class rejects new logical volumes.
+func (m DeviceClassManager) IsDraining(dcName string) bool {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	return m.drain.draining[dcName]
+}
+
+// SetDraining starts or stops draining the device-class. It returns true if the state has changed.
+func (m DeviceClassManager) SetDraining(dcName string, draining bool) bool {
+	m.drain.mu.Lock()
+	defer m.drain.mu.Unlock()
+	if m.drain.draining[dcName] == draining {
+		return false
+	}
+	m.drain.draining[dcName] = draining
+	return true
+}
+
+// checkDraining returns codes.ResourceExhausted if the device-class is draining, unless the logical volume name
+// already exists, so that a retried request fails as without draining.
+func (s *lvService) checkDraining(ctx context.Context, dc *lvmdTypes.DeviceClass, name string) error {
+	if !s.dcmapper.IsDraining(dc.Name) {
+		return nil
+	}
+	names, err := s.volumeNames(ctx, dc)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to list volumes", "deviceClass", dc.Name)
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return internalError(err)
+	}
+	if containsString(names, name) {
+		return nil
+	}
+	return status.Errorf(codes.ResourceExhausted, "device-class %s is draining and accepts no new volumes", dc.Name)
+}
+
+func (s *vgService) SetDeviceClassDraining(ctx context.Context, req *proto.SetDeviceClassDrainingRequest) (*proto.Empty, error) {
+	dc, err := s.dcManager.DeviceClass(req.GetDeviceClass())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+	}
+	if !s.dcManager.SetDraining(dc.Name, req.GetDraining()) {
+		return &proto.Empty{}, nil
+	}
+	log.FromContext(ctx).Info("changed draining of device class", "deviceClass", dc.Name, "draining", req.GetDraining())
+	// the free bytes of the device-class have changed.
+	s.freeBytes.invalidate()
+	s.notifyWatchers()
+	return &proto.Empty{}, nil
+}
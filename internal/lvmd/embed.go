@@ -7,6 +7,7 @@ import (
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	gproto "google.golang.org/protobuf/proto"
@@ -18,7 +19,7 @@ func NewEmbeddedServiceClients(ctx context.Context, dcmapper *DeviceClassManager
 	proto.LVServiceClient,
 	proto.VGServiceClient,
 ) {
-	vgServiceServerInstance, notifier := NewVGService(dcmapper)
+	vgServiceServerInstance, notifier := NewVGService(dcmapper, ocmapper)
 	lvServiceServerInstance := NewLVService(dcmapper, ocmapper, notifier)
 
 	caller := &embeddedServiceClients{
@@ -135,6 +136,94 @@ func (l *embeddedServiceClients) Watch(_ context.Context, _ *proto.Empty, _ ...g
 	return l.vgWatch, nil
 }
 
+// WatchLVs runs the watch of the embedded lvmd in the background, relaying the responses via channel
+// until ctx is done.
+func (l *embeddedServiceClients) WatchLVs(ctx context.Context, in *proto.WatchLVsRequest, _ ...grpc.CallOption) (proto.VGService_WatchLVsClient, error) {
+	watch := &embeddedLVWatch{&embeddedChannelWatch{ctx: ctx, watch: make(chan any)}}
+	go func() {
+		if err := l.vgServiceServer.WatchLVs(in, watch); err != nil && ctx.Err() == nil {
+			log.FromContext(ctx).Error(err, "embedded logical volume watch error")
+		}
+	}()
+	return watch, nil
+}
+
+// embeddedLVWatch is a local implementation of the VGService_WatchLVsClient and VGService_WatchLVsServer.
+type embeddedLVWatch struct {
+	*embeddedChannelWatch
+}
+
+// Recv is used to receive a WatchLVsResponse as a VGService_WatchLVsClient.
+func (l *embeddedLVWatch) Recv() (*proto.WatchLVsResponse, error) {
+	m := new(proto.WatchLVsResponse)
+	if err := l.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Send is used to send a WatchLVsResponse as a VGService_WatchLVsServer.
+// Unlike SendMsg, it gives up when the receiver is gone.
+func (l *embeddedLVWatch) Send(m *proto.WatchLVsResponse) error {
+	select {
+	case l.watch <- m:
+		return nil
+	case <-l.ctx.Done():
+		return l.ctx.Err()
+	}
+}
+
+// NewEmbeddedHealthClient creates a health client locally checking the device-classes instead of using gRPC.
+func NewEmbeddedHealthClient(dcmapper *DeviceClassManager) grpc_health_v1.HealthClient {
+	return &embeddedHealthClient{server: NewHealthService(dcmapper)}
+}
+
+// embeddedHealthClient is an indirection to the local health server implementing the HealthClient interface.
+type embeddedHealthClient struct {
+	server grpc_health_v1.HealthServer
+}
+
+func (l *embeddedHealthClient) Check(ctx context.Context, in *grpc_health_v1.HealthCheckRequest, _ ...grpc.CallOption) (*grpc_health_v1.HealthCheckResponse, error) {
+	return l.server.Check(ctx, in)
+}
+
+// Watch runs the health watch of the embedded lvmd in the background, relaying the responses via channel
+// until ctx is done.
+func (l *embeddedHealthClient) Watch(ctx context.Context, in *grpc_health_v1.HealthCheckRequest, _ ...grpc.CallOption) (grpc_health_v1.Health_WatchClient, error) {
+	watch := &embeddedHealthWatch{&embeddedChannelWatch{ctx: ctx, watch: make(chan any)}}
+	go func() {
+		if err := l.server.Watch(in, watch); err != nil && ctx.Err() == nil {
+			log.FromContext(ctx).Error(err, "embedded health watch error")
+		}
+	}()
+	return watch, nil
+}
+
+// embeddedHealthWatch is a local implementation of the Health_WatchClient and Health_WatchServer.
+type embeddedHealthWatch struct {
+	*embeddedChannelWatch
+}
+
+// Recv is used to receive a HealthCheckResponse as a Health_WatchClient.
+func (l *embeddedHealthWatch) Recv() (*grpc_health_v1.HealthCheckResponse, error) {
+	m := new(grpc_health_v1.HealthCheckResponse)
+	if err := l.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Send is used to send a HealthCheckResponse as a Health_WatchServer.
+// Unlike SendMsg, it gives up when the receiver is gone.
+func (l *embeddedHealthWatch) Send(m *grpc_health_v1.HealthCheckResponse) error {
+	select {
+	case l.watch <- m:
+		return nil
+	case <-l.ctx.Done():
+		return l.ctx.Err()
+	}
+}
+
 func (l *embeddedServiceClients) CreateLV(ctx context.Context, in *proto.CreateLVRequest, _ ...grpc.CallOption) (*proto.CreateLVResponse, error) {
 	return l.lvServiceServer.CreateLV(ctx, in)
 }
@@ -143,18 +232,96 @@ func (l *embeddedServiceClients) RemoveLV(ctx context.Context, in *proto.RemoveL
 	return l.lvServiceServer.RemoveLV(ctx, in)
 }
 
-func (l *embeddedServiceClients) ResizeLV(ctx context.Context, in *proto.ResizeLVRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+func (l *embeddedServiceClients) ResizeLV(ctx context.Context, in *proto.ResizeLVRequest, _ ...grpc.CallOption) (*proto.ResizeLVResponse, error) {
 	return l.lvServiceServer.ResizeLV(ctx, in)
 }
 
+func (l *embeddedServiceClients) ChangeLVTags(ctx context.Context, in *proto.ChangeLVTagsRequest, _ ...grpc.CallOption) (*proto.ChangeLVTagsResponse, error) {
+	return l.lvServiceServer.ChangeLVTags(ctx, in)
+}
+
+func (l *embeddedServiceClients) ActivateLV(ctx context.Context, in *proto.ActivateLVRequest, _ ...grpc.CallOption) (*proto.ActivateLVResponse, error) {
+	return l.lvServiceServer.ActivateLV(ctx, in)
+}
+
+func (l *embeddedServiceClients) DeactivateLV(ctx context.Context, in *proto.DeactivateLVRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.lvServiceServer.DeactivateLV(ctx, in)
+}
+
 func (l *embeddedServiceClients) CreateLVSnapshot(ctx context.Context, in *proto.CreateLVSnapshotRequest, _ ...grpc.CallOption) (*proto.CreateLVSnapshotResponse, error) {
 	return l.lvServiceServer.CreateLVSnapshot(ctx, in)
 }
 
+func (l *embeddedServiceClients) MergeLVSnapshot(ctx context.Context, in *proto.MergeLVSnapshotRequest, _ ...grpc.CallOption) (*proto.MergeLVSnapshotResponse, error) {
+	return l.lvServiceServer.MergeLVSnapshot(ctx, in)
+}
+
+func (l *embeddedServiceClients) CreateDeviceClassSnapshot(ctx context.Context, in *proto.CreateDeviceClassSnapshotRequest, _ ...grpc.CallOption) (*proto.CreateDeviceClassSnapshotResponse, error) {
+	return l.lvServiceServer.CreateDeviceClassSnapshot(ctx, in)
+}
+
+func (l *embeddedServiceClients) MoveLV(ctx context.Context, in *proto.MoveLVRequest, _ ...grpc.CallOption) (*proto.MoveLVResponse, error) {
+	return l.lvServiceServer.MoveLV(ctx, in)
+}
+
+func (l *embeddedServiceClients) ReportThinPoolEvent(ctx context.Context, in *proto.ReportThinPoolEventRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.ReportThinPoolEvent(ctx, in)
+}
+
 func (l *embeddedServiceClients) GetLVList(ctx context.Context, in *proto.GetLVListRequest, _ ...grpc.CallOption) (*proto.GetLVListResponse, error) {
 	return l.vgServiceServer.GetLVList(ctx, in)
 }
 
+func (l *embeddedServiceClients) ListSnapshots(ctx context.Context, in *proto.ListSnapshotsRequest, _ ...grpc.CallOption) (*proto.ListSnapshotsResponse, error) {
+	return l.vgServiceServer.ListSnapshots(ctx, in)
+}
+
 func (l *embeddedServiceClients) GetFreeBytes(ctx context.Context, in *proto.GetFreeBytesRequest, _ ...grpc.CallOption) (*proto.GetFreeBytesResponse, error) {
 	return l.vgServiceServer.GetFreeBytes(ctx, in)
 }
+
+func (l *embeddedServiceClients) CreateVG(ctx context.Context, in *proto.CreateVGRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.CreateVG(ctx, in)
+}
+
+func (l *embeddedServiceClients) RemoveVG(ctx context.Context, in *proto.RemoveVGRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.RemoveVG(ctx, in)
+}
+
+func (l *embeddedServiceClients) ExtendVG(ctx context.Context, in *proto.ExtendVGRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.ExtendVG(ctx, in)
+}
+
+func (l *embeddedServiceClients) ReduceVG(ctx context.Context, in *proto.ReduceVGRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.ReduceVG(ctx, in)
+}
+
+func (l *embeddedServiceClients) GetThinPoolUsage(ctx context.Context, in *proto.GetThinPoolUsageRequest, _ ...grpc.CallOption) (*proto.GetThinPoolUsageResponse, error) {
+	return l.vgServiceServer.GetThinPoolUsage(ctx, in)
+}
+
+func (l *embeddedServiceClients) GetLVMVersion(ctx context.Context, in *proto.Empty, _ ...grpc.CallOption) (*proto.GetLVMVersionResponse, error) {
+	return l.vgServiceServer.GetLVMVersion(ctx, in)
+}
+
+func (l *embeddedServiceClients) GetCapabilities(ctx context.Context, in *proto.Empty, _ ...grpc.CallOption) (*proto.GetCapabilitiesResponse, error) {
+	return l.vgServiceServer.GetCapabilities(ctx, in)
+}
+
+func (l *embeddedServiceClients) GetLVMDevices(ctx context.Context, in *proto.Empty, _ ...grpc.CallOption) (*proto.GetLVMDevicesResponse, error) {
+	return l.vgServiceServer.GetLVMDevices(ctx, in)
+}
+
+func (l *embeddedServiceClients) UpdateLVMDevices(ctx context.Context, in *proto.UpdateLVMDevicesRequest, _ ...grpc.CallOption) (*proto.GetLVMDevicesResponse, error) {
+	return l.vgServiceServer.UpdateLVMDevices(ctx, in)
+}
+
+func (l *embeddedServiceClients) SetDeviceClassDraining(ctx context.Context, in *proto.SetDeviceClassDrainingRequest, _ ...grpc.CallOption) (*proto.Empty, error) {
+	return l.vgServiceServer.SetDeviceClassDraining(ctx, in)
+}
+
+// EvacuatePV is not relayed to the embedded lvmd, as moving physical volumes is an operation for node operators
+// running lvmd as a dedicated process.
+func (l *embeddedServiceClients) EvacuatePV(_ context.Context, _ *proto.EvacuatePVRequest, _ ...grpc.CallOption) (proto.VGService_EvacuatePVClient, error) {
+	return nil, status.Error(codes.Unimplemented, "EvacuatePV is not supported by the embedded lvmd")
+}
@@ -0,0 +1,71 @@
+package lvmd
+
+import (
+	"sync"
+	"time"
+)
+
+// FreeBytesCacheMaxAge is the maximum age of the free bytes that GetFreeBytes returns from the cache
+// instead of running lvm. Zero disables the cache.
+var FreeBytesCacheMaxAge = 10 * time.Second
+
+// freeBytesCache caches the free bytes of the device-classes computed by Watch and GetFreeBytes.
+// The cache is invalidated when the volumes are changed, so that the free bytes are never older than
+// the last change made through lvmd.
+type freeBytesCache struct {
+	mu sync.Mutex
+	// generation is incremented by invalidate, so that the free bytes computed before a change are not stored.
+	generation uint64
+	entries    map[string]freeBytesEntry
+}
+
+type freeBytesEntry struct {
+	freeBytes uint64
+	at        time.Time
+}
+
+func newFreeBytesCache() *freeBytesCache {
+	return &freeBytesCache{entries: make(map[string]freeBytesEntry)}
+}
+
+// get returns the cached free bytes of the device-class if they are newer than FreeBytesCacheMaxAge.
+func (c *freeBytesCache) get(deviceClass string) (uint64, bool) {
+	if FreeBytesCacheMaxAge <= 0 {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[deviceClass]
+	if !ok || time.Since(entry.at) > FreeBytesCacheMaxAge {
+		return 0, false
+	}
+	return entry.freeBytes, true
+}
+
+// begin returns the generation to pass to put with the free bytes computed from now on.
+func (c *freeBytesCache) begin() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// put stores the free bytes computed since begin returned the generation, unless the cache has been invalidated since.
+func (c *freeBytesCache) put(generation uint64, freeBytes map[string]uint64) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	for dc, free := range freeBytes {
+		c.entries[dc] = freeBytesEntry{freeBytes: free, at: now}
+	}
+}
+
+// invalidate drops the cached free bytes.
+func (c *freeBytesCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]freeBytesEntry)
+}
@@ -2,18 +2,127 @@ package lvmd
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-func NewHealthService() grpc_health_v1.HealthServer {
-	return &healthService{}
+// healthWatchInterval is the interval to check the health for the Watch streams.
+const healthWatchInterval = 30 * time.Second
+
+// NewHealthService creates a HealthServer checking the LVM backend of the device-classes.
+//
+// The service "" and the names of the LVMd services report whether all the device-classes are usable,
+// and the name of a device-class reports whether that device-class is usable.
+func NewHealthService(manager *DeviceClassManager) grpc_health_v1.HealthServer {
+	return &healthService{dcManager: manager}
 }
 
 type healthService struct {
 	grpc_health_v1.UnimplementedHealthServer
+	dcManager *DeviceClassManager
+}
+
+func (s *healthService) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	dcs, err := s.deviceClasses(req.GetService())
+	if err != nil {
+		return nil, err
+	}
+	return &grpc_health_v1.HealthCheckResponse{Status: s.check(ctx, dcs)}, nil
+}
+
+// Watch sends the health on start and whenever it changes, checking it every healthWatchInterval.
+// Unknown services are reported as SERVICE_UNKNOWN instead of failing, as the health checking protocol requires.
+func (s *healthService) Watch(req *grpc_health_v1.HealthCheckRequest, server grpc_health_v1.Health_WatchServer) error {
+	ctx := server.Context()
+	dcs, err := s.deviceClasses(req.GetService())
+	if err != nil {
+		return server.Send(&grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN})
+	}
+
+	ticker := time.NewTicker(healthWatchInterval)
+	defer ticker.Stop()
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		if current := s.check(ctx, dcs); current != last {
+			if err := server.Send(&grpc_health_v1.HealthCheckResponse{Status: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// deviceClasses returns the device-classes checked for the service.
+func (s *healthService) deviceClasses(service string) ([]*lvmdTypes.DeviceClass, error) {
+	switch service {
+	case "", proto.LVService_ServiceDesc.ServiceName, proto.VGService_ServiceDesc.ServiceName:
+		return s.dcManager.DeviceClasses(), nil
+	}
+	dc, err := s.dcManager.DeviceClass(service)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "unknown service: %s", service)
+	}
+	return []*lvmdTypes.DeviceClass{dc}, nil
 }
 
-func (*healthService) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
-	return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+// check returns SERVING if all the device-classes are usable, logging the reason otherwise.
+func (s *healthService) check(ctx context.Context, dcs []*lvmdTypes.DeviceClass) grpc_health_v1.HealthCheckResponse_ServingStatus {
+	var vgs []*command.VolumeGroup
+	for _, dc := range dcs {
+		if dc.Type != lvmdTypes.TypeRaw && vgs == nil {
+			var err error
+			vgs, err = command.ListVolumeGroups(ctx)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "failed to list volume groups for health check")
+				return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			}
+		}
+		if err := checkDeviceClass(ctx, dc, vgs); err != nil {
+			log.FromContext(ctx).Error(err, "device-class is unhealthy", "deviceClass", dc.Name)
+			return grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return grpc_health_v1.HealthCheckResponse_SERVING
+}
+
+// checkDeviceClass returns an error if the volume group or thin pool of the device-class is missing,
+// or the devices or the state file of a raw device-class cannot be read.
+// A thin pool running out of space is not an error, as restarting LVMd does not resolve it.
+func checkDeviceClass(ctx context.Context, dc *lvmdTypes.DeviceClass, vgs []*command.VolumeGroup) error {
+	if dc.Type == lvmdTypes.TypeRaw {
+		if _, err := rawDevices(ctx, dc); err != nil {
+			return fmt.Errorf("failed to list devices: %w", err)
+		}
+		if _, err := loadRawState(dc); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	for _, name := range VolumeGroupNames(dc) {
+		if _, err := command.SearchVolumeGroupList(vgs, name); err != nil {
+			return fmt.Errorf("volume group %s: %w", name, err)
+		}
+	}
+	if dc.Type == lvmdTypes.TypeThin {
+		vg, _ := command.SearchVolumeGroupList(vgs, dc.VolumeGroup)
+		if _, err := vg.FindPool(ctx, dc.ThinPoolConfig.Name); err != nil {
+			return fmt.Errorf("thin pool %s/%s: %w", dc.VolumeGroup, dc.ThinPoolConfig.Name, err)
+		}
+	}
+	return nil
 }
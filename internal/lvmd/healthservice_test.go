@@ -0,0 +1,85 @@
+package lvmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestHealthServiceWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("ssd-vg", 10<<30)
+	fake.AddVolumeGroup("thin-vg", 10<<30)
+	defer command.SetExecutor(fake)()
+
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg"},
+		{Name: "thin", VolumeGroup: "thin-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool"}},
+	})
+	health := NewHealthService(dcManager)
+
+	check := func(service string, expected grpc_health_v1.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		res, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetStatus() != expected {
+			t.Errorf("expected %s for service %q, got %s", expected, service, res.GetStatus())
+		}
+	}
+
+	// the thin pool is missing.
+	check("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	check("proto.LVService", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	check("thin", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	check("ssd", grpc_health_v1.HealthCheckResponse_SERVING)
+	if _, err := health.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown service, got %v", err)
+	}
+
+	vg, err := command.FindVolumeGroup(ctx, "thin-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.CreatePool(ctx, "pool", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+	check("", grpc_health_v1.HealthCheckResponse_SERVING)
+	check("proto.VGService", grpc_health_v1.HealthCheckResponse_SERVING)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watch, err := NewEmbeddedHealthClient(dcManager).Watch(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := watch.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("expected SERVING to be watched, got %s", res.GetStatus())
+	}
+
+	watch, err = NewEmbeddedHealthClient(dcManager).Watch(ctx, &grpc_health_v1.HealthCheckRequest{Service: "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = watch.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVICE_UNKNOWN {
+		t.Errorf("expected SERVICE_UNKNOWN to be watched, got %s", res.GetStatus())
+	}
+}
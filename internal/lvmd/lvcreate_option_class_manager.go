@@ -1,6 +1,11 @@
 package lvmd
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
 )
 
@@ -18,7 +23,69 @@ func NewLvcreateOptionClassManager(LvcreateOptionClasses []*lvmdTypes.LvcreateOp
 	return &cm
 }
 
+// ValidateLvcreateOptionClasses validates lvcreate-option-classes. The options cannot set the options lvmd sets
+// from the request, such as the name and the size of the volume, and neither can the inline options allowed
+// by the prefixes.
+func ValidateLvcreateOptionClasses(classes []*lvmdTypes.LvcreateOptionClass) error {
+	names := make(map[string]bool)
+	for _, c := range classes {
+		if len(c.Name) == 0 {
+			return errors.New("lvcreate-option-class name should not be empty")
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate lvcreate-option-class name: %s", c.Name)
+		}
+		names[c.Name] = true
+		if err := command.ValidateLvcreateOptions(c.Options); err != nil {
+			return fmt.Errorf("invalid options of lvcreate-option-class %s: %w", c.Name, err)
+		}
+		for _, prefix := range c.InlineOptions {
+			if prefix == "" {
+				continue
+			}
+			if err := command.ValidateLvcreateOptionPrefix(prefix); err != nil {
+				return fmt.Errorf("invalid inline-options of lvcreate-option-class %s: %w", c.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // LvcreateOptionClassClass returns the lvcreate-option-class by its name
 func (m LvcreateOptionClassManager) LvcreateOptionClass(name string) *lvmdTypes.LvcreateOptionClass {
 	return m.LvcreateOptionClassByName[name]
 }
+
+// InlineOptions returns the prefixes of the inline options allowed for each lvcreate-option-class.
+// Classes without inline options are omitted.
+func (m LvcreateOptionClassManager) InlineOptions() map[string][]string {
+	allowed := make(map[string][]string)
+	for name, c := range m.LvcreateOptionClassByName {
+		if len(c.InlineOptions) > 0 {
+			allowed[name] = c.InlineOptions
+		}
+	}
+	return allowed
+}
+
+// ValidateInlineOptions returns an error if the class is not found or an inline option does not start
+// with one of the prefixes allowed by the class.
+func (m LvcreateOptionClassManager) ValidateInlineOptions(name string, options []string) error {
+	c := m.LvcreateOptionClass(name)
+	if c == nil {
+		return fmt.Errorf("inline lvcreate options need a lvcreate-option-class, but %q is not found", name)
+	}
+	for _, option := range options {
+		allowed := false
+		for _, prefix := range c.InlineOptions {
+			if prefix != "" && strings.HasPrefix(option, prefix) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("inline lvcreate option %q is not allowed by lvcreate-option-class %s", option, name)
+		}
+	}
+	return command.ValidateLvcreateOptions(options)
+}
@@ -43,3 +43,94 @@ func TestLvcreateOptionClassManager(t *testing.T) {
 		}
 	}
 }
+
+func TestLvcreateOptionClassManagerValidateInlineOptions(t *testing.T) {
+	ocm := NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{
+		{
+			Name:          "tunable",
+			Options:       []string{"--type=raid1"},
+			InlineOptions: []string{"--config=allocation/", "--alloc="},
+		},
+		{
+			Name:    "fixed",
+			Options: []string{"--type=raid1"},
+		},
+	})
+
+	cases := []struct {
+		class   string
+		options []string
+		valid   bool
+	}{
+		{"tunable", []string{"--config=allocation/cling_tag_list=[\"@ssd\"]", "--alloc=cling"}, true},
+		{"tunable", nil, true},
+		{"tunable", []string{"--config=devices/filter=[\"a|.*|\"]"}, false},
+		{"tunable", []string{"--alloc"}, false},
+		{"fixed", []string{"--alloc=cling"}, false},
+		{"not-found", []string{"--alloc=cling"}, false},
+	}
+	for _, c := range cases {
+		err := ocm.ValidateInlineOptions(c.class, c.options)
+		if c.valid && err != nil {
+			t.Errorf("%s %v should be valid: %v", c.class, c.options, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s %v should be invalid", c.class, c.options)
+		}
+	}
+
+	allowed := ocm.InlineOptions()
+	if len(allowed) != 1 || len(allowed["tunable"]) != 2 {
+		t.Errorf("unexpected inline options: %v", allowed)
+	}
+}
+
+func TestValidateLvcreateOptionClasses(t *testing.T) {
+	cases := []struct {
+		name    string
+		classes []*lvmdTypes.LvcreateOptionClass
+		valid   bool
+	}{
+		{
+			name: "valid",
+			classes: []*lvmdTypes.LvcreateOptionClass{
+				{Name: "raid", Options: []string{"--type=raid1", "-W", "n"}, InlineOptions: []string{"--config=allocation/"}},
+				{Name: "plain"},
+			},
+			valid: true,
+		},
+		{
+			name:    "empty name",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Options: []string{"--type=raid1"}}},
+		},
+		{
+			name:    "duplicate name",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Name: "raid"}, {Name: "raid"}},
+		},
+		{
+			name:    "size",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Name: "sized", Options: []string{"-L", "1G"}}},
+		},
+		{
+			name:    "name",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Name: "named", Options: []string{"--name=vol"}}},
+		},
+		{
+			name:    "inline size",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Name: "sized", InlineOptions: []string{"--size="}}},
+		},
+		{
+			name:    "inline anything",
+			classes: []*lvmdTypes.LvcreateOptionClass{{Name: "open", InlineOptions: []string{"--"}}},
+		},
+	}
+	for _, c := range cases {
+		err := ValidateLvcreateOptionClasses(c.classes)
+		if c.valid && err != nil {
+			t.Errorf("%s: should be valid: %v", c.name, err)
+		}
+		if !c.valid && err == nil {
+			t.Errorf("%s: should be invalid", c.name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func (s *vgService) GetLVMDevices(ctx context.Context, _ *proto.Empty) (*proto.GetLVMDevicesResponse, error) {
+	res, err := lvmDevices(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to get lvm devices")
+		return nil, internalError(err)
+	}
+	return res, nil
+}
+
+func (s *vgService) UpdateLVMDevices(ctx context.Context, req *proto.UpdateLVMDevicesRequest) (*proto.GetLVMDevicesResponse, error) {
+	logger := log.FromContext(ctx).WithValues("add", req.GetAdd(), "remove", req.GetRemove())
+	if len(req.GetAdd()) == 0 && len(req.GetRemove()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "devices to add or remove are required")
+	}
+	useDevicesFile, err := command.UseDevicesFile(ctx)
+	if err != nil {
+		logger.Error(err, "failed to get lvm configuration")
+		return nil, internalError(err)
+	}
+	if !useDevicesFile {
+		return nil, status.Errorf(codes.FailedPrecondition, "lvm does not use the devices file, set %s to 1 in lvm.conf", command.UseDevicesFileKey)
+	}
+
+	// lvm would no longer find the volume group of a device class without its physical volumes.
+	for _, device := range req.GetRemove() {
+		pv, err := command.FindPhysicalVolume(ctx, device)
+		if errors.Is(err, command.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return nil, internalError(err)
+		}
+		if dc, err := s.dcManager.FindDeviceClassByVGName(pv.VGName()); err == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "physical volume %s is used by device class %s", device, dc.Name)
+		}
+	}
+
+	for _, device := range req.GetAdd() {
+		if err := command.AddToDevicesFile(ctx, device); err != nil {
+			logger.Error(err, "failed to add device to devices file", "device", device)
+			return nil, internalError(err)
+		}
+	}
+	for _, device := range req.GetRemove() {
+		if err := command.RemoveFromDevicesFile(ctx, device); err != nil {
+			logger.Error(err, "failed to remove device from devices file", "device", device)
+			return nil, internalError(err)
+		}
+	}
+	logger.Info("updated lvm devices file")
+
+	res, err := lvmDevices(ctx)
+	if err != nil {
+		logger.Error(err, "failed to get lvm devices")
+		return nil, internalError(err)
+	}
+	return res, nil
+}
+
+// lvmDevices returns the devices file and the device filters of lvm.
+func lvmDevices(ctx context.Context) (*proto.GetLVMDevicesResponse, error) {
+	res := &proto.GetLVMDevicesResponse{}
+	var err error
+	res.UseDevicesFile, err = command.UseDevicesFile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if res.UseDevicesFile {
+		entries, err := command.ListDevicesFile(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			res.Devices = append(res.Devices, &proto.LVMDevice{
+				Device: entry.Device,
+				IdType: entry.IDType,
+				IdName: entry.IDName,
+				Pvid:   entry.PVID,
+			})
+		}
+	}
+	res.GlobalFilter, _, err = command.GetConfigList(ctx, command.GlobalFilterKey)
+	if err != nil {
+		return nil, err
+	}
+	res.Filter, _, err = command.GetConfigList(ctx, command.FilterKey)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
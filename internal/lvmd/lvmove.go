@@ -0,0 +1,305 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// movingSuffix is appended to the name of the volume being copied into the target thin pool.
+	movingSuffix = "-moving"
+	// movedSuffix is appended to the name of the replaced volume until it is removed.
+	movedSuffix = "-moved"
+)
+
+// moveJob is a volume being moved in the background by MoveLV.
+type moveJob struct {
+	target string
+	total  uint64
+	copied uint64
+	done   bool
+	err    error
+}
+
+func (s *lvService) MoveLV(ctx context.Context, req *proto.MoveLVRequest) (*proto.MoveLVResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+
+	dc, err := s.dcmapper.DeviceClass(req.GetDeviceClass())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+	}
+	targetDC, err := s.dcmapper.DeviceClass(req.GetTargetDeviceClass())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetTargetDeviceClass())
+	}
+	if targetDC.Type != lvmdTypes.TypeThin || targetDC.VolumeGroup != dc.VolumeGroup {
+		return nil, status.Errorf(codes.InvalidArgument, "target device class %s is not a thin device class of volume group %s",
+			targetDC.Name, dc.VolumeGroup)
+	}
+	if s.dcmapper.IsDraining(targetDC.Name) {
+		return nil, status.Errorf(codes.ResourceExhausted, "target device class %s is draining and accepts no new volumes", targetDC.Name)
+	}
+	wipe, err := wipePolicy(dc, req.GetWipeOnDelete())
+	if err != nil {
+		return nil, err
+	}
+
+	s.movesMu.Lock()
+	defer s.movesMu.Unlock()
+	if job, ok := s.moves[req.GetName()]; ok {
+		sameTarget := job.target == targetDC.ThinPoolConfig.Name
+		switch {
+		case !job.done && !sameTarget:
+			return nil, status.Errorf(codes.Aborted, "logical volume %s is being moved to thin pool %s", req.GetName(), job.target)
+		case !job.done:
+			return &proto.MoveLVResponse{ProgressPercent: job.progressPercent()}, nil
+		}
+		delete(s.moves, req.GetName())
+		if sameTarget {
+			if job.err != nil {
+				return nil, internalError(job.err)
+			}
+			return &proto.MoveLVResponse{Completed: true, ProgressPercent: 100}, nil
+		}
+		// the target has changed since the move was started, so the volume is moved again.
+	}
+
+	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	if err != nil {
+		return nil, err
+	}
+	lv, err := vg.FindVolume(ctx, req.GetName())
+	if errors.Is(err, command.ErrNotFound) {
+		lv, err = recoverSwap(ctx, vg, req.GetName(), wipe)
+	}
+	if errors.Is(err, command.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
+	}
+	if err != nil {
+		logger.Error(err, "failed to find volume")
+		return nil, internalError(err)
+	}
+	if !lv.IsThin() {
+		return nil, status.Errorf(codes.InvalidArgument, "logical volume %s is not thin", req.GetName())
+	}
+	source, err := lv.Pool(ctx)
+	if err != nil {
+		logger.Error(err, "failed to find thin pool of volume")
+		return nil, internalError(err)
+	}
+	if source.Name() == targetDC.ThinPoolConfig.Name {
+		// the volume has already been moved, e.g. before lvmd restarted, which may have left the replaced volume.
+		if err := finishSwap(ctx, vg, lv, wipe); err != nil {
+			logger.Error(err, "failed to remove replaced volume")
+			return nil, internalError(err)
+		}
+		return &proto.MoveLVResponse{Completed: true, ProgressPercent: 100}, nil
+	}
+	if lv.IsOpen() {
+		return nil, status.Errorf(codes.FailedPrecondition, "logical volume %s is in use", req.GetName())
+	}
+
+	pool, err := vg.FindPool(ctx, targetDC.ThinPoolConfig.Name)
+	if err != nil {
+		logger.Error(err, "failed to get thinpool")
+		return nil, internalError(err)
+	}
+	tpu, err := pool.Free(ctx)
+	if err != nil {
+		logger.Error(err, "failed to get free bytes")
+		return nil, internalError(err)
+	}
+	free, err := s.dcmapper.thinFreeBytes(ctx, targetDC, pool, tpu)
+	if err != nil {
+		logger.Error(err, "failed to get free bytes")
+		return nil, internalError(err)
+	}
+	dataFree := uint64(float64(tpu.SizeBytes) * (100 - tpu.DataPercent) / 100)
+	if free < lv.Size() || dataFree < lv.AllocatedBytes() {
+		return nil, status.Errorf(codes.ResourceExhausted, "no enough space left on thin pool %s: free=%d, data free=%d, requested=%d, allocated=%d",
+			pool.Name(), free, dataFree, lv.Size(), lv.AllocatedBytes())
+	}
+
+	job := &moveJob{target: pool.Name(), total: lv.Size()}
+	s.moves[req.GetName()] = job
+	// the move outlives the request.
+	moveCtx := log.IntoContext(context.Background(), logger.WithValues("target_pool", pool.Name()))
+	go func() {
+		err := s.moveVolume(moveCtx, job, vg, lv, pool, wipe)
+		if err != nil {
+			log.FromContext(moveCtx).Error(err, "failed to move volume")
+		} else {
+			log.FromContext(moveCtx).Info("moved volume")
+		}
+		s.movesMu.Lock()
+		job.done, job.err = true, err
+		s.movesMu.Unlock()
+		s.notify()
+	}()
+	logger.Info("started moving volume", "source_pool", source.Name(), "target_pool", pool.Name())
+	return &proto.MoveLVResponse{}, nil
+}
+
+func (j *moveJob) progressPercent() float64 {
+	if j.total == 0 {
+		return 0
+	}
+	return float64(j.copied) * 100 / float64(j.total)
+}
+
+// moveVolume copies lv to a new volume in pool, which then replaces lv with its name, tags and activation.
+// The replaced volume is wiped according to wipe and removed.
+func (s *lvService) moveVolume(ctx context.Context, job *moveJob, vg *command.VolumeGroup, lv *command.LogicalVolume,
+	pool *command.ThinPool, wipe lvmdTypes.WipePolicy) error {
+	name := lv.Name()
+	movingName := name + movingSuffix
+	movedName := name + movedSuffix
+
+	// remove the leftover of a move interrupted by a restart of lvmd.
+	if err := vg.RemoveVolume(ctx, movingName); err != nil && !errors.Is(err, command.ErrNotFound) {
+		return err
+	}
+
+	wasActive := lv.IsActive()
+	if !wasActive {
+		if err := lv.ActivateIgnoringSkip(ctx); err != nil {
+			return err
+		}
+	}
+	err := s.copyVolume(ctx, job, vg, lv, pool, movingName)
+	if err != nil {
+		if !wasActive {
+			if err2 := lv.Deactivate(ctx); err2 != nil {
+				log.FromContext(ctx).Error(err2, "failed to deactivate volume", "volume", name)
+			}
+		}
+		return err
+	}
+	target, err := vg.FindVolume(ctx, movingName)
+	if err != nil {
+		return err
+	}
+
+	// swap the names, so that the copy is found as the volume from now on.
+	if err := lv.Rename(ctx, movedName); err != nil {
+		return err
+	}
+	if err := target.Rename(ctx, name); err != nil {
+		if err2 := lv.Rename(ctx, name); err2 != nil {
+			log.FromContext(ctx).Error(err2, "failed to restore name of volume", "volume", movedName)
+		}
+		return err
+	}
+	s.notify()
+
+	if err := inheritVolume(ctx, target, lv); err != nil {
+		return err
+	}
+	if !wasActive {
+		if err := target.Deactivate(ctx); err != nil {
+			return err
+		}
+	}
+
+	return removeReplacedVolume(ctx, vg, movedName, wipe)
+}
+
+// copyVolume copies the active volume lv to a new volume named copyName in pool.
+// The incomplete copy is removed if the copy fails.
+func (s *lvService) copyVolume(ctx context.Context, job *moveJob, vg *command.VolumeGroup, lv *command.LogicalVolume,
+	pool *command.ThinPool, copyName string) error {
+	if err := pool.CreateVolume(ctx, copyName, lv.Size(), nil, 0, "", nil); err != nil {
+		return err
+	}
+	target, err := vg.FindVolume(ctx, copyName)
+	if err == nil {
+		err = lv.CopyTo(ctx, target, func(copied uint64) {
+			s.movesMu.Lock()
+			job.copied = copied
+			s.movesMu.Unlock()
+		})
+	}
+	if err != nil {
+		if err2 := vg.RemoveVolume(ctx, copyName); err2 != nil {
+			log.FromContext(ctx).Error(err2, "failed to remove incomplete copy", "copy", copyName)
+		}
+		return err
+	}
+	return nil
+}
+
+// inheritVolume gives the copy of a volume the tags and the activation skip flag of the volume it replaces.
+func inheritVolume(ctx context.Context, target, replaced *command.LogicalVolume) error {
+	if err := target.ChangeTags(ctx, replaced.Tags(), nil); err != nil {
+		return err
+	}
+	if replaced.HasActivationSkip() && !target.HasActivationSkip() {
+		if err := target.SetActivationSkip(ctx, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recoverSwap restores the volume name when lvmd stopped between the renames of moveVolume, which left
+// only the replaced volume and possibly its copy. The copy is complete once the volume is renamed,
+// so the copy takes the name and the swap is finished if it exists; otherwise the replaced volume gets its name back.
+// ErrNotFound is returned if there is no replaced volume either.
+func recoverSwap(ctx context.Context, vg *command.VolumeGroup, name string, wipe lvmdTypes.WipePolicy) (*command.LogicalVolume, error) {
+	replaced, err := vg.FindVolume(ctx, name+movedSuffix)
+	if err != nil {
+		return nil, err
+	}
+	recovered := replaced
+	copied, err := vg.FindVolume(ctx, name+movingSuffix)
+	switch {
+	case err == nil:
+		recovered = copied
+	case !errors.Is(err, command.ErrNotFound):
+		return nil, err
+	}
+	log.FromContext(ctx).Info("recovering volume from interrupted move", "volume", recovered.Name())
+	if err := recovered.Rename(ctx, name); err != nil {
+		return nil, err
+	}
+	if recovered == copied {
+		if err := finishSwap(ctx, vg, copied, wipe); err != nil {
+			return nil, err
+		}
+	}
+	return vg.FindVolume(ctx, name)
+}
+
+// finishSwap completes a move whose copy has already taken the name of the volume, by making the copy
+// inherit the replaced volume and removing it. It does nothing if the replaced volume is already removed.
+// The activation of the copy is left as it is.
+func finishSwap(ctx context.Context, vg *command.VolumeGroup, lv *command.LogicalVolume, wipe lvmdTypes.WipePolicy) error {
+	replaced, err := vg.FindVolume(ctx, lv.Name()+movedSuffix)
+	if errors.Is(err, command.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := inheritVolume(ctx, lv, replaced); err != nil {
+		return err
+	}
+	return removeReplacedVolume(ctx, vg, replaced.Name(), wipe)
+}
+
+// removeReplacedVolume wipes the volume replaced by its copy according to wipe and removes it.
+func removeReplacedVolume(ctx context.Context, vg *command.VolumeGroup, name string, wipe lvmdTypes.WipePolicy) error {
+	if wipe == lvmdTypes.WipeDiscard || wipe == lvmdTypes.WipeZero {
+		if err := wipeVolume(ctx, vg, name, wipe == lvmdTypes.WipeZero); err != nil {
+			return err
+		}
+	}
+	return vg.RemoveVolume(ctx, name)
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
+	"path/filepath"
+	"sync"
 
 	"github.com/topolvm/topolvm/internal/lvmd/command"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
@@ -20,6 +21,9 @@ func NewLVService(dcmapper *DeviceClassManager, ocmapper *LvcreateOptionClassMan
 		dcmapper:   dcmapper,
 		ocmapper:   ocmapper,
 		notifyFunc: notifyFunc,
+		moves:      make(map[string]*moveJob),
+		roundRobin: make(map[string]int),
+		quotaLocks: make(map[string]*sync.Mutex),
 	}
 }
 
@@ -28,6 +32,18 @@ type lvService struct {
 	dcmapper   *DeviceClassManager
 	ocmapper   *LvcreateOptionClassManager
 	notifyFunc func()
+
+	// movesMu guards moves, the volumes being moved by MoveLV.
+	movesMu sync.Mutex
+	moves   map[string]*moveJob
+
+	// roundRobinMu guards roundRobin, the next volume group of the device-classes with the round-robin policy.
+	roundRobinMu sync.Mutex
+	roundRobin   map[string]int
+
+	// quotaMu guards quotaLocks, the locks of the device-classes with max-volumes.
+	quotaMu    sync.Mutex
+	quotaLocks map[string]*sync.Mutex
 }
 
 func (s *lvService) notify() {
@@ -38,16 +54,12 @@ func (s *lvService) notify() {
 
 func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*proto.CreateLVResponse, error) {
 	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	ctx = command.WithWarnings(ctx)
 
 	dc, err := s.dcmapper.DeviceClass(req.DeviceClass)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
-	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
-	if err != nil {
-		return nil, err
-	}
-	oc := s.ocmapper.LvcreateOptionClass(req.LvcreateOptionClass)
 
 	var requested uint64
 	if req.SizeBytes > 0 {
@@ -59,6 +71,47 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 		requested = req.GetSizeGb() << 30
 	}
 
+	if err := s.checkDraining(ctx, dc, req.GetName()); err != nil {
+		return nil, err
+	}
+	// the quotas apply to the device-class requested even if the type is overridden.
+	if err := checkMaxSize(dc, requested); err != nil {
+		return nil, err
+	}
+	unlock, err := s.checkVolumeQuota(ctx, dc, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	requestedDC := dc
+	dc, err = s.dcmapper.ProvisioningDeviceClass(dc, lvmdTypes.DeviceType(req.GetProvisioningType()))
+	if errors.Is(err, ErrInvalidProvisioningType) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	tags := req.GetTags()
+	if dc != requestedDC {
+		tags = overrideTags(requestedDC, tags)
+	} else if dc.Type == lvmdTypes.TypeThin {
+		tags = s.dcmapper.thinVolumeTags(dc, tags)
+	}
+	if dc.Type == lvmdTypes.TypeRaw {
+		return s.createRawLV(ctx, dc, req)
+	}
+	oc := s.ocmapper.LvcreateOptionClass(req.LvcreateOptionClass)
+	if len(req.GetLvcreateOptions()) > 0 {
+		if err := s.ocmapper.ValidateInlineOptions(req.LvcreateOptionClass, req.GetLvcreateOptions()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	vg, err := s.volumeGroupForNewVolume(ctx, dc, req.GetName(), requested)
+	if err != nil {
+		return nil, err
+	}
+
 	free := uint64(0)
 	var pool *command.ThinPool
 	switch dc.Type {
@@ -66,36 +119,82 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 		free, err = vg.Free()
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
+		}
+		if oc == nil {
+			// RAID volumes need space for the redundancy in addition to the requested size.
+			free = GetUsableBytes(dc, free)
 		}
 	case lvmdTypes.TypeThin:
 		pool, err = vg.FindPool(ctx, dc.ThinPoolConfig.Name)
 		if err != nil {
 			logger.Error(err, "failed to get thinpool")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
 		}
 		tpu, err := pool.Free(ctx)
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
+		}
+		free, err = s.dcmapper.thinFreeBytes(ctx, dc, pool, tpu)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
 		}
-		free = uint64(math.Floor(dc.ThinPoolConfig.OverprovisionRatio*float64(tpu.SizeBytes))) - tpu.VirtualBytes
 	default:
 		// technically this block will not be hit however make sure we return error
 		// in such cases where deviceclass target is neither thick or thinpool
 		return nil, status.Error(codes.Internal, fmt.Sprintf("unsupported device class target: %s", dc.Type))
 	}
 
-	if free < requested {
-		logger.Error(err, "not enough space left on VG", "free", free, "requested", requested)
-		return nil, status.Errorf(codes.ResourceExhausted, "no enough space left on VG: free=%d, requested=%d", free, requested)
+	var cacheSize uint64
+	var vdo *command.VDOOptions
+	allocated := requested
+	if dc.Type == lvmdTypes.TypeThick {
+		cacheSize = GetCacheBytes(dc, requested)
+		if oc == nil {
+			// VDO volumes allocate their VDO pool instead of the requested virtual size.
+			if vdo = GetVDOOptions(dc, requested); vdo != nil {
+				allocated = vdo.PhysicalSize
+			}
+		}
+	}
+
+	space := &proto.InsufficientSpace{
+		DeviceClass:    dc.Name,
+		VolumeGroup:    vg.Name(),
+		RequestedBytes: allocated + cacheSize,
+		FreeBytes:      free,
+	}
+	if pool != nil {
+		space.ThinPool = pool.Name()
+	}
+	if free < allocated+cacheSize {
+		logger.Error(err, "not enough space left on VG", "free", free, "requested", allocated, "cache", cacheSize)
+		return nil, insufficientSpaceError(space)
+	}
+	var devices []string
+	if cacheSize != 0 {
+		var cacheFree, originFree uint64
+		devices, cacheFree, originFree, err = splitCacheDevice(ctx, vg, dc.Cache.Device)
+		if err != nil {
+			logger.Error(err, "failed to list physical volumes")
+			return nil, internalError(err)
+		}
+		if cacheFree < cacheSize || originFree < allocated {
+			logger.Error(nil, "not enough space left on physical volumes",
+				"free", originFree, "requested", allocated, "cache_free", cacheFree, "cache", cacheSize)
+			return nil, insufficientSpaceError(space)
+		}
 	}
 
 	var stripe uint
 	var stripeSize string
+	var raid *command.RAIDOptions
 	var lvcreateOptions []string
 	if oc != nil {
-		lvcreateOptions = oc.Options
+		// copy the options of the class not to append the inline options to the configuration.
+		lvcreateOptions = append(append([]string{}, oc.Options...), req.GetLvcreateOptions()...)
 	} else if req.LvcreateOptionClass != "" {
 		return nil, status.Error(codes.Internal, fmt.Sprintf("unsupported lvcreate-option-class target: %s", req.LvcreateOptionClass))
 	} else {
@@ -103,16 +202,24 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 		if dc.Stripe != nil {
 			stripe = *dc.Stripe
 		}
+		if dc.RAID != nil {
+			raid = &command.RAIDOptions{
+				Type:    string(dc.RAID.Type),
+				Mirrors: raidMirrors(dc),
+			}
+		}
 		if dc.LVCreateOptions != nil {
 			lvcreateOptions = dc.LVCreateOptions
 		}
 	}
 
-	switch dc.Type {
-	case lvmdTypes.TypeThick:
-		err = vg.CreateVolume(ctx, req.GetName(), requested, req.GetTags(), stripe, stripeSize, lvcreateOptions)
-	case lvmdTypes.TypeThin:
-		err = pool.CreateVolume(ctx, req.GetName(), requested, req.GetTags(), stripe, stripeSize, lvcreateOptions)
+	switch {
+	case vdo != nil:
+		err = vg.CreateVDOVolume(ctx, req.GetName(), requested, tags, *vdo, lvcreateOptions)
+	case dc.Type == lvmdTypes.TypeThick:
+		err = vg.CreateVolume(ctx, req.GetName(), requested, tags, stripe, stripeSize, raid, lvcreateOptions, devices)
+	case dc.Type == lvmdTypes.TypeThin:
+		err = pool.CreateVolume(ctx, req.GetName(), requested, tags, stripe, stripeSize, lvcreateOptions)
 	default:
 		return nil, status.Error(codes.Internal, fmt.Sprintf("unsupported device class target: %s", dc.Type))
 	}
@@ -120,7 +227,13 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 		logger.Error(err, "failed to create volume",
 			"requested", requested,
 			"tags", req.GetTags())
-		return nil, status.Error(codes.Internal, err.Error())
+		if errors.Is(err, command.ErrInsufficientFreeSpace) {
+			// lvm needs more space than estimated, e.g. for the metadata of RAID volumes,
+			// so the capacity is reported to the watchers again as it has been overestimated.
+			s.notify()
+			return nil, insufficientSpaceError(space)
+		}
+		return nil, internalError(err)
 	}
 
 	lv, err := vg.FindVolume(ctx, req.GetName())
@@ -129,7 +242,36 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 		logger.Error(err, "failed to find volume",
 			"requested", requested,
 			"tags", req.GetTags())
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
+	}
+
+	if cacheSize != 0 {
+		if err := lv.AttachCache(ctx, cacheSize, string(dc.Cache.Mode), []string{dc.Cache.Device}); err != nil {
+			logger.Error(err, "failed to attach cache", "cache", cacheSize, "device", dc.Cache.Device)
+			// remove the volume so that the creation can be retried from scratch.
+			if rmErr := vg.RemoveVolume(ctx, lv.Name()); rmErr != nil {
+				logger.Error(rmErr, "failed to remove volume after failing to attach cache")
+			}
+			return nil, internalError(err)
+		}
+	}
+
+	if dc.ActivationSkip {
+		// the volume is created active to be wiped, and is kept inactive until it is staged.
+		err := lv.SetActivationSkip(ctx, true)
+		if err == nil {
+			err = lv.Deactivate(ctx)
+		}
+		if err == nil {
+			lv, err = vg.FindVolume(ctx, req.GetName())
+		}
+		if err != nil {
+			logger.Error(err, "failed to deactivate volume")
+			if rmErr := vg.RemoveVolume(ctx, req.GetName()); rmErr != nil {
+				logger.Error(rmErr, "failed to remove volume after failing to deactivate it")
+			}
+			return nil, internalError(err)
+		}
 	}
 
 	s.notify()
@@ -147,6 +289,7 @@ func (s *lvService) CreateLV(ctx context.Context, req *proto.CreateLVRequest) (*
 			DevMajor:  lv.MajorNumber(),
 			DevMinor:  lv.MinorNumber(),
 		},
+		Warnings: warningsFromContext(ctx),
 	}, nil
 }
 
@@ -157,15 +300,39 @@ func (s *lvService) RemoveLV(ctx context.Context, req *proto.RemoveLVRequest) (*
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
+	if dc.Type == lvmdTypes.TypeRaw {
+		return s.removeRawLV(ctx, dc, req)
+	}
 
-	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	vg, err := findVolumeGroupOf(ctx, dc, req.GetName())
 	if errors.Is(err, command.ErrNotFound) {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	} else if err != nil {
-		logger.Error(err, "failed to get volume group", "name", dc.VolumeGroup)
+		logger.Error(err, "failed to get volume group", "names", VolumeGroupNames(dc))
 		return nil, err
 	}
 
+	// lvm removes the thick snapshots together with their origin, which may be in use as volumes.
+	snapshots, err := vg.ListCOWSnapshots(ctx, req.GetName())
+	if err != nil {
+		logger.Error(err, "failed to list snapshots", "name", req.GetName())
+		return nil, goneError(err)
+	}
+	if len(snapshots) != 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "logical volume %s has %d thick snapshot(s), remove them first", req.GetName(), len(snapshots))
+	}
+
+	wipe, err := wipePolicy(dc, req.GetWipeOnDelete())
+	if err != nil {
+		return nil, err
+	}
+	if wipe == lvmdTypes.WipeDiscard || wipe == lvmdTypes.WipeZero {
+		if err := wipeVolume(ctx, vg, req.GetName(), wipe == lvmdTypes.WipeZero); err != nil {
+			logger.Error(err, "failed to wipe volume", "name", req.GetName(), "wipe", wipe)
+			return nil, internalError(err)
+		}
+	}
+
 	if err := vg.RemoveVolume(ctx, req.GetName()); errors.Is(err, command.ErrNotFound) {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	} else if err != nil {
@@ -180,8 +347,176 @@ func (s *lvService) RemoveLV(ctx context.Context, req *proto.RemoveLVRequest) (*
 	return &proto.Empty{}, nil
 }
 
+// wipePolicy returns the wipe-on-delete of the device class, or override if it is not empty.
+func wipePolicy(dc *lvmdTypes.DeviceClass, override string) (lvmdTypes.WipePolicy, error) {
+	wipe := dc.WipeOnDelete
+	if override != "" {
+		wipe = lvmdTypes.WipePolicy(override)
+	}
+	switch wipe {
+	case "", lvmdTypes.WipeNone, lvmdTypes.WipeDiscard, lvmdTypes.WipeZero:
+		return wipe, nil
+	}
+	return "", status.Errorf(codes.InvalidArgument, "invalid wipe-on-delete: %s", wipe)
+}
+
+// wipeVolume discards or zeroes the volume before it is removed, activating it if needed.
+// The copy-on-write space of thick snapshots is not wiped, as writing to it changes the snapshot itself.
+func wipeVolume(ctx context.Context, vg *command.VolumeGroup, name string, zero bool) error {
+	lv, err := vg.FindVolume(ctx, name)
+	if errors.Is(err, command.ErrNotFound) {
+		// lvremove reports the volume as not found.
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if lv.IsSnapshot() && !lv.IsThin() {
+		return nil
+	}
+	if !lv.IsActive() {
+		if err := lv.ActivateIgnoringSkip(ctx); err != nil {
+			return err
+		}
+	}
+	return lv.Discard(ctx, zero)
+}
+
+func (s *lvService) ChangeLVTags(ctx context.Context, req *proto.ChangeLVTagsRequest) (*proto.ChangeLVTagsResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+
+	if len(req.GetAddTags()) == 0 && len(req.GetRemoveTags()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "tags to add or remove are required")
+	}
+	removed := make(map[string]bool, len(req.GetRemoveTags()))
+	for _, tag := range req.GetRemoveTags() {
+		removed[tag] = true
+	}
+	for _, tag := range req.GetAddTags() {
+		if removed[tag] {
+			return nil, status.Errorf(codes.InvalidArgument, "tag %s is both added and removed", tag)
+		}
+	}
+	if dc, err := s.dcmapper.DeviceClass(req.GetDeviceClass()); err == nil && dc.Type == lvmdTypes.TypeRaw {
+		return s.changeRawLVTags(ctx, dc, req)
+	}
+
+	lv, err := s.findVolume(ctx, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := lv.ChangeTags(ctx, req.GetAddTags(), req.GetRemoveTags()); err != nil {
+		logger.Error(err, "failed to change tags", "add", req.GetAddTags(), "remove", req.GetRemoveTags())
+		return nil, internalError(err)
+	}
+
+	logger.Info("changed tags of a LV", "add", req.GetAddTags(), "remove", req.GetRemoveTags(), "tags", lv.Tags())
+	return &proto.ChangeLVTagsResponse{Tags: lv.Tags()}, nil
+}
+
+func (s *lvService) ActivateLV(ctx context.Context, req *proto.ActivateLVRequest) (*proto.ActivateLVResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+
+	// the devices of raw volumes are always active unless they are missing.
+	if dc, err := s.dcmapper.DeviceClass(req.GetDeviceClass()); err == nil && dc.Type == lvmdTypes.TypeRaw {
+		lv, err := findRawVolume(ctx, dc, req.GetName())
+		if err != nil {
+			return nil, err
+		}
+		if lv.GetHealthError() != "" {
+			return nil, status.Error(codes.FailedPrecondition, lv.GetHealthError())
+		}
+		return &proto.ActivateLVResponse{Volume: lv}, nil
+	}
+
+	lv, err := s.findVolume(ctx, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	if !lv.IsActive() {
+		if err := lv.ActivateIgnoringSkip(ctx); err != nil {
+			logger.Error(err, "failed to activate volume")
+			return nil, internalError(err)
+		}
+		// find the volume again for the device numbers assigned by the activation.
+		lv, err = lv.VG().FindVolume(ctx, req.GetName())
+		if err != nil {
+			logger.Error(err, "failed to find volume")
+			return nil, internalError(err)
+		}
+		logger.Info("activated a LV")
+	}
+
+	return &proto.ActivateLVResponse{
+		Volume: &proto.LogicalVolume{
+			Name:           lv.Name(),
+			SizeGb:         (lv.Size() + (1 << 30) - 1) >> 30,
+			SizeBytes:      int64(lv.Size()),
+			DevMajor:       lv.MajorNumber(),
+			DevMinor:       lv.MinorNumber(),
+			Tags:           lv.Tags(),
+			Attr:           lv.Attr().String(),
+			CopyPercent:    lv.CopyPercent(),
+			AllocatedBytes: lv.AllocatedBytes(),
+		},
+	}, nil
+}
+
+func (s *lvService) DeactivateLV(ctx context.Context, req *proto.DeactivateLVRequest) (*proto.Empty, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+
+	if dc, err := s.dcmapper.DeviceClass(req.GetDeviceClass()); err == nil && dc.Type == lvmdTypes.TypeRaw {
+		if _, err := findRawVolume(ctx, dc, req.GetName()); err != nil {
+			return nil, err
+		}
+		return &proto.Empty{}, nil
+	}
+
+	lv, err := s.findVolume(ctx, req.GetDeviceClass(), req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if !lv.IsActive() {
+		return &proto.Empty{}, nil
+	}
+	if lv.IsOpen() {
+		return nil, status.Errorf(codes.FailedPrecondition, "logical volume %s is in use", req.GetName())
+	}
+
+	if err := lv.Deactivate(ctx); err != nil {
+		logger.Error(err, "failed to deactivate volume")
+		return nil, internalError(err)
+	}
+
+	logger.Info("deactivated a LV")
+	return &proto.Empty{}, nil
+}
+
+// findVolume finds the logical volume name in the volume group of the device class.
+func (s *lvService) findVolume(ctx context.Context, deviceClass, name string) (*command.LogicalVolume, error) {
+	dc, err := s.dcmapper.DeviceClass(deviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), deviceClass)
+	}
+	vg, err := findVolumeGroupOf(ctx, dc, name)
+	if err != nil {
+		return nil, err
+	}
+	lv, err := vg.FindVolume(ctx, name)
+	if errors.Is(err, command.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", name)
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to find volume", "name", name)
+		return nil, internalError(err)
+	}
+	return lv, nil
+}
+
 func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSnapshotRequest) (*proto.CreateLVSnapshotResponse, error) {
 	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	ctx = command.WithWarnings(ctx)
 
 	var snapType string
 	dc, err := s.dcmapper.DeviceClass(req.DeviceClass)
@@ -190,21 +525,17 @@ func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSna
 	}
 
 	switch dc.Type {
-	case lvmdTypes.TypeThin:
-		snapType = "thin-snapshot"
-	case lvmdTypes.TypeThick:
-		return nil, status.Error(codes.Unimplemented, "device class is not thin. Thick snapshots are not implemented yet")
+	case lvmdTypes.TypeThin, lvmdTypes.TypeThick:
 	default:
 		return nil, status.Errorf(codes.InvalidArgument, "invalid device class type %v", string(dc.Type))
 	}
 
-	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	// Fetch the source logical volume
+	sourceVolume := req.GetSourceVolume()
+	vg, err := findVolumeGroupOf(ctx, dc, sourceVolume)
 	if err != nil {
 		return nil, err
 	}
-
-	// Fetch the source logical volume
-	sourceVolume := req.GetSourceVolume()
 	sourceLV, err := vg.FindVolume(ctx, sourceVolume)
 	if errors.Is(err, command.ErrNotFound) {
 		logger.Error(err, "source logical volume is not found", "sourceVolume", sourceVolume)
@@ -212,11 +543,40 @@ func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSna
 	}
 	if err != nil {
 		logger.Error(err, "failed to find source volume", "sourceVolume", sourceVolume)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
 	}
 
+	// the source volume may have been provisioned with the type overriding that of the device-class,
+	// so the type of the snapshot follows the source volume.
+	snapType = "thin-snapshot"
 	if !sourceLV.IsThin() {
-		return nil, status.Error(codes.Unimplemented, "snapshot can be created for only thin volumes")
+		snapType = "thick-snapshot"
+		if sourceLV.IsSnapshot() {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot take snapshot of thick snapshot %s", sourceVolume)
+		}
+	}
+
+	tags := req.GetTags()
+	// the snapshots of a volume overriding the type belong to the same device-class as the volume.
+	if owner := s.dcmapper.overridingDeviceClass(sourceLV); owner != nil {
+		tags = overrideTags(owner, tags)
+	}
+	thinTags := tags
+	var sharedDC *lvmdTypes.DeviceClass
+	var sharedPool *command.ThinPool
+	if snapType == "thin-snapshot" {
+		if err := s.checkSnapshotDepth(ctx, dc, sourceLV); err != nil {
+			return nil, err
+		}
+		// snapshots in a shared thin pool count towards the quota of the device-class of the source.
+		sharedDC, sharedPool, err = s.dcmapper.thinSnapshotDeviceClass(ctx, sourceLV)
+		if err != nil {
+			logger.Error(err, "failed to get thinpool of source volume")
+			return nil, internalError(err)
+		}
+		if sharedDC != nil {
+			thinTags = s.dcmapper.thinVolumeTags(sharedDC, thinTags)
+		}
 	}
 
 	// In case of thin-snapshots, the size is the same as the source volume on snapshot creation, and then
@@ -242,30 +602,84 @@ func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSna
 		return nil, status.Errorf(codes.OutOfRange, "requested size %v is smaller than source logical volume: %v", desiredSize, sizeOnCreation)
 	}
 
+	var cowSize uint64
+	if snapType == "thick-snapshot" {
+		// thick snapshots always have the size of the source, resizing them changes the copy-on-write space.
+		if desiredSize != sizeOnCreation {
+			return nil, status.Errorf(codes.OutOfRange, "requested size %v is different from source logical volume: %v, thick snapshots cannot be resized", desiredSize, sizeOnCreation)
+		}
+		if dc.Type == lvmdTypes.TypeThin {
+			dc, err = s.dcmapper.ProvisioningDeviceClass(dc, lvmdTypes.TypeThick)
+			if err != nil {
+				return nil, internalError(err)
+			}
+		}
+		cowSize = GetSnapshotCOWBytes(dc, sizeOnCreation)
+		free, err := vg.Free()
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
+		}
+		if free < cowSize {
+			logger.Error(err, "not enough space left on VG", "free", free, "cowSize", cowSize)
+			return nil, insufficientSpaceError(&proto.InsufficientSpace{
+				DeviceClass:    dc.Name,
+				VolumeGroup:    vg.Name(),
+				RequestedBytes: cowSize,
+				FreeBytes:      free,
+			})
+		}
+	}
+
+	if sharedDC != nil {
+		tpu, err := sharedPool.Free(ctx)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
+		}
+		free, err := s.dcmapper.thinFreeBytes(ctx, sharedDC, sharedPool, tpu)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
+		}
+		if free < desiredSize {
+			logger.Error(err, "not enough space left in the quota of the device-class", "free", free, "deviceClass", sharedDC.Name)
+			return nil, status.Errorf(codes.ResourceExhausted, "no enough space left in the quota of device-class %s: free=%d, requested=%d", sharedDC.Name, free, desiredSize)
+		}
+	}
+
 	logger.Info(
 		"lvservice req",
 		"sizeOnCreation", sizeOnCreation,
 		"desiredSize", desiredSize,
 		"sourceVol", sourceVolume,
 		"snapType", snapType,
+		"cowSize", cowSize,
 		"accessType", req.AccessType,
 	)
 	// Create snapshot lv
-
-	if err := sourceLV.ThinSnapshot(ctx, req.GetName(), req.GetTags()); err != nil {
+	// the pending tag is removed once the snapshot is resized and activated, otherwise RecoverSnapshots
+	// completes or rolls back the creation on the next start.
+	pendingTag := pendingSnapshotTag(desiredSize, req.AccessType)
+	if snapType == "thick-snapshot" {
+		err = sourceLV.Snapshot(ctx, req.GetName(), cowSize, append([]string{pendingTag}, tags...))
+	} else {
+		err = sourceLV.ThinSnapshot(ctx, req.GetName(), append([]string{pendingTag}, thinTags...))
+	}
+	if err != nil {
 		logger.Error(err, "failed to create snapshot volume")
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
 	}
 
 	snapLV, err := vg.FindVolume(ctx, req.GetName())
 	if err != nil {
 		logger.Error(err, "failed to get snapshot after creation")
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
 	}
 
 	if err := snapLV.Resize(ctx, desiredSize); err != nil {
 		logger.Error(err, "failed to resize snapshot volume")
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
 	}
 
 	// If source volume is thin, activate the thin snapshot lv with accessmode.
@@ -276,7 +690,11 @@ func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSna
 		} else {
 			logger.Info("deleted a snapshot")
 		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
+	}
+	if err := snapLV.ChangeTags(ctx, nil, []string{pendingTag}); err != nil {
+		logger.Error(err, "failed to remove the pending tag of snapshot volume")
+		return nil, internalError(err)
 	}
 
 	s.notify()
@@ -299,30 +717,294 @@ func (s *lvService) CreateLVSnapshot(ctx context.Context, req *proto.CreateLVSna
 			DevMajor:  snapLV.MajorNumber(),
 			DevMinor:  snapLV.MinorNumber(),
 		},
+		Warnings: warningsFromContext(ctx),
 	}, nil
 }
 
-func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*proto.Empty, error) {
-	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+// checkSnapshotDepth returns FailedPrecondition if a thin snapshot of sourceLV exceeds the max-snapshot-depth
+// of the thin pool. The depth of the snapshot is the length of its origin chain.
+func (s *lvService) checkSnapshotDepth(ctx context.Context, dc *lvmdTypes.DeviceClass, sourceLV *command.LogicalVolume) error {
+	if dc.Type != lvmdTypes.TypeThin {
+		thinDC, err := s.dcmapper.ProvisioningDeviceClass(dc, lvmdTypes.TypeThin)
+		if err != nil {
+			return internalError(err)
+		}
+		dc = thinDC
+	}
+	if dc.ThinPoolConfig == nil || dc.ThinPoolConfig.MaxSnapshotDepth == 0 {
+		return nil
+	}
+
+	chain, err := sourceLV.OriginChain(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to get origin chain", "sourceVolume", sourceLV.Name())
+		return internalError(err)
+	}
+	if depth := uint(len(chain)) + 1; depth > dc.ThinPoolConfig.MaxSnapshotDepth {
+		return status.Errorf(codes.FailedPrecondition, "snapshot of %s would have depth %d exceeding max-snapshot-depth %d",
+			sourceLV.Name(), depth, dc.ThinPoolConfig.MaxSnapshotDepth)
+	}
+	return nil
+}
+
+func (s *lvService) CreateDeviceClassSnapshot(ctx context.Context, req *proto.CreateDeviceClassSnapshotRequest) (*proto.CreateDeviceClassSnapshotResponse, error) {
+	logger := log.FromContext(ctx).WithValues("deviceClass", req.GetDeviceClass(), "nameSuffix", req.GetNameSuffix())
+	ctx = command.WithWarnings(ctx)
 
+	if req.GetNameSuffix() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name suffix is required")
+	}
 	dc, err := s.dcmapper.DeviceClass(req.DeviceClass)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
+	if dc.Type != lvmdTypes.TypeThin {
+		return nil, status.Errorf(codes.InvalidArgument, "device class %s is not thin-provisioned", dc.Name)
+	}
+
 	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
 	if err != nil {
 		return nil, err
 	}
-	// FindVolume on VolumeGroup or ThinPool returns ThinLogicalVolumes as well
-	// and no special handling for resize of LogicalVolume is needed
-	lv, err := vg.FindVolume(ctx, req.GetName())
+	pool, err := vg.FindPool(ctx, dc.ThinPoolConfig.Name)
+	if err != nil {
+		logger.Error(err, "failed to find thin pool", "pool", dc.ThinPoolConfig.Name)
+		return nil, internalError(err)
+	}
+	volumes, err := pool.ListVolumes(ctx)
+	if err != nil {
+		logger.Error(err, "failed to list volumes")
+		return nil, internalError(err)
+	}
+
+	snapshots := make(map[string]string, len(volumes))
+	for name, volume := range volumes {
+		if volume.IsSnapshot() || s.dcmapper.overridingDeviceClass(volume) != nil || !s.dcmapper.ownsThinVolume(dc, volume) {
+			continue
+		}
+		snapshots[name] = name + "-" + req.GetNameSuffix()
+	}
+	for _, snapshot := range snapshots {
+		if _, ok := volumes[snapshot]; ok {
+			return nil, status.Errorf(codes.AlreadyExists, "snapshot %s already exists", snapshot)
+		}
+	}
+
+	logger.Info("snapshotting all volumes of the device class", "volumes", len(snapshots))
+	if err := pool.SnapshotVolumes(ctx, snapshots, s.dcmapper.thinVolumeTags(dc, req.GetTags())); err != nil {
+		logger.Error(err, "failed to snapshot volumes of the device class")
+		// the snapshots taken so far do not share the point in time with the rest, so they are removed.
+		for _, snapshot := range snapshots {
+			if err := vg.RemoveVolume(ctx, snapshot); err != nil && !errors.Is(err, command.ErrNotFound) {
+				logger.Error(err, "failed to delete snapshot after snapshotting failed", "snapshot", snapshot)
+			}
+		}
+		return nil, internalError(err)
+	}
+
+	s.notify()
+
+	logger.Info("snapshotted all volumes of the device class", "volumes", len(snapshots))
+	return &proto.CreateDeviceClassSnapshotResponse{
+		Snapshots: snapshots,
+		Warnings:  warningsFromContext(ctx),
+	}, nil
+}
+
+func (s *lvService) MergeLVSnapshot(ctx context.Context, req *proto.MergeLVSnapshotRequest) (*proto.MergeLVSnapshotResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	ctx = command.WithWarnings(ctx)
+
+	dc, err := s.dcmapper.DeviceClass(req.DeviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
+	}
+	if dc.Type == lvmdTypes.TypeRaw {
+		return nil, status.Errorf(codes.InvalidArgument, "snapshots are not supported for raw device class %s", dc.Name)
+	}
+	vg, err := findVolumeGroupOf(ctx, dc, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	snapLV, err := vg.FindVolume(ctx, req.GetName())
 	if errors.Is(err, command.ErrNotFound) {
 		logger.Error(err, "logical volume is not found")
 		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
 	}
 	if err != nil {
 		logger.Error(err, "failed to find volume")
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, goneError(err)
+	}
+
+	if !snapLV.IsMerging() {
+		if !snapLV.IsSnapshot() {
+			return nil, status.Errorf(codes.InvalidArgument, "logical volume %s is not a snapshot", req.GetName())
+		}
+		origin, err := snapLV.Origin(ctx)
+		if err != nil {
+			logger.Error(err, "failed to find origin of snapshot")
+			return nil, goneError(err)
+		}
+		// the volumes are deactivated for the merge, which fails or is deferred while they are in use.
+		if origin.IsOpen() {
+			return nil, status.Errorf(codes.FailedPrecondition, "origin %s of snapshot %s is in use", origin.Name(), req.GetName())
+		}
+		if snapLV.IsOpen() {
+			return nil, status.Errorf(codes.FailedPrecondition, "snapshot %s is in use", req.GetName())
+		}
+
+		if err := snapLV.Merge(ctx); err != nil {
+			logger.Error(err, "failed to merge snapshot")
+			return nil, goneError(err)
+		}
+		s.notify()
+		logger.Info("started merging snapshot", "origin", origin.Name())
+
+		snapLV, err = vg.FindVolume(ctx, req.GetName())
+		if errors.Is(err, command.ErrNotFound) {
+			// thin snapshots are merged as soon as the origin is activated.
+			logger.Info("merged snapshot")
+			return &proto.MergeLVSnapshotResponse{Completed: true, ProgressPercent: 100, Warnings: warningsFromContext(ctx)}, nil
+		}
+		if err != nil {
+			logger.Error(err, "failed to find volume")
+			return nil, goneError(err)
+		}
+	}
+
+	var progress float64
+	if !snapLV.IsThin() {
+		// the copy-on-write space of thick snapshots is emptied as the merge proceeds.
+		progress = 100 - snapLV.SnapPercent()
+	}
+	return &proto.MergeLVSnapshotResponse{ProgressPercent: progress, Warnings: warningsFromContext(ctx)}, nil
+}
+
+// shrinkLV shrinks lv and its filesystem to requested if the device-class allows it.
+// The volume must not be in use since the filesystem is checked and shrunk offline.
+func (s *lvService) shrinkLV(ctx context.Context, dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume, requested uint64) (*proto.ResizeLVResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", lv.Name())
+	if !dc.AllowShrink {
+		return nil, status.Errorf(codes.FailedPrecondition, "shrinking volumes is not allowed in device-class %s", dc.Name)
+	}
+	if lv.IsOpen() {
+		return nil, status.Errorf(codes.FailedPrecondition, "volume %s is in use and cannot be shrunk", lv.Name())
+	}
+
+	current := lv.Size()
+	if err := lv.Shrink(ctx, requested); err != nil {
+		logger.Error(err, "failed to shrink LV",
+			"requested", requested,
+			"current", current,
+		)
+		return nil, internalError(err)
+	}
+	s.notify()
+
+	logger.Info("shrunk a LV", "size", requested, "original", current)
+
+	return &proto.ResizeLVResponse{Warnings: warningsFromContext(ctx)}, nil
+}
+
+// splitCacheDevice splits the physical volumes of vg into the cache device of a device class and the others,
+// so that the volume being cached is not allocated on the cache device. It returns the names of the others,
+// and the free bytes of the cache device and of the others.
+func splitCacheDevice(ctx context.Context, vg *command.VolumeGroup, cacheDevice string) ([]string, uint64, uint64, error) {
+	pvs, err := vg.ListPhysicalVolumes(ctx)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	// the cache device may be given by a symbolic link, e.g. /dev/disk/by-id/..., while lvm reports /dev/nvme0n1.
+	resolved, err := filepath.EvalSymlinks(cacheDevice)
+	if err != nil {
+		resolved = cacheDevice
+	}
+
+	var devices []string
+	var cacheFree, originFree uint64
+	found := false
+	for _, pv := range pvs {
+		if pv.Name() == cacheDevice || pv.Name() == resolved {
+			found = true
+			cacheFree = pv.Free()
+			continue
+		}
+		devices = append(devices, pv.Name())
+		originFree += pv.Free()
+	}
+	if !found {
+		return nil, 0, 0, fmt.Errorf("cache device %s is not a physical volume of VG %s", cacheDevice, vg.Name())
+	}
+	return devices, cacheFree, originFree, nil
+}
+
+// insufficientSpaceError returns codes.ResourceExhausted with space as the detail, so that the clients can tell
+// the lack of space from the other exhausted resources, e.g. the queue of requests of the volume group.
+func insufficientSpaceError(space *proto.InsufficientSpace) error {
+	var target string
+	switch {
+	case space.ThinPool != "":
+		target = fmt.Sprintf("thin pool %s/%s", space.VolumeGroup, space.ThinPool)
+	case space.VolumeGroup != "":
+		target = "VG " + space.VolumeGroup
+	default:
+		target = "raw devices"
+	}
+	st := status.Newf(codes.ResourceExhausted, "no enough space left on %s of device-class %s: free=%d, requested=%d",
+		target, space.DeviceClass, space.FreeBytes, space.RequestedBytes)
+	detailed, err := st.WithDetails(space)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// internalError returns the status of an unexpected error, e.g. a failed lvm command.
+// Commands killed after their timeout are reported with codes.DeadlineExceeded to tell them from failures.
+// The classified failures of lvm are reported with the codes telling the caller whether to retry.
+func internalError(err error) error {
+	switch {
+	case errors.Is(err, command.ErrCommandTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, command.ErrInsufficientFreeSpace):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, command.ErrLockContention), errors.Is(err, command.ErrResourceBusy):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, command.ErrDeviceMissing):
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// goneError is internalError for the RPCs returning codes.NotFound when the LV is gone, e.g. RemoveLV,
+// so that a missing device is not mistaken for a removed LV.
+func goneError(err error) error {
+	if errors.Is(err, command.ErrDeviceMissing) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return internalError(err)
+}
+
+// warningsFromContext converts the warnings printed by lvm in the context for the response.
+func warningsFromContext(ctx context.Context) []*proto.Warning {
+	var warnings []*proto.Warning
+	for _, w := range command.WarningsFromContext(ctx) {
+		warnings = append(warnings, &proto.Warning{
+			Reason:  string(w.Reason),
+			Message: w.Message,
+		})
+	}
+	return warnings
+}
+
+func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*proto.ResizeLVResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	ctx = command.WithWarnings(ctx)
+
+	dc, err := s.dcmapper.DeviceClass(req.DeviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
 
 	var requested uint64
@@ -334,14 +1016,61 @@ func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*
 		//lint:ignore SA1019 gRPC API has two fields for Gb and Bytes, both are valid until next minor
 		requested = req.GetSizeGb() << 30
 	}
+	if err := checkMaxSize(dc, requested); err != nil {
+		return nil, err
+	}
+	if dc.Type == lvmdTypes.TypeRaw {
+		return s.resizeRawLV(ctx, dc, req)
+	}
+	vg, err := findVolumeGroupOf(ctx, dc, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	// FindVolume on VolumeGroup or ThinPool returns ThinLogicalVolumes as well
+	// and no special handling for resize of LogicalVolume is needed
+	lv, err := vg.FindVolume(ctx, req.GetName())
+	if errors.Is(err, command.ErrNotFound) {
+		logger.Error(err, "logical volume is not found")
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
+	}
+	if err != nil {
+		logger.Error(err, "failed to find volume")
+		return nil, internalError(err)
+	}
+
+	if lv.IsSnapshot() && !lv.IsThin() {
+		return nil, status.Errorf(codes.FailedPrecondition, "thick snapshot %s cannot be resized", req.GetName())
+	}
+
+	// the volume may have been provisioned with the type overriding that of the device-class.
+	if lv.IsThin() && dc.Type != lvmdTypes.TypeThin {
+		pool, err := lv.Pool(ctx)
+		if err != nil {
+			logger.Error(err, "failed to get thinpool of volume")
+			return nil, internalError(err)
+		}
+		dc = s.dcmapper.thinVolumeDeviceClass(vg.Name(), pool.Name(), lv)
+		if dc == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s: thin pool %s", ErrDeviceClassNotFound.Error(), pool.Name())
+		}
+	} else if !lv.IsThin() && dc.Type == lvmdTypes.TypeThin {
+		dc, err = s.dcmapper.ProvisioningDeviceClass(dc, lvmdTypes.TypeThick)
+		if err != nil {
+			return nil, internalError(err)
+		}
+	}
+
 	current := lv.Size()
 
 	if requested < current {
-		logger.Error(err, "shrinking volume size is not allowed",
-			"requested", requested,
-			"current", current,
-		)
-		return nil, status.Error(codes.OutOfRange, "shrinking volume size is not allowed")
+		if !req.GetAllowShrink() {
+			logger.Error(err, "shrinking volume size is not allowed",
+				"requested", requested,
+				"current", current,
+			)
+			return nil, status.Error(codes.OutOfRange, "shrinking volume size is not allowed")
+		}
+		return s.shrinkLV(ctx, dc, lv, requested)
 	}
 
 	free := uint64(0)
@@ -351,20 +1080,27 @@ func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*
 		free, err = vg.Free()
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
+		}
+		if lv.IsRAID() {
+			free = GetUsableBytes(dc, free)
 		}
 	case lvmdTypes.TypeThin:
 		pool, err = vg.FindPool(ctx, dc.ThinPoolConfig.Name)
 		if err != nil {
 			logger.Error(err, "failed to get thinpool")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
 		}
 		tpu, err := pool.Free(ctx)
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
+		}
+		free, err = s.dcmapper.thinFreeBytes(ctx, dc, pool, tpu)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
 		}
-		free = uint64(math.Floor(dc.ThinPoolConfig.OverprovisionRatio*float64(tpu.SizeBytes))) - tpu.VirtualBytes
 	default:
 		// technically this block will not be hit however make sure we return error
 		// in such cases where deviceclass target is neither thick or thinpool
@@ -377,7 +1113,16 @@ func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*
 			"current", current,
 			"free", free,
 		)
-		return nil, status.Errorf(codes.ResourceExhausted, "no enough space left on VG: free=%d, requested=%d", free, requested-current)
+		space := &proto.InsufficientSpace{
+			DeviceClass:    dc.Name,
+			VolumeGroup:    vg.Name(),
+			RequestedBytes: requested - current,
+			FreeBytes:      free,
+		}
+		if pool != nil {
+			space.ThinPool = pool.Name()
+		}
+		return nil, insufficientSpaceError(space)
 	}
 
 	err = lv.Resize(ctx, requested)
@@ -387,11 +1132,11 @@ func (s *lvService) ResizeLV(ctx context.Context, req *proto.ResizeLVRequest) (*
 			"current", current,
 			"free", free,
 		)
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, internalError(err)
 	}
 	s.notify()
 
 	logger.Info("resized a LV", "size", requested)
 
-	return &proto.Empty{}, nil
+	return &proto.ResizeLVResponse{Warnings: warningsFromContext(ctx)}, nil
 }
@@ -3,15 +3,20 @@ package lvmd
 import (
 	"context"
 	"errors"
+	"io"
 	"os"
 	"os/exec"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr/testr"
 	"github.com/topolvm/topolvm/internal/lvmd/command"
 	"github.com/topolvm/topolvm/internal/lvmd/testutils"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -445,3 +450,1624 @@ func TestLVService(t *testing.T) {
 		t.Errorf(`testsnaptag1 not present on snapshot`)
 	}
 }
+
+func TestLVServiceWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.CreatePool(ctx, "pool", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	vdoPercent := uint(25)
+	cowPercent := uint(25)
+	lvService := NewLVService(
+		NewDeviceClassManager(
+			[]*lvmdTypes.DeviceClass{
+				{
+					Name:                   "thick",
+					VolumeGroup:            vg.Name(),
+					SnapshotCOWSizePercent: &cowPercent,
+				},
+				{
+					Name:        "vdo",
+					VolumeGroup: vg.Name(),
+					VDO:         &lvmdTypes.VDOConfig{PhysicalSizePercent: &vdoPercent},
+				},
+				{
+					Name:        "thin",
+					VolumeGroup: vg.Name(),
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: 20.0,
+					},
+					AllowShrink: true,
+				},
+			},
+		), NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), func() { count++ })
+
+	res, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+		SizeBytes:   1 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetVolume().GetSizeBytes() != 1<<30 {
+		t.Errorf(`res.Volume.SizeBytes != %d: %d`, 1<<30, res.GetVolume().GetSizeBytes())
+	}
+
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "test2",
+		DeviceClass: "thick",
+		SizeBytes:   3 << 30,
+	})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf(`code is not codes.ResouceExhausted: %s`, code)
+	}
+	if space := insufficientSpaceDetail(err); space == nil || space.GetDeviceClass() != "thick" || space.GetVolumeGroup() != "fake-vg" ||
+		space.GetThinPool() != "" || space.GetRequestedBytes() != 3<<30 || space.GetFreeBytes() != 2<<30 {
+		t.Errorf("unexpected detail: %v", space)
+	}
+
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "thin-too-large",
+		DeviceClass: "thin",
+		SizeBytes:   30 << 30,
+	})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf(`code is not codes.ResouceExhausted: %s`, code)
+	}
+	if space := insufficientSpaceDetail(err); space == nil || space.GetVolumeGroup() != "fake-vg" || space.GetThinPool() != "pool" ||
+		space.GetRequestedBytes() != 30<<30 || space.GetFreeBytes() != 20<<30 {
+		t.Errorf("unexpected detail: %v", space)
+	}
+
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+		SizeBytes:   2 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// copy-on-write snapshot of a thick volume
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "thick-snap",
+		DeviceClass:  "thick",
+		SourceVolume: "test1",
+		SizeBytes:    3 << 30,
+		AccessType:   "ro",
+	})
+	if code := status.Code(err); code != codes.OutOfRange {
+		t.Errorf(`code is not codes.OutOfRange: %s`, code)
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "thick-snap",
+		DeviceClass:  "thick",
+		SourceVolume: "test1",
+		SizeBytes:    2 << 30,
+		AccessType:   "ro",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	thickSnap, err := vg.FindVolume(ctx, "thick-snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !thickSnap.IsSnapshot() || thickSnap.IsThin() || thickSnap.Size() != 2<<30 {
+		t.Errorf("unexpected thick snapshot: attr=%s, size=%d", thickSnap.Attr(), thickSnap.Size())
+	}
+	if err := vg.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if free, _ := vg.Free(); free != 512<<20 {
+		t.Errorf("unexpected free bytes after creating a thick snapshot: %d", free)
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+	})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf(`code is not codes.FailedPrecondition: %s`, code)
+	}
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "thick-snap",
+		DeviceClass: "thick",
+		SizeBytes:   3 << 30,
+	})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf(`code is not codes.FailedPrecondition: %s`, code)
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{
+		Name:        "thick-snap",
+		DeviceClass: "thick",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// merge a thick snapshot back into its origin
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "thick-snap2",
+		DeviceClass:  "thick",
+		SourceVolume: "test1",
+		SizeBytes:    2 << 30,
+		AccessType:   "rw",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetDataPercent(vg.Name(), "thick-snap2", 40); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		mergeRes, err := lvService.MergeLVSnapshot(ctx, &proto.MergeLVSnapshotRequest{
+			Name:        "thick-snap2",
+			DeviceClass: "thick",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mergeRes.GetCompleted() || mergeRes.GetProgressPercent() != 60 {
+			t.Errorf("unexpected merge progress: completed=%v, progress=%f", mergeRes.GetCompleted(), mergeRes.GetProgressPercent())
+		}
+	}
+	if err := fake.SetDataPercent(vg.Name(), "thick-snap2", 0); err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.MergeLVSnapshot(ctx, &proto.MergeLVSnapshotRequest{
+		Name:        "thick-snap2",
+		DeviceClass: "thick",
+	})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf(`code is not codes.NotFound: %s`, code)
+	}
+	_, err = lvService.MergeLVSnapshot(ctx, &proto.MergeLVSnapshotRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`code is not codes.InvalidArgument: %s`, code)
+	}
+
+	thinRes, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "thin1",
+		DeviceClass: "thin",
+		SizeBytes:   5 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if warnings := thinRes.GetWarnings(); len(warnings) != 1 || warnings[0].GetReason() != string(command.WarningReasonThinPoolOverprovisioned) {
+		t.Errorf("overprovisioning should be warned: %v", warnings)
+	}
+	snapRes, err := lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "snap1",
+		DeviceClass:  "thin",
+		SourceVolume: "thin1",
+		SizeBytes:    5 << 30,
+		AccessType:   "ro",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapRes.GetSnapshot().GetName() != "snap1" {
+		t.Errorf(`snapRes.Snapshot.Name != "snap1": %s`, snapRes.GetSnapshot().GetName())
+	}
+	mergeRes, err := lvService.MergeLVSnapshot(ctx, &proto.MergeLVSnapshotRequest{
+		Name:        "snap1",
+		DeviceClass: "thin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !mergeRes.GetCompleted() {
+		t.Error("merge of thin snapshot should complete immediately")
+	}
+	if _, err := vg.FindVolume(ctx, "snap1"); !errors.Is(err, command.ErrNotFound) {
+		t.Errorf("merged snapshot should be removed: %v", err)
+	}
+
+	// shrink
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "thin1",
+		DeviceClass: "thin",
+		SizeBytes:   4 << 30,
+	})
+	if code := status.Code(err); code != codes.OutOfRange {
+		t.Errorf(`code is not codes.OutOfRange: %s`, code)
+	}
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "thin1",
+		DeviceClass: "thin",
+		SizeBytes:   4 << 30,
+		AllowShrink: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thin1, err := vg.FindVolume(ctx, "thin1"); err != nil {
+		t.Fatal(err)
+	} else if thin1.Size() != 4<<30 {
+		t.Errorf("volume is not shrunk: %d", thin1.Size())
+	}
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+		SizeBytes:   1 << 30,
+		AllowShrink: true,
+	})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf(`code is not codes.FailedPrecondition: %s`, code)
+	}
+
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "dedup1",
+		DeviceClass: "vdo",
+		SizeBytes:   1 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dedup, err := vg.FindVolume(ctx, "dedup1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dedup.IsVDO() || dedup.Size() != 1<<30 {
+		t.Errorf("unexpected VDO volume: attr=%s, size=%d", dedup.Attr(), dedup.Size())
+	}
+
+	// override the type of the device class
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:             "override-thick",
+		DeviceClass:      "thin",
+		SizeBytes:        512 << 20,
+		ProvisioningType: "thick",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{
+		Name:        "override-thick",
+		DeviceClass: "thin",
+		SizeBytes:   768 << 20,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:             "override-thin",
+		DeviceClass:      "thick",
+		SizeBytes:        2 << 30,
+		ProvisioningType: "thin",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, thin := range map[string]bool{"override-thick": false, "override-thin": true} {
+		lv, err := vg.FindVolume(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lv.IsThin() != thin {
+			t.Errorf("%s: unexpected thin: %v", name, lv.IsThin())
+		}
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "override-snap",
+		DeviceClass:  "thick",
+		SourceVolume: "override-thin",
+		AccessType:   "ro",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:             "invalid",
+		DeviceClass:      "thick",
+		SizeBytes:        1 << 30,
+		ProvisioningType: "unknown",
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`code is not codes.InvalidArgument: %s`, code)
+	}
+
+	for name, deviceClass := range map[string]string{
+		"test1": "thick", "thin1": "thin",
+		"override-thick": "thin", "override-thin": "thick", "override-snap": "thick",
+		"dedup1": "vdo",
+	} {
+		_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{
+			Name:        name,
+			DeviceClass: deviceClass,
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+	})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf(`code is not codes.NotFound: %s`, code)
+	}
+	if count != 21 {
+		t.Errorf("unexpected count: %d", count)
+	}
+}
+
+func TestLVServiceCreateDeviceClassSnapshotWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.CreatePool(ctx, "pool", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	lvService := NewLVService(
+		NewDeviceClassManager(
+			[]*lvmdTypes.DeviceClass{
+				{
+					Name:        "thick",
+					VolumeGroup: vg.Name(),
+				},
+				{
+					Name:        "thin",
+					VolumeGroup: vg.Name(),
+					Type:        lvmdTypes.TypeThin,
+					ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+						Name:               "pool",
+						OverprovisionRatio: 10.0,
+					},
+				},
+			},
+		), NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), func() { count++ })
+
+	for _, name := range []string{"thin1", "thin2"} {
+		_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+			Name:        name,
+			DeviceClass: "thin",
+			SizeBytes:   1 << 30,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "snap1",
+		DeviceClass:  "thin",
+		SourceVolume: "thin1",
+		AccessType:   "ro",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = lvService.CreateDeviceClassSnapshot(ctx, &proto.CreateDeviceClassSnapshotRequest{
+		DeviceClass: "thick",
+		NameSuffix:  "backup",
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`code is not codes.InvalidArgument: %s`, code)
+	}
+	_, err = lvService.CreateDeviceClassSnapshot(ctx, &proto.CreateDeviceClassSnapshotRequest{
+		DeviceClass: "thin",
+	})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf(`code is not codes.InvalidArgument: %s`, code)
+	}
+
+	res, err := lvService.CreateDeviceClassSnapshot(ctx, &proto.CreateDeviceClassSnapshotRequest{
+		DeviceClass: "thin",
+		NameSuffix:  "backup",
+		Tags:        []string{"backup"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// snapshots are not snapshotted again.
+	expected := map[string]string{"thin1": "thin1-backup", "thin2": "thin2-backup"}
+	if !reflect.DeepEqual(res.GetSnapshots(), expected) {
+		t.Errorf("unexpected snapshots: %v", res.GetSnapshots())
+	}
+	for _, name := range expected {
+		snapshot, err := vg.FindVolume(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !snapshot.IsSnapshot() || !snapshot.IsThin() {
+			t.Errorf("%s is not a thin snapshot: attr=%s", name, snapshot.Attr())
+		}
+	}
+
+	_, err = lvService.CreateDeviceClassSnapshot(ctx, &proto.CreateDeviceClassSnapshotRequest{
+		DeviceClass: "thin",
+		NameSuffix:  "backup",
+	})
+	if code := status.Code(err); code != codes.AlreadyExists {
+		t.Errorf(`code is not codes.AlreadyExists: %s`, code)
+	}
+	if count != 4 {
+		t.Errorf("unexpected count: %d", count)
+	}
+}
+
+func TestLVServiceChangeLVTagsWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{{Name: "thick", VolumeGroup: "fake-vg"}}),
+		NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+		SizeBytes:   1 << 30,
+		Tags:        []string{"old-scheme", "keep"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := lvService.ChangeLVTags(ctx, &proto.ChangeLVTagsRequest{
+		Name:        "test1",
+		DeviceClass: "thick",
+		AddTags:     []string{"new-scheme", "keep"},
+		RemoveTags:  []string{"old-scheme"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"keep", "new-scheme"}
+	if !reflect.DeepEqual(res.GetTags(), expected) {
+		t.Errorf("unexpected tags: %v", res.GetTags())
+	}
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lv, err := vg.FindVolume(ctx, "test1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lv.Tags(), expected) {
+		t.Errorf("unexpected tags of the volume: %v", lv.Tags())
+	}
+
+	testCases := []struct {
+		req  *proto.ChangeLVTagsRequest
+		code codes.Code
+	}{
+		{
+			req:  &proto.ChangeLVTagsRequest{Name: "test1", DeviceClass: "thick"},
+			code: codes.InvalidArgument,
+		},
+		{
+			req:  &proto.ChangeLVTagsRequest{Name: "test1", DeviceClass: "thick", AddTags: []string{"a"}, RemoveTags: []string{"a"}},
+			code: codes.InvalidArgument,
+		},
+		{
+			req:  &proto.ChangeLVTagsRequest{Name: "missing", DeviceClass: "thick", AddTags: []string{"a"}},
+			code: codes.NotFound,
+		},
+		{
+			req:  &proto.ChangeLVTagsRequest{Name: "test1", DeviceClass: "missing", AddTags: []string{"a"}},
+			code: codes.NotFound,
+		},
+	}
+	for _, tc := range testCases {
+		_, err := lvService.ChangeLVTags(ctx, tc.req)
+		if code := status.Code(err); code != tc.code {
+			t.Errorf("unexpected code for %v: %s", tc.req, code)
+		}
+	}
+}
+
+func TestLVServiceActivationSkipWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{{Name: "skip", VolumeGroup: "fake-vg", ActivationSkip: true}}),
+		NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:        "test1",
+		DeviceClass: "skip",
+		SizeBytes:   1 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	findVolume := func() *command.LogicalVolume {
+		t.Helper()
+		lv, err := vg.FindVolume(ctx, "test1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lv
+	}
+	if lv := findVolume(); lv.IsActive() || !lv.HasActivationSkip() {
+		t.Fatalf("volume should be created inactive with the activation skip flag: %s", lv.Attr())
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := lvService.ActivateLV(ctx, &proto.ActivateLVRequest{Name: "test1", DeviceClass: "skip"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetVolume().GetDevMajor() == 0 {
+			t.Errorf("device numbers of the activated volume should be returned: %v", res.GetVolume())
+		}
+	}
+	if lv := findVolume(); !lv.IsActive() || !lv.HasActivationSkip() {
+		t.Errorf("volume should be activated keeping the activation skip flag: %s", lv.Attr())
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := lvService.DeactivateLV(ctx, &proto.DeactivateLVRequest{Name: "test1", DeviceClass: "skip"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lv := findVolume(); lv.IsActive() {
+		t.Errorf("volume should be deactivated: %s", lv.Attr())
+	}
+
+	_, err = lvService.ActivateLV(ctx, &proto.ActivateLVRequest{Name: "missing", DeviceClass: "skip"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("unexpected code: %s", code)
+	}
+	_, err = lvService.DeactivateLV(ctx, &proto.DeactivateLVRequest{Name: "test1", DeviceClass: "missing"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("unexpected code: %s", code)
+	}
+}
+
+// lvcreateRecorder records the arguments of lvcreate.
+type lvcreateRecorder struct {
+	command.Executor
+	args [][]string
+}
+
+func (r *lvcreateRecorder) Execute(ctx context.Context, binary command.Binary, args ...string) (io.ReadCloser, error) {
+	if binary == command.BinaryLVM && len(args) > 0 && args[0] == "lvcreate" {
+		r.args = append(r.args, args)
+	}
+	return r.Executor.Execute(ctx, binary, args...)
+}
+
+func TestLVServiceInlineOptionsWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	recorder := &lvcreateRecorder{Executor: fake}
+	defer command.SetExecutor(recorder)()
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{{Name: "ssd", VolumeGroup: "fake-vg"}}),
+		NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{
+			{Name: "tunable", Options: []string{"--wipesignatures=y"}, InlineOptions: []string{"--alloc="}},
+			{Name: "fixed", Options: []string{"--wipesignatures=y"}},
+		}), nil)
+
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{
+		Name:                "test1",
+		DeviceClass:         "ssd",
+		LvcreateOptionClass: "tunable",
+		LvcreateOptions:     []string{"--alloc=anywhere"},
+		SizeBytes:           1 << 30,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.args) != 1 {
+		t.Fatalf("lvcreate should be called once: %v", recorder.args)
+	}
+	args := strings.Join(recorder.args[0], " ")
+	if !strings.Contains(args, "--wipesignatures=y") || !strings.Contains(args, "--alloc=anywhere") {
+		t.Errorf("the options of the class and the inline options should be passed: %s", args)
+	}
+	if strings.Contains(args, "-W y") {
+		t.Errorf("the options of the class should replace the default -W: %s", args)
+	}
+
+	for _, req := range []*proto.CreateLVRequest{
+		{Name: "test2", DeviceClass: "ssd", LvcreateOptionClass: "tunable", LvcreateOptions: []string{"--config=devices/filter=[]"}, SizeBytes: 1 << 30},
+		{Name: "test3", DeviceClass: "ssd", LvcreateOptionClass: "fixed", LvcreateOptions: []string{"--alloc=anywhere"}, SizeBytes: 1 << 30},
+		{Name: "test4", DeviceClass: "ssd", LvcreateOptions: []string{"--alloc=anywhere"}, SizeBytes: 1 << 30},
+	} {
+		_, err := lvService.CreateLV(ctx, req)
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("%s: inline options not allowed by the class should be rejected: %v", req.Name, err)
+		}
+	}
+	if len(recorder.args) != 1 {
+		t.Errorf("lvcreate should not be called for rejected options: %v", recorder.args)
+	}
+}
+
+func TestLVServiceCacheDeviceWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddDevice("/dev/sdb", 8<<30)
+	fake.AddDevice("/dev/sdz", 1<<30)
+	recorder := &lvcreateRecorder{Executor: fake}
+	defer command.SetExecutor(recorder)()
+	if _, err := command.CreateVolumeGroup(ctx, "cache-vg", []string{"/dev/sdb", "/dev/sdz"}); err != nil {
+		t.Fatal(err)
+	}
+
+	full := uint(100)
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{Name: "cached", VolumeGroup: "cache-vg", Cache: &lvmdTypes.CacheConfig{Device: "/dev/sdz"}},
+			{Name: "full", VolumeGroup: "cache-vg", Cache: &lvmdTypes.CacheConfig{Device: "/dev/sdz", SizePercent: &full}},
+		}),
+		NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "test1", DeviceClass: "cached", SizeBytes: 2 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorder.args) != 2 {
+		t.Fatalf("lvcreate should be called for the volume and its cache: %v", recorder.args)
+	}
+	if args := strings.Join(recorder.args[0], " "); !strings.HasSuffix(args, "cache-vg /dev/sdb") {
+		t.Errorf("the volume should be allocated on the devices except the cache device: %s", args)
+	}
+	if args := strings.Join(recorder.args[1], " "); !strings.HasSuffix(args, "cache-vg /dev/sdz") {
+		t.Errorf("the cache should be allocated on the cache device: %s", args)
+	}
+
+	// the volume group has enough space for the volume and its cache, but the cache device does not.
+	recorder.args = nil
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "test2", DeviceClass: "full", SizeBytes: 2 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("unexpected code: %s", code)
+	}
+	if len(recorder.args) != 0 {
+		t.Errorf("lvcreate should not be called: %v", recorder.args)
+	}
+}
+
+// discardRecorder records the blkdiscard commands passed to an Executor.
+type discardRecorder struct {
+	command.Executor
+	calls []string
+}
+
+func (e *discardRecorder) Execute(ctx context.Context, binary command.Binary, args ...string) (io.ReadCloser, error) {
+	if binary == command.BinaryBlkdiscard {
+		e.calls = append(e.calls, "blkdiscard "+strings.Join(args, " "))
+	}
+	return e.Executor.Execute(ctx, binary, args...)
+}
+
+func TestLVServiceWipeOnDeleteWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	fake.AddVolumeGroup("plain-vg", 4<<30)
+	recorder := &discardRecorder{Executor: fake}
+	defer command.SetExecutor(recorder)()
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{Name: "wipe", VolumeGroup: "fake-vg", ActivationSkip: true, WipeOnDelete: lvmdTypes.WipeDiscard},
+			{Name: "plain", VolumeGroup: "plain-vg"},
+		}),
+		NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+
+	testCases := []struct {
+		name         string
+		deviceClass  string
+		wipeOnDelete string
+		expected     []string
+	}{
+		{name: "inactive", deviceClass: "wipe", expected: []string{"blkdiscard /dev/fake-vg/inactive"}},
+		{name: "zero", deviceClass: "plain", wipeOnDelete: "zero", expected: []string{"blkdiscard -z /dev/plain-vg/zero"}},
+		{name: "none", deviceClass: "wipe", wipeOnDelete: "none"},
+		{name: "default", deviceClass: "plain"},
+	}
+	for _, tc := range testCases {
+		_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: tc.name, DeviceClass: tc.deviceClass, SizeBytes: 1 << 30})
+		if err != nil {
+			t.Fatal(err)
+		}
+		recorder.calls = nil
+		_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: tc.name, DeviceClass: tc.deviceClass, WipeOnDelete: tc.wipeOnDelete})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(recorder.calls, tc.expected) {
+			t.Errorf("%s: unexpected blkdiscard commands: %v", tc.name, recorder.calls)
+		}
+	}
+
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "invalid", DeviceClass: "plain", SizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "invalid", DeviceClass: "plain", WipeOnDelete: "shred"})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("unexpected code: %s", code)
+	}
+}
+
+func TestLVServiceSnapshotDepthWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.CreatePool(ctx, "pool", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{
+				Name:        "thin",
+				VolumeGroup: vg.Name(),
+				Type:        lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+					Name:               "pool",
+					OverprovisionRatio: 10.0,
+					MaxSnapshotDepth:   2,
+				},
+			},
+		}), NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "vol", DeviceClass: "thin", SizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := "vol"
+	for _, name := range []string{"snap1", "snap2"} {
+		_, err := lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+			Name:         name,
+			DeviceClass:  "thin",
+			SourceVolume: source,
+			AccessType:   "rw",
+		})
+		if err != nil {
+			t.Fatalf("failed to snapshot %s: %v", source, err)
+		}
+		source = name
+	}
+
+	snap2, err := vg.FindVolume(ctx, "snap2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := snap2.OriginChain(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(chain))
+	for _, lv := range chain {
+		names = append(names, lv.Name())
+	}
+	if !reflect.DeepEqual(names, []string{"snap1", "vol"}) {
+		t.Errorf("unexpected origin chain: %v", names)
+	}
+
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "snap3",
+		DeviceClass:  "thin",
+		SourceVolume: "snap2",
+		AccessType:   "rw",
+	})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Errorf("unexpected code: %s", code)
+	}
+
+	// the chain ends at a removed origin.
+	if err := vg.RemoveVolume(ctx, "snap1"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "snap3",
+		DeviceClass:  "thin",
+		SourceVolume: "snap2",
+		AccessType:   "rw",
+	})
+	if err != nil {
+		t.Errorf("snapshot of a snapshot whose origin is removed should be allowed: %v", err)
+	}
+}
+
+func TestLVServiceMoveWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 8<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"pool-a", "pool-b"} {
+		if _, err := vg.CreatePool(ctx, name, 2<<30); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{
+				Name:           "thin-a",
+				VolumeGroup:    vg.Name(),
+				Type:           lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool-a", OverprovisionRatio: 10.0},
+			},
+			{
+				Name:           "thin-b",
+				VolumeGroup:    vg.Name(),
+				Type:           lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool-b", OverprovisionRatio: 10.0},
+			},
+			{Name: "thick", VolumeGroup: vg.Name()},
+		}), NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "vol", DeviceClass: "thin-a", SizeBytes: 1 << 30, Tags: []string{"tag1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = lvService.MoveLV(ctx, &proto.MoveLVRequest{Name: "vol", DeviceClass: "thin-a", TargetDeviceClass: "thick"})
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Errorf("unexpected code moving to thick device class: %s", code)
+	}
+
+	req := &proto.MoveLVRequest{Name: "vol", DeviceClass: "thin-a", TargetDeviceClass: "thin-b"}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		res, err := lvService.MoveLV(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetCompleted() {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("move did not complete")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lv, err := vg.FindVolume(ctx, "vol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := lv.Pool(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool.Name() != "pool-b" {
+		t.Errorf("volume is not moved: pool=%s", pool.Name())
+	}
+	if !reflect.DeepEqual(lv.Tags(), []string{"tag1"}) {
+		t.Errorf("unexpected tags: %v", lv.Tags())
+	}
+	volumes, err := vg.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range volumes {
+		if strings.HasPrefix(name, "vol-") {
+			t.Errorf("volume %s is left", name)
+		}
+	}
+
+	// moving the volume again to the same pool completes immediately.
+	res, err := lvService.MoveLV(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.GetCompleted() {
+		t.Error("move to the current pool should complete immediately")
+	}
+}
+
+// failingDDExecutor fails the dd commands passed to an Executor.
+type failingDDExecutor struct {
+	command.Executor
+}
+
+func (e *failingDDExecutor) Execute(ctx context.Context, binary command.Binary, args ...string) (io.ReadCloser, error) {
+	if binary == command.BinaryDd {
+		return nil, errors.New("dd: error writing: No space left on device")
+	}
+	return e.Executor.Execute(ctx, binary, args...)
+}
+
+func TestLVServiceMoveRecoveryWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 8<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pools := map[string]*command.ThinPool{}
+	for _, name := range []string{"pool-a", "pool-b"} {
+		pools[name], err = vg.CreatePool(ctx, name, 2<<30)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	lvService := NewLVService(
+		NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+			{
+				Name:           "thin-a",
+				VolumeGroup:    vg.Name(),
+				Type:           lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool-a", OverprovisionRatio: 10.0},
+			},
+			{
+				Name:           "thin-b",
+				VolumeGroup:    vg.Name(),
+				Type:           lvmdTypes.TypeThin,
+				ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool-b", OverprovisionRatio: 10.0},
+			},
+		}), NewLvcreateOptionClassManager([]*lvmdTypes.LvcreateOptionClass{}), nil)
+
+	findVolume := func(name string) *command.LogicalVolume {
+		t.Helper()
+		lv, err := vg.FindVolume(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lv
+	}
+	expectPool := func(lv *command.LogicalVolume, expected string) {
+		t.Helper()
+		pool, err := lv.Pool(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pool.Name() != expected {
+			t.Errorf("unexpected pool of %s: expected=%s, actual=%s", lv.Name(), expected, pool.Name())
+		}
+	}
+	move := func(name string) {
+		t.Helper()
+		req := &proto.MoveLVRequest{Name: name, DeviceClass: "thin-a", TargetDeviceClass: "thin-b"}
+		deadline := time.Now().Add(10 * time.Second)
+		for {
+			res, err := lvService.MoveLV(ctx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.GetCompleted() {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("move did not complete")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// lvmd stopped after renaming the volume but before renaming its copy.
+	if err := pools["pool-a"].CreateVolume(ctx, "swapped", 1<<30, []string{"tag1"}, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := findVolume("swapped").SetActivationSkip(ctx, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := pools["pool-b"].CreateVolume(ctx, "swapped"+movingSuffix, 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := findVolume("swapped").Rename(ctx, "swapped"+movedSuffix); err != nil {
+		t.Fatal(err)
+	}
+	move("swapped")
+	lv := findVolume("swapped")
+	expectPool(lv, "pool-b")
+	if !reflect.DeepEqual(lv.Tags(), []string{"tag1"}) || !lv.HasActivationSkip() {
+		t.Errorf("the copy does not inherit the volume: tags=%v, skip=%v", lv.Tags(), lv.HasActivationSkip())
+	}
+
+	// lvmd stopped after renaming the volume, and the copy is gone.
+	if err := pools["pool-a"].CreateVolume(ctx, "renamed", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := findVolume("renamed").Rename(ctx, "renamed"+movedSuffix); err != nil {
+		t.Fatal(err)
+	}
+	move("renamed")
+	expectPool(findVolume("renamed"), "pool-b")
+
+	volumes, err := vg.ListVolumes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name := range volumes {
+		if strings.HasSuffix(name, movedSuffix) || strings.HasSuffix(name, movingSuffix) {
+			t.Errorf("volume %s is left", name)
+		}
+	}
+
+	// a failed copy leaves the volume as it was.
+	if err := pools["pool-a"].CreateVolume(ctx, "failed", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := findVolume("failed").Deactivate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	command.SetExecutor(&failingDDExecutor{Executor: fake})
+	req := &proto.MoveLVRequest{Name: "failed", DeviceClass: "thin-a", TargetDeviceClass: "thin-b"}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		res, err := lvService.MoveLV(ctx, req)
+		if err != nil {
+			if code := status.Code(err); code != codes.Internal {
+				t.Errorf("unexpected code of failed move: %s", code)
+			}
+			break
+		}
+		if res.GetCompleted() {
+			t.Fatal("move should fail")
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("move did not fail")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	lv = findVolume("failed")
+	expectPool(lv, "pool-a")
+	if lv.IsActive() {
+		t.Error("the volume should be deactivated again")
+	}
+	if _, err := vg.FindVolume(ctx, "failed"+movingSuffix); !errors.Is(err, command.ErrNotFound) {
+		t.Errorf("the incomplete copy should be removed: %v", err)
+	}
+}
+
+func TestLVServiceRawWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddDevice("/dev/nvme1n1", 4<<30)
+	fake.AddDevice("/dev/nvme2n1", 2<<30)
+	fake.AddDevice("/dev/nvme3n1", 8<<30)
+	fake.AddDevice("/dev/sdb", 1<<30)
+	defer command.SetExecutor(fake)()
+
+	// physical volumes matching the pattern are not handed out.
+	if _, err := command.CreateVolumeGroup(ctx, "vg", []string{"/dev/nvme3n1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dc := &lvmdTypes.DeviceClass{
+		Name:         "raw",
+		Type:         lvmdTypes.TypeRaw,
+		Raw:          &lvmdTypes.RawConfig{Devices: []string{"/dev/nvme*n1"}, StateFile: t.TempDir() + "/raw.json"},
+		WipeOnDelete: lvmdTypes.WipeDiscard,
+	}
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{dc})
+	var count int
+	vgService, notifier := NewVGService(dcManager, NewLvcreateOptionClassManager(nil))
+	lvService := NewLVService(dcManager, NewLvcreateOptionClassManager(nil), func() {
+		count++
+		notifier()
+	})
+
+	free, err := vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free.GetFreeBytes() != 4<<30 {
+		t.Errorf("the largest free device should be the free bytes: %d", free.GetFreeBytes())
+	}
+
+	// the smallest device fitting the requested size is claimed.
+	res, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv1", DeviceClass: "raw", SizeBytes: 1 << 30, Tags: []string{"tag1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetVolume().GetSizeBytes() != 2<<30 || res.GetVolume().GetDevMajor() == 0 {
+		t.Errorf("unexpected volume: %v", res.GetVolume())
+	}
+	for _, tc := range []struct {
+		req  *proto.CreateLVRequest
+		code codes.Code
+	}{
+		{&proto.CreateLVRequest{Name: "lv1", DeviceClass: "raw", SizeBytes: 1 << 30}, codes.AlreadyExists},
+		{&proto.CreateLVRequest{Name: "lv2", DeviceClass: "raw", SizeBytes: 5 << 30}, codes.ResourceExhausted},
+		{&proto.CreateLVRequest{Name: "lv2", DeviceClass: "raw", SizeBytes: 1 << 30, ProvisioningType: "thin"}, codes.FailedPrecondition},
+		{&proto.CreateLVRequest{Name: "lv2", DeviceClass: "raw", SizeBytes: 1 << 30, LvcreateOptions: []string{"--type=raid1"}}, codes.InvalidArgument},
+	} {
+		_, err := lvService.CreateLV(ctx, tc.req)
+		if status.Code(err) != tc.code {
+			t.Errorf("expected %s for %v, got %v", tc.code, tc.req, err)
+		}
+	}
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv2", DeviceClass: "raw", SizeBytes: 3 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	free, err = vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free.GetFreeBytes() != 0 {
+		t.Errorf("no device should be free: %d", free.GetFreeBytes())
+	}
+
+	tags, err := lvService.ChangeLVTags(ctx, &proto.ChangeLVTagsRequest{Name: "lv1", DeviceClass: "raw", AddTags: []string{"tag2"}, RemoveTags: []string{"tag1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags.GetTags(), []string{"tag2"}) {
+		t.Errorf("unexpected tags: %v", tags.GetTags())
+	}
+	if _, err := lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "lv1", DeviceClass: "raw", SizeBytes: 2 << 30}); err != nil {
+		t.Errorf("resizing within the device should succeed: %v", err)
+	}
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "lv1", DeviceClass: "raw", SizeBytes: 3 << 30})
+	if status.Code(err) != codes.OutOfRange {
+		t.Errorf("expected OutOfRange for resizing beyond the device, got %v", err)
+	}
+	activated, err := lvService.ActivateLV(ctx, &proto.ActivateLVRequest{Name: "lv2", DeviceClass: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr, err := command.ParsedLvAttr(activated.GetVolume().GetAttr()); err != nil || attr.State != command.StateActive {
+		t.Errorf("raw volume should be active: %v", activated.GetVolume())
+	}
+
+	list, err := vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetVolumes()) != 2 || !reflect.DeepEqual(list.GetVolumes()[0].GetTags(), []string{"tag2"}) {
+		t.Errorf("unexpected volumes: %v", list.GetVolumes())
+	}
+
+	if _, err := lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "lv1", DeviceClass: "raw"}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "lv1", DeviceClass: "raw"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for a removed volume, got %v", err)
+	}
+	free, err = vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "raw"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free.GetFreeBytes() != 2<<30 {
+		t.Errorf("the released device should be free: %d", free.GetFreeBytes())
+	}
+	if count != 3 {
+		t.Errorf("the watchers should be notified of creating and removing volumes: %d", count)
+	}
+}
+
+func TestLVServiceSharedThinPoolWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vg.CreatePool(ctx, "pool", 1<<30); err != nil {
+		t.Fatal(err)
+	}
+
+	spare := uint64(0)
+	goldQuota, bronzeQuota := uint64(1), uint64(9)
+	deviceClasses := []*lvmdTypes.DeviceClass{
+		{
+			Name:        "gold",
+			VolumeGroup: vg.Name(),
+			Type:        lvmdTypes.TypeThin,
+			SpareGB:     &spare,
+			ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+				Name:               "pool",
+				OverprovisionRatio: 2.0,
+				VirtualQuotaGB:     &goldQuota,
+			},
+		},
+		{
+			Name:        "bronze",
+			VolumeGroup: vg.Name(),
+			Type:        lvmdTypes.TypeThin,
+			SpareGB:     &spare,
+			ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+				Name:               "pool",
+				OverprovisionRatio: 10.0,
+				VirtualQuotaGB:     &bronzeQuota,
+			},
+		},
+	}
+	if err := ValidateDeviceClasses(deviceClasses); err != nil {
+		t.Fatal(err)
+	}
+	dcManager := NewDeviceClassManager(deviceClasses)
+	ocManager := NewLvcreateOptionClassManager(nil)
+	vgService, notifier := NewVGService(dcManager, ocManager)
+	lvService := NewLVService(dcManager, ocManager, notifier)
+
+	freeBytes := func(deviceClass string) uint64 {
+		t.Helper()
+		res, err := vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: deviceClass})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.GetFreeBytes()
+	}
+	volumes := func(deviceClass string) []string {
+		t.Helper()
+		res, err := vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: deviceClass})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, 0, len(res.GetVolumes()))
+		for _, v := range res.GetVolumes() {
+			names = append(names, v.GetName())
+		}
+		return names
+	}
+
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "g1", DeviceClass: "gold", SizeBytes: 1 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "g2", DeviceClass: "gold", SizeBytes: 1 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("the quota of gold should be exhausted: %v", err)
+	}
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "b1", DeviceClass: "bronze", SizeBytes: 4 << 30}); err != nil {
+		t.Fatal(err)
+	}
+
+	if free := freeBytes("gold"); free != 0 {
+		t.Errorf("unexpected free bytes of gold: %d", free)
+	}
+	// the pool allows 10 GiB of which 5 GiB are used, and bronze uses 4 GiB of its 9 GiB quota.
+	if free := freeBytes("bronze"); free != 5<<30 {
+		t.Errorf("unexpected free bytes of bronze: %d", free)
+	}
+	if names := volumes("gold"); !reflect.DeepEqual(names, []string{"g1"}) {
+		t.Errorf("unexpected volumes of gold: %v", names)
+	}
+	if names := volumes("bronze"); !reflect.DeepEqual(names, []string{"b1"}) {
+		t.Errorf("unexpected volumes of bronze: %v", names)
+	}
+
+	// snapshots count towards the quota of the device-class of the source.
+	if _, err := lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name: "b1-snap", DeviceClass: "bronze", SourceVolume: "b1", AccessType: "ro",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := vg.FindVolume(ctx, "b1-snap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(snap.Tags(), []string{"topolvm.io/device-class=bronze"}) {
+		t.Errorf("unexpected tags of the snapshot: %v", snap.Tags())
+	}
+	if free := freeBytes("bronze"); free != 1<<30 {
+		t.Errorf("unexpected free bytes of bronze: %d", free)
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name: "b1-snap2", DeviceClass: "bronze", SourceVolume: "b1", AccessType: "ro",
+	})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("the quota of bronze should be exhausted: %v", err)
+	}
+}
+
+func insufficientSpaceDetail(err error) *proto.InsufficientSpace {
+	for _, detail := range status.Convert(err).Details() {
+		if space, ok := detail.(*proto.InsufficientSpace); ok {
+			return space
+		}
+	}
+	return nil
+}
+
+type recordingWatchServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*proto.WatchResponse
+}
+
+func (s *recordingWatchServer) Send(r *proto.WatchResponse) error {
+	s.responses = append(s.responses, r)
+	return nil
+}
+
+func (s *recordingWatchServer) Context() context.Context {
+	return s.ctx
+}
+
+func TestLVServiceMultipleVolumeGroupsWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("a1", 4<<30)
+	fake.AddVolumeGroup("a2", 8<<30)
+	fake.AddVolumeGroup("b1", 4<<30)
+	fake.AddVolumeGroup("b2", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	noSpare := uint64(0)
+	dcm := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "most-free", VolumeGroups: []string{"a1", "a2"}, SpareGB: &noSpare},
+		{
+			Name:              "round-robin",
+			VolumeGroups:      []string{"b1", "b2"},
+			VolumeGroupPolicy: lvmdTypes.VolumeGroupPolicyRoundRobin,
+			SpareGB:           &noSpare,
+		},
+	})
+	lvService := NewLVService(dcm, NewLvcreateOptionClassManager(nil), nil)
+	vgServer, _ := NewVGService(dcm, NewLvcreateOptionClassManager(nil))
+	vgs := vgServer.(*vgService)
+
+	create := func(name, deviceClass string, size int64) {
+		t.Helper()
+		_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: name, DeviceClass: deviceClass, SizeBytes: size})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	vgOf := func(name string) string {
+		t.Helper()
+		for _, vgName := range []string{"a1", "a2", "b1", "b2"} {
+			vg, err := command.FindVolumeGroup(ctx, vgName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := vg.FindVolume(ctx, name); err == nil {
+				return vgName
+			}
+		}
+		return ""
+	}
+
+	// most-free creates the volumes in the volume group with the most free space, the first one on a tie.
+	create("v1", "most-free", 2<<30)
+	create("v2", "most-free", 2<<30)
+	create("v3", "most-free", 2<<30)
+	for name, expected := range map[string]string{"v1": "a2", "v2": "a2", "v3": "a1"} {
+		if vg := vgOf(name); vg != expected {
+			t.Errorf("%s should be created in %s, but in %s", name, expected, vg)
+		}
+	}
+
+	// round-robin takes turns, skipping the volume groups without enough space.
+	create("r1", "round-robin", 1<<30)
+	create("r2", "round-robin", 1<<30)
+	create("r3", "round-robin", 1<<30)
+	create("r4", "round-robin", 3<<30)
+	for name, expected := range map[string]string{"r1": "b1", "r2": "b2", "r3": "b1", "r4": "b2"} {
+		if vg := vgOf(name); vg != expected {
+			t.Errorf("%s should be created in %s, but in %s", name, expected, vg)
+		}
+	}
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "r5", DeviceClass: "round-robin", SizeBytes: 3 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("no volume group should have enough space: %v", err)
+	}
+
+	// a retried request does not create the volume in another volume group.
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "r1", DeviceClass: "round-robin", SizeBytes: 1 << 30}); err == nil {
+		t.Error("creating an existing volume should fail")
+	}
+	if vg := vgOf("r1"); vg != "b1" {
+		t.Errorf("r1 should stay in b1, but in %s", vg)
+	}
+
+	// a volume fits into a single volume group, so the free bytes are those of a2 with the most free space.
+	res, err := vgs.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "most-free", ForceRefresh: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetFreeBytes() != 4<<30 {
+		t.Errorf("unexpected free bytes: %d", res.GetFreeBytes())
+	}
+	watch := &recordingWatchServer{ctx: ctx}
+	if err := vgs.send(watch); err != nil {
+		t.Fatal(err)
+	}
+	var items []*proto.WatchItem
+	for _, item := range watch.responses[0].GetItems() {
+		if item.GetDeviceClass() == "most-free" {
+			items = append(items, item)
+		}
+	}
+	// the sums are reported for the metrics.
+	if len(items) != 1 || items[0].GetFreeBytes() != 6<<30 || items[0].GetSizeBytes() != 12<<30 ||
+		items[0].GetLargestFreeBytes() != 4<<30 {
+		t.Errorf("unexpected watch items: %v", items)
+	}
+	list, err := vgs.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: "most-free"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetVolumes()) != 3 {
+		t.Errorf("unexpected volumes: %v", list.GetVolumes())
+	}
+
+	// the volumes are found in any of the volume groups.
+	if _, err := lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "v3", DeviceClass: "most-free", SizeBytes: 3 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "v1", DeviceClass: "most-free"}); err != nil {
+		t.Fatal(err)
+	}
+	if vg := vgOf("v1"); vg != "" {
+		t.Errorf("v1 should be removed from %s", vg)
+	}
+	_, err = lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "v1", DeviceClass: "most-free"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("unexpected code: %s", code)
+	}
+}
+
+func TestLVServiceQuotasWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fast", 16<<30)
+	defer command.SetExecutor(fake)()
+
+	noSpare := uint64(0)
+	lvService := NewLVService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "fast", VolumeGroup: "fast", SpareGB: &noSpare, MaxSize: "2Gi", MaxVolumes: 2},
+	}), NewLvcreateOptionClassManager(nil), nil)
+
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "giant", DeviceClass: "fast", SizeBytes: 3 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("a volume larger than max-size should be rejected: %v", err)
+	}
+	for _, name := range []string{"lv1", "lv2"} {
+		if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: name, DeviceClass: "fast", SizeBytes: 2 << 30}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv3", DeviceClass: "fast", SizeBytes: 1 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("a volume over max-volumes should be rejected: %v", err)
+	}
+	// retries of the existing volumes fail as without the quota.
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv2", DeviceClass: "fast", SizeBytes: 2 << 30})
+	if code := status.Code(err); code == codes.ResourceExhausted {
+		t.Errorf("a retry of an existing volume should not be rejected by max-volumes: %v", err)
+	}
+
+	_, err = lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "lv1", DeviceClass: "fast", SizeBytes: 4 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("resizing beyond max-size should be rejected: %v", err)
+	}
+
+	if _, err := lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "lv1", DeviceClass: "fast"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv3", DeviceClass: "fast", SizeBytes: 1 << 30}); err != nil {
+		t.Errorf("the removed volume should make room for another: %v", err)
+	}
+}
+
+func TestLVServiceDrainingWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("old", 16<<30)
+	defer command.SetExecutor(fake)()
+
+	noSpare := uint64(0)
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "old", VolumeGroup: "old", SpareGB: &noSpare},
+	})
+	var count int
+	vgService, notifier := NewVGService(dcManager, NewLvcreateOptionClassManager(nil))
+	lvService := NewLVService(dcManager, NewLvcreateOptionClassManager(nil), func() {
+		count++
+		notifier()
+	})
+
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv1", DeviceClass: "old", SizeBytes: 1 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vgService.SetDeviceClassDraining(ctx, &proto.SetDeviceClassDrainingRequest{DeviceClass: "old", Draining: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv2", DeviceClass: "old", SizeBytes: 1 << 30})
+	if code := status.Code(err); code != codes.ResourceExhausted {
+		t.Errorf("a new volume in a draining device-class should be rejected: %v", err)
+	}
+	// retries of the existing volumes fail as without draining.
+	_, err = lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv1", DeviceClass: "old", SizeBytes: 1 << 30})
+	if code := status.Code(err); code == codes.ResourceExhausted {
+		t.Errorf("a retry of an existing volume should not be rejected by draining: %v", err)
+	}
+	free, err := vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "old", ForceRefresh: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if free.GetFreeBytes() != 0 {
+		t.Errorf("a draining device-class should have no free bytes: %d", free.GetFreeBytes())
+	}
+
+	// the existing volumes can still be resized, snapshotted and removed.
+	if _, err := lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "lv1", DeviceClass: "old", SizeBytes: 2 << 30}); err != nil {
+		t.Error(err)
+	}
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name: "snap1", DeviceClass: "old", SourceVolume: "lv1", SizeBytes: 2 << 30, AccessType: "ro",
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	for _, name := range []string{"snap1", "lv1"} {
+		if _, err := lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: name, DeviceClass: "old"}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := vgService.SetDeviceClassDraining(ctx, &proto.SetDeviceClassDrainingRequest{DeviceClass: "old"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv2", DeviceClass: "old", SizeBytes: 1 << 30}); err != nil {
+		t.Errorf("a device-class no longer draining should accept new volumes: %v", err)
+	}
+
+	_, err = vgService.SetDeviceClassDraining(ctx, &proto.SetDeviceClassDrainingRequest{DeviceClass: "none", Draining: true})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf("an unknown device-class should be reported: %v", err)
+	}
+}
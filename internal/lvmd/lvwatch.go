@@ -0,0 +1,98 @@
+package lvmd
+
+import (
+	"context"
+	"sort"
+
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// The types of LVEvent.
+const (
+	LVEventCreated = "created"
+	LVEventResized = "resized"
+	LVEventDeleted = "deleted"
+)
+
+// WatchLVs streams the changes of the logical volumes. The logical volumes are listed on each notification
+// of the watchers, i.e. after the LVService changed them, and compared to the previous listing.
+func (s *vgService) WatchLVs(req *proto.WatchLVsRequest, server proto.VGService_WatchLVsServer) error {
+	ctx := server.Context()
+	var dcs []*lvmdTypes.DeviceClass
+	if req.GetDeviceClass() == "" {
+		dcs = s.dcManager.DeviceClasses()
+	} else {
+		dc, err := s.dcManager.DeviceClass(req.GetDeviceClass())
+		if err != nil {
+			return status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+		}
+		dcs = []*lvmdTypes.DeviceClass{dc}
+	}
+
+	ch := make(chan struct{}, 1)
+	num := s.addWatcher(ch)
+	defer s.removeWatcher(num)
+
+	known := make(map[string]map[string]*proto.LogicalVolume)
+	initial := true
+	for {
+		events := s.lvEvents(ctx, dcs, known)
+		if initial || len(events) != 0 {
+			if err := server.Send(&proto.WatchLVsResponse{Initial: initial, Events: events}); err != nil {
+				return err
+			}
+			initial = false
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+// lvEvents lists the logical volumes of the device classes and returns the changes from known, which is updated.
+// A device class failing to be listed, e.g. because its volume group is missing, is retried on the next notification
+// without reporting its volumes as deleted.
+func (s *vgService) lvEvents(ctx context.Context, dcs []*lvmdTypes.DeviceClass, known map[string]map[string]*proto.LogicalVolume) []*proto.LVEvent {
+	var events []*proto.LVEvent
+	for _, dc := range dcs {
+		res, err := s.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: dc.Name})
+		if err != nil {
+			log.FromContext(ctx).Error(err, "failed to list logical volumes to watch", "deviceClass", dc.Name)
+			continue
+		}
+
+		previous := known[dc.Name]
+		current := make(map[string]*proto.LogicalVolume, len(res.Volumes))
+		for _, lv := range res.Volumes {
+			current[lv.Name] = lv
+			old, ok := previous[lv.Name]
+			switch {
+			case !ok:
+				events = append(events, &proto.LVEvent{Type: LVEventCreated, DeviceClass: dc.Name, Volume: lv})
+			case old.SizeBytes != lv.SizeBytes:
+				events = append(events, &proto.LVEvent{Type: LVEventResized, DeviceClass: dc.Name, Volume: lv})
+			}
+		}
+		for name, lv := range previous {
+			if _, ok := current[name]; !ok {
+				events = append(events, &proto.LVEvent{Type: LVEventDeleted, DeviceClass: dc.Name, Volume: lv})
+			}
+		}
+		known[dc.Name] = current
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].DeviceClass != events[j].DeviceClass {
+			return events[i].DeviceClass < events[j].DeviceClass
+		}
+		return events[i].Volume.Name < events[j].Volume.Name
+	})
+	return events
+}
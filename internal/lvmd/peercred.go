@@ -0,0 +1,131 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/sys/unix"
+	"google.golang.org/grpc/credentials"
+)
+
+// RestrictPeers returns a listener accepting only the connections on the Unix domain socket of lis
+// from processes running as one of allowedUIDs or with the primary group one of allowedGIDs,
+// which are checked with SO_PEERCRED. Other connections are closed and logged.
+// All connections are accepted if both are empty.
+func RestrictPeers(lis net.Listener, allowedUIDs, allowedGIDs []uint32, logger logr.Logger) net.Listener {
+	if len(allowedUIDs) == 0 && len(allowedGIDs) == 0 {
+		return lis
+	}
+	uids := make(map[uint32]bool, len(allowedUIDs))
+	for _, uid := range allowedUIDs {
+		uids[uid] = true
+	}
+	gids := make(map[uint32]bool, len(allowedGIDs))
+	for _, gid := range allowedGIDs {
+		gids[gid] = true
+	}
+	return &peerCredListener{Listener: lis, uids: uids, gids: gids, logger: logger}
+}
+
+type peerCredListener struct {
+	net.Listener
+	uids   map[uint32]bool
+	gids   map[uint32]bool
+	logger logr.Logger
+}
+
+// Accept implements net.Listener.
+func (l *peerCredListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		cred, err := peerCredentials(conn)
+		if err != nil {
+			l.logger.Error(err, "failed to get the credentials of the peer")
+			conn.Close()
+			continue
+		}
+		if !l.uids[cred.Uid] && !l.gids[cred.Gid] {
+			l.logger.Info("rejected connection from a peer not allowed", "pid", cred.Pid, "uid", cred.Uid, "gid", cred.Gid)
+			conn.Close()
+			continue
+		}
+		return conn, nil
+	}
+}
+
+func peerCredentials(conn net.Conn) (*unix.Ucred, error) {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, &net.OpError{Op: "getsockopt", Net: conn.LocalAddr().Network(), Err: unix.ENOTSOCK}
+	}
+	rc, err := uc.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	err = rc.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cred, credErr
+}
+
+// PeerCredInfo is the credentials of the process connected to the Unix domain socket.
+// It is the AuthInfo of the peers of the connections with UnixPeerCredentials.
+type PeerCredInfo struct {
+	credentials.CommonAuthInfo
+	PID int32
+	UID uint32
+	GID uint32
+}
+
+// AuthType implements credentials.AuthInfo.
+func (PeerCredInfo) AuthType() string {
+	return "peercred"
+}
+
+// UnixPeerCredentials returns the transport credentials of the gRPC server on a Unix domain socket
+// attaching the credentials of the peer process to the peer of the requests as PeerCredInfo.
+// The connections are neither authenticated nor encrypted.
+func UnixPeerCredentials() credentials.TransportCredentials {
+	return unixPeerCredentials{}
+}
+
+type unixPeerCredentials struct{}
+
+func (unixPeerCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("peer credentials are only for servers")
+}
+
+func (unixPeerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	cred, err := peerCredentials(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, PeerCredInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		PID:            cred.Pid,
+		UID:            cred.Uid,
+		GID:            cred.Gid,
+	}, nil
+}
+
+func (unixPeerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+func (c unixPeerCredentials) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (unixPeerCredentials) OverrideServerName(string) error {
+	return nil
+}
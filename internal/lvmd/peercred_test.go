@@ -0,0 +1,104 @@
+package lvmd
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+)
+
+func TestRestrictPeers(t *testing.T) {
+	uid, gid := uint32(os.Getuid()), uint32(os.Getgid())
+
+	testCases := []struct {
+		name        string
+		allowedUIDs []uint32
+		allowedGIDs []uint32
+		accepted    bool
+	}{
+		{name: "allowed uid", allowedUIDs: []uint32{uid + 1, uid}, accepted: true},
+		{name: "allowed gid", allowedUIDs: []uint32{uid + 1}, allowedGIDs: []uint32{gid}, accepted: true},
+		{name: "not allowed", allowedUIDs: []uint32{uid + 1}, allowedGIDs: []uint32{gid + 1}, accepted: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			socket := filepath.Join(t.TempDir(), "lvmd.sock")
+			lis, err := net.Listen("unix", socket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lis = RestrictPeers(lis, tc.allowedUIDs, tc.allowedGIDs, testr.New(t))
+			defer lis.Close()
+			go func() {
+				for {
+					conn, err := lis.Accept()
+					if err != nil {
+						return
+					}
+					_, _ = conn.Write([]byte("ok"))
+					conn.Close()
+				}
+			}()
+
+			conn, err := net.Dial("unix", socket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			if err := conn.SetReadDeadline(time.Now().Add(10 * time.Second)); err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if accepted := string(b) == "ok"; accepted != tc.accepted {
+				t.Errorf("expected accepted=%v, got %q", tc.accepted, b)
+			}
+		})
+	}
+
+	lis, err := net.Listen("unix", filepath.Join(t.TempDir(), "lvmd.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	if RestrictPeers(lis, nil, nil, testr.New(t)) != lis {
+		t.Error("the listener should not be wrapped without allowlists")
+	}
+}
+
+func TestUnixPeerCredentials(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "lvmd.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lis.Close()
+	client, err := net.Dial("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	conn, err := lis.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, authInfo, err := UnixPeerCredentials().ServerHandshake(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, ok := authInfo.(PeerCredInfo)
+	if !ok {
+		t.Fatalf("unexpected auth info: %#v", authInfo)
+	}
+	if int(info.PID) != os.Getpid() || int(info.UID) != os.Getuid() || int(info.GID) != os.Getgid() {
+		t.Errorf("unexpected credentials: %+v", info)
+	}
+}
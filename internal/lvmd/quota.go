@@ -0,0 +1,112 @@
+package lvmd
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// GetMaxSize returns max-size of the device-class in bytes, or 0 if the size of the volumes is not limited.
+func GetMaxSize(dc *lvmdTypes.DeviceClass) uint64 {
+	if dc.MaxSize == "" {
+		return 0
+	}
+	q, err := resource.ParseQuantity(dc.MaxSize)
+	if err != nil || q.Sign() <= 0 {
+		// rejected by ValidateDeviceClasses.
+		return 0
+	}
+	return uint64(q.Value())
+}
+
+func validateMaxSize(dc *lvmdTypes.DeviceClass) error {
+	q, err := resource.ParseQuantity(dc.MaxSize)
+	if err != nil {
+		return fmt.Errorf("max-size should be a quantity such as \"100Gi\": %s: %w", dc.Name, err)
+	}
+	if q.Sign() <= 0 {
+		return fmt.Errorf("max-size should be greater than 0: %s", dc.Name)
+	}
+	return nil
+}
+
+// checkMaxSize returns codes.ResourceExhausted if requested bytes exceed max-size of the device-class.
+func checkMaxSize(dc *lvmdTypes.DeviceClass, requested uint64) error {
+	if maxSize := GetMaxSize(dc); maxSize != 0 && requested > maxSize {
+		return status.Errorf(codes.ResourceExhausted, "requested size exceeds max-size of device-class %s: max-size=%d, requested=%d",
+			dc.Name, maxSize, requested)
+	}
+	return nil
+}
+
+// checkVolumeQuota returns codes.ResourceExhausted if the device-class has as many volumes as max-volumes,
+// unless the logical volume name is one of them, so that a retried request fails as without the quota.
+// On success, the device-class is locked until unlock is called after creating the volume,
+// so that concurrent requests do not exceed the quota together.
+func (s *lvService) checkVolumeQuota(ctx context.Context, dc *lvmdTypes.DeviceClass, name string) (unlock func(), err error) {
+	if dc.MaxVolumes == 0 {
+		return func() {}, nil
+	}
+	mu := s.quotaLock(dc.Name)
+	mu.Lock()
+	defer func() {
+		if err != nil {
+			mu.Unlock()
+		}
+	}()
+
+	names, err := s.volumeNames(ctx, dc)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to list volumes", "deviceClass", dc.Name)
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, internalError(err)
+	}
+	if len(names) >= int(dc.MaxVolumes) && !containsString(names, name) {
+		return nil, status.Errorf(codes.ResourceExhausted, "device-class %s has reached max-volumes: max-volumes=%d, volumes=%d",
+			dc.Name, dc.MaxVolumes, len(names))
+	}
+	return mu.Unlock, nil
+}
+
+// volumeNames returns the names of the logical volumes of the device-class.
+func (s *lvService) volumeNames(ctx context.Context, dc *lvmdTypes.DeviceClass) ([]string, error) {
+	var names []string
+	if dc.Type == lvmdTypes.TypeRaw {
+		vols, err := rawVolumes(ctx, dc)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range vols {
+			names = append(names, v.GetName())
+		}
+		return names, nil
+	}
+	lvs, _, err := s.dcmapper.deviceClassVolumes(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	for name := range lvs {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// quotaLock returns the lock of the device-class serializing the requests checking max-volumes.
+func (s *lvService) quotaLock(dcName string) *sync.Mutex {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	mu, ok := s.quotaLocks[dcName]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.quotaLocks[dcName] = mu
+	}
+	return mu
+}
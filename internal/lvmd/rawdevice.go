@@ -0,0 +1,408 @@
+package lvmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// defaultRawStateDir is the directory of the state files of the raw device-classes without state-file.
+const defaultRawStateDir = "/var/lib/topolvm/raw"
+
+// The lv_attr reported for the volumes of raw device-classes, which are always active unless the device is missing.
+const (
+	rawAttrActive  = "-wi-a-----"
+	rawAttrMissing = "-wi-----p-"
+)
+
+// rawMu serializes claiming and releasing the devices of the raw device-classes,
+// as the patterns of several device-classes may match the same device.
+var rawMu sync.Mutex
+
+// rawClaim is a device claimed by a volume of a raw device-class.
+type rawClaim struct {
+	// Volume is the name of the volume.
+	Volume string `json:"volume"`
+	// DeviceID is the ID of the device, which stays the same when the path of the device changes.
+	DeviceID string `json:"device-id"`
+	// Size is the size of the device when it was claimed, reported while the device is missing.
+	Size uint64 `json:"size"`
+	// Tags are the tags of the volume.
+	Tags []string `json:"tags,omitempty"`
+}
+
+// rawState is the content of the state file of a raw device-class.
+type rawState struct {
+	Claims []*rawClaim `json:"claims"`
+}
+
+// rawStateFile returns the state file of the raw device-class.
+func rawStateFile(dc *lvmdTypes.DeviceClass) string {
+	if dc.Raw.StateFile != "" {
+		return dc.Raw.StateFile
+	}
+	return filepath.Join(defaultRawStateDir, dc.Name+".json")
+}
+
+// loadRawState reads the state file of the raw device-class, which is empty if the file does not exist.
+func loadRawState(dc *lvmdTypes.DeviceClass) (*rawState, error) {
+	data, err := os.ReadFile(rawStateFile(dc))
+	if errors.Is(err, os.ErrNotExist) {
+		return &rawState{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	state := &rawState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", rawStateFile(dc), err)
+	}
+	return state, nil
+}
+
+// save replaces the state file of the raw device-class atomically.
+func (st *rawState) save(dc *lvmdTypes.DeviceClass) error {
+	file := rawStateFile(dc)
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func (st *rawState) find(volume string) *rawClaim {
+	for _, claim := range st.Claims {
+		if claim.Volume == volume {
+			return claim
+		}
+	}
+	return nil
+}
+
+func (st *rawState) remove(volume string) {
+	for i, claim := range st.Claims {
+		if claim.Volume == volume {
+			st.Claims = append(st.Claims[:i], st.Claims[i+1:]...)
+			return
+		}
+	}
+}
+
+// rawDevices returns the devices matching the patterns of the raw device-class by their ID.
+func rawDevices(ctx context.Context, dc *lvmdTypes.DeviceClass) (map[string]command.BlockDevice, error) {
+	all, err := command.ListBlockDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	devices := make(map[string]command.BlockDevice)
+	for _, device := range all {
+		for _, pattern := range dc.Raw.Devices {
+			if ok, _ := filepath.Match(pattern, device.Path); ok {
+				devices[device.ID] = device
+				break
+			}
+		}
+	}
+	return devices, nil
+}
+
+// freeRawDevices returns the devices of the raw device-class which are neither claimed by a volume of any
+// raw device-class nor in use on the node, sorted by size.
+func (m DeviceClassManager) freeRawDevices(ctx context.Context, dc *lvmdTypes.DeviceClass) ([]command.BlockDevice, error) {
+	devices, err := rawDevices(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range m.DeviceClasses() {
+		if other.Type != lvmdTypes.TypeRaw {
+			continue
+		}
+		state, err := loadRawState(other)
+		if err != nil {
+			return nil, err
+		}
+		for _, claim := range state.Claims {
+			delete(devices, claim.DeviceID)
+		}
+	}
+
+	free := make([]command.BlockDevice, 0, len(devices))
+	for _, device := range devices {
+		// physical volumes, partitioned disks and mounted devices are in use.
+		if device.FSType == "LVM2_member" || device.HasChildren || device.MountPoint != "" {
+			continue
+		}
+		free = append(free, device)
+	}
+	sort.Slice(free, func(i, j int) bool {
+		if free[i].Size != free[j].Size {
+			return free[i].Size < free[j].Size
+		}
+		return free[i].Path < free[j].Path
+	})
+	return free, nil
+}
+
+// rawCapacity returns the size of the largest free device of the raw device-class, which is the largest volume
+// that can be created, and the total size of its devices.
+func (m DeviceClassManager) rawCapacity(ctx context.Context, dc *lvmdTypes.DeviceClass) (free uint64, size uint64, err error) {
+	devices, err := rawDevices(ctx, dc)
+	if err != nil {
+		return 0, 0, err
+	}
+	for _, device := range devices {
+		size += device.Size
+	}
+	freeDevices, err := m.freeRawDevices(ctx, dc)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(freeDevices) != 0 {
+		free = freeDevices[len(freeDevices)-1].Size
+	}
+	return free, size, nil
+}
+
+// rawVolume returns the volume of the claim. device is nil if the device is missing.
+func rawVolume(claim *rawClaim, device *command.BlockDevice) *proto.LogicalVolume {
+	lv := &proto.LogicalVolume{
+		Name:           claim.Volume,
+		SizeGb:         (claim.Size + (1 << 30) - 1) >> 30,
+		SizeBytes:      int64(claim.Size),
+		Tags:           claim.Tags,
+		Attr:           rawAttrActive,
+		AllocatedBytes: claim.Size,
+	}
+	if device == nil {
+		lv.Attr = rawAttrMissing
+		lv.HealthError = fmt.Sprintf("device %s is not found", claim.DeviceID)
+		return lv
+	}
+	lv.DevMajor, lv.DevMinor = device.Major, device.Minor
+	return lv
+}
+
+// rawVolumes returns the volumes of the raw device-class.
+func rawVolumes(ctx context.Context, dc *lvmdTypes.DeviceClass) ([]*proto.LogicalVolume, error) {
+	rawMu.Lock()
+	defer rawMu.Unlock()
+
+	state, err := loadRawState(dc)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := rawDevices(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	vols := make([]*proto.LogicalVolume, 0, len(state.Claims))
+	for _, claim := range state.Claims {
+		var device *command.BlockDevice
+		if d, ok := devices[claim.DeviceID]; ok {
+			device = &d
+		}
+		vols = append(vols, rawVolume(claim, device))
+	}
+	return vols, nil
+}
+
+// findRawVolume returns the volume of the raw device-class, or NotFound.
+func findRawVolume(ctx context.Context, dc *lvmdTypes.DeviceClass, name string) (*proto.LogicalVolume, error) {
+	vols, err := rawVolumes(ctx, dc)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to list raw volumes", "deviceClass", dc.Name)
+		return nil, internalError(err)
+	}
+	for _, lv := range vols {
+		if lv.Name == name {
+			return lv, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", name)
+}
+
+// createRawLV claims the smallest free device of the raw device-class fitting the requested size.
+func (s *lvService) createRawLV(ctx context.Context, dc *lvmdTypes.DeviceClass, req *proto.CreateLVRequest) (*proto.CreateLVResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	if req.GetLvcreateOptionClass() != "" || len(req.GetLvcreateOptions()) != 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "lvcreate options are not supported for raw device class %s", dc.Name)
+	}
+
+	var requested uint64
+	if req.SizeBytes > 0 {
+		requested = uint64(req.GetSizeBytes())
+	} else {
+		//lint:ignore SA1019 gRPC API has two fields for Gb and Bytes, both are valid until next minor
+		requested = req.GetSizeGb() << 30
+	}
+
+	rawMu.Lock()
+	defer rawMu.Unlock()
+
+	state, err := loadRawState(dc)
+	if err != nil {
+		logger.Error(err, "failed to load raw device state")
+		return nil, internalError(err)
+	}
+	if state.find(req.GetName()) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "logical volume %s already exists", req.GetName())
+	}
+	free, err := s.dcmapper.freeRawDevices(ctx, dc)
+	if err != nil {
+		logger.Error(err, "failed to list free raw devices")
+		return nil, internalError(err)
+	}
+	var device *command.BlockDevice
+	var largest uint64
+	for i := range free {
+		if free[i].Size >= requested {
+			device = &free[i]
+			break
+		}
+		if free[i].Size > largest {
+			largest = free[i].Size
+		}
+	}
+	if device == nil {
+		return nil, insufficientSpaceError(&proto.InsufficientSpace{
+			DeviceClass:    dc.Name,
+			RequestedBytes: requested,
+			FreeBytes:      largest,
+		})
+	}
+
+	claim := &rawClaim{Volume: req.GetName(), DeviceID: device.ID, Size: device.Size, Tags: req.GetTags()}
+	state.Claims = append(state.Claims, claim)
+	if err := state.save(dc); err != nil {
+		logger.Error(err, "failed to save raw device state")
+		return nil, internalError(err)
+	}
+	s.notify()
+
+	logger.Info("claimed a raw device", "device", device.Path, "id", device.ID, "size", device.Size, "requested", requested)
+	return &proto.CreateLVResponse{Volume: rawVolume(claim, device)}, nil
+}
+
+// removeRawLV wipes the device of the volume according to the wipe policy and releases it.
+func (s *lvService) removeRawLV(ctx context.Context, dc *lvmdTypes.DeviceClass, req *proto.RemoveLVRequest) (*proto.Empty, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+	wipe, err := wipePolicy(dc, req.GetWipeOnDelete())
+	if err != nil {
+		return nil, err
+	}
+
+	rawMu.Lock()
+	defer rawMu.Unlock()
+
+	state, err := loadRawState(dc)
+	if err != nil {
+		logger.Error(err, "failed to load raw device state")
+		return nil, internalError(err)
+	}
+	claim := state.find(req.GetName())
+	if claim == nil {
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
+	}
+
+	if wipe == lvmdTypes.WipeDiscard || wipe == lvmdTypes.WipeZero {
+		devices, err := rawDevices(ctx, dc)
+		if err != nil {
+			logger.Error(err, "failed to list raw devices")
+			return nil, internalError(err)
+		}
+		// a missing device cannot be wiped, but it is released so that the volume can be deleted.
+		if device, ok := devices[claim.DeviceID]; ok {
+			if err := command.DiscardDevice(ctx, device.Path, wipe == lvmdTypes.WipeZero); err != nil {
+				logger.Error(err, "failed to wipe raw device", "device", device.Path, "wipe", wipe)
+				return nil, internalError(err)
+			}
+		} else {
+			logger.Info("raw device to wipe is not found", "id", claim.DeviceID)
+		}
+	}
+
+	state.remove(req.GetName())
+	if err := state.save(dc); err != nil {
+		logger.Error(err, "failed to save raw device state")
+		return nil, internalError(err)
+	}
+	s.notify()
+
+	logger.Info("released a raw device", "id", claim.DeviceID)
+	return &proto.Empty{}, nil
+}
+
+// resizeRawLV succeeds if the device of the volume is not smaller than the requested size, as devices cannot be resized.
+func (s *lvService) resizeRawLV(ctx context.Context, dc *lvmdTypes.DeviceClass, req *proto.ResizeLVRequest) (*proto.ResizeLVResponse, error) {
+	lv, err := findRawVolume(ctx, dc, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	requested := req.GetSizeBytes()
+	if requested <= 0 {
+		//lint:ignore SA1019 gRPC API has two fields for Gb and Bytes, both are valid until next minor
+		requested = int64(req.GetSizeGb() << 30)
+	}
+	if requested > lv.SizeBytes {
+		return nil, status.Errorf(codes.OutOfRange, "raw device of %s has %d bytes and cannot be expanded to %d bytes",
+			req.GetName(), lv.SizeBytes, requested)
+	}
+	return &proto.ResizeLVResponse{}, nil
+}
+
+// changeRawLVTags changes the tags of the volume recorded in the state file.
+func (s *lvService) changeRawLVTags(ctx context.Context, dc *lvmdTypes.DeviceClass, req *proto.ChangeLVTagsRequest) (*proto.ChangeLVTagsResponse, error) {
+	logger := log.FromContext(ctx).WithValues("name", req.GetName())
+
+	rawMu.Lock()
+	defer rawMu.Unlock()
+
+	state, err := loadRawState(dc)
+	if err != nil {
+		logger.Error(err, "failed to load raw device state")
+		return nil, internalError(err)
+	}
+	claim := state.find(req.GetName())
+	if claim == nil {
+		return nil, status.Errorf(codes.NotFound, "logical volume %s is not found", req.GetName())
+	}
+
+	removed := make(map[string]bool, len(req.GetRemoveTags()))
+	for _, tag := range req.GetRemoveTags() {
+		removed[tag] = true
+	}
+	tags := make([]string, 0, len(claim.Tags)+len(req.GetAddTags()))
+	seen := make(map[string]bool)
+	for _, tag := range append(claim.Tags, req.GetAddTags()...) {
+		if !removed[tag] && !seen[tag] {
+			tags = append(tags, tag)
+			seen[tag] = true
+		}
+	}
+	claim.Tags = tags
+	if err := state.save(dc); err != nil {
+		logger.Error(err, "failed to save raw device state")
+		return nil, internalError(err)
+	}
+
+	logger.Info("changed tags of a raw volume", "add", req.GetAddTags(), "remove", req.GetRemoveTags(), "tags", tags)
+	return &proto.ChangeLVTagsResponse{Tags: tags}, nil
+}
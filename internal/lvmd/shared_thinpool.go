@@ -0,0 +1,129 @@
+package lvmd
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+// deviceClassTagPrefix prefixes the tag recording the device-class of the thin volumes in a thin pool
+// shared by device-classes.
+const deviceClassTagPrefix = "topolvm.io/device-class="
+
+// deviceClassTag returns the tag of the thin volumes of the device-class in a shared thin pool.
+func deviceClassTag(dcName string) string {
+	return deviceClassTagPrefix + dcName
+}
+
+// sharedThinDeviceClass returns the device-class of the thin pool of dc whose quota applies to the volumes
+// provisioned on dc, or nil if the thin pool is not shared. It is dc itself, or the first device-class of
+// the pool if dc provisions thin volumes by overriding the type.
+func (m DeviceClassManager) sharedThinDeviceClass(dc *lvmdTypes.DeviceClass) *lvmdTypes.DeviceClass {
+	if dc.ThinPoolConfig == nil {
+		return nil
+	}
+	shared := m.thinDeviceClassesByPoolName[dc.VolumeGroup+"/"+dc.ThinPoolConfig.Name]
+	if len(shared) < 2 {
+		return nil
+	}
+	for _, s := range shared {
+		if s.Name == dc.Name {
+			return s
+		}
+	}
+	return shared[0]
+}
+
+// thinVolumeDeviceClass returns the device-class a thin volume in a shared pool belongs to by its tag.
+// Volumes without the tag, e.g. created before the pool was shared, belong to the first device-class of the pool.
+func (m DeviceClassManager) thinVolumeDeviceClass(vgName, poolName string, lv *command.LogicalVolume) *lvmdTypes.DeviceClass {
+	shared := m.thinDeviceClassesByPoolName[vgName+"/"+poolName]
+	if len(shared) == 0 {
+		return nil
+	}
+	for _, tag := range lv.Tags() {
+		name, ok := strings.CutPrefix(tag, deviceClassTagPrefix)
+		if !ok {
+			continue
+		}
+		for _, s := range shared {
+			if s.Name == name {
+				return s
+			}
+		}
+	}
+	return shared[0]
+}
+
+// ownsThinVolume returns true if the thin volume in the pool of dc belongs to dc.
+// Every volume belongs to dc unless the pool is shared.
+func (m DeviceClassManager) ownsThinVolume(dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume) bool {
+	shared := m.sharedThinDeviceClass(dc)
+	if shared == nil {
+		return true
+	}
+	return m.thinVolumeDeviceClass(dc.VolumeGroup, dc.ThinPoolConfig.Name, lv) == shared
+}
+
+// thinVolumeTags returns the tags to create a thin volume on dc with, which record the device-class
+// if the pool is shared.
+func (m DeviceClassManager) thinVolumeTags(dc *lvmdTypes.DeviceClass, tags []string) []string {
+	shared := m.sharedThinDeviceClass(dc)
+	if shared == nil {
+		return tags
+	}
+	return append(append([]string{}, tags...), deviceClassTag(shared.Name))
+}
+
+// thinSnapshotDeviceClass returns the device-class of the thin volume lv in a shared pool, which its thin snapshots
+// belong to as well, or nil if the pool of lv is not shared.
+func (m DeviceClassManager) thinSnapshotDeviceClass(ctx context.Context, lv *command.LogicalVolume) (*lvmdTypes.DeviceClass, *command.ThinPool, error) {
+	pool, err := lv.Pool(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	vgName := pool.VG().Name()
+	if len(m.thinDeviceClassesByPoolName[vgName+"/"+pool.Name()]) < 2 {
+		return nil, pool, nil
+	}
+	return m.thinVolumeDeviceClass(vgName, pool.Name(), lv), pool, nil
+}
+
+// thinFreeBytes returns the virtual bytes dc can still provision in the thin pool, limited by the overprovision
+// ratio of dc over the whole pool and the virtual-quota-gb of the device-class if set.
+func (m DeviceClassManager) thinFreeBytes(ctx context.Context, dc *lvmdTypes.DeviceClass, pool *command.ThinPool, tpu *command.ThinPoolUsage) (uint64, error) {
+	free := subtractOrZero(uint64(math.Floor(dc.ThinPoolConfig.OverprovisionRatio*float64(tpu.SizeBytes))), tpu.VirtualBytes)
+
+	quota := dc.ThinPoolConfig.VirtualQuotaGB
+	shared := m.sharedThinDeviceClass(dc)
+	if shared != nil {
+		quota = shared.ThinPoolConfig.VirtualQuotaGB
+	}
+	if quota == nil {
+		return free, nil
+	}
+	lvs, err := pool.ListVolumes(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var used uint64
+	for _, lv := range lvs {
+		if shared == nil || m.thinVolumeDeviceClass(dc.VolumeGroup, pool.Name(), lv) == shared {
+			used += lv.Size()
+		}
+	}
+	if quotaFree := subtractOrZero(*quota<<30, used); quotaFree < free {
+		return quotaFree, nil
+	}
+	return free, nil
+}
+
+func subtractOrZero(a, b uint64) uint64 {
+	if a < b {
+		return 0
+	}
+	return a - b
+}
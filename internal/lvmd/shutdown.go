@@ -0,0 +1,99 @@
+package lvmd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultShutdownGracePeriod is how long lvmd waits for the RPCs in flight on shutdown by default.
+// It is shorter than the default termination grace period of Kubernetes, 30 seconds,
+// so that lvmd cancels the RPCs still running rather than being killed.
+const DefaultShutdownGracePeriod = 25 * time.Second
+
+// ShutdownTracker counts the unary RPCs in flight, so that lvmd waits for them on shutdown instead of
+// killing lvcreate or lvremove halfway. After Shutdown, new RPCs fail with codes.Unavailable,
+// which the clients retry after lvmd restarts.
+type ShutdownTracker struct {
+	mu       sync.Mutex
+	inFlight int
+	stopping bool
+	idle     chan struct{}
+}
+
+// NewShutdownTracker returns a new ShutdownTracker.
+func NewShutdownTracker() *ShutdownTracker {
+	return &ShutdownTracker{idle: make(chan struct{})}
+}
+
+func (t *ShutdownTracker) start() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopping {
+		return false
+	}
+	t.inFlight++
+	return true
+}
+
+func (t *ShutdownTracker) done() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.inFlight--
+	if t.stopping && t.inFlight == 0 {
+		close(t.idle)
+	}
+}
+
+// UnaryInterceptor returns a unary interceptor tracking the RPCs and rejecting them after Shutdown.
+func (t *ShutdownTracker) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !t.start() {
+			return nil, status.Errorf(codes.Unavailable, "lvmd is shutting down, %s rejected", info.FullMethod)
+		}
+		defer t.done()
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor returns a stream interceptor rejecting new streams after Shutdown.
+// Streams such as Watch do not change the volumes, so they are not waited for.
+func (t *ShutdownTracker) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		t.mu.Lock()
+		stopping := t.stopping
+		t.mu.Unlock()
+		if stopping {
+			return status.Errorf(codes.Unavailable, "lvmd is shutting down, %s rejected", info.FullMethod)
+		}
+		return handler(srv, ss)
+	}
+}
+
+// Shutdown rejects new RPCs and waits for the RPCs in flight to finish for up to gracePeriod.
+// It returns the number of the RPCs still running, which is 0 if all have finished.
+func (t *ShutdownTracker) Shutdown(gracePeriod time.Duration) int {
+	t.mu.Lock()
+	if !t.stopping {
+		t.stopping = true
+		if t.inFlight == 0 {
+			close(t.idle)
+		}
+	}
+	t.mu.Unlock()
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+	select {
+	case <-t.idle:
+	case <-timer.C:
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.inFlight
+}
@@ -0,0 +1,77 @@
+package lvmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShutdownTracker(t *testing.T) {
+	tracker := NewShutdownTracker()
+	interceptor := tracker.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.LVService/CreateLV"}
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	errCh := make(chan error)
+	go func() {
+		_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			close(started)
+			<-release
+			return nil, nil
+		})
+		errCh <- err
+	}()
+	<-started
+
+	if remaining := tracker.Shutdown(10 * time.Millisecond); remaining != 1 {
+		t.Errorf("expected 1 request still running after the grace period, got %d", remaining)
+	}
+	_, err := interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+		t.Error("the request should be rejected after shutdown")
+		return nil, nil
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", err)
+	}
+	streamErr := tracker.StreamInterceptor()(nil, nil, &grpc.StreamServerInfo{FullMethod: "/proto.VGService/Watch"},
+		func(any, grpc.ServerStream) error {
+			t.Error("the stream should be rejected after shutdown")
+			return nil
+		})
+	if status.Code(streamErr) != codes.Unavailable {
+		t.Errorf("expected Unavailable, got %v", streamErr)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+	if remaining := tracker.Shutdown(10 * time.Second); remaining != 0 {
+		t.Errorf("expected the request to finish, got %d running", remaining)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("the request in flight should succeed: %v", err)
+	}
+}
+
+func TestShutdownTrackerIdle(t *testing.T) {
+	tracker := NewShutdownTracker()
+	_, err := tracker.UnaryInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/proto.LVService/RemoveLV"},
+		func(context.Context, any) (any, error) { return nil, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	if remaining := tracker.Shutdown(10 * time.Second); remaining != 0 {
+		t.Errorf("expected no request running, got %d", remaining)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected shutdown without requests to return immediately, took %s", elapsed)
+	}
+}
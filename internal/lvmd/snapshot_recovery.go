@@ -0,0 +1,130 @@
+package lvmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pendingSnapshotTagPrefix prefixes the tag of a snapshot whose creation has not completed yet.
+// CreateLVSnapshot adds it with lvcreate and removes it after resizing and activating the snapshot,
+// so the tag is the token of a request interrupted in between, e.g. by a restart of lvmd.
+// It records the size and the access type of the request, e.g. "topolvm.io/pending-snapshot=2147483648:rw".
+const pendingSnapshotTagPrefix = "topolvm.io/pending-snapshot="
+
+// pendingSnapshotTag returns the tag of a snapshot being created with size bytes and access.
+func pendingSnapshotTag(size uint64, access string) string {
+	return fmt.Sprintf("%s%d:%s", pendingSnapshotTagPrefix, size, access)
+}
+
+// pendingSnapshot returns the tag of the pending creation of lv and the size and the access type it records.
+// ok is false if lv has no such tag.
+func pendingSnapshot(lv *command.LogicalVolume) (tag string, size uint64, access string, ok bool, err error) {
+	for _, tag := range lv.Tags() {
+		value, found := strings.CutPrefix(tag, pendingSnapshotTagPrefix)
+		if !found {
+			continue
+		}
+		sizeValue, access, found := strings.Cut(value, ":")
+		if !found {
+			return tag, 0, "", true, fmt.Errorf("invalid tag %q", tag)
+		}
+		size, err := strconv.ParseUint(sizeValue, 10, 64)
+		if err != nil {
+			return tag, 0, "", true, fmt.Errorf("invalid size of tag %q: %w", tag, err)
+		}
+		return tag, size, access, true, nil
+	}
+	return "", 0, "", false, nil
+}
+
+// RecoverSnapshots completes or rolls back the snapshots of the device-classes whose creation was interrupted
+// between lvcreate and the end of CreateLVSnapshot, as recorded by their pending tag. It is called on start
+// before serving any request.
+//
+// A pending snapshot is completed by resizing and activating it as requested, the same steps as CreateLVSnapshot.
+// If they fail, the snapshot is removed as CreateLVSnapshot does on failure, unless it is open,
+// which means it has been published already and is kept as is.
+func RecoverSnapshots(ctx context.Context, deviceClasses []*lvmdTypes.DeviceClass) error {
+	seen := make(map[string]bool)
+	for _, dc := range deviceClasses {
+		if dc.Type == lvmdTypes.TypeRaw {
+			continue
+		}
+		vgs, err := deviceClassVolumeGroups(ctx, dc)
+		if err != nil {
+			return err
+		}
+		for _, vg := range vgs {
+			if seen[vg.Name()] {
+				continue
+			}
+			seen[vg.Name()] = true
+			if err := recoverVolumeGroupSnapshots(ctx, vg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func recoverVolumeGroupSnapshots(ctx context.Context, vg *command.VolumeGroup) error {
+	lvs, err := vg.ListVolumes(ctx)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(lvs))
+	for name := range lvs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := recoverSnapshot(ctx, vg, lvs[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func recoverSnapshot(ctx context.Context, vg *command.VolumeGroup, lv *command.LogicalVolume) error {
+	tag, size, access, ok, err := pendingSnapshot(lv)
+	if !ok {
+		return nil
+	}
+	logger := log.FromContext(ctx).WithValues("name", lv.Name(), "volume_group", vg.Name(), "tag", tag)
+	if err == nil {
+		err = completeSnapshot(ctx, lv, tag, size, access)
+	}
+	if err == nil {
+		logger.Info("completed the interrupted creation of a snapshot", "decision", "complete", "size", size, "accessType", access)
+		return nil
+	}
+	if lv.IsOpen() {
+		logger.Error(err, "failed to complete the interrupted creation of a snapshot in use", "decision", "keep")
+		return nil
+	}
+	if err := vg.RemoveVolume(ctx, lv.Name()); err != nil {
+		logger.Error(err, "failed to roll back the interrupted creation of a snapshot")
+		return err
+	}
+	logger.Info("rolled back the interrupted creation of a snapshot", "decision", "rollback", "reason", err.Error())
+	return nil
+}
+
+// completeSnapshot resizes and activates lv as CreateLVSnapshot does after lvcreate, and then removes tag.
+// Each step does nothing if it has been done before the interruption.
+func completeSnapshot(ctx context.Context, lv *command.LogicalVolume, tag string, size uint64, access string) error {
+	if err := lv.Resize(ctx, size); err != nil {
+		return err
+	}
+	if err := lv.Activate(ctx, access); err != nil {
+		return err
+	}
+	return lv.ChangeTags(ctx, nil, []string{tag})
+}
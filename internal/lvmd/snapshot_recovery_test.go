@@ -0,0 +1,110 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestRecoverSnapshotsWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 8<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool", 4<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "thick", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	thin, err := vg.FindVolume(ctx, "thin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	thick, err := vg.FindVolume(ctx, "thick")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lvmd stopped after lvcreate of the snapshots.
+	if err := thin.ThinSnapshot(ctx, "interrupted", []string{pendingSnapshotTag(2<<30, "rw"), "user"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := thin.ThinSnapshot(ctx, "invalid-tag", []string{pendingSnapshotTagPrefix + "2Gi"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := thick.Snapshot(ctx, "invalid-access", 1<<30, []string{pendingSnapshotTag(1<<30, "none")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := thin.ThinSnapshot(ctx, "completed", []string{"user"}); err != nil {
+		t.Fatal(err)
+	}
+
+	deviceClasses := []*lvmdTypes.DeviceClass{
+		{Name: "thin", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool", OverprovisionRatio: 10}},
+		{Name: "thick", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThick},
+	}
+	if err := RecoverSnapshots(ctx, deviceClasses); err != nil {
+		t.Fatal(err)
+	}
+
+	interrupted, err := vg.FindVolume(ctx, "interrupted")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interrupted.Size() != 2<<30 || !interrupted.IsActive() || !reflect.DeepEqual(interrupted.Tags(), []string{"user"}) {
+		t.Errorf("expected the interrupted snapshot to be completed: size=%d, active=%v, tags=%v",
+			interrupted.Size(), interrupted.IsActive(), interrupted.Tags())
+	}
+	for _, name := range []string{"invalid-tag", "invalid-access"} {
+		if _, err := vg.FindVolume(ctx, name); !errors.Is(err, command.ErrNotFound) {
+			t.Errorf("expected %s to be rolled back: %v", name, err)
+		}
+	}
+	completed, err := vg.FindVolume(ctx, "completed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if completed.Size() != 1<<30 || !reflect.DeepEqual(completed.Tags(), []string{"user"}) {
+		t.Errorf("expected the snapshot without the pending tag to be untouched: size=%d, tags=%v",
+			completed.Size(), completed.Tags())
+	}
+
+	// CreateLVSnapshot removes the pending tag on success.
+	lvService := NewLVService(NewDeviceClassManager(deviceClasses), NewLvcreateOptionClassManager(nil), nil)
+	_, err = lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+		Name:         "created",
+		DeviceClass:  "thin",
+		SourceVolume: "thin",
+		SizeBytes:    2 << 30,
+		AccessType:   "ro",
+		Tags:         []string{"user"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := vg.FindVolume(ctx, "created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, ok, _ := pendingSnapshot(created); ok {
+		t.Errorf("expected the pending tag to be removed: %v", created.Tags())
+	}
+}
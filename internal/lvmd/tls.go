@@ -0,0 +1,59 @@
+package lvmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ServerTLSConfig returns the TLS configuration of the TCP listener of lvmd.
+// Clients must present a certificate signed by the CAs in caFile.
+func ServerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, pool, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+// ClientTLSConfig returns the TLS configuration to connect to the TCP listener of lvmd.
+// The server certificate must be signed by the CAs in caFile and valid for serverName,
+// which defaults to the host of the address dialed if empty.
+func ClientTLSConfig(certFile, keyFile, caFile, serverName string) (*tls.Config, error) {
+	cert, pool, err := loadTLSFiles(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS13,
+	}, nil
+}
+
+func loadTLSFiles(certFile, keyFile, caFile string) (tls.Certificate, *x509.CertPool, error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return tls.Certificate{}, nil, errors.New("certificate, key and CA files are required for mutual TLS")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load the certificate: %w", err)
+	}
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("failed to load the CA certificates: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fmt.Errorf("no CA certificate found in %s", caFile)
+	}
+	return cert, pool, nil
+}
@@ -0,0 +1,133 @@
+package lvmd
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// writeCert issues a certificate signed by parent, or a self-signed CA certificate if parent is nil,
+// and writes it and its key to dir.
+func writeCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey,
+	usage x509.ExtKeyUsage) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	path := func(name string) string { return filepath.Join(dir, name) }
+	ca, caKey := writeCert(t, dir, "ca", nil, nil, x509.ExtKeyUsageAny)
+	writeCert(t, dir, "lvmd", ca, caKey, x509.ExtKeyUsageServerAuth)
+	writeCert(t, dir, "topolvm-node", ca, caKey, x509.ExtKeyUsageClientAuth)
+	// a CA not trusted by lvmd.
+	other, otherKey := writeCert(t, dir, "other", nil, nil, x509.ExtKeyUsageAny)
+	writeCert(t, dir, "untrusted", other, otherKey, x509.ExtKeyUsageClientAuth)
+
+	if _, err := ServerTLSConfig(path("lvmd.crt"), path("lvmd.key"), ""); err == nil {
+		t.Error("the CA file should be required")
+	}
+	serverConfig, err := ServerTLSConfig(path("lvmd.crt"), path("lvmd.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(serverConfig)))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	check := func(clientConfig *tls.Config) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(clientConfig)))
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		_, err = grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		return err
+	}
+
+	clientConfig, err := ClientTLSConfig(path("topolvm-node.crt"), path("topolvm-node.key"), path("ca.crt"), "lvmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check(clientConfig); err != nil {
+		t.Errorf("client with a trusted certificate should connect: %v", err)
+	}
+
+	clientConfig, err = ClientTLSConfig(path("untrusted.crt"), path("untrusted.key"), path("ca.crt"), "lvmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check(clientConfig); err == nil {
+		t.Error("client with an untrusted certificate should be rejected")
+	}
+
+	clientConfig, err = ClientTLSConfig(path("topolvm-node.crt"), path("topolvm-node.key"), path("ca.crt"), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := check(clientConfig); err == nil {
+		t.Error("server certificate for another name should be rejected")
+	}
+}
@@ -4,7 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/topolvm/topolvm/internal/lvmd/command"
@@ -16,10 +17,13 @@ import (
 )
 
 // NewVGService creates a VGServiceServer
-func NewVGService(manager *DeviceClassManager) (proto.VGServiceServer, func()) {
+func NewVGService(manager *DeviceClassManager, ocManager *LvcreateOptionClassManager) (proto.VGServiceServer, func()) {
 	svc := &vgService{
-		dcManager: manager,
-		watchers:  make(map[int]chan struct{}),
+		dcManager:  manager,
+		ocManager:  ocManager,
+		watchers:   make(map[int]chan struct{}),
+		poolUsages: make(map[string]thinPoolUsage),
+		freeBytes:  newFreeBytesCache(),
 	}
 
 	return svc, svc.notifyWatchers
@@ -28,11 +32,22 @@ func NewVGService(manager *DeviceClassManager) (proto.VGServiceServer, func()) {
 type vgService struct {
 	proto.UnimplementedVGServiceServer
 	dcManager *DeviceClassManager
+	ocManager *LvcreateOptionClassManager
 
-	// mu protects watcherCounter and watchers. must take it when use them.
+	// mu protects watcherCounter, watchers and poolUsages. must take it when use them.
 	mu             sync.Mutex
 	watcherCounter int
 	watchers       map[int]chan struct{}
+	// poolUsages are the usages of the thin pools last reported by ReportThinPoolEvent, keyed by device class.
+	poolUsages map[string]thinPoolUsage
+
+	// freeBytes caches the free bytes sent by Watch for GetFreeBytes.
+	freeBytes *freeBytesCache
+}
+
+type thinPoolUsage struct {
+	data     float64
+	metadata float64
 }
 
 func (s *vgService) GetLVList(ctx context.Context, req *proto.GetLVListRequest) (*proto.GetLVListResponse, error) {
@@ -40,55 +55,154 @@ func (s *vgService) GetLVList(ctx context.Context, req *proto.GetLVListRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
-	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	if dc.Type == lvmdTypes.TypeRaw {
+		vols, err := rawVolumes(ctx, dc)
+		if err != nil {
+			return nil, internalError(err)
+		}
+		return &proto.GetLVListResponse{Volumes: vols}, nil
+	}
+	lvs, poolHealthError, err := s.dcManager.deviceClassVolumes(ctx, dc)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), dc.VolumeGroup)
+		return nil, err
+	}
+
+	vols := make([]*proto.LogicalVolume, 0, len(lvs))
+	for _, lv := range lvs {
+		vols = append(vols, logicalVolumeItem(dc, lv, poolHealthError))
+	}
+	return &proto.GetLVListResponse{Volumes: vols}, nil
+}
+
+func (s *vgService) ListSnapshots(ctx context.Context, req *proto.ListSnapshotsRequest) (*proto.ListSnapshotsResponse, error) {
+	dc, err := s.dcManager.DeviceClass(req.GetDeviceClass())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+	}
+	switch dc.Type {
+	case lvmdTypes.TypeThin, lvmdTypes.TypeThick:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid device class type %v", string(dc.Type))
+	}
+	lvs, poolHealthError, err := s.dcManager.deviceClassVolumes(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	origin := req.GetOrigin()
+	if _, ok := lvs[origin]; origin != "" && !ok {
+		return nil, status.Errorf(codes.NotFound, "origin logical volume %s is not found", origin)
+	}
+
+	var snapshots []*proto.LogicalVolume
+	for _, lv := range lvs {
+		if !lv.IsSnapshot() || (origin != "" && lv.OriginName() != origin) {
+			continue
+		}
+		snapshots = append(snapshots, logicalVolumeItem(dc, lv, poolHealthError))
 	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return &proto.ListSnapshotsResponse{Snapshots: snapshots}, nil
+}
+
+// deviceClassVolumes returns the logical volumes of the thick or thin device-class by name, including the volumes
+// provisioned on it by overriding the type, and why the thin pool is unhealthy for thin device-classes.
+func (m DeviceClassManager) deviceClassVolumes(ctx context.Context, dc *lvmdTypes.DeviceClass) (map[string]*command.LogicalVolume, string, error) {
+	vgs, err := deviceClassVolumeGroups(ctx, dc)
+	if err != nil {
+		return nil, "", status.Errorf(codes.NotFound, "%s: %s", err.Error(), strings.Join(VolumeGroupNames(dc), ","))
+	}
+	vg := vgs[0]
 
 	var lvs map[string]*command.LogicalVolume
+	// poolHealthError is reported for the thin volumes, whose attributes do not tell if the pool is full or failed.
+	var poolHealthError string
 
 	switch dc.Type {
 	case lvmdTypes.TypeThick:
-		// thick logicalvolumes
-		lvs, err = vg.ListVolumes(ctx)
-		if err != nil {
-			return nil, err
+		// thick logicalvolumes of all the volume groups of the device-class
+		lvs = make(map[string]*command.LogicalVolume)
+		for _, vg := range vgs {
+			vgLVs, err := vg.ListVolumes(ctx)
+			if err != nil {
+				return nil, "", err
+			}
+			for name, lv := range vgLVs {
+				// the lvs overriding the type belong to the device-class recorded in their tags,
+				// and the other thin lvs belong to the thin device-classes.
+				if owner := m.overridingDeviceClass(lv); owner != nil {
+					if owner.Name != dc.Name {
+						continue
+					}
+				} else if lv.IsThin() {
+					continue
+				}
+				lvs[name] = lv
+			}
 		}
 	case lvmdTypes.TypeThin:
 		var pool *command.ThinPool
 		pool, err = vg.FindPool(ctx, dc.ThinPoolConfig.Name)
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		// thin logicalvolumes
 		lvs, err = pool.ListVolumes(ctx)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		for name, lv := range lvs {
+			// thin lvs provisioned on thick device-classes by overriding the type belong to them.
+			if m.overridingDeviceClass(lv) != nil || !m.ownsThinVolume(dc, lv) {
+				delete(lvs, name)
+			}
+		}
+		if err := pool.Health(); err != nil {
+			poolHealthError = err.Error()
+		}
+		// thick logicalvolumes provisioned on this device-class by overriding the type
+		vgLVs, err := vg.ListVolumes(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		for name, lv := range vgLVs {
+			if owner := m.overridingDeviceClass(lv); owner != nil && owner.Name == dc.Name {
+				lvs[name] = lv
+			}
 		}
 	default:
 		// technically this block will not be hit however make sure we return error
 		// in such cases where deviceclass target is neither thick or thinpool
-		return nil, status.Error(codes.Internal, fmt.Sprintf("unsupported device class target: %s", dc.Type))
+		return nil, "", status.Error(codes.Internal, fmt.Sprintf("unsupported device class target: %s", dc.Type))
 	}
+	return lvs, poolHealthError, nil
+}
 
-	vols := make([]*proto.LogicalVolume, 0, len(lvs))
-	for _, lv := range lvs {
-		if dc.Type == lvmdTypes.TypeThick && lv.IsThin() {
-			// do not send thin lvs if request is on TypeThick
-			continue
-		}
-
-		vols = append(vols, &proto.LogicalVolume{
-			Name:      lv.Name(),
-			SizeGb:    (lv.Size() + (1 << 30) - 1) >> 30,
-			SizeBytes: int64(lv.Size()),
-			DevMajor:  lv.MajorNumber(),
-			DevMinor:  lv.MinorNumber(),
-			Tags:      lv.Tags(),
-			Attr:      lv.Attr(),
-		})
+// logicalVolumeItem converts a logical volume of the device-class for the response.
+// poolHealthError is reported for the thin volumes of thin device-classes.
+func logicalVolumeItem(dc *lvmdTypes.DeviceClass, lv *command.LogicalVolume, poolHealthError string) *proto.LogicalVolume {
+	var healthError string
+	if err := lv.Health(); err != nil {
+		healthError = err.Error()
+	}
+	var lvPoolHealthError string
+	if lv.IsThin() && dc.Type == lvmdTypes.TypeThin {
+		lvPoolHealthError = poolHealthError
+	}
+	return &proto.LogicalVolume{
+		Name:             lv.Name(),
+		SizeGb:           (lv.Size() + (1 << 30) - 1) >> 30,
+		SizeBytes:        int64(lv.Size()),
+		DevMajor:         lv.MajorNumber(),
+		DevMinor:         lv.MinorNumber(),
+		Tags:             lv.Tags(),
+		Attr:             lv.Attr().String(),
+		CopyPercent:      lv.CopyPercent(),
+		VdoSavingPercent: lv.VDOSavingPercent(),
+		AllocatedBytes:   lv.AllocatedBytes(),
+		HealthError:      healthError,
+		PoolHealthError:  lvPoolHealthError,
+		Origin:           lv.OriginName(),
 	}
-	return &proto.GetLVListResponse{Volumes: vols}, nil
 }
 
 func (s *vgService) GetFreeBytes(ctx context.Context, req *proto.GetFreeBytesRequest) (*proto.GetFreeBytesResponse, error) {
@@ -97,6 +211,35 @@ func (s *vgService) GetFreeBytes(ctx context.Context, req *proto.GetFreeBytesReq
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.DeviceClass)
 	}
+	if s.dcManager.IsDraining(dc.Name) {
+		// no new volume fits into a draining device-class.
+		return &proto.GetFreeBytesResponse{FreeBytes: 0}, nil
+	}
+	if !req.GetForceRefresh() {
+		if free, ok := s.freeBytes.get(dc.Name); ok {
+			return &proto.GetFreeBytesResponse{FreeBytes: free}, nil
+		}
+	}
+	generation := s.freeBytes.begin()
+	if dc.Type == lvmdTypes.TypeRaw {
+		// a volume is as large as the device it claims, so the largest free device is the free space.
+		free, _, err := s.dcManager.rawCapacity(ctx, dc)
+		if err != nil {
+			logger.Error(err, "failed to get free raw devices")
+			return nil, internalError(err)
+		}
+		s.freeBytes.put(generation, map[string]uint64{dc.Name: free})
+		return &proto.GetFreeBytesResponse{FreeBytes: free}, nil
+	}
+	if len(dc.VolumeGroups) != 0 {
+		vgFree, err := s.multiVolumeGroupFreeBytes(ctx, dc)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, err
+		}
+		s.freeBytes.put(generation, map[string]uint64{dc.Name: vgFree})
+		return &proto.GetFreeBytesResponse{FreeBytes: vgFree}, nil
+	}
 	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
 	if err != nil {
 		return nil, err
@@ -108,22 +251,26 @@ func (s *vgService) GetFreeBytes(ctx context.Context, req *proto.GetFreeBytesReq
 		vgFree, err = vg.Free()
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
 		}
 	case lvmdTypes.TypeThin:
 		pool, err := vg.FindPool(ctx, dc.ThinPoolConfig.Name)
 		if err != nil {
 			logger.Error(err, "failed to get thinpool")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
 		}
 		tpu, err := pool.Free(ctx)
 		if err != nil {
 			logger.Error(err, "failed to get free bytes")
-			return nil, status.Error(codes.Internal, err.Error())
+			return nil, internalError(err)
 		}
 
-		// freebytes available in thinpool considering the overprovisionratio
-		vgFree = uint64(math.Floor(dc.ThinPoolConfig.OverprovisionRatio*float64(tpu.SizeBytes))) - tpu.VirtualBytes
+		// freebytes available in thinpool considering the overprovisionratio and the quota
+		vgFree, err = s.dcManager.thinFreeBytes(ctx, dc, pool, tpu)
+		if err != nil {
+			logger.Error(err, "failed to get free bytes")
+			return nil, internalError(err)
+		}
 
 	default:
 		return nil, status.Error(codes.Internal, fmt.Sprintf("unsupported device class target: %s", dc.Type))
@@ -135,73 +282,100 @@ func (s *vgService) GetFreeBytes(ctx context.Context, req *proto.GetFreeBytesReq
 	} else {
 		vgFree -= spare
 	}
+	if dc.Type == lvmdTypes.TypeThick {
+		vgFree = GetUsableBytes(dc, vgFree)
+	}
+	s.freeBytes.put(generation, map[string]uint64{dc.Name: vgFree})
 
 	return &proto.GetFreeBytesResponse{
 		FreeBytes: vgFree,
 	}, nil
 }
 
+// multiVolumeGroupFreeBytes returns the free bytes of a device-class backed by multiple volume groups,
+// which are the largest usable free bytes of the volume groups after the spare of each,
+// since a logical volume cannot span volume groups.
+func (s *vgService) multiVolumeGroupFreeBytes(ctx context.Context, dc *lvmdTypes.DeviceClass) (uint64, error) {
+	vgs, err := deviceClassVolumeGroups(ctx, dc)
+	if err != nil {
+		return 0, status.Errorf(codes.NotFound, "%s: %s", err.Error(), strings.Join(dc.VolumeGroups, ","))
+	}
+	var free uint64
+	for _, vg := range vgs {
+		vgFree, err := vg.Free()
+		if err != nil {
+			return 0, internalError(err)
+		}
+		if usable := GetUsableBytes(dc, subtractOrZero(vgFree, GetSpare(dc))); usable > free {
+			free = usable
+		}
+	}
+	return free, nil
+}
+
 func (s *vgService) send(server proto.VGService_WatchServer) error {
+	generation := s.freeBytes.begin()
 	vgs, err := command.ListVolumeGroups(server.Context())
 	if err != nil {
 		return err
 	}
 	res := &proto.WatchResponse{}
+	// freeBytes are the free bytes of the device-classes as returned by GetFreeBytes.
+	freeBytes := make(map[string]uint64)
+	// multiVGItems are the items of the device-classes backed by multiple volume groups reported so far.
+	multiVGItems := make(map[string]*proto.WatchItem)
 	for _, vg := range vgs {
 
 		vgFree, err := vg.Free()
 		if err != nil {
-			return status.Error(codes.Internal, err.Error())
+			return internalError(err)
 		}
 		vgSize, err := vg.Size()
 		if err != nil {
-			return status.Error(codes.Internal, err.Error())
+			return internalError(err)
 		}
 
 		pools, err := vg.ListPools(server.Context(), "")
 		if err != nil {
-			return status.Error(codes.Internal, err.Error())
+			return internalError(err)
 		}
 
 		for _, pool := range pools {
-			dc, err := s.dcManager.FindDeviceClassByThinPoolName(vg.Name(), pool.Name())
-			// we either get nil or ErrDeviceClassNotFound
-			if errors.Is(err, ErrDeviceClassNotFound) {
-				continue
-			}
-
-			// if we find a device class then it'll be a thin target
-			tpi := &proto.ThinPoolItem{}
-			pool, err := vg.FindPool(server.Context(), dc.ThinPoolConfig.Name)
-			if err != nil {
-				return status.Error(codes.Internal, err.Error())
-			}
 			tpu, err := pool.Free(server.Context())
 			if err != nil {
-				return status.Error(codes.Internal, err.Error())
+				return internalError(err)
 			}
-
-			// used for updating prometheus metrics
-			tpi.DataPercent = tpu.DataPercent
-			tpi.MetadataPercent = tpu.MetadataPercent
-
-			// used for annotating the node for capacity aware scheduling
-			opb := uint64(math.Floor(dc.ThinPoolConfig.OverprovisionRatio*float64(tpu.SizeBytes))) - tpu.VirtualBytes
-			tpi.OverprovisionBytes = opb
-			if dc.Default {
-				res.FreeBytes = opb
+			// a thin pool shared by device-classes is reported for each of them.
+			for _, dc := range s.dcManager.thinDeviceClassesByPoolName[vg.Name()+"/"+pool.Name()] {
+				tpi := &proto.ThinPoolItem{}
+
+				// used for updating prometheus metrics
+				tpi.DataPercent = tpu.DataPercent
+				tpi.MetadataPercent = tpu.MetadataPercent
+
+				// used for annotating the node for capacity aware scheduling
+				opb, err := s.dcManager.thinFreeBytes(server.Context(), dc, pool, tpu)
+				if err != nil {
+					return internalError(err)
+				}
+				tpi.OverprovisionBytes = opb
+				freeBytes[dc.Name] = subtractOrZero(opb, GetSpare(dc))
+				if dc.Default {
+					res.FreeBytes = opb
+				}
+
+				// size bytes of the thinpool
+				tpi.SizeBytes = tpu.SizeBytes
+
+				// include thinpoolitem in the response
+				res.Items = append(res.Items, &proto.WatchItem{
+					DeviceClass: dc.Name,
+					FreeBytes:   vgFree,
+					SizeBytes:   vgSize,
+					ThinPool:    tpi,
+					Default:     dc.Default,
+				})
 			}
-
-			// size bytes of the thinpool
-			tpi.SizeBytes = tpu.SizeBytes
-
-			// include thinpoolitem in the response
-			res.Items = append(res.Items, &proto.WatchItem{
-				DeviceClass: dc.Name,
-				FreeBytes:   vgFree,
-				SizeBytes:   vgSize,
-				ThinPool:    tpi,
-			})
 		}
 
 		dc, err := s.dcManager.FindDeviceClassByVGName(vg.Name())
@@ -215,20 +389,415 @@ func (s *vgService) send(server proto.VGService_WatchServer) error {
 		} else {
 			vgFree -= spare
 		}
+		vgFree = GetUsableBytes(dc, vgFree)
+
+		if item, ok := multiVGItems[dc.Name]; ok {
+			// the volume groups of a device-class backed by multiple ones are reported together.
+			// The sums are for the metrics, while a volume fits into the volume group with the most free space.
+			item.FreeBytes += vgFree
+			item.SizeBytes += vgSize
+			if vgFree > item.LargestFreeBytes {
+				item.LargestFreeBytes = vgFree
+			}
+			freeBytes[dc.Name] = item.LargestFreeBytes
+			if dc.Default {
+				res.FreeBytes = item.LargestFreeBytes
+			}
+			continue
+		}
+		freeBytes[dc.Name] = vgFree
 
 		if dc.Default {
 			res.FreeBytes = vgFree
 		}
 
-		res.Items = append(res.Items, &proto.WatchItem{
+		var cache *proto.CacheItem
+		if dc.Cache != nil {
+			cache, err = cacheItem(server.Context(), vg)
+			if err != nil {
+				return internalError(err)
+			}
+		}
+
+		var vdo *proto.VDOItem
+		if dc.VDO != nil {
+			vdo, err = vdoItem(server.Context(), vg)
+			if err != nil {
+				return internalError(err)
+			}
+		}
+
+		item := &proto.WatchItem{
 			DeviceClass: dc.Name,
 			FreeBytes:   vgFree,
 			SizeBytes:   vgSize,
+			Cache:       cache,
+			Vdo:         vdo,
+			Default:     dc.Default,
+		}
+		if len(dc.VolumeGroups) != 0 {
+			item.LargestFreeBytes = vgFree
+			multiVGItems[dc.Name] = item
+		}
+		res.Items = append(res.Items, item)
+	}
+	for _, dc := range s.dcManager.DeviceClasses() {
+		if dc.Type != lvmdTypes.TypeRaw {
+			continue
+		}
+		free, size, err := s.dcManager.rawCapacity(server.Context(), dc)
+		if err != nil {
+			return internalError(err)
+		}
+		freeBytes[dc.Name] = free
+		if dc.Default {
+			res.FreeBytes = free
+		}
+		res.Items = append(res.Items, &proto.WatchItem{
+			DeviceClass: dc.Name,
+			FreeBytes:   free,
+			SizeBytes:   size,
+			Default:     dc.Default,
 		})
 	}
+	// no new volume fits into a draining device-class, so that the scheduler selects other nodes.
+	for _, item := range res.Items {
+		if !s.dcManager.IsDraining(item.DeviceClass) {
+			continue
+		}
+		item.FreeBytes = 0
+		item.LargestFreeBytes = 0
+		if item.ThinPool != nil {
+			item.ThinPool.OverprovisionBytes = 0
+		}
+		freeBytes[item.DeviceClass] = 0
+		if item.Default {
+			res.FreeBytes = 0
+		}
+	}
+	res.LvcreateOptionClasses = s.lvcreateOptionClassItems()
+	s.freeBytes.put(generation, freeBytes)
 	return server.Send(res)
 }
 
+// lvcreateOptionClassItems advertises the inline options allowed by the lvcreate-option-classes, sorted by name.
+func (s *vgService) lvcreateOptionClassItems() []*proto.LvcreateOptionClassItem {
+	if s.ocManager == nil {
+		return nil
+	}
+	var items []*proto.LvcreateOptionClassItem
+	for name, options := range s.ocManager.InlineOptions() {
+		items = append(items, &proto.LvcreateOptionClassItem{Name: name, InlineOptions: options})
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items
+}
+
+// cacheItem sums up the cache statistics of the cached volumes in the volume group.
+func cacheItem(ctx context.Context, vg *command.VolumeGroup) (*proto.CacheItem, error) {
+	lvs, err := vg.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item := &proto.CacheItem{}
+	var stats command.CacheStats
+	for _, lv := range lvs {
+		if !lv.IsCached() {
+			continue
+		}
+		item.Volumes++
+		stats.Add(lv.CacheStats())
+	}
+	item.TotalBlocks = stats.TotalBlocks
+	item.UsedBlocks = stats.UsedBlocks
+	item.DirtyBlocks = stats.DirtyBlocks
+	item.ReadHits = stats.ReadHits
+	item.ReadMisses = stats.ReadMisses
+	item.WriteHits = stats.WriteHits
+	item.WriteMisses = stats.WriteMisses
+	return item, nil
+}
+
+// vdoItem averages the space saving of the VDO volumes in the volume group weighted by their size.
+func vdoItem(ctx context.Context, vg *command.VolumeGroup) (*proto.VDOItem, error) {
+	lvs, err := vg.ListVolumes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	item := &proto.VDOItem{}
+	var saved, total float64
+	for _, lv := range lvs {
+		if !lv.IsVDO() {
+			continue
+		}
+		item.Volumes++
+		saved += lv.VDOSavingPercent() * float64(lv.Size())
+		total += float64(lv.Size())
+	}
+	if total != 0 {
+		item.SavingPercent = saved / total
+	}
+	return item, nil
+}
+
+func (s *vgService) CreateVG(ctx context.Context, req *proto.CreateVGRequest) (*proto.Empty, error) {
+	logger := log.FromContext(ctx).WithValues("vg", req.GetVgName())
+	if req.GetVgName() == "" || len(req.GetDevices()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "volume group name and devices are required")
+	}
+	_, err := command.FindVolumeGroup(ctx, req.GetVgName())
+	switch {
+	case err == nil:
+		return nil, status.Errorf(codes.AlreadyExists, "volume group %s already exists", req.GetVgName())
+	case !errors.Is(err, command.ErrNotFound):
+		return nil, internalError(err)
+	}
+
+	if _, err := command.CreateVolumeGroup(ctx, req.GetVgName(), req.GetDevices()); err != nil {
+		logger.Error(err, "failed to create volume group", "devices", req.GetDevices())
+		return nil, internalError(err)
+	}
+	logger.Info("created a new volume group", "devices", req.GetDevices())
+	return &proto.Empty{}, nil
+}
+
+func (s *vgService) RemoveVG(ctx context.Context, req *proto.RemoveVGRequest) (*proto.Empty, error) {
+	logger := log.FromContext(ctx).WithValues("vg", req.GetVgName())
+	if dc, err := s.dcManager.FindDeviceClassByVGName(req.GetVgName()); err == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "volume group %s is used by device class %s", req.GetVgName(), dc.Name)
+	}
+	vg, err := command.FindVolumeGroup(ctx, req.GetVgName())
+	switch {
+	case errors.Is(err, command.ErrNotFound):
+		return nil, status.Errorf(codes.NotFound, "volume group %s is not found", req.GetVgName())
+	case err != nil:
+		return nil, internalError(err)
+	}
+
+	err = vg.Remove(ctx)
+	switch {
+	case errors.Is(err, command.ErrVolumeGroupNotEmpty):
+		return nil, status.Errorf(codes.FailedPrecondition, "volume group %s has logical volumes", req.GetVgName())
+	case err != nil:
+		logger.Error(err, "failed to remove volume group")
+		return nil, internalError(err)
+	}
+	logger.Info("removed volume group")
+	return &proto.Empty{}, nil
+}
+
+func (s *vgService) ExtendVG(ctx context.Context, req *proto.ExtendVGRequest) (*proto.Empty, error) {
+	vg, err := s.deviceClassVG(ctx, req.GetDeviceClass(), req.GetDevices())
+	if err != nil {
+		return nil, err
+	}
+	logger := log.FromContext(ctx).WithValues("deviceClass", req.GetDeviceClass(), "vg", vg.Name())
+
+	if err := vg.Extend(ctx, req.GetDevices()); err != nil {
+		logger.Error(err, "failed to extend volume group", "devices", req.GetDevices())
+		return nil, internalError(err)
+	}
+	logger.Info("extended volume group", "devices", req.GetDevices())
+	s.notifyWatchers()
+	return &proto.Empty{}, nil
+}
+
+func (s *vgService) ReduceVG(ctx context.Context, req *proto.ReduceVGRequest) (*proto.Empty, error) {
+	vg, err := s.deviceClassVG(ctx, req.GetDeviceClass(), req.GetDevices())
+	if err != nil {
+		return nil, err
+	}
+	logger := log.FromContext(ctx).WithValues("deviceClass", req.GetDeviceClass(), "vg", vg.Name())
+
+	if err := vg.Reduce(ctx, req.GetDevices()); err != nil {
+		logger.Error(err, "failed to reduce volume group", "devices", req.GetDevices())
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	logger.Info("reduced volume group", "devices", req.GetDevices())
+	s.notifyWatchers()
+	return &proto.Empty{}, nil
+}
+
+func (s *vgService) EvacuatePV(req *proto.EvacuatePVRequest, server proto.VGService_EvacuatePVServer) error {
+	ctx := server.Context()
+	logger := log.FromContext(ctx).WithValues("deviceClass", req.GetDeviceClass(), "pv", req.GetPvName())
+
+	if req.GetPvName() == "" {
+		return status.Error(codes.InvalidArgument, "pv_name is required")
+	}
+	dc, err := s.dcManager.DeviceClass(req.GetDeviceClass())
+	if err != nil {
+		return status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+	}
+	pv, err := command.FindPhysicalVolume(ctx, req.GetPvName())
+	if errors.Is(err, command.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "physical volume not found: %s", req.GetPvName())
+	} else if err != nil {
+		return internalError(err)
+	}
+	if !containsString(VolumeGroupNames(dc), pv.VGName()) {
+		return status.Errorf(codes.FailedPrecondition, "physical volume %s does not belong to volume group %s", pv.Name(), strings.Join(VolumeGroupNames(dc), ","))
+	}
+
+	// exclude the physical volume from allocation so that no volume is created on it during the move.
+	if err := pv.SetAllocatable(ctx, false); err != nil {
+		logger.Error(err, "failed to exclude physical volume from allocation")
+		return internalError(err)
+	}
+
+	logger.Info("moving extents off physical volume", "destinations", req.GetDestinations())
+	var sendErr error
+	err = pv.Move(ctx, req.GetDestinations(), func(percent float64) {
+		// pvmove keeps running even if the client has gone away.
+		if sendErr == nil {
+			sendErr = server.Send(&proto.EvacuatePVResponse{ProgressPercent: percent})
+		}
+	})
+	if err != nil {
+		logger.Error(err, "failed to move extents off physical volume")
+		return internalError(err)
+	}
+	logger.Info("moved extents off physical volume")
+	if sendErr != nil {
+		return sendErr
+	}
+	return server.Send(&proto.EvacuatePVResponse{ProgressPercent: 100, Completed: true})
+}
+
+func (s *vgService) ReportThinPoolEvent(ctx context.Context, req *proto.ReportThinPoolEventRequest) (*proto.Empty, error) {
+	dc, err := s.dcManager.FindDeviceClassByThinPoolName(req.GetVolumeGroup(), req.GetThinPool())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s/%s", err.Error(), req.GetVolumeGroup(), req.GetThinPool())
+	}
+	usage := thinPoolUsage{data: req.GetDataPercent(), metadata: req.GetMetadataPercent()}
+
+	s.mu.Lock()
+	prev := s.poolUsages[dc.Name]
+	s.poolUsages[dc.Name] = usage
+	s.mu.Unlock()
+
+	thresholds := dc.ThinPoolConfig.NotifyThresholds
+	if len(thresholds) != 0 &&
+		!crossesThreshold(thresholds, prev.data, usage.data) &&
+		!crossesThreshold(thresholds, prev.metadata, usage.metadata) {
+		return &proto.Empty{}, nil
+	}
+	log.FromContext(ctx).Info("notifying thin pool event", "deviceClass", dc.Name,
+		"dataPercent", usage.data, "metadataPercent", usage.metadata)
+	s.notifyWatchers()
+	return &proto.Empty{}, nil
+}
+
+func (s *vgService) GetThinPoolUsage(ctx context.Context, req *proto.GetThinPoolUsageRequest) (*proto.GetThinPoolUsageResponse, error) {
+	logger := log.FromContext(ctx).WithValues("deviceClass", req.GetDeviceClass())
+	dc, err := s.dcManager.DeviceClass(req.GetDeviceClass())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), req.GetDeviceClass())
+	}
+	if dc.Type != lvmdTypes.TypeThin {
+		return nil, status.Errorf(codes.FailedPrecondition, "device class %s has no thin pool", dc.Name)
+	}
+	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), dc.VolumeGroup)
+	}
+	pool, err := vg.FindPool(ctx, dc.ThinPoolConfig.Name)
+	if err != nil {
+		logger.Error(err, "failed to get thinpool")
+		return nil, internalError(err)
+	}
+	tpu, err := pool.Free(ctx)
+	if err != nil {
+		logger.Error(err, "failed to get thinpool usage")
+		return nil, internalError(err)
+	}
+	return &proto.GetThinPoolUsageResponse{
+		VolumeGroup:     vg.Name(),
+		ThinPool:        pool.Name(),
+		DataPercent:     tpu.DataPercent,
+		MetadataPercent: tpu.MetadataPercent,
+		VirtualBytes:    tpu.VirtualBytes,
+		SizeBytes:       tpu.SizeBytes,
+	}, nil
+}
+
+func (s *vgService) GetLVMVersion(ctx context.Context, _ *proto.Empty) (*proto.GetLVMVersionResponse, error) {
+	version, ok := command.DetectedVersion()
+	if !ok {
+		// lvmd failed to detect the version on startup, which is then reported without enabling the features.
+		var err error
+		version, err = command.GetVersion(ctx)
+		if err != nil {
+			log.FromContext(ctx).Error(err, "failed to get lvm version")
+			return nil, internalError(err)
+		}
+	}
+	return &proto.GetLVMVersionResponse{Version: version.String(), ReportFormat: command.ReportFormat()}, nil
+}
+
+// GetCapabilities implements proto.VGServiceServer.
+func (s *vgService) GetCapabilities(ctx context.Context, _ *proto.Empty) (*proto.GetCapabilitiesResponse, error) {
+	segTypes, err := command.SegmentTypes(ctx)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "failed to get lvm segment types")
+		return nil, internalError(err)
+	}
+	supported := make(map[string]bool, len(segTypes))
+	for _, t := range segTypes {
+		supported[t] = true
+	}
+
+	res := &proto.GetCapabilitiesResponse{
+		SegmentTypes:     segTypes,
+		ThinProvisioning: supported["thin"] && supported["thin-pool"],
+		Raid:             supported["raid1"],
+		Cache:            supported["cache"],
+		Vdo:              supported["vdo"] && supported["vdo-pool"],
+	}
+	for _, dc := range s.dcManager.DeviceClasses() {
+		res.DeviceClasses = append(res.DeviceClasses, &proto.DeviceClassCapabilities{
+			DeviceClass: dc.Name,
+			Type:        string(dc.Type),
+			Snapshot:    dc.Type != lvmdTypes.TypeRaw,
+			Shrink:      dc.AllowShrink,
+			Raid:        dc.RAID != nil,
+			Cache:       dc.Cache != nil,
+			Vdo:         dc.VDO != nil,
+		})
+	}
+	return res, nil
+}
+
+// crossesThreshold returns true if any of the thresholds lies between the previous and the current usage.
+func crossesThreshold(thresholds []float64, prev, current float64) bool {
+	for _, threshold := range thresholds {
+		if (prev < threshold) != (current < threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// deviceClassVG returns the volume group of the device class to add or remove devices.
+func (s *vgService) deviceClassVG(ctx context.Context, deviceClass string, devices []string) (*command.VolumeGroup, error) {
+	if len(devices) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "devices are required")
+	}
+	dc, err := s.dcManager.DeviceClass(deviceClass)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "%s: %s", err.Error(), deviceClass)
+	}
+	if len(dc.VolumeGroups) != 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "device class %s is backed by volume groups %s, extend or reduce them with lvm",
+			dc.Name, strings.Join(dc.VolumeGroups, ","))
+	}
+	vg, err := command.FindVolumeGroup(ctx, dc.VolumeGroup)
+	if err != nil {
+		return nil, internalError(err)
+	}
+	return vg, nil
+}
+
 func (s *vgService) addWatcher(ch chan struct{}) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -248,6 +817,9 @@ func (s *vgService) removeWatcher(num int) {
 }
 
 func (s *vgService) notifyWatchers() {
+	// the volumes have been changed, so the free bytes have to be computed again.
+	s.freeBytes.invalidate()
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
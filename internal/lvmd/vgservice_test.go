@@ -2,9 +2,13 @@ package lvmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math"
 	"os"
 	"os/exec"
+	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -13,7 +17,11 @@ import (
 	"github.com/topolvm/topolvm/internal/lvmd/testutils"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	gproto "google.golang.org/protobuf/proto"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
@@ -53,6 +61,36 @@ func (s *mockWatchServer) RecvMsg(m interface{}) error {
 	panic("implement me")
 }
 
+type mockEvacuatePVServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*proto.EvacuatePVResponse
+}
+
+func (s *mockEvacuatePVServer) Send(r *proto.EvacuatePVResponse) error {
+	s.responses = append(s.responses, r)
+	return nil
+}
+
+func (s *mockEvacuatePVServer) Context() context.Context {
+	return s.ctx
+}
+
+type mockWatchLVsServer struct {
+	grpc.ServerStream
+	ctx context.Context
+	ch  chan *proto.WatchLVsResponse
+}
+
+func (s *mockWatchLVsServer) Send(r *proto.WatchLVsResponse) error {
+	s.ch <- r
+	return nil
+}
+
+func (s *mockWatchLVsServer) Context() context.Context {
+	return s.ctx
+}
+
 func testWatch(t *testing.T) {
 	overprovisionRatio := float64(2.0)
 	tests := []struct {
@@ -82,7 +120,7 @@ func testWatch(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
-			vgService, notifier := NewVGService(NewDeviceClassManager(tt.deviceClasses))
+			vgService, notifier := NewVGService(NewDeviceClassManager(tt.deviceClasses), NewLvcreateOptionClassManager(nil))
 
 			ch1 := make(chan struct{})
 			server1 := &mockWatchServer{
@@ -186,6 +224,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 				},
 			},
 		),
+		NewLvcreateOptionClassManager(nil),
 	)
 
 	// thick lvs
@@ -211,7 +250,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 
 	// create thick volume
 	testtag := "testtag"
-	if err := vg.CreateVolume(ctx, "test1", 1<<30, []string{testtag}, 0, "", nil); err != nil {
+	if err := vg.CreateVolume(ctx, "test1", 1<<30, []string{testtag}, 0, "", nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -274,7 +313,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 
 	// thick lv creation
 
-	if err = vg.CreateVolume(ctx, "test2", 1<<30, nil, 0, "", nil); err != nil {
+	if err = vg.CreateVolume(ctx, "test2", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 
@@ -358,7 +397,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 
 	// Creation of thick volumes
 
-	if err := vg.CreateVolume(ctx, "test3", 1<<30, nil, 2, "4k", nil); err != nil {
+	if err := vg.CreateVolume(ctx, "test3", 1<<30, nil, 2, "4k", nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 	test3Vol, err := vg.FindVolume(ctx, "test3")
@@ -366,7 +405,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 		t.Fatal(err)
 	}
 
-	if err := vg.CreateVolume(ctx, "test4", 1<<30, nil, 2, "4M", nil); err != nil {
+	if err := vg.CreateVolume(ctx, "test4", 1<<30, nil, 2, "4M", nil, nil, nil); err != nil {
 		t.Fatal(err)
 	}
 	test4Vol, err := vg.FindVolume(ctx, "test4")
@@ -387,7 +426,7 @@ func testVGService(t *testing.T, vg *command.VolumeGroup) {
 		// 1. confirm that stripe, stripesize and raid isn't possible on thin lv
 		// 2. if above is true, enforce some sensible defaults during validation of deviceclass
 		// thick lv with raid
-		if err := vg.CreateVolume(ctx, "test5", 1<<30, nil, 0, "", []string{"--type=raid1"}); err != nil {
+		if err := vg.CreateVolume(ctx, "test5", 1<<30, nil, 0, "", nil, []string{"--type=raid1"}, nil); err != nil {
 			t.Fatal(err)
 		}
 
@@ -461,3 +500,774 @@ func TestVGService(t *testing.T) {
 		testWatch(t)
 	})
 }
+
+func TestVGServiceLifecycleWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	for _, dev := range []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"} {
+		fake.AddDevice(dev, 1<<30)
+	}
+	defer command.SetExecutor(fake)()
+
+	noSpare := uint64(0)
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg", Type: lvmdTypes.TypeThick, SpareGB: &noSpare},
+	}), NewLvcreateOptionClassManager(nil))
+
+	if _, err := vgService.CreateVG(ctx, &proto.CreateVGRequest{VgName: "ssd-vg"}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+	if _, err := vgService.CreateVG(ctx, &proto.CreateVGRequest{VgName: "ssd-vg", Devices: []string{"/dev/sdb"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vgService.CreateVG(ctx, &proto.CreateVGRequest{VgName: "ssd-vg", Devices: []string{"/dev/sdc"}}); status.Code(err) != codes.AlreadyExists {
+		t.Errorf("expected AlreadyExists, got %v", err)
+	}
+
+	if _, err := vgService.ExtendVG(ctx, &proto.ExtendVGRequest{DeviceClass: "unknown", Devices: []string{"/dev/sdc"}}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+	if _, err := vgService.ExtendVG(ctx, &proto.ExtendVGRequest{DeviceClass: "ssd", Devices: []string{"/dev/sdc"}}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "ssd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetFreeBytes() != 2<<30 {
+		t.Errorf("unexpected free bytes after extension: %d", res.GetFreeBytes())
+	}
+
+	if _, err := vgService.ReduceVG(ctx, &proto.ReduceVGRequest{DeviceClass: "ssd", Devices: []string{"/dev/sdd"}}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition, got %v", err)
+	}
+	if _, err := vgService.ReduceVG(ctx, &proto.ReduceVGRequest{DeviceClass: "ssd", Devices: []string{"/dev/sdc"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vgService.RemoveVG(ctx, &proto.RemoveVGRequest{VgName: "ssd-vg"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for the volume group of a device class, got %v", err)
+	}
+	if _, err := vgService.CreateVG(ctx, &proto.CreateVGRequest{VgName: "spare-vg", Devices: []string{"/dev/sdc", "/dev/sdd"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vgService.RemoveVG(ctx, &proto.RemoveVGRequest{VgName: "spare-vg"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := vgService.RemoveVG(ctx, &proto.RemoveVGRequest{VgName: "spare-vg"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound, got %v", err)
+	}
+}
+
+func TestVGServiceGetFreeBytesCacheWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("ssd-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	noSpare := uint64(0)
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg", Type: lvmdTypes.TypeThick, SpareGB: &noSpare},
+	})
+	vgService, notifier := NewVGService(dcManager, NewLvcreateOptionClassManager(nil))
+	lvService := NewLVService(dcManager, NewLvcreateOptionClassManager(nil), notifier)
+
+	expect := func(forceRefresh bool, expected uint64) {
+		t.Helper()
+		res, err := vgService.GetFreeBytes(ctx, &proto.GetFreeBytesRequest{DeviceClass: "ssd", ForceRefresh: forceRefresh})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.GetFreeBytes() != expected {
+			t.Errorf("expected %d free bytes, got %d", expected, res.GetFreeBytes())
+		}
+	}
+
+	expect(false, 4<<30)
+	// a volume created bypassing lvmd is not noticed until the cache expires or is refreshed.
+	vg, err := command.FindVolumeGroup(ctx, "ssd-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "external", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, 4<<30)
+	expect(true, 3<<30)
+	expect(false, 3<<30)
+
+	// volumes created through lvmd invalidate the cache.
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv", DeviceClass: "ssd", SizeBytes: 1 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, 2<<30)
+
+	prevMaxAge := FreeBytesCacheMaxAge
+	FreeBytesCacheMaxAge = 0
+	defer func() { FreeBytesCacheMaxAge = prevMaxAge }()
+	if err := vg.CreateVolume(ctx, "external2", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, 1<<30)
+}
+
+func TestVGServiceEvacuatePVWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	for _, dev := range []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"} {
+		fake.AddDevice(dev, 1<<30)
+	}
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.CreateVolumeGroup(ctx, "ssd-vg", []string{"/dev/sdb", "/dev/sdc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vg.CreateVolume(ctx, "lv", 512<<20, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := command.CreateVolumeGroup(ctx, "other-vg", []string{"/dev/sdd"}); err != nil {
+		t.Fatal(err)
+	}
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg", Type: lvmdTypes.TypeThick},
+	}), NewLvcreateOptionClassManager(nil))
+
+	for _, tc := range []struct {
+		req  *proto.EvacuatePVRequest
+		code codes.Code
+	}{
+		{&proto.EvacuatePVRequest{DeviceClass: "ssd"}, codes.InvalidArgument},
+		{&proto.EvacuatePVRequest{DeviceClass: "unknown", PvName: "/dev/sdb"}, codes.NotFound},
+		{&proto.EvacuatePVRequest{DeviceClass: "ssd", PvName: "/dev/sdx"}, codes.NotFound},
+		{&proto.EvacuatePVRequest{DeviceClass: "ssd", PvName: "/dev/sdd"}, codes.FailedPrecondition},
+	} {
+		err := vgService.EvacuatePV(tc.req, &mockEvacuatePVServer{ctx: ctx})
+		if status.Code(err) != tc.code {
+			t.Errorf("expected %s for %v, got %v", tc.code, tc.req, err)
+		}
+	}
+
+	server := &mockEvacuatePVServer{ctx: ctx}
+	if err := vgService.EvacuatePV(&proto.EvacuatePVRequest{DeviceClass: "ssd", PvName: "/dev/sdb"}, server); err != nil {
+		t.Fatal(err)
+	}
+	if len(server.responses) == 0 {
+		t.Fatal("no progress is streamed")
+	}
+	if last := server.responses[len(server.responses)-1]; !last.GetCompleted() || last.GetProgressPercent() != 100 {
+		t.Errorf("unexpected last response: %v", last)
+	}
+
+	pv, err := command.FindPhysicalVolume(ctx, "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	attr, err := command.ParsedPvAttr(pv.Attr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr.IsAllocatable() || pv.Free() != pv.Size() {
+		t.Errorf("physical volume should be evacuated: attr=%s, free=%d", attr, pv.Free())
+	}
+	if _, err := vgService.ReduceVG(ctx, &proto.ReduceVGRequest{DeviceClass: "ssd", Devices: []string{"/dev/sdb"}}); err != nil {
+		t.Errorf("evacuated physical volume should be removable: %v", err)
+	}
+}
+
+func TestVGServiceLVMDevicesWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	for _, dev := range []string{"/dev/sdb", "/dev/sdc", "/dev/sdd"} {
+		fake.AddDevice(dev, 1<<30)
+	}
+	fake.SetConfig(command.GlobalFilterKey, `["r|/dev/loop.*|"]`)
+	defer command.SetExecutor(fake)()
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg", Type: lvmdTypes.TypeThick},
+	}), NewLvcreateOptionClassManager(nil))
+
+	res, err := vgService.GetLVMDevices(ctx, &proto.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetUseDevicesFile() || len(res.GetDevices()) != 0 || len(res.GetGlobalFilter()) != 1 {
+		t.Errorf("unexpected devices: %v", res)
+	}
+	_, err = vgService.UpdateLVMDevices(ctx, &proto.UpdateLVMDevicesRequest{Add: []string{"/dev/sdc"}})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("the devices file should not be updated if it is not used: %v", err)
+	}
+
+	fake.SetConfig(command.UseDevicesFileKey, "1")
+	if _, err := command.CreateVolumeGroup(ctx, "ssd-vg", []string{"/dev/sdb"}); err != nil {
+		t.Fatal(err)
+	}
+	res, err = vgService.UpdateLVMDevices(ctx, &proto.UpdateLVMDevicesRequest{Add: []string{"/dev/sdc", "/dev/sdd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.GetUseDevicesFile() || len(res.GetDevices()) != 3 {
+		t.Fatalf("unexpected devices: %v", res)
+	}
+	if res.GetDevices()[0].GetDevice() != "/dev/sdb" || res.GetDevices()[0].GetPvid() == "" {
+		t.Errorf("the physical volume should be in the devices file: %v", res.GetDevices()[0])
+	}
+
+	for _, tc := range []struct {
+		req  *proto.UpdateLVMDevicesRequest
+		code codes.Code
+	}{
+		{&proto.UpdateLVMDevicesRequest{}, codes.InvalidArgument},
+		{&proto.UpdateLVMDevicesRequest{Remove: []string{"/dev/sdb"}}, codes.FailedPrecondition},
+		{&proto.UpdateLVMDevicesRequest{Add: []string{"/dev/sdx"}}, codes.Internal},
+	} {
+		_, err := vgService.UpdateLVMDevices(ctx, tc.req)
+		if status.Code(err) != tc.code {
+			t.Errorf("expected %s for %v, got %v", tc.code, tc.req, err)
+		}
+	}
+
+	res, err = vgService.UpdateLVMDevices(ctx, &proto.UpdateLVMDevicesRequest{Remove: []string{"/dev/sdd"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetDevices()) != 2 || res.GetDevices()[1].GetDevice() != "/dev/sdc" {
+		t.Errorf("unexpected devices: %v", res.GetDevices())
+	}
+}
+
+func TestVGServiceWatchLVsWithFakeLVM(t *testing.T) {
+	ctx, cancel := context.WithCancel(ctrl.LoggerInto(context.Background(), testr.New(t)))
+	defer cancel()
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("ssd-vg", 4<<30)
+	fake.AddVolumeGroup("hdd-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	hdd, err := command.FindVolumeGroup(ctx, "hdd-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hdd.CreateVolume(ctx, "existing", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "ssd-vg", Type: lvmdTypes.TypeThick},
+		{Name: "hdd", VolumeGroup: "hdd-vg", Type: lvmdTypes.TypeThick},
+	})
+	vgService, notifier := NewVGService(dcManager, NewLvcreateOptionClassManager(nil))
+	lvService := NewLVService(dcManager, NewLvcreateOptionClassManager(nil), notifier)
+
+	if err := vgService.WatchLVs(&proto.WatchLVsRequest{DeviceClass: "unknown"}, &mockWatchLVsServer{ctx: ctx}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown device class, got %v", err)
+	}
+
+	server := &mockWatchLVsServer{ctx: ctx, ch: make(chan *proto.WatchLVsResponse)}
+	done := make(chan error)
+	go func() {
+		done <- vgService.WatchLVs(&proto.WatchLVsRequest{}, server)
+	}()
+	expect := func(initial bool, events ...string) {
+		t.Helper()
+		var res *proto.WatchLVsResponse
+		select {
+		case res = <-server.ch:
+		case <-time.After(waitDuration * time.Second):
+			t.Fatal("timed out waiting for the events")
+		}
+		actual := make([]string, 0, len(res.GetEvents()))
+		for _, e := range res.GetEvents() {
+			actual = append(actual, fmt.Sprintf("%s %s/%s %d", e.GetType(), e.GetDeviceClass(), e.GetVolume().GetName(), e.GetVolume().GetSizeBytes()))
+		}
+		if res.GetInitial() != initial || !reflect.DeepEqual(actual, events) {
+			t.Errorf("expected initial=%t events=%v, got initial=%t events=%v", initial, events, res.GetInitial(), actual)
+		}
+	}
+
+	expect(true, fmt.Sprintf("created hdd/existing %d", 1<<30))
+	if _, err := lvService.CreateLV(ctx, &proto.CreateLVRequest{Name: "lv", DeviceClass: "ssd", SizeBytes: 1 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, fmt.Sprintf("created ssd/lv %d", 1<<30))
+	if _, err := lvService.ResizeLV(ctx, &proto.ResizeLVRequest{Name: "lv", DeviceClass: "ssd", SizeBytes: 2 << 30}); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, fmt.Sprintf("resized ssd/lv %d", 2<<30))
+	if _, err := lvService.RemoveLV(ctx, &proto.RemoveLVRequest{Name: "existing", DeviceClass: "hdd"}); err != nil {
+		t.Fatal(err)
+	}
+	expect(false, fmt.Sprintf("deleted hdd/existing %d", 1<<30))
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the watch to end with the context, got %v", err)
+	}
+}
+
+func TestVGServiceGetLVMVersionWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.SetVersion("2.03.11")
+	defer command.SetExecutor(fake)()
+
+	vgService, _ := NewVGService(NewDeviceClassManager(nil), NewLvcreateOptionClassManager(nil))
+	res, err := vgService.GetLVMVersion(ctx, &proto.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetVersion() != "2.03.11" || res.GetReportFormat() != "json" {
+		t.Errorf("unexpected version: %v", res)
+	}
+}
+
+func TestVGServiceGetCapabilitiesWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.SetSegmentTypes("linear", "striped", "snapshot", "raid1", "thin-pool", "thin", "cache-pool", "cache")
+	defer command.SetExecutor(fake)()
+
+	dcm := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{
+			Name:        "thin",
+			VolumeGroup: "vg",
+			Type:        lvmdTypes.TypeThin,
+			ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+				Name:               "pool",
+				OverprovisionRatio: 2,
+			},
+		},
+		{
+			Name:        "thick",
+			VolumeGroup: "vg",
+			Default:     true,
+			AllowShrink: true,
+			RAID:        &lvmdTypes.RAIDConfig{Type: "raid1"},
+		},
+	})
+	vgService, _ := NewVGService(dcm, NewLvcreateOptionClassManager(nil))
+	res, err := vgService.GetCapabilities(ctx, &proto.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.GetThinProvisioning() || !res.GetRaid() || !res.GetCache() || res.GetVdo() {
+		t.Errorf("unexpected lvm capabilities: %v", res)
+	}
+	if len(res.GetSegmentTypes()) != 8 {
+		t.Errorf("unexpected segment types: %v", res.GetSegmentTypes())
+	}
+
+	dcs := res.GetDeviceClasses()
+	if len(dcs) != 2 {
+		t.Fatalf("unexpected device classes: %v", dcs)
+	}
+	thick, thin := dcs[0], dcs[1]
+	if thick.GetDeviceClass() != "thick" || thick.GetType() != "thick" ||
+		!thick.GetSnapshot() || !thick.GetShrink() || !thick.GetRaid() || thick.GetCache() || thick.GetVdo() {
+		t.Errorf("unexpected capabilities of thick: %v", thick)
+	}
+	if thin.GetDeviceClass() != "thin" || thin.GetType() != "thin" ||
+		!thin.GetSnapshot() || thin.GetShrink() || thin.GetRaid() {
+		t.Errorf("unexpected capabilities of thin: %v", thin)
+	}
+}
+
+func TestVGServiceListSnapshotsWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("thick-vg", 4<<30)
+	fake.AddVolumeGroup("thin-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	thickVG, err := command.FindVolumeGroup(ctx, "thick-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := thickVG.CreateVolume(ctx, "thick", 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	thick, err := thickVG.FindVolume(ctx, "thick")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := thick.Snapshot(ctx, "thick-snap", 1<<30, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	thinVG, err := command.FindVolumeGroup(ctx, "thin-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := thinVG.CreatePool(ctx, "pool", 2<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	thin, err := thinVG.FindVolume(ctx, "thin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"thin-snap2", "thin-snap1"} {
+		if err := thin.ThinSnapshot(ctx, name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snap, err := thinVG.FindVolume(ctx, "thin-snap1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := snap.ThinSnapshot(ctx, "thin-snap1-snap", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "thick", VolumeGroup: "thick-vg", Type: lvmdTypes.TypeThick},
+		{Name: "thin", VolumeGroup: "thin-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool", OverprovisionRatio: 5}},
+	}), NewLvcreateOptionClassManager(nil))
+
+	testCases := []struct {
+		deviceClass string
+		origin      string
+		expected    []string
+	}{
+		{deviceClass: "thick", origin: "thick", expected: []string{"thick-snap <- thick"}},
+		{deviceClass: "thin", origin: "thin", expected: []string{"thin-snap1 <- thin", "thin-snap2 <- thin"}},
+		{deviceClass: "thin", origin: "thin-snap1", expected: []string{"thin-snap1-snap <- thin-snap1"}},
+		{deviceClass: "thin", origin: "thin-snap2"},
+		{deviceClass: "thin", expected: []string{"thin-snap1 <- thin", "thin-snap1-snap <- thin-snap1", "thin-snap2 <- thin"}},
+	}
+	for _, tc := range testCases {
+		res, err := vgService.ListSnapshots(ctx, &proto.ListSnapshotsRequest{DeviceClass: tc.deviceClass, Origin: tc.origin})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, snapshot := range res.GetSnapshots() {
+			actual = append(actual, snapshot.GetName()+" <- "+snapshot.GetOrigin())
+		}
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("expected snapshots %v of %s/%s, got %v", tc.expected, tc.deviceClass, tc.origin, actual)
+		}
+	}
+
+	for _, tc := range []struct {
+		req  *proto.ListSnapshotsRequest
+		code codes.Code
+	}{
+		{&proto.ListSnapshotsRequest{DeviceClass: "unknown"}, codes.NotFound},
+		{&proto.ListSnapshotsRequest{DeviceClass: "thin", Origin: "thick"}, codes.NotFound},
+	} {
+		if _, err := vgService.ListSnapshots(ctx, tc.req); status.Code(err) != tc.code {
+			t.Errorf("expected %s for %v, got %v", tc.code, tc.req, err)
+		}
+	}
+}
+
+func TestVGServiceGetLVListOverridingTypeWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 20<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pool := range []string{"pool1", "pool2"} {
+		if _, err := vg.CreatePool(ctx, pool, 4<<30); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dcManager := NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "thick", VolumeGroup: "fake-vg", ThinDeviceClass: "thin2"},
+		{Name: "thin1", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool1", OverprovisionRatio: 5}},
+		{Name: "thin2", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool2", OverprovisionRatio: 5}},
+	})
+	lvService := NewLVService(dcManager, NewLvcreateOptionClassManager(nil), nil)
+	vgService, _ := NewVGService(dcManager, NewLvcreateOptionClassManager(nil))
+
+	for _, req := range []*proto.CreateLVRequest{
+		{Name: "thick", DeviceClass: "thick", SizeBytes: 1 << 30},
+		{Name: "thick-thin", DeviceClass: "thick", SizeBytes: 1 << 30, ProvisioningType: "thin"},
+		{Name: "thin1", DeviceClass: "thin1", SizeBytes: 1 << 30},
+		{Name: "thin1-thick", DeviceClass: "thin1", SizeBytes: 1 << 30, ProvisioningType: "thick"},
+		{Name: "thin2", DeviceClass: "thin2", SizeBytes: 1 << 30},
+	} {
+		if _, err := lvService.CreateLV(ctx, req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots := map[string]string{"thick-thin": "thick", "thin1-thick": "thin1"}
+	for source, deviceClass := range snapshots {
+		_, err := lvService.CreateLVSnapshot(ctx, &proto.CreateLVSnapshotRequest{
+			Name: source + "-snap", DeviceClass: deviceClass, SourceVolume: source, SizeBytes: 1 << 30, AccessType: "ro",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	thickThin, err := vg.FindVolume(ctx, "thick-thin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pool, err := thickThin.Pool(ctx); err != nil {
+		t.Fatal(err)
+	} else if pool.Name() != "pool2" {
+		t.Errorf("the thin volume should be in the pool of thin-device-class: %s", pool.Name())
+	}
+
+	for deviceClass, expected := range map[string][]string{
+		"thick": {"thick", "thick-thin", "thick-thin-snap"},
+		"thin1": {"thin1", "thin1-thick", "thin1-thick-snap"},
+		"thin2": {"thin2"},
+	} {
+		res, err := vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: deviceClass})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []string
+		for _, lv := range res.GetVolumes() {
+			actual = append(actual, lv.GetName())
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected volumes %v of %s, got %v", expected, deviceClass, actual)
+		}
+	}
+}
+
+func TestVGServiceGetThinPoolUsageWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin", 3<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetDataPercent("fake-vg", "pool", 25); err != nil {
+		t.Fatal(err)
+	}
+	if err := fake.SetMetadataPercent("fake-vg", "pool", 92.5); err != nil {
+		t.Fatal(err)
+	}
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "thick", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThick},
+		{Name: "thin", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool", OverprovisionRatio: 5}},
+	}), NewLvcreateOptionClassManager(nil))
+
+	res, err := vgService.GetThinPoolUsage(ctx, &proto.GetThinPoolUsageRequest{DeviceClass: "thin"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &proto.GetThinPoolUsageResponse{
+		VolumeGroup:     "fake-vg",
+		ThinPool:        "pool",
+		DataPercent:     25,
+		MetadataPercent: 92.5,
+		VirtualBytes:    3 << 30,
+		SizeBytes:       1 << 30,
+	}
+	if !gproto.Equal(res, expected) {
+		t.Errorf("expected %v, got %v", expected, res)
+	}
+
+	if _, err := vgService.GetThinPoolUsage(ctx, &proto.GetThinPoolUsageRequest{DeviceClass: "thick"}); status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("expected FailedPrecondition for a thick device class, got %v", err)
+	}
+	if _, err := vgService.GetThinPoolUsage(ctx, &proto.GetThinPoolUsageRequest{DeviceClass: "unknown"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NotFound for an unknown device class, got %v", err)
+	}
+}
+
+func TestVGServiceReportThinPoolEvent(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	server, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{
+			Name:        "thin",
+			VolumeGroup: "vg",
+			Type:        lvmdTypes.TypeThin,
+			ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+				Name:               "pool",
+				OverprovisionRatio: 10,
+				NotifyThresholds:   []float64{80, 90},
+			},
+		},
+		{
+			Name:        "every-event",
+			VolumeGroup: "vg",
+			Type:        lvmdTypes.TypeThin,
+			ThinPoolConfig: &lvmdTypes.ThinPoolConfig{
+				Name:               "pool2",
+				OverprovisionRatio: 10,
+			},
+		},
+	}), NewLvcreateOptionClassManager(nil))
+	svc := server.(*vgService)
+	ch := make(chan struct{}, 1)
+	defer svc.removeWatcher(svc.addWatcher(ch))
+
+	testCases := []struct {
+		pool     string
+		data     float64
+		metadata float64
+		notified bool
+	}{
+		{pool: "pool", data: 50, metadata: 10, notified: false},
+		{pool: "pool", data: 80, metadata: 10, notified: true},
+		{pool: "pool", data: 85, metadata: 10, notified: false},
+		{pool: "pool", data: 85, metadata: 95, notified: true},
+		// an extended pool dropping below a threshold is notified too.
+		{pool: "pool", data: 60, metadata: 95, notified: true},
+		{pool: "pool2", data: 55, metadata: 10, notified: true},
+		{pool: "pool2", data: 60, metadata: 10, notified: true},
+	}
+	for i, tc := range testCases {
+		_, err := svc.ReportThinPoolEvent(ctx, &proto.ReportThinPoolEventRequest{
+			VolumeGroup:     "vg",
+			ThinPool:        tc.pool,
+			DataPercent:     tc.data,
+			MetadataPercent: tc.metadata,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var notified bool
+		select {
+		case <-ch:
+			notified = true
+		default:
+		}
+		if notified != tc.notified {
+			t.Errorf("case %d: expected notified=%v, got %v", i, tc.notified, notified)
+		}
+	}
+
+	_, err := svc.ReportThinPoolEvent(ctx, &proto.ReportThinPoolEventRequest{VolumeGroup: "vg", ThinPool: "unknown"})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Errorf(`code is not codes.NotFound: %s`, code)
+	}
+}
+
+func TestVGServiceGetLVListHealthWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "ssd", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThick},
+	}), NewLvcreateOptionClassManager(nil))
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"healthy", "suspended"} {
+		if err := vg.CreateVolume(ctx, name, 1<<30, nil, 0, "", nil, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := fake.Execute(ctx, command.BinaryDmsetup, "suspend", "fake--vg-suspended")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	res, err := vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: "ssd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.GetVolumes()) != 2 {
+		t.Fatalf("unexpected volumes: %v", res.GetVolumes())
+	}
+	for _, v := range res.GetVolumes() {
+		switch v.GetName() {
+		case "healthy":
+			if v.GetHealthError() != "" {
+				t.Errorf("healthy volume should have no health error: %s", v.GetHealthError())
+			}
+		case "suspended":
+			if v.GetHealthError() != command.ErrLogicalVolumeSuspended.Error() {
+				t.Errorf("unexpected health error of suspended volume: %q", v.GetHealthError())
+			}
+		}
+		if len(v.GetAttr()) != 10 {
+			t.Errorf("unexpected attr of %s: %q", v.GetName(), v.GetAttr())
+		}
+	}
+}
+
+func TestVGServiceGetLVListPoolHealthWithFakeLVM(t *testing.T) {
+	ctx := ctrl.LoggerInto(context.Background(), testr.New(t))
+
+	fake := command.NewFakeLVM()
+	fake.AddVolumeGroup("fake-vg", 4<<30)
+	defer command.SetExecutor(fake)()
+
+	vgService, _ := NewVGService(NewDeviceClassManager([]*lvmdTypes.DeviceClass{
+		{Name: "thin", VolumeGroup: "fake-vg", Type: lvmdTypes.TypeThin, ThinPoolConfig: &lvmdTypes.ThinPoolConfig{Name: "pool0", OverprovisionRatio: 5}},
+	}), NewLvcreateOptionClassManager(nil))
+	vg, err := command.FindVolumeGroup(ctx, "fake-vg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := vg.CreatePool(ctx, "pool0", 1<<30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.CreateVolume(ctx, "thin1", 1<<30, nil, 0, "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	poolHealthError := func() string {
+		t.Helper()
+		res, err := vgService.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: "thin"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res.GetVolumes()) != 1 {
+			t.Fatalf("unexpected volumes: %v", res.GetVolumes())
+		}
+		if res.GetVolumes()[0].GetHealthError() != "" {
+			t.Errorf("thin volume should have no health error: %s", res.GetVolumes()[0].GetHealthError())
+		}
+		return res.GetVolumes()[0].GetPoolHealthError()
+	}
+	if msg := poolHealthError(); msg != "" {
+		t.Errorf("pool should be healthy: %s", msg)
+	}
+	if err := fake.SetDataPercent("fake-vg", "pool0", 100); err != nil {
+		t.Fatal(err)
+	}
+	if msg := poolHealthError(); msg != command.ErrThinPoolOutOfDataSpace.Error() {
+		t.Errorf("unexpected pool health error: %q", msg)
+	}
+}
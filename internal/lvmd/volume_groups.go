@@ -0,0 +1,138 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+// deviceClassVolumeGroups returns the volume groups of the device-class in the order of the configuration.
+// The volume groups of a device-class backed by multiple ones are reported together, so they have to be looked up
+// again with command.FindVolumeGroup to see the changes made to them.
+func deviceClassVolumeGroups(ctx context.Context, dc *lvmdTypes.DeviceClass) ([]*command.VolumeGroup, error) {
+	names := VolumeGroupNames(dc)
+	if len(names) == 1 {
+		vg, err := command.FindVolumeGroup(ctx, names[0])
+		if err != nil {
+			return nil, err
+		}
+		return []*command.VolumeGroup{vg}, nil
+	}
+
+	all, err := command.ListVolumeGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vgs := make([]*command.VolumeGroup, 0, len(names))
+	for _, name := range names {
+		vg, err := command.SearchVolumeGroupList(all, name)
+		if err != nil {
+			return nil, fmt.Errorf("volume group %s: %w", name, err)
+		}
+		vgs = append(vgs, vg)
+	}
+	return vgs, nil
+}
+
+// findVolumeGroupOf returns the volume group of the device-class containing the logical volume name.
+// The first volume group is returned if none contains it, so that looking up the volume reports it as not found.
+func findVolumeGroupOf(ctx context.Context, dc *lvmdTypes.DeviceClass, name string) (*command.VolumeGroup, error) {
+	vgs, err := deviceClassVolumeGroups(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	if len(vgs) == 1 {
+		return vgs[0], nil
+	}
+	vg, err := searchVolume(ctx, vgs, name)
+	if err != nil {
+		return nil, err
+	}
+	if vg == nil {
+		vg = vgs[0]
+	}
+	return command.FindVolumeGroup(ctx, vg.Name())
+}
+
+// searchVolume returns the volume group containing the logical volume name, or nil if none does.
+func searchVolume(ctx context.Context, vgs []*command.VolumeGroup, name string) (*command.VolumeGroup, error) {
+	for _, vg := range vgs {
+		_, err := vg.FindVolume(ctx, name)
+		if err == nil {
+			return vg, nil
+		}
+		if !errors.Is(err, command.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// volumeGroupForNewVolume returns the volume group of the device-class to create the logical volume name of
+// requested bytes in. For a device-class backed by multiple volume groups, the volume group already containing
+// the volume is returned, so that a retried request fails as with a single volume group, or otherwise the one
+// selected by the volume-group-policy. The volume group with the most free space is returned if none has enough.
+func (s *lvService) volumeGroupForNewVolume(ctx context.Context, dc *lvmdTypes.DeviceClass, name string, requested uint64) (*command.VolumeGroup, error) {
+	vgs, err := deviceClassVolumeGroups(ctx, dc)
+	if err != nil {
+		return nil, err
+	}
+	if len(vgs) == 1 {
+		return vgs[0], nil
+	}
+	existing, err := searchVolume(ctx, vgs, name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return command.FindVolumeGroup(ctx, existing.Name())
+	}
+
+	free := make([]uint64, len(vgs))
+	for i, vg := range vgs {
+		vgFree, err := vg.Free()
+		if err != nil {
+			return nil, err
+		}
+		free[i] = GetUsableBytes(dc, vgFree)
+	}
+
+	if dc.VolumeGroupPolicy == lvmdTypes.VolumeGroupPolicyRoundRobin {
+		start := s.nextVolumeGroup(dc.Name, len(vgs))
+		for i := range vgs {
+			j := (start + i) % len(vgs)
+			if free[j] >= requested {
+				return command.FindVolumeGroup(ctx, vgs[j].Name())
+			}
+		}
+	}
+
+	mostFree := 0
+	for i := range vgs {
+		if free[i] > free[mostFree] {
+			mostFree = i
+		}
+	}
+	return command.FindVolumeGroup(ctx, vgs[mostFree].Name())
+}
+
+// nextVolumeGroup returns the index of the volume group to try first for the round-robin policy of the device-class.
+func (s *lvService) nextVolumeGroup(deviceClass string, n int) int {
+	s.roundRobinMu.Lock()
+	defer s.roundRobinMu.Unlock()
+	next := s.roundRobin[deviceClass] % n
+	s.roundRobin[deviceClass] = next + 1
+	return next
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
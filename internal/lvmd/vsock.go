@@ -0,0 +1,222 @@
+package lvmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// VsockAddr is the address of an AF_VSOCK socket, which connects virtual machines and their host.
+type VsockAddr struct {
+	// CID is the context identifier of the virtual machine, or 2 for the host.
+	CID  uint32
+	Port uint32
+}
+
+// Network implements net.Addr.
+func (a *VsockAddr) Network() string {
+	return "vsock"
+}
+
+// String implements net.Addr.
+func (a *VsockAddr) String() string {
+	return fmt.Sprintf("%d:%d", a.CID, a.Port)
+}
+
+// ParseVsockAddr parses an address of the form "CID:port", e.g. "2:1024" for port 1024 of the host.
+func ParseVsockAddr(s string) (*VsockAddr, error) {
+	cid, port, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid vsock address %q: expected CID:port", s)
+	}
+	c, err := strconv.ParseUint(cid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CID of vsock address %q: %w", s, err)
+	}
+	p, err := strconv.ParseUint(port, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port of vsock address %q: %w", s, err)
+	}
+	return &VsockAddr{CID: uint32(c), Port: uint32(p)}, nil
+}
+
+// ListenVsock listens on the port of all the CIDs of this machine.
+// Connections from virtual machines not in allowedCIDs are closed, unless allowedCIDs is empty.
+func ListenVsock(port uint32, allowedCIDs []uint32) (net.Listener, error) {
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrVM{CID: unix.VMADDR_CID_ANY, Port: port}); err != nil {
+		unix.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	if err := unix.Listen(fd, unix.SOMAXCONN); err != nil {
+		unix.Close(fd)
+		return nil, os.NewSyscallError("listen", err)
+	}
+	addr := &VsockAddr{CID: unix.VMADDR_CID_ANY, Port: port}
+	if sa, err := unix.Getsockname(fd); err == nil {
+		if vm, ok := sa.(*unix.SockaddrVM); ok {
+			addr.Port = vm.Port
+		}
+	}
+
+	f := os.NewFile(uintptr(fd), "vsock:"+addr.String())
+	rc, err := f.SyscallConn()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	allowed := make(map[uint32]bool, len(allowedCIDs))
+	for _, cid := range allowedCIDs {
+		allowed[cid] = true
+	}
+	return &vsockListener{file: f, rc: rc, addr: addr, allowed: allowed}, nil
+}
+
+type vsockListener struct {
+	file    *os.File
+	rc      syscall.RawConn
+	addr    *VsockAddr
+	allowed map[uint32]bool
+}
+
+// Accept implements net.Listener.
+func (l *vsockListener) Accept() (net.Conn, error) {
+	for {
+		var nfd int
+		var sa unix.Sockaddr
+		var err error
+		rerr := l.rc.Read(func(fd uintptr) bool {
+			nfd, sa, err = unix.Accept4(int(fd), unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC)
+			return err != unix.EAGAIN
+		})
+		if rerr != nil {
+			return nil, rerr
+		}
+		if err != nil {
+			return nil, os.NewSyscallError("accept4", err)
+		}
+
+		remote := &VsockAddr{}
+		if vm, ok := sa.(*unix.SockaddrVM); ok {
+			remote.CID, remote.Port = vm.CID, vm.Port
+		}
+		if len(l.allowed) != 0 && !l.allowed[remote.CID] {
+			unix.Close(nfd)
+			continue
+		}
+		return &vsockConn{File: os.NewFile(uintptr(nfd), "vsock:"+remote.String()), local: l.addr, remote: remote}, nil
+	}
+}
+
+// Close implements net.Listener.
+func (l *vsockListener) Close() error {
+	return l.file.Close()
+}
+
+// Addr implements net.Listener.
+func (l *vsockListener) Addr() net.Addr {
+	return l.addr
+}
+
+// DialVsock connects to the address over AF_VSOCK.
+func DialVsock(ctx context.Context, addr *VsockAddr) (net.Conn, error) {
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_NONBLOCK|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	err = unix.Connect(fd, &unix.SockaddrVM{CID: addr.CID, Port: addr.Port})
+	if err != nil && err != unix.EINPROGRESS {
+		unix.Close(fd)
+		return nil, os.NewSyscallError("connect", err)
+	}
+	f := os.NewFile(uintptr(fd), "vsock:"+addr.String())
+	if err == unix.EINPROGRESS {
+		if err := waitConnected(ctx, f); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+
+	local := &VsockAddr{}
+	if sa, err := unix.Getsockname(fd); err == nil {
+		if vm, ok := sa.(*unix.SockaddrVM); ok {
+			local.CID, local.Port = vm.CID, vm.Port
+		}
+	}
+	return &vsockConn{File: f, local: local, remote: addr}, nil
+}
+
+// waitConnected waits for the non-blocking connect of the socket to complete or ctx to be done.
+func waitConnected(ctx context.Context, f *os.File) error {
+	rc, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = f.SetWriteDeadline(deadline)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// interrupt the wait.
+			_ = f.SetWriteDeadline(time.Unix(1, 0))
+		case <-done:
+		}
+	}()
+
+	var connErr error
+	err = rc.Write(func(fd uintptr) bool {
+		soErr, err := unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_ERROR)
+		if err != nil {
+			connErr = os.NewSyscallError("getsockopt", err)
+			return true
+		}
+		if soErr != 0 {
+			connErr = os.NewSyscallError("connect", syscall.Errno(soErr))
+			return true
+		}
+		// the connection is established once the peer is known.
+		_, err = unix.Getpeername(int(fd))
+		return !errors.Is(err, unix.ENOTCONN)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	if connErr != nil {
+		return connErr
+	}
+	return f.SetWriteDeadline(time.Time{})
+}
+
+// vsockConn is a connected AF_VSOCK socket. The deadlines are supported by os.File for non-blocking sockets.
+type vsockConn struct {
+	*os.File
+	local  *VsockAddr
+	remote *VsockAddr
+}
+
+// LocalAddr implements net.Conn.
+func (c *vsockConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr implements net.Conn.
+func (c *vsockConn) RemoteAddr() net.Addr {
+	return c.remote
+}
@@ -0,0 +1,73 @@
+package lvmd
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseVsockAddr(t *testing.T) {
+	addr, err := ParseVsockAddr("2:1024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.CID != 2 || addr.Port != 1024 {
+		t.Errorf("unexpected address: %s", addr)
+	}
+	for _, s := range []string{"", "2", "host:1024", "2:port", "2:4294967296"} {
+		if _, err := ParseVsockAddr(s); err == nil {
+			t.Errorf("%q should be invalid", s)
+		}
+	}
+}
+
+func TestVsockLoopback(t *testing.T) {
+	lis, err := ListenVsock(unix.VMADDR_PORT_ANY, nil)
+	if err != nil {
+		t.Skipf("vsock is not available: %v", err)
+	}
+	defer lis.Close()
+	port := lis.Addr().(*VsockAddr).Port
+
+	accepted := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			accepted <- err
+			return
+		}
+		defer conn.Close()
+		_, err = io.Copy(conn, conn)
+		accepted <- err
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	start := time.Now()
+	conn, err := DialVsock(ctx, &VsockAddr{CID: unix.VMADDR_CID_LOCAL, Port: port})
+	if err != nil {
+		// the dial must respect the context even if vsock loopback is not supported.
+		if time.Since(start) > 4*time.Second {
+			t.Errorf("dial did not return on the deadline: %v", err)
+		}
+		t.Skipf("vsock loopback is not available: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if err := conn.SetReadDeadline(time.Now().Add(3 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		t.Fatal(err)
+	}
+	if string(buf) != "ping" {
+		t.Errorf("unexpected echo: %q", buf)
+	}
+}
@@ -0,0 +1,301 @@
+package preflight
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	// StatusPass means the node meets the requirement.
+	StatusPass Status = "pass"
+	// StatusWarn means the node meets the requirement, but something may need attention.
+	StatusWarn Status = "warn"
+	// StatusFail means the node does not meet the requirement.
+	StatusFail Status = "fail"
+)
+
+// DefaultModules are the kernel modules of the device-mapper targets used by TopoLVM.
+var DefaultModules = []string{"dm_thin_pool", "dm_snapshot", "dm_crypt"}
+
+// nsenterArgs are the arguments of nsenter to run a command on the host, as lvmd does if it is containerized.
+var nsenterArgs = []string{"-m", "-u", "-i", "-n", "-p", "-t", "1"}
+
+// Config is the configuration of the checks.
+type Config struct {
+	// Modules are the kernel modules that must be loaded or loadable.
+	Modules []string
+	// MinLVMVersion is the minimum version of lvm2.
+	MinLVMVersion string
+	// MinThinToolsVersion is the minimum version of thin-provisioning-tools. Empty skips the check.
+	MinThinToolsVersion string
+	// Containerized tells that lvm is executed on the host with nsenter, like lvmd with the same option.
+	Containerized bool
+	// LVMPath is the path of lvm, on the host if Containerized is true.
+	LVMPath string
+	// NsenterPath is the path of nsenter.
+	NsenterPath string
+}
+
+// Env is the environment the checks inspect.
+type Env struct {
+	// Root is the root of /proc and /sys of the node.
+	Root string
+	// HostRoot is the root filesystem of the host, e.g. /proc/1/root in a container sharing the PID namespace of the host.
+	HostRoot string
+	// Run runs a command and returns its combined output.
+	Run func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+// Result is the result of a check.
+type Result struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+	Version string `json:"version,omitempty"`
+}
+
+// Report is the machine-readable report of the checks.
+type Report struct {
+	Node   string   `json:"node,omitempty"`
+	Kernel string   `json:"kernel,omitempty"`
+	Ready  bool     `json:"ready"`
+	Checks []Result `json:"checks"`
+}
+
+// Run checks whether the node can run lvmd and topolvm-node with the configuration.
+// The node is ready if no check fails.
+func Run(ctx context.Context, cfg Config, env Env) *Report {
+	report := &Report{Ready: true}
+	if data, err := os.ReadFile(filepath.Join(env.Root, "/proc/sys/kernel/osrelease")); err == nil {
+		report.Kernel = strings.TrimSpace(string(data))
+	}
+
+	for _, module := range cfg.Modules {
+		report.Checks = append(report.Checks, checkModule(env, report.Kernel, module))
+	}
+	if cfg.Containerized {
+		report.Checks = append(report.Checks, checkNsenter(ctx, cfg, env))
+	}
+	report.Checks = append(report.Checks, checkLVM(ctx, cfg, env))
+	if cfg.MinThinToolsVersion != "" {
+		report.Checks = append(report.Checks, checkThinTools(ctx, cfg, env))
+	}
+	report.Checks = append(report.Checks, checkDevicesFile(ctx, cfg, env))
+
+	for _, r := range report.Checks {
+		if r.Status == StatusFail {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// host runs the command on the host if cfg.Containerized is true.
+func host(ctx context.Context, cfg Config, env Env, name string, args ...string) ([]byte, error) {
+	if cfg.Containerized {
+		args = append(append(append([]string{}, nsenterArgs...), name), args...)
+		name = cfg.NsenterPath
+	}
+	return env.Run(ctx, name, args...)
+}
+
+// checkModule checks that the kernel module is loaded, built into the kernel or can be loaded on demand.
+func checkModule(env Env, kernel, module string) Result {
+	r := Result{Name: "kernel-module/" + module}
+	if _, err := os.Stat(filepath.Join(env.Root, "/sys/module", module)); err == nil {
+		r.Status, r.Message = StatusPass, "loaded"
+		return r
+	}
+
+	// the files of modules.builtin and modules.dep use the file names, in which "_" may be "-".
+	dir := filepath.Join(env.HostRoot, "/lib/modules", kernel)
+	if kernel != "" && moduleListed(filepath.Join(dir, "modules.builtin"), module) {
+		r.Status, r.Message = StatusPass, "built into the kernel"
+		return r
+	}
+	if kernel != "" && moduleListed(filepath.Join(dir, "modules.dep"), module) {
+		r.Status, r.Message = StatusWarn, "not loaded, but is loaded on demand"
+		return r
+	}
+	r.Status, r.Message = StatusFail, fmt.Sprintf("neither loaded nor available for kernel %q", kernel)
+	return r
+}
+
+// moduleListed returns true if the module is in the list of modules.builtin or modules.dep.
+func moduleListed(file, module string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		path, _, _ := strings.Cut(scanner.Text(), ":")
+		name := filepath.Base(path)
+		name, _, _ = strings.Cut(name, ".ko")
+		if strings.ReplaceAll(name, "-", "_") == module {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNsenter checks that nsenter can enter the namespaces of the host.
+func checkNsenter(ctx context.Context, cfg Config, env Env) Result {
+	r := Result{Name: "nsenter"}
+	if out, err := host(ctx, cfg, env, "true"); err != nil {
+		r.Status, r.Message = StatusFail, commandError("failed to enter the namespaces of the host with "+cfg.NsenterPath, err, out)
+		return r
+	}
+	r.Status, r.Message = StatusPass, "entered the namespaces of the host with "+cfg.NsenterPath
+	return r
+}
+
+var lvmVersionPattern = regexp.MustCompile(`LVM version:\s*(\S+)`)
+
+// checkLVM checks the version of lvm2.
+func checkLVM(ctx context.Context, cfg Config, env Env) Result {
+	r := Result{Name: "lvm2"}
+	out, err := host(ctx, cfg, env, cfg.LVMPath, "version")
+	if err != nil {
+		r.Status, r.Message = StatusFail, commandError("failed to run "+cfg.LVMPath, err, out)
+		return r
+	}
+	m := lvmVersionPattern.FindSubmatch(out)
+	if m == nil {
+		r.Status, r.Message = StatusFail, fmt.Sprintf("unknown output of lvm version: %s", bytes.TrimSpace(out))
+		return r
+	}
+	r.Version = string(m[1])
+	return checkVersion(r, cfg.MinLVMVersion)
+}
+
+// checkThinTools checks the version of thin_check of thin-provisioning-tools, which lvm runs to activate thin pools.
+func checkThinTools(ctx context.Context, cfg Config, env Env) Result {
+	r := Result{Name: "thin-provisioning-tools"}
+	thinCheck := "thin_check"
+	if value, ok := lvmConfig(ctx, cfg, env, "global/thin_check_executable"); ok && value != "" {
+		thinCheck = value
+	}
+	out, err := host(ctx, cfg, env, thinCheck, "-V")
+	if err != nil {
+		r.Status, r.Message = StatusFail, commandError("failed to run "+thinCheck, err, out)
+		return r
+	}
+	r.Version = strings.TrimSpace(string(out))
+	return checkVersion(r, cfg.MinThinToolsVersion)
+}
+
+// checkDevicesFile reports the configuration of the devices file of lvm, which hides the devices not listed in it.
+func checkDevicesFile(ctx context.Context, cfg Config, env Env) Result {
+	r := Result{Name: "devices-file"}
+	value, ok := lvmConfig(ctx, cfg, env, "devices/use_devicesfile")
+	if !ok {
+		r.Status, r.Message = StatusPass, "not known to lvm, all devices are visible to lvm"
+		return r
+	}
+	if value != "1" {
+		r.Status, r.Message = StatusPass, "disabled, all devices are visible to lvm"
+		return r
+	}
+
+	name := "system.devices"
+	if value, ok := lvmConfig(ctx, cfg, env, "devices/devicesfile"); ok && value != "" {
+		name = value
+	}
+	path := filepath.Join("/etc/lvm/devices", name)
+	data, err := os.ReadFile(filepath.Join(env.HostRoot, path))
+	if os.IsNotExist(err) {
+		r.Status, r.Message = StatusPass, fmt.Sprintf("enabled, but %s does not exist, so all devices are visible to lvm", path)
+		return r
+	} else if err != nil {
+		r.Status, r.Message = StatusFail, fmt.Sprintf("failed to read %s: %v", path, err)
+		return r
+	}
+
+	var devices int
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "IDTYPE=") {
+			devices++
+		}
+	}
+	if devices == 0 {
+		r.Status, r.Message = StatusWarn, fmt.Sprintf("enabled, but %s lists no devices, so no physical volume is visible to lvm", path)
+		return r
+	}
+	r.Status, r.Message = StatusPass, fmt.Sprintf("enabled, %s lists %d device(s)", path, devices)
+	return r
+}
+
+// commandError returns the message of a failed command with its output.
+func commandError(msg string, err error, out []byte) string {
+	if out = bytes.TrimSpace(out); len(out) != 0 {
+		return fmt.Sprintf("%s: %v: %s", msg, err, out)
+	}
+	return fmt.Sprintf("%s: %v", msg, err)
+}
+
+// lvmConfig returns the value of the lvm configuration, or false if lvm does not know the setting.
+func lvmConfig(ctx context.Context, cfg Config, env Env, key string) (string, bool) {
+	out, err := host(ctx, cfg, env, cfg.LVMPath, "config", "--typeconfig", "full", key)
+	if err != nil {
+		return "", false
+	}
+	_, value, ok := strings.Cut(strings.TrimSpace(string(out)), "=")
+	if !ok {
+		return "", false
+	}
+	return strings.Trim(value, `"`), true
+}
+
+// checkVersion sets the status of r by comparing r.Version with minVersion.
+func checkVersion(r Result, minVersion string) Result {
+	if minVersion != "" && compareVersions(r.Version, minVersion) < 0 {
+		r.Status, r.Message = StatusFail, fmt.Sprintf("version %s is older than %s", r.Version, minVersion)
+		return r
+	}
+	r.Status, r.Message = StatusPass, "version "+r.Version
+	return r
+}
+
+// compareVersions compares the dotted numeric versions such as "2.03.16(2)" by their components.
+// Anything following the numbers of a component is ignored.
+func compareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x = leadingNumber(as[i])
+		}
+		if i < len(bs) {
+			y = leadingNumber(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func leadingNumber(s string) int {
+	end := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		s = s[:end]
+	}
+	n, _ := strconv.Atoi(s)
+	return n
+}
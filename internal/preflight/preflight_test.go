@@ -0,0 +1,125 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "/proc/sys/kernel/osrelease"), "6.1.0-13-amd64\n")
+	if err := os.MkdirAll(filepath.Join(root, "/sys/module/dm_snapshot"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(root, "/lib/modules/6.1.0-13-amd64/modules.builtin"), "kernel/drivers/md/dm-crypt.ko\n")
+	writeFile(t, filepath.Join(root, "/lib/modules/6.1.0-13-amd64/modules.dep"),
+		"kernel/drivers/md/persistent-data/dm-persistent-data.ko:\nkernel/drivers/md/dm-thin-pool.ko: kernel/drivers/md/persistent-data/dm-persistent-data.ko\n")
+	writeFile(t, filepath.Join(root, "/etc/lvm/devices/system.devices"), "VERSION=1.1.2\n")
+
+	var commands []string
+	outputs := map[string]string{
+		"lvm version": "  LVM version:     2.03.16(2) (2022-05-18)\n  Library version: 1.02.185 (2022-05-18)\n",
+		"lvm config --typeconfig full global/thin_check_executable": `thin_check_executable="/usr/sbin/thin_check"`,
+		"lvm config --typeconfig full devices/use_devicesfile":      "use_devicesfile=1",
+		"/usr/sbin/thin_check -V":                                   "0.9.0\n",
+	}
+	env := Env{
+		Root:     root,
+		HostRoot: root,
+		Run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+			command := strings.Join(append([]string{name}, args...), " ")
+			commands = append(commands, command)
+			if command, ok := strings.CutPrefix(command, "nsenter -m -u -i -n -p -t 1 "); ok {
+				if command == "true" {
+					return nil, nil
+				}
+				if out, ok := outputs[command]; ok {
+					return []byte(out), nil
+				}
+			}
+			return []byte("Configuration node not found"), errors.New("exit status 5")
+		},
+	}
+	cfg := Config{
+		Modules:             append(append([]string{}, DefaultModules...), "dm_vdo"),
+		MinLVMVersion:       "2.03.0",
+		MinThinToolsVersion: "0.7.0",
+		Containerized:       true,
+		LVMPath:             "lvm",
+		NsenterPath:         "nsenter",
+	}
+
+	report := Run(context.Background(), cfg, env)
+	if report.Ready {
+		t.Error("the node should not be ready without dm_vdo")
+	}
+	if report.Kernel != "6.1.0-13-amd64" {
+		t.Errorf("unexpected kernel: %s", report.Kernel)
+	}
+	expected := map[string]Status{
+		"kernel-module/dm_thin_pool": StatusWarn,
+		"kernel-module/dm_snapshot":  StatusPass,
+		"kernel-module/dm_crypt":     StatusPass,
+		"kernel-module/dm_vdo":       StatusFail,
+		"nsenter":                    StatusPass,
+		"lvm2":                       StatusPass,
+		"thin-provisioning-tools":    StatusPass,
+		"devices-file":               StatusWarn,
+	}
+	if len(report.Checks) != len(expected) {
+		t.Errorf("unexpected checks: %+v", report.Checks)
+	}
+	for _, r := range report.Checks {
+		if r.Status != expected[r.Name] {
+			t.Errorf("unexpected status of %s: %s, %s", r.Name, r.Status, r.Message)
+		}
+	}
+	for _, command := range commands {
+		if !strings.HasPrefix(command, "nsenter ") {
+			t.Errorf("command should run on the host: %s", command)
+		}
+	}
+
+	cfg.Modules = DefaultModules
+	cfg.MinLVMVersion = "2.03.21"
+	report = Run(context.Background(), cfg, env)
+	if report.Ready {
+		t.Error("the node should not be ready with an old lvm")
+	}
+	for _, r := range report.Checks {
+		if r.Name == "lvm2" && (r.Status != StatusFail || r.Version != "2.03.16(2)") {
+			t.Errorf("unexpected result of lvm2: %+v", r)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"2.03.16(2)", "2.03.0", 1},
+		{"2.02.187(2)", "2.03.0", -1},
+		{"0.9.0", "0.9", 0},
+		{"1.0.4", "0.9.0", 1},
+	}
+	for _, tt := range tests {
+		if actual := compareVersions(tt.a, tt.b); actual != tt.expected {
+			t.Errorf("compareVersions(%q, %q) = %d, expected %d", tt.a, tt.b, actual, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package runners
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var deregisterLogger = ctrl.Log.WithName("runners").WithName("deregister")
+
+// Deregister withdraws the node from the scheduling of new volumes when topolvm-node terminates.
+// It sets the capacity annotations of the node to zero, so that the scheduler stops considering the node immediately
+// rather than relying on the annotations going stale. They are restored once topolvm-node starts again.
+// The CSINode is left to kubelet and the node-driver-registrar, which would not register the driver again
+// if only topolvm-node restarted.
+func Deregister(ctx context.Context, c client.Client, nodeName string) error {
+	if err := flushCapacity(ctx, c, nodeName); err != nil {
+		return fmt.Errorf("failed to flush the capacity annotations: %w", err)
+	}
+	return nil
+}
+
+func flushCapacity(ctx context.Context, c client.Client, nodeName string) error {
+	var node metav1.PartialObjectMetadata
+	node.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+	if err := c.Get(ctx, types.NamespacedName{Name: nodeName}, &node); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	node2 := node.DeepCopy()
+	var flushed []string
+	for key, value := range node2.Annotations {
+		if (strings.HasPrefix(key, topolvm.GetCapacityKeyPrefix()) || strings.HasPrefix(key, topolvm.GetThickCapacityKeyPrefix())) && value != "0" {
+			node2.Annotations[key] = "0"
+			flushed = append(flushed, key)
+		}
+	}
+	if len(flushed) == 0 {
+		return nil
+	}
+	if err := c.Patch(ctx, node2, client.MergeFrom(&node)); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	deregisterLogger.Info("flushed capacity annotations", "node", nodeName, "annotations", flushed)
+	return nil
+}
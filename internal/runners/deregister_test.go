@@ -0,0 +1,97 @@
+package runners
+
+import (
+	"context"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDeregister(t *testing.T) {
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{corev1.AddToScheme, storagev1.AddToScheme} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	capacityKey := topolvm.GetCapacityKeyPrefix() + "ssd"
+	thickKey := topolvm.GetThickCapacityKeyPrefix() + "ssd"
+	flushedKey := topolvm.GetCapacityKeyPrefix() + "hdd"
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+			Annotations: map[string]string{
+				capacityKey:   "1073741824",
+				thickKey:      "2147483648",
+				flushedKey:    "0",
+				"example.com": "keep",
+			},
+		},
+	}
+	csiNode := &storagev1.CSINode{
+		ObjectMeta: metav1.ObjectMeta{Name: "node1"},
+		Spec: storagev1.CSINodeSpec{
+			Drivers: []storagev1.CSINodeDriver{{Name: topolvm.GetPluginName(), NodeID: "node1"}},
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(node, csiNode).Build()
+	ctx := context.Background()
+
+	if err := Deregister(ctx, c, "node1"); err != nil {
+		t.Fatal(err)
+	}
+
+	var got corev1.Node
+	if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]string{
+		capacityKey:   "0",
+		thickKey:      "0",
+		flushedKey:    "0",
+		"example.com": "keep",
+	} {
+		if got.Annotations[key] != expected {
+			t.Errorf("annotation %s: expected %q, actual %q", key, expected, got.Annotations[key])
+		}
+	}
+
+	var gotCSINode storagev1.CSINode
+	if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &gotCSINode); err != nil {
+		t.Fatal(err)
+	}
+	if len(gotCSINode.Spec.Drivers) != 1 || gotCSINode.Spec.Drivers[0].Name != topolvm.GetPluginName() {
+		t.Errorf("CSINode should be left alone: %v", gotCSINode.Spec.Drivers)
+	}
+
+	// a second call finds nothing to flush
+	rv := got.ResourceVersion
+	if err := Deregister(ctx, c, "node1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.ResourceVersion != rv {
+		t.Errorf("node should not be patched when the annotations are already zero")
+	}
+}
+
+func TestFlushCapacityNodeNotFound(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	if err := flushCapacity(context.Background(), c, "missing"); err != nil {
+		t.Errorf("a deleted node should be ignored: %v", err)
+	}
+}
@@ -13,6 +13,7 @@ type gRPCServerRunner struct {
 	srv            *grpc.Server
 	sockFile       string
 	leaderElection bool
+	ready          <-chan struct{}
 }
 
 var _ manager.LeaderElectionRunnable = gRPCServerRunner{}
@@ -21,11 +22,26 @@ var _ manager.LeaderElectionRunnable = gRPCServerRunner{}
 // The server will listen on UNIX domain socket at sockFile.
 // If leaderElection is true, the server will run only when it is elected as leader.
 func NewGRPCRunner(srv *grpc.Server, sockFile string, leaderElection bool) manager.Runnable {
-	return gRPCServerRunner{srv, sockFile, leaderElection}
+	return gRPCServerRunner{srv, sockFile, leaderElection, nil}
+}
+
+// NewGRPCRunnerAfter is the same as NewGRPCRunner, but the server will not listen on
+// the socket until ready is closed, e.g. by WarmUp, so that no request is accepted before.
+// A nil ready does not delay the server.
+func NewGRPCRunnerAfter(srv *grpc.Server, sockFile string, leaderElection bool, ready <-chan struct{}) manager.Runnable {
+	return gRPCServerRunner{srv, sockFile, leaderElection, ready}
 }
 
 // Start implements controller-runtime's manager.Runnable.
 func (r gRPCServerRunner) Start(ctx context.Context) error {
+	if r.ready != nil {
+		select {
+		case <-r.ready:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+
 	err := os.Remove(r.sockFile)
 	if err != nil && !os.IsNotExist(err) {
 		return err
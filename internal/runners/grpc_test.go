@@ -0,0 +1,68 @@
+package runners
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+func TestGRPCRunnerAfter(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "csi.sock")
+	ready := make(chan struct{})
+	r := NewGRPCRunnerAfter(grpc.NewServer(), sockFile, false, ready)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- r.Start(ctx)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(sockFile); !os.IsNotExist(err) {
+		t.Fatalf("the socket should not be listened on before ready is closed: %v", err)
+	}
+
+	close(ready)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(sockFile); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the socket is not listened on after ready is closed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("unexpected error on stop: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the server did not stop")
+	}
+}
+
+func TestGRPCRunnerAfterCanceled(t *testing.T) {
+	sockFile := filepath.Join(t.TempDir(), "csi.sock")
+	r := NewGRPCRunnerAfter(grpc.NewServer(), sockFile, true, make(chan struct{}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.Start(ctx); err != nil {
+		t.Errorf("Start should return without error when canceled before ready: %v", err)
+	}
+	if _, err := os.Stat(sockFile); !os.IsNotExist(err) {
+		t.Errorf("the socket should not be listened on: %v", err)
+	}
+	if !r.(gRPCServerRunner).NeedLeaderElection() {
+		t.Error("leader election should be kept")
+	}
+}
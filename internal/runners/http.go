@@ -0,0 +1,40 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+type httpServerRunner struct {
+	srv            *http.Server
+	leaderElection bool
+}
+
+var _ manager.LeaderElectionRunnable = httpServerRunner{}
+
+// NewHTTPRunner creates controller-runtime's manager.Runnable for an HTTP server listening on srv.Addr.
+// If leaderElection is true, the server will run only when it is elected as leader.
+func NewHTTPRunner(srv *http.Server, leaderElection bool) manager.Runnable {
+	return httpServerRunner{srv, leaderElection}
+}
+
+// Start implements controller-runtime's manager.Runnable.
+func (r httpServerRunner) Start(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		_ = r.srv.Shutdown(context.Background())
+	}()
+
+	if err := r.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// NeedLeaderElection implements controller-runtime's manager.LeaderElectionRunnable.
+func (r httpServerRunner) NeedLeaderElection() bool {
+	return r.leaderElection
+}
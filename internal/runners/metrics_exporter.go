@@ -2,8 +2,14 @@ package runners
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
+	"reflect"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/topolvm/topolvm"
@@ -25,6 +31,10 @@ const (
 
 	TypeThick = "thick"
 	TypeThin  = "thin"
+
+	// emergencyCheckInterval is the interval to check the usage of thin pools against the critical threshold
+	// between the reports of lvmd.
+	emergencyCheckInterval = 10 * time.Second
 )
 
 var meLogger = ctrl.Log.WithName("runners").WithName("metrics_exporter")
@@ -39,6 +49,9 @@ type NodeMetrics struct {
 	OverProvisionBytes uint64
 	DeviceClass        string
 	DeviceClassType    string
+	// VDO is set for device classes with VDO.
+	VDO              bool
+	VDOSavingPercent float64
 }
 
 // thinPoolMetricsExporter is the subset of metricsExporter corresponding to the deviceclass target
@@ -56,13 +69,23 @@ type metricsExporter struct {
 	availableBytes *prometheus.GaugeVec
 	sizeBytes      *prometheus.GaugeVec
 	thinPool       *thinPoolMetricsExporter
+	vdoSaving      *prometheus.GaugeVec
+	// criticalThreshold is the data or metadata usage of thin pools in percent
+	// above which no new volume should be scheduled to the device class.
+	criticalThreshold float64
+
+	mu sync.Mutex
+	// thinDeviceClasses holds the thin device classes last reported by lvmd, and whether each is the default.
+	thinDeviceClasses map[string]bool
 }
 
 var _ manager.LeaderElectionRunnable = &metricsExporter{}
 
 // NewMetricsExporter creates controller-runtime's manager.Runnable to run
 // a metrics exporter for a node.
-func NewMetricsExporter(vgServiceClient proto.VGServiceClient, client client.Client, nodeName string) manager.Runnable {
+// If criticalThreshold is positive, device classes whose thin pool usage reaches it in percent are
+// marked with the capacity emergency annotation on the node.
+func NewMetricsExporter(vgServiceClient proto.VGServiceClient, client client.Client, nodeName string, criticalThreshold float64) manager.Runnable {
 	// metrics available under volumegroup subsystem
 	availableBytes := prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace:   metricsNamespace,
@@ -113,6 +136,15 @@ func NewMetricsExporter(vgServiceClient proto.VGServiceClient, client client.Cli
 		ConstLabels: prometheus.Labels{"node": nodeName},
 	}, []string{"device_class"})
 
+	// metrics available under vdo subsystem
+	vdoSaving := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   metricsNamespace,
+		Subsystem:   "vdo",
+		Name:        "saving_percent",
+		Help:        "LVM VDO space saving percent of the volumes",
+		ConstLabels: prometheus.Labels{"node": nodeName},
+	}, []string{"device_class"})
+
 	return &metricsExporter{
 		client:         client,
 		nodeName:       nodeName,
@@ -125,6 +157,8 @@ func NewMetricsExporter(vgServiceClient proto.VGServiceClient, client client.Cli
 			metadataPercent:  metadataPercent,
 			opAvailableBytes: opAvailableBytes,
 		},
+		vdoSaving:         vdoSaving,
+		criticalThreshold: criticalThreshold,
 	}
 }
 
@@ -136,6 +170,7 @@ func (m *metricsExporter) getCollectors() []prometheus.Collector {
 		m.thinPool.dataPercent,
 		m.thinPool.metadataPercent,
 		m.thinPool.opAvailableBytes,
+		m.vdoSaving,
 	}
 }
 
@@ -180,6 +215,10 @@ func (m *metricsExporter) Start(ctx context.Context) error {
 					m.thinPool.metadataPercent.WithLabelValues(met.DeviceClass).Set(met.MetadataPercent)
 					m.thinPool.opAvailableBytes.WithLabelValues(met.DeviceClass).Set(float64(met.OverProvisionBytes))
 				}
+
+				if met.VDO {
+					m.vdoSaving.WithLabelValues(met.DeviceClass).Set(met.VDOSavingPercent)
+				}
 			}
 		}
 	}()
@@ -188,6 +227,9 @@ func (m *metricsExporter) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if m.criticalThreshold > 0 {
+		go m.checkEmergencies(ctx)
+	}
 	return m.updateNode(ctx, wc, metricsCh)
 }
 
@@ -209,8 +251,10 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 			return err
 		}
 
+		thinDeviceClasses := make(map[string]bool)
 		for _, item := range res.Items {
 			if item.ThinPool != nil {
+				thinDeviceClasses[item.DeviceClass] = item.Default
 				ch <- NodeMetrics{
 					DeviceClass:        item.DeviceClass,
 					FreeBytes:          item.FreeBytes,
@@ -223,13 +267,18 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 				}
 			} else {
 				ch <- NodeMetrics{
-					DeviceClass:     item.DeviceClass,
-					FreeBytes:       item.FreeBytes,
-					SizeBytes:       item.SizeBytes,
-					DeviceClassType: TypeThick,
+					DeviceClass:      item.DeviceClass,
+					FreeBytes:        item.FreeBytes,
+					SizeBytes:        item.SizeBytes,
+					DeviceClassType:  TypeThick,
+					VDO:              item.Vdo != nil,
+					VDOSavingPercent: item.GetVdo().GetSavingPercent(),
 				}
 			}
 		}
+		m.mu.Lock()
+		m.thinDeviceClasses = thinDeviceClasses
+		m.mu.Unlock()
 
 		var nodeMetadata v1.PartialObjectMetadata
 
@@ -251,11 +300,22 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 			var freeSize uint64
 			if item.ThinPool != nil {
 				freeSize = item.ThinPool.OverprovisionBytes
+				// thick volumes provisioned on the thin device-class by overriding the type take the VG free space.
+				nodeMetadata2.Annotations[topolvm.GetThickCapacityKeyPrefix()+item.DeviceClass] = strconv.FormatUint(item.FreeBytes, 10)
+				if item.Default {
+					nodeMetadata2.Annotations[topolvm.GetThickCapacityKeyPrefix()+topolvm.DefaultDeviceClassAnnotationName] = strconv.FormatUint(item.FreeBytes, 10)
+				}
 			} else {
 				freeSize = item.FreeBytes
+				// a volume of a device-class backed by multiple volume groups fits into one of them.
+				if item.LargestFreeBytes != 0 {
+					freeSize = item.LargestFreeBytes
+				}
 			}
 			nodeMetadata2.Annotations[topolvm.GetCapacityKeyPrefix()+item.DeviceClass] = strconv.FormatUint(freeSize, 10)
 		}
+		m.annotateEmergencies(nodeMetadata2, res.Items)
+		annotateInlineOptions(nodeMetadata2, res.LvcreateOptionClasses)
 		if err := m.client.Patch(ctx, nodeMetadata2, client.MergeFrom(&nodeMetadata)); err != nil {
 			return err
 		}
@@ -263,3 +323,144 @@ func (m *metricsExporter) updateNode(ctx context.Context, wc proto.VGService_Wat
 
 	return nil
 }
+
+// checkEmergencies checks the usage of the thin pools every emergencyCheckInterval, so that the node is annotated
+// as soon as a thin pool crosses the critical threshold rather than on the next report of lvmd.
+func (m *metricsExporter) checkEmergencies(ctx context.Context) {
+	ticker := time.NewTicker(emergencyCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := m.patchEmergencies(ctx); err != nil {
+			meLogger.Error(err, "failed to check thin pool usage")
+		}
+	}
+}
+
+// patchEmergencies patches the capacity emergency annotations of the node from the current usage of the thin pools
+// if they have changed. The annotations of the device classes whose usage cannot be got are kept as they are.
+func (m *metricsExporter) patchEmergencies(ctx context.Context) error {
+	m.mu.Lock()
+	items := make([]*proto.WatchItem, 0, len(m.thinDeviceClasses))
+	for dc, isDefault := range m.thinDeviceClasses {
+		items = append(items, &proto.WatchItem{DeviceClass: dc, Default: isDefault})
+	}
+	m.mu.Unlock()
+
+	var checked, skipped []*proto.WatchItem
+	for _, item := range items {
+		usage, err := m.vgService.GetThinPoolUsage(ctx, &proto.GetThinPoolUsageRequest{DeviceClass: item.DeviceClass})
+		if err != nil {
+			meLogger.Error(err, "failed to get thin pool usage", "device_class", item.DeviceClass)
+			skipped = append(skipped, item)
+			continue
+		}
+		item.ThinPool = &proto.ThinPoolItem{DataPercent: usage.DataPercent, MetadataPercent: usage.MetadataPercent}
+		checked = append(checked, item)
+	}
+
+	var nodeMetadata v1.PartialObjectMetadata
+	nodeMetadata.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Node"))
+	if err := m.client.Get(ctx, types.NamespacedName{Name: m.nodeName}, &nodeMetadata); err != nil {
+		return err
+	}
+	if nodeMetadata.DeletionTimestamp != nil {
+		return nil
+	}
+	nodeMetadata2 := nodeMetadata.DeepCopy()
+	if nodeMetadata2.Annotations == nil {
+		nodeMetadata2.Annotations = make(map[string]string)
+	}
+	m.annotateEmergencies(nodeMetadata2, checked)
+	prefix := topolvm.GetCapacityEmergencyKeyPrefix()
+	for _, item := range skipped {
+		keys := []string{prefix + item.DeviceClass}
+		if item.Default {
+			keys = append(keys, prefix+topolvm.DefaultDeviceClassAnnotationName)
+		}
+		for _, key := range keys {
+			if reason, ok := nodeMetadata.Annotations[key]; ok {
+				nodeMetadata2.Annotations[key] = reason
+			}
+		}
+	}
+	if reflect.DeepEqual(emergencyAnnotations(&nodeMetadata), emergencyAnnotations(nodeMetadata2)) {
+		return nil
+	}
+	return m.client.Patch(ctx, nodeMetadata2, client.MergeFrom(&nodeMetadata))
+}
+
+// emergencyAnnotations returns the capacity emergency annotations of the node.
+func emergencyAnnotations(node *v1.PartialObjectMetadata) map[string]string {
+	annotations := make(map[string]string)
+	for key, reason := range node.Annotations {
+		if strings.HasPrefix(key, topolvm.GetCapacityEmergencyKeyPrefix()) {
+			annotations[key] = reason
+		}
+	}
+	return annotations
+}
+
+// annotateEmergencies replaces the capacity emergency annotations of the node with the device classes
+// whose thin pool usage exceeds the critical threshold.
+func (m *metricsExporter) annotateEmergencies(node *v1.PartialObjectMetadata, items []*proto.WatchItem) {
+	prefix := topolvm.GetCapacityEmergencyKeyPrefix()
+	previous := make(map[string]string)
+	for key, reason := range node.Annotations {
+		if strings.HasPrefix(key, prefix) {
+			previous[key] = reason
+			delete(node.Annotations, key)
+		}
+	}
+
+	for _, item := range items {
+		reason := m.emergencyReason(item.ThinPool)
+		if reason == "" {
+			continue
+		}
+		node.Annotations[prefix+item.DeviceClass] = reason
+		if item.Default {
+			node.Annotations[prefix+topolvm.DefaultDeviceClassAnnotationName] = reason
+		}
+		if _, ok := previous[prefix+item.DeviceClass]; !ok {
+			meLogger.Info("thin pool exceeds the critical threshold", "device_class", item.DeviceClass, "reason", reason)
+		}
+	}
+}
+
+// annotateInlineOptions replaces the annotations of the node advertising the inline lvcreate options
+// allowed by the lvcreate-option-classes, which topolvm-controller validates StorageClasses with.
+func annotateInlineOptions(node *v1.PartialObjectMetadata, classes []*proto.LvcreateOptionClassItem) {
+	prefix := topolvm.GetLvcreateInlineOptionsKeyPrefix()
+	for key := range node.Annotations {
+		if strings.HasPrefix(key, prefix) {
+			delete(node.Annotations, key)
+		}
+	}
+	for _, class := range classes {
+		data, err := json.Marshal(class.InlineOptions)
+		if err != nil {
+			meLogger.Error(err, "failed to marshal inline options", "lvcreate_option_class", class.Name)
+			continue
+		}
+		node.Annotations[prefix+class.Name] = string(data)
+	}
+}
+
+// emergencyReason returns why no new volume should be scheduled to the thin pool, or an empty string.
+func (m *metricsExporter) emergencyReason(tp *proto.ThinPoolItem) string {
+	if tp == nil || m.criticalThreshold <= 0 {
+		return ""
+	}
+	switch {
+	case tp.DataPercent >= m.criticalThreshold:
+		return fmt.Sprintf("thin pool data usage %.2f%% exceeds the critical threshold %.2f%%", tp.DataPercent, m.criticalThreshold)
+	case tp.MetadataPercent >= m.criticalThreshold:
+		return fmt.Sprintf("thin pool metadata usage %.2f%% exceeds the critical threshold %.2f%%", tp.MetadataPercent, m.criticalThreshold)
+	}
+	return ""
+}
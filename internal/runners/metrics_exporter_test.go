@@ -0,0 +1,86 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// thinPoolUsageVGService serves the thin pool usage of the device classes, failing for those without usage.
+type thinPoolUsageVGService struct {
+	proto.VGServiceClient
+	dataPercent map[string]float64
+}
+
+func (s *thinPoolUsageVGService) GetThinPoolUsage(ctx context.Context, in *proto.GetThinPoolUsageRequest, opts ...grpc.CallOption) (*proto.GetThinPoolUsageResponse, error) {
+	percent, ok := s.dataPercent[in.GetDeviceClass()]
+	if !ok {
+		return nil, errors.New("lvmd is unavailable")
+	}
+	return &proto.GetThinPoolUsageResponse{DataPercent: percent}, nil
+}
+
+func TestPatchEmergencies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	prefix := topolvm.GetCapacityEmergencyKeyPrefix()
+	previousReason := "thin pool data usage 99.00% exceeds the critical threshold 90.00%"
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node1",
+			Annotations: map[string]string{
+				prefix + "recovered": previousReason,
+				prefix + "broken":    previousReason,
+			},
+		},
+	}).Build()
+	vgService := &thinPoolUsageVGService{dataPercent: map[string]float64{"full": 95, "recovered": 50}}
+	m := NewMetricsExporter(vgService, c, "node1", 90).(*metricsExporter)
+	// the usage of broken cannot be got, so its annotation is kept.
+	m.thinDeviceClasses = map[string]bool{"full": true, "recovered": false, "broken": false}
+	ctx := context.Background()
+
+	if err := m.patchEmergencies(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var node corev1.Node
+	if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &node); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		prefix + "full":                                   "thin pool data usage 95.00% exceeds the critical threshold 90.00%",
+		prefix + topolvm.DefaultDeviceClassAnnotationName: "thin pool data usage 95.00% exceeds the critical threshold 90.00%",
+		prefix + "broken":                                 previousReason,
+	}
+	if len(node.Annotations) != len(expected) {
+		t.Errorf("unexpected annotations: %v", node.Annotations)
+	}
+	for key, reason := range expected {
+		if node.Annotations[key] != reason {
+			t.Errorf("annotation %s: expected %q, actual %q", key, reason, node.Annotations[key])
+		}
+	}
+
+	// nothing has changed, so the node is not patched.
+	rv := node.ResourceVersion
+	if err := m.patchEmergencies(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "node1"}, &node); err != nil {
+		t.Fatal(err)
+	}
+	if node.ResourceVersion != rv {
+		t.Error("the node should not be patched when the annotations have not changed")
+	}
+}
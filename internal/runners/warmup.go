@@ -0,0 +1,77 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+var warmUpLogger = ctrl.Log.WithName("runners").WithName("warm_up")
+
+type warmUp struct {
+	warmUp        func(context.Context) error
+	retryInterval time.Duration
+	done          chan struct{}
+}
+
+// WarmUp is the interface to prepare the process, e.g. fill caches, before it serves requests.
+type WarmUp interface {
+	manager.Runnable
+	// Done returns a channel closed once the warm-up function has succeeded.
+	Done() <-chan struct{}
+	// Check fails until the warm-up function has succeeded. It can be passed to manager.AddReadyzCheck.
+	Check(req *http.Request) error
+}
+
+var _ manager.LeaderElectionRunnable = &warmUp{}
+
+// NewWarmUp creates controller-runtime's manager.Runnable to run the warm-up function
+// until it succeeds, retrying at given interval.
+func NewWarmUp(fn func(context.Context) error, retryInterval time.Duration) WarmUp {
+	return &warmUp{warmUp: fn, retryInterval: retryInterval, done: make(chan struct{})}
+}
+
+// Start implements controller-runtime's manager.Runnable.
+func (w *warmUp) Start(ctx context.Context) error {
+	start := time.Now()
+	for {
+		err := w.warmUp(ctx)
+		if err == nil {
+			break
+		}
+		warmUpLogger.Error(err, "failed to warm up, retrying", "interval", w.retryInterval)
+
+		select {
+		case <-time.After(w.retryInterval):
+		case <-ctx.Done():
+			return nil
+		}
+	}
+	warmUpLogger.Info("warmed up", "duration", time.Since(start))
+	close(w.done)
+	return nil
+}
+
+// NeedLeaderElection implements controller-runtime's manager.LeaderElectionRunnable.
+func (w *warmUp) NeedLeaderElection() bool {
+	return false
+}
+
+// Done implements WarmUp.
+func (w *warmUp) Done() <-chan struct{} {
+	return w.done
+}
+
+// Check implements WarmUp.
+func (w *warmUp) Check(_ *http.Request) error {
+	select {
+	case <-w.done:
+		return nil
+	default:
+		return errors.New("not warmed up yet")
+	}
+}
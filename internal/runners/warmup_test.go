@@ -0,0 +1,58 @@
+package runners
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWarmUp(t *testing.T) {
+	attempts := 0
+	w := NewWarmUp(func(context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("cache is not synced")
+		}
+		return nil
+	}, time.Millisecond)
+
+	if err := w.Check(nil); err == nil {
+		t.Error("Check should fail before the warm-up")
+	}
+	select {
+	case <-w.Done():
+		t.Fatal("Done should not be closed before the warm-up")
+	default:
+	}
+
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 3 {
+		t.Errorf("the warm-up should be retried until it succeeds: attempts=%d", attempts)
+	}
+	if err := w.Check(nil); err != nil {
+		t.Errorf("Check should succeed after the warm-up: %v", err)
+	}
+	select {
+	case <-w.Done():
+	default:
+		t.Error("Done should be closed after the warm-up")
+	}
+}
+
+func TestWarmUpCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := NewWarmUp(func(context.Context) error {
+		cancel()
+		return errors.New("cache is not synced")
+	}, time.Hour)
+
+	if err := w.Start(ctx); err != nil {
+		t.Errorf("Start should return without error when canceled: %v", err)
+	}
+	if err := w.Check(nil); err == nil {
+		t.Error("Check should fail when the warm-up has not succeeded")
+	}
+}
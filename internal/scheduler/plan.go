@@ -0,0 +1,130 @@
+package scheduler
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// PlannedClaim is a hypothetical volume claim to be placed by Plan.
+type PlannedClaim struct {
+	// Name identifies the claim in the result.
+	Name string `json:"name"`
+	// DeviceClass is the device-class as used in capacity annotations,
+	// i.e. topolvm.DefaultDeviceClassAnnotationName for the default device-class.
+	DeviceClass string `json:"deviceClass"`
+	// Size is the requested capacity in bytes.
+	Size int64 `json:"size"`
+	// NodeSelector restricts the nodes the claim may be placed on.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+// Placement is the result of placing a PlannedClaim.
+type Placement struct {
+	Claim       string `json:"claim"`
+	DeviceClass string `json:"deviceClass"`
+	Size        int64  `json:"size"`
+	// Node is the node the claim would land on, or empty if no node has enough capacity.
+	Node string `json:"node,omitempty"`
+	// Score is the score of Node given by the prioritize verb.
+	Score int `json:"score,omitempty"`
+	// FailedNodes holds the reasons why the other nodes were filtered out.
+	FailedNodes FailedNodesMap `json:"failedNodes,omitempty"`
+}
+
+// PlanResult is the result of Plan.
+type PlanResult struct {
+	Placements []Placement `json:"placements"`
+	// Remaining maps node names to the capacity in bytes per device-class after all placements.
+	Remaining map[string]map[string]uint64 `json:"remaining"`
+}
+
+// Plan places the claims one after another on the nodes, using the same filter and score
+// as the predicate and prioritize verbs, and subtracts the capacity of each placement from
+// the node it lands on. Ties are broken by the node name, while kube-scheduler picks one of
+// the best nodes at random.
+// The nodes are not modified.
+func Plan(nodes []corev1.Node, claims []PlannedClaim, defaultDivisor float64, divisors map[string]float64) PlanResult {
+	items := make([]corev1.Node, len(nodes))
+	for i := range nodes {
+		nodes[i].DeepCopyInto(&items[i])
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	result := PlanResult{
+		Placements: make([]Placement, 0, len(claims)),
+		Remaining:  map[string]map[string]uint64{},
+	}
+	for _, claim := range claims {
+		result.Placements = append(result.Placements, placeClaim(items, claim, defaultDivisor, divisors))
+	}
+
+	for _, node := range items {
+		remaining := map[string]uint64{}
+		for k, v := range node.Annotations {
+			if !strings.HasPrefix(k, topolvm.GetCapacityKeyPrefix()) {
+				continue
+			}
+			capacity, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			remaining[k[len(topolvm.GetCapacityKeyPrefix()):]] = capacity
+		}
+		if len(remaining) != 0 {
+			result.Remaining[node.Name] = remaining
+		}
+	}
+	return result
+}
+
+func placeClaim(nodes []corev1.Node, claim PlannedClaim, defaultDivisor float64, divisors map[string]float64) Placement {
+	placement := Placement{
+		Claim:       claim.Name,
+		DeviceClass: claim.DeviceClass,
+		Size:        claim.Size,
+		FailedNodes: FailedNodesMap{},
+	}
+
+	selector := labels.SelectorFromSet(claim.NodeSelector)
+	candidates := corev1.NodeList{}
+	for i := range nodes {
+		if !selector.Matches(labels.Set(nodes[i].Labels)) {
+			placement.FailedNodes[nodes[i].Name] = "node selector mismatch"
+			continue
+		}
+		candidates.Items = append(candidates.Items, nodes[i])
+	}
+
+	filtered := filterNodes(candidates, map[string]int64{claim.DeviceClass: claim.Size})
+	for name, reason := range filtered.FailedNodes {
+		placement.FailedNodes[name] = reason
+	}
+
+	best := -1
+	for _, node := range filtered.Nodes.Items {
+		score := scoreNode(node, []string{claim.DeviceClass}, defaultDivisor, divisors)
+		if score > best {
+			best = score
+			placement.Node = node.Name
+			placement.Score = score
+		}
+	}
+	if placement.Node == "" {
+		return placement
+	}
+
+	for i := range nodes {
+		if nodes[i].Name != placement.Node {
+			continue
+		}
+		key := topolvm.GetCapacityKeyPrefix() + claim.DeviceClass
+		capacity, _ := strconv.ParseUint(nodes[i].Annotations[key], 10, 64)
+		nodes[i].Annotations[key] = strconv.FormatUint(capacity-uint64(claim.Size), 10)
+	}
+	return placement
+}
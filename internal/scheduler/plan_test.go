@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/topolvm/topolvm"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestPlan(t *testing.T) {
+	nodes := []corev1.Node{
+		testNode("10.1.1.2", 8, 10, 10),
+		testNode("10.1.1.1", 4, 10, 10),
+		testNode("10.1.1.3", 2, 10, 10),
+	}
+	nodes[2].Labels = map[string]string{"zone": "a"}
+
+	claims := []PlannedClaim{
+		{Name: "claim1", DeviceClass: "dc1", Size: 4 << 30},
+		{Name: "claim2", DeviceClass: "dc1", Size: 4 << 30},
+		{Name: "claim3", DeviceClass: "dc1", Size: 4 << 30},
+		{Name: "claim4", DeviceClass: "dc1", Size: 1 << 30, NodeSelector: map[string]string{"zone": "a"}},
+		{Name: "claim5", DeviceClass: "dc1", Size: 4 << 30},
+	}
+
+	result := Plan(nodes, claims, 1, nil)
+
+	expected := []string{"10.1.1.2", "10.1.1.1", "10.1.1.2", "10.1.1.3", ""}
+	for i, p := range result.Placements {
+		if p.Node != expected[i] {
+			t.Errorf("%s: expected node %q, actual %q", p.Claim, expected[i], p.Node)
+		}
+	}
+	if reason := result.Placements[3].FailedNodes["10.1.1.1"]; reason != "node selector mismatch" {
+		t.Errorf("unexpected reason: %s", reason)
+	}
+	if reason := result.Placements[4].FailedNodes["10.1.1.3"]; reason != "out of VG free space" {
+		t.Errorf("unexpected reason: %s", reason)
+	}
+
+	remaining := map[string]uint64{}
+	for node, capacities := range result.Remaining {
+		remaining[node] = capacities["dc1"]
+	}
+	if !reflect.DeepEqual(remaining, map[string]uint64{"10.1.1.1": 0, "10.1.1.2": 0, "10.1.1.3": 1 << 30}) {
+		t.Errorf("unexpected remaining capacity: %v", remaining)
+	}
+
+	if nodes[0].Annotations[topolvm.GetCapacityKeyPrefix()+"dc1"] != testNode("10.1.1.2", 8, 10, 10).Annotations[topolvm.GetCapacityKeyPrefix()+"dc1"] {
+		t.Error("the given nodes should not be modified")
+	}
+}
@@ -50,6 +50,9 @@ func filterNode(node corev1.Node, requested map[string]int64) string {
 		if !ok {
 			return "no capacity annotation"
 		}
+		if _, ok := node.Annotations[topolvm.GetCapacityEmergencyKeyPrefix()+dc]; ok {
+			return "capacity emergency"
+		}
 		capacity, err := strconv.ParseUint(val, 10, 64)
 		if err != nil {
 			return "bad capacity annotation: " + val
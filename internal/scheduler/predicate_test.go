@@ -92,6 +92,35 @@ func TestFilterNodes(t *testing.T) {
 				},
 			},
 		},
+		{
+			nodes: corev1.NodeList{
+				Items: []corev1.Node{
+					testNode("10.1.1.1", 5, 10, 10),
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "10.1.1.2",
+							Annotations: map[string]string{
+								topolvm.GetCapacityKeyPrefix() + "dc1":          fmt.Sprintf("%d", 5<<30),
+								topolvm.GetCapacityEmergencyKeyPrefix() + "dc1": "thin pool data usage 96.00% exceeds the critical threshold 95.00%",
+							},
+						},
+					},
+				},
+			},
+			requested: map[string]int64{
+				"dc1": 2 << 30,
+			},
+			expect: ExtenderFilterResult{
+				Nodes: &corev1.NodeList{
+					Items: []corev1.Node{
+						testNode("10.1.1.1", 5, 10, 10),
+					},
+				},
+				FailedNodes: FailedNodesMap{
+					"10.1.1.2": "capacity emergency",
+				},
+			},
+		},
 		{
 			nodes: corev1.NodeList{
 				Items: []corev1.Node{
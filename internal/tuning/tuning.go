@@ -0,0 +1,41 @@
+// Package tuning sizes the binaries of TopoLVM to the resources of their containers.
+package tuning
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/go-logr/logr"
+	"go.uber.org/automaxprocs/maxprocs"
+)
+
+// LVMDMaxConcurrent caps the default number of requests per volume group run by lvmd at the same time.
+// topolvm-controller paces the deletions of volumes by it as well.
+const LVMDMaxConcurrent = 4
+
+// SetMaxProcs sets GOMAXPROCS to the CPU quota of the container, so that the Go runtime
+// does not run more threads than the CPUs it is allowed to use. GOMAXPROCS in the environment takes precedence.
+func SetMaxProcs(logger logr.Logger) {
+	_, err := maxprocs.Set(maxprocs.Logger(func(format string, args ...any) {
+		logger.Info(fmt.Sprintf(format, args...))
+	}))
+	if err != nil {
+		logger.Error(err, "failed to set GOMAXPROCS from the CPU quota")
+	}
+}
+
+// Workers returns the number of workers for the CPUs available, i.e. GOMAXPROCS, between 1 and max.
+// A positive override is returned as it is.
+func Workers(override, max int) int {
+	if override > 0 {
+		return override
+	}
+	n := runtime.GOMAXPROCS(0)
+	if n > max {
+		n = max
+	}
+	if n < 1 {
+		n = 1
+	}
+	return n
+}
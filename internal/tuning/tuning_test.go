@@ -0,0 +1,27 @@
+package tuning
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestWorkers(t *testing.T) {
+	procs := runtime.GOMAXPROCS(2)
+	defer runtime.GOMAXPROCS(procs)
+
+	testCases := []struct {
+		override int
+		max      int
+		expected int
+	}{
+		{override: 0, max: 4, expected: 2},
+		{override: 0, max: 1, expected: 1},
+		{override: 0, max: 0, expected: 1},
+		{override: 16, max: 4, expected: 16},
+	}
+	for _, tc := range testCases {
+		if workers := Workers(tc.override, tc.max); workers != tc.expected {
+			t.Errorf("Workers(%d, %d) = %d, expected %d", tc.override, tc.max, workers, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package controller
+
+import (
+	internalController "github.com/topolvm/topolvm/internal/controller"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CapacityAlertThresholds are the usages of a device-class in percent at which the capacity alerts fire.
+type CapacityAlertThresholds = internalController.CapacityAlertThresholds
+
+// SetupCapacityAlertReconciler creates CapacityAlertReconciler and sets up with manager.
+func SetupCapacityAlertReconciler(mgr ctrl.Manager, client client.Client, rule types.NamespacedName, labels map[string]string,
+	defaults CapacityAlertThresholds, thresholds map[string]CapacityAlertThresholds) error {
+	reconciler := internalController.NewCapacityAlertReconciler(client, rule, labels, defaults, thresholds)
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,13 @@
+package controller
+
+import (
+	internalController "github.com/topolvm/topolvm/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetupCapacityReclaimReconciler creates CapacityReclaimReconciler and sets up with manager.
+func SetupCapacityReclaimReconciler(mgr ctrl.Manager, client client.Client) error {
+	reconciler := internalController.NewCapacityReclaimReconciler(client)
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,25 @@
+package controller
+
+import (
+	"time"
+
+	internalController "github.com/topolvm/topolvm/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeletedNodePolicy is how LogicalVolumes referring to a deleted node are handled.
+type DeletedNodePolicy = internalController.DeletedNodePolicy
+
+// The policies for LogicalVolumes referring to a deleted node.
+const (
+	DeletedNodeOrphan      = internalController.DeletedNodeOrphan
+	DeletedNodeForceDelete = internalController.DeletedNodeForceDelete
+	DeletedNodeMigrate     = internalController.DeletedNodeMigrate
+)
+
+// SetupDeletedNodeReconciler creates DeletedNodeReconciler and sets up with manager.
+func SetupDeletedNodeReconciler(mgr ctrl.Manager, client client.Client, policy DeletedNodePolicy, ttl time.Duration) error {
+	reconciler := internalController.NewDeletedNodeReconciler(client, policy, ttl)
+	return reconciler.SetupWithManager(mgr)
+}
@@ -0,0 +1,14 @@
+package controller
+
+import (
+	internalController "github.com/topolvm/topolvm/internal/controller"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SetupLVMdConfigRolloutReconciler creates LVMdConfigRolloutReconciler and sets up with manager.
+func SetupLVMdConfigRolloutReconciler(mgr ctrl.Manager, client client.Client, daemonSets map[types.NamespacedName]string, topologyKey string) error {
+	reconciler := internalController.NewLVMdConfigRolloutReconciler(client, daemonSets, topologyKey)
+	return reconciler.SetupWithManager(mgr)
+}
@@ -7,7 +7,8 @@ import (
 )
 
 // SetupNodeReconciler creates NodeReconciler and sets up with manager.
-func SetupNodeReconciler(mgr ctrl.Manager, client client.Client, skipNodeFinalize bool) error {
-	reconciler := internalController.NewNodeReconciler(client, skipNodeFinalize)
+// With scaleDownProtection, cluster-autoscaler is kept from scaling down nodes hosting logical volumes.
+func SetupNodeReconciler(mgr ctrl.Manager, client client.Client, skipNodeFinalize, scaleDownProtection bool) error {
+	reconciler := internalController.NewNodeReconciler(client, skipNodeFinalize, scaleDownProtection)
 	return reconciler.SetupWithManager(mgr)
 }
@@ -0,0 +1,317 @@
+// Package convert translates between the LogicalVolume custom resource and the messages of the lvmd protocol,
+// so that topolvm-node and the tools talking to lvmd directly map them in the same way.
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/topolvm/topolvm"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VolumeName returns the name of the logical volume of lv in lvmd, which is the UID of lv.
+func VolumeName(lv *topolvmv1.LogicalVolume) string {
+	return string(lv.UID)
+}
+
+// LegacySizeGb returns the deprecated size_gb field of the requests for bytes, rounded down to GiB.
+// lvmd before size_bytes was introduced only reads size_gb, so it is still set.
+func LegacySizeGb(bytes int64) uint64 {
+	// convert to uint64 because lvmd internals and lvm use uint64 but CSI uses int64.
+	return uint64(bytes >> 30)
+}
+
+// CreateLVRequest returns the request creating the logical volume of lv, which has no source.
+func CreateLVRequest(lv *topolvmv1.LogicalVolume) *proto.CreateLVRequest {
+	reqBytes := lv.Spec.Size.Value()
+	return &proto.CreateLVRequest{
+		Name:                VolumeName(lv),
+		DeviceClass:         lv.Spec.DeviceClass,
+		LvcreateOptionClass: lv.Spec.LvcreateOptionClass,
+		LvcreateOptions:     lv.Spec.LvcreateOptions,
+		ProvisioningType:    lv.Spec.ProvisioningType,
+		SizeGb:              LegacySizeGb(reqBytes),
+		SizeBytes:           reqBytes,
+	}
+}
+
+// CreateLVSnapshotRequest returns the request creating the logical volume of lv as a snapshot of source,
+// the LogicalVolume referred to by spec.source of lv.
+func CreateLVSnapshotRequest(lv, source *topolvmv1.LogicalVolume) *proto.CreateLVSnapshotRequest {
+	reqBytes := lv.Spec.Size.Value()
+	return &proto.CreateLVSnapshotRequest{
+		Name:         VolumeName(lv),
+		DeviceClass:  lv.Spec.DeviceClass,
+		SourceVolume: source.Status.VolumeID,
+		SizeGb:       LegacySizeGb(reqBytes),
+		SizeBytes:    reqBytes,
+		AccessType:   lv.Spec.AccessType,
+	}
+}
+
+// ResizeLVRequest returns the request resizing the logical volume of lv to spec.size.
+// allowShrink has to be set to make the volume smaller.
+func ResizeLVRequest(lv *topolvmv1.LogicalVolume, allowShrink bool) *proto.ResizeLVRequest {
+	reqBytes := lv.Spec.Size.Value()
+	return &proto.ResizeLVRequest{
+		Name:        VolumeName(lv),
+		SizeGb:      LegacySizeGb(reqBytes),
+		SizeBytes:   reqBytes,
+		DeviceClass: lv.Spec.DeviceClass,
+		AllowShrink: allowShrink,
+	}
+}
+
+// RemoveLVRequest returns the request removing the logical volume of lv.
+func RemoveLVRequest(lv *topolvmv1.LogicalVolume) *proto.RemoveLVRequest {
+	return &proto.RemoveLVRequest{
+		Name:         VolumeName(lv),
+		DeviceClass:  lv.Spec.DeviceClass,
+		WipeOnDelete: lv.Spec.WipeOnDelete,
+	}
+}
+
+// MergeLVSnapshotRequest returns the request merging the snapshot of lv into its origin.
+func MergeLVSnapshotRequest(lv *topolvmv1.LogicalVolume) *proto.MergeLVSnapshotRequest {
+	return &proto.MergeLVSnapshotRequest{
+		Name:        VolumeName(lv),
+		DeviceClass: lv.Spec.DeviceClass,
+	}
+}
+
+// MoveLVRequest returns the request moving the logical volume of lv to the device-class target.
+func MoveLVRequest(lv *topolvmv1.LogicalVolume, target string) *proto.MoveLVRequest {
+	return &proto.MoveLVRequest{
+		Name:              VolumeName(lv),
+		DeviceClass:       lv.Spec.DeviceClass,
+		TargetDeviceClass: target,
+		WipeOnDelete:      lv.Spec.WipeOnDelete,
+	}
+}
+
+// ExpandSnapshotName returns the name of the snapshot of lv taken before expanding it to spec.size,
+// which is the same for the retries of the expansion.
+func ExpandSnapshotName(lv *topolvmv1.LogicalVolume) string {
+	return fmt.Sprintf("%s-expand-%d", lv.Status.VolumeID, lv.Spec.Size.Value())
+}
+
+// ExpandSnapshotRequest returns the request taking a read-only snapshot of lv before expanding it to spec.size.
+// The snapshot is tagged to be removed at expires.
+func ExpandSnapshotRequest(lv *topolvmv1.LogicalVolume, expires time.Time) *proto.CreateLVSnapshotRequest {
+	return &proto.CreateLVSnapshotRequest{
+		Name:         ExpandSnapshotName(lv),
+		DeviceClass:  lv.Spec.DeviceClass,
+		SourceVolume: lv.Status.VolumeID,
+		AccessType:   "ro",
+		Tags:         []string{topolvm.GetExpandSnapshotExpiresTag() + strconv.FormatInt(expires.Unix(), 10)},
+	}
+}
+
+// ExpandSnapshotExpiry returns when the snapshot taken before an expansion is removed,
+// or false if volume is not such a snapshot.
+func ExpandSnapshotExpiry(volume *proto.LogicalVolume) (time.Time, bool) {
+	for _, tag := range volume.GetTags() {
+		value, ok := strings.CutPrefix(tag, topolvm.GetExpandSnapshotExpiresTag())
+		if !ok {
+			continue
+		}
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		return time.Unix(unix, 0), true
+	}
+	return time.Time{}, false
+}
+
+// FindVolume returns the logical volume of lv out of volumes, or nil if it is not found.
+func FindVolume(volumes []*proto.LogicalVolume, lv *topolvmv1.LogicalVolume) *proto.LogicalVolume {
+	name := VolumeName(lv)
+	for _, v := range volumes {
+		if v.GetName() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// Quantity returns bytes as a quantity in the binary SI format used by the status of LogicalVolume.
+func Quantity(bytes int64) *resource.Quantity {
+	return resource.NewQuantity(bytes, resource.BinarySI)
+}
+
+// SetCreated records the logical volume created for spec.size of lv in its status.
+// The current size is the requested size rather than the size of volume, which is rounded up by lvm,
+// so that it can be compared with spec.size.
+func SetCreated(lv *topolvmv1.LogicalVolume, volume *proto.LogicalVolume) {
+	lv.Status.VolumeID = volume.GetName()
+	lv.Status.CurrentSize = Quantity(lv.Spec.Size.Value())
+	lv.Status.Code = codes.OK
+	lv.Status.Message = ""
+}
+
+// SetResized records that the logical volume of lv has been resized to spec.size in its status.
+func SetResized(lv *topolvmv1.LogicalVolume) {
+	lv.Status.CurrentSize = Quantity(lv.Spec.Size.Value())
+	lv.Status.Code = codes.OK
+	lv.Status.Message = ""
+}
+
+// SetError records the error returned by lvmd in the status of lv.
+// Errors other than gRPC statuses are recorded as codes.Internal.
+func SetError(lv *topolvmv1.LogicalVolume, err error) {
+	lv.Status.Code, lv.Status.Message = CodeAndMessage(err)
+}
+
+// CodeAndMessage returns the code and the message of the error returned by lvmd.
+// Errors other than gRPC statuses are reported as codes.Internal.
+func CodeAndMessage(err error) (codes.Code, string) {
+	s, ok := status.FromError(err)
+	if !ok {
+		return codes.Internal, err.Error()
+	}
+	return s.Code(), s.Message()
+}
+
+// InsufficientSpace returns the detail of the error returned by lvmd when the volume does not fit into the free
+// space of the device-class, or nil for the other errors, including the other codes.ResourceExhausted.
+func InsufficientSpace(err error) *proto.InsufficientSpace {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.ResourceExhausted {
+		return nil
+	}
+	for _, detail := range s.Details() {
+		if space, ok := detail.(*proto.InsufficientSpace); ok {
+			return space
+		}
+	}
+	return nil
+}
+
+// SetAllocatedSize records the space allocated for volume in the status of lv.
+// It returns true if the status has changed.
+func SetAllocatedSize(lv *topolvmv1.LogicalVolume, volume *proto.LogicalVolume) bool {
+	allocated := Quantity(int64(volume.GetAllocatedBytes()))
+	if lv.Status.AllocatedSize != nil && lv.Status.AllocatedSize.Cmp(*allocated) == 0 {
+		return false
+	}
+	lv.Status.AllocatedSize = allocated
+	return true
+}
+
+// SetHealth records the health of volume reported by lvmd in the VolumeHealthy condition of lv.
+// The condition is not added while the LV has always been healthy.
+// It returns true if the status has changed.
+func SetHealth(lv *topolvmv1.LogicalVolume, volume *proto.LogicalVolume) bool {
+	cond := metav1.Condition{
+		Type:    topolvmv1.ConditionVolumeHealthy,
+		Status:  metav1.ConditionTrue,
+		Reason:  topolvmv1.HealthReasonHealthy,
+		Message: "the volume is healthy",
+	}
+	switch {
+	case volume.GetHealthError() != "":
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, topolvmv1.HealthReasonVolumeUnhealthy, volume.GetHealthError()
+	case volume.GetPoolHealthError() != "":
+		cond.Status, cond.Reason, cond.Message = metav1.ConditionFalse, topolvmv1.HealthReasonThinPoolUnhealthy, volume.GetPoolHealthError()
+	}
+
+	current := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy)
+	if current == nil && cond.Status == metav1.ConditionTrue {
+		return false
+	}
+	if current != nil && current.Status == cond.Status && current.Reason == cond.Reason && current.Message == cond.Message {
+		return false
+	}
+	meta.SetStatusCondition(&lv.Status.Conditions, cond)
+	return true
+}
+
+// SetOrigin records the origin of volume in LVM in the status of lv.
+// It returns true if the status has changed.
+func SetOrigin(lv *topolvmv1.LogicalVolume, volume *proto.LogicalVolume) bool {
+	if lv.Status.Origin == volume.GetOrigin() {
+		return false
+	}
+	lv.Status.Origin = volume.GetOrigin()
+	return true
+}
+
+// Volume is a logical volume reported by lvmd with its attributes decoded.
+type Volume struct {
+	// Name is the name of the logical volume, which is the UID of its LogicalVolume.
+	Name string
+	// Size is the size of the logical volume.
+	Size *resource.Quantity
+	// AllocatedSize is the space allocated from the thin pool or the volume group.
+	AllocatedSize *resource.Quantity
+	// Tags are the lvm tags of the logical volume.
+	Tags []string
+	// Origin is the name of the origin of a snapshot, or empty if the volume is not a snapshot.
+	Origin string
+	// DevMajor and DevMinor are the device numbers of the logical volume.
+	DevMajor uint32
+	DevMinor uint32
+
+	// Thin is true for thin volumes.
+	Thin bool
+	// Active is true if the device of the logical volume is present.
+	Active bool
+	// ReadOnly is true if the logical volume cannot be written.
+	ReadOnly bool
+	// Merging is true while a snapshot is merged into its origin, both for the snapshot and the origin.
+	Merging bool
+	// HealthError explains why the volume is unhealthy, or is empty if it is healthy.
+	HealthError string
+	// PoolHealthError explains why the thin pool of a thin volume is unhealthy, or is empty if it is healthy.
+	PoolHealthError string
+}
+
+// IsSnapshot returns true if the volume is a snapshot of another volume.
+func (v *Volume) IsSnapshot() bool {
+	return v.Origin != ""
+}
+
+// IsSnapshotOf returns true if the volume is a snapshot of the logical volume of source.
+func (v *Volume) IsSnapshotOf(source *topolvmv1.LogicalVolume) bool {
+	return v.IsSnapshot() && v.Origin == source.Status.VolumeID
+}
+
+// VolumeFromProto decodes the logical volume reported by lvmd.
+func VolumeFromProto(volume *proto.LogicalVolume) (*Volume, error) {
+	attr, err := command.ParsedLvAttr(volume.GetAttr())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the attributes of volume %s: %w", volume.GetName(), err)
+	}
+
+	var tags []string
+	if len(volume.GetTags()) > 0 {
+		tags = make([]string, len(volume.GetTags()))
+		copy(tags, volume.GetTags())
+	}
+	return &Volume{
+		Name:            volume.GetName(),
+		Size:            Quantity(volume.GetSizeBytes()),
+		AllocatedSize:   Quantity(int64(volume.GetAllocatedBytes())),
+		Tags:            tags,
+		Origin:          volume.GetOrigin(),
+		DevMajor:        volume.GetDevMajor(),
+		DevMinor:        volume.GetDevMinor(),
+		Thin:            attr.VolumeType == command.VolumeTypeThinVolume,
+		Active:          attr.State == command.StateActive,
+		ReadOnly:        attr.Permissions == command.PermissionsReadOnly || attr.Permissions == command.PermissionsReadOnlyActivationOfNonReadOnlyVolume,
+		Merging:         attr.VolumeType == command.VolumeTypeMergingSnapshot || attr.VolumeType == command.VolumeTypeOriginWithMergingSnapshot,
+		HealthError:     volume.GetHealthError(),
+		PoolHealthError: volume.GetPoolHealthError(),
+	}, nil
+}
@@ -0,0 +1,395 @@
+package convert
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testLogicalVolume(size string) *topolvmv1.LogicalVolume {
+	return &topolvmv1.LogicalVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", UID: "8d4d2d2c-5f0b-4e0c-9d5a-3f6b1c2a7e10"},
+		Spec: topolvmv1.LogicalVolumeSpec{
+			Name:                "test",
+			NodeName:            "node1",
+			Size:                resource.MustParse(size),
+			DeviceClass:         "ssd",
+			LvcreateOptionClass: "raid1",
+			LvcreateOptions:     []string{"--nosync"},
+			ProvisioningType:    "thin",
+			AccessType:          "rw",
+			WipeOnDelete:        "discard",
+		},
+	}
+}
+
+func TestLegacySizeGb(t *testing.T) {
+	cases := []struct {
+		bytes int64
+		gb    uint64
+	}{
+		{0, 0},
+		{1<<30 - 1, 0},
+		{1 << 30, 1},
+		{3<<30 + 1, 3},
+		{5 << 40, 5 << 10},
+	}
+	for _, c := range cases {
+		if got := LegacySizeGb(c.bytes); got != c.gb {
+			t.Errorf("LegacySizeGb(%d) = %d, expected %d", c.bytes, got, c.gb)
+		}
+	}
+}
+
+func TestRequests(t *testing.T) {
+	lv := testLogicalVolume("1536Mi")
+	const name = "8d4d2d2c-5f0b-4e0c-9d5a-3f6b1c2a7e10"
+	const size = 1536 << 20
+
+	if got := VolumeName(lv); got != name {
+		t.Errorf("VolumeName() = %s, expected %s", got, name)
+	}
+
+	create := CreateLVRequest(lv)
+	expectedCreate := &proto.CreateLVRequest{
+		Name:                name,
+		DeviceClass:         "ssd",
+		LvcreateOptionClass: "raid1",
+		LvcreateOptions:     []string{"--nosync"},
+		ProvisioningType:    "thin",
+		SizeGb:              1,
+		SizeBytes:           size,
+	}
+	if !reflect.DeepEqual(create, expectedCreate) {
+		t.Errorf("CreateLVRequest() = %v, expected %v", create, expectedCreate)
+	}
+
+	source := testLogicalVolume("1Gi")
+	source.UID = "source-uid"
+	source.Status.VolumeID = "source-volume"
+	snapshot := CreateLVSnapshotRequest(lv, source)
+	expectedSnapshot := &proto.CreateLVSnapshotRequest{
+		Name:         name,
+		DeviceClass:  "ssd",
+		SourceVolume: "source-volume",
+		SizeGb:       1,
+		SizeBytes:    size,
+		AccessType:   "rw",
+	}
+	if !reflect.DeepEqual(snapshot, expectedSnapshot) {
+		t.Errorf("CreateLVSnapshotRequest() = %v, expected %v", snapshot, expectedSnapshot)
+	}
+
+	for _, allowShrink := range []bool{false, true} {
+		resize := ResizeLVRequest(lv, allowShrink)
+		expectedResize := &proto.ResizeLVRequest{
+			Name:        name,
+			SizeGb:      1,
+			SizeBytes:   size,
+			DeviceClass: "ssd",
+			AllowShrink: allowShrink,
+		}
+		if !reflect.DeepEqual(resize, expectedResize) {
+			t.Errorf("ResizeLVRequest(%v) = %v, expected %v", allowShrink, resize, expectedResize)
+		}
+	}
+
+	remove := RemoveLVRequest(lv)
+	expectedRemove := &proto.RemoveLVRequest{Name: name, DeviceClass: "ssd", WipeOnDelete: "discard"}
+	if !reflect.DeepEqual(remove, expectedRemove) {
+		t.Errorf("RemoveLVRequest() = %v, expected %v", remove, expectedRemove)
+	}
+
+	merge := MergeLVSnapshotRequest(lv)
+	expectedMerge := &proto.MergeLVSnapshotRequest{Name: name, DeviceClass: "ssd"}
+	if !reflect.DeepEqual(merge, expectedMerge) {
+		t.Errorf("MergeLVSnapshotRequest() = %v, expected %v", merge, expectedMerge)
+	}
+
+	move := MoveLVRequest(lv, "hdd")
+	expectedMove := &proto.MoveLVRequest{Name: name, DeviceClass: "ssd", TargetDeviceClass: "hdd", WipeOnDelete: "discard"}
+	if !reflect.DeepEqual(move, expectedMove) {
+		t.Errorf("MoveLVRequest() = %v, expected %v", move, expectedMove)
+	}
+}
+
+func TestExpandSnapshot(t *testing.T) {
+	lv := testLogicalVolume("2Gi")
+	lv.Status.VolumeID = "volume"
+	expires := time.Unix(1760000000, 0)
+
+	req := ExpandSnapshotRequest(lv, expires)
+	expected := &proto.CreateLVSnapshotRequest{
+		Name:         "volume-expand-2147483648",
+		DeviceClass:  "ssd",
+		SourceVolume: "volume",
+		AccessType:   "ro",
+		Tags:         []string{"topolvm.io/expand-snapshot-expires=1760000000"},
+	}
+	if !reflect.DeepEqual(req, expected) {
+		t.Errorf("ExpandSnapshotRequest() = %v, expected %v", req, expected)
+	}
+
+	got, ok := ExpandSnapshotExpiry(&proto.LogicalVolume{Name: req.Name, Tags: append([]string{"other"}, req.Tags...)})
+	if !ok || !got.Equal(expires) {
+		t.Errorf("ExpandSnapshotExpiry() = %v, %v, expected %v", got, ok, expires)
+	}
+	for _, tags := range [][]string{nil, {"other"}, {"topolvm.io/expand-snapshot-expires=soon"}} {
+		if _, ok := ExpandSnapshotExpiry(&proto.LogicalVolume{Name: "snapshot", Tags: tags}); ok {
+			t.Errorf("ExpandSnapshotExpiry() should be false for tags %v", tags)
+		}
+	}
+}
+
+func TestFindVolume(t *testing.T) {
+	lv := testLogicalVolume("1Gi")
+	target := &proto.LogicalVolume{Name: string(lv.UID)}
+	volumes := []*proto.LogicalVolume{{Name: "other"}, target}
+
+	if got := FindVolume(volumes, lv); got != target {
+		t.Errorf("FindVolume() = %v, expected %v", got, target)
+	}
+	if got := FindVolume(volumes[:1], lv); got != nil {
+		t.Errorf("FindVolume() = %v, expected nil", got)
+	}
+	if got := FindVolume(nil, lv); got != nil {
+		t.Errorf("FindVolume(nil) = %v, expected nil", got)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	lv := testLogicalVolume("1536Mi")
+	lv.Status.Code = codes.Internal
+	lv.Status.Message = "failed before"
+
+	// lvm rounds the size up to the extents, which is not reflected in the status.
+	SetCreated(lv, &proto.LogicalVolume{Name: "volume", SizeBytes: 1540 << 20})
+	if lv.Status.VolumeID != "volume" {
+		t.Errorf("expected volumeID volume, got %s", lv.Status.VolumeID)
+	}
+	if lv.Status.CurrentSize == nil || lv.Status.CurrentSize.Cmp(lv.Spec.Size) != 0 {
+		t.Errorf("expected currentSize %s, got %v", lv.Spec.Size.String(), lv.Status.CurrentSize)
+	}
+	if lv.Status.CurrentSize.String() != "1536Mi" {
+		t.Errorf("expected currentSize in the binary SI format, got %s", lv.Status.CurrentSize.String())
+	}
+	if lv.Status.Code != codes.OK || lv.Status.Message != "" {
+		t.Errorf("expected the error to be cleared, got %s: %s", lv.Status.Code, lv.Status.Message)
+	}
+
+	SetError(lv, status.Error(codes.ResourceExhausted, "no enough space left on VG: free=0, requested=1"))
+	if lv.Status.Code != codes.ResourceExhausted || lv.Status.Message != "no enough space left on VG: free=0, requested=1" {
+		t.Errorf("unexpected status %s: %s", lv.Status.Code, lv.Status.Message)
+	}
+	SetError(lv, errors.New("connection refused"))
+	if lv.Status.Code != codes.Internal || lv.Status.Message != "connection refused" {
+		t.Errorf("unexpected status %s: %s", lv.Status.Code, lv.Status.Message)
+	}
+
+	lv.Spec.Size = resource.MustParse("2Gi")
+	SetResized(lv)
+	if lv.Status.CurrentSize.String() != "2Gi" {
+		t.Errorf("expected currentSize 2Gi, got %s", lv.Status.CurrentSize.String())
+	}
+	if lv.Status.Code != codes.OK || lv.Status.Message != "" {
+		t.Errorf("expected the error to be cleared, got %s: %s", lv.Status.Code, lv.Status.Message)
+	}
+	if lv.Status.VolumeID != "volume" {
+		t.Errorf("expected volumeID to be kept, got %s", lv.Status.VolumeID)
+	}
+
+	if !SetAllocatedSize(lv, &proto.LogicalVolume{AllocatedBytes: 4 << 20}) {
+		t.Error("expected allocatedSize to be set")
+	}
+	if lv.Status.AllocatedSize.String() != "4Mi" {
+		t.Errorf("expected allocatedSize 4Mi, got %s", lv.Status.AllocatedSize.String())
+	}
+	if SetAllocatedSize(lv, &proto.LogicalVolume{AllocatedBytes: 4 << 20}) {
+		t.Error("expected allocatedSize to be unchanged")
+	}
+	if !SetAllocatedSize(lv, &proto.LogicalVolume{AllocatedBytes: 8 << 20}) {
+		t.Error("expected allocatedSize to be changed")
+	}
+	if lv.Status.AllocatedSize.String() != "8Mi" {
+		t.Errorf("expected allocatedSize 8Mi, got %s", lv.Status.AllocatedSize.String())
+	}
+}
+
+func TestSetOrigin(t *testing.T) {
+	lv := &topolvmv1.LogicalVolume{}
+	if !SetOrigin(lv, &proto.LogicalVolume{Origin: "source"}) {
+		t.Error("expected origin to be set")
+	}
+	if lv.Status.Origin != "source" {
+		t.Errorf("expected origin source, got %s", lv.Status.Origin)
+	}
+	if SetOrigin(lv, &proto.LogicalVolume{Origin: "source"}) {
+		t.Error("expected origin to be unchanged")
+	}
+	if !SetOrigin(lv, &proto.LogicalVolume{}) {
+		t.Error("expected origin to be cleared")
+	}
+	if lv.Status.Origin != "" {
+		t.Errorf("expected empty origin, got %s", lv.Status.Origin)
+	}
+}
+
+func TestInsufficientSpace(t *testing.T) {
+	space := &proto.InsufficientSpace{DeviceClass: "thin", VolumeGroup: "vg", ThinPool: "pool0", RequestedBytes: 2 << 30, FreeBytes: 1 << 30}
+	st, err := status.New(codes.ResourceExhausted, "no enough space").WithDetails(space)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := InsufficientSpace(st.Err())
+	if got == nil || got.GetDeviceClass() != "thin" || got.GetVolumeGroup() != "vg" || got.GetThinPool() != "pool0" ||
+		got.GetRequestedBytes() != 2<<30 || got.GetFreeBytes() != 1<<30 {
+		t.Errorf("unexpected detail: %v", got)
+	}
+
+	for _, err := range []error{
+		nil,
+		errors.New("connection refused"),
+		status.Error(codes.ResourceExhausted, "too many requests queued for volume group vg, CreateLV rejected"),
+	} {
+		if got := InsufficientSpace(err); got != nil {
+			t.Errorf("expected no detail for %v, got %v", err, got)
+		}
+	}
+}
+
+func TestVolumeFromProto(t *testing.T) {
+	source := testLogicalVolume("1Gi")
+	source.Status.VolumeID = "origin"
+
+	cases := []struct {
+		name     string
+		volume   *proto.LogicalVolume
+		expected *Volume
+	}{
+		{
+			name: "thick",
+			volume: &proto.LogicalVolume{
+				Name: "thick", SizeBytes: 1 << 30, AllocatedBytes: 1 << 30, DevMajor: 253, DevMinor: 1,
+				Tags: []string{"a", "b"}, Attr: "-wi-a-----",
+			},
+			expected: &Volume{
+				Name: "thick", Size: Quantity(1 << 30), AllocatedSize: Quantity(1 << 30), DevMajor: 253, DevMinor: 1,
+				Tags: []string{"a", "b"}, Active: true,
+			},
+		},
+		{
+			name: "inactive thin",
+			volume: &proto.LogicalVolume{
+				Name: "thin", SizeBytes: 2 << 30, AllocatedBytes: 64 << 20, Attr: "Vwi---tz-k",
+				PoolHealthError: "thin pool is out of data space",
+			},
+			expected: &Volume{
+				Name: "thin", Size: Quantity(2 << 30), AllocatedSize: Quantity(64 << 20), Thin: true,
+				PoolHealthError: "thin pool is out of data space",
+			},
+		},
+		{
+			name: "read-only thin snapshot",
+			volume: &proto.LogicalVolume{
+				Name: "snap", SizeBytes: 1 << 30, Attr: "Vri-a-tz--", Origin: "origin",
+			},
+			expected: &Volume{
+				Name: "snap", Size: Quantity(1 << 30), AllocatedSize: Quantity(0), Origin: "origin",
+				Thin: true, Active: true, ReadOnly: true,
+			},
+		},
+		{
+			name: "merging thick snapshot",
+			volume: &proto.LogicalVolume{
+				Name: "snap", SizeBytes: 1 << 30, AllocatedBytes: 1 << 30, Attr: "Swi-a-s---", Origin: "origin",
+			},
+			expected: &Volume{
+				Name: "snap", Size: Quantity(1 << 30), AllocatedSize: Quantity(1 << 30), Origin: "origin",
+				Active: true, Merging: true,
+			},
+		},
+		{
+			name: "unhealthy raid",
+			volume: &proto.LogicalVolume{
+				Name: "raid", SizeBytes: 1 << 30, Attr: "rwi-a-r-r-",
+				HealthError: "RAID volume requires a refresh",
+			},
+			expected: &Volume{
+				Name: "raid", Size: Quantity(1 << 30), AllocatedSize: Quantity(0), Active: true,
+				HealthError: "RAID volume requires a refresh",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := VolumeFromProto(c.volume)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, c.expected) {
+				t.Errorf("VolumeFromProto() = %+v, expected %+v", got, c.expected)
+			}
+			if got.IsSnapshot() != (c.volume.Origin != "") {
+				t.Errorf("IsSnapshot() = %v for origin %q", got.IsSnapshot(), c.volume.Origin)
+			}
+			if got.IsSnapshotOf(source) != (c.volume.Origin == "origin") {
+				t.Errorf("IsSnapshotOf() = %v for origin %q", got.IsSnapshotOf(source), c.volume.Origin)
+			}
+		})
+	}
+
+	// the tags are copied so that modifying them does not change the response.
+	volume := &proto.LogicalVolume{Name: "tags", Tags: []string{"a"}, Attr: "-wi-a-----"}
+	got, err := VolumeFromProto(volume)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.Tags[0] = "b"
+	if volume.Tags[0] != "a" {
+		t.Error("expected the tags of the response to be unchanged")
+	}
+
+	if _, err := VolumeFromProto(&proto.LogicalVolume{Name: "broken", Attr: "-wi"}); err == nil {
+		t.Error("expected an invalid attr to be rejected")
+	}
+}
+
+func TestSetHealth(t *testing.T) {
+	lv := &topolvmv1.LogicalVolume{}
+	if SetHealth(lv, &proto.LogicalVolume{}) || len(lv.Status.Conditions) != 0 {
+		t.Errorf("expected no condition for a healthy volume: %+v", lv.Status.Conditions)
+	}
+
+	if !SetHealth(lv, &proto.LogicalVolume{PoolHealthError: "out of data space"}) {
+		t.Error("expected condition to be set")
+	}
+	cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy)
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != topolvmv1.HealthReasonThinPoolUnhealthy || cond.Message != "out of data space" {
+		t.Errorf("unexpected condition: %+v", cond)
+	}
+	if !SetHealth(lv, &proto.LogicalVolume{HealthError: "suspended", PoolHealthError: "out of data space"}) {
+		t.Error("expected condition to be updated")
+	}
+	if cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy); cond.Reason != topolvmv1.HealthReasonVolumeUnhealthy {
+		t.Errorf("expected the health of the volume to take precedence: %+v", cond)
+	}
+	if SetHealth(lv, &proto.LogicalVolume{HealthError: "suspended"}) {
+		t.Error("expected condition to be unchanged")
+	}
+
+	if !SetHealth(lv, &proto.LogicalVolume{}) {
+		t.Error("expected condition to be updated on recovery")
+	}
+	if cond := meta.FindStatusCondition(lv.Status.Conditions, topolvmv1.ConditionVolumeHealthy); cond.Status != metav1.ConditionTrue || cond.Reason != topolvmv1.HealthReasonHealthy {
+		t.Errorf("unexpected condition after recovery: %+v", cond)
+	}
+}
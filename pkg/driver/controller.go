@@ -16,6 +16,43 @@ type ControllerServerSettings = internalDriver.ControllerServerSettings
 // It contains the minimum allocation settings for the controller inside controller server settings.
 type MinimumAllocationSettings = internalDriver.MinimumAllocationSettings
 
+// MaximumAllocationSettings is an externally consumable wrapper.
+// It contains the maximum allocation settings for the controller inside controller server settings.
+type MaximumAllocationSettings = internalDriver.MaximumAllocationSettings
+
+// MaximumAllocation is an externally consumable wrapper.
+// It contains the maximum sizes of the volumes by default or of a device class.
+type MaximumAllocation = internalDriver.MaximumAllocation
+
+// MaximumAllocationPolicy is an externally consumable wrapper.
+// It is how the controller handles the volumes requested larger than the maximum allocation.
+type MaximumAllocationPolicy = internalDriver.MaximumAllocationPolicy
+
+const (
+	// MaximumAllocationReject rejects the volumes requested larger than the maximum with OutOfRange.
+	MaximumAllocationReject = internalDriver.MaximumAllocationReject
+	// MaximumAllocationCap allocates the maximum for the volumes requested larger than it.
+	MaximumAllocationCap = internalDriver.MaximumAllocationCap
+)
+
+// AllocationUnitSettings is an externally consumable wrapper.
+// It contains the units the requested sizes are rounded up to inside controller server settings.
+type AllocationUnitSettings = internalDriver.AllocationUnitSettings
+
+// DeletionPacingSettings is an externally consumable wrapper.
+// It contains the limits of the deletions of the volumes by node inside controller server settings.
+type DeletionPacingSettings = internalDriver.DeletionPacingSettings
+
+// DefaultMaxDeletionsPerNode is the default number of volumes of a node deleted at the same time.
+const DefaultMaxDeletionsPerNode = internalDriver.DefaultMaxDeletionsPerNode
+
+// DefaultLVMDMaxConcurrent is the default concurrency limit of lvmd on 4 CPUs or more.
+const DefaultLVMDMaxConcurrent = internalDriver.DefaultLVMDMaxConcurrent
+
+// MaxDeletionsPerNode returns the number of volumes of a node deleted at the same time
+// for the concurrency limit of lvmd.
+var MaxDeletionsPerNode = internalDriver.MaxDeletionsPerNode
+
 // Quantity is an externally consumable wrapper.
 // It is used to represent a quantity of a resource.
 type Quantity = internalDriver.Quantity
@@ -27,3 +64,14 @@ var NewQuantityFlagVar = internalDriver.NewQuantityFlagVar
 // QuantityVar is an externally consumable wrapper.
 // It is used to create a new quantity variable.
 var QuantityVar = internalDriver.QuantityVar
+
+// WarmUpper is an externally consumable wrapper.
+// It is implemented by the controller server, which primes its cache before accepting requests.
+type WarmUpper = internalDriver.WarmUpper
+
+// CapacityAPIPath is the path prefix of the HTTP API served by the handler of NewCapacityAPI.
+const CapacityAPIPath = internalDriver.CapacityAPIPath
+
+// NewCapacityAPI is an externally consumable wrapper.
+// It returns a read-only HTTP API answering the capacity of device classes from the annotations of the nodes.
+var NewCapacityAPI = internalDriver.NewCapacityAPI
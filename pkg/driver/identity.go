@@ -5,3 +5,7 @@ import (
 )
 
 var NewIdentityServer = internalDriver.NewIdentityServer
+
+// NewLegacyIdentityServer is an externally consumable wrapper.
+// It returns an identity server advertising the legacy plugin name.
+var NewLegacyIdentityServer = internalDriver.NewLegacyIdentityServer
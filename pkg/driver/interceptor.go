@@ -0,0 +1,33 @@
+package driver
+
+import (
+	internalDriver "github.com/topolvm/topolvm/internal/driver"
+)
+
+// ServerKind is an externally consumable wrapper.
+// It identifies the CSI gRPC server of TopoLVM.
+type ServerKind = internalDriver.ServerKind
+
+const (
+	ServerKindController = internalDriver.ServerKindController
+	ServerKindNode       = internalDriver.ServerKindNode
+)
+
+// RegisterUnaryInterceptor is an externally consumable wrapper.
+// It registers a gRPC interceptor for the CSI server of the given kind, typically from init().
+var RegisterUnaryInterceptor = internalDriver.RegisterUnaryInterceptor
+
+// IdempotencyAuditor is an externally consumable wrapper.
+// It records the CSI calls and flags replays whose outcome is inconsistent with the previous call.
+type IdempotencyAuditor = internalDriver.IdempotencyAuditor
+
+// NewIdempotencyAuditor is an externally consumable wrapper.
+// It returns an IdempotencyAuditor keeping the given number of calls.
+var NewIdempotencyAuditor = internalDriver.NewIdempotencyAuditor
+
+// IdempotencyAuditPath is the path of the HTTP endpoint serving the records of IdempotencyAuditor.
+const IdempotencyAuditPath = internalDriver.IdempotencyAuditPath
+
+// ServerOptions is an externally consumable wrapper.
+// It returns the options to create the CSI gRPC server with the registered interceptors.
+var ServerOptions = internalDriver.ServerOptions
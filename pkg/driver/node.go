@@ -5,3 +5,36 @@ import (
 )
 
 var NewNodeServer = internalDriver.NewNodeServer
+
+// NodeServerSettings is an externally consumable wrapper.
+// It is used to configure the node server.
+type NodeServerSettings = internalDriver.NodeServerSettings
+
+// MountStrategy is an externally consumable wrapper.
+// It decides how the node server executes mount.
+type MountStrategy = internalDriver.MountStrategy
+
+const (
+	MountStrategyDirect      = internalDriver.MountStrategyDirect
+	MountStrategyNsenterHost = internalDriver.MountStrategyNsenterHost
+	MountStrategySystemdRun  = internalDriver.MountStrategySystemdRun
+)
+
+// MountStrategyHints is an externally consumable wrapper.
+// It returns hints about the mount strategy for the running environment.
+var MountStrategyHints = internalDriver.MountStrategyHints
+
+// NewLegacyNodeServer is an externally consumable wrapper.
+// It serves the volumes of a node server under the legacy plugin name.
+var NewLegacyNodeServer = internalDriver.NewLegacyNodeServer
+
+// QuotaDirectory is an externally consumable wrapper.
+// It is a directory inside a volume limited by an XFS project quota.
+type QuotaDirectory = internalDriver.QuotaDirectory
+
+// ProjectQuotaAPIPath is the path prefix of the HTTP API served by the handler of NewProjectQuotaAPI.
+const ProjectQuotaAPIPath = internalDriver.ProjectQuotaAPIPath
+
+// NewProjectQuotaAPI is an externally consumable wrapper.
+// It returns an HTTP API creating directories limited by XFS project quotas inside the volumes mounted on the node.
+var NewProjectQuotaAPI = internalDriver.NewProjectQuotaAPI
@@ -0,0 +1,20 @@
+package lvmd
+
+import (
+	"context"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+// ConfigureAutoActivation writes the lvm configuration file on the host that restricts the autoactivation by lvm
+// to the volume groups not used by device-classes with activation-skip, and to the extra entries.
+func ConfigureAutoActivation(ctx context.Context, file string, deviceClasses []*lvmdTypes.DeviceClass, extra []string) error {
+	return internalLvmd.ConfigureAutoActivation(ctx, file, deviceClasses, extra)
+}
+
+// CheckAutoActivation logs and returns warnings about the volumes of device-classes with activation-skip
+// that lvm on the host autoactivates.
+func CheckAutoActivation(ctx context.Context, deviceClasses []*lvmdTypes.DeviceClass) ([]string, error) {
+	return internalLvmd.CheckAutoActivation(ctx, deviceClasses)
+}
@@ -1,9 +1,59 @@
 package lvmd
 
 import (
+	"context"
+	"time"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
 	internalLvmdCommand "github.com/topolvm/topolvm/internal/lvmd/command"
 )
 
 func Containerized(sw bool) {
 	internalLvmdCommand.Containerized = sw
 }
+
+// SetBinaryPaths sets the paths of the lvm, dmsetup and nsenter binaries. Empty paths are detected.
+func SetBinaryPaths(lvm, dmsetup, nsenter string) {
+	internalLvmdCommand.LVMPath = lvm
+	internalLvmdCommand.DmsetupPath = dmsetup
+	internalLvmdCommand.NsenterPath = nsenter
+}
+
+// SetCommandTimeouts sets the timeouts of lvm commands by the sub-command.
+// The timeout of "default" applies to the commands not listed.
+func SetCommandTimeouts(timeouts map[string]time.Duration) {
+	internalLvmdCommand.CommandTimeouts = timeouts
+}
+
+// RetryPolicy is the capped exponential backoff of retrying lvm commands.
+type RetryPolicy = internalLvmdCommand.RetryPolicy
+
+// SetLockRetry sets the policy of retrying lvm commands failing on lock contention or busy devices.
+func SetLockRetry(policy RetryPolicy) {
+	internalLvmdCommand.LockRetry = policy
+}
+
+// SetUdevSettleTimeout sets the maximum time to wait for udev after creating, removing or renaming
+// logical volumes. Zero disables waiting.
+func SetUdevSettleTimeout(timeout time.Duration) {
+	internalLvmdCommand.UdevSettleTimeout = timeout
+}
+
+// SetFailureLogInterval sets the interval in which identical failures of lvm commands are logged only once.
+// Zero logs every failure.
+func SetFailureLogInterval(interval time.Duration) {
+	internalLvmdCommand.FailureLogInterval = interval
+}
+
+// SetFreeBytesCacheMaxAge sets the maximum age of the free bytes returned by GetFreeBytes from the cache
+// instead of running lvm. Zero disables the cache.
+func SetFreeBytesCacheMaxAge(maxAge time.Duration) {
+	internalLvmd.FreeBytesCacheMaxAge = maxAge
+}
+
+// DetectLVMVersion detects the version of lvm and enables the features depending on it, such as the json_std
+// report format. lvm is used with the features of all supported versions if the detection fails.
+func DetectLVMVersion(ctx context.Context) error {
+	_, err := internalLvmdCommand.DetectVersion(ctx)
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
 	"github.com/topolvm/topolvm/pkg/lvmd/proto"
 	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func NewEmbeddedServiceClients(
@@ -21,3 +22,8 @@ func NewEmbeddedServiceClients(
 
 	return internalLvmd.NewEmbeddedServiceClients(ctx, dcManager, lvOptionClassManager)
 }
+
+// NewEmbeddedHealthClient creates a health client checking the device-classes locally, like the health service of lvmd.
+func NewEmbeddedHealthClient(deviceClasses []*lvmdTypes.DeviceClass) grpc_health_v1.HealthClient {
+	return internalLvmd.NewEmbeddedHealthClient(internalLvmd.NewDeviceClassManager(deviceClasses))
+}
@@ -2,7 +2,7 @@
 // LVMd manages logical volumes of an LVM volume group.
 //
 // The protocol consists of two services:
-// - VGService provides information of the volume group.
+// - VGService provides information of the volume group and manages the volume groups.
 // - LVService provides management functions for logical volumes on the volume group.
 
 // Code generated by protoc-gen-go. DO NOT EDIT.
@@ -73,12 +73,18 @@ type LogicalVolume struct {
 
 	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
 	// Deprecated: Marked as deprecated in pkg/lvmd/proto/lvmd.proto.
-	SizeGb    uint64   `protobuf:"varint,2,opt,name=size_gb,json=sizeGb,proto3" json:"size_gb,omitempty"`          // Volume size in GiB.
-	DevMajor  uint32   `protobuf:"varint,3,opt,name=dev_major,json=devMajor,proto3" json:"dev_major,omitempty"`    // Device major number.
-	DevMinor  uint32   `protobuf:"varint,4,opt,name=dev_minor,json=devMinor,proto3" json:"dev_minor,omitempty"`    // Device minor number.
-	Tags      []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`                             // Tags to add to the volume during creation
-	SizeBytes int64    `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Volume size in canonical CSI bytes.
-	Attr      string   `protobuf:"bytes,7,opt,name=attr,proto3" json:"attr,omitempty"`                             // Volume attributes.
+	SizeGb           uint64   `protobuf:"varint,2,opt,name=size_gb,json=sizeGb,proto3" json:"size_gb,omitempty"`                                  // Volume size in GiB.
+	DevMajor         uint32   `protobuf:"varint,3,opt,name=dev_major,json=devMajor,proto3" json:"dev_major,omitempty"`                            // Device major number.
+	DevMinor         uint32   `protobuf:"varint,4,opt,name=dev_minor,json=devMinor,proto3" json:"dev_minor,omitempty"`                            // Device minor number.
+	Tags             []string `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`                                                     // Tags to add to the volume during creation
+	SizeBytes        int64    `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                         // Volume size in canonical CSI bytes.
+	Attr             string   `protobuf:"bytes,7,opt,name=attr,proto3" json:"attr,omitempty"`                                                     // Volume attributes.
+	CopyPercent      float64  `protobuf:"fixed64,8,opt,name=copy_percent,json=copyPercent,proto3" json:"copy_percent,omitempty"`                  // Synchronization progress of RAID volumes in percent.
+	VdoSavingPercent float64  `protobuf:"fixed64,9,opt,name=vdo_saving_percent,json=vdoSavingPercent,proto3" json:"vdo_saving_percent,omitempty"` // Space saving of VDO volumes in percent.
+	AllocatedBytes   uint64   `protobuf:"varint,10,opt,name=allocated_bytes,json=allocatedBytes,proto3" json:"allocated_bytes,omitempty"`         // Space allocated from the thin pool or volume group in bytes.
+	HealthError      string   `protobuf:"bytes,11,opt,name=health_error,json=healthError,proto3" json:"health_error,omitempty"`                   // Why the volume is unhealthy according to attr, or empty if it is healthy.
+	PoolHealthError  string   `protobuf:"bytes,12,opt,name=pool_health_error,json=poolHealthError,proto3" json:"pool_health_error,omitempty"`     // Why the thin pool of a thin volume is unhealthy, e.g. out of data space, or empty if it is healthy.
+	Origin           string   `protobuf:"bytes,13,opt,name=origin,proto3" json:"origin,omitempty"`                                                // The origin of a snapshot, or empty if the volume is not a snapshot.
 }
 
 func (x *LogicalVolume) Reset() {
@@ -163,6 +169,184 @@ func (x *LogicalVolume) GetAttr() string {
 	return ""
 }
 
+func (x *LogicalVolume) GetCopyPercent() float64 {
+	if x != nil {
+		return x.CopyPercent
+	}
+	return 0
+}
+
+func (x *LogicalVolume) GetVdoSavingPercent() float64 {
+	if x != nil {
+		return x.VdoSavingPercent
+	}
+	return 0
+}
+
+func (x *LogicalVolume) GetAllocatedBytes() uint64 {
+	if x != nil {
+		return x.AllocatedBytes
+	}
+	return 0
+}
+
+func (x *LogicalVolume) GetHealthError() string {
+	if x != nil {
+		return x.HealthError
+	}
+	return ""
+}
+
+func (x *LogicalVolume) GetPoolHealthError() string {
+	if x != nil {
+		return x.PoolHealthError
+	}
+	return ""
+}
+
+func (x *LogicalVolume) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+// Represents a warning that LVM printed although the operation succeeded.
+type Warning struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason  string `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`   // Category of the warning, e.g. "ThinPoolOverprovisioned".
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"` // The warning message printed by LVM.
+}
+
+func (x *Warning) Reset() {
+	*x = Warning{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Warning) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warning) ProtoMessage() {}
+
+func (x *Warning) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warning.ProtoReflect.Descriptor instead.
+func (*Warning) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Warning) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Warning) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Attached to the status of RESOURCE_EXHAUSTED when a volume does not fit into the free space of the device-class.
+type InsufficientSpace struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass    string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	VolumeGroup    string `protobuf:"bytes,2,opt,name=volume_group,json=volumeGroup,proto3" json:"volume_group,omitempty"`           // The volume group the volume was to be allocated from, or empty for raw device-classes.
+	ThinPool       string `protobuf:"bytes,3,opt,name=thin_pool,json=thinPool,proto3" json:"thin_pool,omitempty"`                    // The thin pool the volume was to be allocated from, or empty for thick volumes.
+	RequestedBytes uint64 `protobuf:"varint,4,opt,name=requested_bytes,json=requestedBytes,proto3" json:"requested_bytes,omitempty"` // The space the volume needs, including its cache and VDO pool.
+	FreeBytes      uint64 `protobuf:"varint,5,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"`                // The free space after the spare space, or the size of the largest free device of raw device-classes.
+}
+
+func (x *InsufficientSpace) Reset() {
+	*x = InsufficientSpace{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InsufficientSpace) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InsufficientSpace) ProtoMessage() {}
+
+func (x *InsufficientSpace) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InsufficientSpace.ProtoReflect.Descriptor instead.
+func (*InsufficientSpace) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *InsufficientSpace) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *InsufficientSpace) GetVolumeGroup() string {
+	if x != nil {
+		return x.VolumeGroup
+	}
+	return ""
+}
+
+func (x *InsufficientSpace) GetThinPool() string {
+	if x != nil {
+		return x.ThinPool
+	}
+	return ""
+}
+
+func (x *InsufficientSpace) GetRequestedBytes() uint64 {
+	if x != nil {
+		return x.RequestedBytes
+	}
+	return 0
+}
+
+func (x *InsufficientSpace) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
 // Represents the input for CreateLV.
 type CreateLVRequest struct {
 	state         protoimpl.MessageState
@@ -175,13 +359,15 @@ type CreateLVRequest struct {
 	Tags                []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`                    // Tags to add to the volume during creation
 	DeviceClass         string   `protobuf:"bytes,4,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
 	LvcreateOptionClass string   `protobuf:"bytes,5,opt,name=lvcreate_option_class,json=lvcreateOptionClass,proto3" json:"lvcreate_option_class,omitempty"`
-	SizeBytes           int64    `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Volume size in canonical CSI bytes.
+	SizeBytes           int64    `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                     // Volume size in canonical CSI bytes.
+	ProvisioningType    string   `protobuf:"bytes,7,opt,name=provisioning_type,json=provisioningType,proto3" json:"provisioning_type,omitempty"` // "thick" or "thin" to override the type of the device class.
+	LvcreateOptions     []string `protobuf:"bytes,8,rep,name=lvcreate_options,json=lvcreateOptions,proto3" json:"lvcreate_options,omitempty"`    // Inline arguments to lvcreate, which must be allowed by the inline-options of the lvcreate-option-class.
 }
 
 func (x *CreateLVRequest) Reset() {
 	*x = CreateLVRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[2]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -194,7 +380,7 @@ func (x *CreateLVRequest) String() string {
 func (*CreateLVRequest) ProtoMessage() {}
 
 func (x *CreateLVRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[2]
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +393,7 @@ func (x *CreateLVRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateLVRequest.ProtoReflect.Descriptor instead.
 func (*CreateLVRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{2}
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *CreateLVRequest) GetName() string {
@@ -253,19 +439,34 @@ func (x *CreateLVRequest) GetSizeBytes() int64 {
 	return 0
 }
 
+func (x *CreateLVRequest) GetProvisioningType() string {
+	if x != nil {
+		return x.ProvisioningType
+	}
+	return ""
+}
+
+func (x *CreateLVRequest) GetLvcreateOptions() []string {
+	if x != nil {
+		return x.LvcreateOptions
+	}
+	return nil
+}
+
 // Represents the response of CreateLV.
 type CreateLVResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Volume *LogicalVolume `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"` // Information of the created volume.
+	Volume   *LogicalVolume `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"`     // Information of the created volume.
+	Warnings []*Warning     `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"` // Warnings printed by LVM.
 }
 
 func (x *CreateLVResponse) Reset() {
 	*x = CreateLVResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[3]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -278,7 +479,7 @@ func (x *CreateLVResponse) String() string {
 func (*CreateLVResponse) ProtoMessage() {}
 
 func (x *CreateLVResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[3]
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -291,7 +492,7 @@ func (x *CreateLVResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateLVResponse.ProtoReflect.Descriptor instead.
 func (*CreateLVResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{3}
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *CreateLVResponse) GetVolume() *LogicalVolume {
@@ -301,20 +502,28 @@ func (x *CreateLVResponse) GetVolume() *LogicalVolume {
 	return nil
 }
 
+func (x *CreateLVResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
 // Represents the input for RemoveLV.
 type RemoveLVRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
-	DeviceClass string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Name         string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	DeviceClass  string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	WipeOnDelete string `protobuf:"bytes,3,opt,name=wipe_on_delete,json=wipeOnDelete,proto3" json:"wipe_on_delete,omitempty"` // Overrides the wipe-on-delete of the device class: "none", "discard" or "zero".
 }
 
 func (x *RemoveLVRequest) Reset() {
 	*x = RemoveLVRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[4]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -327,7 +536,7 @@ func (x *RemoveLVRequest) String() string {
 func (*RemoveLVRequest) ProtoMessage() {}
 
 func (x *RemoveLVRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[4]
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -340,7 +549,7 @@ func (x *RemoveLVRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RemoveLVRequest.ProtoReflect.Descriptor instead.
 func (*RemoveLVRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{4}
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *RemoveLVRequest) GetName() string {
@@ -357,6 +566,13 @@ func (x *RemoveLVRequest) GetDeviceClass() string {
 	return ""
 }
 
+func (x *RemoveLVRequest) GetWipeOnDelete() string {
+	if x != nil {
+		return x.WipeOnDelete
+	}
+	return ""
+}
+
 type CreateLVSnapshotRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -375,7 +591,7 @@ type CreateLVSnapshotRequest struct {
 func (x *CreateLVSnapshotRequest) Reset() {
 	*x = CreateLVSnapshotRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[5]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -388,7 +604,7 @@ func (x *CreateLVSnapshotRequest) String() string {
 func (*CreateLVSnapshotRequest) ProtoMessage() {}
 
 func (x *CreateLVSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[5]
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -401,7 +617,7 @@ func (x *CreateLVSnapshotRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateLVSnapshotRequest.ProtoReflect.Descriptor instead.
 func (*CreateLVSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{5}
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *CreateLVSnapshotRequest) GetName() string {
@@ -460,12 +676,13 @@ type CreateLVSnapshotResponse struct {
 	unknownFields protoimpl.UnknownFields
 
 	Snapshot *LogicalVolume `protobuf:"bytes,1,opt,name=snapshot,proto3" json:"snapshot,omitempty"` // Information of the created snapshot lv.
+	Warnings []*Warning     `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"` // Warnings printed by LVM.
 }
 
 func (x *CreateLVSnapshotResponse) Reset() {
 	*x = CreateLVSnapshotResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[6]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -478,7 +695,7 @@ func (x *CreateLVSnapshotResponse) String() string {
 func (*CreateLVSnapshotResponse) ProtoMessage() {}
 
 func (x *CreateLVSnapshotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[6]
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -491,7 +708,7 @@ func (x *CreateLVSnapshotResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateLVSnapshotResponse.ProtoReflect.Descriptor instead.
 func (*CreateLVSnapshotResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{6}
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *CreateLVSnapshotResponse) GetSnapshot() *LogicalVolume {
@@ -501,39 +718,44 @@ func (x *CreateLVSnapshotResponse) GetSnapshot() *LogicalVolume {
 	return nil
 }
 
-// Represents the input for ResizeLV.
+func (x *CreateLVSnapshotResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// Represents the input for ChangeLVTags.
 //
-// The volume must already exist.
-// The volume size will be set to exactly "size_gb".
-type ResizeLVRequest struct {
+// A tag both added and removed is rejected.
+type ChangeLVTagsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
-	// Deprecated: Marked as deprecated in pkg/lvmd/proto/lvmd.proto.
-	SizeGb      uint64 `protobuf:"varint,2,opt,name=size_gb,json=sizeGb,proto3" json:"size_gb,omitempty"`          // Volume size in GiB.
-	SizeBytes   int64  `protobuf:"varint,7,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Volume size in canonical CSI bytes.
-	DeviceClass string `protobuf:"bytes,3,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Name        string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	DeviceClass string   `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	AddTags     []string `protobuf:"bytes,3,rep,name=add_tags,json=addTags,proto3" json:"add_tags,omitempty"`          // Tags to add to the volume.
+	RemoveTags  []string `protobuf:"bytes,4,rep,name=remove_tags,json=removeTags,proto3" json:"remove_tags,omitempty"` // Tags to remove from the volume.
 }
 
-func (x *ResizeLVRequest) Reset() {
-	*x = ResizeLVRequest{}
+func (x *ChangeLVTagsRequest) Reset() {
+	*x = ChangeLVTagsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[7]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ResizeLVRequest) String() string {
+func (x *ChangeLVTagsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ResizeLVRequest) ProtoMessage() {}
+func (*ChangeLVTagsRequest) ProtoMessage() {}
 
-func (x *ResizeLVRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[7]
+func (x *ChangeLVTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -544,66 +766,65 @@ func (x *ResizeLVRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ResizeLVRequest.ProtoReflect.Descriptor instead.
-func (*ResizeLVRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ChangeLVTagsRequest.ProtoReflect.Descriptor instead.
+func (*ChangeLVTagsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ResizeLVRequest) GetName() string {
+func (x *ChangeLVTagsRequest) GetName() string {
 	if x != nil {
 		return x.Name
 	}
 	return ""
 }
 
-// Deprecated: Marked as deprecated in pkg/lvmd/proto/lvmd.proto.
-func (x *ResizeLVRequest) GetSizeGb() uint64 {
+func (x *ChangeLVTagsRequest) GetDeviceClass() string {
 	if x != nil {
-		return x.SizeGb
+		return x.DeviceClass
 	}
-	return 0
+	return ""
 }
 
-func (x *ResizeLVRequest) GetSizeBytes() int64 {
+func (x *ChangeLVTagsRequest) GetAddTags() []string {
 	if x != nil {
-		return x.SizeBytes
+		return x.AddTags
 	}
-	return 0
+	return nil
 }
 
-func (x *ResizeLVRequest) GetDeviceClass() string {
+func (x *ChangeLVTagsRequest) GetRemoveTags() []string {
 	if x != nil {
-		return x.DeviceClass
+		return x.RemoveTags
 	}
-	return ""
+	return nil
 }
 
-// Represents the response of GetLVList.
-type GetLVListResponse struct {
+// Represents the response of ChangeLVTags.
+type ChangeLVTagsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Volumes []*LogicalVolume `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"` // Information of volumes.
+	Tags []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"` // Tags of the volume after the change.
 }
 
-func (x *GetLVListResponse) Reset() {
-	*x = GetLVListResponse{}
+func (x *ChangeLVTagsResponse) Reset() {
+	*x = ChangeLVTagsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[8]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetLVListResponse) String() string {
+func (x *ChangeLVTagsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLVListResponse) ProtoMessage() {}
+func (*ChangeLVTagsResponse) ProtoMessage() {}
 
-func (x *GetLVListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[8]
+func (x *ChangeLVTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -614,44 +835,45 @@ func (x *GetLVListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLVListResponse.ProtoReflect.Descriptor instead.
-func (*GetLVListResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ChangeLVTagsResponse.ProtoReflect.Descriptor instead.
+func (*ChangeLVTagsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *GetLVListResponse) GetVolumes() []*LogicalVolume {
+func (x *ChangeLVTagsResponse) GetTags() []string {
 	if x != nil {
-		return x.Volumes
+		return x.Tags
 	}
 	return nil
 }
 
-// Represents the response of GetFreeBytes.
-type GetFreeBytesResponse struct {
+// Represents the input for ActivateLV.
+type ActivateLVRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FreeBytes uint64 `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space of the volume group in bytes.
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	DeviceClass string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
 }
 
-func (x *GetFreeBytesResponse) Reset() {
-	*x = GetFreeBytesResponse{}
+func (x *ActivateLVRequest) Reset() {
+	*x = ActivateLVRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[9]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetFreeBytesResponse) String() string {
+func (x *ActivateLVRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetFreeBytesResponse) ProtoMessage() {}
+func (*ActivateLVRequest) ProtoMessage() {}
 
-func (x *GetFreeBytesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[9]
+func (x *ActivateLVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -662,43 +884,51 @@ func (x *GetFreeBytesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetFreeBytesResponse.ProtoReflect.Descriptor instead.
-func (*GetFreeBytesResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use ActivateLVRequest.ProtoReflect.Descriptor instead.
+func (*ActivateLVRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetFreeBytesResponse) GetFreeBytes() uint64 {
+func (x *ActivateLVRequest) GetName() string {
 	if x != nil {
-		return x.FreeBytes
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-type GetLVListRequest struct {
+func (x *ActivateLVRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+// Represents the response of ActivateLV.
+type ActivateLVResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Volume *LogicalVolume `protobuf:"bytes,1,opt,name=volume,proto3" json:"volume,omitempty"` // Information of the activated volume.
 }
 
-func (x *GetLVListRequest) Reset() {
-	*x = GetLVListRequest{}
+func (x *ActivateLVResponse) Reset() {
+	*x = ActivateLVResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[10]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetLVListRequest) String() string {
+func (x *ActivateLVResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetLVListRequest) ProtoMessage() {}
+func (*ActivateLVResponse) ProtoMessage() {}
 
-func (x *GetLVListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[10]
+func (x *ActivateLVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -709,43 +939,45 @@ func (x *GetLVListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetLVListRequest.ProtoReflect.Descriptor instead.
-func (*GetLVListRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ActivateLVResponse.ProtoReflect.Descriptor instead.
+func (*ActivateLVResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GetLVListRequest) GetDeviceClass() string {
+func (x *ActivateLVResponse) GetVolume() *LogicalVolume {
 	if x != nil {
-		return x.DeviceClass
+		return x.Volume
 	}
-	return ""
+	return nil
 }
 
-type GetFreeBytesRequest struct {
+// Represents the input for DeactivateLV.
+type DeactivateLVRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	DeviceClass string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
 }
 
-func (x *GetFreeBytesRequest) Reset() {
-	*x = GetFreeBytesRequest{}
+func (x *DeactivateLVRequest) Reset() {
+	*x = DeactivateLVRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[11]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetFreeBytesRequest) String() string {
+func (x *DeactivateLVRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetFreeBytesRequest) ProtoMessage() {}
+func (*DeactivateLVRequest) ProtoMessage() {}
 
-func (x *GetFreeBytesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[11]
+func (x *DeactivateLVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -756,45 +988,56 @@ func (x *GetFreeBytesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetFreeBytesRequest.ProtoReflect.Descriptor instead.
-func (*GetFreeBytesRequest) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use DeactivateLVRequest.ProtoReflect.Descriptor instead.
+func (*DeactivateLVRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *GetFreeBytesRequest) GetDeviceClass() string {
+func (x *DeactivateLVRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DeactivateLVRequest) GetDeviceClass() string {
 	if x != nil {
 		return x.DeviceClass
 	}
 	return ""
 }
 
-// Represents the stream output from Watch.
-type WatchResponse struct {
+// Represents the input for CreateDeviceClassSnapshot.
+//
+// The thin volumes of the device class, except for the snapshots, are snapshotted at a single point in time.
+// The snapshot of each volume is named by appending "-" and name_suffix to the name of the volume.
+type CreateDeviceClassSnapshotRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FreeBytes uint64       `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space of the default volume group in bytes. In the case of thin pools, free space on the thinpool with overprovision in bytes.
-	Items     []*WatchItem `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	DeviceClass string   `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	NameSuffix  string   `protobuf:"bytes,2,opt,name=name_suffix,json=nameSuffix,proto3" json:"name_suffix,omitempty"` // Suffix of the names of the snapshots.
+	Tags        []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`                               // Tags to add to the snapshots.
 }
 
-func (x *WatchResponse) Reset() {
-	*x = WatchResponse{}
+func (x *CreateDeviceClassSnapshotRequest) Reset() {
+	*x = CreateDeviceClassSnapshotRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[12]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *WatchResponse) String() string {
+func (x *CreateDeviceClassSnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WatchResponse) ProtoMessage() {}
+func (*CreateDeviceClassSnapshotRequest) ProtoMessage() {}
 
-func (x *WatchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[12]
+func (x *CreateDeviceClassSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -805,54 +1048,59 @@ func (x *WatchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
-func (*WatchResponse) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use CreateDeviceClassSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*CreateDeviceClassSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *WatchResponse) GetFreeBytes() uint64 {
+func (x *CreateDeviceClassSnapshotRequest) GetDeviceClass() string {
 	if x != nil {
-		return x.FreeBytes
+		return x.DeviceClass
 	}
-	return 0
+	return ""
 }
 
-func (x *WatchResponse) GetItems() []*WatchItem {
+func (x *CreateDeviceClassSnapshotRequest) GetNameSuffix() string {
 	if x != nil {
-		return x.Items
+		return x.NameSuffix
+	}
+	return ""
+}
+
+func (x *CreateDeviceClassSnapshotRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
 	}
 	return nil
 }
 
-// Represents the details of thinpool.
-type ThinPoolItem struct {
+// Represents the response of CreateDeviceClassSnapshot.
+type CreateDeviceClassSnapshotResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	DataPercent        float64 `protobuf:"fixed64,1,opt,name=data_percent,json=dataPercent,proto3" json:"data_percent,omitempty"`                     // Data percent occupied on the thinpool, used for monitoring.
-	MetadataPercent    float64 `protobuf:"fixed64,2,opt,name=metadata_percent,json=metadataPercent,proto3" json:"metadata_percent,omitempty"`         // Metadata percent occupied on the thinpool, used for monitoring.
-	OverprovisionBytes uint64  `protobuf:"varint,3,opt,name=overprovision_bytes,json=overprovisionBytes,proto3" json:"overprovision_bytes,omitempty"` // Free space on the thinpool with overprovision, used for annotating node.
-	SizeBytes          uint64  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                            // Physical data space size of the thinpool.
+	Snapshots map[string]string `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"` // Names of the snapshots keyed by the names of their source volumes.
+	Warnings  []*Warning        `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`                                                                                           // Warnings printed by LVM.
 }
 
-func (x *ThinPoolItem) Reset() {
-	*x = ThinPoolItem{}
+func (x *CreateDeviceClassSnapshotResponse) Reset() {
+	*x = CreateDeviceClassSnapshotResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[13]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *ThinPoolItem) String() string {
+func (x *CreateDeviceClassSnapshotResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ThinPoolItem) ProtoMessage() {}
+func (*CreateDeviceClassSnapshotResponse) ProtoMessage() {}
 
-func (x *ThinPoolItem) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[13]
+func (x *CreateDeviceClassSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -863,68 +1111,111 @@ func (x *ThinPoolItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ThinPoolItem.ProtoReflect.Descriptor instead.
-func (*ThinPoolItem) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use CreateDeviceClassSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*CreateDeviceClassSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ThinPoolItem) GetDataPercent() float64 {
+func (x *CreateDeviceClassSnapshotResponse) GetSnapshots() map[string]string {
 	if x != nil {
-		return x.DataPercent
+		return x.Snapshots
 	}
-	return 0
+	return nil
 }
 
-func (x *ThinPoolItem) GetMetadataPercent() float64 {
+func (x *CreateDeviceClassSnapshotResponse) GetWarnings() []*Warning {
 	if x != nil {
-		return x.MetadataPercent
+		return x.Warnings
 	}
-	return 0
+	return nil
 }
 
-func (x *ThinPoolItem) GetOverprovisionBytes() uint64 {
+// Represents the input for MergeLVSnapshot.
+//
+// The snapshot is merged into its origin and removed once the merge has completed.
+type MergeLVSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name        string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The name of the snapshot logical volume.
+	DeviceClass string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+}
+
+func (x *MergeLVSnapshotRequest) Reset() {
+	*x = MergeLVSnapshotRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MergeLVSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MergeLVSnapshotRequest) ProtoMessage() {}
+
+func (x *MergeLVSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MergeLVSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*MergeLVSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *MergeLVSnapshotRequest) GetName() string {
 	if x != nil {
-		return x.OverprovisionBytes
+		return x.Name
 	}
-	return 0
+	return ""
 }
 
-func (x *ThinPoolItem) GetSizeBytes() uint64 {
+func (x *MergeLVSnapshotRequest) GetDeviceClass() string {
 	if x != nil {
-		return x.SizeBytes
+		return x.DeviceClass
 	}
-	return 0
+	return ""
 }
 
-// Represents the response corresponding to device class targets.
-type WatchItem struct {
+// Represents the response of MergeLVSnapshot.
+type MergeLVSnapshotResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	FreeBytes   uint64        `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space in the volume group in bytes.
-	DeviceClass string        `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
-	SizeBytes   uint64        `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Size of volume group in bytes.
-	ThinPool    *ThinPoolItem `protobuf:"bytes,4,opt,name=thin_pool,json=thinPool,proto3" json:"thin_pool,omitempty"`
+	Completed       bool       `protobuf:"varint,1,opt,name=completed,proto3" json:"completed,omitempty"`                                     // True if the merge has completed and the snapshot is removed.
+	ProgressPercent float64    `protobuf:"fixed64,2,opt,name=progress_percent,json=progressPercent,proto3" json:"progress_percent,omitempty"` // Progress of the merge in percent.
+	Warnings        []*Warning `protobuf:"bytes,3,rep,name=warnings,proto3" json:"warnings,omitempty"`                                        // Warnings printed by LVM.
 }
 
-func (x *WatchItem) Reset() {
-	*x = WatchItem{}
+func (x *MergeLVSnapshotResponse) Reset() {
+	*x = MergeLVSnapshotResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[14]
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *WatchItem) String() string {
+func (x *MergeLVSnapshotResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*WatchItem) ProtoMessage() {}
+func (*MergeLVSnapshotResponse) ProtoMessage() {}
 
-func (x *WatchItem) ProtoReflect() protoreflect.Message {
-	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[14]
+func (x *MergeLVSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -935,259 +1226,3174 @@ func (x *WatchItem) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use WatchItem.ProtoReflect.Descriptor instead.
-func (*WatchItem) Descriptor() ([]byte, []int) {
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{14}
-}
-
-func (x *WatchItem) GetFreeBytes() uint64 {
-	if x != nil {
-		return x.FreeBytes
-	}
-	return 0
+// Deprecated: Use MergeLVSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*MergeLVSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *WatchItem) GetDeviceClass() string {
+func (x *MergeLVSnapshotResponse) GetCompleted() bool {
 	if x != nil {
-		return x.DeviceClass
+		return x.Completed
 	}
-	return ""
+	return false
 }
 
-func (x *WatchItem) GetSizeBytes() uint64 {
+func (x *MergeLVSnapshotResponse) GetProgressPercent() float64 {
 	if x != nil {
-		return x.SizeBytes
+		return x.ProgressPercent
 	}
 	return 0
 }
 
-func (x *WatchItem) GetThinPool() *ThinPoolItem {
+func (x *MergeLVSnapshotResponse) GetWarnings() []*Warning {
 	if x != nil {
-		return x.ThinPool
+		return x.Warnings
 	}
 	return nil
 }
 
-var File_pkg_lvmd_proto_lvmd_proto protoreflect.FileDescriptor
+// Represents the input for MoveLV.
+//
+// The thin volume is copied to a new volume in the thin pool of target_device_class, which then replaces it.
+// The volume must not be in use.
+type MoveLVRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_pkg_lvmd_proto_lvmd_proto_rawDesc = []byte{
-	0x0a, 0x19, 0x70, 0x6b, 0x67, 0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0xc1, 0x01, 0x0a, 0x0d,
-	0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x12, 0x0a,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
-	0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67, 0x62, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a, 0x65, 0x47, 0x62, 0x12, 0x1b,
-	0x0a, 0x09, 0x64, 0x65, 0x76, 0x5f, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
-	0x0d, 0x52, 0x08, 0x64, 0x65, 0x76, 0x4d, 0x61, 0x6a, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x64,
-	0x65, 0x76, 0x5f, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
-	0x64, 0x65, 0x76, 0x4d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73,
-	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
-	0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
-	0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61,
-	0x74, 0x74, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x22,
-	0xcc, 0x01, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f,
-	0x67, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69,
-	0x7a, 0x65, 0x47, 0x62, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x6c,
-	0x76, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63,
-	0x6c, 0x61, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x6c, 0x76, 0x63, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12,
-	0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20,
-	0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x40,
-	0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
-	0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63,
-	0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
-	0x22, 0x48, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63,
-	0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
-	0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0xe6, 0x01, 0x0a, 0x17, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61,
-	0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21,
-	0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x03,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73,
-	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x6f, 0x6c, 0x75,
-	0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
-	0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67,
-	0x62, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a,
-	0x65, 0x47, 0x62, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x74, 0x79,
-	0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74,
-	0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79,
-	0x74, 0x65, 0x73, 0x22, 0x4c, 0x0a, 0x18, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x53,
-	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x30, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
-	0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61,
-	0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x22, 0x84, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a,
-	0x65, 0x5f, 0x67, 0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06,
-	0x73, 0x69, 0x7a, 0x65, 0x47, 0x62, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62,
-	0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65,
-	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
-	0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x43, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4c,
-	0x56, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a,
-	0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f,
-	0x6c, 0x75, 0x6d, 0x65, 0x52, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x22, 0x35, 0x0a,
-	0x14, 0x47, 0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x62, 0x79,
-	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x65, 0x65, 0x42,
-	0x79, 0x74, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x38, 0x0a, 0x13, 0x47,
-	0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
-	0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61,
-	0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x56, 0x0a, 0x0d, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x62,
-	0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x65, 0x65,
-	0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x26, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x02,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x74,
-	0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0xac, 0x01,
-	0x0a, 0x0c, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x21,
-	0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
-	0x74, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x65,
-	0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6d, 0x65, 0x74,
-	0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x2f, 0x0a, 0x13,
-	0x6f, 0x76, 0x65, 0x72, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x79,
-	0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12, 0x6f, 0x76, 0x65, 0x72, 0x70,
-	0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a,
-	0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
-	0x04, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x9e, 0x01, 0x0a,
-	0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72,
-	0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
-	0x66, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76,
-	0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
-	0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
-	0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x09, 0x74,
-	0x68, 0x69, 0x6e, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x49,
-	0x74, 0x65, 0x6d, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x32, 0x81, 0x02,
-	0x0a, 0x09, 0x4c, 0x56, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b, 0x0a, 0x08, 0x43,
-	0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65, 0x6d, 0x6f,
-	0x76, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x6d,
-	0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65,
-	0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52,
-	0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c,
-	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x53, 0x0a, 0x10,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
-	0x12, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c,
-	0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c,
-	0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x32, 0xc3, 0x01, 0x0a, 0x09, 0x56, 0x47, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x3e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x17, 0x2e, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65,
-	0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
-	0x47, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
-	0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42,
-	0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63,
-	0x68, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a,
-	0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x74, 0x6f, 0x70, 0x6f, 0x6c, 0x76, 0x6d, 0x2f, 0x74, 0x6f,
-	0x70, 0x6f, 0x6c, 0x76, 0x6d, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2f, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	Name              string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	DeviceClass       string `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	TargetDeviceClass string `protobuf:"bytes,3,opt,name=target_device_class,json=targetDeviceClass,proto3" json:"target_device_class,omitempty"` // A thin device class of the same volume group.
+	WipeOnDelete      string `protobuf:"bytes,4,opt,name=wipe_on_delete,json=wipeOnDelete,proto3" json:"wipe_on_delete,omitempty"`                // Overrides the wipe-on-delete of device_class for the replaced volume.
 }
 
-var (
-	file_pkg_lvmd_proto_lvmd_proto_rawDescOnce sync.Once
-	file_pkg_lvmd_proto_lvmd_proto_rawDescData = file_pkg_lvmd_proto_lvmd_proto_rawDesc
-)
+func (x *MoveLVRequest) Reset() {
+	*x = MoveLVRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP() []byte {
-	file_pkg_lvmd_proto_lvmd_proto_rawDescOnce.Do(func() {
-		file_pkg_lvmd_proto_lvmd_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_lvmd_proto_lvmd_proto_rawDescData)
-	})
-	return file_pkg_lvmd_proto_lvmd_proto_rawDescData
+func (x *MoveLVRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_pkg_lvmd_proto_lvmd_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
-var file_pkg_lvmd_proto_lvmd_proto_goTypes = []interface{}{
-	(*Empty)(nil),                    // 0: proto.Empty
-	(*LogicalVolume)(nil),            // 1: proto.LogicalVolume
-	(*CreateLVRequest)(nil),          // 2: proto.CreateLVRequest
-	(*CreateLVResponse)(nil),         // 3: proto.CreateLVResponse
-	(*RemoveLVRequest)(nil),          // 4: proto.RemoveLVRequest
-	(*CreateLVSnapshotRequest)(nil),  // 5: proto.CreateLVSnapshotRequest
-	(*CreateLVSnapshotResponse)(nil), // 6: proto.CreateLVSnapshotResponse
-	(*ResizeLVRequest)(nil),          // 7: proto.ResizeLVRequest
-	(*GetLVListResponse)(nil),        // 8: proto.GetLVListResponse
-	(*GetFreeBytesResponse)(nil),     // 9: proto.GetFreeBytesResponse
-	(*GetLVListRequest)(nil),         // 10: proto.GetLVListRequest
-	(*GetFreeBytesRequest)(nil),      // 11: proto.GetFreeBytesRequest
-	(*WatchResponse)(nil),            // 12: proto.WatchResponse
-	(*ThinPoolItem)(nil),             // 13: proto.ThinPoolItem
-	(*WatchItem)(nil),                // 14: proto.WatchItem
+func (*MoveLVRequest) ProtoMessage() {}
+
+func (x *MoveLVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
-var file_pkg_lvmd_proto_lvmd_proto_depIdxs = []int32{
-	1,  // 0: proto.CreateLVResponse.volume:type_name -> proto.LogicalVolume
-	1,  // 1: proto.CreateLVSnapshotResponse.snapshot:type_name -> proto.LogicalVolume
-	1,  // 2: proto.GetLVListResponse.volumes:type_name -> proto.LogicalVolume
-	14, // 3: proto.WatchResponse.items:type_name -> proto.WatchItem
-	13, // 4: proto.WatchItem.thin_pool:type_name -> proto.ThinPoolItem
-	2,  // 5: proto.LVService.CreateLV:input_type -> proto.CreateLVRequest
-	4,  // 6: proto.LVService.RemoveLV:input_type -> proto.RemoveLVRequest
-	7,  // 7: proto.LVService.ResizeLV:input_type -> proto.ResizeLVRequest
-	5,  // 8: proto.LVService.CreateLVSnapshot:input_type -> proto.CreateLVSnapshotRequest
-	10, // 9: proto.VGService.GetLVList:input_type -> proto.GetLVListRequest
-	11, // 10: proto.VGService.GetFreeBytes:input_type -> proto.GetFreeBytesRequest
-	0,  // 11: proto.VGService.Watch:input_type -> proto.Empty
-	3,  // 12: proto.LVService.CreateLV:output_type -> proto.CreateLVResponse
-	0,  // 13: proto.LVService.RemoveLV:output_type -> proto.Empty
-	0,  // 14: proto.LVService.ResizeLV:output_type -> proto.Empty
-	6,  // 15: proto.LVService.CreateLVSnapshot:output_type -> proto.CreateLVSnapshotResponse
-	8,  // 16: proto.VGService.GetLVList:output_type -> proto.GetLVListResponse
-	9,  // 17: proto.VGService.GetFreeBytes:output_type -> proto.GetFreeBytesResponse
-	12, // 18: proto.VGService.Watch:output_type -> proto.WatchResponse
-	12, // [12:19] is the sub-list for method output_type
-	5,  // [5:12] is the sub-list for method input_type
-	5,  // [5:5] is the sub-list for extension type_name
-	5,  // [5:5] is the sub-list for extension extendee
-	0,  // [0:5] is the sub-list for field type_name
+
+// Deprecated: Use MoveLVRequest.ProtoReflect.Descriptor instead.
+func (*MoveLVRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{18}
 }
 
-func init() { file_pkg_lvmd_proto_lvmd_proto_init() }
-func file_pkg_lvmd_proto_lvmd_proto_init() {
-	if File_pkg_lvmd_proto_lvmd_proto != nil {
-		return
+func (x *MoveLVRequest) GetName() string {
+	if x != nil {
+		return x.Name
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Empty); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
+	return ""
+}
+
+func (x *MoveLVRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *MoveLVRequest) GetTargetDeviceClass() string {
+	if x != nil {
+		return x.TargetDeviceClass
+	}
+	return ""
+}
+
+func (x *MoveLVRequest) GetWipeOnDelete() string {
+	if x != nil {
+		return x.WipeOnDelete
+	}
+	return ""
+}
+
+// Represents the response of MoveLV.
+type MoveLVResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Completed       bool    `protobuf:"varint,1,opt,name=completed,proto3" json:"completed,omitempty"`                                     // True if the volume has been replaced by the one in the target thin pool.
+	ProgressPercent float64 `protobuf:"fixed64,2,opt,name=progress_percent,json=progressPercent,proto3" json:"progress_percent,omitempty"` // Progress of the copy in percent.
+}
+
+func (x *MoveLVResponse) Reset() {
+	*x = MoveLVResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MoveLVResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MoveLVResponse) ProtoMessage() {}
+
+func (x *MoveLVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MoveLVResponse.ProtoReflect.Descriptor instead.
+func (*MoveLVResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *MoveLVResponse) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *MoveLVResponse) GetProgressPercent() float64 {
+	if x != nil {
+		return x.ProgressPercent
+	}
+	return 0
+}
+
+// Represents the input for ResizeLV.
+//
+// The volume must already exist.
+// The volume size will be set to exactly "size_gb".
+type ResizeLVRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"` // The logical volume name.
+	// Deprecated: Marked as deprecated in pkg/lvmd/proto/lvmd.proto.
+	SizeGb      uint64 `protobuf:"varint,2,opt,name=size_gb,json=sizeGb,proto3" json:"size_gb,omitempty"`          // Volume size in GiB.
+	SizeBytes   int64  `protobuf:"varint,7,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Volume size in canonical CSI bytes.
+	DeviceClass string `protobuf:"bytes,3,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	// Shrink the volume and its filesystem if the size is smaller than the current size.
+	// The device-class must allow shrinking and the volume must not be in use.
+	AllowShrink bool `protobuf:"varint,8,opt,name=allow_shrink,json=allowShrink,proto3" json:"allow_shrink,omitempty"`
+}
+
+func (x *ResizeLVRequest) Reset() {
+	*x = ResizeLVRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeLVRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeLVRequest) ProtoMessage() {}
+
+func (x *ResizeLVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeLVRequest.ProtoReflect.Descriptor instead.
+func (*ResizeLVRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ResizeLVRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in pkg/lvmd/proto/lvmd.proto.
+func (x *ResizeLVRequest) GetSizeGb() uint64 {
+	if x != nil {
+		return x.SizeGb
+	}
+	return 0
+}
+
+func (x *ResizeLVRequest) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *ResizeLVRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *ResizeLVRequest) GetAllowShrink() bool {
+	if x != nil {
+		return x.AllowShrink
+	}
+	return false
+}
+
+// Represents the response of ResizeLV.
+type ResizeLVResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Warnings []*Warning `protobuf:"bytes,1,rep,name=warnings,proto3" json:"warnings,omitempty"` // Warnings printed by LVM.
+}
+
+func (x *ResizeLVResponse) Reset() {
+	*x = ResizeLVResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResizeLVResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResizeLVResponse) ProtoMessage() {}
+
+func (x *ResizeLVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResizeLVResponse.ProtoReflect.Descriptor instead.
+func (*ResizeLVResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ResizeLVResponse) GetWarnings() []*Warning {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+// Represents the response of GetLVList.
+type GetLVListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Volumes []*LogicalVolume `protobuf:"bytes,1,rep,name=volumes,proto3" json:"volumes,omitempty"` // Information of volumes.
+}
+
+func (x *GetLVListResponse) Reset() {
+	*x = GetLVListResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLVListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLVListResponse) ProtoMessage() {}
+
+func (x *GetLVListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLVListResponse.ProtoReflect.Descriptor instead.
+func (*GetLVListResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetLVListResponse) GetVolumes() []*LogicalVolume {
+	if x != nil {
+		return x.Volumes
+	}
+	return nil
+}
+
+// Represents the response of GetFreeBytes.
+type GetFreeBytesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FreeBytes uint64 `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space of the volume group in bytes.
+}
+
+func (x *GetFreeBytesResponse) Reset() {
+	*x = GetFreeBytesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetFreeBytesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFreeBytesResponse) ProtoMessage() {}
+
+func (x *GetFreeBytesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFreeBytesResponse.ProtoReflect.Descriptor instead.
+func (*GetFreeBytesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetFreeBytesResponse) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+type GetLVListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+}
+
+func (x *GetLVListRequest) Reset() {
+	*x = GetLVListRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLVListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLVListRequest) ProtoMessage() {}
+
+func (x *GetLVListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLVListRequest.ProtoReflect.Descriptor instead.
+func (*GetLVListRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetLVListRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+// Represents the input for ListSnapshots.
+type ListSnapshotsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Origin      string `protobuf:"bytes,2,opt,name=origin,proto3" json:"origin,omitempty"` // The origin of the snapshots to list. All the snapshots of the device class are listed if empty.
+}
+
+func (x *ListSnapshotsRequest) Reset() {
+	*x = ListSnapshotsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListSnapshotsRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *ListSnapshotsRequest) GetOrigin() string {
+	if x != nil {
+		return x.Origin
+	}
+	return ""
+}
+
+// Represents the output from ListSnapshots.
+type ListSnapshotsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Snapshots []*LogicalVolume `protobuf:"bytes,1,rep,name=snapshots,proto3" json:"snapshots,omitempty"` // The snapshots sorted by name.
+}
+
+func (x *ListSnapshotsResponse) Reset() {
+	*x = ListSnapshotsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ListSnapshotsResponse) GetSnapshots() []*LogicalVolume {
+	if x != nil {
+		return x.Snapshots
+	}
+	return nil
+}
+
+type GetFreeBytesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass  string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	ForceRefresh bool   `protobuf:"varint,2,opt,name=force_refresh,json=forceRefresh,proto3" json:"force_refresh,omitempty"` // Query lvm instead of returning the free bytes cached by lvmd.
+}
+
+func (x *GetFreeBytesRequest) Reset() {
+	*x = GetFreeBytesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetFreeBytesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFreeBytesRequest) ProtoMessage() {}
+
+func (x *GetFreeBytesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFreeBytesRequest.ProtoReflect.Descriptor instead.
+func (*GetFreeBytesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetFreeBytesRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *GetFreeBytesRequest) GetForceRefresh() bool {
+	if x != nil {
+		return x.ForceRefresh
+	}
+	return false
+}
+
+// Represents the input for CreateVG.
+type CreateVGRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VgName  string   `protobuf:"bytes,1,opt,name=vg_name,json=vgName,proto3" json:"vg_name,omitempty"` // The volume group name.
+	Devices []string `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty"`             // The devices of the volume group, which are initialized as physical volumes if needed.
+}
+
+func (x *CreateVGRequest) Reset() {
+	*x = CreateVGRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateVGRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateVGRequest) ProtoMessage() {}
+
+func (x *CreateVGRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateVGRequest.ProtoReflect.Descriptor instead.
+func (*CreateVGRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *CreateVGRequest) GetVgName() string {
+	if x != nil {
+		return x.VgName
+	}
+	return ""
+}
+
+func (x *CreateVGRequest) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// Represents the input for RemoveVG.
+type RemoveVGRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VgName string `protobuf:"bytes,1,opt,name=vg_name,json=vgName,proto3" json:"vg_name,omitempty"` // The volume group name.
+}
+
+func (x *RemoveVGRequest) Reset() {
+	*x = RemoveVGRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoveVGRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveVGRequest) ProtoMessage() {}
+
+func (x *RemoveVGRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveVGRequest.ProtoReflect.Descriptor instead.
+func (*RemoveVGRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RemoveVGRequest) GetVgName() string {
+	if x != nil {
+		return x.VgName
+	}
+	return ""
+}
+
+// Represents the input for ExtendVG.
+type ExtendVGRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string   `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Devices     []string `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty"` // The devices to add, which are initialized as physical volumes if needed.
+}
+
+func (x *ExtendVGRequest) Reset() {
+	*x = ExtendVGRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExtendVGRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExtendVGRequest) ProtoMessage() {}
+
+func (x *ExtendVGRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExtendVGRequest.ProtoReflect.Descriptor instead.
+func (*ExtendVGRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ExtendVGRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *ExtendVGRequest) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// Represents the input for ReduceVG.
+type ReduceVGRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string   `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Devices     []string `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty"` // The physical volumes to remove, which must not be in use.
+}
+
+func (x *ReduceVGRequest) Reset() {
+	*x = ReduceVGRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReduceVGRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReduceVGRequest) ProtoMessage() {}
+
+func (x *ReduceVGRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReduceVGRequest.ProtoReflect.Descriptor instead.
+func (*ReduceVGRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ReduceVGRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *ReduceVGRequest) GetDevices() []string {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+// Represents the input for EvacuatePV.
+type EvacuatePVRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass  string   `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	PvName       string   `protobuf:"bytes,2,opt,name=pv_name,json=pvName,proto3" json:"pv_name,omitempty"` // The physical volume to move the extents from.
+	Destinations []string `protobuf:"bytes,3,rep,name=destinations,proto3" json:"destinations,omitempty"`   // The physical volumes to move the extents to. Any other physical volume of the volume group if empty.
+}
+
+func (x *EvacuatePVRequest) Reset() {
+	*x = EvacuatePVRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvacuatePVRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvacuatePVRequest) ProtoMessage() {}
+
+func (x *EvacuatePVRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvacuatePVRequest.ProtoReflect.Descriptor instead.
+func (*EvacuatePVRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *EvacuatePVRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *EvacuatePVRequest) GetPvName() string {
+	if x != nil {
+		return x.PvName
+	}
+	return ""
+}
+
+func (x *EvacuatePVRequest) GetDestinations() []string {
+	if x != nil {
+		return x.Destinations
+	}
+	return nil
+}
+
+// Represents the stream output from EvacuatePV.
+type EvacuatePVResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ProgressPercent float64 `protobuf:"fixed64,1,opt,name=progress_percent,json=progressPercent,proto3" json:"progress_percent,omitempty"` // Percentage of the extents moved off the physical volume.
+	Completed       bool    `protobuf:"varint,2,opt,name=completed,proto3" json:"completed,omitempty"`                                     // All extents have been moved off the physical volume.
+}
+
+func (x *EvacuatePVResponse) Reset() {
+	*x = EvacuatePVResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EvacuatePVResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EvacuatePVResponse) ProtoMessage() {}
+
+func (x *EvacuatePVResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EvacuatePVResponse.ProtoReflect.Descriptor instead.
+func (*EvacuatePVResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *EvacuatePVResponse) GetProgressPercent() float64 {
+	if x != nil {
+		return x.ProgressPercent
+	}
+	return 0
+}
+
+func (x *EvacuatePVResponse) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+// Represents the input for ReportThinPoolEvent.
+type ReportThinPoolEventRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VolumeGroup     string  `protobuf:"bytes,1,opt,name=volume_group,json=volumeGroup,proto3" json:"volume_group,omitempty"`
+	ThinPool        string  `protobuf:"bytes,2,opt,name=thin_pool,json=thinPool,proto3" json:"thin_pool,omitempty"`
+	DataPercent     float64 `protobuf:"fixed64,3,opt,name=data_percent,json=dataPercent,proto3" json:"data_percent,omitempty"`             // Data usage of the thin pool in percent.
+	MetadataPercent float64 `protobuf:"fixed64,4,opt,name=metadata_percent,json=metadataPercent,proto3" json:"metadata_percent,omitempty"` // Metadata usage of the thin pool in percent.
+}
+
+func (x *ReportThinPoolEventRequest) Reset() {
+	*x = ReportThinPoolEventRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReportThinPoolEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportThinPoolEventRequest) ProtoMessage() {}
+
+func (x *ReportThinPoolEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportThinPoolEventRequest.ProtoReflect.Descriptor instead.
+func (*ReportThinPoolEventRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ReportThinPoolEventRequest) GetVolumeGroup() string {
+	if x != nil {
+		return x.VolumeGroup
+	}
+	return ""
+}
+
+func (x *ReportThinPoolEventRequest) GetThinPool() string {
+	if x != nil {
+		return x.ThinPool
+	}
+	return ""
+}
+
+func (x *ReportThinPoolEventRequest) GetDataPercent() float64 {
+	if x != nil {
+		return x.DataPercent
+	}
+	return 0
+}
+
+func (x *ReportThinPoolEventRequest) GetMetadataPercent() float64 {
+	if x != nil {
+		return x.MetadataPercent
+	}
+	return 0
+}
+
+// Represents the input for GetThinPoolUsage.
+type GetThinPoolUsageRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"` // The device class of the thin pool.
+}
+
+func (x *GetThinPoolUsageRequest) Reset() {
+	*x = GetThinPoolUsageRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetThinPoolUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThinPoolUsageRequest) ProtoMessage() {}
+
+func (x *GetThinPoolUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThinPoolUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetThinPoolUsageRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetThinPoolUsageRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+// Represents the output from GetThinPoolUsage.
+type GetThinPoolUsageResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	VolumeGroup     string  `protobuf:"bytes,1,opt,name=volume_group,json=volumeGroup,proto3" json:"volume_group,omitempty"`               // The volume group of the thin pool.
+	ThinPool        string  `protobuf:"bytes,2,opt,name=thin_pool,json=thinPool,proto3" json:"thin_pool,omitempty"`                        // The name of the thin pool.
+	DataPercent     float64 `protobuf:"fixed64,3,opt,name=data_percent,json=dataPercent,proto3" json:"data_percent,omitempty"`             // The data usage of the thin pool in percent.
+	MetadataPercent float64 `protobuf:"fixed64,4,opt,name=metadata_percent,json=metadataPercent,proto3" json:"metadata_percent,omitempty"` // The metadata usage of the thin pool in percent.
+	VirtualBytes    uint64  `protobuf:"varint,5,opt,name=virtual_bytes,json=virtualBytes,proto3" json:"virtual_bytes,omitempty"`           // The sum of the virtual sizes of the thin volumes in the pool.
+	SizeBytes       uint64  `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                    // The size of the thin pool in bytes.
+}
+
+func (x *GetThinPoolUsageResponse) Reset() {
+	*x = GetThinPoolUsageResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetThinPoolUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetThinPoolUsageResponse) ProtoMessage() {}
+
+func (x *GetThinPoolUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetThinPoolUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetThinPoolUsageResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetThinPoolUsageResponse) GetVolumeGroup() string {
+	if x != nil {
+		return x.VolumeGroup
+	}
+	return ""
+}
+
+func (x *GetThinPoolUsageResponse) GetThinPool() string {
+	if x != nil {
+		return x.ThinPool
+	}
+	return ""
+}
+
+func (x *GetThinPoolUsageResponse) GetDataPercent() float64 {
+	if x != nil {
+		return x.DataPercent
+	}
+	return 0
+}
+
+func (x *GetThinPoolUsageResponse) GetMetadataPercent() float64 {
+	if x != nil {
+		return x.MetadataPercent
+	}
+	return 0
+}
+
+func (x *GetThinPoolUsageResponse) GetVirtualBytes() uint64 {
+	if x != nil {
+		return x.VirtualBytes
+	}
+	return 0
+}
+
+func (x *GetThinPoolUsageResponse) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+// Represents the output from GetLVMVersion.
+type GetLVMVersionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Version      string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                               // The version of lvm, e.g. 2.03.22.
+	ReportFormat string `protobuf:"bytes,2,opt,name=report_format,json=reportFormat,proto3" json:"report_format,omitempty"` // The report format lvmd requests from lvm, json or json_std.
+}
+
+func (x *GetLVMVersionResponse) Reset() {
+	*x = GetLVMVersionResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLVMVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLVMVersionResponse) ProtoMessage() {}
+
+func (x *GetLVMVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLVMVersionResponse.ProtoReflect.Descriptor instead.
+func (*GetLVMVersionResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *GetLVMVersionResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetLVMVersionResponse) GetReportFormat() string {
+	if x != nil {
+		return x.ReportFormat
+	}
+	return ""
+}
+
+// Represents the optional features of a device class in GetCapabilitiesResponse.
+type DeviceClassCapabilities struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"` // The name of the device class.
+	Type        string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                  // The type of the device class, thick, thin or raw.
+	Snapshot    bool   `protobuf:"varint,3,opt,name=snapshot,proto3" json:"snapshot,omitempty"`                         // Snapshots of the volumes can be taken.
+	Shrink      bool   `protobuf:"varint,4,opt,name=shrink,proto3" json:"shrink,omitempty"`                             // The volumes can be shrunk.
+	Raid        bool   `protobuf:"varint,5,opt,name=raid,proto3" json:"raid,omitempty"`                                 // The volumes are created as RAID volumes.
+	Cache       bool   `protobuf:"varint,6,opt,name=cache,proto3" json:"cache,omitempty"`                               // The volumes are created with a cache.
+	Vdo         bool   `protobuf:"varint,7,opt,name=vdo,proto3" json:"vdo,omitempty"`                                   // The volumes are created as VDO volumes.
+}
+
+func (x *DeviceClassCapabilities) Reset() {
+	*x = DeviceClassCapabilities{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeviceClassCapabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceClassCapabilities) ProtoMessage() {}
+
+func (x *DeviceClassCapabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceClassCapabilities.ProtoReflect.Descriptor instead.
+func (*DeviceClassCapabilities) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *DeviceClassCapabilities) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *DeviceClassCapabilities) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DeviceClassCapabilities) GetSnapshot() bool {
+	if x != nil {
+		return x.Snapshot
+	}
+	return false
+}
+
+func (x *DeviceClassCapabilities) GetShrink() bool {
+	if x != nil {
+		return x.Shrink
+	}
+	return false
+}
+
+func (x *DeviceClassCapabilities) GetRaid() bool {
+	if x != nil {
+		return x.Raid
+	}
+	return false
+}
+
+func (x *DeviceClassCapabilities) GetCache() bool {
+	if x != nil {
+		return x.Cache
+	}
+	return false
+}
+
+func (x *DeviceClassCapabilities) GetVdo() bool {
+	if x != nil {
+		return x.Vdo
+	}
+	return false
+}
+
+// Represents the output from GetCapabilities.
+type GetCapabilitiesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SegmentTypes     []string                   `protobuf:"bytes,1,rep,name=segment_types,json=segmentTypes,proto3" json:"segment_types,omitempty"`              // The segment types supported by lvm, e.g. thin, raid1 or vdo.
+	ThinProvisioning bool                       `protobuf:"varint,2,opt,name=thin_provisioning,json=thinProvisioning,proto3" json:"thin_provisioning,omitempty"` // lvm supports thin pools and thin volumes.
+	Raid             bool                       `protobuf:"varint,3,opt,name=raid,proto3" json:"raid,omitempty"`                                                 // lvm supports RAID volumes.
+	Cache            bool                       `protobuf:"varint,4,opt,name=cache,proto3" json:"cache,omitempty"`                                               // lvm supports cached volumes.
+	Vdo              bool                       `protobuf:"varint,5,opt,name=vdo,proto3" json:"vdo,omitempty"`                                                   // lvm supports VDO volumes.
+	DeviceClasses    []*DeviceClassCapabilities `protobuf:"bytes,6,rep,name=device_classes,json=deviceClasses,proto3" json:"device_classes,omitempty"`           // The features of the device classes sorted by name.
+}
+
+func (x *GetCapabilitiesResponse) Reset() {
+	*x = GetCapabilitiesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCapabilitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCapabilitiesResponse) ProtoMessage() {}
+
+func (x *GetCapabilitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCapabilitiesResponse.ProtoReflect.Descriptor instead.
+func (*GetCapabilitiesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetCapabilitiesResponse) GetSegmentTypes() []string {
+	if x != nil {
+		return x.SegmentTypes
+	}
+	return nil
+}
+
+func (x *GetCapabilitiesResponse) GetThinProvisioning() bool {
+	if x != nil {
+		return x.ThinProvisioning
+	}
+	return false
+}
+
+func (x *GetCapabilitiesResponse) GetRaid() bool {
+	if x != nil {
+		return x.Raid
+	}
+	return false
+}
+
+func (x *GetCapabilitiesResponse) GetCache() bool {
+	if x != nil {
+		return x.Cache
+	}
+	return false
+}
+
+func (x *GetCapabilitiesResponse) GetVdo() bool {
+	if x != nil {
+		return x.Vdo
+	}
+	return false
+}
+
+func (x *GetCapabilitiesResponse) GetDeviceClasses() []*DeviceClassCapabilities {
+	if x != nil {
+		return x.DeviceClasses
+	}
+	return nil
+}
+
+// Represents a device in the lvm devices file.
+type LVMDevice struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Device string `protobuf:"bytes,1,opt,name=device,proto3" json:"device,omitempty"`               // The current path of the device. Empty if the device is not found.
+	IdType string `protobuf:"bytes,2,opt,name=id_type,json=idType,proto3" json:"id_type,omitempty"` // The type of the identifier of the device, e.g. sys_wwid or devname.
+	IdName string `protobuf:"bytes,3,opt,name=id_name,json=idName,proto3" json:"id_name,omitempty"` // The identifier of the device.
+	Pvid   string `protobuf:"bytes,4,opt,name=pvid,proto3" json:"pvid,omitempty"`                   // The UUID of the physical volume on the device. Empty if it is not a physical volume.
+}
+
+func (x *LVMDevice) Reset() {
+	*x = LVMDevice{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LVMDevice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LVMDevice) ProtoMessage() {}
+
+func (x *LVMDevice) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LVMDevice.ProtoReflect.Descriptor instead.
+func (*LVMDevice) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *LVMDevice) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+func (x *LVMDevice) GetIdType() string {
+	if x != nil {
+		return x.IdType
+	}
+	return ""
+}
+
+func (x *LVMDevice) GetIdName() string {
+	if x != nil {
+		return x.IdName
+	}
+	return ""
+}
+
+func (x *LVMDevice) GetPvid() string {
+	if x != nil {
+		return x.Pvid
+	}
+	return ""
+}
+
+// Represents the output from GetLVMDevices and UpdateLVMDevices.
+type GetLVMDevicesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UseDevicesFile bool         `protobuf:"varint,1,opt,name=use_devices_file,json=useDevicesFile,proto3" json:"use_devices_file,omitempty"` // lvm only scans and uses the devices in the devices file.
+	Devices        []*LVMDevice `protobuf:"bytes,2,rep,name=devices,proto3" json:"devices,omitempty"`                                        // The devices in the devices file.
+	GlobalFilter   []string     `protobuf:"bytes,3,rep,name=global_filter,json=globalFilter,proto3" json:"global_filter,omitempty"`          // devices/global_filter of lvm.conf.
+	Filter         []string     `protobuf:"bytes,4,rep,name=filter,proto3" json:"filter,omitempty"`                                          // devices/filter of lvm.conf.
+}
+
+func (x *GetLVMDevicesResponse) Reset() {
+	*x = GetLVMDevicesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetLVMDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLVMDevicesResponse) ProtoMessage() {}
+
+func (x *GetLVMDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLVMDevicesResponse.ProtoReflect.Descriptor instead.
+func (*GetLVMDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *GetLVMDevicesResponse) GetUseDevicesFile() bool {
+	if x != nil {
+		return x.UseDevicesFile
+	}
+	return false
+}
+
+func (x *GetLVMDevicesResponse) GetDevices() []*LVMDevice {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+func (x *GetLVMDevicesResponse) GetGlobalFilter() []string {
+	if x != nil {
+		return x.GlobalFilter
+	}
+	return nil
+}
+
+func (x *GetLVMDevicesResponse) GetFilter() []string {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
+// Represents the input for UpdateLVMDevices.
+type UpdateLVMDevicesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Add    []string `protobuf:"bytes,1,rep,name=add,proto3" json:"add,omitempty"`       // The devices to add to the devices file.
+	Remove []string `protobuf:"bytes,2,rep,name=remove,proto3" json:"remove,omitempty"` // The devices to remove from the devices file.
+}
+
+func (x *UpdateLVMDevicesRequest) Reset() {
+	*x = UpdateLVMDevicesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpdateLVMDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLVMDevicesRequest) ProtoMessage() {}
+
+func (x *UpdateLVMDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLVMDevicesRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLVMDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *UpdateLVMDevicesRequest) GetAdd() []string {
+	if x != nil {
+		return x.Add
+	}
+	return nil
+}
+
+func (x *UpdateLVMDevicesRequest) GetRemove() []string {
+	if x != nil {
+		return x.Remove
+	}
+	return nil
+}
+
+// Represents the input for SetDeviceClassDraining.
+type SetDeviceClassDrainingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	Draining    bool   `protobuf:"varint,2,opt,name=draining,proto3" json:"draining,omitempty"` // Reject new logical volumes in the device class if true, or accept them again if false.
+}
+
+func (x *SetDeviceClassDrainingRequest) Reset() {
+	*x = SetDeviceClassDrainingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SetDeviceClassDrainingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDeviceClassDrainingRequest) ProtoMessage() {}
+
+func (x *SetDeviceClassDrainingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDeviceClassDrainingRequest.ProtoReflect.Descriptor instead.
+func (*SetDeviceClassDrainingRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *SetDeviceClassDrainingRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *SetDeviceClassDrainingRequest) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+// Represents the stream output from Watch.
+type WatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FreeBytes             uint64                     `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space of the default volume group in bytes. In the case of thin pools, free space on the thinpool with overprovision in bytes.
+	Items                 []*WatchItem               `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	LvcreateOptionClasses []*LvcreateOptionClassItem `protobuf:"bytes,3,rep,name=lvcreate_option_classes,json=lvcreateOptionClasses,proto3" json:"lvcreate_option_classes,omitempty"` // The lvcreate-option-classes allowing inline options.
+}
+
+func (x *WatchResponse) Reset() {
+	*x = WatchResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchResponse) ProtoMessage() {}
+
+func (x *WatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchResponse.ProtoReflect.Descriptor instead.
+func (*WatchResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *WatchResponse) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *WatchResponse) GetItems() []*WatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetLvcreateOptionClasses() []*LvcreateOptionClassItem {
+	if x != nil {
+		return x.LvcreateOptionClasses
+	}
+	return nil
+}
+
+// Represents the input for WatchLVs.
+type WatchLVsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DeviceClass string `protobuf:"bytes,1,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"` // The device class to watch the logical volumes of. All device classes are watched if empty.
+}
+
+func (x *WatchLVsRequest) Reset() {
+	*x = WatchLVsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLVsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLVsRequest) ProtoMessage() {}
+
+func (x *WatchLVsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLVsRequest.ProtoReflect.Descriptor instead.
+func (*WatchLVsRequest) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *WatchLVsRequest) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+// Represents a change of a logical volume.
+type LVEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type        string         `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`                                  // "created", "resized" or "deleted".
+	DeviceClass string         `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"` // The device class of the logical volume.
+	Volume      *LogicalVolume `protobuf:"bytes,3,opt,name=volume,proto3" json:"volume,omitempty"`                              // The logical volume after the change, or before the deletion.
+}
+
+func (x *LVEvent) Reset() {
+	*x = LVEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LVEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LVEvent) ProtoMessage() {}
+
+func (x *LVEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LVEvent.ProtoReflect.Descriptor instead.
+func (*LVEvent) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *LVEvent) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *LVEvent) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *LVEvent) GetVolume() *LogicalVolume {
+	if x != nil {
+		return x.Volume
+	}
+	return nil
+}
+
+// Represents the stream output from WatchLVs.
+type WatchLVsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Initial bool       `protobuf:"varint,1,opt,name=initial,proto3" json:"initial,omitempty"` // Set for the first response, whose events create all the existing logical volumes.
+	Events  []*LVEvent `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`    // The changes since the previous response.
+}
+
+func (x *WatchLVsResponse) Reset() {
+	*x = WatchLVsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[47]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchLVsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchLVsResponse) ProtoMessage() {}
+
+func (x *WatchLVsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[47]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchLVsResponse.ProtoReflect.Descriptor instead.
+func (*WatchLVsResponse) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *WatchLVsResponse) GetInitial() bool {
+	if x != nil {
+		return x.Initial
+	}
+	return false
+}
+
+func (x *WatchLVsResponse) GetEvents() []*LVEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// Represents the inline options allowed by a lvcreate-option-class.
+type LvcreateOptionClassItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name          string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                        // The name of the lvcreate-option-class.
+	InlineOptions []string `protobuf:"bytes,2,rep,name=inline_options,json=inlineOptions,proto3" json:"inline_options,omitempty"` // Prefixes of the inline lvcreate options allowed by the class.
+}
+
+func (x *LvcreateOptionClassItem) Reset() {
+	*x = LvcreateOptionClassItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[48]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LvcreateOptionClassItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LvcreateOptionClassItem) ProtoMessage() {}
+
+func (x *LvcreateOptionClassItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[48]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LvcreateOptionClassItem.ProtoReflect.Descriptor instead.
+func (*LvcreateOptionClassItem) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *LvcreateOptionClassItem) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LvcreateOptionClassItem) GetInlineOptions() []string {
+	if x != nil {
+		return x.InlineOptions
+	}
+	return nil
+}
+
+// Represents the details of thinpool.
+type ThinPoolItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	DataPercent        float64 `protobuf:"fixed64,1,opt,name=data_percent,json=dataPercent,proto3" json:"data_percent,omitempty"`                     // Data percent occupied on the thinpool, used for monitoring.
+	MetadataPercent    float64 `protobuf:"fixed64,2,opt,name=metadata_percent,json=metadataPercent,proto3" json:"metadata_percent,omitempty"`         // Metadata percent occupied on the thinpool, used for monitoring.
+	OverprovisionBytes uint64  `protobuf:"varint,3,opt,name=overprovision_bytes,json=overprovisionBytes,proto3" json:"overprovision_bytes,omitempty"` // Free space on the thinpool with overprovision, used for annotating node.
+	SizeBytes          uint64  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                            // Physical data space size of the thinpool.
+}
+
+func (x *ThinPoolItem) Reset() {
+	*x = ThinPoolItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[49]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ThinPoolItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ThinPoolItem) ProtoMessage() {}
+
+func (x *ThinPoolItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[49]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ThinPoolItem.ProtoReflect.Descriptor instead.
+func (*ThinPoolItem) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ThinPoolItem) GetDataPercent() float64 {
+	if x != nil {
+		return x.DataPercent
+	}
+	return 0
+}
+
+func (x *ThinPoolItem) GetMetadataPercent() float64 {
+	if x != nil {
+		return x.MetadataPercent
+	}
+	return 0
+}
+
+func (x *ThinPoolItem) GetOverprovisionBytes() uint64 {
+	if x != nil {
+		return x.OverprovisionBytes
+	}
+	return 0
+}
+
+func (x *ThinPoolItem) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+// Represents the dm-cache statistics summed up over the cached volumes of a device class.
+type CacheItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Volumes     uint32 `protobuf:"varint,1,opt,name=volumes,proto3" json:"volumes,omitempty"`                            // Number of cached volumes.
+	TotalBlocks uint64 `protobuf:"varint,2,opt,name=total_blocks,json=totalBlocks,proto3" json:"total_blocks,omitempty"` // Total number of cache blocks.
+	UsedBlocks  uint64 `protobuf:"varint,3,opt,name=used_blocks,json=usedBlocks,proto3" json:"used_blocks,omitempty"`    // Number of used cache blocks.
+	DirtyBlocks uint64 `protobuf:"varint,4,opt,name=dirty_blocks,json=dirtyBlocks,proto3" json:"dirty_blocks,omitempty"` // Number of dirty cache blocks, which are not yet written back.
+	ReadHits    uint64 `protobuf:"varint,5,opt,name=read_hits,json=readHits,proto3" json:"read_hits,omitempty"`          // Number of read hits.
+	ReadMisses  uint64 `protobuf:"varint,6,opt,name=read_misses,json=readMisses,proto3" json:"read_misses,omitempty"`    // Number of read misses.
+	WriteHits   uint64 `protobuf:"varint,7,opt,name=write_hits,json=writeHits,proto3" json:"write_hits,omitempty"`       // Number of write hits.
+	WriteMisses uint64 `protobuf:"varint,8,opt,name=write_misses,json=writeMisses,proto3" json:"write_misses,omitempty"` // Number of write misses.
+}
+
+func (x *CacheItem) Reset() {
+	*x = CacheItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[50]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheItem) ProtoMessage() {}
+
+func (x *CacheItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[50]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheItem.ProtoReflect.Descriptor instead.
+func (*CacheItem) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *CacheItem) GetVolumes() uint32 {
+	if x != nil {
+		return x.Volumes
+	}
+	return 0
+}
+
+func (x *CacheItem) GetTotalBlocks() uint64 {
+	if x != nil {
+		return x.TotalBlocks
+	}
+	return 0
+}
+
+func (x *CacheItem) GetUsedBlocks() uint64 {
+	if x != nil {
+		return x.UsedBlocks
+	}
+	return 0
+}
+
+func (x *CacheItem) GetDirtyBlocks() uint64 {
+	if x != nil {
+		return x.DirtyBlocks
+	}
+	return 0
+}
+
+func (x *CacheItem) GetReadHits() uint64 {
+	if x != nil {
+		return x.ReadHits
+	}
+	return 0
+}
+
+func (x *CacheItem) GetReadMisses() uint64 {
+	if x != nil {
+		return x.ReadMisses
+	}
+	return 0
+}
+
+func (x *CacheItem) GetWriteHits() uint64 {
+	if x != nil {
+		return x.WriteHits
+	}
+	return 0
+}
+
+func (x *CacheItem) GetWriteMisses() uint64 {
+	if x != nil {
+		return x.WriteMisses
+	}
+	return 0
+}
+
+// Represents the space saving of the VDO volumes of a device class.
+type VDOItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Volumes       uint32  `protobuf:"varint,1,opt,name=volumes,proto3" json:"volumes,omitempty"`                                   // Number of VDO volumes.
+	SavingPercent float64 `protobuf:"fixed64,2,opt,name=saving_percent,json=savingPercent,proto3" json:"saving_percent,omitempty"` // Space saving in percent averaged over the VDO volumes weighted by their size.
+}
+
+func (x *VDOItem) Reset() {
+	*x = VDOItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[51]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VDOItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VDOItem) ProtoMessage() {}
+
+func (x *VDOItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[51]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VDOItem.ProtoReflect.Descriptor instead.
+func (*VDOItem) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *VDOItem) GetVolumes() uint32 {
+	if x != nil {
+		return x.Volumes
+	}
+	return 0
+}
+
+func (x *VDOItem) GetSavingPercent() float64 {
+	if x != nil {
+		return x.SavingPercent
+	}
+	return 0
+}
+
+// Represents the response corresponding to device class targets.
+type WatchItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	FreeBytes   uint64        `protobuf:"varint,1,opt,name=free_bytes,json=freeBytes,proto3" json:"free_bytes,omitempty"` // Free space in the volume group in bytes.
+	DeviceClass string        `protobuf:"bytes,2,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	SizeBytes   uint64        `protobuf:"varint,3,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"` // Size of volume group in bytes.
+	ThinPool    *ThinPoolItem `protobuf:"bytes,4,opt,name=thin_pool,json=thinPool,proto3" json:"thin_pool,omitempty"`
+	Cache       *CacheItem    `protobuf:"bytes,5,opt,name=cache,proto3" json:"cache,omitempty"`      // Only set for device classes with cache.
+	Vdo         *VDOItem      `protobuf:"bytes,6,opt,name=vdo,proto3" json:"vdo,omitempty"`          // Only set for device classes with VDO.
+	Default     bool          `protobuf:"varint,7,opt,name=default,proto3" json:"default,omitempty"` // Set for the default device class.
+	// Only set for device classes backed by multiple volume groups: the usable free space of the volume group with the most of it in bytes, which is the largest volume that can be created. free_bytes is the sum over the volume groups.
+	LargestFreeBytes uint64 `protobuf:"varint,8,opt,name=largest_free_bytes,json=largestFreeBytes,proto3" json:"largest_free_bytes,omitempty"`
+}
+
+func (x *WatchItem) Reset() {
+	*x = WatchItem{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[52]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchItem) ProtoMessage() {}
+
+func (x *WatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_pkg_lvmd_proto_lvmd_proto_msgTypes[52]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchItem.ProtoReflect.Descriptor instead.
+func (*WatchItem) Descriptor() ([]byte, []int) {
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *WatchItem) GetFreeBytes() uint64 {
+	if x != nil {
+		return x.FreeBytes
+	}
+	return 0
+}
+
+func (x *WatchItem) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+func (x *WatchItem) GetSizeBytes() uint64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *WatchItem) GetThinPool() *ThinPoolItem {
+	if x != nil {
+		return x.ThinPool
+	}
+	return nil
+}
+
+func (x *WatchItem) GetCache() *CacheItem {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+func (x *WatchItem) GetVdo() *VDOItem {
+	if x != nil {
+		return x.Vdo
+	}
+	return nil
+}
+
+func (x *WatchItem) GetDefault() bool {
+	if x != nil {
+		return x.Default
+	}
+	return false
+}
+
+func (x *WatchItem) GetLargestFreeBytes() uint64 {
+	if x != nil {
+		return x.LargestFreeBytes
+	}
+	return 0
+}
+
+var File_pkg_lvmd_proto_lvmd_proto protoreflect.FileDescriptor
+
+var file_pkg_lvmd_proto_lvmd_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x70, 0x6b, 0x67, 0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x22, 0x07, 0x0a, 0x05, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0xa2, 0x03, 0x0a, 0x0d,
+	0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67, 0x62, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a, 0x65, 0x47, 0x62, 0x12, 0x1b,
+	0x0a, 0x09, 0x64, 0x65, 0x76, 0x5f, 0x6d, 0x61, 0x6a, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x08, 0x64, 0x65, 0x76, 0x4d, 0x61, 0x6a, 0x6f, 0x72, 0x12, 0x1b, 0x0a, 0x09, 0x64,
+	0x65, 0x76, 0x5f, 0x6d, 0x69, 0x6e, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08,
+	0x64, 0x65, 0x76, 0x4d, 0x69, 0x6e, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x18, 0x05, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x61,
+	0x74, 0x74, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x61, 0x74, 0x74, 0x72, 0x12,
+	0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x70, 0x79, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18,
+	0x08, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x63, 0x6f, 0x70, 0x79, 0x50, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x76, 0x64, 0x6f, 0x5f, 0x73, 0x61, 0x76, 0x69, 0x6e, 0x67,
+	0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x01, 0x52, 0x10,
+	0x76, 0x64, 0x6f, 0x53, 0x61, 0x76, 0x69, 0x6e, 0x67, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c, 0x6c, 0x6f, 0x63, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x63,
+	0x61, 0x74, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x68, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x2a, 0x0a, 0x11,
+	0x70, 0x6f, 0x6f, 0x6c, 0x5f, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x70, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61,
+	0x6c, 0x74, 0x68, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x22, 0x3b, 0x0a, 0x07, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61,
+	0x73, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xbe, 0x01,
+	0x0a, 0x11, 0x49, 0x6e, 0x73, 0x75, 0x66, 0x66, 0x69, 0x63, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x70,
+	0x61, 0x63, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c,
+	0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x6f,
+	0x6c, 0x75, 0x6d, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x68, 0x69,
+	0x6e, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x68,
+	0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x27, 0x0a, 0x0f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x65, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0e, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0xa4,
+	0x02, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67,
+	0x62, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a,
+	0x65, 0x47, 0x62, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x32, 0x0a, 0x15, 0x6c, 0x76,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6c,
+	0x61, 0x73, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x13, 0x6c, 0x76, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x2b, 0x0a,
+	0x11, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73,
+	0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x54, 0x79, 0x70, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6c, 0x76,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0f, 0x6c, 0x76, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x6c, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c,
+	0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2c, 0x0a, 0x06, 0x76, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52,
+	0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69,
+	0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69,
+	0x6e, 0x67, 0x73, 0x22, 0x6e, 0x0a, 0x0f, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x24, 0x0a,
+	0x0e, 0x77, 0x69, 0x70, 0x65, 0x5f, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x77, 0x69, 0x70, 0x65, 0x4f, 0x6e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x22, 0xe6, 0x01, 0x0a, 0x17, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x23, 0x0a, 0x0d, 0x73, 0x6f,
+	0x75, 0x72, 0x63, 0x65, 0x5f, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x12,
+	0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67, 0x62, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04,
+	0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a, 0x65, 0x47, 0x62, 0x12, 0x1f, 0x0a, 0x0b,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x78, 0x0a, 0x18,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x2a, 0x0a, 0x08, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61,
+	0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73, 0x22, 0x88, 0x01, 0x0a, 0x13, 0x43, 0x68, 0x61, 0x6e, 0x67,
+	0x65, 0x4c, 0x56, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61,
+	0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x61, 0x64, 0x64, 0x5f, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x61, 0x64, 0x64, 0x54, 0x61, 0x67, 0x73,
+	0x12, 0x1f, 0x0a, 0x0b, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x5f, 0x74, 0x61, 0x67, 0x73, 0x18,
+	0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x54, 0x61, 0x67,
+	0x73, 0x22, 0x2a, 0x0a, 0x14, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x56, 0x54, 0x61, 0x67,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x4a, 0x0a,
+	0x11, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x42, 0x0a, 0x12, 0x41, 0x63, 0x74,
+	0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x2c, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x06, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x22, 0x4c, 0x0a,
+	0x13, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x7a, 0x0a, 0x20, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61,
+	0x73, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x75, 0x66, 0x66, 0x69,
+	0x78, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6e, 0x61, 0x6d, 0x65, 0x53, 0x75, 0x66,
+	0x66, 0x69, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0xe4, 0x01, 0x0a, 0x21, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x55, 0x0a,
+	0x09, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x37, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x09, 0x73, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x73, 0x12, 0x2a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x1a, 0x3c, 0x0a, 0x0e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x4f,
+	0x0a, 0x16, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c,
+	0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22,
+	0x8e, 0x01, 0x0a, 0x17, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63,
+	0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x65, 0x72,
+	0x63, 0x65, 0x6e, 0x74, 0x12, 0x2a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57,
+	0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x73,
+	0x22, 0x9c, 0x01, 0x0a, 0x0d, 0x4d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x74, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x5f, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x24, 0x0a, 0x0e, 0x77, 0x69, 0x70,
+	0x65, 0x5f, 0x6f, 0x6e, 0x5f, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0c, 0x77, 0x69, 0x70, 0x65, 0x4f, 0x6e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x22,
+	0x59, 0x0a, 0x0e, 0x4d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x12,
+	0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x63,
+	0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x70, 0x72, 0x6f, 0x67, 0x72,
+	0x65, 0x73, 0x73, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0xa7, 0x01, 0x0a, 0x0f, 0x52,
+	0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61,
+	0x6d, 0x65, 0x12, 0x1b, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x67, 0x62, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x42, 0x02, 0x18, 0x01, 0x52, 0x06, 0x73, 0x69, 0x7a, 0x65, 0x47, 0x62, 0x12,
+	0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x07, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x73, 0x68, 0x72, 0x69, 0x6e,
+	0x6b, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x53, 0x68,
+	0x72, 0x69, 0x6e, 0x6b, 0x22, 0x3e, 0x0a, 0x10, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x08, 0x77, 0x61, 0x72, 0x6e,
+	0x69, 0x6e, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x57, 0x61, 0x72, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x08, 0x77, 0x61, 0x72, 0x6e,
+	0x69, 0x6e, 0x67, 0x73, 0x22, 0x43, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x76, 0x6f, 0x6c,
+	0x75, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65,
+	0x52, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x22, 0x35, 0x0a, 0x14, 0x47, 0x65, 0x74,
+	0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x22, 0x35, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x51, 0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61,
+	0x73, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x22, 0x4b, 0x0a, 0x15, 0x4c, 0x69,
+	0x73, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c,
+	0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x09, 0x73, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x22, 0x5d, 0x0a, 0x13, 0x47, 0x65, 0x74, 0x46, 0x72,
+	0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x12, 0x23, 0x0a, 0x0d, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x5f, 0x72, 0x65, 0x66, 0x72, 0x65,
+	0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x66, 0x6f, 0x72, 0x63, 0x65, 0x52,
+	0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x22, 0x44, 0x0a, 0x0f, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x56, 0x47, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x76, 0x67, 0x5f,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x76, 0x67, 0x4e, 0x61,
+	0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x2a, 0x0a, 0x0f,
+	0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x56, 0x47, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x17, 0x0a, 0x07, 0x76, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x76, 0x67, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x4e, 0x0a, 0x0f, 0x45, 0x78, 0x74, 0x65,
+	0x6e, 0x64, 0x56, 0x47, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x4e, 0x0a, 0x0f, 0x52, 0x65, 0x64, 0x75,
+	0x63, 0x65, 0x56, 0x47, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x18,
+	0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x22, 0x73, 0x0a, 0x11, 0x45, 0x76, 0x61, 0x63,
+	0x75, 0x61, 0x74, 0x65, 0x50, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73,
+	0x12, 0x17, 0x0a, 0x07, 0x70, 0x76, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x70, 0x76, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x64, 0x65, 0x73,
+	0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0c, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x5d, 0x0a,
+	0x12, 0x45, 0x76, 0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x50, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f,
+	0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x70,
+	0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x1c,
+	0x0a, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x70, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x22, 0xaa, 0x01, 0x0a,
+	0x1a, 0x52, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1b,
+	0x0a, 0x09, 0x74, 0x68, 0x69, 0x6e, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x29,
+	0x0a, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0x3c, 0x0a, 0x17, 0x47, 0x65, 0x74,
+	0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63,
+	0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0xec, 0x01, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x54,
+	0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x5f, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x68, 0x69, 0x6e, 0x5f,
+	0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e,
+	0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72,
+	0x63, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61,
+	0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0c, 0x76, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x69, 0x7a,
+	0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x56, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4d,
+	0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x65, 0x70,
+	0x6f, 0x72, 0x74, 0x5f, 0x66, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x6d, 0x61, 0x74, 0x22, 0xc0,
+	0x01, 0x0a, 0x17, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x43, 0x61,
+	0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x12, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x08, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x73, 0x68, 0x72, 0x69, 0x6e, 0x6b, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x73,
+	0x68, 0x72, 0x69, 0x6e, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x72, 0x61, 0x69, 0x64, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x04, 0x72, 0x61, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x63,
+	0x68, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x12,
+	0x10, 0x0a, 0x03, 0x76, 0x64, 0x6f, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x76, 0x64,
+	0x6f, 0x22, 0xee, 0x01, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x23, 0x0a,
+	0x0d, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x73, 0x65, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x73, 0x12, 0x2b, 0x0a, 0x11, 0x74, 0x68, 0x69, 0x6e, 0x5f, 0x70, 0x72, 0x6f, 0x76, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x10, 0x74,
+	0x68, 0x69, 0x6e, 0x50, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x69, 0x6e, 0x67, 0x12,
+	0x12, 0x0a, 0x04, 0x72, 0x61, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x72,
+	0x61, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x76, 0x64, 0x6f,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x76, 0x64, 0x6f, 0x12, 0x45, 0x0a, 0x0e, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73, 0x18, 0x06, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74,
+	0x69, 0x65, 0x73, 0x52, 0x0d, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73,
+	0x65, 0x73, 0x22, 0x69, 0x0a, 0x09, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x17, 0x0a, 0x07, 0x69, 0x64, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x64, 0x54, 0x79, 0x70, 0x65,
+	0x12, 0x17, 0x0a, 0x07, 0x69, 0x64, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x69, 0x64, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x76, 0x69,
+	0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x76, 0x69, 0x64, 0x22, 0xaa, 0x01,
+	0x0a, 0x15, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28, 0x0a, 0x10, 0x75, 0x73, 0x65, 0x5f, 0x64,
+	0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x5f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0e, 0x75, 0x73, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x46, 0x69, 0x6c,
+	0x65, 0x12, 0x2a, 0x0a, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x56, 0x4d, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x52, 0x07, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x23, 0x0a,
+	0x0d, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x67, 0x6c, 0x6f, 0x62, 0x61, 0x6c, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x43, 0x0a, 0x17, 0x55, 0x70,
+	0x64, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x64, 0x64, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x03, 0x61, 0x64, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76,
+	0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x22,
+	0x5e, 0x0a, 0x1d, 0x53, 0x65, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73,
+	0x73, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x22,
+	0xae, 0x01, 0x0a, 0x0d, 0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72, 0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x66, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73,
+	0x12, 0x26, 0x0a, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x10, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65,
+	0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x12, 0x56, 0x0a, 0x17, 0x6c, 0x76, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6c, 0x61, 0x73,
+	0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x4c, 0x76, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x6c, 0x61, 0x73, 0x73, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x15, 0x6c, 0x76, 0x63, 0x72, 0x65,
+	0x61, 0x74, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x65, 0x73,
+	0x22, 0x34, 0x0a, 0x0f, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x56, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c,
+	0x61, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x22, 0x6e, 0x0a, 0x07, 0x4c, 0x56, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f,
+	0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x2c, 0x0a, 0x06, 0x76, 0x6f, 0x6c, 0x75,
+	0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x4c, 0x6f, 0x67, 0x69, 0x63, 0x61, 0x6c, 0x56, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x52, 0x06,
+	0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x22, 0x54, 0x0a, 0x10, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c,
+	0x56, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x69, 0x6e,
+	0x69, 0x74, 0x69, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x69, 0x6e, 0x69,
+	0x74, 0x69, 0x61, 0x6c, 0x12, 0x26, 0x0a, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x56, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x52, 0x06, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x22, 0x54, 0x0a, 0x17,
+	0x4c, 0x76, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6c,
+	0x61, 0x73, 0x73, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x69,
+	0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x5f, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0d, 0x69, 0x6e, 0x6c, 0x69, 0x6e, 0x65, 0x4f, 0x70, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x22, 0xac, 0x01, 0x0a, 0x0c, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x49,
+	0x74, 0x65, 0x6d, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63,
+	0x65, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x50,
+	0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
+	0x74, 0x61, 0x5f, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x0f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x12, 0x2f, 0x0a, 0x13, 0x6f, 0x76, 0x65, 0x72, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x12,
+	0x6f, 0x76, 0x65, 0x72, 0x70, 0x72, 0x6f, 0x76, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x22, 0x8c, 0x02, 0x0a, 0x09, 0x43, 0x61, 0x63, 0x68, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x12,
+	0x18, 0x0a, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x76, 0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x1f, 0x0a, 0x0b,
+	0x75, 0x73, 0x65, 0x64, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x64, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x12, 0x21, 0x0a,
+	0x0c, 0x64, 0x69, 0x72, 0x74, 0x79, 0x5f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0b, 0x64, 0x69, 0x72, 0x74, 0x79, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x73,
+	0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x68, 0x69, 0x74, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x48, 0x69, 0x74, 0x73, 0x12, 0x1f, 0x0a,
+	0x0b, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x0a, 0x72, 0x65, 0x61, 0x64, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x12, 0x1d,
+	0x0a, 0x0a, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x68, 0x69, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x04, 0x52, 0x09, 0x77, 0x72, 0x69, 0x74, 0x65, 0x48, 0x69, 0x74, 0x73, 0x12, 0x21, 0x0a,
+	0x0c, 0x77, 0x72, 0x69, 0x74, 0x65, 0x5f, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x08, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x0b, 0x77, 0x72, 0x69, 0x74, 0x65, 0x4d, 0x69, 0x73, 0x73, 0x65, 0x73,
+	0x22, 0x4a, 0x0a, 0x07, 0x56, 0x44, 0x4f, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x18, 0x0a, 0x07, 0x76,
+	0x6f, 0x6c, 0x75, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x76, 0x6f,
+	0x6c, 0x75, 0x6d, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x61, 0x76, 0x69, 0x6e, 0x67, 0x5f,
+	0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x73,
+	0x61, 0x76, 0x69, 0x6e, 0x67, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x22, 0xb0, 0x02, 0x0a,
+	0x09, 0x57, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1d, 0x0a, 0x0a, 0x66, 0x72,
+	0x65, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09,
+	0x66, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x5f, 0x63, 0x6c, 0x61, 0x73, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
+	0x73, 0x69, 0x7a, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x09, 0x73, 0x69, 0x7a, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x30, 0x0a, 0x09, 0x74,
+	0x68, 0x69, 0x6e, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x49,
+	0x74, 0x65, 0x6d, 0x52, 0x08, 0x74, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x12, 0x26, 0x0a,
+	0x05, 0x63, 0x61, 0x63, 0x68, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x49, 0x74, 0x65, 0x6d, 0x52, 0x05,
+	0x63, 0x61, 0x63, 0x68, 0x65, 0x12, 0x20, 0x0a, 0x03, 0x76, 0x64, 0x6f, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x56, 0x44, 0x4f, 0x49, 0x74,
+	0x65, 0x6d, 0x52, 0x03, 0x76, 0x64, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c,
+	0x74, 0x12, 0x2c, 0x0a, 0x12, 0x6c, 0x61, 0x72, 0x67, 0x65, 0x73, 0x74, 0x5f, 0x66, 0x72, 0x65,
+	0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x10, 0x6c,
+	0x61, 0x72, 0x67, 0x65, 0x73, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x32,
+	0xcb, 0x05, 0x0a, 0x09, 0x4c, 0x56, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3b, 0x0a,
+	0x08, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x4c, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65,
+	0x6d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52,
+	0x65, 0x6d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3b, 0x0a, 0x08,
+	0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x73, 0x69, 0x7a, 0x65, 0x4c,
+	0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0c, 0x43, 0x68, 0x61,
+	0x6e, 0x67, 0x65, 0x4c, 0x56, 0x54, 0x61, 0x67, 0x73, 0x12, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x4c, 0x56, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x4c, 0x56, 0x54, 0x61, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x41, 0x0a, 0x0a, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56,
+	0x12, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x38, 0x0a, 0x0c, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76,
+	0x61, 0x74, 0x65, 0x4c, 0x56, 0x12, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x44, 0x65,
+	0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12,
+	0x53, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x0f, 0x4d, 0x65, 0x72, 0x67, 0x65, 0x4c, 0x56, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x1d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x4d, 0x65, 0x72, 0x67, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d,
+	0x65, 0x72, 0x67, 0x65, 0x4c, 0x56, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x6e, 0x0a, 0x19, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x12, 0x27, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x06, 0x4d, 0x6f, 0x76, 0x65, 0x4c, 0x56,
+	0x12, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d, 0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x15, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4d,
+	0x6f, 0x76, 0x65, 0x4c, 0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xd3, 0x08,
+	0x0a, 0x09, 0x56, 0x47, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x3e, 0x0a, 0x09, 0x47,
+	0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4a, 0x0a, 0x0d, 0x4c,
+	0x69, 0x73, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x1b, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x47, 0x0a, 0x0c, 0x47, 0x65, 0x74, 0x46, 0x72,
+	0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x1a, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x65, 0x74, 0x46, 0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65, 0x74, 0x46,
+	0x72, 0x65, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x2d, 0x0a, 0x05, 0x57, 0x61, 0x74, 0x63, 0x68, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x14, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x57, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12,
+	0x3d, 0x0a, 0x08, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x56, 0x73, 0x12, 0x16, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x4c, 0x56, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x57, 0x61, 0x74, 0x63,
+	0x68, 0x4c, 0x56, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x30,
+	0x0a, 0x08, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x56, 0x47, 0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x56, 0x47, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x30, 0x0a, 0x08, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x56, 0x47, 0x12, 0x16, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x56, 0x47, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d, 0x70,
+	0x74, 0x79, 0x12, 0x30, 0x0a, 0x08, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x56, 0x47, 0x12, 0x16,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x78, 0x74, 0x65, 0x6e, 0x64, 0x56, 0x47, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45,
+	0x6d, 0x70, 0x74, 0x79, 0x12, 0x30, 0x0a, 0x08, 0x52, 0x65, 0x64, 0x75, 0x63, 0x65, 0x56, 0x47,
+	0x12, 0x16, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x64, 0x75, 0x63, 0x65, 0x56,
+	0x47, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x43, 0x0a, 0x0a, 0x45, 0x76, 0x61, 0x63, 0x75, 0x61,
+	0x74, 0x65, 0x50, 0x56, 0x12, 0x18, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x76, 0x61,
+	0x63, 0x75, 0x61, 0x74, 0x65, 0x50, 0x56, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x76, 0x61, 0x63, 0x75, 0x61, 0x74, 0x65, 0x50,
+	0x56, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x46, 0x0a, 0x13, 0x52,
+	0x65, 0x70, 0x6f, 0x72, 0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x12, 0x21, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x52, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x53, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f,
+	0x6f, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x65, 0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x65, 0x74, 0x54, 0x68, 0x69, 0x6e, 0x50, 0x6f, 0x6f, 0x6c, 0x55, 0x73, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4c,
+	0x56, 0x4d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x65, 0x74, 0x4c, 0x56, 0x4d, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3f, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x43, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47,
+	0x65, 0x74, 0x43, 0x61, 0x70, 0x61, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x4c, 0x56, 0x4d,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x1c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x47, 0x65,
+	0x74, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x50, 0x0a, 0x10, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x4d,
+	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x1e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e,
+	0x47, 0x65, 0x74, 0x4c, 0x56, 0x4d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x16, 0x53, 0x65, 0x74, 0x44, 0x65, 0x76, 0x69,
+	0x63, 0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x12,
+	0x24, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x53, 0x65, 0x74, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x43, 0x6c, 0x61, 0x73, 0x73, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x42, 0x2b, 0x5a, 0x29, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
+	0x6d, 0x2f, 0x74, 0x6f, 0x70, 0x6f, 0x6c, 0x76, 0x6d, 0x2f, 0x74, 0x6f, 0x70, 0x6f, 0x6c, 0x76,
+	0x6d, 0x2f, 0x70, 0x6b, 0x67, 0x2f, 0x6c, 0x76, 0x6d, 0x64, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_pkg_lvmd_proto_lvmd_proto_rawDescOnce sync.Once
+	file_pkg_lvmd_proto_lvmd_proto_rawDescData = file_pkg_lvmd_proto_lvmd_proto_rawDesc
+)
+
+func file_pkg_lvmd_proto_lvmd_proto_rawDescGZIP() []byte {
+	file_pkg_lvmd_proto_lvmd_proto_rawDescOnce.Do(func() {
+		file_pkg_lvmd_proto_lvmd_proto_rawDescData = protoimpl.X.CompressGZIP(file_pkg_lvmd_proto_lvmd_proto_rawDescData)
+	})
+	return file_pkg_lvmd_proto_lvmd_proto_rawDescData
+}
+
+var file_pkg_lvmd_proto_lvmd_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
+var file_pkg_lvmd_proto_lvmd_proto_goTypes = []interface{}{
+	(*Empty)(nil),                             // 0: proto.Empty
+	(*LogicalVolume)(nil),                     // 1: proto.LogicalVolume
+	(*Warning)(nil),                           // 2: proto.Warning
+	(*InsufficientSpace)(nil),                 // 3: proto.InsufficientSpace
+	(*CreateLVRequest)(nil),                   // 4: proto.CreateLVRequest
+	(*CreateLVResponse)(nil),                  // 5: proto.CreateLVResponse
+	(*RemoveLVRequest)(nil),                   // 6: proto.RemoveLVRequest
+	(*CreateLVSnapshotRequest)(nil),           // 7: proto.CreateLVSnapshotRequest
+	(*CreateLVSnapshotResponse)(nil),          // 8: proto.CreateLVSnapshotResponse
+	(*ChangeLVTagsRequest)(nil),               // 9: proto.ChangeLVTagsRequest
+	(*ChangeLVTagsResponse)(nil),              // 10: proto.ChangeLVTagsResponse
+	(*ActivateLVRequest)(nil),                 // 11: proto.ActivateLVRequest
+	(*ActivateLVResponse)(nil),                // 12: proto.ActivateLVResponse
+	(*DeactivateLVRequest)(nil),               // 13: proto.DeactivateLVRequest
+	(*CreateDeviceClassSnapshotRequest)(nil),  // 14: proto.CreateDeviceClassSnapshotRequest
+	(*CreateDeviceClassSnapshotResponse)(nil), // 15: proto.CreateDeviceClassSnapshotResponse
+	(*MergeLVSnapshotRequest)(nil),            // 16: proto.MergeLVSnapshotRequest
+	(*MergeLVSnapshotResponse)(nil),           // 17: proto.MergeLVSnapshotResponse
+	(*MoveLVRequest)(nil),                     // 18: proto.MoveLVRequest
+	(*MoveLVResponse)(nil),                    // 19: proto.MoveLVResponse
+	(*ResizeLVRequest)(nil),                   // 20: proto.ResizeLVRequest
+	(*ResizeLVResponse)(nil),                  // 21: proto.ResizeLVResponse
+	(*GetLVListResponse)(nil),                 // 22: proto.GetLVListResponse
+	(*GetFreeBytesResponse)(nil),              // 23: proto.GetFreeBytesResponse
+	(*GetLVListRequest)(nil),                  // 24: proto.GetLVListRequest
+	(*ListSnapshotsRequest)(nil),              // 25: proto.ListSnapshotsRequest
+	(*ListSnapshotsResponse)(nil),             // 26: proto.ListSnapshotsResponse
+	(*GetFreeBytesRequest)(nil),               // 27: proto.GetFreeBytesRequest
+	(*CreateVGRequest)(nil),                   // 28: proto.CreateVGRequest
+	(*RemoveVGRequest)(nil),                   // 29: proto.RemoveVGRequest
+	(*ExtendVGRequest)(nil),                   // 30: proto.ExtendVGRequest
+	(*ReduceVGRequest)(nil),                   // 31: proto.ReduceVGRequest
+	(*EvacuatePVRequest)(nil),                 // 32: proto.EvacuatePVRequest
+	(*EvacuatePVResponse)(nil),                // 33: proto.EvacuatePVResponse
+	(*ReportThinPoolEventRequest)(nil),        // 34: proto.ReportThinPoolEventRequest
+	(*GetThinPoolUsageRequest)(nil),           // 35: proto.GetThinPoolUsageRequest
+	(*GetThinPoolUsageResponse)(nil),          // 36: proto.GetThinPoolUsageResponse
+	(*GetLVMVersionResponse)(nil),             // 37: proto.GetLVMVersionResponse
+	(*DeviceClassCapabilities)(nil),           // 38: proto.DeviceClassCapabilities
+	(*GetCapabilitiesResponse)(nil),           // 39: proto.GetCapabilitiesResponse
+	(*LVMDevice)(nil),                         // 40: proto.LVMDevice
+	(*GetLVMDevicesResponse)(nil),             // 41: proto.GetLVMDevicesResponse
+	(*UpdateLVMDevicesRequest)(nil),           // 42: proto.UpdateLVMDevicesRequest
+	(*SetDeviceClassDrainingRequest)(nil),     // 43: proto.SetDeviceClassDrainingRequest
+	(*WatchResponse)(nil),                     // 44: proto.WatchResponse
+	(*WatchLVsRequest)(nil),                   // 45: proto.WatchLVsRequest
+	(*LVEvent)(nil),                           // 46: proto.LVEvent
+	(*WatchLVsResponse)(nil),                  // 47: proto.WatchLVsResponse
+	(*LvcreateOptionClassItem)(nil),           // 48: proto.LvcreateOptionClassItem
+	(*ThinPoolItem)(nil),                      // 49: proto.ThinPoolItem
+	(*CacheItem)(nil),                         // 50: proto.CacheItem
+	(*VDOItem)(nil),                           // 51: proto.VDOItem
+	(*WatchItem)(nil),                         // 52: proto.WatchItem
+	nil,                                       // 53: proto.CreateDeviceClassSnapshotResponse.SnapshotsEntry
+}
+var file_pkg_lvmd_proto_lvmd_proto_depIdxs = []int32{
+	1,  // 0: proto.CreateLVResponse.volume:type_name -> proto.LogicalVolume
+	2,  // 1: proto.CreateLVResponse.warnings:type_name -> proto.Warning
+	1,  // 2: proto.CreateLVSnapshotResponse.snapshot:type_name -> proto.LogicalVolume
+	2,  // 3: proto.CreateLVSnapshotResponse.warnings:type_name -> proto.Warning
+	1,  // 4: proto.ActivateLVResponse.volume:type_name -> proto.LogicalVolume
+	53, // 5: proto.CreateDeviceClassSnapshotResponse.snapshots:type_name -> proto.CreateDeviceClassSnapshotResponse.SnapshotsEntry
+	2,  // 6: proto.CreateDeviceClassSnapshotResponse.warnings:type_name -> proto.Warning
+	2,  // 7: proto.MergeLVSnapshotResponse.warnings:type_name -> proto.Warning
+	2,  // 8: proto.ResizeLVResponse.warnings:type_name -> proto.Warning
+	1,  // 9: proto.GetLVListResponse.volumes:type_name -> proto.LogicalVolume
+	1,  // 10: proto.ListSnapshotsResponse.snapshots:type_name -> proto.LogicalVolume
+	38, // 11: proto.GetCapabilitiesResponse.device_classes:type_name -> proto.DeviceClassCapabilities
+	40, // 12: proto.GetLVMDevicesResponse.devices:type_name -> proto.LVMDevice
+	52, // 13: proto.WatchResponse.items:type_name -> proto.WatchItem
+	48, // 14: proto.WatchResponse.lvcreate_option_classes:type_name -> proto.LvcreateOptionClassItem
+	1,  // 15: proto.LVEvent.volume:type_name -> proto.LogicalVolume
+	46, // 16: proto.WatchLVsResponse.events:type_name -> proto.LVEvent
+	49, // 17: proto.WatchItem.thin_pool:type_name -> proto.ThinPoolItem
+	50, // 18: proto.WatchItem.cache:type_name -> proto.CacheItem
+	51, // 19: proto.WatchItem.vdo:type_name -> proto.VDOItem
+	4,  // 20: proto.LVService.CreateLV:input_type -> proto.CreateLVRequest
+	6,  // 21: proto.LVService.RemoveLV:input_type -> proto.RemoveLVRequest
+	20, // 22: proto.LVService.ResizeLV:input_type -> proto.ResizeLVRequest
+	9,  // 23: proto.LVService.ChangeLVTags:input_type -> proto.ChangeLVTagsRequest
+	11, // 24: proto.LVService.ActivateLV:input_type -> proto.ActivateLVRequest
+	13, // 25: proto.LVService.DeactivateLV:input_type -> proto.DeactivateLVRequest
+	7,  // 26: proto.LVService.CreateLVSnapshot:input_type -> proto.CreateLVSnapshotRequest
+	16, // 27: proto.LVService.MergeLVSnapshot:input_type -> proto.MergeLVSnapshotRequest
+	14, // 28: proto.LVService.CreateDeviceClassSnapshot:input_type -> proto.CreateDeviceClassSnapshotRequest
+	18, // 29: proto.LVService.MoveLV:input_type -> proto.MoveLVRequest
+	24, // 30: proto.VGService.GetLVList:input_type -> proto.GetLVListRequest
+	25, // 31: proto.VGService.ListSnapshots:input_type -> proto.ListSnapshotsRequest
+	27, // 32: proto.VGService.GetFreeBytes:input_type -> proto.GetFreeBytesRequest
+	0,  // 33: proto.VGService.Watch:input_type -> proto.Empty
+	45, // 34: proto.VGService.WatchLVs:input_type -> proto.WatchLVsRequest
+	28, // 35: proto.VGService.CreateVG:input_type -> proto.CreateVGRequest
+	29, // 36: proto.VGService.RemoveVG:input_type -> proto.RemoveVGRequest
+	30, // 37: proto.VGService.ExtendVG:input_type -> proto.ExtendVGRequest
+	31, // 38: proto.VGService.ReduceVG:input_type -> proto.ReduceVGRequest
+	32, // 39: proto.VGService.EvacuatePV:input_type -> proto.EvacuatePVRequest
+	34, // 40: proto.VGService.ReportThinPoolEvent:input_type -> proto.ReportThinPoolEventRequest
+	35, // 41: proto.VGService.GetThinPoolUsage:input_type -> proto.GetThinPoolUsageRequest
+	0,  // 42: proto.VGService.GetLVMVersion:input_type -> proto.Empty
+	0,  // 43: proto.VGService.GetCapabilities:input_type -> proto.Empty
+	0,  // 44: proto.VGService.GetLVMDevices:input_type -> proto.Empty
+	42, // 45: proto.VGService.UpdateLVMDevices:input_type -> proto.UpdateLVMDevicesRequest
+	43, // 46: proto.VGService.SetDeviceClassDraining:input_type -> proto.SetDeviceClassDrainingRequest
+	5,  // 47: proto.LVService.CreateLV:output_type -> proto.CreateLVResponse
+	0,  // 48: proto.LVService.RemoveLV:output_type -> proto.Empty
+	21, // 49: proto.LVService.ResizeLV:output_type -> proto.ResizeLVResponse
+	10, // 50: proto.LVService.ChangeLVTags:output_type -> proto.ChangeLVTagsResponse
+	12, // 51: proto.LVService.ActivateLV:output_type -> proto.ActivateLVResponse
+	0,  // 52: proto.LVService.DeactivateLV:output_type -> proto.Empty
+	8,  // 53: proto.LVService.CreateLVSnapshot:output_type -> proto.CreateLVSnapshotResponse
+	17, // 54: proto.LVService.MergeLVSnapshot:output_type -> proto.MergeLVSnapshotResponse
+	15, // 55: proto.LVService.CreateDeviceClassSnapshot:output_type -> proto.CreateDeviceClassSnapshotResponse
+	19, // 56: proto.LVService.MoveLV:output_type -> proto.MoveLVResponse
+	22, // 57: proto.VGService.GetLVList:output_type -> proto.GetLVListResponse
+	26, // 58: proto.VGService.ListSnapshots:output_type -> proto.ListSnapshotsResponse
+	23, // 59: proto.VGService.GetFreeBytes:output_type -> proto.GetFreeBytesResponse
+	44, // 60: proto.VGService.Watch:output_type -> proto.WatchResponse
+	47, // 61: proto.VGService.WatchLVs:output_type -> proto.WatchLVsResponse
+	0,  // 62: proto.VGService.CreateVG:output_type -> proto.Empty
+	0,  // 63: proto.VGService.RemoveVG:output_type -> proto.Empty
+	0,  // 64: proto.VGService.ExtendVG:output_type -> proto.Empty
+	0,  // 65: proto.VGService.ReduceVG:output_type -> proto.Empty
+	33, // 66: proto.VGService.EvacuatePV:output_type -> proto.EvacuatePVResponse
+	0,  // 67: proto.VGService.ReportThinPoolEvent:output_type -> proto.Empty
+	36, // 68: proto.VGService.GetThinPoolUsage:output_type -> proto.GetThinPoolUsageResponse
+	37, // 69: proto.VGService.GetLVMVersion:output_type -> proto.GetLVMVersionResponse
+	39, // 70: proto.VGService.GetCapabilities:output_type -> proto.GetCapabilitiesResponse
+	41, // 71: proto.VGService.GetLVMDevices:output_type -> proto.GetLVMDevicesResponse
+	41, // 72: proto.VGService.UpdateLVMDevices:output_type -> proto.GetLVMDevicesResponse
+	0,  // 73: proto.VGService.SetDeviceClassDraining:output_type -> proto.Empty
+	47, // [47:74] is the sub-list for method output_type
+	20, // [20:47] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_pkg_lvmd_proto_lvmd_proto_init() }
+func file_pkg_lvmd_proto_lvmd_proto_init() {
+	if File_pkg_lvmd_proto_lvmd_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Empty); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LogicalVolume); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Warning); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InsufficientSpace); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLVRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLVResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveLVRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLVSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateLVSnapshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeLVTagsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ChangeLVTagsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActivateLVRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActivateLVResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeactivateLVRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateDeviceClassSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateDeviceClassSnapshotResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MergeLVSnapshotRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
 			case 2:
 				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*LogicalVolume); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MergeLVSnapshotResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1198,8 +4404,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateLVRequest); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveLVRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1210,8 +4416,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateLVResponse); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MoveLVResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1222,8 +4428,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RemoveLVRequest); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResizeLVRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1234,8 +4440,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateLVSnapshotRequest); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResizeLVResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1246,8 +4452,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateLVSnapshotResponse); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLVListResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1258,8 +4464,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResizeLVRequest); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetFreeBytesResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1270,8 +4476,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetLVListResponse); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLVListRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1282,8 +4488,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetFreeBytesResponse); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSnapshotsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1294,8 +4500,8 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetLVListRequest); i {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListSnapshotsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1306,7 +4512,7 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetFreeBytesRequest); i {
 			case 0:
 				return &v.state
@@ -1318,7 +4524,199 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateVGRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoveVGRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExtendVGRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReduceVGRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvacuatePVRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EvacuatePVResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReportThinPoolEventRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetThinPoolUsageRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetThinPoolUsageResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLVMVersionResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeviceClassCapabilities); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCapabilitiesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LVMDevice); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetLVMDevicesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpdateLVMDevicesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SetDeviceClassDrainingRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*WatchResponse); i {
 			case 0:
 				return &v.state
@@ -1330,7 +4728,55 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchLVsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LVEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*WatchLVsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[48].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LvcreateOptionClassItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[49].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ThinPoolItem); i {
 			case 0:
 				return &v.state
@@ -1342,7 +4788,31 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 				return nil
 			}
 		}
-		file_pkg_lvmd_proto_lvmd_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[50].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[51].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VDOItem); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_pkg_lvmd_proto_lvmd_proto_msgTypes[52].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*WatchItem); i {
 			case 0:
 				return &v.state
@@ -1361,7 +4831,7 @@ func file_pkg_lvmd_proto_lvmd_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_pkg_lvmd_proto_lvmd_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   15,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
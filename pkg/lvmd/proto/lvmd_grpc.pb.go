@@ -2,7 +2,7 @@
 // LVMd manages logical volumes of an LVM volume group.
 //
 // The protocol consists of two services:
-// - VGService provides information of the volume group.
+// - VGService provides information of the volume group and manages the volume groups.
 // - LVService provides management functions for logical volumes on the volume group.
 
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
@@ -26,10 +26,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	LVService_CreateLV_FullMethodName         = "/proto.LVService/CreateLV"
-	LVService_RemoveLV_FullMethodName         = "/proto.LVService/RemoveLV"
-	LVService_ResizeLV_FullMethodName         = "/proto.LVService/ResizeLV"
-	LVService_CreateLVSnapshot_FullMethodName = "/proto.LVService/CreateLVSnapshot"
+	LVService_CreateLV_FullMethodName                  = "/proto.LVService/CreateLV"
+	LVService_RemoveLV_FullMethodName                  = "/proto.LVService/RemoveLV"
+	LVService_ResizeLV_FullMethodName                  = "/proto.LVService/ResizeLV"
+	LVService_ChangeLVTags_FullMethodName              = "/proto.LVService/ChangeLVTags"
+	LVService_ActivateLV_FullMethodName                = "/proto.LVService/ActivateLV"
+	LVService_DeactivateLV_FullMethodName              = "/proto.LVService/DeactivateLV"
+	LVService_CreateLVSnapshot_FullMethodName          = "/proto.LVService/CreateLVSnapshot"
+	LVService_MergeLVSnapshot_FullMethodName           = "/proto.LVService/MergeLVSnapshot"
+	LVService_CreateDeviceClassSnapshot_FullMethodName = "/proto.LVService/CreateDeviceClassSnapshot"
+	LVService_MoveLV_FullMethodName                    = "/proto.LVService/MoveLV"
 )
 
 // LVServiceClient is the client API for LVService service.
@@ -41,8 +47,23 @@ type LVServiceClient interface {
 	// Remove a logical volume.
 	RemoveLV(ctx context.Context, in *RemoveLVRequest, opts ...grpc.CallOption) (*Empty, error)
 	// Resize a logical volume.
-	ResizeLV(ctx context.Context, in *ResizeLVRequest, opts ...grpc.CallOption) (*Empty, error)
+	ResizeLV(ctx context.Context, in *ResizeLVRequest, opts ...grpc.CallOption) (*ResizeLVResponse, error)
+	// Add and remove tags of a logical volume.
+	ChangeLVTags(ctx context.Context, in *ChangeLVTagsRequest, opts ...grpc.CallOption) (*ChangeLVTagsResponse, error)
+	// Activate a logical volume, also if it has the activation skip flag.
+	ActivateLV(ctx context.Context, in *ActivateLVRequest, opts ...grpc.CallOption) (*ActivateLVResponse, error)
+	// Deactivate a logical volume which is not in use.
+	DeactivateLV(ctx context.Context, in *DeactivateLVRequest, opts ...grpc.CallOption) (*Empty, error)
 	CreateLVSnapshot(ctx context.Context, in *CreateLVSnapshotRequest, opts ...grpc.CallOption) (*CreateLVSnapshotResponse, error)
+	// Merge a snapshot back into its origin logical volume.
+	// The merge runs in the background, call it again to get the progress.
+	MergeLVSnapshot(ctx context.Context, in *MergeLVSnapshotRequest, opts ...grpc.CallOption) (*MergeLVSnapshotResponse, error)
+	// Snapshot all the thin volumes of a device class at a single point in time, e.g. for backups of the node.
+	// The volumes are suspended until their snapshots are taken.
+	CreateDeviceClassSnapshot(ctx context.Context, in *CreateDeviceClassSnapshotRequest, opts ...grpc.CallOption) (*CreateDeviceClassSnapshotResponse, error)
+	// Move a thin volume to the thin pool of another device class of the same volume group.
+	// The volume is copied in the background, call it again to get the progress.
+	MoveLV(ctx context.Context, in *MoveLVRequest, opts ...grpc.CallOption) (*MoveLVResponse, error)
 }
 
 type lVServiceClient struct {
@@ -71,8 +92,8 @@ func (c *lVServiceClient) RemoveLV(ctx context.Context, in *RemoveLVRequest, opt
 	return out, nil
 }
 
-func (c *lVServiceClient) ResizeLV(ctx context.Context, in *ResizeLVRequest, opts ...grpc.CallOption) (*Empty, error) {
-	out := new(Empty)
+func (c *lVServiceClient) ResizeLV(ctx context.Context, in *ResizeLVRequest, opts ...grpc.CallOption) (*ResizeLVResponse, error) {
+	out := new(ResizeLVResponse)
 	err := c.cc.Invoke(ctx, LVService_ResizeLV_FullMethodName, in, out, opts...)
 	if err != nil {
 		return nil, err
@@ -80,6 +101,33 @@ func (c *lVServiceClient) ResizeLV(ctx context.Context, in *ResizeLVRequest, opt
 	return out, nil
 }
 
+func (c *lVServiceClient) ChangeLVTags(ctx context.Context, in *ChangeLVTagsRequest, opts ...grpc.CallOption) (*ChangeLVTagsResponse, error) {
+	out := new(ChangeLVTagsResponse)
+	err := c.cc.Invoke(ctx, LVService_ChangeLVTags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) ActivateLV(ctx context.Context, in *ActivateLVRequest, opts ...grpc.CallOption) (*ActivateLVResponse, error) {
+	out := new(ActivateLVResponse)
+	err := c.cc.Invoke(ctx, LVService_ActivateLV_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) DeactivateLV(ctx context.Context, in *DeactivateLVRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, LVService_DeactivateLV_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *lVServiceClient) CreateLVSnapshot(ctx context.Context, in *CreateLVSnapshotRequest, opts ...grpc.CallOption) (*CreateLVSnapshotResponse, error) {
 	out := new(CreateLVSnapshotResponse)
 	err := c.cc.Invoke(ctx, LVService_CreateLVSnapshot_FullMethodName, in, out, opts...)
@@ -89,6 +137,33 @@ func (c *lVServiceClient) CreateLVSnapshot(ctx context.Context, in *CreateLVSnap
 	return out, nil
 }
 
+func (c *lVServiceClient) MergeLVSnapshot(ctx context.Context, in *MergeLVSnapshotRequest, opts ...grpc.CallOption) (*MergeLVSnapshotResponse, error) {
+	out := new(MergeLVSnapshotResponse)
+	err := c.cc.Invoke(ctx, LVService_MergeLVSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) CreateDeviceClassSnapshot(ctx context.Context, in *CreateDeviceClassSnapshotRequest, opts ...grpc.CallOption) (*CreateDeviceClassSnapshotResponse, error) {
+	out := new(CreateDeviceClassSnapshotResponse)
+	err := c.cc.Invoke(ctx, LVService_CreateDeviceClassSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *lVServiceClient) MoveLV(ctx context.Context, in *MoveLVRequest, opts ...grpc.CallOption) (*MoveLVResponse, error) {
+	out := new(MoveLVResponse)
+	err := c.cc.Invoke(ctx, LVService_MoveLV_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LVServiceServer is the server API for LVService service.
 // All implementations must embed UnimplementedLVServiceServer
 // for forward compatibility
@@ -98,8 +173,23 @@ type LVServiceServer interface {
 	// Remove a logical volume.
 	RemoveLV(context.Context, *RemoveLVRequest) (*Empty, error)
 	// Resize a logical volume.
-	ResizeLV(context.Context, *ResizeLVRequest) (*Empty, error)
+	ResizeLV(context.Context, *ResizeLVRequest) (*ResizeLVResponse, error)
+	// Add and remove tags of a logical volume.
+	ChangeLVTags(context.Context, *ChangeLVTagsRequest) (*ChangeLVTagsResponse, error)
+	// Activate a logical volume, also if it has the activation skip flag.
+	ActivateLV(context.Context, *ActivateLVRequest) (*ActivateLVResponse, error)
+	// Deactivate a logical volume which is not in use.
+	DeactivateLV(context.Context, *DeactivateLVRequest) (*Empty, error)
 	CreateLVSnapshot(context.Context, *CreateLVSnapshotRequest) (*CreateLVSnapshotResponse, error)
+	// Merge a snapshot back into its origin logical volume.
+	// The merge runs in the background, call it again to get the progress.
+	MergeLVSnapshot(context.Context, *MergeLVSnapshotRequest) (*MergeLVSnapshotResponse, error)
+	// Snapshot all the thin volumes of a device class at a single point in time, e.g. for backups of the node.
+	// The volumes are suspended until their snapshots are taken.
+	CreateDeviceClassSnapshot(context.Context, *CreateDeviceClassSnapshotRequest) (*CreateDeviceClassSnapshotResponse, error)
+	// Move a thin volume to the thin pool of another device class of the same volume group.
+	// The volume is copied in the background, call it again to get the progress.
+	MoveLV(context.Context, *MoveLVRequest) (*MoveLVResponse, error)
 	mustEmbedUnimplementedLVServiceServer()
 }
 
@@ -113,12 +203,30 @@ func (UnimplementedLVServiceServer) CreateLV(context.Context, *CreateLVRequest)
 func (UnimplementedLVServiceServer) RemoveLV(context.Context, *RemoveLVRequest) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RemoveLV not implemented")
 }
-func (UnimplementedLVServiceServer) ResizeLV(context.Context, *ResizeLVRequest) (*Empty, error) {
+func (UnimplementedLVServiceServer) ResizeLV(context.Context, *ResizeLVRequest) (*ResizeLVResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ResizeLV not implemented")
 }
+func (UnimplementedLVServiceServer) ChangeLVTags(context.Context, *ChangeLVTagsRequest) (*ChangeLVTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ChangeLVTags not implemented")
+}
+func (UnimplementedLVServiceServer) ActivateLV(context.Context, *ActivateLVRequest) (*ActivateLVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ActivateLV not implemented")
+}
+func (UnimplementedLVServiceServer) DeactivateLV(context.Context, *DeactivateLVRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivateLV not implemented")
+}
 func (UnimplementedLVServiceServer) CreateLVSnapshot(context.Context, *CreateLVSnapshotRequest) (*CreateLVSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateLVSnapshot not implemented")
 }
+func (UnimplementedLVServiceServer) MergeLVSnapshot(context.Context, *MergeLVSnapshotRequest) (*MergeLVSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeLVSnapshot not implemented")
+}
+func (UnimplementedLVServiceServer) CreateDeviceClassSnapshot(context.Context, *CreateDeviceClassSnapshotRequest) (*CreateDeviceClassSnapshotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDeviceClassSnapshot not implemented")
+}
+func (UnimplementedLVServiceServer) MoveLV(context.Context, *MoveLVRequest) (*MoveLVResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MoveLV not implemented")
+}
 func (UnimplementedLVServiceServer) mustEmbedUnimplementedLVServiceServer() {}
 
 // UnsafeLVServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -186,6 +294,60 @@ func _LVService_ResizeLV_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LVService_ChangeLVTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeLVTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).ChangeLVTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_ChangeLVTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).ChangeLVTags(ctx, req.(*ChangeLVTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_ActivateLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActivateLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).ActivateLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_ActivateLV_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).ActivateLV(ctx, req.(*ActivateLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_DeactivateLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeactivateLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).DeactivateLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_DeactivateLV_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).DeactivateLV(ctx, req.(*DeactivateLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _LVService_CreateLVSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreateLVSnapshotRequest)
 	if err := dec(in); err != nil {
@@ -204,6 +366,60 @@ func _LVService_CreateLVSnapshot_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LVService_MergeLVSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeLVSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).MergeLVSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_MergeLVSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).MergeLVSnapshot(ctx, req.(*MergeLVSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_CreateDeviceClassSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDeviceClassSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).CreateDeviceClassSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_CreateDeviceClassSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).CreateDeviceClassSnapshot(ctx, req.(*CreateDeviceClassSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LVService_MoveLV_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MoveLVRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LVServiceServer).MoveLV(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LVService_MoveLV_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LVServiceServer).MoveLV(ctx, req.(*MoveLVRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LVService_ServiceDesc is the grpc.ServiceDesc for LVService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -223,19 +439,57 @@ var LVService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ResizeLV",
 			Handler:    _LVService_ResizeLV_Handler,
 		},
+		{
+			MethodName: "ChangeLVTags",
+			Handler:    _LVService_ChangeLVTags_Handler,
+		},
+		{
+			MethodName: "ActivateLV",
+			Handler:    _LVService_ActivateLV_Handler,
+		},
+		{
+			MethodName: "DeactivateLV",
+			Handler:    _LVService_DeactivateLV_Handler,
+		},
 		{
 			MethodName: "CreateLVSnapshot",
 			Handler:    _LVService_CreateLVSnapshot_Handler,
 		},
+		{
+			MethodName: "MergeLVSnapshot",
+			Handler:    _LVService_MergeLVSnapshot_Handler,
+		},
+		{
+			MethodName: "CreateDeviceClassSnapshot",
+			Handler:    _LVService_CreateDeviceClassSnapshot_Handler,
+		},
+		{
+			MethodName: "MoveLV",
+			Handler:    _LVService_MoveLV_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "pkg/lvmd/proto/lvmd.proto",
 }
 
 const (
-	VGService_GetLVList_FullMethodName    = "/proto.VGService/GetLVList"
-	VGService_GetFreeBytes_FullMethodName = "/proto.VGService/GetFreeBytes"
-	VGService_Watch_FullMethodName        = "/proto.VGService/Watch"
+	VGService_GetLVList_FullMethodName              = "/proto.VGService/GetLVList"
+	VGService_ListSnapshots_FullMethodName          = "/proto.VGService/ListSnapshots"
+	VGService_GetFreeBytes_FullMethodName           = "/proto.VGService/GetFreeBytes"
+	VGService_Watch_FullMethodName                  = "/proto.VGService/Watch"
+	VGService_WatchLVs_FullMethodName               = "/proto.VGService/WatchLVs"
+	VGService_CreateVG_FullMethodName               = "/proto.VGService/CreateVG"
+	VGService_RemoveVG_FullMethodName               = "/proto.VGService/RemoveVG"
+	VGService_ExtendVG_FullMethodName               = "/proto.VGService/ExtendVG"
+	VGService_ReduceVG_FullMethodName               = "/proto.VGService/ReduceVG"
+	VGService_EvacuatePV_FullMethodName             = "/proto.VGService/EvacuatePV"
+	VGService_ReportThinPoolEvent_FullMethodName    = "/proto.VGService/ReportThinPoolEvent"
+	VGService_GetThinPoolUsage_FullMethodName       = "/proto.VGService/GetThinPoolUsage"
+	VGService_GetLVMVersion_FullMethodName          = "/proto.VGService/GetLVMVersion"
+	VGService_GetCapabilities_FullMethodName        = "/proto.VGService/GetCapabilities"
+	VGService_GetLVMDevices_FullMethodName          = "/proto.VGService/GetLVMDevices"
+	VGService_UpdateLVMDevices_FullMethodName       = "/proto.VGService/UpdateLVMDevices"
+	VGService_SetDeviceClassDraining_FullMethodName = "/proto.VGService/SetDeviceClassDraining"
 )
 
 // VGServiceClient is the client API for VGService service.
@@ -244,10 +498,42 @@ const (
 type VGServiceClient interface {
 	// Get the list of logical volumes in the volume group.
 	GetLVList(ctx context.Context, in *GetLVListRequest, opts ...grpc.CallOption) (*GetLVListResponse, error)
+	// Get the list of snapshot logical volumes of an origin volume or of the device class.
+	ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
 	// Get the free space of the volume group in bytes.
 	GetFreeBytes(ctx context.Context, in *GetFreeBytesRequest, opts ...grpc.CallOption) (*GetFreeBytesResponse, error)
 	// Stream the volume group metrics.
 	Watch(ctx context.Context, in *Empty, opts ...grpc.CallOption) (VGService_WatchClient, error)
+	// Stream the creation, resize and deletion of the logical volumes, starting with the existing ones.
+	WatchLVs(ctx context.Context, in *WatchLVsRequest, opts ...grpc.CallOption) (VGService_WatchLVsClient, error)
+	// Create a volume group which is not used by any device class yet.
+	CreateVG(ctx context.Context, in *CreateVGRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Remove a volume group which is not used by any device class and has no logical volumes.
+	RemoveVG(ctx context.Context, in *RemoveVGRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Add devices to the volume group of the device class.
+	ExtendVG(ctx context.Context, in *ExtendVGRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Remove unused physical volumes from the volume group of the device class.
+	ReduceVG(ctx context.Context, in *ReduceVGRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Move the extents off a physical volume of the volume group of the device class, streaming the progress.
+	// The physical volume is excluded from allocation first and stays so after the move.
+	EvacuatePV(ctx context.Context, in *EvacuatePVRequest, opts ...grpc.CallOption) (VGService_EvacuatePVClient, error)
+	// Report the usage of a thin pool on an event of dmeventd, which notifies the watchers
+	// if the usage crosses a threshold of the device class.
+	ReportThinPoolEvent(ctx context.Context, in *ReportThinPoolEventRequest, opts ...grpc.CallOption) (*Empty, error)
+	// Get the data and metadata usage of the thin pool of a thin device class.
+	GetThinPoolUsage(ctx context.Context, in *GetThinPoolUsageRequest, opts ...grpc.CallOption) (*GetThinPoolUsageResponse, error)
+	// Get the version of lvm on the node and the features lvmd uses with it.
+	GetLVMVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetLVMVersionResponse, error)
+	// Get the optional features supported by lvm on the node and enabled for the device classes.
+	GetCapabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error)
+	// Get the lvm devices file and the device filters of lvm.conf, which limit the devices lvm scans and uses.
+	GetLVMDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetLVMDevicesResponse, error)
+	// Add devices to or remove devices from the lvm devices file.
+	// The physical volumes of the volume groups of the device classes cannot be removed.
+	UpdateLVMDevices(ctx context.Context, in *UpdateLVMDevicesRequest, opts ...grpc.CallOption) (*GetLVMDevicesResponse, error)
+	// Start or stop draining a device class. A draining device class rejects new logical volumes
+	// and reports no free space, while the existing ones can still be resized, snapshotted and removed.
+	SetDeviceClassDraining(ctx context.Context, in *SetDeviceClassDrainingRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type vGServiceClient struct {
@@ -267,6 +553,15 @@ func (c *vGServiceClient) GetLVList(ctx context.Context, in *GetLVListRequest, o
 	return out, nil
 }
 
+func (c *vGServiceClient) ListSnapshots(ctx context.Context, in *ListSnapshotsRequest, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, VGService_ListSnapshots_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *vGServiceClient) GetFreeBytes(ctx context.Context, in *GetFreeBytesRequest, opts ...grpc.CallOption) (*GetFreeBytesResponse, error) {
 	out := new(GetFreeBytesResponse)
 	err := c.cc.Invoke(ctx, VGService_GetFreeBytes_FullMethodName, in, out, opts...)
@@ -308,16 +603,211 @@ func (x *vGServiceWatchClient) Recv() (*WatchResponse, error) {
 	return m, nil
 }
 
+func (c *vGServiceClient) WatchLVs(ctx context.Context, in *WatchLVsRequest, opts ...grpc.CallOption) (VGService_WatchLVsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VGService_ServiceDesc.Streams[1], VGService_WatchLVs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vGServiceWatchLVsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VGService_WatchLVsClient interface {
+	Recv() (*WatchLVsResponse, error)
+	grpc.ClientStream
+}
+
+type vGServiceWatchLVsClient struct {
+	grpc.ClientStream
+}
+
+func (x *vGServiceWatchLVsClient) Recv() (*WatchLVsResponse, error) {
+	m := new(WatchLVsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *vGServiceClient) CreateVG(ctx context.Context, in *CreateVGRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_CreateVG_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) RemoveVG(ctx context.Context, in *RemoveVGRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_RemoveVG_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) ExtendVG(ctx context.Context, in *ExtendVGRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_ExtendVG_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) ReduceVG(ctx context.Context, in *ReduceVGRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_ReduceVG_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) EvacuatePV(ctx context.Context, in *EvacuatePVRequest, opts ...grpc.CallOption) (VGService_EvacuatePVClient, error) {
+	stream, err := c.cc.NewStream(ctx, &VGService_ServiceDesc.Streams[2], VGService_EvacuatePV_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &vGServiceEvacuatePVClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type VGService_EvacuatePVClient interface {
+	Recv() (*EvacuatePVResponse, error)
+	grpc.ClientStream
+}
+
+type vGServiceEvacuatePVClient struct {
+	grpc.ClientStream
+}
+
+func (x *vGServiceEvacuatePVClient) Recv() (*EvacuatePVResponse, error) {
+	m := new(EvacuatePVResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *vGServiceClient) ReportThinPoolEvent(ctx context.Context, in *ReportThinPoolEventRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_ReportThinPoolEvent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) GetThinPoolUsage(ctx context.Context, in *GetThinPoolUsageRequest, opts ...grpc.CallOption) (*GetThinPoolUsageResponse, error) {
+	out := new(GetThinPoolUsageResponse)
+	err := c.cc.Invoke(ctx, VGService_GetThinPoolUsage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) GetLVMVersion(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetLVMVersionResponse, error) {
+	out := new(GetLVMVersionResponse)
+	err := c.cc.Invoke(ctx, VGService_GetLVMVersion_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) GetCapabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetCapabilitiesResponse, error) {
+	out := new(GetCapabilitiesResponse)
+	err := c.cc.Invoke(ctx, VGService_GetCapabilities_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) GetLVMDevices(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*GetLVMDevicesResponse, error) {
+	out := new(GetLVMDevicesResponse)
+	err := c.cc.Invoke(ctx, VGService_GetLVMDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) UpdateLVMDevices(ctx context.Context, in *UpdateLVMDevicesRequest, opts ...grpc.CallOption) (*GetLVMDevicesResponse, error) {
+	out := new(GetLVMDevicesResponse)
+	err := c.cc.Invoke(ctx, VGService_UpdateLVMDevices_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *vGServiceClient) SetDeviceClassDraining(ctx context.Context, in *SetDeviceClassDrainingRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, VGService_SetDeviceClassDraining_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // VGServiceServer is the server API for VGService service.
 // All implementations must embed UnimplementedVGServiceServer
 // for forward compatibility
 type VGServiceServer interface {
 	// Get the list of logical volumes in the volume group.
 	GetLVList(context.Context, *GetLVListRequest) (*GetLVListResponse, error)
+	// Get the list of snapshot logical volumes of an origin volume or of the device class.
+	ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error)
 	// Get the free space of the volume group in bytes.
 	GetFreeBytes(context.Context, *GetFreeBytesRequest) (*GetFreeBytesResponse, error)
 	// Stream the volume group metrics.
 	Watch(*Empty, VGService_WatchServer) error
+	// Stream the creation, resize and deletion of the logical volumes, starting with the existing ones.
+	WatchLVs(*WatchLVsRequest, VGService_WatchLVsServer) error
+	// Create a volume group which is not used by any device class yet.
+	CreateVG(context.Context, *CreateVGRequest) (*Empty, error)
+	// Remove a volume group which is not used by any device class and has no logical volumes.
+	RemoveVG(context.Context, *RemoveVGRequest) (*Empty, error)
+	// Add devices to the volume group of the device class.
+	ExtendVG(context.Context, *ExtendVGRequest) (*Empty, error)
+	// Remove unused physical volumes from the volume group of the device class.
+	ReduceVG(context.Context, *ReduceVGRequest) (*Empty, error)
+	// Move the extents off a physical volume of the volume group of the device class, streaming the progress.
+	// The physical volume is excluded from allocation first and stays so after the move.
+	EvacuatePV(*EvacuatePVRequest, VGService_EvacuatePVServer) error
+	// Report the usage of a thin pool on an event of dmeventd, which notifies the watchers
+	// if the usage crosses a threshold of the device class.
+	ReportThinPoolEvent(context.Context, *ReportThinPoolEventRequest) (*Empty, error)
+	// Get the data and metadata usage of the thin pool of a thin device class.
+	GetThinPoolUsage(context.Context, *GetThinPoolUsageRequest) (*GetThinPoolUsageResponse, error)
+	// Get the version of lvm on the node and the features lvmd uses with it.
+	GetLVMVersion(context.Context, *Empty) (*GetLVMVersionResponse, error)
+	// Get the optional features supported by lvm on the node and enabled for the device classes.
+	GetCapabilities(context.Context, *Empty) (*GetCapabilitiesResponse, error)
+	// Get the lvm devices file and the device filters of lvm.conf, which limit the devices lvm scans and uses.
+	GetLVMDevices(context.Context, *Empty) (*GetLVMDevicesResponse, error)
+	// Add devices to or remove devices from the lvm devices file.
+	// The physical volumes of the volume groups of the device classes cannot be removed.
+	UpdateLVMDevices(context.Context, *UpdateLVMDevicesRequest) (*GetLVMDevicesResponse, error)
+	// Start or stop draining a device class. A draining device class rejects new logical volumes
+	// and reports no free space, while the existing ones can still be resized, snapshotted and removed.
+	SetDeviceClassDraining(context.Context, *SetDeviceClassDrainingRequest) (*Empty, error)
 	mustEmbedUnimplementedVGServiceServer()
 }
 
@@ -328,12 +818,54 @@ type UnimplementedVGServiceServer struct {
 func (UnimplementedVGServiceServer) GetLVList(context.Context, *GetLVListRequest) (*GetLVListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetLVList not implemented")
 }
+func (UnimplementedVGServiceServer) ListSnapshots(context.Context, *ListSnapshotsRequest) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
 func (UnimplementedVGServiceServer) GetFreeBytes(context.Context, *GetFreeBytesRequest) (*GetFreeBytesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFreeBytes not implemented")
 }
 func (UnimplementedVGServiceServer) Watch(*Empty, VGService_WatchServer) error {
 	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
 }
+func (UnimplementedVGServiceServer) WatchLVs(*WatchLVsRequest, VGService_WatchLVsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchLVs not implemented")
+}
+func (UnimplementedVGServiceServer) CreateVG(context.Context, *CreateVGRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateVG not implemented")
+}
+func (UnimplementedVGServiceServer) RemoveVG(context.Context, *RemoveVGRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveVG not implemented")
+}
+func (UnimplementedVGServiceServer) ExtendVG(context.Context, *ExtendVGRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExtendVG not implemented")
+}
+func (UnimplementedVGServiceServer) ReduceVG(context.Context, *ReduceVGRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReduceVG not implemented")
+}
+func (UnimplementedVGServiceServer) EvacuatePV(*EvacuatePVRequest, VGService_EvacuatePVServer) error {
+	return status.Errorf(codes.Unimplemented, "method EvacuatePV not implemented")
+}
+func (UnimplementedVGServiceServer) ReportThinPoolEvent(context.Context, *ReportThinPoolEventRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportThinPoolEvent not implemented")
+}
+func (UnimplementedVGServiceServer) GetThinPoolUsage(context.Context, *GetThinPoolUsageRequest) (*GetThinPoolUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetThinPoolUsage not implemented")
+}
+func (UnimplementedVGServiceServer) GetLVMVersion(context.Context, *Empty) (*GetLVMVersionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLVMVersion not implemented")
+}
+func (UnimplementedVGServiceServer) GetCapabilities(context.Context, *Empty) (*GetCapabilitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCapabilities not implemented")
+}
+func (UnimplementedVGServiceServer) GetLVMDevices(context.Context, *Empty) (*GetLVMDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLVMDevices not implemented")
+}
+func (UnimplementedVGServiceServer) UpdateLVMDevices(context.Context, *UpdateLVMDevicesRequest) (*GetLVMDevicesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateLVMDevices not implemented")
+}
+func (UnimplementedVGServiceServer) SetDeviceClassDraining(context.Context, *SetDeviceClassDrainingRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDeviceClassDraining not implemented")
+}
 func (UnimplementedVGServiceServer) mustEmbedUnimplementedVGServiceServer() {}
 
 // UnsafeVGServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -365,6 +897,24 @@ func _VGService_GetLVList_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _VGService_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSnapshotsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).ListSnapshots(ctx, req.(*ListSnapshotsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _VGService_GetFreeBytes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetFreeBytesRequest)
 	if err := dec(in); err != nil {
@@ -404,6 +954,246 @@ func (x *vGServiceWatchServer) Send(m *WatchResponse) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _VGService_WatchLVs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchLVsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VGServiceServer).WatchLVs(m, &vGServiceWatchLVsServer{stream})
+}
+
+type VGService_WatchLVsServer interface {
+	Send(*WatchLVsResponse) error
+	grpc.ServerStream
+}
+
+type vGServiceWatchLVsServer struct {
+	grpc.ServerStream
+}
+
+func (x *vGServiceWatchLVsServer) Send(m *WatchLVsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _VGService_CreateVG_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateVGRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).CreateVG(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_CreateVG_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).CreateVG(ctx, req.(*CreateVGRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_RemoveVG_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveVGRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).RemoveVG(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_RemoveVG_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).RemoveVG(ctx, req.(*RemoveVGRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_ExtendVG_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExtendVGRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).ExtendVG(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_ExtendVG_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).ExtendVG(ctx, req.(*ExtendVGRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_ReduceVG_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReduceVGRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).ReduceVG(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_ReduceVG_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).ReduceVG(ctx, req.(*ReduceVGRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_EvacuatePV_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EvacuatePVRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(VGServiceServer).EvacuatePV(m, &vGServiceEvacuatePVServer{stream})
+}
+
+type VGService_EvacuatePVServer interface {
+	Send(*EvacuatePVResponse) error
+	grpc.ServerStream
+}
+
+type vGServiceEvacuatePVServer struct {
+	grpc.ServerStream
+}
+
+func (x *vGServiceEvacuatePVServer) Send(m *EvacuatePVResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _VGService_ReportThinPoolEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportThinPoolEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).ReportThinPoolEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_ReportThinPoolEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).ReportThinPoolEvent(ctx, req.(*ReportThinPoolEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_GetThinPoolUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetThinPoolUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).GetThinPoolUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_GetThinPoolUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).GetThinPoolUsage(ctx, req.(*GetThinPoolUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_GetLVMVersion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).GetLVMVersion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_GetLVMVersion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).GetLVMVersion(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_GetCapabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).GetCapabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_GetCapabilities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).GetCapabilities(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_GetLVMDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).GetLVMDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_GetLVMDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).GetLVMDevices(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_UpdateLVMDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLVMDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).UpdateLVMDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_UpdateLVMDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).UpdateLVMDevices(ctx, req.(*UpdateLVMDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VGService_SetDeviceClassDraining_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDeviceClassDrainingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VGServiceServer).SetDeviceClassDraining(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VGService_SetDeviceClassDraining_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VGServiceServer).SetDeviceClassDraining(ctx, req.(*SetDeviceClassDrainingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // VGService_ServiceDesc is the grpc.ServiceDesc for VGService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -415,10 +1205,58 @@ var VGService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetLVList",
 			Handler:    _VGService_GetLVList_Handler,
 		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _VGService_ListSnapshots_Handler,
+		},
 		{
 			MethodName: "GetFreeBytes",
 			Handler:    _VGService_GetFreeBytes_Handler,
 		},
+		{
+			MethodName: "CreateVG",
+			Handler:    _VGService_CreateVG_Handler,
+		},
+		{
+			MethodName: "RemoveVG",
+			Handler:    _VGService_RemoveVG_Handler,
+		},
+		{
+			MethodName: "ExtendVG",
+			Handler:    _VGService_ExtendVG_Handler,
+		},
+		{
+			MethodName: "ReduceVG",
+			Handler:    _VGService_ReduceVG_Handler,
+		},
+		{
+			MethodName: "ReportThinPoolEvent",
+			Handler:    _VGService_ReportThinPoolEvent_Handler,
+		},
+		{
+			MethodName: "GetThinPoolUsage",
+			Handler:    _VGService_GetThinPoolUsage_Handler,
+		},
+		{
+			MethodName: "GetLVMVersion",
+			Handler:    _VGService_GetLVMVersion_Handler,
+		},
+		{
+			MethodName: "GetCapabilities",
+			Handler:    _VGService_GetCapabilities_Handler,
+		},
+		{
+			MethodName: "GetLVMDevices",
+			Handler:    _VGService_GetLVMDevices_Handler,
+		},
+		{
+			MethodName: "UpdateLVMDevices",
+			Handler:    _VGService_UpdateLVMDevices_Handler,
+		},
+		{
+			MethodName: "SetDeviceClassDraining",
+			Handler:    _VGService_SetDeviceClassDraining_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -426,6 +1264,16 @@ var VGService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _VGService_Watch_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "WatchLVs",
+			Handler:       _VGService_WatchLVs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "EvacuatePV",
+			Handler:       _VGService_EvacuatePV_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/lvmd/proto/lvmd.proto",
 }
@@ -0,0 +1,14 @@
+package lvmd
+
+import (
+	"context"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+// RecoverSnapshots completes or rolls back the snapshots of the device-classes whose creation was interrupted,
+// e.g. by a restart between lvcreate and the activation of the snapshot.
+func RecoverSnapshots(ctx context.Context, deviceClasses []*lvmdTypes.DeviceClass) error {
+	return internalLvmd.RecoverSnapshots(ctx, deviceClasses)
+}
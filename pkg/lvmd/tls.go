@@ -0,0 +1,12 @@
+package lvmd
+
+import (
+	"crypto/tls"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
+)
+
+// ClientTLSConfig returns the TLS configuration to connect to lvmd listening on TCP with mutual TLS.
+func ClientTLSConfig(certFile, keyFile, caFile, serverName string) (*tls.Config, error) {
+	return internalLvmd.ClientTLSConfig(certFile, keyFile, caFile, serverName)
+}
@@ -5,6 +5,66 @@ type DeviceType string
 const (
 	TypeThin  = DeviceType("thin")
 	TypeThick = DeviceType("thick")
+	TypeRaw   = DeviceType("raw")
+)
+
+type RAIDType string
+
+const (
+	TypeRAID1  = RAIDType("raid1")
+	TypeRAID5  = RAIDType("raid5")
+	TypeRAID10 = RAIDType("raid10")
+)
+
+// RAIDConfig holds the RAID layout of logical volumes in a device class
+type RAIDConfig struct {
+	// Type is the RAID segment type, supports 'raid1', 'raid5' or 'raid10'
+	Type RAIDType `json:"type"`
+	// Mirrors is the number of additional data copies for 'raid1' and 'raid10', defaults to 1
+	Mirrors *uint `json:"mirrors"`
+}
+
+type CacheMode string
+
+const (
+	CacheModeWritethrough = CacheMode("writethrough")
+	CacheModeWriteback    = CacheMode("writeback")
+)
+
+// CacheConfig holds the dm-cache configuration of logical volumes in a device class
+type CacheConfig struct {
+	// Device is the physical volume of the volume group holding the caches, e.g. a fast SSD
+	Device string `json:"device"`
+	// SizePercent is the size of the cache of each logical volume in percent of the volume size, defaults to 10
+	SizePercent *uint `json:"size-percent"`
+	// Mode is the cache mode, supports 'writethrough' or 'writeback', defaults to 'writethrough'
+	Mode CacheMode `json:"mode"`
+}
+
+// VDOConfig holds the VDO configuration of logical volumes in a device class
+type VDOConfig struct {
+	// Compression enables compression of the data, defaults to true
+	Compression *bool `json:"compression"`
+	// Deduplication enables deduplication of the data, defaults to true
+	Deduplication *bool `json:"deduplication"`
+	// PhysicalSizePercent is the size of the VDO pool of each logical volume in percent of the volume size, defaults to 100
+	PhysicalSizePercent *uint `json:"physical-size-percent"`
+}
+
+// VolumeGroupPolicy selects the volume group of a new logical volume in a device class backed by multiple volume groups
+type VolumeGroupPolicy string
+
+const (
+	VolumeGroupPolicyMostFree   = VolumeGroupPolicy("most-free")
+	VolumeGroupPolicyRoundRobin = VolumeGroupPolicy("round-robin")
+)
+
+type WipePolicy string
+
+const (
+	WipeNone    = WipePolicy("none")
+	WipeDiscard = WipePolicy("discard")
+	WipeZero    = WipePolicy("zero")
 )
 
 // ThinPoolConfig holds the configuration of thin pool in a volume group
@@ -13,15 +73,41 @@ type ThinPoolConfig struct {
 	Name string `json:"name"`
 	// OverprovisionRatio signifies the upper bound multiplier for allowing logical volume creation in this pool
 	OverprovisionRatio float64 `json:"overprovision-ratio"`
+	// NotifyThresholds are the data or metadata usages of this pool in percent at which the watchers
+	// are notified immediately when dmeventd reports an event of the pool.
+	// Every event is notified if empty.
+	NotifyThresholds []float64 `json:"notify-thresholds"`
+	// MaxSnapshotDepth is the maximum length of the origin chain of thin snapshots in this pool,
+	// e.g. 1 disallows snapshots of snapshots. The depth is not limited if 0.
+	MaxSnapshotDepth uint `json:"max-snapshot-depth"`
+	// VirtualQuotaGB is the maximum total virtual size in GiB of the thin volumes of this device-class.
+	// Device-classes may share a thin pool if all of them set it.
+	VirtualQuotaGB *uint64 `json:"virtual-quota-gb"`
+}
+
+// RawConfig holds the devices handed out as a whole by a device-class of type 'raw'
+type RawConfig struct {
+	// Devices are the disks or partitions of this device-class. Glob patterns such as /dev/nvme*n1 are
+	// matched against the devices on each use, so that devices added later are discovered.
+	Devices []string `json:"devices"`
+	// StateFile is the file tracking the devices claimed by volumes, which must persist across reboots,
+	// defaults to /var/lib/topolvm/raw/<device-class name>.json
+	StateFile string `json:"state-file"`
 }
 
 // DeviceClass maps between device-classes and target for logical volume creation
-// current targets are VolumeGroup for thick-lv and ThinPool for thin-lv
+// current targets are VolumeGroup for thick-lv, ThinPool for thin-lv and whole devices for raw
 type DeviceClass struct {
 	// Name for the device-class name
 	Name string `json:"name"`
 	// Volume group name for the device-class
 	VolumeGroup string `json:"volume-group"`
+	// VolumeGroups are the names of the volume groups of a thick device-class backed by more than one,
+	// e.g. one per disk, instead of VolumeGroup
+	VolumeGroups []string `json:"volume-groups"`
+	// VolumeGroupPolicy selects the volume group out of VolumeGroups for a new logical volume,
+	// supports 'most-free' (default) or 'round-robin'
+	VolumeGroupPolicy VolumeGroupPolicy `json:"volume-group-policy"`
 	// Default is a flag to indicate whether the device-class is the default
 	Default bool `json:"default"`
 	// SpareGB is storage capacity in GiB to be spared
@@ -32,10 +118,44 @@ type DeviceClass struct {
 	StripeSize string `json:"stripe-size"`
 	// LVCreateOptions are extra arguments to pass to lvcreate
 	LVCreateOptions []string `json:"lvcreate-options"`
-	// Type is the name of logical volume target, supports 'thick' (default) or 'thin' currently
+	// Type is the name of logical volume target, supports 'thick' (default), 'thin' or 'raw' currently
 	Type DeviceType `json:"type"`
 	// ThinPoolConfig holds the configuration for thinpool in this volume group corresponding to the device-class
 	ThinPoolConfig *ThinPoolConfig `json:"thin-pool"`
+	// ThinDeviceClass is the thin device-class on the same volume group whose thin pool holds the thin volumes
+	// requested on this thick device-class by overriding the type. It is required if the volume group has
+	// more than one thin device-class.
+	ThinDeviceClass string `json:"thin-device-class"`
+	// Raw holds the devices of this device-class if Type is 'raw', which are handed out as volumes without LVM
+	Raw *RawConfig `json:"raw"`
+	// RAID holds the RAID layout of thick logical volumes in this device-class.
+	// The number of data stripes for 'raid5' and 'raid10' is taken from Stripe.
+	RAID *RAIDConfig `json:"raid"`
+	// Cache holds the dm-cache configuration of thick logical volumes in this device-class
+	Cache *CacheConfig `json:"cache"`
+	// VDO holds the VDO configuration of thick logical volumes in this device-class
+	VDO *VDOConfig `json:"vdo"`
+	// SnapshotCOWSizePercent is the size of the copy-on-write space of snapshots of thick logical volumes
+	// in percent of the source volume, 100 if not specified.
+	SnapshotCOWSizePercent *uint `json:"snapshot-cow-size-percent"`
+	// AllowShrink enables shrinking logical volumes in this device-class together with their filesystems.
+	AllowShrink bool `json:"allow-shrink"`
+	// ActivationSkip creates logical volumes in this device-class with the activation skip flag.
+	// They are activated only while they are staged on the node.
+	ActivationSkip bool `json:"activation-skip"`
+	// WipeOnDelete wipes logical volumes in this device-class before removing them, supports 'none' (default),
+	// 'discard' or 'zero'. 'discard' falls back to 'zero' for devices not supporting discards.
+	WipeOnDelete WipePolicy `json:"wipe-on-delete"`
+	// MaxSize is the maximum size of a logical volume in this device-class as a quantity, e.g. "100Gi".
+	// The size is not limited if empty.
+	MaxSize string `json:"max-size"`
+	// MaxVolumes is the maximum number of logical volumes in this device-class including snapshots.
+	// The number is not limited if 0.
+	MaxVolumes uint `json:"max-volumes"`
+	// Draining makes this device-class reject new logical volumes while the existing ones can still be resized,
+	// snapshotted and removed, e.g. to empty it before replacing the disks. It can be changed at runtime
+	// by the SetDeviceClassDraining RPC.
+	Draining bool `json:"draining"`
 }
 
 type LvcreateOptionClass struct {
@@ -43,4 +163,7 @@ type LvcreateOptionClass struct {
 	Name string `json:"name"`
 	// Options are extra arguments to pass to lvcreate
 	Options []string `json:"options"`
+	// InlineOptions are the prefixes of the arguments which StorageClasses using this class may pass to lvcreate
+	// in addition to Options, e.g. "--config=allocation/".
+	InlineOptions []string `json:"inline-options"`
 }
@@ -0,0 +1,20 @@
+package lvmd
+
+import (
+	"context"
+	"net"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
+)
+
+// VsockDialer returns a dialer connecting to lvmd listening on the vsock address of the form "CID:port",
+// which can be passed to grpc.WithContextDialer.
+func VsockDialer(address string) (func(context.Context, string) (net.Conn, error), error) {
+	addr, err := internalLvmd.ParseVsockAddr(address)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx context.Context, _ string) (net.Conn, error) {
+		return internalLvmd.DialVsock(ctx, addr)
+	}, nil
+}
@@ -5,3 +5,5 @@ import (
 )
 
 var NewGRPCRunner = internalRunners.NewGRPCRunner
+
+var NewGRPCRunnerAfter = internalRunners.NewGRPCRunnerAfter
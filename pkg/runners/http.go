@@ -0,0 +1,7 @@
+package runners
+
+import (
+	internalRunners "github.com/topolvm/topolvm/internal/runners"
+)
+
+var NewHTTPRunner = internalRunners.NewHTTPRunner
@@ -0,0 +1,120 @@
+package testing
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	topolvmlegacyv1 "github.com/topolvm/topolvm/api/legacy/v1"
+	topolvmv1 "github.com/topolvm/topolvm/api/v1"
+	internalController "github.com/topolvm/topolvm/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+// Environment is an envtest API server with the TopoLVM CRDs installed.
+type Environment struct {
+	Config *rest.Config
+	Scheme *k8sruntime.Scheme
+	// Client reads from and writes to the API server directly.
+	Client client.Client
+}
+
+// StartEnvironment starts an API server with the TopoLVM CRDs until the test ends.
+// The binaries of the API server and etcd are looked up by envtest, e.g. in KUBEBUILDER_ASSETS.
+func StartEnvironment(t testing.TB) *Environment {
+	t.Helper()
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths:     []string{crdDirectory()},
+		ErrorIfCRDPathMissing: true,
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("failed to start envtest: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("failed to stop envtest: %v", err)
+		}
+	})
+
+	scheme := k8sruntime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(topolvmv1.AddToScheme(scheme))
+	utilruntime.Must(topolvmlegacyv1.AddToScheme(scheme))
+	c, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Environment{Config: cfg, Scheme: scheme, Client: c}
+}
+
+// crdDirectory returns the directory of the CRDs in the source of this module,
+// which is also available in the module cache.
+func crdDirectory() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "..", "config", "crd", "bases")
+}
+
+// StartController runs the controllers of topolvm-controller until the test ends, which finalize
+// Nodes and PersistentVolumeClaims.
+func (e *Environment) StartController(t testing.TB) {
+	t.Helper()
+	e.startManager(t, func(mgr ctrl.Manager) error {
+		c := mgr.GetClient()
+		if err := internalController.NewNodeReconciler(c, false, false).SetupWithManager(mgr); err != nil {
+			return err
+		}
+		return internalController.NewPersistentVolumeClaimReconciler(c, mgr.GetAPIReader()).SetupWithManager(mgr)
+	})
+}
+
+// StartNode creates a Node of nodeName and runs the LogicalVolume controller of topolvm-node for it
+// until the test ends, which creates and removes the volumes of LogicalVolumes in local.
+func (e *Environment) StartNode(t testing.TB, nodeName string, local *Local) {
+	t.Helper()
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: nodeName}}
+	if err := e.Client.Create(context.Background(), node); err != nil {
+		t.Fatal(err)
+	}
+	e.startManager(t, func(mgr ctrl.Manager) error {
+		reconciler := internalController.NewLogicalVolumeReconcilerWithServices(mgr.GetClient(), nodeName, local.VG, local.LV)
+		return reconciler.SetupWithManager(mgr)
+	})
+}
+
+func (e *Environment) startManager(t testing.TB, setup func(ctrl.Manager) error) {
+	t.Helper()
+	mgr, err := ctrl.NewManager(e.Config, ctrl.Options{
+		Scheme:  e.Scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setup(mgr); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := mgr.Start(ctx); err != nil {
+			t.Errorf("failed to run the manager: %v", err)
+		}
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
@@ -0,0 +1,97 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	internalLvmd "github.com/topolvm/topolvm/internal/lvmd"
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Local is an lvmd serving gRPC on a Unix domain socket in the test process.
+type Local struct {
+	// Socket is the path of the Unix domain socket, which can be passed to topolvm-node as --lvmd-socket.
+	Socket string
+	// Conn is the connection to Socket shared by the clients.
+	Conn *grpc.ClientConn
+
+	LV     proto.LVServiceClient
+	VG     proto.VGServiceClient
+	Health grpc_health_v1.HealthClient
+
+	notify func()
+}
+
+// NewLocal starts an lvmd serving the device-classes until the test ends.
+// It runs lvm commands on the host unless NewFakeLVM is called before.
+func NewLocal(t testing.TB, deviceClasses []*lvmdTypes.DeviceClass, lvcreateOptionClasses []*lvmdTypes.LvcreateOptionClass) *Local {
+	t.Helper()
+	// t.TempDir may exceed the maximum length of socket paths.
+	dir, err := os.MkdirTemp("", "lvmd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	socket := filepath.Join(dir, "lvmd.sock")
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dcm := internalLvmd.NewDeviceClassManager(deviceClasses)
+	ocm := internalLvmd.NewLvcreateOptionClassManager(lvcreateOptionClasses)
+	vgService, notify := internalLvmd.NewVGService(dcm, ocm)
+	server := grpc.NewServer()
+	proto.RegisterVGServiceServer(server, vgService)
+	proto.RegisterLVServiceServer(server, internalLvmd.NewLVService(dcm, ocm, notify))
+	grpc_health_v1.RegisterHealthServer(server, internalLvmd.NewHealthService(dcm))
+	go func() {
+		_ = server.Serve(lis)
+	}()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return &Local{
+		Socket: socket,
+		Conn:   conn,
+		LV:     proto.NewLVServiceClient(conn),
+		VG:     proto.NewVGServiceClient(conn),
+		Health: grpc_health_v1.NewHealthClient(conn),
+		notify: notify,
+	}
+}
+
+// Notify makes the Watch streams of VG send the current free space, which lvmd does periodically
+// and after changing logical volumes. Call it after changing a FakeLVM directly.
+func (l *Local) Notify() {
+	l.notify()
+}
+
+// Check returns an error unless the health service of the lvmd reports SERVING for all the device-classes.
+func (l *Local) Check(ctx context.Context) error {
+	res, err := l.Health.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+	if res.GetStatus() != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("lvmd is %s", res.GetStatus())
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package testing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/topolvm/topolvm/pkg/lvmd/proto"
+	lvmdTypes "github.com/topolvm/topolvm/pkg/lvmd/types"
+)
+
+func TestLocalWithFakeLVM(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFakeLVM(t)
+	local := NewLocal(t, []*lvmdTypes.DeviceClass{{Name: "ssd", VolumeGroup: "ssd-vg", Default: true}}, nil)
+
+	if err := local.Check(ctx); err == nil {
+		t.Error("lvmd should not be serving without the volume group")
+	}
+	fake.AddVolumeGroup("ssd-vg", 10<<30)
+	if err := local.Check(ctx); err != nil {
+		t.Error(err)
+	}
+
+	res, err := local.LV.CreateLV(ctx, &proto.CreateLVRequest{Name: "test", DeviceClass: "ssd", SizeBytes: 1 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.GetVolume().GetSizeBytes() != 1<<30 {
+		t.Errorf("expected a volume of 1 GiB, got %d bytes", res.GetVolume().GetSizeBytes())
+	}
+
+	list, err := local.VG.GetLVList(ctx, &proto.GetLVListRequest{DeviceClass: "ssd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.GetVolumes()) != 1 || list.GetVolumes()[0].GetName() != "test" {
+		t.Errorf("unexpected volumes: %v", list.GetVolumes())
+	}
+}
@@ -0,0 +1,25 @@
+// Package testing provides helpers to test software built on top of TopoLVM against a simulated LVM
+// without root privileges or a Kubernetes cluster of real nodes.
+//
+// NewFakeLVM replaces lvm with an in-memory simulation, NewLocal serves lvmd on top of it, and
+// StartEnvironment runs the TopoLVM controllers of a node against an envtest API server.
+package testing
+
+import (
+	"testing"
+
+	"github.com/topolvm/topolvm/internal/lvmd/command"
+)
+
+// FakeLVM simulates the lvm commands issued by lvmd in memory.
+// Volume groups and devices are added with AddVolumeGroup and AddDevice.
+type FakeLVM = command.FakeLVM
+
+// NewFakeLVM makes lvmd run lvm commands against a FakeLVM without any volume group until the test ends.
+// The commands of lvmd are replaced process-wide, so tests using it must not run in parallel.
+func NewFakeLVM(t testing.TB) *FakeLVM {
+	t.Helper()
+	fake := command.NewFakeLVM()
+	t.Cleanup(command.SetExecutor(fake))
+	return fake
+}
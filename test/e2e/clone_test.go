@@ -27,7 +27,7 @@ const (
 func testPVCClone() {
 	var nsCloneTest string
 	BeforeEach(func() {
-		nsCloneTest = "clone-test-" + randomString()
+		nsCloneTest = testNamespace("clone-test-" + randomString())
 		createNamespace(nsCloneTest)
 	})
 	AfterEach(func() {
@@ -105,10 +105,10 @@ func testPVCClone() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("confirming that the file exists in the cloned volume")
@@ -180,10 +180,10 @@ func testPVCClone() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("deleting the source volume and application")
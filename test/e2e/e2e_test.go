@@ -44,7 +44,7 @@ func testE2E() {
 	BeforeEach(func() {
 		cc = commonBeforeEach()
 
-		ns = testNamespacePrefix + randomString()
+		ns = testNamespace(testNamespacePrefix + randomString())
 		createNamespace(ns)
 	})
 
@@ -516,7 +516,7 @@ func testE2E() {
 					return fmt.Errorf("kubectl get nodes error: %w", err)
 				}
 				for _, node := range nodes.Items {
-					if node.Name == "topolvm-e2e-control-plane" {
+					if !isTestNode(node.Name) {
 						continue
 					}
 					strCap, ok := node.Annotations[topolvm.GetCapacityKeyPrefix()+"dc1"]
@@ -706,7 +706,7 @@ func testE2E() {
 		var targetNode string
 		var maxCapacity int
 		for _, node := range nodeList.Items {
-			if node.Name == "topolvm-e2e-control-plane" {
+			if !isTestNode(node.Name) {
 				continue
 			}
 
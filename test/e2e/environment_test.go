@@ -0,0 +1,203 @@
+package e2e
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// testEnvironment describes the cluster under test. By default, it is the kind cluster set up by the Makefile
+// with lvmd and the volume groups on the host. With E2E_EXTERNAL=true, the suite validates an existing cluster
+// instead, e.g. a bare-metal cluster after installing TopoLVM, whose volume groups have been created beforehand.
+type testEnvironment struct {
+	// external is true when the suite runs against an existing cluster (E2E_EXTERNAL).
+	// The specs stopping or deleting nodes are skipped, and the namespaces of the specs are deleted after the suite.
+	external bool
+	// kubeconfig is passed to kubectl if it is not empty (E2E_KUBECONFIG).
+	kubeconfig string
+	// nodes are the nodes running topolvm-node in place of the kind workers (E2E_NODES, comma separated).
+	nodes []string
+	// lvmCommand is the command running lvm on a node, where {node} is replaced with the name of the node
+	// (E2E_LVM_COMMAND, e.g. "ssh {node} sudo"). The kind nodes share the LVM of the host,
+	// so lvm runs locally with sudo by default.
+	lvmCommand []string
+	// volumeGroups maps the volume groups of the kind cluster, e.g. "thin1", to the volume groups
+	// of the cluster (E2E_VOLUME_GROUPS, e.g. "thin1=vg-nvme,thick2=vg-hdd").
+	volumeGroups map[string]string
+	// thinPool is the thin pool of the thin device-class (E2E_THIN_POOL).
+	thinPool string
+	// namespacePrefix is prepended to the namespaces of the specs (E2E_NAMESPACE_PREFIX).
+	// It defaults to "topolvm-e2e-" for an external cluster, so that the cleanup does not touch other namespaces.
+	namespacePrefix string
+}
+
+var kindNodes = []string{"topolvm-e2e-worker", "topolvm-e2e-worker2", "topolvm-e2e-worker3"}
+
+const kindControlPlaneNode = "topolvm-e2e-control-plane"
+
+var testEnv = loadTestEnvironment()
+
+func loadTestEnvironment() *testEnvironment {
+	env := &testEnvironment{
+		external:        os.Getenv("E2E_EXTERNAL") == "true",
+		kubeconfig:      os.Getenv("E2E_KUBECONFIG"),
+		nodes:           splitList(os.Getenv("E2E_NODES")),
+		lvmCommand:      strings.Fields(os.Getenv("E2E_LVM_COMMAND")),
+		volumeGroups:    make(map[string]string),
+		thinPool:        os.Getenv("E2E_THIN_POOL"),
+		namespacePrefix: os.Getenv("E2E_NAMESPACE_PREFIX"),
+	}
+	for _, vg := range splitList(os.Getenv("E2E_VOLUME_GROUPS")) {
+		name, value, _ := strings.Cut(vg, "=")
+		env.volumeGroups[name] = value
+	}
+	if len(env.lvmCommand) == 0 {
+		env.lvmCommand = []string{"sudo"}
+	}
+	if env.thinPool == "" {
+		env.thinPool = "pool0"
+	}
+	if env.namespacePrefix == "" && env.external {
+		env.namespacePrefix = "topolvm-e2e-"
+	}
+	return env
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// validate checks that the environment of an external cluster is complete,
+// since the defaults only apply to the kind cluster.
+func (e *testEnvironment) validate() error {
+	if !e.external {
+		return nil
+	}
+	if len(e.nodes) == 0 {
+		return fmt.Errorf("E2E_NODES is required for an external cluster")
+	}
+	if os.Getenv("E2E_LVM_COMMAND") == "" {
+		return fmt.Errorf("E2E_LVM_COMMAND is required for an external cluster")
+	}
+	return nil
+}
+
+// isTestNode returns true if topolvm-node runs on the node.
+func isTestNode(name string) bool {
+	if len(testEnv.nodes) == 0 {
+		return name != kindControlPlaneNode
+	}
+	return containsString(testEnv.nodes, name)
+}
+
+// testNode returns the i-th node running topolvm-node, e.g. "topolvm-e2e-worker2" for 1 in the kind cluster.
+// The spec is skipped if the cluster does not have as many nodes.
+func testNode(i int) string {
+	nodes := testEnv.nodes
+	if len(nodes) == 0 {
+		nodes = kindNodes
+	}
+	if i >= len(nodes) {
+		Skip(fmt.Sprintf("This test requires %d nodes", i+1))
+	}
+	return nodes[i]
+}
+
+// testVolumeGroup returns the volume group of the cluster in place of the volume group of the kind cluster name,
+// e.g. "node1-thin1" for "thin1".
+func testVolumeGroup(name string) string {
+	if vg, ok := testEnv.volumeGroups[name]; ok {
+		return vg
+	}
+	return "node1-" + name
+}
+
+// testNamespace returns the namespace of a spec prefixed with E2E_NAMESPACE_PREFIX.
+func testNamespace(name string) string {
+	return testEnv.namespacePrefix + name
+}
+
+func skipIfExternal(reason string) {
+	if testEnv.external {
+		Skip("skip because the cluster is external: " + reason)
+	}
+}
+
+// execLVM runs the lvm command args, e.g. "lvs", on the node.
+func execLVM(node string, args ...string) ([]byte, error) {
+	command := make([]string, 0, len(testEnv.lvmCommand)+len(args))
+	for _, c := range testEnv.lvmCommand {
+		command = append(command, strings.ReplaceAll(c, "{node}", node))
+	}
+	command = append(command, args...)
+	return execAtLocal(command[0], nil, command[1:]...)
+}
+
+// execLVMOnNodes runs the lvm command args on all the nodes and concatenates the outputs.
+// The kind nodes share the LVM of the host, so it runs only once for them.
+func execLVMOnNodes(args ...string) ([]byte, error) {
+	if !testEnv.external {
+		return execLVM("", args...)
+	}
+	var stdout []byte
+	for _, node := range testEnv.nodes {
+		out, err := execLVM(node, args...)
+		if err != nil {
+			return nil, err
+		}
+		stdout = append(stdout, out...)
+	}
+	return stdout, nil
+}
+
+// deleteTestNamespaces deletes the namespaces with E2E_NAMESPACE_PREFIX left over by the specs, e.g. failed ones,
+// and waits for them to be deleted, so that the volumes in them are removed from the nodes.
+func deleteTestNamespaces() {
+	if testEnv.namespacePrefix == "" {
+		return
+	}
+	Eventually(func() error {
+		var namespaces corev1.NamespaceList
+		err := getObjects(&namespaces, "namespaces")
+		if err == ErrObjectNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var remaining []string
+		for _, ns := range namespaces.Items {
+			if strings.HasPrefix(ns.Name, testEnv.namespacePrefix) {
+				remaining = append(remaining, ns.Name)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		_, err = kubectl(append([]string{"delete", "namespaces", "--ignore-not-found", "--wait=false"}, remaining...)...)
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("namespaces are still being deleted: %v", remaining)
+	}).WithTimeout(10 * time.Minute).Should(Succeed())
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -47,16 +47,23 @@ func execAtLocal(cmd string, input []byte, args ...string) (stdout []byte, err e
 
 // kubectl executes kubectl command.
 func kubectl(args ...string) (stdout []byte, err error) {
-	return execAtLocal(kubectlPath, nil, args...)
+	return execAtLocal(kubectlPath, nil, kubectlArgs(args)...)
 }
 
 // kubectlWithInput executes kubectl command with stdin input.
 func kubectlWithInput(input []byte, args ...string) (stdout []byte, err error) {
-	return execAtLocal(kubectlPath, input, args...)
+	return execAtLocal(kubectlPath, input, kubectlArgs(args)...)
+}
+
+func kubectlArgs(args []string) []string {
+	if testEnv.kubeconfig == "" {
+		return args
+	}
+	return append([]string{"--kubeconfig", testEnv.kubeconfig}, args...)
 }
 
 func countLVMs() (int, error) {
-	stdout, err := execAtLocal("sudo", nil, "lvs", "-o", "lv_name", "--noheadings")
+	stdout, err := execLVMOnNodes("lvs", "-o", "lv_name", "--noheadings")
 	if err != nil {
 		return -1, err
 	}
@@ -72,7 +79,7 @@ func getNodeAnnotationMapWithPrefix(prefix string) (map[string]map[string]string
 
 	capacities := make(map[string]map[string]string)
 	for _, node := range nodeList.Items {
-		if node.Name == "topolvm-e2e-control-plane" {
+		if !isTestNode(node.Name) {
 			continue
 		}
 
@@ -154,7 +161,7 @@ type lvinfo struct {
 }
 
 func getLVInfo(lvName string) (*lvinfo, error) {
-	stdout, err := execAtLocal("sudo", nil,
+	stdout, err := execLVMOnNodes(
 		"lvs", "--noheadings", "-o", "lv_size,pool_lv,vg_name",
 		"--units", "b", "--nosuffix", "--separator", ":",
 		"--select", "lv_name="+lvName)
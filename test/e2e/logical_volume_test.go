@@ -20,7 +20,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-const nsLogicalVolumeTest = "logical-volume"
+var nsLogicalVolumeTest = testNamespace("logical-volume")
 
 //go:embed testdata/logical_volume/pvc-template.yaml
 var pvcTemplateYAMLForLV string
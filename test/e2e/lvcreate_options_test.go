@@ -19,7 +19,7 @@ func testLVCreateOptions() {
 	BeforeEach(func() {
 		cc = commonBeforeEach()
 
-		ns = testNamespacePrefix + randomString()
+		ns = testNamespace(testNamespacePrefix + randomString())
 		createNamespace(ns)
 	})
 
@@ -65,7 +65,7 @@ func testLVCreateOptions() {
 			}).Should(Succeed())
 
 			By("checking that the LV is of type raid")
-			stdout, err := execAtLocal("sudo", nil, "lvs", "-o", "lv_attr", "--noheadings", "--select", "lv_name="+lvName)
+			stdout, err := execLVMOnNodes("lvs", "-o", "lv_attr", "--noheadings", "--select", "lv_name="+lvName)
 			Expect(err).ShouldNot(HaveOccurred())
 			attribute_bit1 := string(strings.TrimSpace(string(stdout))[0])
 			// lv_attr bit 1 represents the volume type, where 'r' is for raid
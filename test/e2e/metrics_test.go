@@ -21,7 +21,7 @@ import (
 	"sigs.k8s.io/yaml"
 )
 
-const nsMetricsTest = "metrics-test"
+var nsMetricsTest = testNamespace("metrics-test")
 
 //go:embed testdata/metrics/pause-pod-with-pvc-template.yaml
 var pausePodWithPVCTemplateYAML string
@@ -111,7 +111,7 @@ func testMetrics() {
 			Expect(mf.Metric).Should(HaveLen(len(deviceClasses)))
 
 			for _, deviceClass := range deviceClasses {
-				vgFree, err := vgFreeByte(deviceClass)
+				vgFree, err := vgFreeByte(pod.Spec.NodeName, deviceClass)
 				Expect(err).ShouldNot(HaveOccurred())
 
 				available := getGaugeValueWithLabels(map[string]string{
@@ -197,10 +197,12 @@ func loadDeviceClasses(node string) ([]*lvmdTypes.DeviceClass, error) {
 	} else if err != ErrObjectNotFound {
 		return nil, err
 	} else {
+		// lvmd runs as a systemd service on the host of the kind cluster.
+		skipIfExternal("the configuration of lvmd is not found in the cluster")
 		path, ok := map[string]string{
-			"topolvm-e2e-worker":  "lvmd1.yaml",
-			"topolvm-e2e-worker2": "lvmd2.yaml",
-			"topolvm-e2e-worker3": "lvmd3.yaml",
+			kindNodes[0]: "lvmd1.yaml",
+			kindNodes[1]: "lvmd2.yaml",
+			kindNodes[2]: "lvmd3.yaml",
 		}[node]
 		if !ok {
 			return nil, fmt.Errorf("unknown node: %s", node)
@@ -220,8 +222,8 @@ func loadDeviceClasses(node string) ([]*lvmdTypes.DeviceClass, error) {
 	return config.DeviceClasses, nil
 }
 
-func vgFreeByte(deviceClass *lvmdTypes.DeviceClass) (int64, error) {
-	output, err := execAtLocal("sudo", nil, "vgs",
+func vgFreeByte(node string, deviceClass *lvmdTypes.DeviceClass) (int64, error) {
+	output, err := execLVM(node, "vgs",
 		"-o", "vg_free",
 		"--noheadings",
 		"--units=b",
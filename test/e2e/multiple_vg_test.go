@@ -20,7 +20,7 @@ func testMultipleVolumeGroups() {
 	testNamespacePrefix := "multivgtest-"
 	var ns string
 	BeforeEach(func() {
-		ns = testNamespacePrefix + randomString()
+		ns = testNamespace(testNamespacePrefix + randomString())
 		createNamespace(ns)
 	})
 
@@ -62,7 +62,7 @@ func testMultipleVolumeGroups() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thick2"
+		vgName := testVolumeGroup("thick2")
 		Expect(vgName).Should(Equal(lv.vgName))
 	})
 
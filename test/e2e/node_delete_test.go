@@ -14,7 +14,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
-const nsNodeDeleteTest = "node-delete-test"
+var nsNodeDeleteTest = testNamespace("node-delete-test")
 
 //go:embed testdata/node_delete/statefulset-template.yaml
 var statefulSetTemplateYAML string
@@ -22,6 +22,7 @@ var statefulSetTemplateYAML string
 func testNodeDelete() {
 
 	BeforeEach(func() {
+		skipIfExternal("the node is stopped with docker")
 		skipIfSingleNode()
 
 		createNamespace(nsNodeDeleteTest)
@@ -68,7 +69,7 @@ func testNodeDelete() {
 
 		By("getting a target pod")
 		var targetPod *corev1.Pod
-		targetNode := "topolvm-e2e-worker3"
+		targetNode := kindNodes[2]
 		var pods corev1.PodList
 		err = getObjects(&pods, "pods", "-n", nsNodeDeleteTest)
 		Expect(err).ShouldNot(HaveOccurred())
@@ -18,7 +18,7 @@ var pvcForReadWriteOncePodTemplateYAML string
 var podForReadWriteOncePodTemplateYAML string
 
 func testReadWriteOncePod() {
-	ns := "read-write-once-pod-test"
+	ns := testNamespace("read-write-once-pod-test")
 	var cc CleanupContext
 
 	BeforeEach(func() {
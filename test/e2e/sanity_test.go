@@ -26,9 +26,11 @@ func testSanity() {
 	var tc, thinTC sanity.TestConfig
 
 	BeforeEach(func() {
-		_, err := kubectl("delete", "nodes", "topolvm-e2e-worker2", "--ignore-not-found")
+		skipIfExternal("the nodes other than the first one are deleted")
+
+		_, err := kubectl("delete", "nodes", kindNodes[1], "--ignore-not-found")
 		Expect(err).ShouldNot(HaveOccurred())
-		_, err = kubectl("delete", "nodes", "topolvm-e2e-worker3", "--ignore-not-found")
+		_, err = kubectl("delete", "nodes", kindNodes[2], "--ignore-not-found")
 		Expect(err).ShouldNot(HaveOccurred())
 
 		Eventually(func(g Gomega) {
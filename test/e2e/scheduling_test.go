@@ -16,7 +16,7 @@ import (
 //go:embed testdata/scheduling/pvc-pod-template.yaml
 var podPVCTemplateYAML string
 
-const nsSchedulingTest = "scheduling-test"
+var nsSchedulingTest = testNamespace("scheduling-test")
 
 func testScheduling() {
 	var cc CleanupContext
@@ -32,7 +32,7 @@ func testScheduling() {
 
 	It("should schedule a pod with a PVC if a node has enough capacity", func() {
 		name := "pod-pvc"
-		nodeName := "topolvm-e2e-worker"
+		nodeName := testNode(0)
 
 		By("checking the pod with a PVC is running if a node capacity is sufficient")
 		var node corev1.Node
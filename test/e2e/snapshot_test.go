@@ -45,7 +45,7 @@ func testSnapRestore() {
 	var snapshot snapapi.VolumeSnapshot
 
 	BeforeEach(func() {
-		nsSnapTest = "snap-test-" + randomString()
+		nsSnapTest = testNamespace("snap-test-" + randomString())
 		createNamespace(nsSnapTest)
 	})
 	AfterEach(func() {
@@ -145,10 +145,10 @@ func testSnapRestore() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("confirming that the file exists")
@@ -261,10 +261,10 @@ func testSnapRestore() {
 
 		By(fmt.Sprintf("using lv with size %v", lv.size))
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("confirming that the file exists")
@@ -384,10 +384,10 @@ func testSnapRestore() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		// delete the source PVC as well as the snapshot
@@ -97,12 +97,20 @@ var _ = BeforeSuite(func() {
 	kubectlPath = os.Getenv("KUBECTL")
 	Expect(kubectlPath).ShouldNot(BeEmpty())
 	fmt.Println("This test uses a kubectl at " + kubectlPath)
+	Expect(testEnv.validate()).To(Succeed())
+	if testEnv.external {
+		fmt.Printf("This test runs against an external cluster with nodes %v\n", testEnv.nodes)
+	}
 
 	By("Getting node count")
 	var nodes corev1.NodeList
 	err := getObjects(&nodes, "nodes", "-l=!node-role.kubernetes.io/control-plane")
 	Expect(err).Should(SatisfyAny(Not(HaveOccurred()), BeIdenticalTo(ErrObjectNotFound)))
 	nonControlPlaneNodeCount = len(nodes.Items)
+	if testEnv.external {
+		// the control plane of an external cluster may not run TopoLVM, or may run it without the label.
+		nonControlPlaneNodeCount = len(testEnv.nodes)
+	}
 
 	By("Waiting for kindnet to get ready if necessary")
 	// Because kindnet will crash. we need to confirm its readiness twice.
@@ -124,6 +132,13 @@ var _ = BeforeSuite(func() {
 	Expect(err).ShouldNot(HaveOccurred())
 })
 
+var _ = AfterSuite(func() {
+	if testEnv.external {
+		By("Deleting the namespaces of the tests")
+		deleteTestNamespaces()
+	}
+})
+
 var _ = Describe("TopoLVM", func() {
 	Context("scheduling", testScheduling)
 	Context("metrics", testMetrics)
@@ -24,7 +24,7 @@ func testThinProvisioning() {
 	var cc CleanupContext
 	BeforeEach(func() {
 		cc = commonBeforeEach()
-		ns = testNamespacePrefix + randomString()
+		ns = testNamespace(testNamespacePrefix + randomString())
 		createNamespace(ns)
 	})
 
@@ -61,10 +61,10 @@ func testThinProvisioning() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("deleting the Pod and PVC")
@@ -127,10 +127,10 @@ func testThinProvisioning() {
 				return err
 			}).Should(Succeed())
 
-			vgName := "node1-thin1"
+			vgName := testVolumeGroup("thin1")
 			Expect(vgName).Should(Equal(lv.vgName))
 
-			poolName := "pool0"
+			poolName := testEnv.thinPool
 			Expect(poolName).Should(Equal(lv.poolName))
 		}
 
@@ -196,10 +196,10 @@ func testThinProvisioning() {
 			return err
 		}).Should(Succeed())
 
-		vgName := "node1-thin1"
+		vgName := testVolumeGroup("thin1")
 		Expect(vgName).Should(Equal(lv.vgName))
 
-		poolName := "pool0"
+		poolName := testEnv.thinPool
 		Expect(poolName).Should(Equal(lv.poolName))
 
 		By("Failing to deploying a PVC when total size > thinpoolsize * overprovisioning")